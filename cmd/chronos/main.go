@@ -1,24 +1,44 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/ericp/chronos-bot-reminder/internal/api"
 	"github.com/ericp/chronos-bot-reminder/internal/bot"
 	"github.com/ericp/chronos-bot-reminder/internal/config"
 	"github.com/ericp/chronos-bot-reminder/internal/database"
 	"github.com/ericp/chronos-bot-reminder/internal/engine"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/ericp/chronos-bot-reminder/internal/tracing"
 )
 
 func main() {
-	log.Println("[ALL] - ⏳ Initializing Chronos Reminder")
-
 	// Load configuration
 	cfg := config.Load()
 
+	// Configure the structured loggers, the standard log package goes through them from here
+	if err := logging.Init(cfg); err != nil {
+		log.Fatalf("[ALL] - ❌ Invalid logging configuration: %v", err)
+	}
+
+	// "chronos migrate up|down|status" manages the schema and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+
+	// Export the traces when enabled, the pending spans are flushed on shutdown
+	shutdownTracing, err := tracing.Init(cfg)
+	if err != nil {
+		log.Fatalf("[ALL] - ❌ Invalid tracing configuration: %v", err)
+	}
+
+	log.Println("[ALL] - ⏳ Initializing Chronos Reminder")
+
 	// Initialize database
 	if err := database.Initialize(); err != nil {
 		log.Fatalf("[DATABASE] - ❌ Failed to initialize database: %v", err)
@@ -63,4 +83,11 @@ func main() {
 	if err := apiServer.Stop(); err != nil {
 		log.Printf("[API] - ❌ Error stopping API server: %v", err)
 	}
+
+	// Flush the spans of the last requests and fires
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		log.Printf("[ALL] - ❌ Error flushing traces: %v", err)
+	}
 }
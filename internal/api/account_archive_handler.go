@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/google/uuid"
+)
+
+// maxAccountArchiveSize is the largest archive accepted by the import
+const maxAccountArchiveSize = 10 << 20
+
+// AccountArchiveHandler exports the data of an account and restores it
+type AccountArchiveHandler struct {
+	archiveService *services.AccountArchiveService
+}
+
+// NewAccountArchiveHandler creates a new account archive handler
+func NewAccountArchiveHandler(archiveService *services.AccountArchiveService) *AccountArchiveHandler {
+	return &AccountArchiveHandler{archiveService: archiveService}
+}
+
+func (h *AccountArchiveHandler) accountID(r *http.Request) (uuid.UUID, bool) {
+	val := r.Context().Value(AccountIDKey)
+	if val == nil {
+		return uuid.Nil, false
+	}
+	id, ok := val.(uuid.UUID)
+	return id, ok
+}
+
+// ExportAccount downloads the data of the account: the versioned JSON archive, or the
+// reminders only with ?format=csv
+// @Route: GET /api/account/export
+func (h *AccountArchiveHandler) ExportAccount(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := h.accountID(r)
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, "Account ID not found in context")
+		return
+	}
+
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" {
+		WriteError(w, http.StatusBadRequest, "format must be json or csv")
+		return
+	}
+
+	archive, err := h.archiveService.Export(accountID)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("Error exporting account", "account_id", accountID, "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to export account")
+		return
+	}
+
+	filename := "chronos-export-" + time.Now().UTC().Format("20060102") + "." + format
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Cache-Control", "no-store")
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		if err := services.WriteRemindersCSV(w, archive); err != nil {
+			logging.FromContext(r.Context()).Error("Error writing CSV export", "account_id", accountID, "error", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(archive); err != nil {
+		logging.FromContext(r.Context()).Error("Error writing export", "account_id", accountID, "error", err)
+	}
+}
+
+// ImportAccount restores a JSON archive produced by ExportAccount into the account.
+// ?mode=skip (default), overwrite or replace decides what happens to existing data.
+// @Route: POST /api/account/import
+func (h *AccountArchiveHandler) ImportAccount(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := h.accountID(r)
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, "Account ID not found in context")
+		return
+	}
+
+	mode := services.ArchiveConflictMode(strings.ToLower(r.URL.Query().Get("mode")))
+	if mode == "" {
+		mode = services.ArchiveConflictSkip
+	}
+	if !mode.IsValid() {
+		WriteError(w, http.StatusBadRequest, "mode must be skip, overwrite or replace")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxAccountArchiveSize)
+	defer r.Body.Close()
+
+	archive, err := services.ParseAccountArchive(r.Body)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.archiveService.Import(r.Context(), accountID, archive, mode)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("Error importing archive", "account_id", accountID, "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to import archive")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, report)
+}
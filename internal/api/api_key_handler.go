@@ -2,7 +2,10 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 	"github.com/google/uuid"
@@ -22,7 +25,14 @@ func NewAPIKeyHandler(apiKeyService *services.APIKeyService) *APIKeyHandler {
 
 // CreateAPIKeyRequest represents the request to create a new API key
 type CreateAPIKeyRequest struct {
-	Name string `json:"name"`
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`     // e.g. ["reminders.write", "dfm.read"], defaults to ["reminders.read"]
+	ExpiresAt *time.Time `json:"expires_at"` // optional, the key never expires when omitted
+}
+
+// RotateAPIKeyRequest represents the optional body of a rotation
+type RotateAPIKeyRequest struct {
+	GracePeriodMinutes *int `json:"grace_period_minutes"` // how long the old key keeps working, defaults to 24 hours
 }
 
 // ListAPIKeysResponse represents the response with all API keys
@@ -32,11 +42,40 @@ type ListAPIKeysResponse struct {
 
 // APIKeyResponse represents an API key in responses
 type APIKeyResponse struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	Scopes    string `json:"scopes"`
-	CreatedAt string `json:"created_at"`
-	Key       string `json:"key,omitempty"` // Only populated on creation
+	ID                   string  `json:"id"`
+	Name                 string  `json:"name"`
+	Scopes               string  `json:"scopes"`
+	CreatedAt            string  `json:"created_at"`
+	ExpiresAt            *string `json:"expires_at"`
+	Expired              bool    `json:"expired"`
+	LastUsedAt           *string `json:"last_used_at"`
+	LastUsedIP           *string `json:"last_used_ip"`
+	PreviousKeyExpiresAt *string `json:"previous_key_expires_at,omitempty"` // set while the key replaced by a rotation still works
+	Key                  string  `json:"key,omitempty"`                     // Only populated on creation and rotation
+}
+
+// newAPIKeyResponse converts API key metadata into its response
+func newAPIKeyResponse(metadata *services.APIKeyMetadata) APIKeyResponse {
+	formatTime := func(t *time.Time) *string {
+		if t == nil {
+			return nil
+		}
+		formatted := t.Format("2006-01-02T15:04:05Z07:00")
+		return &formatted
+	}
+
+	return APIKeyResponse{
+		ID:                   metadata.ID,
+		Name:                 metadata.Name,
+		Scopes:               metadata.Scopes,
+		CreatedAt:            metadata.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ExpiresAt:            formatTime(metadata.ExpiresAt),
+		Expired:              metadata.Expired,
+		LastUsedAt:           formatTime(metadata.LastUsed),
+		LastUsedIP:           metadata.LastUsedIP,
+		PreviousKeyExpiresAt: formatTime(metadata.PreviousKeyExpiresAt),
+		Key:                  metadata.Key,
+	}
 }
 
 // CreateAPIKey creates a new API key
@@ -66,9 +105,9 @@ func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create the API key
-	metadata, err := h.apiKeyService.CreateAPIKey(accountID, req.Name)
+	metadata, err := h.apiKeyService.CreateAPIKey(accountID, req.Name, req.Scopes, req.ExpiresAt)
 	if err != nil {
-		if err.Error() == "maximum of 5 API keys per account" {
+		if errors.Is(err, services.ErrInvalidAPIKeyScope) || errors.Is(err, services.ErrInvalidAPIKeyExpiry) || err.Error() == "maximum of 5 API keys per account" {
 			WriteError(w, http.StatusBadRequest, err.Error())
 			return
 		}
@@ -76,15 +115,7 @@ func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp := APIKeyResponse{
-		ID:        metadata.ID,
-		Name:      metadata.Name,
-		Scopes:    metadata.Scopes,
-		CreatedAt: metadata.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		Key:       metadata.Key,
-	}
-
-	WriteJSON(w, http.StatusCreated, resp)
+	WriteJSON(w, http.StatusCreated, newAPIKeyResponse(metadata))
 }
 
 // GetAPIKeys retrieves all API keys for the account
@@ -109,13 +140,8 @@ func (h *APIKeyHandler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
 	}
 
 	responses := make([]APIKeyResponse, len(keys))
-	for i, key := range keys {
-		responses[i] = APIKeyResponse{
-			ID:        key.ID,
-			Name:      key.Name,
-			Scopes:    key.Scopes,
-			CreatedAt: key.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		}
+	for i := range keys {
+		responses[i] = newAPIKeyResponse(&keys[i])
 	}
 
 	WriteJSON(w, http.StatusOK, ListAPIKeysResponse{Keys: responses})
@@ -155,3 +181,53 @@ func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
 		"message": "API key revoked successfully",
 	})
 }
+
+// RotateAPIKey issues a new secret for an API key. The old secret keeps working for
+// grace_period_minutes (24 hours by default, 0 to revoke it immediately).
+// @Route: POST /api/api-keys/{id}/rotate
+func (h *APIKeyHandler) RotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	// Extract account ID from context
+	accountIDVal := r.Context().Value(AccountIDKey)
+	if accountIDVal == nil {
+		WriteError(w, http.StatusUnauthorized, "Account ID not found in context")
+		return
+	}
+
+	accountID, ok := accountIDVal.(uuid.UUID)
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, "Invalid account ID in context")
+		return
+	}
+
+	// The body is optional
+	var req RotateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	grace := services.DefaultAPIKeyRotationGrace
+	if req.GracePeriodMinutes != nil {
+		grace = time.Duration(*req.GracePeriodMinutes) * time.Minute
+	}
+
+	metadata, err := h.apiKeyService.RotateAPIKey(accountID, r.PathValue("id"), grace)
+	if err != nil {
+		if err.Error() == "unauthorized" || err.Error() == "API key not found" {
+			WriteError(w, http.StatusNotFound, "API key not found")
+			return
+		}
+		if err.Error() == "not an API key" {
+			WriteError(w, http.StatusBadRequest, "Not an API key")
+			return
+		}
+		if errors.Is(err, services.ErrInvalidAPIKeyGracePeriod) || errors.Is(err, services.ErrAPIKeyExpired) {
+			WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		WriteError(w, http.StatusInternalServerError, "Failed to rotate API key")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, newAPIKeyResponse(metadata))
+}
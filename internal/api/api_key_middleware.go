@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -11,7 +12,11 @@ import (
 // contextKey is a custom type for context keys
 type contextKeyAPIKeyAuth string
 
-const APIKeyAuthKey contextKeyAPIKeyAuth = "api_key_auth"
+const (
+	APIKeyAuthKey           contextKeyAPIKeyAuth = "api_key_auth"
+	APIKeyScopesKey         contextKeyAPIKeyAuth = "api_key_scopes"          // scopes granted to the key
+	APIKeyRequiredScopesKey contextKeyAPIKeyAuth = "api_key_required_scopes" // scopes declared by the route
+)
 
 // APIKeyAuthMiddleware creates middleware that validates API keys
 func APIKeyAuthMiddleware(apiKeyService *services.APIKeyService) func(http.Handler) http.Handler {
@@ -34,15 +39,21 @@ func APIKeyAuthMiddleware(apiKeyService *services.APIKeyService) func(http.Handl
 			apiKey := parts[1]
 
 			// Validate the API key
-			accountID, err := apiKeyService.ValidateAPIKey(apiKey)
+			identity, err := apiKeyService.AuthenticateAPIKey(apiKey, clientIP(r))
 			if err != nil {
-				WriteError(w, http.StatusUnauthorized, "Invalid API key")
+				writeAPIKeyAuthError(w, err)
+				return
+			}
+
+			scopes := services.ParseAPIKeyScopes(identity.Scopes)
+			if !authorizeAPIKeyScopes(w, r, scopes) {
 				return
 			}
 
 			// Add account ID to request context
-			ctx := context.WithValue(r.Context(), AccountIDKey, accountID)
+			ctx := context.WithValue(r.Context(), AccountIDKey, identity.AccountID)
 			ctx = context.WithValue(ctx, APIKeyAuthKey, true)
+			ctx = context.WithValue(ctx, APIKeyScopesKey, scopes)
 			*r = *r.WithContext(ctx)
 
 			next.ServeHTTP(w, r)
@@ -50,6 +61,15 @@ func APIKeyAuthMiddleware(apiKeyService *services.APIKeyService) func(http.Handl
 	}
 }
 
+// writeAPIKeyAuthError rejects a request whose API key failed to authenticate
+func writeAPIKeyAuthError(w http.ResponseWriter, err error) {
+	if errors.Is(err, services.ErrAPIKeyExpired) {
+		WriteError(w, http.StatusUnauthorized, "API key expired")
+		return
+	}
+	WriteError(w, http.StatusUnauthorized, "Invalid API key")
+}
+
 // IsAPIKeyAuth checks if the request was authenticated via API key
 func IsAPIKeyAuth(r *http.Request) bool {
 	val := r.Context().Value(APIKeyAuthKey)
@@ -59,41 +79,63 @@ func IsAPIKeyAuth(r *http.Request) bool {
 	return val.(bool)
 }
 
-// APIKeyScopeMiddleware validates that API key has the required scope for read operations
-func APIKeyScopeMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Only enforce scope restrictions for API keys
-		if !IsAPIKeyAuth(r) {
-			next.ServeHTTP(w, r)
-			return
-		}
+// APIKeyScopeErrorResponse is returned with a 403 when an API key lacks a scope
+type APIKeyScopeErrorResponse struct {
+	Error          string   `json:"error"`
+	RequiredScopes []string `json:"required_scopes"`
+	GrantedScopes  []string `json:"granted_scopes"`
+}
 
-		// API keys are only allowed for GET requests on specific endpoints
-		if r.Method != http.MethodGet {
-			WriteError(w, http.StatusForbidden, "API keys can only be used for read operations")
-			return
+// RequireAPIKeyScopes opens a route to API keys holding all the given scopes. It must
+// run before the auth middleware, which rejects API keys on routes that do not declare
+// their scopes. Sessions are not affected.
+func RequireAPIKeyScopes(scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		// Without scopes the route stays closed to API keys
+		if len(scopes) == 0 {
+			return next
 		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), APIKeyRequiredScopesKey, scopes)
+			*r = *r.WithContext(ctx)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
 
-		// Check if the endpoint is allowed for API keys
-		path := r.URL.Path
-		allowedPaths := []string{
-			"/api/reminders",           // GET /api/reminders - list all reminders
-			"/api/reminders/",          // GET /api/reminders/{id} - get single reminder
-		}
+// authorizeAPIKeyScopes writes a 403 and returns false when the granted scopes do not
+// cover the scopes the route requires
+func authorizeAPIKeyScopes(w http.ResponseWriter, r *http.Request, granted []string) bool {
+	required, declared := r.Context().Value(APIKeyRequiredScopesKey).([]string)
+	if !declared {
+		WriteJSON(w, http.StatusForbidden, APIKeyScopeErrorResponse{
+			Error:          "API keys cannot be used on this endpoint",
+			RequiredScopes: []string{},
+			GrantedScopes:  granted,
+		})
+		return false
+	}
 
-		allowed := false
-		for _, p := range allowedPaths {
-			if strings.HasPrefix(path, p) {
-				allowed = true
-				break
-			}
+	var missing []string
+	for _, scope := range required {
+		if !services.HasAPIKeyScope(granted, scope) {
+			missing = append(missing, scope)
 		}
+	}
+	if len(missing) > 0 {
+		WriteJSON(w, http.StatusForbidden, APIKeyScopeErrorResponse{
+			Error:          "API key is missing the " + strings.Join(missing, ", ") + " scope",
+			RequiredScopes: required,
+			GrantedScopes:  granted,
+		})
+		return false
+	}
 
-		if !allowed {
-			WriteError(w, http.StatusForbidden, "API key does not have permission for this endpoint")
-			return
-		}
+	return true
+}
 
-		next.ServeHTTP(w, r)
-	})
+// GetAPIKeyScopes returns the scopes of the API key that authenticated the request
+func GetAPIKeyScopes(r *http.Request) []string {
+	scopes, _ := r.Context().Value(APIKeyScopesKey).([]string)
+	return scopes
 }
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 	"github.com/google/uuid"
 )
@@ -41,6 +42,7 @@ type RegisterRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 	Timezone string `json:"timezone"` // IANA timezone identifier (e.g., "America/New_York")
+	Locale   string `json:"locale"`   // optional language (en, fr or es), defaults to Accept-Language
 }
 
 // RegisterResponse represents the registration response payload
@@ -153,6 +155,10 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		Username: req.Username,
 		Password: req.Password,
 		Timezone: req.Timezone,
+		Locale:   req.Locale,
+	}
+	if serviceReq.Locale == "" {
+		serviceReq.Locale = i18n.MatchAcceptLanguage(r.Header.Get("Accept-Language"))
 	}
 
 	// Register the user
@@ -183,7 +189,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	verificationLink := h.webAppURL + "/verify?email=" + req.Email + "&code=" + verificationCode
 
 	// Send verification email
-	_, err = h.verificationService.SendVerificationEmail(req.Email, verificationCode, verificationLink)
+	_, err = h.verificationService.SendVerificationEmail(req.Email, verificationCode, verificationLink, i18n.Resolve(account.Locale))
 	if err != nil {
 		// Log error but don't fail registration
 		WriteError(w, http.StatusInternalServerError, "Failed to send verification email")
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/google/uuid"
+)
+
+// CalendarHandler serves the iCalendar feed of an account and manages its token
+type CalendarHandler struct {
+	calendarFeedService *services.CalendarFeedService
+}
+
+// NewCalendarHandler creates a new calendar handler
+func NewCalendarHandler(calendarFeedService *services.CalendarFeedService) *CalendarHandler {
+	return &CalendarHandler{calendarFeedService: calendarFeedService}
+}
+
+// CalendarTokenResponse is returned when a calendar feed token is created
+type CalendarTokenResponse struct {
+	Token string `json:"token"`
+	URL   string `json:"url"` // feed URL to paste in the calendar client
+}
+
+func (h *CalendarHandler) accountID(r *http.Request) (uuid.UUID, bool) {
+	val := r.Context().Value(AccountIDKey)
+	if val == nil {
+		return uuid.Nil, false
+	}
+	id, ok := val.(uuid.UUID)
+	return id, ok
+}
+
+// CalendarTokenMiddleware authenticates calendar clients with the token carried by the
+// feed URL (?token=cal_...). Requests without a token go through the regular auth
+// middleware so the web app can download the feed with its session.
+func CalendarTokenMiddleware(calendarFeedService *services.CalendarFeedService, authMiddleware func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		authenticated := authMiddleware(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := r.URL.Query().Get("token")
+			if token == "" {
+				authenticated.ServeHTTP(w, r)
+				return
+			}
+
+			accountID, err := calendarFeedService.ValidateToken(token)
+			if err != nil {
+				if !errors.Is(err, services.ErrCalendarTokenInvalid) {
+					logging.FromContext(r.Context()).Error("Error validating calendar token", "error", err)
+				}
+				WriteError(w, http.StatusUnauthorized, "Invalid calendar token")
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), AccountIDKey, accountID)
+			*r = *r.WithContext(ctx)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// GetCalendarFeed returns the reminders of the account as an iCalendar document
+// @Route: GET /api/calendar.ics
+func (h *CalendarHandler) GetCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := h.accountID(r)
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, "Account ID not found in context")
+		return
+	}
+
+	feed, err := h.calendarFeedService.BuildFeed(accountID)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("Error building calendar feed", "account_id", accountID, "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to build calendar feed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="chronos.ics"`)
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(feed))
+}
+
+// CreateCalendarToken creates the calendar feed token of the account. The previous
+// token, if any, stops working.
+// @Route: POST /api/calendar/token
+func (h *CalendarHandler) CreateCalendarToken(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := h.accountID(r)
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, "Account ID not found in context")
+		return
+	}
+
+	token, err := h.calendarFeedService.CreateToken(accountID)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("Error creating calendar token", "account_id", accountID, "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to create calendar token")
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, CalendarTokenResponse{
+		Token: token,
+		URL:   calendarFeedURL(r, token),
+	})
+}
+
+// RevokeCalendarToken disables the calendar feed of the account
+// @Route: DELETE /api/calendar/token
+func (h *CalendarHandler) RevokeCalendarToken(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := h.accountID(r)
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, "Account ID not found in context")
+		return
+	}
+
+	if err := h.calendarFeedService.RevokeToken(accountID); err != nil {
+		logging.FromContext(r.Context()).Error("Error revoking calendar token", "account_id", accountID, "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to revoke calendar token")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "Calendar token revoked successfully",
+	})
+}
+
+// calendarFeedURL builds the subscription URL from the request, behind a proxy too
+func calendarFeedURL(r *http.Request, token string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host + "/api/calendar.ics?token=" + url.QueryEscape(token)
+}
@@ -3,10 +3,10 @@ package api
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"strings"
 
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 )
 
@@ -89,7 +89,7 @@ This message was sent through the Chronos Reminder contact form.
 	})
 
 	if err != nil {
-		log.Printf("[CONTACT] - ❌ Failed to send contact email: %v", err)
+		logging.FromContext(r.Context()).Error("Failed to send contact email", "error", err)
 		WriteError(w, http.StatusInternalServerError, "Failed to send message. Please try again later.")
 		return
 	}
@@ -103,10 +103,10 @@ This message was sent through the Chronos Reminder contact form.
 
 	if err != nil {
 		// Log the error but don't fail the request
-		log.Printf("[CONTACT] - ⚠️  Failed to send confirmation email to %s: %v", req.Email, err)
+		logging.FromContext(r.Context()).Warn("Failed to send contact confirmation email", "email", req.Email, "error", err)
 	}
 
-	log.Printf("[CONTACT] - ✅ Contact form submitted successfully from %s (%s)", req.Email, req.Type)
+	logging.FromContext(r.Context()).Info("Contact form submitted", "email", req.Email, "type", req.Type)
 
 	// Return success response
 	WriteJSON(w, http.StatusOK, map[string]interface{}{
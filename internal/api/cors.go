@@ -18,6 +18,7 @@ var ProtectedRoutes = map[string]bool{
 	"/api/reminders":                             true, // Get all reminders
 	"/api/reminders/{id}":                        true, // Get single reminder
 	"/api/reminders/errors":                      true, // Get reminders with errors
+	"/api/reminders/deliveries":                  true, // Get delivery history
 	"/api/account":                               true, // Get account info
 	"/api/account/identity/app/change-password": true, // Change app identity password
 	// Add more authenticated routes here
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidDestination is returned when a requested destination cannot be used
+var ErrInvalidDestination = errors.New("invalid destination")
+
+// prepareDestination checks a requested destination and fills in the metadata the
+// account can provide (Discord user, Telegram chat, login email, push account). Phone
+// numbers are stored in E.164 format and webhooks get their signing secret, reused from
+// the previous destination posting to the same URL when there is one. It returns the
+// destination with its new plain signing secret, if any; the reminder ID is left for
+// the caller to set. Unusable destinations fail with ErrInvalidDestination.
+func prepareDestination(accountRepo repositories.AccountRepository, accountID uuid.UUID, dest CreateDestinationRequest, previous []models.ReminderDestination) (*models.ReminderDestination, string, error) {
+	destType := models.DestinationType(dest.Type)
+
+	// Validate destination type
+	if !destType.IsValid() {
+		return nil, "", fmt.Errorf("%w: unknown type %q", ErrInvalidDestination, dest.Type)
+	}
+
+	if dest.Metadata == nil {
+		dest.Metadata = models.JSONB{}
+	}
+
+	// Handle discord_dm destination
+	if destType == models.DestinationDiscordDM {
+		// If user_id not provided, get it from the Discord identity
+		if _, exists := dest.Metadata["user_id"]; !exists {
+			account, err := accountRepo.GetWithIdentities(accountID)
+			if err == nil && account != nil {
+				for _, identity := range account.Identities {
+					if identity.Provider == "discord" {
+						dest.Metadata["user_id"] = identity.ExternalID
+						break
+					}
+				}
+			}
+		}
+	}
+
+	// Handle telegram destination
+	if destType == models.DestinationTelegram {
+		// If chat_id not provided, use the first linked Telegram chat
+		if _, exists := dest.Metadata["chat_id"]; !exists {
+			account, err := accountRepo.GetWithIdentities(accountID)
+			if err == nil && account != nil {
+				for _, identity := range account.Identities {
+					if identity.Provider == models.ProviderTelegram {
+						dest.Metadata["chat_id"] = identity.ExternalID
+						break
+					}
+				}
+			}
+		}
+	}
+
+	// Handle sms and voice destinations - store the number in E.164 format
+	if destType == models.DestinationSMS || destType == models.DestinationVoice {
+		if phoneNumber, ok := dest.Metadata["phone_number"].(string); ok {
+			if normalized, valid := models.NormalizePhoneNumber(phoneNumber); valid {
+				dest.Metadata["phone_number"] = normalized
+			}
+		}
+	}
+
+	// Handle email destination
+	if destType == models.DestinationEmail {
+		if _, hasEmail := dest.Metadata["email"]; !hasEmail {
+			// Auto-fill from account-level email
+			acct, err := accountRepo.GetByID(accountID)
+			if err == nil && acct != nil && acct.Email != nil {
+				dest.Metadata["email"] = *acct.Email
+			}
+		}
+	}
+
+	// Handle android_push and web_push destinations - always inject account_id from auth context
+	if destType == models.DestinationAndroidPush || destType == models.DestinationWebPush {
+		dest.Metadata["account_id"] = accountID.String()
+	}
+
+	reminderDest := &models.ReminderDestination{
+		Type:     destType,
+		Metadata: dest.Metadata,
+	}
+
+	// Required fields (user_id, guild_id/channel_id, url, email...) and webhook platform
+	if err := reminderDest.ValidateMetadata(); err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidDestination, err)
+	}
+
+	secret, err := services.PrepareWebhookSigning(reminderDest, previous)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to prepare webhook signing secret: %w", err)
+	}
+
+	return reminderDest, secret, nil
+}
@@ -2,11 +2,11 @@ package api
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 	"github.com/google/uuid"
 )
@@ -139,7 +139,7 @@ func (h *DiscordGuildHandler) GetUserGuilds(w http.ResponseWriter, r *http.Reque
 		// Attempt to refresh the token
 		newAccessToken, newRefreshToken, refreshErr := h.discordOAuthService.RefreshDiscordToken(r.Context(), *discordIdentity.RefreshToken)
 		if refreshErr != nil {
-			fmt.Printf("[GUILD_HANDLER] Error refreshing token: %v\n", refreshErr)
+			logging.FromContext(r.Context()).Error("Error refreshing Discord token", "error", refreshErr)
 			WriteError(w, http.StatusUnauthorized, "Failed to refresh Discord token")
 			return
 		}
@@ -155,7 +155,7 @@ func (h *DiscordGuildHandler) GetUserGuilds(w http.ResponseWriter, r *http.Reque
 	}
 
 	if err != nil {
-		fmt.Printf("[GUILD_HANDLER] Error fetching guilds: %v\n", err)
+		logging.FromContext(r.Context()).Error("Error fetching Discord guilds", "error", err)
 		WriteError(w, http.StatusInternalServerError, "Failed to fetch guilds from Discord: "+err.Error())
 		return
 	}
@@ -237,7 +237,7 @@ func (h *DiscordGuildHandler) GetGuildChannels(w http.ResponseWriter, r *http.Re
 	// Check if bot is in the guild
 	botInGuild, err := h.discordOAuthService.IsBotInGuild(r.Context(), req.GuildID)
 	if err != nil {
-		fmt.Printf("[GUILD_HANDLER] Error checking bot guild membership: %v\n", err)
+		logging.FromContext(r.Context()).Error("Error checking bot guild membership", "error", err)
 		WriteError(w, http.StatusInternalServerError, "Failed to verify bot guild membership")
 		return
 	}
@@ -262,7 +262,7 @@ func (h *DiscordGuildHandler) GetGuildChannels(w http.ResponseWriter, r *http.Re
 		// Attempt to refresh the token
 		newAccessToken, newRefreshToken, refreshErr := h.discordOAuthService.RefreshDiscordToken(r.Context(), *discordIdentity.RefreshToken)
 		if refreshErr != nil {
-			fmt.Printf("[GUILD_HANDLER] Error refreshing token: %v\n", refreshErr)
+			logging.FromContext(r.Context()).Error("Error refreshing Discord token", "error", refreshErr)
 			WriteError(w, http.StatusUnauthorized, "Failed to refresh Discord token")
 			return
 		}
@@ -278,7 +278,7 @@ func (h *DiscordGuildHandler) GetGuildChannels(w http.ResponseWriter, r *http.Re
 	}
 
 	if err != nil {
-		fmt.Printf("[GUILD_HANDLER] Error fetching channels: %v\n", err)
+		logging.FromContext(r.Context()).Error("Error fetching Discord channels", "error", err)
 		WriteError(w, http.StatusInternalServerError, "Failed to fetch channels from Discord: "+err.Error())
 		return
 	}
@@ -359,7 +359,7 @@ func (h *DiscordGuildHandler) GetGuildRoles(w http.ResponseWriter, r *http.Reque
 	// Check if bot is in the guild
 	botInGuild, err := h.discordOAuthService.IsBotInGuild(r.Context(), req.GuildID)
 	if err != nil {
-		fmt.Printf("[GUILD_HANDLER] Error checking bot guild membership: %v\n", err)
+		logging.FromContext(r.Context()).Error("Error checking bot guild membership", "error", err)
 		WriteError(w, http.StatusInternalServerError, "Failed to verify bot guild membership")
 		return
 	}
@@ -384,7 +384,7 @@ func (h *DiscordGuildHandler) GetGuildRoles(w http.ResponseWriter, r *http.Reque
 		// Attempt to refresh the token
 		newAccessToken, newRefreshToken, refreshErr := h.discordOAuthService.RefreshDiscordToken(r.Context(), *discordIdentity.RefreshToken)
 		if refreshErr != nil {
-			fmt.Printf("[GUILD_HANDLER] Error refreshing token: %v\n", refreshErr)
+			logging.FromContext(r.Context()).Error("Error refreshing Discord token", "error", refreshErr)
 			WriteError(w, http.StatusUnauthorized, "Failed to refresh Discord token")
 			return
 		}
@@ -400,7 +400,7 @@ func (h *DiscordGuildHandler) GetGuildRoles(w http.ResponseWriter, r *http.Reque
 	}
 
 	if err != nil {
-		fmt.Printf("[GUILD_HANDLER] Error fetching roles: %v\n", err)
+		logging.FromContext(r.Context()).Error("Error fetching Discord roles", "error", err)
 		WriteError(w, http.StatusInternalServerError, "Failed to fetch roles from Discord: "+err.Error())
 		return
 	}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/ericp/chronos-bot-reminder/internal/config"
 	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 	"github.com/google/uuid"
 )
@@ -140,7 +141,7 @@ func (h *DiscordOAuthHandler) DiscordCallback(w http.ResponseWriter, r *http.Req
 
 	// Check if setup is required (token will be "SETUP_REQUIRED" in that case)
 	if token == "SETUP_REQUIRED" {
-		fmt.Printf("[DISCORD_CALLBACK] Setup required detected, returning setup response for account: %s\n", account.ID)
+		logging.FromContext(r.Context()).Info("Discord setup required", "account_id", account.ID)
 
 		setupResp := OAuthSetupRequiredResponse{
 			Status:          "setup_required",
@@ -150,8 +151,6 @@ func (h *DiscordOAuthHandler) DiscordCallback(w http.ResponseWriter, r *http.Req
 			DiscordUsername: userInfo.Username,
 			NeedsSetup:      true,
 		}
-
-		fmt.Printf("[DISCORD_CALLBACK] Setup response: %+v\n", setupResp)
 		
 		// Send response to all waiters (first request + any duplicates)
 		resultChannel <- &ProcessingResult{Response: setupResp}
@@ -384,7 +383,7 @@ func (h *DiscordOAuthHandler) CompleteDiscordSetup(w http.ResponseWriter, r *htt
 		req.Timezone = "UTC" // Default to UTC
 	}
 
-	fmt.Printf("[DISCORD_SETUP] Request received - Email: %s, Username: %s, Timezone: %s\n", req.Email, req.Username, req.Timezone)
+	logging.FromContext(r.Context()).Debug("Discord setup request received", "username", req.Username, "timezone", req.Timezone)
 
 	// Create app identity for the account
 	token, err := h.discordOAuthService.CreateAppIdentityForDiscordAccount(
@@ -491,7 +490,7 @@ func (h *DiscordOAuthHandler) MergeDiscordAccounts(w http.ResponseWriter, r *htt
 	}
 
 	if err := services.MergeAccounts(r.Context(), h.repos, survivorID, mergedID); err != nil {
-		fmt.Printf("[MERGE] Failed to merge accounts %s <- %s: %v\n", survivorID, mergedID, err)
+		logging.FromContext(r.Context()).Error("Failed to merge accounts", "survivor_account_id", survivorID, "merged_account_id", mergedID, "error", err)
 		WriteError(w, http.StatusInternalServerError, "Failed to merge accounts")
 		return
 	}
@@ -3,7 +3,9 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/ericp/chronos-bot-reminder/internal/services"
@@ -112,6 +114,30 @@ func WriteJSON(w http.ResponseWriter, statusCode int, data interface{}) {
 	json.NewEncoder(w).Encode(data)
 }
 
+// defaultHistoryLimit and maxHistoryLimit bound the history endpoints (deliveries, ...)
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 500
+)
+
+// parseLimitParam reads the "limit" query parameter, falling back to defaultHistoryLimit
+// and capping the value at maxHistoryLimit
+func parseLimitParam(r *http.Request) (int, error) {
+	raw := r.URL.Query().Get("limit")
+	if raw == "" {
+		return defaultHistoryLimit, nil
+	}
+
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("limit must be a positive integer")
+	}
+	if limit > maxHistoryLimit {
+		limit = maxHistoryLimit
+	}
+	return limit, nil
+}
+
 // WriteError writes an error response
 func WriteError(w http.ResponseWriter, statusCode int, message string) {
 	WriteJSON(w, statusCode, map[string]string{
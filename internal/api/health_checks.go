@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/ericp/chronos-bot-reminder/internal/config"
+	"github.com/ericp/chronos-bot-reminder/internal/engine"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/redis/go-redis/v9"
+)
+
+// DatabaseProbe pings Postgres
+func DatabaseProbe(getDB func() (*sql.DB, error)) HealthProbe {
+	return func(ctx context.Context) (ComponentStatus, string) {
+		db, err := getDB()
+		if err != nil {
+			return ComponentDown, err.Error()
+		}
+		if err := db.PingContext(ctx); err != nil {
+			return ComponentDown, err.Error()
+		}
+		return ComponentUp, ""
+	}
+}
+
+// RedisProbe pings Redis
+func RedisProbe(getClient func() *redis.Client) HealthProbe {
+	return func(ctx context.Context) (ComponentStatus, string) {
+		client := getClient()
+		if client == nil {
+			return ComponentDown, "redis is not initialized"
+		}
+		if err := client.Ping(ctx).Err(); err != nil {
+			return ComponentDown, err.Error()
+		}
+		return ComponentUp, ""
+	}
+}
+
+// EngineWorkerProbe checks an engine component. It is standby on the followers, the
+// leader instance runs the engine.
+func EngineWorkerProbe(worker string, statuses func() map[string]engine.WorkerStatus) HealthProbe {
+	return func(ctx context.Context) (ComponentStatus, string) {
+		current := statuses()
+		if current == nil {
+			return ComponentDown, "scheduler service not started"
+		}
+
+		switch current[worker] {
+		case engine.WorkerRunning:
+			return ComponentUp, ""
+		case engine.WorkerStandby:
+			return ComponentStandby, "another instance holds the leadership"
+		case engine.WorkerDisabled:
+			return ComponentDisabled, ""
+		default:
+			return ComponentDown, fmt.Sprintf("%s is not running", worker)
+		}
+	}
+}
+
+// DiscordProbe checks that the Discord gateway connection is up
+func DiscordProbe(getSession func() *discordgo.Session) HealthProbe {
+	return func(ctx context.Context) (ComponentStatus, string) {
+		session := getSession()
+		if session == nil {
+			return ComponentDown, "session not started"
+		}
+
+		session.RLock()
+		ready := session.DataReady
+		session.RUnlock()
+		if !ready {
+			return ComponentDown, "gateway disconnected"
+		}
+		return ComponentUp, fmt.Sprintf("heartbeat %v", session.HeartbeatLatency())
+	}
+}
+
+// MailerProbe checks the mail transport configuration, without sending anything
+func MailerProbe(cfg *config.Config) HealthProbe {
+	return func(ctx context.Context) (ComponentStatus, string) {
+		transport, err := services.NewMailTransportFromConfig(cfg)
+		if errors.Is(err, services.ErrMailNotConfigured) {
+			return ComponentDisabled, err.Error()
+		}
+		if err != nil {
+			return ComponentDown, err.Error()
+		}
+		if transport.Name() == services.MailTransportLog {
+			return ComponentDisabled, "emails are only logged"
+		}
+		return ComponentUp, transport.Name()
+	}
+}
+
+// FcmProbe checks that the Firebase credentials of the Android push are readable
+func FcmProbe(cfg *config.Config) HealthProbe {
+	return func(ctx context.Context) (ComponentStatus, string) {
+		if cfg.GoogleAppCredentials == "" {
+			return ComponentDisabled, "GOOGLE_APPLICATION_CREDENTIALS not set"
+		}
+		if _, err := os.Stat(cfg.GoogleAppCredentials); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return ComponentDown, "credentials file not found"
+			}
+			return ComponentDown, err.Error()
+		}
+		return ComponentUp, ""
+	}
+}
@@ -1,19 +1,71 @@
 package api
 
 import (
+	"context"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/ericp/chronos-bot-reminder/internal/config"
 )
 
+// healthProbeTimeout bounds each component probe so a hanging dependency cannot hang the health check
+const healthProbeTimeout = 2 * time.Second
+
+// ComponentStatus is the state of a component reported by the health checks
+type ComponentStatus string
+
+// Component statuses, every status but down is healthy
+const (
+	ComponentUp       ComponentStatus = "up"
+	ComponentDown     ComponentStatus = "down"
+	ComponentStandby  ComponentStatus = "standby"  // runs on another instance
+	ComponentDisabled ComponentStatus = "disabled" // optional and not configured
+)
+
+// HealthProbe checks a component. The detail explains the status (error, transport, ...).
+type HealthProbe func(ctx context.Context) (ComponentStatus, string)
+
+// HealthCheck is a component checked by the readiness probe
+type HealthCheck struct {
+	Name  string
+	Probe HealthProbe
+	// Critical components make the instance unready (503) when down, the others only degrade it
+	Critical bool
+	// Liveness components are also checked by the liveness probe: only a restart fixes them
+	Liveness bool
+}
+
+// ComponentHealth is the result of a probe
+type ComponentHealth struct {
+	Status    ComponentStatus `json:"status"`
+	Critical  bool            `json:"critical"`
+	LatencyMs float64         `json:"latency_ms"`
+	Detail    string          `json:"detail,omitempty"`
+}
+
+// Overall health statuses
+const (
+	healthOK          = "ok"
+	healthDegraded    = "degraded"
+	healthUnavailable = "unavailable"
+)
+
 // HealthHandler handles health check requests
-type HealthHandler struct{}
+type HealthHandler struct {
+	checks []HealthCheck
+}
 
 // NewHealthHandler creates a new health handler
 func NewHealthHandler() *HealthHandler {
 	return &HealthHandler{}
 }
 
+// AddCheck adds a component to the readiness (and, if set, liveness) probes
+func (h *HealthHandler) AddCheck(check HealthCheck) {
+	h.checks = append(h.checks, check)
+}
+
 // Root handles GET / and identifies the service.
 func (h *HealthHandler) Root(w http.ResponseWriter, r *http.Request) {
 	WriteJSON(w, http.StatusOK, map[string]interface{}{
@@ -38,3 +90,87 @@ func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
 		"version": config.Version,
 	})
 }
+
+// Live tells whether the process works. The external dependencies are left to the
+// readiness probe since restarting the instance does not fix a Postgres or Redis outage.
+// @Summary Liveness probe
+// @Description Checks the engine components of the instance, 503 when one of them died
+// @Tags Health
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Instance is alive"
+// @Failure 503 {object} map[string]interface{} "A component needs a restart"
+// @Router /api/health/live [get]
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	var checks []HealthCheck
+	for _, check := range h.checks {
+		if check.Liveness {
+			check.Critical = true
+			checks = append(checks, check)
+		}
+	}
+	h.writeReport(w, r, checks)
+}
+
+// Ready tells whether the instance can serve: every component is probed with its latency
+// @Summary Readiness probe
+// @Description Probes the database, Redis, the engine, the Discord gateway and the delivery providers, 503 when a critical one is down
+// @Tags Health
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Instance is ready, possibly degraded"
+// @Failure 503 {object} map[string]interface{} "A critical component is down"
+// @Router /api/health/ready [get]
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	h.writeReport(w, r, h.checks)
+}
+
+// writeReport runs the probes concurrently and answers 503 when a critical component is down
+func (h *HealthHandler) writeReport(w http.ResponseWriter, r *http.Request, checks []HealthCheck) {
+	components := make(map[string]ComponentHealth, len(checks))
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		wg.Add(1)
+		go func(check HealthCheck) {
+			defer wg.Done()
+			result := runProbe(r.Context(), check)
+			mutex.Lock()
+			components[check.Name] = result
+			mutex.Unlock()
+		}(check)
+	}
+	wg.Wait()
+
+	status, statusCode := healthOK, http.StatusOK
+	for _, component := range components {
+		if component.Status != ComponentDown {
+			continue
+		}
+		if component.Critical {
+			status, statusCode = healthUnavailable, http.StatusServiceUnavailable
+			break
+		}
+		status = healthDegraded
+	}
+
+	WriteJSON(w, statusCode, map[string]interface{}{
+		"status":     status,
+		"service":    "chronos-reminder-api",
+		"version":    config.Version,
+		"components": components,
+	})
+}
+
+// runProbe runs a probe within its timeout and measures it
+func runProbe(ctx context.Context, check HealthCheck) ComponentHealth {
+	ctx, cancel := context.WithTimeout(ctx, healthProbeTimeout)
+	defer cancel()
+
+	startedAt := time.Now()
+	status, detail := check.Probe(ctx)
+	return ComponentHealth{
+		Status:    status,
+		Critical:  check.Critical,
+		LatencyMs: float64(time.Since(startedAt).Microseconds()) / 1000,
+		Detail:    detail,
+	}
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+)
+
+// LogLevelsHandler changes the log level of the subsystems at runtime
+type LogLevelsHandler struct {
+	logger *slog.Logger
+}
+
+// NewLogLevelsHandler creates a new log levels handler
+func NewLogLevelsHandler() *LogLevelsHandler {
+	return &LogLevelsHandler{logger: logging.For(logging.API)}
+}
+
+// GetLogLevels returns the level of each subsystem
+// @Summary Get the log levels
+// @Description Returns the current log level of each subsystem (app, engine, dispatcher, api, bot)
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]string "Level per subsystem"
+// @Failure 401 {object} map[string]interface{} "Invalid admin token"
+// @Router /api/admin/log-levels [get]
+func (h *LogLevelsHandler) GetLogLevels(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, currentLogLevels())
+}
+
+// UpdateLogLevels changes the level of the given subsystems, the others keep theirs
+// @Summary Update the log levels
+// @Description Sets the level (debug, info, warn or error) of the given subsystems. Nothing changes when one of them is invalid.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param levels body map[string]string true "Level per subsystem, e.g. {\"engine\": \"debug\"}"
+// @Success 200 {object} map[string]string "Level per subsystem"
+// @Failure 400 {object} map[string]interface{} "Unknown subsystem or level"
+// @Failure 401 {object} map[string]interface{} "Invalid admin token"
+// @Router /api/admin/log-levels [put]
+func (h *LogLevelsHandler) UpdateLogLevels(w http.ResponseWriter, r *http.Request) {
+	var req map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate every entry before applying any
+	levels := make(map[logging.Subsystem]slog.Level, len(req))
+	for name, value := range req {
+		subsystem, err := logging.ParseSubsystem(name)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		level, err := logging.ParseLevel(value)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		levels[subsystem] = level
+	}
+
+	for subsystem, level := range levels {
+		logging.SetLevel(subsystem, level)
+		h.logger.InfoContext(r.Context(), "Log level changed", "target_subsystem", subsystem, "level", level)
+	}
+
+	WriteJSON(w, http.StatusOK, currentLogLevels())
+}
+
+// AdminTokenMiddleware protects the admin endpoints, which are only registered with a token
+func AdminTokenMiddleware(token string) func(http.Handler) http.Handler {
+	return bearerTokenMiddleware(token, "Invalid admin token")
+}
+
+func currentLogLevels() map[string]string {
+	current := map[string]string{}
+	for subsystem, level := range logging.Levels() {
+		current[string(subsystem)] = strings.ToLower(level.String())
+	}
+	return current
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+)
+
+// RequestIDHeader carries the correlation ID of a request, in both directions
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the request IDs accepted from the clients
+const maxRequestIDLength = 64
+
+// RequestLoggingMiddleware gives each request a correlation ID, taken from the
+// X-Request-ID header of the client when it is usable, and logs the request once
+// answered, as an error for the 5xx. The handlers find the request logger with
+// logging.FromContext.
+func RequestLoggingMiddleware(wm *WrappedMux) func(http.Handler) http.Handler {
+	logger := logging.For(logging.API)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(RequestIDHeader)
+			if !isValidRequestID(requestID) {
+				requestID = logging.NewCorrelationID()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			requestLogger := logger.With("correlation_id", requestID)
+			ctx := logging.WithCorrelationID(r.Context(), requestID)
+			ctx = logging.WithLogger(ctx, requestLogger)
+			r = r.WithContext(ctx)
+
+			route := unmatchedRoute
+			if _, pattern := wm.mux.Handler(r); pattern != "" {
+				route = pattern
+			}
+
+			startedAt := time.Now()
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			level := slog.LevelInfo
+			if recorder.status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			requestLogger.Log(ctx, level, "Request served",
+				"method", r.Method,
+				"route", route,
+				"status", recorder.status,
+				"duration", time.Since(startedAt),
+			)
+		})
+	}
+}
+
+// isValidRequestID accepts the short printable ASCII IDs, anything else is replaced
+// so a client cannot inject content in the logs
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < '!' || requestID[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/config"
+	"github.com/ericp/chronos-bot-reminder/internal/metrics"
+)
+
+// unmatchedRoute labels the requests no route matched, so unknown paths cannot blow up the label set
+const unmatchedRoute = "unmatched"
+
+// otherMethod labels the requests with a non-standard method, the method is chosen by the client
+const otherMethod = "OTHER"
+
+// standardMethods are the HTTP methods recorded under their own label
+var standardMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodConnect: true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// statusRecorder captures the status code written by the handlers
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// MetricsMiddleware records the requests per route pattern of the mux rather than per
+// path, so /api/reminders/{id} is a single series whatever the ID
+func MetricsMiddleware(wm *WrappedMux) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := unmatchedRoute
+			if _, pattern := wm.mux.Handler(r); pattern != "" {
+				route = pattern
+			}
+
+			startedAt := time.Now()
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			method := r.Method
+			if !standardMethods[method] {
+				method = otherMethod
+			}
+			metrics.ObserveHTTPRequest(route, method, recorder.status, time.Since(startedAt))
+		})
+	}
+}
+
+// ErrMetricsTokenRequired is returned when the metrics endpoint would be public outside DEV
+var ErrMetricsTokenRequired = errors.New("METRICS_TOKEN is required to serve /metrics outside DEV")
+
+// NewMetricsHandler returns the Prometheus scrape handler behind the metrics token.
+// Only DEV serves it without a token: the metrics expose the routes and the load of
+// the instance.
+func NewMetricsHandler(cfg *config.Config) (http.Handler, error) {
+	if cfg.MetricsToken == "" && cfg.Environment != "DEV" {
+		return nil, ErrMetricsTokenRequired
+	}
+	return MetricsTokenMiddleware(cfg.MetricsToken)(metrics.Handler()), nil
+}
+
+// MetricsTokenMiddleware protects the metrics endpoint with a bearer token when one is configured
+func MetricsTokenMiddleware(token string) func(http.Handler) http.Handler {
+	return bearerTokenMiddleware(token, "Invalid metrics token")
+}
+
+// bearerTokenMiddleware compares the bearer token in constant time, an empty token lets everything through
+func bearerTokenMiddleware(token string, message string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if token == "" {
+			return next
+		}
+		expected := []byte("Bearer " + token)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+				WriteError(w, http.StatusUnauthorized, message)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/google/uuid"
+)
+
+// PhoneNumberHandler handles the phone numbers of SMS and voice destinations
+type PhoneNumberHandler struct {
+	telephonyService *services.TelephonyService
+}
+
+// NewPhoneNumberHandler creates a new phone number handler
+func NewPhoneNumberHandler(telephonyService *services.TelephonyService) *PhoneNumberHandler {
+	return &PhoneNumberHandler{telephonyService: telephonyService}
+}
+
+// PhoneNumberRequest represents a request to register a phone number
+type PhoneNumberRequest struct {
+	PhoneNumber string `json:"phone_number"` // E.164, e.g. +33612345678
+}
+
+// VerifyPhoneNumberRequest represents a request to confirm a phone number with its code
+type VerifyPhoneNumberRequest struct {
+	PhoneNumber string `json:"phone_number"`
+	Code        string `json:"code"`
+}
+
+// GetPhoneNumbers lists the phone numbers registered by the account
+// @Route: GET /api/phone-numbers
+func (h *PhoneNumberHandler) GetPhoneNumbers(w http.ResponseWriter, r *http.Request) {
+	accountID := r.Context().Value(AccountIDKey).(uuid.UUID)
+
+	phoneNumbers, err := h.telephonyService.PhoneNumbers(accountID)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch phone numbers")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, phoneNumbers)
+}
+
+// AddPhoneNumber registers a phone number and texts it a verification code
+// @Route: POST /api/phone-numbers
+func (h *PhoneNumberHandler) AddPhoneNumber(w http.ResponseWriter, r *http.Request) {
+	accountID := r.Context().Value(AccountIDKey).(uuid.UUID)
+
+	var req PhoneNumberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	phoneNumber, err := h.telephonyService.StartPhoneVerification(r.Context(), accountID, req.PhoneNumber)
+	if err != nil {
+		writePhoneNumberError(w, r, err, "Failed to send verification code")
+		return
+	}
+
+	WriteJSON(w, http.StatusAccepted, phoneNumber)
+}
+
+// VerifyPhoneNumber confirms a phone number with the code it received
+// @Route: POST /api/phone-numbers/verify
+func (h *PhoneNumberHandler) VerifyPhoneNumber(w http.ResponseWriter, r *http.Request) {
+	accountID := r.Context().Value(AccountIDKey).(uuid.UUID)
+
+	var req VerifyPhoneNumberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	phoneNumber, err := h.telephonyService.ConfirmPhoneVerification(r.Context(), accountID, req.PhoneNumber, req.Code)
+	if err != nil {
+		writePhoneNumberError(w, r, err, "Failed to verify phone number")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, phoneNumber)
+}
+
+// DeletePhoneNumber removes a phone number, its destinations stop receiving reminders
+// @Route: DELETE /api/phone-numbers/{number}
+func (h *PhoneNumberHandler) DeletePhoneNumber(w http.ResponseWriter, r *http.Request) {
+	accountID := r.Context().Value(AccountIDKey).(uuid.UUID)
+
+	if err := h.telephonyService.RemovePhoneNumber(accountID, r.PathValue("number")); err != nil {
+		writePhoneNumberError(w, r, err, "Failed to delete phone number")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Phone number deleted"})
+}
+
+// GetUsage returns the SMS and voice calls sent this month against the quotas
+// @Route: GET /api/phone-numbers/usage
+func (h *PhoneNumberHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	accountID := r.Context().Value(AccountIDKey).(uuid.UUID)
+
+	usage, err := h.telephonyService.Usage(accountID)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch usage")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, usage)
+}
+
+// writePhoneNumberError maps the telephony errors to HTTP responses
+func writePhoneNumberError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrTelephonyNotConfigured):
+		WriteError(w, http.StatusServiceUnavailable, "SMS and voice are not configured")
+	case errors.Is(err, services.ErrInvalidPhoneNumber),
+		errors.Is(err, services.ErrInvalidPhoneVerificationCode),
+		errors.Is(err, services.ErrPhoneNumberAlreadyVerified):
+		WriteError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrPhoneNumberNotFound):
+		WriteError(w, http.StatusNotFound, "Phone number not found")
+	case errors.Is(err, services.ErrPhoneVerificationCooldown),
+		errors.Is(err, services.ErrTooManyPhoneVerificationAttempts),
+		errors.Is(err, services.ErrTelephonyQuotaExceeded):
+		WriteError(w, http.StatusTooManyRequests, err.Error())
+	default:
+		logging.FromContext(r.Context()).Error(fallback, "error", err)
+		WriteError(w, http.StatusInternalServerError, fallback)
+	}
+}
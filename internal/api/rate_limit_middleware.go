@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 )
 
@@ -26,7 +27,7 @@ func RateLimitMiddleware(rateLimiter *services.RateLimiterService) func(http.Han
 			allowed, remaining, resetTime, err := rateLimiter.CheckRateLimit(r.Context(), sessionID)
 			if err != nil {
 				// Log error but allow request to proceed (fail open)
-				fmt.Printf("[RATE_LIMIT] Error checking rate limit: %v\n", err)
+				logging.FromContext(r.Context()).Error("Error checking rate limit", "error", err)
 				next.ServeHTTP(w, r)
 				return
 			}
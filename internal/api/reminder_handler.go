@@ -2,12 +2,14 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
 	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 	"github.com/google/uuid"
 )
@@ -17,6 +19,7 @@ type ReminderHandler struct {
 	reminderRepo      repositories.ReminderRepository
 	destinationRepo   repositories.ReminderDestinationRepository
 	reminderErrorRepo repositories.ReminderErrorRepository
+	deliveryRepo      repositories.ReminderDeliveryRepository
 	accountRepo       repositories.AccountRepository
 	timezoneRepo      repositories.TimezoneRepository
 }
@@ -44,6 +47,11 @@ func (h *ReminderHandler) SetTimezoneRepository(repo repositories.TimezoneReposi
 	h.timezoneRepo = repo
 }
 
+// SetReminderDeliveryRepository sets the reminder delivery repository
+func (h *ReminderHandler) SetReminderDeliveryRepository(repo repositories.ReminderDeliveryRepository) {
+	h.deliveryRepo = repo
+}
+
 // GetReminder retrieves a single reminder by ID
 func (h *ReminderHandler) GetReminder(w http.ResponseWriter, r *http.Request) {
 	accountID := r.Context().Value(AccountIDKey).(uuid.UUID)
@@ -111,14 +119,12 @@ func (h *ReminderHandler) UpdateReminder(w http.ResponseWriter, r *http.Request)
 
 	// Parse request body
 	var updateData struct {
-		Message      string          `json:"message"`
-		Date         string          `json:"date"`
-		Time         string          `json:"time"`
-		Recurrence   json.RawMessage `json:"recurrence"`
-		Destinations []struct {
-			Type     string                 `json:"type"`
-			Metadata map[string]interface{} `json:"metadata"`
-		} `json:"destinations"`
+		Message      string                     `json:"message"`
+		Date         string                     `json:"date"`
+		Time         string                     `json:"time"`
+		Recurrence   json.RawMessage            `json:"recurrence"`
+		RRule        *string                    `json:"rrule"`
+		Destinations []CreateDestinationRequest `json:"destinations"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&updateData); err != nil {
@@ -140,28 +146,16 @@ func (h *ReminderHandler) UpdateReminder(w http.ResponseWriter, r *http.Request)
 		}
 		reminder.RemindAtUTC = parsedTime.UTC()
 		reminder.NextFireUTC = &parsedTime
+		// The new date starts a new series
+		reminder.RecurrenceStartUTC = &reminder.RemindAtUTC
 	}
 
 	// Handle recurrence - can be string or int
 	if len(updateData.Recurrence) > 0 {
-		var recurrenceValue int
-
-		// Try to parse as string first (new format: "DAILY", "WEEKLY", etc.)
-		var recurrenceStr string
-		if err := json.Unmarshal(updateData.Recurrence, &recurrenceStr); err == nil {
-			// It's a string, convert using the map
-			if val, exists := services.RecurrenceTypeMap[recurrenceStr]; exists {
-				recurrenceValue = val
-			} else {
-				WriteError(w, http.StatusBadRequest, "Invalid recurrence type")
-				return
-			}
-		} else {
-			// Try to parse as int (legacy format)
-			if err := json.Unmarshal(updateData.Recurrence, &recurrenceValue); err != nil {
-				WriteError(w, http.StatusBadRequest, "Invalid recurrence format")
-				return
-			}
+		recurrenceValue, err := parseRecurrenceValue(updateData.Recurrence)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, err.Error())
+			return
 		}
 
 		if recurrenceValue >= 0 {
@@ -169,51 +163,55 @@ func (h *ReminderHandler) UpdateReminder(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	// Update destinations if provided
-	if len(updateData.Destinations) > 0 {
-		// Delete old destinations
-		if err := h.destinationRepo.DeleteByReminderID(id); err != nil {
-			WriteError(w, http.StatusInternalServerError, "Failed to update destinations")
+	// Re-anchor the custom rule when it, the date or the recurrence changed
+	if updateData.RRule != nil || len(updateData.Recurrence) > 0 || (updateData.Date != "" && updateData.Time != "") {
+		rule := ""
+		if updateData.RRule != nil {
+			rule = *updateData.RRule
+		} else if reminder.RRule != nil && services.GetRecurrenceType(int(reminder.Recurrence)) == services.RecurrenceRRule {
+			rule = *reminder.RRule
+		}
+		if err := services.ApplyRRule(reminder, rule, account.Timezone.IANALocation); err != nil {
+			WriteError(w, http.StatusBadRequest, err.Error())
 			return
 		}
+	}
 
-		effectiveRecurrence := int(reminder.Recurrence)
-
-		// Create new destinations
+	// Update destinations if provided, they are all checked before the old ones are replaced
+	newSecrets := make(map[uuid.UUID]string)
+	if len(updateData.Destinations) > 0 {
 		newDestinations := make([]models.ReminderDestination, len(updateData.Destinations))
 		for i, dest := range updateData.Destinations {
-			destType := models.DestinationType(dest.Type)
-			if !destType.IsValid() {
-				WriteError(w, http.StatusBadRequest, "Invalid destination type")
+			// Webhooks posting to the same URL keep their signing secret
+			reminderDest, secret, err := prepareDestination(h.accountRepo, accountID, dest, reminder.Destinations)
+			if errors.Is(err, ErrInvalidDestination) {
+				WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid destination at index %d: %v", i, err))
 				return
 			}
-
-			if destType == models.DestinationEmail {
-				if _, hasEmail := dest.Metadata["email"]; !hasEmail {
-					WriteError(w, http.StatusBadRequest, fmt.Sprintf("Email destination requires email in metadata"))
-					return
-				}
-				if services.GetRecurrenceType(effectiveRecurrence) == services.RecurrenceHourly {
-					WriteError(w, http.StatusBadRequest, "Email destination cannot be used with hourly recurrence")
-					return
-				}
+			if err != nil {
+				WriteError(w, http.StatusInternalServerError, "Failed to prepare webhook signing secret")
+				return
 			}
 
-			if destType == models.DestinationAndroidPush {
-				if dest.Metadata == nil {
-					dest.Metadata = map[string]interface{}{}
-				}
-				dest.Metadata["account_id"] = accountID.String()
+			if reminderDest.Type == models.DestinationEmail && services.IsHourlyReminder(reminder) {
+				WriteError(w, http.StatusBadRequest, "Email destination cannot be used with hourly recurrence")
+				return
 			}
 
-			newDestinations[i] = models.ReminderDestination{
-				ID:         uuid.New(),
-				ReminderID: id,
-				Type:       destType,
-				Metadata:   models.JSONB(dest.Metadata),
+			reminderDest.ID = uuid.New()
+			reminderDest.ReminderID = id
+			newDestinations[i] = *reminderDest
+			if secret != "" {
+				newSecrets[reminderDest.ID] = secret
 			}
 		}
 
+		// Delete old destinations
+		if err := h.destinationRepo.DeleteByReminderID(id); err != nil {
+			WriteError(w, http.StatusInternalServerError, "Failed to update destinations")
+			return
+		}
+
 		if err := h.destinationRepo.CreateMultiple(newDestinations); err != nil {
 			WriteError(w, http.StatusInternalServerError, "Failed to create destinations")
 			return
@@ -223,12 +221,19 @@ func (h *ReminderHandler) UpdateReminder(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Save reminder
-	if err := h.reminderRepo.Update(reminder, true); err != nil {
+	if err := repositories.ReminderRepositoryWithContext(r.Context(), h.reminderRepo).Update(reminder, true); err != nil {
 		WriteError(w, http.StatusInternalServerError, "Failed to update reminder")
 		return
 	}
+	logging.FromContext(r.Context()).Info("Reminder updated", "reminder_id", reminder.ID, "account_id", accountID)
 
-	WriteJSON(w, http.StatusOK, ToReminderResponse(reminder))
+	// The signing secrets of new webhooks are shown this once
+	response := ToReminderResponse(reminder)
+	for i := range response.Destinations {
+		response.Destinations[i].SigningSecret = newSecrets[response.Destinations[i].ID]
+	}
+
+	WriteJSON(w, http.StatusOK, response)
 }
 
 // DeleteReminder deletes a reminder
@@ -261,10 +266,11 @@ func (h *ReminderHandler) DeleteReminder(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Delete reminder
-	if err := h.reminderRepo.Delete(id, true); err != nil {
+	if err := repositories.ReminderRepositoryWithContext(r.Context(), h.reminderRepo).Delete(id, true); err != nil {
 		WriteError(w, http.StatusInternalServerError, "Failed to delete reminder")
 		return
 	}
+	logging.FromContext(r.Context()).Info("Reminder deleted", "reminder_id", id, "account_id", accountID)
 
 	WriteJSON(w, http.StatusOK, map[string]string{"message": "Reminder deleted successfully"})
 }
@@ -295,10 +301,11 @@ func (h *ReminderHandler) PauseReminder(w http.ResponseWriter, r *http.Request)
 	const pauseBit = 128
 	reminder.Recurrence |= pauseBit
 
-	if err := h.reminderRepo.Update(reminder, true); err != nil {
+	if err := repositories.ReminderRepositoryWithContext(r.Context(), h.reminderRepo).Update(reminder, true); err != nil {
 		WriteError(w, http.StatusInternalServerError, "Failed to pause reminder")
 		return
 	}
+	logging.FromContext(r.Context()).Info("Reminder paused", "reminder_id", reminder.ID, "account_id", accountID)
 
 	WriteJSON(w, http.StatusOK, ToReminderResponse(reminder))
 }
@@ -329,10 +336,11 @@ func (h *ReminderHandler) ResumeReminder(w http.ResponseWriter, r *http.Request)
 	const pauseBit = 128
 	reminder.Recurrence &= ^pauseBit
 
-	if err := h.reminderRepo.Update(reminder, true); err != nil {
+	if err := repositories.ReminderRepositoryWithContext(r.Context(), h.reminderRepo).Update(reminder, true); err != nil {
 		WriteError(w, http.StatusInternalServerError, "Failed to resume reminder")
 		return
 	}
+	logging.FromContext(r.Context()).Info("Reminder resumed", "reminder_id", reminder.ID, "account_id", accountID)
 
 	WriteJSON(w, http.StatusOK, ToReminderResponse(reminder))
 }
@@ -362,21 +370,24 @@ func (h *ReminderHandler) DuplicateReminder(w http.ResponseWriter, r *http.Reque
 
 	// Create new reminder
 	newReminder := &models.Reminder{
-		ID:             uuid.New(),
-		AccountID:      original.AccountID,
-		RemindAtUTC:    original.RemindAtUTC,
-		Message:        original.Message,
-		Recurrence:     original.Recurrence,
-		CreatedAt:      time.Now().UTC(),
-		NextFireUTC:    original.NextFireUTC,
-		SnoozedAtUTC:   original.SnoozedAtUTC,
+		ID:                 uuid.New(),
+		AccountID:          original.AccountID,
+		RemindAtUTC:        original.RemindAtUTC,
+		Message:            original.Message,
+		Recurrence:         original.Recurrence,
+		RRule:              original.RRule,
+		RecurrenceStartUTC: original.RecurrenceStartUTC,
+		CreatedAt:          time.Now().UTC(),
+		NextFireUTC:        original.NextFireUTC,
+		SnoozedAtUTC:       original.SnoozedAtUTC,
 	}
 
 	// Create reminder
-	if err := h.reminderRepo.Create(newReminder, true); err != nil {
+	if err := repositories.ReminderRepositoryWithContext(r.Context(), h.reminderRepo).Create(newReminder, true); err != nil {
 		WriteError(w, http.StatusInternalServerError, "Failed to duplicate reminder")
 		return
 	}
+	logging.FromContext(r.Context()).Info("Reminder duplicated", "reminder_id", newReminder.ID, "source_reminder_id", original.ID, "account_id", accountID)
 
 	// Duplicate destinations
 	if len(original.Destinations) > 0 {
@@ -438,6 +449,116 @@ func (h *ReminderHandler) SnoozeReminder(w http.ResponseWriter, r *http.Request)
 		WriteError(w, http.StatusInternalServerError, "Failed to snooze reminder")
 		return
 	}
+	logging.FromContext(r.Context()).Info("Reminder snoozed", "reminder_id", id, "account_id", accountID, "snooze_until", snoozeUntil)
 
 	WriteJSON(w, http.StatusOK, map[string]string{"message": fmt.Sprintf("Snoozed for %d minutes", req.Minutes)})
 }
+
+// GetReminderDeliveries returns the delivery history of a reminder, newest first
+// @Route: GET /api/reminders/{id}/deliveries
+func (h *ReminderHandler) GetReminderDeliveries(w http.ResponseWriter, r *http.Request) {
+	accountID := r.Context().Value(AccountIDKey).(uuid.UUID)
+	reminderID := r.PathValue("id")
+
+	id, err := uuid.Parse(reminderID)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid reminder ID")
+		return
+	}
+
+	limit, err := parseLimitParam(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Deliveries outlive one-time reminders, so they are looked up by account
+	// rather than through the (possibly deleted) reminder
+	deliveries, err := h.deliveryRepo.GetByAccountAndReminder(accountID, id, limit)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "Failed to retrieve deliveries")
+		return
+	}
+
+	// Without deliveries, only a reminder of the account has an (empty) history
+	if len(deliveries) == 0 {
+		reminder, err := h.reminderRepo.GetByID(id)
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, "Failed to fetch reminder")
+			return
+		}
+		if reminder == nil || reminder.AccountID != accountID {
+			WriteError(w, http.StatusNotFound, "Reminder not found")
+			return
+		}
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"deliveries": deliveries,
+		"count":      len(deliveries),
+	})
+}
+
+// RotateWebhookSecret replaces the signing secret of a webhook destination and returns
+// the new one. Requests are signed with it right away.
+// @Route: POST /api/reminders/{id}/destinations/{destinationId}/rotate-secret
+func (h *ReminderHandler) RotateWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	accountID := r.Context().Value(AccountIDKey).(uuid.UUID)
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid reminder ID")
+		return
+	}
+	destinationID, err := uuid.Parse(r.PathValue("destinationId"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid destination ID")
+		return
+	}
+
+	reminder, err := h.reminderRepo.GetWithDestinations(id)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch reminder")
+		return
+	}
+
+	if reminder == nil || reminder.AccountID != accountID {
+		WriteError(w, http.StatusNotFound, "Reminder not found")
+		return
+	}
+
+	var destination *models.ReminderDestination
+	for i := range reminder.Destinations {
+		if reminder.Destinations[i].ID == destinationID {
+			destination = &reminder.Destinations[i]
+			break
+		}
+	}
+
+	if destination == nil {
+		WriteError(w, http.StatusNotFound, "Destination not found")
+		return
+	}
+
+	if destination.Type != models.DestinationWebhook {
+		WriteError(w, http.StatusBadRequest, "Only webhook destinations have a signing secret")
+		return
+	}
+
+	secret, err := services.SetWebhookSigningSecret(destination)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("Error rotating signing secret", "destination_id", destinationID, "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to rotate signing secret")
+		return
+	}
+
+	if err := h.destinationRepo.Update(destination); err != nil {
+		logging.FromContext(r.Context()).Error("Error saving signing secret", "destination_id", destinationID, "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to rotate signing secret")
+		return
+	}
+
+	response := ToDestinationResponse(*destination)
+	response.SigningSecret = secret
+	WriteJSON(w, http.StatusOK, response)
+}
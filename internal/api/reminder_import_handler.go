@@ -0,0 +1,269 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/google/uuid"
+)
+
+// maxICalImportSize is the largest iCalendar upload accepted
+const maxICalImportSize = 1 << 20
+
+// Status of an entry in an import report
+const (
+	ImportStatusCreated     = "created"
+	ImportStatusWouldCreate = "would_create" // dry run
+	ImportStatusSkipped     = "skipped"
+	ImportStatusUnsupported = "unsupported"
+	ImportStatusFailed      = "failed" // the entry is valid but could not be saved
+)
+
+// ImportRemindersResponse reports what an iCalendar import did, entry by entry
+type ImportRemindersResponse struct {
+	DryRun      bool                 `json:"dry_run"`
+	Created     int                  `json:"created"` // reminders created, or that would be in a dry run
+	Skipped     int                  `json:"skipped"`
+	Unsupported int                  `json:"unsupported"`
+	Failed      int                  `json:"failed"`
+	Items       []ImportReminderItem `json:"items"`
+}
+
+// ImportReminderItem is the outcome of one VEVENT or VTODO
+type ImportReminderItem struct {
+	Index          int        `json:"index"`
+	UID            string     `json:"uid,omitempty"`
+	Summary        string     `json:"summary,omitempty"`
+	Status         string     `json:"status"`
+	Reason         string     `json:"reason,omitempty"`
+	Warnings       []string   `json:"warnings,omitempty"`
+	ReminderID     *string    `json:"reminder_id,omitempty"`
+	RemindAtUTC    *time.Time `json:"remind_at_utc,omitempty"`
+	RecurrenceType string     `json:"recurrence_type,omitempty"`
+	RRule          *string    `json:"rrule,omitempty"`
+
+	// The signing secrets of the new webhook destinations are shown this once
+	Destinations []DestinationResponse `json:"destinations,omitempty"`
+}
+
+// ImportReminders creates reminders from an iCalendar upload, either a multipart form
+// (file, optional destinations JSON array and dry_run fields) or a raw text/calendar
+// body. Every entry goes to the given destinations, the Discord DM of the account by
+// default. With dry_run nothing is saved and the report previews the import.
+// @Route: POST /api/reminders/import
+func (h *UserHandler) ImportReminders(w http.ResponseWriter, r *http.Request) {
+	accountID, err := h.extractAccountIDFromToken(r)
+	if err != nil {
+		WriteError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	account, err := h.accountRepo.GetWithTimezone(accountID)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "Failed to retrieve account")
+		return
+	}
+	if account == nil {
+		WriteError(w, http.StatusNotFound, "Account not found")
+		return
+	}
+	if account.Timezone == nil {
+		WriteError(w, http.StatusBadRequest, "Account timezone not set")
+		return
+	}
+	ianaLocation := account.Timezone.IANALocation
+
+	location, err := time.LoadLocation(ianaLocation)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid timezone: "+ianaLocation)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxICalImportSize)
+	defer r.Body.Close()
+
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+	var data []byte
+	var requested []CreateDestinationRequest
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		if err := r.ParseMultipartForm(maxICalImportSize); err != nil {
+			WriteError(w, http.StatusBadRequest, "Invalid multipart form or file too large")
+			return
+		}
+
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "file is required")
+			return
+		}
+		defer file.Close()
+
+		if data, err = io.ReadAll(file); err != nil {
+			WriteError(w, http.StatusBadRequest, "Failed to read file")
+			return
+		}
+
+		if raw := r.FormValue("destinations"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &requested); err != nil {
+				WriteError(w, http.StatusBadRequest, "destinations must be a JSON array of destinations")
+				return
+			}
+		}
+		if value := r.FormValue("dry_run"); value != "" {
+			dryRun, _ = strconv.ParseBool(value)
+		}
+	} else {
+		if data, err = io.ReadAll(r.Body); err != nil {
+			WriteError(w, http.StatusBadRequest, "Failed to read body or file too large")
+			return
+		}
+	}
+
+	// Resolve the destination set once, every imported reminder gets its own copy
+	var destinations []*models.ReminderDestination
+	for i, dest := range requested {
+		reminderDest, _, err := prepareDestination(h.accountRepo, accountID, dest, nil)
+		if errors.Is(err, ErrInvalidDestination) {
+			WriteError(w, http.StatusBadRequest, "Invalid destination at index "+strconv.Itoa(i))
+			return
+		}
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, "Failed to prepare destinations")
+			return
+		}
+		destinations = append(destinations, reminderDest)
+	}
+	if len(destinations) == 0 {
+		reminderDest := h.defaultDestination(accountID)
+		if reminderDest == nil {
+			WriteError(w, http.StatusBadRequest, "No destination provided and no Discord account to default to")
+			return
+		}
+		destinations = append(destinations, reminderDest)
+	}
+
+	entries, err := services.ParseICalendar(string(data), location)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid iCalendar file: "+err.Error())
+		return
+	}
+
+	response := ImportRemindersResponse{DryRun: dryRun, Items: []ImportReminderItem{}}
+	for i := range entries {
+		item := h.importEntry(r.Context(), &entries[i], accountID, ianaLocation, destinations, dryRun)
+		item.Index = i
+
+		switch item.Status {
+		case ImportStatusCreated, ImportStatusWouldCreate:
+			response.Created++
+		case ImportStatusSkipped:
+			response.Skipped++
+		case ImportStatusUnsupported:
+			response.Unsupported++
+		case ImportStatusFailed:
+			response.Failed++
+		}
+		response.Items = append(response.Items, item)
+	}
+
+	status := http.StatusOK
+	if !dryRun && response.Created > 0 {
+		status = http.StatusCreated
+	}
+	WriteJSON(w, status, response)
+}
+
+// importEntry maps one entry onto a reminder and, unless dryRun, saves it with a copy
+// of the destinations. The reminder and its destinations are inserted together, an
+// entry is either fully imported or reported as failed.
+func (h *UserHandler) importEntry(ctx context.Context, entry *services.ICalEntry, accountID uuid.UUID, ianaLocation string, destinations []*models.ReminderDestination, dryRun bool) ImportReminderItem {
+	item := ImportReminderItem{UID: entry.UID, Summary: entry.Summary}
+
+	reminder, err := services.ICalEntryToReminder(entry, ianaLocation)
+	item.Warnings = entry.Warnings
+	if err != nil {
+		var issue *services.ICalImportIssue
+		if !errors.As(err, &issue) {
+			issue = &services.ICalImportIssue{Unsupported: true, Reason: err.Error()}
+		}
+		item.Status = ImportStatusSkipped
+		if issue.Unsupported {
+			item.Status = ImportStatusUnsupported
+		}
+		item.Reason = issue.Reason
+		return item
+	}
+	reminder.AccountID = accountID
+
+	// Same guard as CreateReminder
+	if services.IsHourlyReminder(reminder) {
+		for _, dest := range destinations {
+			if dest.Type == models.DestinationEmail {
+				item.Status = ImportStatusUnsupported
+				item.Reason = "Email destination cannot be used with hourly recurrence"
+				return item
+			}
+		}
+	}
+
+	item.RemindAtUTC = &reminder.RemindAtUTC
+	item.RecurrenceType = services.GetRecurrenceTypeName(services.GetRecurrenceType(int(reminder.Recurrence)))
+	item.RRule = reminder.RRule
+
+	if dryRun {
+		item.Status = ImportStatusWouldCreate
+		return item
+	}
+
+	// Each reminder gets fresh destinations, webhooks with a signing secret of their own
+	for _, dest := range destinations {
+		reminderDest := models.ReminderDestination{
+			Type:     dest.Type,
+			Metadata: maps.Clone(dest.Metadata),
+		}
+		if _, err := services.PrepareWebhookSigning(&reminderDest, nil); err != nil {
+			logging.FromContext(ctx).Error("Error preparing webhook signing secret", "error", err)
+			item.Status = ImportStatusFailed
+			item.Reason = "Failed to create reminder"
+			return item
+		}
+		reminder.Destinations = append(reminder.Destinations, reminderDest)
+	}
+
+	// The destinations are created with the reminder, in the same transaction
+	if err := repositories.ReminderRepositoryWithContext(ctx, h.reminderRepo).Create(reminder, true); err != nil {
+		logging.FromContext(ctx).Error("Error importing reminder", "account_id", accountID, "error", err)
+		item.Status = ImportStatusFailed
+		item.Reason = "Failed to create reminder"
+		return item
+	}
+	logging.FromContext(ctx).Info("Reminder imported", "reminder_id", reminder.ID, "account_id", accountID)
+
+	for i := range reminder.Destinations {
+		response := ToDestinationResponse(reminder.Destinations[i])
+		if reminder.Destinations[i].Type == models.DestinationWebhook {
+			if secret, err := services.WebhookSigningSecret(&reminder.Destinations[i]); err == nil {
+				response.SigningSecret = secret
+			}
+		}
+		item.Destinations = append(item.Destinations, response)
+	}
+
+	reminderID := reminder.ID.String()
+	item.ReminderID = &reminderID
+	item.Status = ImportStatusCreated
+	return item
+}
@@ -2,12 +2,15 @@ package api
 
 import (
 	"fmt"
-	"log"
 	"net/http"
 
+	"github.com/ericp/chronos-bot-reminder/internal/bot"
 	"github.com/ericp/chronos-bot-reminder/internal/config"
+	"github.com/ericp/chronos-bot-reminder/internal/database"
 	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
 	"github.com/ericp/chronos-bot-reminder/internal/docs"
+	"github.com/ericp/chronos-bot-reminder/internal/engine"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
@@ -36,10 +39,7 @@ func NewServer(cfg *config.Config, repos *repositories.Repositories) *Server {
 	)
 
 	// Initialize mailer service
-	mailerService := services.NewMailerService(
-		cfg.ResendAPIKey,
-		config.EmailNoreply,
-	)
+	mailerService := services.NewMailerServiceFromConfig(cfg)
 
 	// Initialize verification service (used by auth + Discord OAuth signup)
 	verificationService := services.NewVerificationService(
@@ -87,6 +87,7 @@ func NewServer(cfg *config.Config, repos *repositories.Repositories) *Server {
 	discordGuildHandler := NewDiscordGuildHandler(discordOAuthService)
 	userHandler := NewUserHandler(repos.Reminder, repos.ReminderError, repos.Account, sessionService)
 	userHandler.SetReminderDestinationRepository(repos.ReminderDestination)
+	userHandler.SetReminderDeliveryRepository(repos.ReminderDelivery)
 	userHandler.SetIdentityRepository(repos.Identity)
 	userHandler.SetTimezoneRepository(repos.Timezone)
 	userHandler.SetDiscordOAuthService(discordOAuthService)
@@ -99,6 +100,33 @@ func NewServer(cfg *config.Config, repos *repositories.Repositories) *Server {
 	)
 	reminderHandler.SetAccountRepository(repos.Account)
 	reminderHandler.SetTimezoneRepository(repos.Timezone)
+	reminderHandler.SetReminderDeliveryRepository(repos.ReminderDelivery)
+
+	// Initialize webhook handler
+	webhookHandler := NewWebhookHandler(repos.Reminder, repos.Account)
+
+	// Initialize Telegram handler
+	telegramClient := services.NewTelegramClient(cfg.TelegramAPIBaseURL, cfg.TelegramBotToken)
+	telegramService := services.NewTelegramService(
+		telegramClient,
+		repos.Identity,
+		repos.Reminder,
+		services.NewRedisTelegramLinkCodeStore(),
+		cfg.TelegramBotUsername,
+	)
+	telegramHandler := NewTelegramHandler(telegramService, cfg.TelegramWebhookSecret)
+	if telegramClient.IsEnabled() && cfg.TelegramWebhookURL != "" {
+		go func() {
+			if err := telegramClient.SetWebhook(cfg.TelegramWebhookURL, cfg.TelegramWebhookSecret); err != nil {
+				logging.For(logging.API).Warn("Failed to register the Telegram webhook", "error", err)
+				return
+			}
+			logging.For(logging.API).Info("Telegram webhook registered")
+		}()
+	}
+
+	// Initialize phone number handler (SMS and voice destinations)
+	phoneNumberHandler := NewPhoneNumberHandler(services.NewTelephonyServiceFromConfig(cfg, repos))
 
 	// Initialize Don't Forget Me handler
 	dfmHandler := NewDFMHandler(
@@ -117,26 +145,62 @@ func NewServer(cfg *config.Config, repos *repositories.Repositories) *Server {
 	// Initialize FCM token handler
 	fcmHandler := NewFcmHandler(repos.FcmToken)
 
-	// Initialize health handler
+	// Initialize web push subscription handler
+	webPushHandler := NewWebPushHandler(
+		services.NewWebPushService(cfg.VAPIDPublicKey, cfg.VAPIDPrivateKey, cfg.VAPIDSubject),
+		repos.WebPushSubscription,
+	)
+
+	// Initialize calendar feed handler
+	calendarFeedService := services.NewCalendarFeedService(
+		repos.Account,
+		repos.Reminder,
+		repos.DFMNote,
+	)
+	calendarHandler := NewCalendarHandler(calendarFeedService)
+
+	// Initialize account archive handler
+	accountArchiveHandler := NewAccountArchiveHandler(services.NewAccountArchiveService(repos))
+
+	// Initialize health handler, the database, Redis and the engine are needed to serve
 	healthHandler := NewHealthHandler()
+	healthHandler.AddCheck(HealthCheck{Name: "database", Probe: DatabaseProbe(database.GetSQLDB), Critical: true})
+	healthHandler.AddCheck(HealthCheck{Name: "redis", Probe: RedisProbe(database.GetRedisClient), Critical: true})
+	for _, worker := range []string{engine.WorkerScheduler, engine.WorkerGarbageCollector, engine.WorkerDFMScheduler} {
+		healthHandler.AddCheck(HealthCheck{Name: worker, Probe: EngineWorkerProbe(worker, engine.CurrentWorkerStatuses), Critical: true, Liveness: true})
+	}
+	healthHandler.AddCheck(HealthCheck{Name: "discord", Probe: DiscordProbe(bot.CurrentSession)})
+	healthHandler.AddCheck(HealthCheck{Name: "mailer", Probe: MailerProbe(cfg)})
+	healthHandler.AddCheck(HealthCheck{Name: "fcm", Probe: FcmProbe(cfg)})
 
 	// Initialize contact handler
 	contactHandler := NewContactHandler(mailerService)
 
-	// Create wrapped mux with CORS middleware
+	// Create wrapped mux with CORS middleware, the tracing, logging and metrics middlewares
+	// come first to also cover the requests answered by the CORS preflight
 	wrappedMux := NewWrappedMux()
+	wrappedMux.Use(TracingMiddleware(wrappedMux))
+	wrappedMux.Use(RequestLoggingMiddleware(wrappedMux))
+	if cfg.MetricsEnabled {
+		wrappedMux.Use(MetricsMiddleware(wrappedMux))
+	}
 	wrappedMux.Use(CORSMiddleware(cfg))
+	trustedProxies, err := ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		logging.For(logging.API).Warn("Ignoring TRUSTED_PROXIES, forwarding headers will not be trusted", "error", err)
+	}
+	wrappedMux.Use(ClientIPMiddleware(trustedProxies))
 
 	// Apply rate limiter middleware to protected routes (if enabled)
 	var rateLimitMiddleware func(http.Handler) http.Handler
 	if cfg.RateLimitEnabled {
 		rateLimitMiddleware = RateLimitMiddleware(rateLimiterService)
-		log.Printf("[API] - ⚡ Rate limiting enabled: %d requests per %d seconds\n",
-			cfg.RateLimitRequestsPerWindow, cfg.RateLimitWindowSeconds)
+		logging.For(logging.API).Info("Rate limiting enabled",
+			"requests_per_window", cfg.RateLimitRequestsPerWindow, "window_seconds", cfg.RateLimitWindowSeconds)
 	} else {
 		// No-op middleware
 		rateLimitMiddleware = func(next http.Handler) http.Handler { return next }
-		log.Println("[API] - ⚡ Rate limiting disabled")
+		logging.For(logging.API).Info("Rate limiting disabled")
 	}
 
 	// Register all routes
@@ -147,11 +211,23 @@ func NewServer(cfg *config.Config, repos *repositories.Repositories) *Server {
 	registerDiscordGuildRoutes(wrappedMux, discordGuildHandler)
 	registerUserRoutes(wrappedMux, userHandler, discordOAuthHandler, sessionService, apiKeyService, rateLimitMiddleware)
 	registerReminderRoutes(wrappedMux, reminderHandler, sessionService, apiKeyService, rateLimitMiddleware)
+	registerWebhookRoutes(wrappedMux, webhookHandler, sessionService, apiKeyService, rateLimitMiddleware)
+	registerTelegramRoutes(wrappedMux, telegramHandler, sessionService, apiKeyService, rateLimitMiddleware)
+	registerPhoneNumberRoutes(wrappedMux, phoneNumberHandler, sessionService, apiKeyService, rateLimitMiddleware)
 	registerDFMRoutes(wrappedMux, dfmHandler, sessionService, apiKeyService, rateLimitMiddleware)
+	registerCalendarRoutes(wrappedMux, calendarHandler, calendarFeedService, sessionService, apiKeyService, rateLimitMiddleware)
+	registerAccountArchiveRoutes(wrappedMux, accountArchiveHandler, sessionService, apiKeyService, rateLimitMiddleware)
 	registerTimezoneRoutes(wrappedMux, timezoneHandler)
 	registerAPIKeyRoutes(wrappedMux, apiKeyHandler, sessionService, apiKeyService, rateLimitMiddleware)
 	registerFcmRoutes(wrappedMux, fcmHandler, sessionService, apiKeyService, rateLimitMiddleware)
+	registerWebPushRoutes(wrappedMux, webPushHandler, sessionService, apiKeyService, rateLimitMiddleware)
 	registerContactRoutes(wrappedMux, contactHandler)
+	if cfg.MetricsEnabled {
+		registerMetricsRoutes(wrappedMux, cfg)
+	}
+	if cfg.LogAdminToken != "" {
+		registerAdminRoutes(wrappedMux, NewLogLevelsHandler(), cfg.LogAdminToken)
+	}
 
 	return &Server{
 		mux:           wrappedMux,
@@ -184,6 +260,25 @@ func registerSwaggerRoutes(mux *WrappedMux) {
 func registerHealthRoutes(mux *WrappedMux, healthHandler *HealthHandler) {
 	mux.HandleFunc("GET /", healthHandler.Root)
 	mux.HandleFunc("GET /api/health", healthHandler.Health)
+	mux.HandleFunc("GET /api/health/live", healthHandler.Live)
+	mux.HandleFunc("GET /api/health/ready", healthHandler.Ready)
+}
+
+// registerMetricsRoutes registers the Prometheus scrape endpoint, unless it would be public
+func registerMetricsRoutes(mux *WrappedMux, cfg *config.Config) {
+	handler, err := NewMetricsHandler(cfg)
+	if err != nil {
+		logging.For(logging.API).Warn("Metrics endpoint not registered", "error", err)
+		return
+	}
+	mux.Handle("GET /metrics", handler)
+}
+
+// registerAdminRoutes registers the operator endpoints, behind the admin token
+func registerAdminRoutes(mux *WrappedMux, logLevelsHandler *LogLevelsHandler, adminToken string) {
+	adminMiddleware := AdminTokenMiddleware(adminToken)
+	mux.Handle("GET /api/admin/log-levels", adminMiddleware(http.HandlerFunc(logLevelsHandler.GetLogLevels)))
+	mux.Handle("PUT /api/admin/log-levels", adminMiddleware(http.HandlerFunc(logLevelsHandler.UpdateLogLevels)))
 }
 
 // registerAuthRoutes registers authentication routes
@@ -221,13 +316,21 @@ func registerUserRoutes(mux *WrappedMux, userHandler *UserHandler, discordOAuthH
 		return rateLimitMiddleware(authMiddleware(handler))
 	}
 
+	// Same chain, also open to API keys holding the scopes
+	scopedMiddleware := func(handler http.Handler, scopes ...string) http.Handler {
+		return rateLimitMiddleware(RequireAPIKeyScopes(scopes...)(authMiddleware(handler)))
+	}
+
 	// Wrap each user route handler with both middlewares
-	mux.Handle("GET /api/reminders", chainMiddleware(http.HandlerFunc(userHandler.GetReminders)))
-	mux.Handle("POST /api/reminders", chainMiddleware(http.HandlerFunc(userHandler.CreateReminder)))
-	mux.Handle("GET /api/reminders/errors", chainMiddleware(http.HandlerFunc(userHandler.GetReminderErrors)))
-	mux.Handle("GET /api/account", chainMiddleware(http.HandlerFunc(userHandler.GetAccount)))
+	mux.Handle("GET /api/reminders", scopedMiddleware(http.HandlerFunc(userHandler.GetReminders), services.ScopeRemindersRead))
+	mux.Handle("POST /api/reminders", scopedMiddleware(http.HandlerFunc(userHandler.CreateReminder), services.ScopeRemindersWrite))
+	mux.Handle("POST /api/reminders/import", scopedMiddleware(http.HandlerFunc(userHandler.ImportReminders), services.ScopeRemindersWrite))
+	mux.Handle("GET /api/reminders/errors", scopedMiddleware(http.HandlerFunc(userHandler.GetReminderErrors), services.ScopeRemindersRead))
+	mux.Handle("GET /api/reminders/deliveries", scopedMiddleware(http.HandlerFunc(userHandler.GetReminderDeliveries), services.ScopeRemindersRead))
+	mux.Handle("GET /api/account", scopedMiddleware(http.HandlerFunc(userHandler.GetAccount), services.ScopeAccountRead))
 	mux.Handle("POST /api/account/identity/app/change-password", chainMiddleware(http.HandlerFunc(userHandler.ChangeAppIdentityPassword)))
-	mux.Handle("PUT /api/account/timezone", chainMiddleware(http.HandlerFunc(userHandler.UpdateAccountTimezone)))
+	mux.Handle("PUT /api/account/timezone", scopedMiddleware(http.HandlerFunc(userHandler.UpdateAccountTimezone), services.ScopeAccountWrite))
+	mux.Handle("PUT /api/account/locale", scopedMiddleware(http.HandlerFunc(userHandler.UpdateAccountLocale), services.ScopeAccountWrite))
 	mux.Handle("PUT /api/account/identity/app/username", chainMiddleware(http.HandlerFunc(userHandler.UpdateAppIdentityUsername)))
 	mux.Handle("PUT /api/account/identity/app/email", chainMiddleware(http.HandlerFunc(userHandler.UpdateAppIdentityEmail)))
 	mux.Handle("DELETE /api/account", chainMiddleware(http.HandlerFunc(userHandler.DeleteAccount)))
@@ -241,42 +344,122 @@ func registerUserRoutes(mux *WrappedMux, userHandler *UserHandler, discordOAuthH
 func registerReminderRoutes(mux *WrappedMux, reminderHandler *ReminderHandler, sessionService *services.SessionService, apiKeyService *services.APIKeyService, rateLimitMiddleware func(http.Handler) http.Handler) {
 	authMiddleware := AuthMiddleware(sessionService, apiKeyService)
 
-	// Chain middlewares: rate limit -> auth
-	chainMiddleware := func(handler http.Handler) http.Handler {
-		return rateLimitMiddleware(authMiddleware(handler))
+	// Chain middlewares: rate limit -> API key scopes -> auth
+	chainMiddleware := func(handler http.Handler, scopes ...string) http.Handler {
+		return rateLimitMiddleware(RequireAPIKeyScopes(scopes...)(authMiddleware(handler)))
 	}
 
 	// Reminder CRUD operations
-	mux.Handle("GET /api/reminders/{id}", chainMiddleware(http.HandlerFunc(reminderHandler.GetReminder)))
-	mux.Handle("PUT /api/reminders/{id}", chainMiddleware(http.HandlerFunc(reminderHandler.UpdateReminder)))
-	mux.Handle("DELETE /api/reminders/{id}", chainMiddleware(http.HandlerFunc(reminderHandler.DeleteReminder)))
+	mux.Handle("GET /api/reminders/{id}", chainMiddleware(http.HandlerFunc(reminderHandler.GetReminder), services.ScopeRemindersRead))
+	mux.Handle("PUT /api/reminders/{id}", chainMiddleware(http.HandlerFunc(reminderHandler.UpdateReminder), services.ScopeRemindersWrite))
+	mux.Handle("DELETE /api/reminders/{id}", chainMiddleware(http.HandlerFunc(reminderHandler.DeleteReminder), services.ScopeRemindersWrite))
 
 	// Reminder state operations
-	mux.Handle("POST /api/reminders/{id}/pause", chainMiddleware(http.HandlerFunc(reminderHandler.PauseReminder)))
-	mux.Handle("POST /api/reminders/{id}/resume", chainMiddleware(http.HandlerFunc(reminderHandler.ResumeReminder)))
-	mux.Handle("POST /api/reminders/{id}/duplicate", chainMiddleware(http.HandlerFunc(reminderHandler.DuplicateReminder)))
-	mux.Handle("POST /api/reminders/{id}/snooze", chainMiddleware(http.HandlerFunc(reminderHandler.SnoozeReminder)))
+	mux.Handle("POST /api/reminders/{id}/pause", chainMiddleware(http.HandlerFunc(reminderHandler.PauseReminder), services.ScopeRemindersWrite))
+	mux.Handle("POST /api/reminders/{id}/resume", chainMiddleware(http.HandlerFunc(reminderHandler.ResumeReminder), services.ScopeRemindersWrite))
+	mux.Handle("POST /api/reminders/{id}/duplicate", chainMiddleware(http.HandlerFunc(reminderHandler.DuplicateReminder), services.ScopeRemindersWrite))
+	mux.Handle("POST /api/reminders/{id}/destinations/{destinationId}/rotate-secret", chainMiddleware(http.HandlerFunc(reminderHandler.RotateWebhookSecret), services.ScopeRemindersWrite))
+	mux.Handle("POST /api/reminders/{id}/snooze", chainMiddleware(http.HandlerFunc(reminderHandler.SnoozeReminder), services.ScopeRemindersWrite))
+
+	// Reminder history
+	mux.Handle("GET /api/reminders/{id}/deliveries", chainMiddleware(http.HandlerFunc(reminderHandler.GetReminderDeliveries), services.ScopeRemindersRead))
 }
 
 // registerDFMRoutes registers "Don't Forget Me" routes with auth and rate limit middleware
 func registerDFMRoutes(mux *WrappedMux, dfmHandler *DFMHandler, sessionService *services.SessionService, apiKeyService *services.APIKeyService, rateLimitMiddleware func(http.Handler) http.Handler) {
 	authMiddleware := AuthMiddleware(sessionService, apiKeyService)
 
+	// Chain middlewares: rate limit -> API key scopes -> auth
+	chainMiddleware := func(handler http.Handler, scopes ...string) http.Handler {
+		return rateLimitMiddleware(RequireAPIKeyScopes(scopes...)(authMiddleware(handler)))
+	}
+
+	// Note and items
+	mux.Handle("GET /api/dfm", chainMiddleware(http.HandlerFunc(dfmHandler.GetNote), services.ScopeDFMRead))
+	mux.Handle("POST /api/dfm/items", chainMiddleware(http.HandlerFunc(dfmHandler.AddItem), services.ScopeDFMWrite))
+	mux.Handle("PUT /api/dfm/items/{id}", chainMiddleware(http.HandlerFunc(dfmHandler.UpdateItem), services.ScopeDFMWrite))
+	mux.Handle("DELETE /api/dfm/items/{id}", chainMiddleware(http.HandlerFunc(dfmHandler.DeleteItem), services.ScopeDFMWrite))
+
+	// Reminder configuration
+	mux.Handle("PUT /api/dfm/reminder", chainMiddleware(http.HandlerFunc(dfmHandler.SetReminder), services.ScopeDFMWrite))
+	mux.Handle("DELETE /api/dfm/reminder", chainMiddleware(http.HandlerFunc(dfmHandler.RemoveReminder), services.ScopeDFMWrite))
+	mux.Handle("POST /api/dfm/send", chainMiddleware(http.HandlerFunc(dfmHandler.SendNow), services.ScopeDFMWrite))
+}
+
+// registerCalendarRoutes registers the iCalendar feed and its token management routes.
+// The feed also accepts the token of its URL since calendar clients cannot log in.
+func registerCalendarRoutes(mux *WrappedMux, calendarHandler *CalendarHandler, calendarFeedService *services.CalendarFeedService, sessionService *services.SessionService, apiKeyService *services.APIKeyService, rateLimitMiddleware func(http.Handler) http.Handler) {
+	authMiddleware := AuthMiddleware(sessionService, apiKeyService)
+	feedMiddleware := CalendarTokenMiddleware(calendarFeedService, authMiddleware)
+
 	// Chain middlewares: rate limit -> auth
 	chainMiddleware := func(handler http.Handler) http.Handler {
 		return rateLimitMiddleware(authMiddleware(handler))
 	}
 
-	// Note and items
-	mux.Handle("GET /api/dfm", chainMiddleware(http.HandlerFunc(dfmHandler.GetNote)))
-	mux.Handle("POST /api/dfm/items", chainMiddleware(http.HandlerFunc(dfmHandler.AddItem)))
-	mux.Handle("PUT /api/dfm/items/{id}", chainMiddleware(http.HandlerFunc(dfmHandler.UpdateItem)))
-	mux.Handle("DELETE /api/dfm/items/{id}", chainMiddleware(http.HandlerFunc(dfmHandler.DeleteItem)))
+	mux.Handle("GET /api/calendar.ics", rateLimitMiddleware(RequireAPIKeyScopes(services.ScopeRemindersRead, services.ScopeDFMRead)(feedMiddleware(http.HandlerFunc(calendarHandler.GetCalendarFeed)))))
+	mux.Handle("POST /api/calendar/token", chainMiddleware(http.HandlerFunc(calendarHandler.CreateCalendarToken)))
+	mux.Handle("DELETE /api/calendar/token", chainMiddleware(http.HandlerFunc(calendarHandler.RevokeCalendarToken)))
+}
 
-	// Reminder configuration
-	mux.Handle("PUT /api/dfm/reminder", chainMiddleware(http.HandlerFunc(dfmHandler.SetReminder)))
-	mux.Handle("DELETE /api/dfm/reminder", chainMiddleware(http.HandlerFunc(dfmHandler.RemoveReminder)))
-	mux.Handle("POST /api/dfm/send", chainMiddleware(http.HandlerFunc(dfmHandler.SendNow)))
+// registerAccountArchiveRoutes registers account export and import routes with auth and rate limit middleware
+func registerAccountArchiveRoutes(mux *WrappedMux, accountArchiveHandler *AccountArchiveHandler, sessionService *services.SessionService, apiKeyService *services.APIKeyService, rateLimitMiddleware func(http.Handler) http.Handler) {
+	authMiddleware := AuthMiddleware(sessionService, apiKeyService)
+
+	// Chain middlewares: rate limit -> API key scopes -> auth
+	chainMiddleware := func(handler http.Handler, scopes ...string) http.Handler {
+		return rateLimitMiddleware(RequireAPIKeyScopes(scopes...)(authMiddleware(handler)))
+	}
+
+	// The archive covers every resource of the account
+	mux.Handle("GET /api/account/export", chainMiddleware(http.HandlerFunc(accountArchiveHandler.ExportAccount), services.ScopeAccountRead, services.ScopeRemindersRead, services.ScopeDFMRead))
+	mux.Handle("POST /api/account/import", chainMiddleware(http.HandlerFunc(accountArchiveHandler.ImportAccount), services.ScopeAccountWrite, services.ScopeRemindersWrite, services.ScopeDFMWrite))
+}
+
+// registerWebhookRoutes registers webhook tooling routes with auth and rate limit middleware
+func registerWebhookRoutes(mux *WrappedMux, webhookHandler *WebhookHandler, sessionService *services.SessionService, apiKeyService *services.APIKeyService, rateLimitMiddleware func(http.Handler) http.Handler) {
+	authMiddleware := AuthMiddleware(sessionService, apiKeyService)
+
+	// Chain middlewares: rate limit -> API key scopes -> auth
+	chainMiddleware := func(handler http.Handler, scopes ...string) http.Handler {
+		return rateLimitMiddleware(RequireAPIKeyScopes(scopes...)(authMiddleware(handler)))
+	}
+
+	// The preview may render an existing reminder
+	mux.Handle("POST /api/webhooks/preview", chainMiddleware(http.HandlerFunc(webhookHandler.PreviewWebhook), services.ScopeRemindersRead))
+}
+
+// registerTelegramRoutes registers Telegram chat linking routes and the bot webhook
+func registerTelegramRoutes(mux *WrappedMux, telegramHandler *TelegramHandler, sessionService *services.SessionService, apiKeyService *services.APIKeyService, rateLimitMiddleware func(http.Handler) http.Handler) {
+	authMiddleware := AuthMiddleware(sessionService, apiKeyService)
+
+	// Chain middlewares: rate limit -> API key scopes -> auth
+	chainMiddleware := func(handler http.Handler, scopes ...string) http.Handler {
+		return rateLimitMiddleware(RequireAPIKeyScopes(scopes...)(authMiddleware(handler)))
+	}
+
+	mux.Handle("POST /api/telegram/link", chainMiddleware(http.HandlerFunc(telegramHandler.CreateLinkCode), services.ScopeAccountWrite))
+	mux.Handle("GET /api/telegram/chats", chainMiddleware(http.HandlerFunc(telegramHandler.GetLinkedChats), services.ScopeAccountRead))
+	mux.Handle("DELETE /api/telegram/chats/{chatId}", chainMiddleware(http.HandlerFunc(telegramHandler.UnlinkChat), services.ScopeAccountWrite))
+
+	// Called by Telegram, authenticated by the webhook secret token
+	mux.HandleFunc("POST /api/telegram/webhook", telegramHandler.HandleWebhook)
+}
+
+// registerPhoneNumberRoutes registers the phone number verification and usage routes
+func registerPhoneNumberRoutes(mux *WrappedMux, phoneNumberHandler *PhoneNumberHandler, sessionService *services.SessionService, apiKeyService *services.APIKeyService, rateLimitMiddleware func(http.Handler) http.Handler) {
+	authMiddleware := AuthMiddleware(sessionService, apiKeyService)
+
+	// Chain middlewares: rate limit -> API key scopes -> auth
+	chainMiddleware := func(handler http.Handler, scopes ...string) http.Handler {
+		return rateLimitMiddleware(RequireAPIKeyScopes(scopes...)(authMiddleware(handler)))
+	}
+
+	mux.Handle("GET /api/phone-numbers", chainMiddleware(http.HandlerFunc(phoneNumberHandler.GetPhoneNumbers), services.ScopeAccountRead))
+	mux.Handle("POST /api/phone-numbers", chainMiddleware(http.HandlerFunc(phoneNumberHandler.AddPhoneNumber), services.ScopeAccountWrite))
+	mux.Handle("POST /api/phone-numbers/verify", chainMiddleware(http.HandlerFunc(phoneNumberHandler.VerifyPhoneNumber), services.ScopeAccountWrite))
+	mux.Handle("DELETE /api/phone-numbers/{number}", chainMiddleware(http.HandlerFunc(phoneNumberHandler.DeletePhoneNumber), services.ScopeAccountWrite))
+	mux.Handle("GET /api/phone-numbers/usage", chainMiddleware(http.HandlerFunc(phoneNumberHandler.GetUsage), services.ScopeAccountRead))
 }
 
 // registerTimezoneRoutes registers timezone routes (public, no auth required)
@@ -297,6 +480,7 @@ func registerAPIKeyRoutes(mux *WrappedMux, apiKeyHandler *APIKeyHandler, session
 	mux.Handle("POST /api/api-keys", chainMiddleware(http.HandlerFunc(apiKeyHandler.CreateAPIKey)))
 	mux.Handle("GET /api/api-keys", chainMiddleware(http.HandlerFunc(apiKeyHandler.GetAPIKeys)))
 	mux.Handle("DELETE /api/api-keys/{id}", chainMiddleware(http.HandlerFunc(apiKeyHandler.RevokeAPIKey)))
+	mux.Handle("POST /api/api-keys/{id}/rotate", chainMiddleware(http.HandlerFunc(apiKeyHandler.RotateAPIKey)))
 }
 
 // registerFcmRoutes registers FCM token routes with auth and rate limit middleware
@@ -313,6 +497,23 @@ func registerFcmRoutes(mux *WrappedMux, fcmHandler *FcmHandler, sessionService *
 	mux.Handle("DELETE /api/fcm/token", chainMiddleware(http.HandlerFunc(fcmHandler.UnregisterToken)))
 }
 
+// registerWebPushRoutes registers browser push subscription routes with auth and rate limit middleware
+func registerWebPushRoutes(mux *WrappedMux, webPushHandler *WebPushHandler, sessionService *services.SessionService, apiKeyService *services.APIKeyService, rateLimitMiddleware func(http.Handler) http.Handler) {
+	authMiddleware := AuthMiddleware(sessionService, apiKeyService)
+
+	// Chain middlewares: rate limit -> auth
+	chainMiddleware := func(handler http.Handler) http.Handler {
+		return rateLimitMiddleware(authMiddleware(handler))
+	}
+
+	// The VAPID public key is public, the service worker needs it before subscribing
+	mux.HandleFunc("GET /api/webpush/vapid-public-key", webPushHandler.GetVAPIDPublicKey)
+
+	mux.Handle("GET /api/webpush/status", chainMiddleware(http.HandlerFunc(webPushHandler.HasSubscriptions)))
+	mux.Handle("POST /api/webpush/subscription", chainMiddleware(http.HandlerFunc(webPushHandler.RegisterSubscription)))
+	mux.Handle("DELETE /api/webpush/subscription", chainMiddleware(http.HandlerFunc(webPushHandler.UnregisterSubscription)))
+}
+
 // registerContactRoutes registers contact form routes (public, no auth required)
 func registerContactRoutes(mux *WrappedMux, contactHandler *ContactHandler) {
 	mux.HandleFunc("POST /api/contact", contactHandler.SubmitContact)
@@ -325,10 +526,11 @@ func (s *Server) Start() error {
 		Handler: s.mux,
 	}
 
-	log.Printf("[API] - 🚀 Starting API server on port %s\n", s.port)
-	log.Printf("[API] - 📡 Server running at http://localhost:%s\n", s.port)
-	log.Printf("[API] - 📚 Swagger documentation available at http://localhost:%s/swagger/\n", s.port)
-	log.Printf("[API] - 🌐 WebClient available at %s", s.cfg.WebAppURL)
+	logging.For(logging.API).Info("Starting API server",
+		"port", s.port,
+		"swagger_url", "http://localhost:"+s.port+"/swagger/",
+		"web_app_url", s.cfg.WebAppURL,
+	)
 	if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("[API] - ❌ Failed to start server: %w", err)
 	}
@@ -342,7 +544,7 @@ func (s *Server) Stop() error {
 		return nil
 	}
 
-	log.Println("[API] - 🛑 Shutting down API server...")
+	logging.For(logging.API).Info("Shutting down API server")
 	return s.server.Close()
 }
 
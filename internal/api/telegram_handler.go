@@ -0,0 +1,129 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/google/uuid"
+)
+
+// TelegramSecretTokenHeader carries the secret token Telegram was given with setWebhook
+const TelegramSecretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// maxTelegramUpdateSize caps the size of an update sent to the bot webhook
+const maxTelegramUpdateSize = 1 << 20
+
+// TelegramHandler handles Telegram chat linking and the bot webhook
+type TelegramHandler struct {
+	telegramService *services.TelegramService
+	webhookSecret   string
+}
+
+// NewTelegramHandler creates a new Telegram handler. Updates are refused while webhookSecret is empty.
+func NewTelegramHandler(telegramService *services.TelegramService, webhookSecret string) *TelegramHandler {
+	return &TelegramHandler{
+		telegramService: telegramService,
+		webhookSecret:   webhookSecret,
+	}
+}
+
+// TelegramChatResponse represents a Telegram chat linked to the account
+type TelegramChatResponse struct {
+	ChatID   string    `json:"chat_id"`
+	Name     *string   `json:"name"`
+	LinkedAt time.Time `json:"linked_at"`
+}
+
+// CreateLinkCode generates a one-time code to send to the bot from the chat to link
+// @Route: POST /api/telegram/link
+func (h *TelegramHandler) CreateLinkCode(w http.ResponseWriter, r *http.Request) {
+	accountID := r.Context().Value(AccountIDKey).(uuid.UUID)
+
+	if !h.telegramService.IsEnabled() {
+		WriteError(w, http.StatusServiceUnavailable, "Telegram is not configured")
+		return
+	}
+
+	code, err := h.telegramService.CreateLinkCode(accountID)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("Error creating Telegram link code", "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to create link code")
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, code)
+}
+
+// GetLinkedChats lists the Telegram chats linked to the account
+// @Route: GET /api/telegram/chats
+func (h *TelegramHandler) GetLinkedChats(w http.ResponseWriter, r *http.Request) {
+	accountID := r.Context().Value(AccountIDKey).(uuid.UUID)
+
+	identities, err := h.telegramService.LinkedChats(accountID)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "Failed to fetch Telegram chats")
+		return
+	}
+
+	chats := make([]TelegramChatResponse, 0, len(identities))
+	for _, identity := range identities {
+		chats = append(chats, TelegramChatResponse{
+			ChatID:   identity.ExternalID,
+			Name:     identity.Username,
+			LinkedAt: identity.CreatedAt,
+		})
+	}
+
+	WriteJSON(w, http.StatusOK, chats)
+}
+
+// UnlinkChat removes a linked Telegram chat, its destinations stop receiving reminders
+// @Route: DELETE /api/telegram/chats/{chatId}
+func (h *TelegramHandler) UnlinkChat(w http.ResponseWriter, r *http.Request) {
+	accountID := r.Context().Value(AccountIDKey).(uuid.UUID)
+
+	err := h.telegramService.UnlinkChat(accountID, r.PathValue("chatId"))
+	if errors.Is(err, services.ErrTelegramChatNotLinked) {
+		WriteError(w, http.StatusNotFound, "Telegram chat not found")
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "Failed to unlink Telegram chat")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Telegram chat unlinked"})
+}
+
+// HandleWebhook receives the bot updates: link commands and snooze button presses
+// @Route: POST /api/telegram/webhook
+func (h *TelegramHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
+	if h.webhookSecret == "" || !h.telegramService.IsEnabled() {
+		WriteError(w, http.StatusServiceUnavailable, "Telegram webhook is not configured")
+		return
+	}
+
+	secret := r.Header.Get(TelegramSecretTokenHeader)
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(h.webhookSecret)) != 1 {
+		WriteError(w, http.StatusUnauthorized, "Invalid secret token")
+		return
+	}
+
+	var update services.TelegramUpdate
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTelegramUpdateSize)).Decode(&update); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid update")
+		return
+	}
+
+	// Telegram redelivers updates answered with an error, a failed update is only logged
+	if err := h.telegramService.HandleUpdate(r.Context(), &update); err != nil {
+		logging.FromContext(r.Context()).Error("Error handling Telegram update", "update_id", update.UpdateID, "error", err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
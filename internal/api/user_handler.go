@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -10,6 +11,8 @@ import (
 
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
 	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 	"github.com/google/uuid"
 )
@@ -20,6 +23,7 @@ type CreateReminderRequest struct {
 	Time         string          `json:"time"`         // HH:mm format
 	Message      string          `json:"message"`
 	Recurrence   json.RawMessage `json:"recurrence"`   // Can be string ("DAILY") or int (4)
+	RRule        string          `json:"rrule"`        // Optional RFC 5545 rule ("FREQ=WEEKLY;BYDAY=MO,WE,FR"), overrides recurrence
 	Destinations []CreateDestinationRequest   `json:"destinations"`
 }
 
@@ -35,6 +39,7 @@ type CreateReminderResponse struct {
 	Message         string            `json:"message"`
 	RemindAtUTC     time.Time         `json:"remind_at_utc"`
 	RecurrenceType  string            `json:"recurrence_type"`
+	RRule           *string           `json:"rrule,omitempty"`
 	IsPaused        bool              `json:"is_paused"`
 	Destinations    []interface{}     `json:"destinations"`
 }
@@ -49,8 +54,22 @@ type ReminderResponse struct {
 	Message         string                 `json:"message"`
 	CreatedAt       time.Time              `json:"created_at"`
 	RecurrenceType  string                 `json:"recurrence_type"`
+	RRule           *string                `json:"rrule,omitempty"`
 	IsPaused        bool                   `json:"is_paused"`
-	Destinations    []models.ReminderDestination `json:"destinations,omitempty"`
+	Destinations    []DestinationResponse  `json:"destinations,omitempty"`
+}
+
+// DestinationResponse represents a reminder destination in API responses. The signing
+// secret of a webhook is only shown when it is generated.
+type DestinationResponse struct {
+	models.ReminderDestination
+	SigningSecret string `json:"signing_secret,omitempty"`
+}
+
+// ToDestinationResponse converts a destination, without its stored signing secret
+func ToDestinationResponse(destination models.ReminderDestination) DestinationResponse {
+	destination.Metadata = services.RedactDestinationMetadata(destination.Metadata)
+	return DestinationResponse{ReminderDestination: destination}
 }
 
 // ToReminderResponse converts a Reminder model to ReminderResponse with decoded recurrence
@@ -65,9 +84,43 @@ func ToReminderResponse(reminder *models.Reminder) *ReminderResponse {
 		Message:        reminder.Message,
 		CreatedAt:      reminder.CreatedAt,
 		RecurrenceType: services.GetRecurrenceTypeName(recurrenceType),
+		RRule:          reminder.RRule,
 		IsPaused:       services.IsPaused(int(reminder.Recurrence)),
-		Destinations:   reminder.Destinations,
+		Destinations:   toDestinationResponses(reminder.Destinations),
+	}
+}
+
+// toDestinationResponses converts the destinations of a reminder
+func toDestinationResponses(destinations []models.ReminderDestination) []DestinationResponse {
+	if len(destinations) == 0 {
+		return nil
+	}
+	responses := make([]DestinationResponse, len(destinations))
+	for i, destination := range destinations {
+		responses[i] = ToDestinationResponse(destination)
 	}
+	return responses
+}
+
+// parseRecurrenceValue decodes the recurrence field of a request, either a type
+// name ("DAILY", "RRULE") or the legacy integer state
+func parseRecurrenceValue(raw json.RawMessage) (int, error) {
+	var recurrenceStr string
+	if err := json.Unmarshal(raw, &recurrenceStr); err == nil {
+		if recurrenceStr == "RRULE" {
+			return services.RecurrenceRRule, nil
+		}
+		if val, exists := services.RecurrenceTypeMap[recurrenceStr]; exists {
+			return val, nil
+		}
+		return 0, fmt.Errorf("Invalid recurrence type")
+	}
+
+	var recurrenceInt int
+	if err := json.Unmarshal(raw, &recurrenceInt); err != nil {
+		return 0, fmt.Errorf("Invalid recurrence format")
+	}
+	return recurrenceInt, nil
 }
 
 // UserHandler handles user-related requests
@@ -75,6 +128,7 @@ type UserHandler struct {
 	reminderRepo            repositories.ReminderRepository
 	reminderErrorRepo       repositories.ReminderErrorRepository
 	reminderDestinationRepo repositories.ReminderDestinationRepository
+	reminderDeliveryRepo    repositories.ReminderDeliveryRepository
 	accountRepo             repositories.AccountRepository
 	identityRepo            repositories.IdentityRepository
 	timezoneRepo            repositories.TimezoneRepository
@@ -102,6 +156,11 @@ func (h *UserHandler) SetReminderDestinationRepository(repo repositories.Reminde
 	h.reminderDestinationRepo = repo
 }
 
+// SetReminderDeliveryRepository sets the reminder delivery repository
+func (h *UserHandler) SetReminderDeliveryRepository(repo repositories.ReminderDeliveryRepository) {
+	h.reminderDeliveryRepo = repo
+}
+
 // SetIdentityRepository sets the identity repository
 func (h *UserHandler) SetIdentityRepository(repo repositories.IdentityRepository) {
 	h.identityRepo = repo
@@ -191,24 +250,12 @@ func (h *UserHandler) CreateReminder(w http.ResponseWriter, r *http.Request) {
 	// Handle recurrence - can be string or int
 	var recurrenceValue int16
 	if len(req.Recurrence) > 0 {
-		var recurrenceStr string
-		if err := json.Unmarshal(req.Recurrence, &recurrenceStr); err == nil {
-			// It's a string, convert using the map
-			if val, exists := services.RecurrenceTypeMap[recurrenceStr]; exists {
-				recurrenceValue = int16(val)
-			} else {
-				WriteError(w, http.StatusBadRequest, "Invalid recurrence type")
-				return
-			}
-		} else {
-			// Try to parse as int (legacy format)
-			var recurrenceInt int
-			if err := json.Unmarshal(req.Recurrence, &recurrenceInt); err != nil {
-				WriteError(w, http.StatusBadRequest, "Invalid recurrence format")
-				return
-			}
-			recurrenceValue = int16(recurrenceInt)
+		val, err := parseRecurrenceValue(req.Recurrence)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, err.Error())
+			return
 		}
+		recurrenceValue = int16(val)
 	}
 
 	// Create the reminder with UTC time
@@ -219,139 +266,60 @@ func (h *UserHandler) CreateReminder(w http.ResponseWriter, r *http.Request) {
 		Recurrence:  recurrenceValue,
 	}
 
+	// Attach the custom rule, if any
+	if err := services.ApplyRRule(reminder, req.RRule, account.Timezone.IANALocation); err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// Save the reminder to database
-	if err := h.reminderRepo.Create(reminder, true); err != nil {
+	if err := repositories.ReminderRepositoryWithContext(r.Context(), h.reminderRepo).Create(reminder, true); err != nil {
 		WriteError(w, http.StatusInternalServerError, "Failed to create reminder")
 		return
 	}
+	logging.FromContext(r.Context()).Info("Reminder created", "reminder_id", reminder.ID, "account_id", accountID)
 
 	// Process destinations
 	var destinations []interface{}
 	for _, dest := range req.Destinations {
-		destType := models.DestinationType(dest.Type)
-
-		// Validate destination type
-		if !destType.IsValid() {
-			continue
-		}
-
-		// Handle discord_dm destination
-		if destType == models.DestinationDiscordDM {
-			// If user_id not provided, get it from the Discord identity
-			if _, exists := dest.Metadata["user_id"]; !exists {
-				account, err := h.accountRepo.GetWithIdentities(accountID)
-				if err == nil && account != nil {
-					for _, identity := range account.Identities {
-						if identity.Provider == "discord" {
-							dest.Metadata["user_id"] = identity.ExternalID
-							break
-						}
-					}
-				}
-				// Skip if still no user_id
-				if _, exists := dest.Metadata["user_id"]; !exists {
-					continue
-				}
-			}
-		}
-
-		// Handle discord_channel destination
-		if destType == models.DestinationDiscordChannel {
-			// Validate required fields
-			if _, hasGuild := dest.Metadata["guild_id"]; !hasGuild {
-				continue
-			}
-			if _, hasChannel := dest.Metadata["channel_id"]; !hasChannel {
-				continue
-			}
-			// mention_role_id is optional
-		}
-
-		// Handle webhook destination
-		if destType == models.DestinationWebhook {
-			// Validate required fields
-			if _, hasURL := dest.Metadata["url"]; !hasURL {
-				continue
-			}
-
-			// Validate optional platform field
-			if platformVal, exists := dest.Metadata["platform"]; exists {
-				if platformStr, ok := platformVal.(string); ok {
-					platform := models.WebhookPlatform(platformStr)
-					if !platform.IsValid() {
-						// Invalid platform, skip this destination
-						continue
-					}
-				}
-			}
-		}
-
-		// Handle email destination
-		if destType == models.DestinationEmail {
-			if _, hasEmail := dest.Metadata["email"]; !hasEmail {
-				// Auto-fill from account-level email
-				acct, err := h.accountRepo.GetByID(accountID)
-				if err == nil && acct != nil && acct.Email != nil {
-					dest.Metadata["email"] = *acct.Email
-				}
-				if _, still := dest.Metadata["email"]; !still {
-					continue
-				}
-			}
-
-			// Guard: disallow email for hourly (or shorter) recurrence to avoid flooding Resend free tier
-			recurrenceType := services.GetRecurrenceType(int(recurrenceValue))
-			if recurrenceType == services.RecurrenceHourly {
-				WriteError(w, http.StatusBadRequest, "Email destination cannot be used with hourly recurrence")
-				return
+		reminderDest, _, err := prepareDestination(h.accountRepo, accountID, dest, nil)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidDestination) {
+				logging.FromContext(r.Context()).Error("Failed to prepare destination", "reminder_id", reminder.ID, "error", err)
 			}
+			continue
 		}
 
-		// Handle android_push destination - always inject account_id from auth context
-		if destType == models.DestinationAndroidPush {
-			if dest.Metadata == nil {
-				dest.Metadata = models.JSONB{}
-			}
-			dest.Metadata["account_id"] = accountID.String()
+		// Guard: disallow email for hourly (or shorter) recurrence to avoid flooding Resend free tier
+		if reminderDest.Type == models.DestinationEmail && services.IsHourlyReminder(reminder) {
+			WriteError(w, http.StatusBadRequest, "Email destination cannot be used with hourly recurrence")
+			return
 		}
 
 		// Create the destination
-		reminderDest := &models.ReminderDestination{
-			ReminderID: reminder.ID,
-			Type:       destType,
-			Metadata:   dest.Metadata,
-		}
-
+		reminderDest.ReminderID = reminder.ID
 		if err := h.reminderDestinationRepo.Create(reminderDest); err != nil {
 			// Log error but continue - don't fail the entire operation
-			fmt.Printf("[CREATE_REMINDER] Failed to create destination: %v\n", err)
+			logging.FromContext(r.Context()).Error("Failed to create destination", "reminder_id", reminder.ID, "error", err)
 			continue
 		}
 
-		destinations = append(destinations, reminderDest)
+		// The signing secret of a new webhook is shown this once
+		response := ToDestinationResponse(*reminderDest)
+		if reminderDest.Type == models.DestinationWebhook {
+			if secret, err := services.WebhookSigningSecret(reminderDest); err == nil {
+				response.SigningSecret = secret
+			}
+		}
+		destinations = append(destinations, response)
 	}
 
 	// If no destinations were provided or valid, create a default discord_dm destination
-	// Get the Discord identity for the user
 	if len(destinations) == 0 {
-		account, err := h.accountRepo.GetWithIdentities(accountID)
-		if err == nil && account != nil && len(account.Identities) > 0 {
-			// Find Discord identity
-			for _, identity := range account.Identities {
-				if identity.Provider == "discord" {
-					reminderDest := &models.ReminderDestination{
-						ReminderID: reminder.ID,
-						Type:       models.DestinationDiscordDM,
-						Metadata: models.JSONB{
-							"user_id": identity.ExternalID,
-						},
-					}
-
-					if err := h.reminderDestinationRepo.Create(reminderDest); err == nil {
-						destinations = append(destinations, reminderDest)
-					}
-					break
-				}
+		if reminderDest := h.defaultDestination(accountID); reminderDest != nil {
+			reminderDest.ReminderID = reminder.ID
+			if err := h.reminderDestinationRepo.Create(reminderDest); err == nil {
+				destinations = append(destinations, ToDestinationResponse(*reminderDest))
 			}
 		}
 	}
@@ -365,6 +333,7 @@ func (h *UserHandler) CreateReminder(w http.ResponseWriter, r *http.Request) {
 		Message:        reminder.Message,
 		RemindAtUTC:    reminder.RemindAtUTC,
 		RecurrenceType: services.GetRecurrenceTypeName(recurrenceType),
+		RRule:          reminder.RRule,
 		IsPaused:       isPaused,
 		Destinations:   destinations,
 	}
@@ -372,6 +341,29 @@ func (h *UserHandler) CreateReminder(w http.ResponseWriter, r *http.Request) {
 	WriteJSON(w, http.StatusCreated, response)
 }
 
+// defaultDestination returns the destination used when a reminder is created without
+// any usable one: a DM to the account's Discord user. It returns nil for accounts
+// without a Discord identity.
+func (h *UserHandler) defaultDestination(accountID uuid.UUID) *models.ReminderDestination {
+	account, err := h.accountRepo.GetWithIdentities(accountID)
+	if err != nil || account == nil {
+		return nil
+	}
+
+	// Find Discord identity
+	for _, identity := range account.Identities {
+		if identity.Provider == "discord" {
+			return &models.ReminderDestination{
+				Type: models.DestinationDiscordDM,
+				Metadata: models.JSONB{
+					"user_id": identity.ExternalID,
+				},
+			}
+		}
+	}
+	return nil
+}
+
 // GetReminders retrieves all reminders for the authenticated user with their destinations
 // @Route: GET /api/reminders
 func (h *UserHandler) GetReminders(w http.ResponseWriter, r *http.Request) {
@@ -501,6 +493,38 @@ func (h *UserHandler) GetReminderErrors(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// GetReminderDeliveries retrieves the delivery history of every reminder of the authenticated user
+// @Route: GET /api/reminders/deliveries
+func (h *UserHandler) GetReminderDeliveries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	accountID, err := h.extractAccountIDFromToken(r)
+	if err != nil {
+		WriteError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	limit, err := parseLimitParam(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	deliveries, err := h.reminderDeliveryRepo.GetByAccountID(accountID, limit)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "Failed to retrieve deliveries")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"deliveries": deliveries,
+		"count":      len(deliveries),
+	})
+}
+
 // GetAccount retrieves the authenticated user's account information with identities
 // @Route: GET /api/account
 func (h *UserHandler) GetAccount(w http.ResponseWriter, r *http.Request) {
@@ -780,6 +804,58 @@ func (h *UserHandler) UpdateAccountTimezone(w http.ResponseWriter, r *http.Reque
 	})
 }
 
+// UpdateAccountLocale updates the language of the bot replies, reminders and emails for the
+// authenticated user's account
+// @Route: PUT /api/account/locale
+func (h *UserHandler) UpdateAccountLocale(w http.ResponseWriter, r *http.Request) {
+	accountID, err := h.extractAccountIDFromToken(r)
+	if err != nil {
+		WriteError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var req struct {
+		Locale string `json:"locale"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	defer r.Body.Close()
+
+	locale := i18n.Normalize(req.Locale)
+	if locale == "" {
+		WriteError(w, http.StatusBadRequest, fmt.Sprintf("Unsupported locale, expected one of: %s", strings.Join(i18n.Supported(), ", ")))
+		return
+	}
+
+	account, err := h.accountRepo.GetWithIdentities(accountID)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "Failed to retrieve account")
+		return
+	}
+
+	if account == nil {
+		WriteError(w, http.StatusNotFound, "Account not found")
+		return
+	}
+
+	if err := h.accountRepo.UpdateLocale(accountID, locale); err != nil {
+		WriteError(w, http.StatusInternalServerError, "Failed to update locale")
+		return
+	}
+
+	// The bot reads accounts from the cache, drop it so the next reply uses the new language
+	account.Locale = locale
+	services.InvalidateAccountCache(account)
+
+	WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "Locale updated successfully",
+		"locale":  locale,
+	})
+}
+
 // UpdateAppIdentityUsername updates the username for the app identity
 // @Route: PUT /api/account/identity/app/username
 func (h *UserHandler) UpdateAppIdentityUsername(w http.ResponseWriter, r *http.Request) {
@@ -969,10 +1045,11 @@ func (h *UserHandler) DeleteReminder(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Delete the reminder with notification enabled
-	if err := h.reminderRepo.Delete(reminderID, true); err != nil {
+	if err := repositories.ReminderRepositoryWithContext(r.Context(), h.reminderRepo).Delete(reminderID, true); err != nil {
 		WriteError(w, http.StatusInternalServerError, "Failed to delete reminder")
 		return
 	}
+	logging.FromContext(r.Context()).Info("Reminder deleted", "reminder_id", reminderID, "account_id", accountID)
 
 	WriteJSON(w, http.StatusOK, map[string]string{
 		"message": "Reminder deleted successfully",
@@ -1087,7 +1164,8 @@ func (h *UserHandler) extractAccountIDFromToken(r *http.Request) (uuid.UUID, err
 	return accountID, nil
 }
 
-// AuthMiddleware creates a middleware that validates JWT tokens or API keys
+// AuthMiddleware creates a middleware that validates JWT tokens or API keys.
+// API keys are only accepted on routes wrapped in RequireAPIKeyScopes.
 func AuthMiddleware(sessionService *services.SessionService, apiKeyService *services.APIKeyService) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1121,17 +1199,24 @@ func AuthMiddleware(sessionService *services.SessionService, apiKeyService *serv
 			// Check if this is an API key (starts with "ck_")
 			if strings.HasPrefix(token, "ck_") {
 				// Validate API key
-				accountID, err := apiKeyService.ValidateAPIKey(token)
+				identity, err := apiKeyService.AuthenticateAPIKey(token, clientIP(r))
 				if err != nil {
-					WriteError(w, http.StatusUnauthorized, "Invalid API key")
+					writeAPIKeyAuthError(w, err)
 					return
 				}
-				if accountID == uuid.Nil {
+				if identity.AccountID == uuid.Nil {
 					WriteError(w, http.StatusUnauthorized, "Invalid API key")
 					return
 				}
-				// Add account ID to request context
-				ctx := context.WithValue(r.Context(), AccountIDKey, accountID)
+				// Only the routes declaring scopes accept API keys
+				scopes := services.ParseAPIKeyScopes(identity.Scopes)
+				if !authorizeAPIKeyScopes(w, r, scopes) {
+					return
+				}
+				// Add account ID and scopes to request context
+				ctx := context.WithValue(r.Context(), AccountIDKey, identity.AccountID)
+				ctx = context.WithValue(ctx, APIKeyAuthKey, true)
+				ctx = context.WithValue(ctx, APIKeyScopesKey, scopes)
 				*r = *r.WithContext(ctx)
 				next.ServeHTTP(w, r)
 				return
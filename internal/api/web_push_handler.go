@@ -0,0 +1,148 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/google/uuid"
+)
+
+// WebPushHandler handles browser push subscription registration
+type WebPushHandler struct {
+	webPushService   *services.WebPushService
+	subscriptionRepo repositories.WebPushSubscriptionRepository
+}
+
+// NewWebPushHandler creates a new web push handler
+func NewWebPushHandler(webPushService *services.WebPushService, subscriptionRepo repositories.WebPushSubscriptionRepository) *WebPushHandler {
+	return &WebPushHandler{
+		webPushService:   webPushService,
+		subscriptionRepo: subscriptionRepo,
+	}
+}
+
+// webPushSubscriptionRequest is the PushSubscription JSON of the browser (subscription.toJSON())
+type webPushSubscriptionRequest struct {
+	Endpoint string               `json:"endpoint"`
+	Keys     services.WebPushKeys `json:"keys"`
+}
+
+func (h *WebPushHandler) accountID(r *http.Request) (uuid.UUID, bool) {
+	val := r.Context().Value(AccountIDKey)
+	if val == nil {
+		return uuid.Nil, false
+	}
+	id, ok := val.(uuid.UUID)
+	return id, ok
+}
+
+// GetVAPIDPublicKey returns the application server key browsers subscribe with.
+// @Route: GET /api/webpush/vapid-public-key
+func (h *WebPushHandler) GetVAPIDPublicKey(w http.ResponseWriter, r *http.Request) {
+	if !h.webPushService.IsEnabled() {
+		WriteError(w, http.StatusServiceUnavailable, "Browser notifications are not configured")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]string{"public_key": h.webPushService.PublicKey()})
+}
+
+// HasSubscriptions returns whether the calling account has at least one subscribed browser.
+// @Route: GET /api/webpush/status
+func (h *WebPushHandler) HasSubscriptions(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := h.accountID(r)
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, "Account ID not found in context")
+		return
+	}
+
+	subscriptions, err := h.subscriptionRepo.GetByAccountID(accountID)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "Failed to check web push subscriptions")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]bool{
+		"enabled":  h.webPushService.IsEnabled(),
+		"has_push": len(subscriptions) > 0,
+	})
+}
+
+// RegisterSubscription registers or refreshes the push subscription of a browser.
+// @Route: POST /api/webpush/subscription
+func (h *WebPushHandler) RegisterSubscription(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := h.accountID(r)
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, "Account ID not found in context")
+		return
+	}
+
+	if !h.webPushService.IsEnabled() {
+		WriteError(w, http.StatusServiceUnavailable, "Browser notifications are not configured")
+		return
+	}
+
+	var req webPushSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	req.Endpoint = strings.TrimSpace(req.Endpoint)
+	if err := services.ValidateWebPushSubscription(req.Endpoint, req.Keys); err != nil {
+		if errors.Is(err, services.ErrInvalidWebPushSubscription) {
+			WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		WriteError(w, http.StatusInternalServerError, "Failed to register web push subscription")
+		return
+	}
+
+	subscription := &models.WebPushSubscription{
+		AccountID: accountID,
+		Endpoint:  req.Endpoint,
+		P256dh:    req.Keys.P256dh,
+		Auth:      req.Keys.Auth,
+		UserAgent: r.UserAgent(),
+	}
+	if err := h.subscriptionRepo.Upsert(subscription); err != nil {
+		WriteError(w, http.StatusInternalServerError, "Failed to register web push subscription")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Web push subscription registered"})
+}
+
+// UnregisterSubscription removes the push subscription of a browser.
+// @Route: DELETE /api/webpush/subscription
+func (h *WebPushHandler) UnregisterSubscription(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := h.accountID(r)
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, "Account ID not found in context")
+		return
+	}
+
+	var req webPushSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	req.Endpoint = strings.TrimSpace(req.Endpoint)
+	if req.Endpoint == "" {
+		WriteError(w, http.StatusBadRequest, "Endpoint is required")
+		return
+	}
+
+	if err := h.subscriptionRepo.DeleteByAccountAndEndpoint(accountID, req.Endpoint); err != nil {
+		WriteError(w, http.StatusInternalServerError, "Failed to unregister web push subscription")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Web push subscription unregistered"})
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/google/uuid"
+)
+
+// WebhookHandler handles webhook destination tooling
+type WebhookHandler struct {
+	reminderRepo repositories.ReminderRepository
+	accountRepo  repositories.AccountRepository
+	formatter    *services.WebhookFormatter
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(reminderRepo repositories.ReminderRepository, accountRepo repositories.AccountRepository) *WebhookHandler {
+	return &WebhookHandler{
+		reminderRepo: reminderRepo,
+		accountRepo:  accountRepo,
+		formatter:    services.NewWebhookFormatter(),
+	}
+}
+
+// WebhookPreviewRequest represents the request to preview a webhook payload
+type WebhookPreviewRequest struct {
+	Metadata   map[string]interface{} `json:"metadata"`    // destination metadata: template, platform, username...; url is optional
+	ReminderID *uuid.UUID             `json:"reminder_id"` // optional, renders this reminder instead of a sample
+}
+
+// WebhookPreviewResponse represents a rendered webhook payload
+type WebhookPreviewResponse struct {
+	ContentType string            `json:"content_type"`
+	Headers     map[string]string `json:"headers,omitempty"` // platform headers, e.g. ntfy priority and tags
+	Payload     json.RawMessage   `json:"payload"`           // a JSON string for plain text payloads
+}
+
+// PreviewWebhook renders the payload a webhook destination would send, without sending it
+// @Route: POST /api/webhooks/preview
+func (h *WebhookHandler) PreviewWebhook(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := r.Context().Value(AccountIDKey).(uuid.UUID)
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, "Account ID not found in context")
+		return
+	}
+
+	var req WebhookPreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	destination := &models.ReminderDestination{
+		Type:     models.DestinationWebhook,
+		Metadata: models.JSONB{},
+	}
+	for key, value := range req.Metadata {
+		destination.Metadata[key] = value
+	}
+	// Nothing is sent, the URL is only needed to pass validation
+	if _, exists := destination.Metadata["url"]; !exists {
+		destination.Metadata["url"] = "https://example.com/webhook"
+	}
+	if err := destination.ValidateMetadata(); err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	account, err := h.accountRepo.GetWithTimezone(accountID)
+	if err != nil || account == nil {
+		WriteError(w, http.StatusInternalServerError, "Failed to retrieve account")
+		return
+	}
+
+	reminder := &models.Reminder{
+		ID:          uuid.Nil,
+		AccountID:   accountID,
+		Message:     "This is a preview of your reminder",
+		RemindAtUTC: time.Now().UTC().Add(time.Hour).Truncate(time.Minute),
+		CreatedAt:   time.Now().UTC(),
+	}
+	if req.ReminderID != nil {
+		reminder, err = h.reminderRepo.GetByID(*req.ReminderID)
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, "Failed to fetch reminder")
+			return
+		}
+		if reminder == nil || reminder.AccountID != accountID {
+			WriteError(w, http.StatusNotFound, "Reminder not found")
+			return
+		}
+	}
+
+	payload, err := h.formatter.FormatPayload(reminder, destination, account)
+	if err != nil {
+		logging.FromContext(r.Context()).Warn("Webhook preview failed", "account_id", accountID, "error", err)
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	contentType := h.formatter.GetContentType(destination)
+	if contentType != "application/json" {
+		if payload, err = json.Marshal(string(payload)); err != nil {
+			WriteError(w, http.StatusInternalServerError, "Failed to render preview")
+			return
+		}
+	}
+
+	WriteJSON(w, http.StatusOK, WebhookPreviewResponse{
+		ContentType: contentType,
+		Headers:     h.formatter.GetHeaders(destination),
+		Payload:     payload,
+	})
+}
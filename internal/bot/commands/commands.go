@@ -5,8 +5,10 @@ import (
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/ericp/chronos-bot-reminder/internal/bot/utils"
 	"github.com/ericp/chronos-bot-reminder/internal/database"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 )
 
@@ -74,6 +76,7 @@ func RegisterCommands(session *discordgo.Session) (int, error) {
 	var applicationCommands []*discordgo.ApplicationCommand
 	
 	for _, cmd := range commands {
+		localizeCommandDescription(cmd.Data)
 		applicationCommands = append(applicationCommands, cmd.Data)
 	}
 	
@@ -85,6 +88,24 @@ func RegisterCommands(session *discordgo.Session) (int, error) {
 	return len(applicationCommands), nil
 }
 
+// localizeCommandDescription adds the translated descriptions of a command for the
+// Discord clients set to one of the supported languages
+func localizeCommandDescription(data *discordgo.ApplicationCommand) {
+	localizations := map[discordgo.Locale]string{}
+	for locale, discordLocales := range utils.DiscordLocales() {
+		description, ok := i18n.Lookup(locale, "command."+data.Name+".description")
+		if !ok {
+			continue
+		}
+		for _, discordLocale := range discordLocales {
+			localizations[discordLocale] = description
+		}
+	}
+	if len(localizations) > 0 {
+		data.DescriptionLocalizations = &localizations
+	}
+}
+
 // HandleCommand routes an interaction to the appropriate command handler
 func HandleCommand(session *discordgo.Session, interaction *discordgo.InteractionCreate) error {
 	if interaction.Type != discordgo.InteractionApplicationCommand {
@@ -114,6 +135,7 @@ func HandleCommand(session *discordgo.Session, interaction *discordgo.Interactio
 		if err != nil {
 			return err
 		}
+		services.SyncAccountLocale(account, interaction.Locale)
 	}
 
 	return command.Run(session, interaction, account)
@@ -147,18 +169,19 @@ func DateAutocompleteHandler(session *discordgo.Session, interaction *discordgo.
 		}
 	}
 
-	// Predefined suggestions
+	// Predefined suggestions, the values stay in English as the date parser expects them
+	locale := utils.Locale(interaction, nil)
 	suggestions := []*discordgo.ApplicationCommandOptionChoice{
-		{Name: "Today", Value: "today"},
-		{Name: "Tomorrow", Value: "tomorrow"},
-		{Name: "Next Week", Value: "next week"},
-		{Name: "Next Month", Value: "next month"},
+		{Name: i18n.T(locale, "autocomplete.today"), Value: "today"},
+		{Name: i18n.T(locale, "autocomplete.tomorrow"), Value: "tomorrow"},
+		{Name: i18n.T(locale, "autocomplete.next_week"), Value: "next week"},
+		{Name: i18n.T(locale, "autocomplete.next_month"), Value: "next month"},
 	}
 
 	// Filter suggestions based on current input
 	var filteredSuggestions []*discordgo.ApplicationCommandOptionChoice
 	for _, suggestion := range suggestions {
-		if currentInput == "" || strings.Contains(strings.ToLower(suggestion.Name), currentInput) {
+		if currentInput == "" || strings.Contains(strings.ToLower(suggestion.Name), currentInput) || strings.Contains(suggestion.Value.(string), currentInput) {
 			filteredSuggestions = append(filteredSuggestions, suggestion)
 		}
 	}
@@ -280,6 +303,7 @@ func RemindersAutocompleteHandler(session *discordgo.Session, interaction *disco
 	}
 
 	// Filter reminders based on input and create choices
+	locale := utils.Locale(interaction, account)
 	var choices []*discordgo.ApplicationCommandOptionChoice
 	for _, reminder := range allReminders {
 		// For pause/unpause commands, filter out one-time reminders
@@ -304,14 +328,14 @@ func RemindersAutocompleteHandler(session *discordgo.Session, interaction *disco
 		}
 
 		// Create a display name that includes both message and time
-		displayTime := reminder.RemindAtUTC.Format("Jan 2, 2006 15:04")
+		displayTime := i18n.FormatShortDateTime(locale, reminder.RemindAtUTC)
 		displayName := fmt.Sprintf("[%s] %s", displayTime, reminder.Message)
 		
 		// Add status indicator for pause/unpause commands
 		if subcommandName == "pause" || subcommandName == "unpause" {
-			recurrenceName := services.GetRecurrenceTypeLabel(services.GetRecurrenceType(int(reminder.Recurrence)))
+			recurrenceName := services.LocalizedRecurrenceTypeLabel(locale, services.GetRecurrenceType(int(reminder.Recurrence)))
 			if services.IsPaused(int(reminder.Recurrence)) {
-				displayName += " [⏸️ Paused]"
+				displayName += fmt.Sprintf(" [⏸️ %s]", i18n.T(locale, "reminder.status.paused_short"))
 			} else {
 				displayName += fmt.Sprintf(" [🔁 %s]", recurrenceName)
 			}
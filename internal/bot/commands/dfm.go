@@ -9,14 +9,16 @@ import (
 	"github.com/ericp/chronos-bot-reminder/internal/bot/utils"
 	"github.com/ericp/chronos-bot-reminder/internal/database"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 )
 
 // dfmHandler handles the main dfm command
 func dfmHandler(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
 	options := interaction.ApplicationCommandData().Options
+	locale := utils.Locale(interaction, account)
 	if len(options) == 0 {
-		return utils.SendError(session, interaction, "Invalid Command", "Please specify a subcommand.")
+		return utils.SendError(session, interaction, i18n.T(locale, "command.error.invalid_title"), i18n.T(locale, "command.error.invalid"))
 	}
 
 	subcommand := options[0]
@@ -38,7 +40,7 @@ func dfmHandler(session *discordgo.Session, interaction *discordgo.InteractionCr
 	case "send":
 		return logic.HandleDFMSend(session, interaction, account)
 	default:
-		return utils.SendError(session, interaction, "Unknown Subcommand", "The specified subcommand is not recognized.")
+		return utils.SendError(session, interaction, i18n.T(locale, "command.error.unknown_subcommand_title"), i18n.T(locale, "command.error.unknown_subcommand"))
 	}
 }
 
@@ -7,6 +7,7 @@ import (
 	"github.com/bwmarrin/discordgo"
 	"github.com/ericp/chronos-bot-reminder/internal/bot/utils"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 )
 
 // parseDurationString parses duration strings with various formats (e.g., "10s", "5m")
@@ -56,6 +57,7 @@ func formatDurationHourglass(d time.Duration) string {
 
 func hourglassHandler(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
 	options := interaction.ApplicationCommandData().Options
+	locale := utils.Locale(interaction, account)
 
 	var duration string
 	var message string
@@ -73,20 +75,20 @@ func hourglassHandler(session *discordgo.Session, interaction *discordgo.Interac
 	// Parse the duration
 	parsedDuration, err := parseDurationString(duration)
 	if err != nil {
-		return utils.SendError(session, interaction, "Invalid Duration Format",
-			fmt.Sprintf("Could not parse duration '%s'. Use formats like '10s' or '5m'. For longer reminders, use /remindme or /remindus commands.", duration))
+		return utils.SendError(session, interaction, i18n.T(locale, "hourglass.error.format_title"),
+			i18n.T(locale, "hourglass.error.format", duration))
 	}
 
 	// Validate duration is not too long (max 30 minutes for in-memory timer)
 	if parsedDuration > 30*time.Minute {
-		return utils.SendError(session, interaction, "Duration Too Long",
-			"The timer duration cannot exceed 30 minutes. For longer reminders, use /remindme or /remindus commands.")
+		return utils.SendError(session, interaction, i18n.T(locale, "hourglass.error.too_long_title"),
+			i18n.T(locale, "hourglass.error.too_long"))
 	}
 
 	// Validate duration is not zero or negative
 	if parsedDuration <= 0 {
-		return utils.SendError(session, interaction, "Invalid Duration",
-			"The timer duration must be greater than 0.")
+		return utils.SendError(session, interaction, i18n.T(locale, "hourglass.error.invalid_title"),
+			i18n.T(locale, "hourglass.error.invalid"))
 	}
 
 	// Get the user ID
@@ -102,10 +104,10 @@ func hourglassHandler(session *discordgo.Session, interaction *discordgo.Interac
 
 	// Send immediate acknowledgement
 	endTime := time.Now().Add(parsedDuration)
-	description := fmt.Sprintf("**Message:** %s\n**Duration:** %s\n**End Time:** <t:%d:t>",
+	description := i18n.T(locale, "hourglass.started.description",
 		message, formatDurationHourglass(parsedDuration), endTime.Unix())
 
-	if err := utils.SendEmbed(session, interaction, "⏳ Timer Started", description, nil); err != nil {
+	if err := utils.SendEmbed(session, interaction, "⏳ "+i18n.T(locale, "hourglass.started.title"), description, nil); err != nil {
 		return err
 	}
 
@@ -115,7 +117,7 @@ func hourglassHandler(session *discordgo.Session, interaction *discordgo.Interac
 
 		// Send follow-up message when timer ends
 		embed := &discordgo.MessageEmbed{
-			Title:       "⏳ Timer Finished!",
+			Title:       "⏳ " + i18n.T(locale, "hourglass.finished"),
 			Description: fmt.Sprintf("<@%s> - %s", userID, message),
 			Color:       utils.ColorSuccess,
 			Thumbnail: &discordgo.MessageEmbedThumbnail{
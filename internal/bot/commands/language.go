@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/ericp/chronos-bot-reminder/internal/bot/utils"
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+)
+
+// languageHandler shows or changes the language the bot and the emails use for the account
+func languageHandler(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
+	options := interaction.ApplicationCommandData().Options
+	locale := utils.Locale(interaction, account)
+
+	if len(options) == 0 || options[0].Name != "language" {
+		return utils.SendInfo(session, interaction, "🌐 "+i18n.T(locale, "language.current.title"),
+			i18n.T(locale, "language.current.description", i18n.Name(locale)))
+	}
+
+	if err := services.ChangeAccountLocale(account, options[0].StringValue()); err != nil {
+		return utils.SendError(session, interaction, i18n.T(locale, "error.title"), i18n.T(locale, "language.error.change_failed"))
+	}
+
+	// Confirm in the newly selected language
+	locale = account.Locale
+	return utils.SendSuccess(session, interaction, "🌐 "+i18n.T(locale, "language.changed.title"),
+		i18n.T(locale, "language.changed.description", i18n.Name(locale)), nil)
+}
+
+// languageChoices lists the supported languages, each named in its own language
+func languageChoices() []*discordgo.ApplicationCommandOptionChoice {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, locale := range i18n.Supported() {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  i18n.Name(locale),
+			Value: locale,
+		})
+	}
+	return choices
+}
+
+// Register the language command
+func init() {
+	RegisterCommand(&Command{
+		Description: Description{
+			Name:             "language",
+			Emoji:            "🌐",
+			CategoryName:     "User",
+			ShortDescription: "Choose the language of the bot",
+			FullDescription:  "Display or change the language used for the bot replies, your reminders and the emails you receive. English, French and Spanish are available.",
+			Usage:            "/language [language:<language>]",
+			Example:          "/language language:Français",
+		},
+		Data: &discordgo.ApplicationCommand{
+			Name:        "language",
+			Description: "Choose the language of the bot",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "language",
+					Description: "The language to use (leave empty to see the current one)",
+					Required:    false,
+					Choices:     languageChoices(),
+				},
+			},
+		},
+		NeedsAccount: true,
+		Run:          languageHandler,
+	})
+}
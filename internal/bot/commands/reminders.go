@@ -6,13 +6,15 @@ import (
 	"github.com/ericp/chronos-bot-reminder/internal/bot/logic"
 	"github.com/ericp/chronos-bot-reminder/internal/bot/utils"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 )
 
 // remindersHandler handles the main reminders command
 func remindersHandler(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
 	options := interaction.ApplicationCommandData().Options
+	locale := utils.Locale(interaction, account)
 	if len(options) == 0 {
-		return utils.SendError(session, interaction, "Invalid Command", "Please specify a subcommand.")
+		return utils.SendError(session, interaction, i18n.T(locale, "command.error.invalid_title"), i18n.T(locale, "command.error.invalid"))
 	}
 
 	subcommand := options[0]
@@ -28,7 +30,7 @@ func remindersHandler(session *discordgo.Session, interaction *discordgo.Interac
 	case "delete":
 		return logic.HandleDeleteReminder(session, interaction, account, subcommand.Options)
 	default:
-		return utils.SendError(session, interaction, "Unknown Subcommand", "The specified subcommand is not recognized.")
+		return utils.SendError(session, interaction, i18n.T(locale, "command.error.unknown_subcommand_title"), i18n.T(locale, "command.error.unknown_subcommand"))
 	}
 }
 
@@ -1,7 +1,6 @@
 package commands
 
 import (
-	"fmt"
 	"strings"
 	"time"
 
@@ -10,17 +9,20 @@ import (
 	"github.com/ericp/chronos-bot-reminder/internal/bot/utils"
 	"github.com/ericp/chronos-bot-reminder/internal/database"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 )
 
 // reminderHandler handles the reminder creation command
 func reminderHandler(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
 	options := interaction.ApplicationCommandData().Options
+	locale := utils.Locale(interaction, account)
 
 	var message string
 	var dateStr string
 	var timeStr string
 	var recurrenceType string = "ONCE" // Default to ONCE
+	var rrule string
 
 	// Parse command options
 	for _, option := range options {
@@ -35,6 +37,8 @@ func reminderHandler(session *discordgo.Session, interaction *discordgo.Interact
 			if option.StringValue() != "" {
 				recurrenceType = option.StringValue()
 			}
+		case "rrule":
+			rrule = option.StringValue()
 		}
 	}
 
@@ -44,28 +48,27 @@ func reminderHandler(session *discordgo.Session, interaction *discordgo.Interact
 	// Parse the reminder date and time in user's timezone
 	parsedTime, err := services.ParseReminderDateTimeInTimezone(dateStr, timeStr, account.Timezone.IANALocation)
 	if err != nil {
-		return utils.SendError(session, interaction, "Invalid Date/Time Format", 
-			fmt.Sprintf("Could not parse the date '%s' and time '%s'. Please check your date and time formats.", dateStr, timeStr))
+		return utils.SendError(session, interaction, i18n.T(locale, "remind.error.datetime_format_title"), 
+			i18n.T(locale, "remind.error.datetime_format", dateStr, timeStr))
 	}
 
 	location, err := time.LoadLocation(account.Timezone.IANALocation)
 	if err != nil {
-		return utils.SendError(session, interaction, "Invalid Timezone", 
-			fmt.Sprintf("Could not load timezone '%s'. Please check your timezone settings.", account.Timezone.IANALocation))
+		return utils.SendError(session, interaction, i18n.T(locale, "remind.error.timezone_title"), 
+			i18n.T(locale, "remind.error.timezone", account.Timezone.IANALocation))
 	}
 	now := time.Now().In(location)
 	// If the parsed reminder time is before the current time, return an error
 	if parsedTime.Before(now) {
-		return utils.SendError(session, interaction, "Invalid Date/Time", 
-			"The specified date and time is in the past. Please provide a future date and time for the reminder. You entered: " + parsedTime.Format(time.RFC3339) +
-			". Current time is: " + now.Format(time.RFC3339))
+		return utils.SendError(session, interaction, i18n.T(locale, "remind.error.past_title"), 
+			i18n.T(locale, "remind.error.past", parsedTime.Format(time.RFC3339), now.Format(time.RFC3339)))
 	}
 
 	// Get recurrence type value
 	recurrenceTypeValue, exists := services.RecurrenceTypeMap[strings.ToUpper(recurrenceType)]
 	if !exists {
-		return utils.SendError(session, interaction, "Invalid Recurrence Type", 
-			fmt.Sprintf("Invalid recurrence type '%s'. Valid options are: ONCE, YEARLY, MONTHLY, WEEKLY, DAILY, HOURLY, WORKDAYS, WEEKEND.", recurrenceType))
+		return utils.SendError(session, interaction, i18n.T(locale, "remind.error.recurrence_title"), 
+			i18n.T(locale, "remind.error.recurrence", recurrenceType))
 	}
 
 	// Create the reminder with UTC time
@@ -76,10 +79,19 @@ func reminderHandler(session *discordgo.Session, interaction *discordgo.Interact
 		Recurrence:  int16(services.BuildRecurrenceState(recurrenceTypeValue, false)),
 	}
 
+	// A custom rule overrides the recurrence choice
+	if rrule != "" {
+		if err := services.ApplyRRule(reminder, rrule, account.Timezone.IANALocation); err != nil {
+			return utils.SendError(session, interaction, i18n.T(locale, "remind.error.rrule_title"),
+				i18n.T(locale, "remind.error.rrule", rrule, err))
+		}
+		parsedTime = reminder.RemindAtUTC.In(location)
+	}
+
 	// Save the reminder to database
 	if err := repo.Reminder.Create(reminder, true); err != nil {
-		return utils.SendError(session, interaction, "Database Error", 
-			"Failed to save the reminder. Please try again later.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), 
+			i18n.T(locale, "remind.error.save"))
 	}
 
 	// userId is either the interaction user ID or the member user ID
@@ -102,32 +114,34 @@ func reminderHandler(session *discordgo.Session, interaction *discordgo.Interact
 	if err := repo.ReminderDestination.Create(destination); err != nil {
 		// If destination creation fails, we should clean up the reminder
 		repo.Reminder.Delete(reminder.ID, true)
-		return utils.SendError(session, interaction, "Database Error", 
-			"Failed to set up reminder destination. Please try again later.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), 
+			i18n.T(locale, "remind.error.destination"))
 	}
 
 	// Format response message
 	var recurrenceText string
-	if recurrenceType == "ONCE" {
-		recurrenceText = "This is a one-time reminder."
+	if reminder.RRule != nil {
+		recurrenceText = i18n.T(locale, "remind.created.rrule", *reminder.RRule)
+	} else if recurrenceType == "ONCE" {
+		recurrenceText = i18n.T(locale, "remind.created.once")
 	} else {
-		recurrenceText = fmt.Sprintf("This reminder will repeat: %s", strings.ToLower(recurrenceType))
+		recurrenceText = i18n.T(locale, "remind.created.repeat", strings.ToLower(services.LocalizedRecurrenceTypeLabel(locale, recurrenceTypeValue)))
 	}
 
 	// Load account timezone for display
 	var displayTime string
 	if account != nil && account.Timezone != nil {
 		// Display the local time as entered by the user
-		displayTime = parsedTime.Format("Monday, January 2, 2006 at 15:04")
+		displayTime = i18n.FormatDateTime(locale, parsedTime)
 	} else {
 		// Display in the same timezone as the parsed time was created
-		displayTime = parsedTime.Format("Monday, January 2, 2006 at 15:04")
+		displayTime = i18n.FormatDateTime(locale, parsedTime)
 	}
 
-	description := fmt.Sprintf("**Content:** %s\n**Remind Time:** %s", 
+	description := i18n.T(locale, "remind.created.description", 
 		message, displayTime)
 
-	return utils.SendEmbed(session, interaction, "Reminder Created! ⏰", description, &recurrenceText)
+	return utils.SendEmbed(session, interaction, i18n.T(locale, "remind.created.title")+" ⏰", description, &recurrenceText)
 }
 
 // Register the reminder command
@@ -141,7 +155,7 @@ func init() {
 			CategoryName:     "Reminders",
 			ShortDescription: "Create a new reminder",
 			FullDescription:  "Create a new reminder that will be sent to you via direct message at the specified date and time",
-			Usage:            "/remindme message:<text> date:<date> time:<time> [recurrence:<type>]",
+			Usage:            "/remindme message:<text> date:<date> time:<time> [recurrence:<type>] [rrule:<rule>]",
 			Example:          "/remindme message:\"Take medicine\" date:\"25/12/2024\" time:\"15:30\" recurrence:daily",
 		},
 		Data: &discordgo.ApplicationCommand{
@@ -207,6 +221,12 @@ func init() {
 						},
 					},
 				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "rrule",
+					Description: "Custom RFC 5545 rule, overrides recurrence (e.g. 'FREQ=MONTHLY;BYDAY=-1FR')",
+					Required:    false,
+				},
 			},
 		},
 		NeedsAccount: true,
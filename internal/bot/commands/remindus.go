@@ -1,7 +1,6 @@
 package commands
 
 import (
-	"fmt"
 	"strings"
 	"time"
 
@@ -10,6 +9,7 @@ import (
 	"github.com/ericp/chronos-bot-reminder/internal/bot/utils"
 	"github.com/ericp/chronos-bot-reminder/internal/database"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 )
 
@@ -45,6 +45,8 @@ func getRoleFromCache(session *discordgo.Session, guildID, roleID string, roleCa
 
 // remindUsHandler handles the remind us command
 func remindUsHandler(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
+	locale := utils.Locale(interaction, account)
+
 	// Defer the interaction response immediately to avoid timeout
 	session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
@@ -52,8 +54,8 @@ func remindUsHandler(session *discordgo.Session, interaction *discordgo.Interact
 
 	// Check if the command is being used in a server (not DM)
 	if interaction.GuildID == "" {
-		return utils.SendErrorDeferred(session, interaction, "Server Required", 
-			"The `/remindus` command can only be used in a server, not in direct messages. Use `/remindme` for personal reminders.", nil, true)
+		return utils.SendErrorDeferred(session, interaction, i18n.T(locale, "remindus.error.server_required_title"), 
+			i18n.T(locale, "remindus.error.server_required"), nil, true)
 	}
 
 	options := interaction.ApplicationCommandData().Options
@@ -64,6 +66,7 @@ func remindUsHandler(session *discordgo.Session, interaction *discordgo.Interact
 	var channelID string
 	var roleID string
 	var recurrenceType string = "ONCE"
+	var rrule string
 
 	// Parse command options
 	for _, option := range options {
@@ -94,29 +97,31 @@ func remindUsHandler(session *discordgo.Session, interaction *discordgo.Interact
 			if option.StringValue() != "" {
 				recurrenceType = option.StringValue()
 			}
+		case "rrule":
+			rrule = option.StringValue()
 		}
 	}
 
 	// Validate that a channel was selected
 	if channelID == "" {
-		return utils.SendErrorDeferred(session, interaction, "Channel Required", 
-			"Please select a channel where the reminder should be sent.", nil, true)
+		return utils.SendErrorDeferred(session, interaction, i18n.T(locale, "remindus.error.channel_required_title"), 
+			i18n.T(locale, "remindus.error.channel_required"), nil, true)
 	}
 
 	// Validate required fields
 	if message == "" {
-		return utils.SendErrorDeferred(session, interaction, "Message Required", 
-			"Please provide a message for the reminder.", nil, true)
+		return utils.SendErrorDeferred(session, interaction, i18n.T(locale, "remindus.error.message_required_title"), 
+			i18n.T(locale, "remindus.error.message_required"), nil, true)
 	}
 	
 	if dateStr == "" {
-		return utils.SendErrorDeferred(session, interaction, "Date Required", 
-			"Please provide a date for the reminder.", nil, true)
+		return utils.SendErrorDeferred(session, interaction, i18n.T(locale, "remindus.error.date_required_title"), 
+			i18n.T(locale, "remindus.error.date_required"), nil, true)
 	}
 	
 	if timeStr == "" {
-		return utils.SendErrorDeferred(session, interaction, "Time Required", 
-			"Please provide a time for the reminder.", nil, true)
+		return utils.SendErrorDeferred(session, interaction, i18n.T(locale, "remindus.error.time_required_title"), 
+			i18n.T(locale, "remindus.error.time_required"), nil, true)
 	}
 
 	// Get the user ID for permission checking
@@ -126,22 +131,22 @@ func remindUsHandler(session *discordgo.Session, interaction *discordgo.Interact
 	} else if interaction.User != nil {
 		userID = interaction.User.ID
 	} else {
-		return utils.SendErrorDeferred(session, interaction, "User Information Missing", 
-			"Could not determine user information for permission check.", nil, true)
+		return utils.SendErrorDeferred(session, interaction, i18n.T(locale, "remindus.error.user_missing_title"), 
+			i18n.T(locale, "remindus.error.user_missing"), nil, true)
 	}
 
 	// Cache guild roles to avoid multiple API calls
 	roleCache, err := getGuildRolesCache(session, interaction.GuildID)
 	if err != nil {
-		return utils.SendErrorDeferred(session, interaction, "Permission Check Failed", 
-			"Could not verify your permissions for the selected channel.", nil, true)
+		return utils.SendErrorDeferred(session, interaction, i18n.T(locale, "remindus.error.permission_check_title"), 
+			i18n.T(locale, "remindus.error.permission_check"), nil, true)
 	}
 
 	// Verify the user has manage channel permissions, administrator permissions, or is the server owner
 	channelPerms, err := session.UserChannelPermissions(userID, channelID)
 	if err != nil {
-		return utils.SendErrorDeferred(session, interaction, "Permission Check Failed", 
-			"Could not verify your permissions for the selected channel.", nil, true)
+		return utils.SendErrorDeferred(session, interaction, i18n.T(locale, "remindus.error.permission_check_title"), 
+			i18n.T(locale, "remindus.error.permission_check"), nil, true)
 	}
 
 	userPerms := interaction.Member.Permissions
@@ -151,16 +156,16 @@ func remindUsHandler(session *discordgo.Session, interaction *discordgo.Interact
 	isAllowed := err == nil && (guild.OwnerID == userID || hasPerm(userPerms, discordgo.PermissionAdministrator) || hasPerm(userPerms, discordgo.PermissionManageChannels) || hasPerm(channelPerms, discordgo.PermissionManageChannels))
 
 	if !isAllowed {
-		return utils.SendErrorDeferred(session, interaction, "Insufficient Permissions", 
-			"You need 'Manage Channel', 'Administrator' permission, or be the server owner to create reminders in the selected channel.", nil, true)
+		return utils.SendErrorDeferred(session, interaction, i18n.T(locale, "remindus.error.insufficient_title"), 
+			i18n.T(locale, "remindus.error.insufficient"), nil, true)
 	}
 
 	// If a role is specified, validate role mention permissions
 	if roleID != "" {
 		botMember, err := session.GuildMember(interaction.GuildID, session.State.User.ID)
 		if err != nil {
-			return utils.SendErrorDeferred(session, interaction, "Bot Permission Check Failed", 
-				"Could not verify bot's permissions to mention roles.", nil, true)
+			return utils.SendErrorDeferred(session, interaction, i18n.T(locale, "remindus.error.bot_permission_check_title"), 
+				i18n.T(locale, "remindus.error.bot_permission_check"), nil, true)
 		}
 
 		// Check guild-wide permissions
@@ -190,21 +195,21 @@ func remindUsHandler(session *discordgo.Session, interaction *discordgo.Interact
 		}
 
 		if !botHasPermission {
-			return utils.SendErrorDeferred(session, interaction, "Bot Insufficient Permissions", 
-				"The bot needs 'Mention Everyone', 'Manage Roles', or 'Administrator' permission to mention roles in reminders.", nil, true)
+			return utils.SendErrorDeferred(session, interaction, i18n.T(locale, "remindus.error.bot_insufficient_title"), 
+				i18n.T(locale, "remindus.error.bot_insufficient"), nil, true)
 		}
 
 		// Get the role to validate
 		role := getRoleFromCache(session, interaction.GuildID, roleID, roleCache)
 		if role == nil {
-			return utils.SendErrorDeferred(session, interaction, "Invalid Role", 
-				"The specified role could not be found.", nil, true)
+			return utils.SendErrorDeferred(session, interaction, i18n.T(locale, "remindus.error.invalid_role_title"), 
+				i18n.T(locale, "remindus.error.invalid_role"), nil, true)
 		}
 
 		// Check if user has manage roles permission
 		if !hasPerm(userPerms, discordgo.PermissionManageRoles) {
-			return utils.SendErrorDeferred(session, interaction, "Role Permission Required", 
-				"You need 'Manage Roles' permission to mention roles in reminders.", nil, true)
+			return utils.SendErrorDeferred(session, interaction, i18n.T(locale, "remindus.error.role_permission_title"), 
+				i18n.T(locale, "remindus.error.role_permission"), nil, true)
 		}
 
 		// Bot must be able to mention the role (role must be lower than bot's highest role)
@@ -217,36 +222,35 @@ func remindUsHandler(session *discordgo.Session, interaction *discordgo.Interact
 		}
 
 		if role.Position >= botHighestRolePos {
-			return utils.SendErrorDeferred(session, interaction, "Bot Role Hierarchy Insufficient", 
-				"The bot's highest role must be higher than the specified role to mention it in reminders.", nil, true)
+			return utils.SendErrorDeferred(session, interaction, i18n.T(locale, "remindus.error.role_hierarchy_title"), 
+				i18n.T(locale, "remindus.error.role_hierarchy"), nil, true)
 		}
 	}
 
 	// Parse the reminder date and time in user's timezone
 	parsedTime, err := services.ParseReminderDateTimeInTimezone(dateStr, timeStr, account.Timezone.IANALocation)
 	if err != nil {
-		return utils.SendErrorDeferred(session, interaction, "Invalid Date/Time Format", 
-			fmt.Sprintf("Could not parse the date '%s' and time '%s'. Please check your date and time formats.", dateStr, timeStr), nil, true)
+		return utils.SendErrorDeferred(session, interaction, i18n.T(locale, "remind.error.datetime_format_title"), 
+			i18n.T(locale, "remind.error.datetime_format", dateStr, timeStr), nil, true)
 	}
 
 	location, err := time.LoadLocation(account.Timezone.IANALocation)
 	if err != nil {
-		return utils.SendErrorDeferred(session, interaction, "Invalid Timezone", 
-			fmt.Sprintf("Could not load timezone '%s'. Please check your timezone settings.", account.Timezone.IANALocation), nil, true)
+		return utils.SendErrorDeferred(session, interaction, i18n.T(locale, "remind.error.timezone_title"), 
+			i18n.T(locale, "remind.error.timezone", account.Timezone.IANALocation), nil, true)
 	}
 	now := time.Now().In(location)
 	// If the parsed reminder time is before the current time, return an error
 	if parsedTime.Before(now) {
-		return utils.SendErrorDeferred(session, interaction, "Invalid Date/Time", 
-			"The specified date and time is in the past. Please provide a future date and time for the reminder. You entered: "+parsedTime.Format(time.RFC3339)+
-			". Current time is: "+now.Format(time.RFC3339), nil, true)
+		return utils.SendErrorDeferred(session, interaction, i18n.T(locale, "remind.error.past_title"), 
+			i18n.T(locale, "remind.error.past", parsedTime.Format(time.RFC3339), now.Format(time.RFC3339)), nil, true)
 	}
 
 	// Get recurrence type value
 	recurrenceTypeValue, exists := services.RecurrenceTypeMap[strings.ToUpper(recurrenceType)]
 	if !exists {
-		return utils.SendErrorDeferred(session, interaction, "Invalid Recurrence Type", 
-			fmt.Sprintf("Invalid recurrence type '%s'. Valid options are: ONCE, YEARLY, MONTHLY, WEEKLY, DAILY, HOURLY, WORKDAYS, WEEKEND.", recurrenceType), nil, true)
+		return utils.SendErrorDeferred(session, interaction, i18n.T(locale, "remind.error.recurrence_title"), 
+			i18n.T(locale, "remind.error.recurrence", recurrenceType), nil, true)
 	}
 
 	// Create the reminder with UTC time
@@ -257,12 +261,21 @@ func remindUsHandler(session *discordgo.Session, interaction *discordgo.Interact
 		Recurrence:  int16(services.BuildRecurrenceState(recurrenceTypeValue, false)),
 	}
 
+	// A custom rule overrides the recurrence choice
+	if rrule != "" {
+		if err := services.ApplyRRule(reminder, rrule, account.Timezone.IANALocation); err != nil {
+			return utils.SendErrorDeferred(session, interaction, i18n.T(locale, "remind.error.rrule_title"),
+				i18n.T(locale, "remind.error.rrule", rrule, err), nil, true)
+		}
+		parsedTime = reminder.RemindAtUTC.In(location)
+	}
+
 	repo := database.GetRepositories()
 
 	// Save the reminder to database
 	if err := repo.Reminder.Create(reminder, true); err != nil {
-		return utils.SendErrorDeferred(session, interaction, "Database Error", 
-			"Failed to save the reminder. Please try again later.", nil, true)
+		return utils.SendErrorDeferred(session, interaction, i18n.T(locale, "error.database"), 
+			i18n.T(locale, "remind.error.save"), nil, true)
 	}
 
 	// Create the discord_channel destination
@@ -285,37 +298,39 @@ func remindUsHandler(session *discordgo.Session, interaction *discordgo.Interact
 	if err := repo.ReminderDestination.Create(destination); err != nil {
 		// If destination creation fails, we should clean up the reminder
 		repo.Reminder.Delete(reminder.ID, true)
-		return utils.SendErrorDeferred(session, interaction, "Database Error", 
-			"Failed to set up reminder destination. Please try again later.", nil, true)
+		return utils.SendErrorDeferred(session, interaction, i18n.T(locale, "error.database"), 
+			i18n.T(locale, "remind.error.destination"), nil, true)
 	}
 
 	// Format response message
 	var recurrenceText string
-	if recurrenceType == "ONCE" {
-		recurrenceText = "This is a one-time reminder."
+	if reminder.RRule != nil {
+		recurrenceText = i18n.T(locale, "remind.created.rrule", *reminder.RRule)
+	} else if recurrenceType == "ONCE" {
+		recurrenceText = i18n.T(locale, "remind.created.once")
 	} else {
-		recurrenceText = fmt.Sprintf("This reminder will repeat: %s", strings.ToLower(recurrenceType))
+		recurrenceText = i18n.T(locale, "remind.created.repeat", strings.ToLower(services.LocalizedRecurrenceTypeLabel(locale, recurrenceTypeValue)))
 	}
 
 	// Load account timezone for display
 	var displayTime string
 	if account != nil && account.Timezone != nil {
 		// Display the local time as entered by the user
-		displayTime = parsedTime.Format("Monday, January 2, 2006 at 15:04")
+		displayTime = i18n.FormatDateTime(locale, parsedTime)
 	} else {
 		// Display in the same timezone as the parsed time was created
-		displayTime = parsedTime.Format("Monday, January 2, 2006 at 15:04")
+		displayTime = i18n.FormatDateTime(locale, parsedTime)
 	}
 
-	description := fmt.Sprintf("**Content:** %s\n**Remind Time:** %s\n**Channel:** <#%s>", 
-		message, displayTime, channelID)
+	description := i18n.T(locale, "remind.created.description", 
+		message, displayTime) + i18n.T(locale, "remindus.created.channel", channelID)
 	
 	// Add role mention info if specified
 	if roleID != "" {
-		description += fmt.Sprintf("\n**Role Mention:** <@&%s>", roleID)
+		description += i18n.T(locale, "remindus.created.role", roleID)
 	}
 
-	return utils.SendEmbedDeferred(session, interaction, "Channel Reminder Created! 📢", description, &recurrenceText, true)
+	return utils.SendEmbedDeferred(session, interaction, i18n.T(locale, "remindus.created.title")+" 📢", description, &recurrenceText, true)
 }
 
 func init() {
@@ -328,7 +343,7 @@ func init() {
 			CategoryName:     "Reminders",
 			ShortDescription: "Create a new reminder in a channel",
 			FullDescription:  "Create a new reminder that will be sent in a specified channel at the specified date and time. Requires 'Manage Channel', 'Administrator' permission, or server ownership.",
-			Usage:            "/remindus message:<text> date:<date> time:<time> channel:<channel> [role:<role>] [recurrence:<type>]",
+			Usage:            "/remindus message:<text> date:<date> time:<time> channel:<channel> [role:<role>] [recurrence:<type>] [rrule:<rule>]",
 			Example:          "/remindus message:\"Team meeting\" date:\"25/12/2024\" time:\"10:00\" channel:#general role:@developers recurrence:weekly",
 		},
 		Data: &discordgo.ApplicationCommand{
@@ -406,6 +421,12 @@ func init() {
 						},
 					},
 				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "rrule",
+					Description: "Custom RFC 5545 rule, overrides recurrence (e.g. 'FREQ=MONTHLY;BYDAY=-1FR')",
+					Required:    false,
+				},
 			},
 		},
 		NeedsAccount: true,
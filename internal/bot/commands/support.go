@@ -5,16 +5,18 @@ import (
 	"github.com/ericp/chronos-bot-reminder/internal/bot/utils"
 	"github.com/ericp/chronos-bot-reminder/internal/config"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 )
 
 func supportHandler(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
-	description := "Need help? Here are the resources available:\n\n" +
-		"📖 **Documentation & Contact**: " + config.URLWebApp + "\n" +
-		"Visit our website for full documentation, FAQ, and contact information.\n\n" +
-		"💬 **Official Discord Server**: " + config.URLDiscordInvite + "\n" +
-		"Join our community to get support, share feedback, and connect with other users."
+	locale := utils.Locale(interaction, account)
+	description := i18n.T(locale, "support.intro") + "\n\n" +
+		"📖 **" + i18n.T(locale, "support.documentation") + "**: " + config.URLWebApp + "\n" +
+		i18n.T(locale, "support.documentation_hint") + "\n\n" +
+		"💬 **" + i18n.T(locale, "support.discord") + "**: " + config.URLDiscordInvite + "\n" +
+		i18n.T(locale, "support.discord_hint")
 
-	return utils.SendEmbed(session, interaction, "💡 Need Support?", description, nil)
+	return utils.SendEmbed(session, interaction, "💡 "+i18n.T(locale, "support.title"), description, nil)
 }
 
 func init() {
@@ -5,18 +5,20 @@ import (
 	"github.com/ericp/chronos-bot-reminder/internal/bot/utils"
 	"github.com/ericp/chronos-bot-reminder/internal/config"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 )
 
 func termsHandler(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
-	description := "📋 **Terms & Privacy Policy**: " + config.URLWebApp + "/terms\n\n" +
-		"Chronos is a personal project with no company, no investors, and no monetization plans.\n\n" +
-		"**Key points:**\n" +
-		"🔒 Your password is bcrypt-hashed - nobody can read it, including the developer\n" +
-		"🚫 Your data is never shared with or sold to any third party\n" +
-		"🗑️ You can delete your account and all associated data at any time\n" +
-		"💻 Self-hosting is available if you want full control over your data"
+	locale := utils.Locale(interaction, account)
+	description := "📋 **" + i18n.T(locale, "terms.policy") + "**: " + config.URLWebApp + "/terms\n\n" +
+		i18n.T(locale, "terms.personal_project") + "\n\n" +
+		"**" + i18n.T(locale, "terms.key_points") + "**\n" +
+		"🔒 " + i18n.T(locale, "terms.password") + "\n" +
+		"🚫 " + i18n.T(locale, "terms.no_sharing") + "\n" +
+		"🗑️ " + i18n.T(locale, "terms.deletion") + "\n" +
+		"💻 " + i18n.T(locale, "terms.self_hosting")
 
-	return utils.SendEmbed(session, interaction, "📜 Terms & Privacy", description, nil)
+	return utils.SendEmbed(session, interaction, "📜 "+i18n.T(locale, "terms.title"), description, nil)
 }
 
 func init() {
@@ -5,6 +5,7 @@ import (
 
 	"github.com/ericp/chronos-bot-reminder/internal/bot/utils"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 )
 
 // Register the tic command
@@ -25,8 +26,9 @@ func init() {
 			Options:     nil,
 		},
 		NeedsAccount: false,
-		Run: 	  func(session *discordgo.Session, interaction *discordgo.InteractionCreate, _ *models.Account) error {
-			return utils.SendInfo(session, interaction, "The bot is alive!", "⏰ Tac !")
+		Run: 	  func(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
+			locale := utils.Locale(interaction, account)
+			return utils.SendInfo(session, interaction, i18n.T(locale, "tic.alive"), "⏰ "+i18n.T(locale, "tic.tac"))
 		},
 	})
 }
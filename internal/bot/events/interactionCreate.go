@@ -1,29 +1,51 @@
 package events
 
 import (
-	"log"
+	"context"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/ericp/chronos-bot-reminder/internal/bot/commands"
 	"github.com/ericp/chronos-bot-reminder/internal/bot/handlers"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
 )
 
 func InteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// The interaction ID correlates the records of an interaction
+	ctx := logging.WithCorrelationID(context.Background(), i.ID)
+	logger := logging.For(logging.Bot).With("interaction_type", i.Type.String())
+	if i.Type == discordgo.InteractionApplicationCommand || i.Type == discordgo.InteractionApplicationCommandAutocomplete {
+		logger = logger.With("command", i.ApplicationCommandData().Name)
+	}
+	if user := interactionUser(i); user != nil {
+		logger = logger.With("discord_user_id", user.ID)
+	}
+
+	startedAt := time.Now()
+	var err error
 	switch i.Type {
 	case discordgo.InteractionApplicationCommand:
-		err := commands.HandleCommand(s, i)
-		if err != nil {
-			log.Printf("[DISCORD_BOT] - ❌ Error handling command: %v", err)
-		}
+		err = commands.HandleCommand(s, i)
 	case discordgo.InteractionApplicationCommandAutocomplete:
-		err := commands.HandleAutocomplete(s, i)
-		if err != nil {
-			log.Printf("[DISCORD_BOT] - ❌ Error handling autocomplete: %v", err)
-		}
+		err = commands.HandleAutocomplete(s, i)
 	case discordgo.InteractionMessageComponent:
-		err := handlers.HandleMessageComponent(s, i)
-		if err != nil {
-			log.Printf("[DISCORD_BOT] - ❌ Error handling message component: %v", err)
-		}
+		logger = logger.With("custom_id", i.MessageComponentData().CustomID)
+		err = handlers.HandleMessageComponent(s, i)
+	default:
+		return
+	}
+
+	if err != nil {
+		logger.ErrorContext(ctx, "Error handling interaction", "duration", time.Since(startedAt), "error", err)
+		return
+	}
+	logger.DebugContext(ctx, "Interaction handled", "duration", time.Since(startedAt))
+}
+
+// interactionUser returns the user behind an interaction, in a guild or in DMs
+func interactionUser(i *discordgo.InteractionCreate) *discordgo.User {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User
 	}
-}
\ No newline at end of file
+	return i.User
+}
@@ -1,16 +1,16 @@
 package events
 
 import (
-	"log"
-
 	"github.com/bwmarrin/discordgo"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
 )
 
 func Ready(s *discordgo.Session, event *discordgo.Ready) {
-	log.Printf("[DISCORD_BOT] - 🤖 Bot is ready! Logged in as: %s#%s", event.User.Username, event.User.Discriminator)
+	logger := logging.For(logging.Bot)
+	logger.Info("Bot is ready", "username", event.User.Username, "discriminator", event.User.Discriminator)
 
 	err := s.UpdateListeningStatus("⏳ https://chronosrmd.com ⌛️")
 	if err != nil {
-		log.Printf("[DISCORD_BOT] - ⚠️ Error setting bot status: %v", err)
+		logger.Warn("Error setting bot status", "error", err)
 	}
-}
\ No newline at end of file
+}
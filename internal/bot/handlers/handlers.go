@@ -45,6 +45,7 @@ func HandleMessageComponent(s *discordgo.Session, i *discordgo.InteractionCreate
 				if err != nil {
 					return err
 				}
+				services.SyncAccountLocale(account, i.Locale)
 			}
 			
 			return handler.Handler(s, i, account)
@@ -9,12 +9,14 @@ import (
 	"github.com/bwmarrin/discordgo"
 	"github.com/ericp/chronos-bot-reminder/internal/bot/utils"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 )
 
 // calculTimeHandler handles the time calculation command
 func CalculTimeHandler(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
 	options := interaction.ApplicationCommandData().Options
+	locale := utils.Locale(interaction, account)
 
 	var timeOne string
 	var operation string
@@ -34,15 +36,15 @@ func CalculTimeHandler(session *discordgo.Session, interaction *discordgo.Intera
 
 	// Validate inputs
 	if timeOne == "" || operation == "" || timeTwo == "" {
-		return utils.SendError(session, interaction, "Missing Parameters", 
-			"Please provide all required parameters: time1, operation, and time2/factor.")
+		return utils.SendError(session, interaction, i18n.T(locale, "calcul.error.missing_title"), 
+			i18n.T(locale, "calcul.error.missing_description"))
 	}
 
 	// Parse the first time
 	parsedTime1, err := parseTimeInput(timeOne, account)
 	if err != nil {
-		return utils.SendError(session, interaction, "Invalid Time Format", 
-			fmt.Sprintf("Could not parse time1 '%s'. Use formats like '2h 30m', '14:30', or '2.5h'.", timeOne))
+		return utils.SendError(session, interaction, i18n.T(locale, "calcul.error.invalid_time_title"), 
+			i18n.T(locale, "calcul.error.invalid_time_description", "time1", timeOne))
 	}
 
 	var result time.Duration
@@ -52,8 +54,8 @@ func CalculTimeHandler(session *discordgo.Session, interaction *discordgo.Intera
 	case "ADD", "+":
 		parsedTime2, err := parseTimeInput(timeTwo, account)
 		if err != nil {
-			return utils.SendError(session, interaction, "Invalid Time Format", 
-				fmt.Sprintf("Could not parse time2 '%s'. Use formats like '2h 30m', '14:30', or '2.5h'.", timeTwo))
+			return utils.SendError(session, interaction, i18n.T(locale, "calcul.error.invalid_time_title"), 
+				i18n.T(locale, "calcul.error.invalid_time_description", "time2", timeTwo))
 		}
 		result = parsedTime1 + parsedTime2
 		resultStr = fmt.Sprintf("%s + %s = %s", 
@@ -62,8 +64,8 @@ func CalculTimeHandler(session *discordgo.Session, interaction *discordgo.Intera
 	case "SUBTRACT", "SUB", "-":
 		parsedTime2, err := parseTimeInput(timeTwo, account)
 		if err != nil {
-			return utils.SendError(session, interaction, "Invalid Time Format", 
-				fmt.Sprintf("Could not parse time2 '%s'. Use formats like '2h 30m', '14:30', or '2.5h'.", timeTwo))
+			return utils.SendError(session, interaction, i18n.T(locale, "calcul.error.invalid_time_title"), 
+				i18n.T(locale, "calcul.error.invalid_time_description", "time2", timeTwo))
 		}
 		result = parsedTime1 - parsedTime2
 		if result < 0 {
@@ -78,8 +80,8 @@ func CalculTimeHandler(session *discordgo.Session, interaction *discordgo.Intera
 	case "MULTIPLY", "MUL", "*", "×":
 		factor, err := parseFactorInput(timeTwo)
 		if err != nil {
-			return utils.SendError(session, interaction, "Invalid Factor", 
-				fmt.Sprintf("Could not parse factor '%s'. Use a number like '2', '1.5', or '0.5'.", timeTwo))
+			return utils.SendError(session, interaction, i18n.T(locale, "calcul.error.invalid_factor_title"), 
+				i18n.T(locale, "calcul.error.invalid_factor_description", timeTwo))
 		}
 		result = time.Duration(float64(parsedTime1) * factor)
 		resultStr = fmt.Sprintf("%s × %.2f = %s", 
@@ -88,29 +90,29 @@ func CalculTimeHandler(session *discordgo.Session, interaction *discordgo.Intera
 	case "DIVIDE", "DIV", "/", "÷":
 		factor, err := parseFactorInput(timeTwo)
 		if err != nil {
-			return utils.SendError(session, interaction, "Invalid Factor", 
-				fmt.Sprintf("Could not parse factor '%s'. Use a number like '2', '1.5', or '0.5'.", timeTwo))
+			return utils.SendError(session, interaction, i18n.T(locale, "calcul.error.invalid_factor_title"), 
+				i18n.T(locale, "calcul.error.invalid_factor_description", timeTwo))
 		}
 		if factor == 0 {
-			return utils.SendError(session, interaction, "Division by Zero", 
-				"Cannot divide by zero.")
+			return utils.SendError(session, interaction, i18n.T(locale, "calcul.error.division_title"), 
+				i18n.T(locale, "calcul.error.division_description"))
 		}
 		result = time.Duration(float64(parsedTime1) / factor)
 		resultStr = fmt.Sprintf("%s ÷ %.2f = %s", 
 			formatDuration(parsedTime1), factor, formatDuration(result))
 
 	default:
-		return utils.SendError(session, interaction, "Invalid Operation", 
-			fmt.Sprintf("Unknown operation '%s'. Use: add (+), subtract (-), multiply (×), or divide (÷).", operation))
+		return utils.SendError(session, interaction, i18n.T(locale, "calcul.error.operation_title"), 
+			i18n.T(locale, "calcul.error.operation_description", operation))
 	}
 
 	// Format additional information
-	additionalInfo := fmt.Sprintf("**Result in different formats:**\n• Hours: %.2f h\n• Minutes: %.0f min\n• Seconds: %.0f sec", 
+	additionalInfo := i18n.T(locale, "calcul.result.formats", 
 		result.Hours(), result.Minutes(), result.Seconds())
 
-	description := fmt.Sprintf("**Calculation:** %s\n\n%s", resultStr, additionalInfo)
+	description := i18n.T(locale, "calcul.result.description", resultStr, additionalInfo)
 
-	return utils.SendEmbed(session, interaction, "Time Calculation Result 🧮", description, nil)
+	return utils.SendEmbed(session, interaction, i18n.T(locale, "calcul.result.title")+" 🧮", description, nil)
 }
 
 // parseTimeInput parses various time input formats and returns a duration
@@ -8,40 +8,41 @@ import (
 	"github.com/ericp/chronos-bot-reminder/internal/bot/utils"
 	"github.com/ericp/chronos-bot-reminder/internal/database"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 )
 
-// BuildReminderEmbed creates a detailed embed for a reminder with its destinations
-func BuildReminderEmbed(session *discordgo.Session, reminder *models.Reminder) *discordgo.MessageEmbed {
+// BuildReminderEmbed creates a detailed embed for a reminder with its destinations, in the given locale
+func BuildReminderEmbed(session *discordgo.Session, reminder *models.Reminder, locale string) *discordgo.MessageEmbed {
 	// Format the reminder time
-	remindTimeStr := reminder.RemindAtUTC.Format("Monday, January 2, 2006 at 15:04")
+	remindTimeStr := i18n.FormatDateTime(locale, reminder.RemindAtUTC)
 
 	// Determine status
-	status := "✅ Active"
+	status := "✅ " + i18n.T(locale, "reminder.status.active")
 	if services.IsPaused(int(reminder.Recurrence)) {
-		status = "⏸️ Paused"
+		status = "⏸️ " + i18n.T(locale, "reminder.status.paused")
 	}
 
 	embed := &discordgo.MessageEmbed{
-		Title:       "📝 Reminder Details",
-		Description: fmt.Sprintf("**Message:** %s", reminder.Message),
+		Title:       "📝 " + i18n.T(locale, "reminder.details.title"),
+		Description: fmt.Sprintf("**%s** %s", i18n.T(locale, "reminder.field.message"), reminder.Message),
 		Color:       utils.ColorInfo,
 		Thumbnail: &discordgo.MessageEmbedThumbnail{
 			URL: utils.ClockLogo,
 		},
 		Fields: []*discordgo.MessageEmbedField{
 			{
-				Name:   "⏰ Remind Time",
+				Name:   "⏰ " + i18n.T(locale, "reminder.details.remind_time"),
 				Value:  remindTimeStr,
 				Inline: true,
 			},
 			{
-				Name:   "📊 Status",
+				Name:   "📊 " + i18n.T(locale, "reminder.details.status"),
 				Value:  status,
 				Inline: true,
 			},
 			{
-				Name:   "🆔 Reminder ID",
+				Name:   "🆔 " + i18n.T(locale, "reminder.details.id"),
 				Value:  reminder.ID.String(),
 				Inline: false,
 			},
@@ -54,7 +55,7 @@ func BuildReminderEmbed(session *discordgo.Session, reminder *models.Reminder) *
 
 	// Add owner information if different from viewer
 	if reminder.Account != nil {
-		ownerInfo := fmt.Sprintf("Account ID: %s", reminder.Account.ID.String())
+		ownerInfo := i18n.T(locale, "reminder.details.account_id", reminder.Account.ID.String())
 		// Try to get Discord identity for better display
 		repo := database.GetRepositories()
 		identities, err := repo.Identity.GetByAccountID(reminder.Account.ID)
@@ -69,7 +70,7 @@ func BuildReminderEmbed(session *discordgo.Session, reminder *models.Reminder) *
 		}
 
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-			Name:   "👤 Created By",
+			Name:   "👤 " + i18n.T(locale, "reminder.details.created_by"),
 			Value:  ownerInfo,
 			Inline: true,
 		})
@@ -78,9 +79,9 @@ func BuildReminderEmbed(session *discordgo.Session, reminder *models.Reminder) *
 	// If the reminder has a recurrence different from one-time, show it
 	recurrenceType := services.GetRecurrenceType(int(reminder.Recurrence))
 	if recurrenceType != services.RecurrenceOnce {
-		recurrenceStr := services.GetRecurrenceTypeLabel(recurrenceType)
+		recurrenceStr := services.LocalizedRecurrenceTypeLabel(locale, recurrenceType)
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-			Name:   "🔁 Recurrence",
+			Name:   "🔁 " + i18n.T(locale, "reminder.details.recurrence"),
 			Value:  recurrenceStr,
 			Inline: true,
 		})
@@ -89,7 +90,7 @@ func BuildReminderEmbed(session *discordgo.Session, reminder *models.Reminder) *
 	// Add destinations
 	if len(reminder.Destinations) > 0 {
 		for i, dest := range reminder.Destinations {
-			destField := buildDestinationField(dest, i+1)
+			destField := buildDestinationField(dest, i+1, locale)
 			embed.Fields = append(embed.Fields, destField)
 		}
 	}
@@ -98,38 +99,45 @@ func BuildReminderEmbed(session *discordgo.Session, reminder *models.Reminder) *
 }
 
 // buildDestinationField creates an embed field for a destination
-func buildDestinationField(dest models.ReminderDestination, index int) *discordgo.MessageEmbedField {
-	fieldName := fmt.Sprintf("📍 Destination %d", index)
+func buildDestinationField(dest models.ReminderDestination, index int, locale string) *discordgo.MessageEmbedField {
+	fieldName := "📍 " + i18n.T(locale, "destination.title", index)
 	var fieldValue string
 
+	// line renders a "**Label:** value" line of the field
+	line := func(labelKey string, value string) string {
+		return fmt.Sprintf("**%s** %s", i18n.T(locale, labelKey), value)
+	}
+	unknown := i18n.T(locale, "destination.unknown")
+	invalid := i18n.T(locale, "destination.invalid")
+
 	switch dest.Type {
 	case models.DestinationDiscordDM:
 		if userID, exists := dest.Metadata["user_id"]; exists {
 			if userIDStr, ok := userID.(string); ok {
-				fieldValue = fmt.Sprintf("**Type:** Discord DM\n**User:** <@%s>", userIDStr)
+				fieldValue = line("destination.type", "Discord DM") + "\n" + line("destination.user", fmt.Sprintf("<@%s>", userIDStr))
 			} else {
-				fieldValue = "**Type:** Discord DM\n**User:** Unknown"
+				fieldValue = line("destination.type", "Discord DM") + "\n" + line("destination.user", unknown)
 			}
 		} else {
-			fieldValue = "**Type:** Discord DM\n**User:** Invalid configuration"
+			fieldValue = line("destination.type", "Discord DM") + "\n" + line("destination.user", invalid)
 		}
 
 	case models.DestinationDiscordChannel:
-		channelInfo := "**Type:** Discord Channel\n"
+		channelInfo := line("destination.type", i18n.T(locale, "destination.discord_channel")) + "\n"
 		
 		if channelID, exists := dest.Metadata["channel_id"]; exists {
 			if channelIDStr, ok := channelID.(string); ok {
-				channelInfo += fmt.Sprintf("**Channel:** <#%s>\n", channelIDStr)
+				channelInfo += line("destination.channel", fmt.Sprintf("<#%s>", channelIDStr)) + "\n"
 			} else {
-				channelInfo += "**Channel:** Unknown\n"
+				channelInfo += line("destination.channel", unknown) + "\n"
 			}
 		} else {
-			channelInfo += "**Channel:** Invalid configuration\n"
+			channelInfo += line("destination.channel", invalid) + "\n"
 		}
 
 		if guildID, exists := dest.Metadata["guild_id"]; exists {
 			if guildIDStr, ok := guildID.(string); ok {
-				channelInfo += fmt.Sprintf("**Server ID:** %s", guildIDStr)
+				channelInfo += line("destination.server_id", guildIDStr)
 			}
 		}
 
@@ -140,30 +148,30 @@ func buildDestinationField(dest models.ReminderDestination, index int) *discordg
 			if urlStr, ok := url.(string); ok {
 				// Mask the webhook URL for security
 				maskedURL := maskWebhookURL(urlStr)
-				fieldValue = fmt.Sprintf("**Type:** Webhook\n**URL:** %s", maskedURL)
+				fieldValue = line("destination.type", "Webhook") + "\n" + line("destination.url", maskedURL)
 			} else {
-				fieldValue = "**Type:** Webhook\n**URL:** Invalid configuration"
+				fieldValue = line("destination.type", "Webhook") + "\n" + line("destination.url", invalid)
 			}
 		} else {
-			fieldValue = "**Type:** Webhook\n**URL:** Invalid configuration"
+			fieldValue = line("destination.type", "Webhook") + "\n" + line("destination.url", invalid)
 		}
 
 		// Add platform information if specified
 		if platform, exists := dest.Metadata["platform"]; exists {
 			if platformStr, ok := platform.(string); ok && platformStr != "" && platformStr != "generic" {
-				fieldValue += fmt.Sprintf("\n**Platform:** %s", platformStr)
+				fieldValue += "\n" + line("destination.platform", platformStr)
 			}
 		}
 
 		// Add any additional webhook metadata
 		if name, exists := dest.Metadata["name"]; exists {
 			if nameStr, ok := name.(string); ok {
-				fieldValue += fmt.Sprintf("\n**Name:** %s", nameStr)
+				fieldValue += "\n" + line("destination.name", nameStr)
 			}
 		}
 
 	default:
-		fieldValue = fmt.Sprintf("**Type:** Unknown (%s)\n**Configuration:** %v", dest.Type, dest.Metadata)
+		fieldValue = line("destination.type", fmt.Sprintf("%s (%s)", unknown, dest.Type)) + "\n" + line("destination.configuration", fmt.Sprint(dest.Metadata))
 	}
 
 	return &discordgo.MessageEmbedField{
@@ -219,11 +227,11 @@ func CanAccessReminder(interaction *discordgo.InteractionCreate, account *models
 	return false
 }
 
-// CanSnoozeReminder checks if a reminder can be snoozed
-func CanSnoozeReminder(reminder *models.Reminder) (bool, string) {
+// CanSnoozeReminder checks if a reminder can be snoozed, the reason is given in locale
+func CanSnoozeReminder(reminder *models.Reminder, locale string) (bool, string) {
 	// Check if reminder is already snoozed
 	if reminder.SnoozedAtUTC != nil {
-		return false, "This reminder is already snoozed."
+		return false, i18n.T(locale, "snooze.error.already_snoozed")
 	}
 
 	return true, ""
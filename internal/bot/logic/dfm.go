@@ -10,6 +10,7 @@ import (
 	"github.com/ericp/chronos-bot-reminder/internal/config"
 	"github.com/ericp/chronos-bot-reminder/internal/database"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 )
 
@@ -20,7 +21,7 @@ func dfmWebURL() string {
 }
 
 // sendDFMEmbed sends an ephemeral embed with a link button to the DFM web page
-func sendDFMEmbed(session *discordgo.Session, interaction *discordgo.InteractionCreate, embed *discordgo.MessageEmbed) error {
+func sendDFMEmbed(session *discordgo.Session, interaction *discordgo.InteractionCreate, embed *discordgo.MessageEmbed, locale string) error {
 	return session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
@@ -29,7 +30,7 @@ func sendDFMEmbed(session *discordgo.Session, interaction *discordgo.Interaction
 				discordgo.ActionsRow{
 					Components: []discordgo.MessageComponent{
 						discordgo.Button{
-							Label: "Open in the web app",
+							Label: i18n.T(locale, "dfm.open_web_app"),
 							Style: discordgo.LinkButton,
 							URL:   dfmWebURL(),
 						},
@@ -44,6 +45,7 @@ func sendDFMEmbed(session *discordgo.Session, interaction *discordgo.Interaction
 // HandleDFMCreate adds a new item to the user's note
 func HandleDFMCreate(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account, options []*discordgo.ApplicationCommandInteractionDataOption) error {
 	repo := database.GetRepositories()
+	locale := utils.Locale(interaction, account)
 
 	var content string
 	for _, option := range options {
@@ -53,12 +55,12 @@ func HandleDFMCreate(session *discordgo.Session, interaction *discordgo.Interact
 	}
 
 	if content == "" {
-		return utils.SendError(session, interaction, "Invalid Content", "The item content cannot be empty.")
+		return utils.SendError(session, interaction, i18n.T(locale, "dfm.error.invalid_content_title"), i18n.T(locale, "dfm.error.invalid_content"))
 	}
 
 	note, err := repo.DFMNote.GetOrCreateByAccountID(account.ID)
 	if err != nil {
-		return utils.SendError(session, interaction, "Database Error", "Failed to access your note.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), i18n.T(locale, "dfm.error.access"))
 	}
 
 	item := &models.DFMItem{
@@ -66,33 +68,34 @@ func HandleDFMCreate(session *discordgo.Session, interaction *discordgo.Interact
 		Content: content,
 	}
 	if err := repo.DFMItem.Create(item); err != nil {
-		return utils.SendError(session, interaction, "Database Error", "Failed to add the item to your note.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), i18n.T(locale, "dfm.error.add"))
 	}
 
 	embed := &discordgo.MessageEmbed{
-		Title:       "💭 Don't Forget Me",
-		Description: fmt.Sprintf("✅ Added to your note:\n**%s**", content),
+		Title:       "💭 " + i18n.T(locale, "dfm.title"),
+		Description: "✅ " + i18n.T(locale, "dfm.added", content),
 		Color:       utils.ColorSuccess,
 	}
-	return sendDFMEmbed(session, interaction, embed)
+	return sendDFMEmbed(session, interaction, embed, locale)
 }
 
 // HandleDFMList shows the user's note with its items and reminder settings
 func HandleDFMList(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
 	repo := database.GetRepositories()
+	locale := utils.Locale(interaction, account)
 
 	if _, err := repo.DFMNote.GetOrCreateByAccountID(account.ID); err != nil {
-		return utils.SendError(session, interaction, "Database Error", "Failed to access your note.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), i18n.T(locale, "dfm.error.access"))
 	}
 
 	note, err := repo.DFMNote.GetWithItems(account.ID)
 	if err != nil || note == nil {
-		return utils.SendError(session, interaction, "Database Error", "Failed to access your note.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), i18n.T(locale, "dfm.error.access"))
 	}
 
 	var description strings.Builder
 	if len(note.Items) == 0 {
-		description.WriteString("Your note is empty. Use `/dfm create` to add something you don't want to forget.")
+		description.WriteString(i18n.T(locale, "dfm.note_empty") + " " + i18n.T(locale, "dfm.list.empty_hint"))
 	} else {
 		for _, item := range note.Items {
 			if item.Checked {
@@ -105,8 +108,8 @@ func HandleDFMList(session *discordgo.Session, interaction *discordgo.Interactio
 
 	description.WriteString("\n")
 	if note.HasReminder() {
-		recurrenceLabel := services.GetRecurrenceTypeLabel(services.GetRecurrenceType(int(note.Recurrence)))
-		description.WriteString(fmt.Sprintf("🔔 Reminder: **%s** via **%s**", recurrenceLabel, dfmDestinationsLabel(note)))
+		recurrenceLabel := services.LocalizedRecurrenceTypeLabel(locale, services.GetRecurrenceType(int(note.Recurrence)))
+		description.WriteString("🔔 " + i18n.T(locale, "dfm.list.reminder", recurrenceLabel, dfmDestinationsLabel(note, locale)))
 
 		if note.NextFireUTC != nil {
 			fireTime := *note.NextFireUTC
@@ -116,21 +119,21 @@ func HandleDFMList(session *discordgo.Session, interaction *discordgo.Interactio
 					fireTime = fireTime.In(loc)
 				}
 			}
-			description.WriteString(fmt.Sprintf(" - next: %s", fireTime.Format("Jan 02, 15:04 MST")))
+			description.WriteString(" - " + i18n.T(locale, "dfm.list.next", i18n.FormatShortDateTime(locale, fireTime)))
 		}
 	} else {
-		description.WriteString("🔕 No reminder set. Use `/dfm set-reminder` to be reminded of your note.")
+		description.WriteString("🔕 " + i18n.T(locale, "dfm.list.no_reminder"))
 	}
 
 	embed := &discordgo.MessageEmbed{
-		Title:       "💭 Don't Forget Me - Your note",
+		Title:       "💭 " + i18n.T(locale, "dfm.note_title"),
 		Description: description.String(),
 		Color:       utils.ColorInfo,
 		Footer: &discordgo.MessageEmbedFooter{
-			Text: fmt.Sprintf("%d item(s)", len(note.Items)),
+			Text: i18n.TN(locale, "dfm.list.items", len(note.Items), len(note.Items)),
 		},
 	}
-	return sendDFMEmbed(session, interaction, embed)
+	return sendDFMEmbed(session, interaction, embed, locale)
 }
 
 // getDFMItemFromOptions resolves the selected item and verifies ownership
@@ -166,68 +169,71 @@ func getDFMItemFromOptions(account *models.Account, options []*discordgo.Applica
 // HandleDFMSetChecked checks or unchecks an item of the note
 func HandleDFMSetChecked(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account, options []*discordgo.ApplicationCommandInteractionDataOption, checked bool) error {
 	repo := database.GetRepositories()
+	locale := utils.Locale(interaction, account)
 
 	item, err := getDFMItemFromOptions(account, options)
 	if err != nil {
-		return utils.SendError(session, interaction, "Item Not Found", "The specified item could not be found in your note.")
+		return utils.SendError(session, interaction, i18n.T(locale, "dfm.error.item_not_found_title"), i18n.T(locale, "dfm.error.item_not_found"))
 	}
 
 	item.Checked = checked
 	if err := repo.DFMItem.Update(item); err != nil {
-		return utils.SendError(session, interaction, "Database Error", "Failed to update the item.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), i18n.T(locale, "dfm.error.update"))
 	}
 
-	action := "⬜ Item unchecked"
+	action := "⬜ " + i18n.T(locale, "dfm.item_unchecked")
 	if checked {
-		action = "✅ Item checked"
+		action = "✅ " + i18n.T(locale, "dfm.item_checked")
 	}
 	embed := &discordgo.MessageEmbed{
-		Title:       "💭 Don't Forget Me",
+		Title:       "💭 " + i18n.T(locale, "dfm.title"),
 		Description: fmt.Sprintf("%s:\n**%s**", action, item.Content),
 		Color:       utils.ColorSuccess,
 	}
-	return sendDFMEmbed(session, interaction, embed)
+	return sendDFMEmbed(session, interaction, embed, locale)
 }
 
 // HandleDFMDelete removes an item from the note
 func HandleDFMDelete(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account, options []*discordgo.ApplicationCommandInteractionDataOption) error {
 	repo := database.GetRepositories()
+	locale := utils.Locale(interaction, account)
 
 	item, err := getDFMItemFromOptions(account, options)
 	if err != nil {
-		return utils.SendError(session, interaction, "Item Not Found", "The specified item could not be found in your note.")
+		return utils.SendError(session, interaction, i18n.T(locale, "dfm.error.item_not_found_title"), i18n.T(locale, "dfm.error.item_not_found"))
 	}
 
 	if err := repo.DFMItem.Delete(item.ID); err != nil {
-		return utils.SendError(session, interaction, "Database Error", "Failed to delete the item.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), i18n.T(locale, "dfm.error.delete"))
 	}
 
 	embed := &discordgo.MessageEmbed{
-		Title:       "💭 Don't Forget Me",
-		Description: fmt.Sprintf("🗑️ Removed from your note:\n**%s**", item.Content),
+		Title:       "💭 " + i18n.T(locale, "dfm.title"),
+		Description: "🗑️ " + i18n.T(locale, "dfm.removed", item.Content),
 		Color:       utils.ColorSuccess,
 	}
-	return sendDFMEmbed(session, interaction, embed)
+	return sendDFMEmbed(session, interaction, embed, locale)
 }
 
 // dfmDestinationsLabel returns a human readable list of the note's delivery channels
-func dfmDestinationsLabel(note *models.DFMNote) string {
+func dfmDestinationsLabel(note *models.DFMNote, locale string) string {
 	var labels []string
 	if note.SendDiscordDM {
-		labels = append(labels, "Discord DM")
+		labels = append(labels, i18n.T(locale, "dfm.destination.discord_dm"))
 	}
 	if note.SendEmail {
-		labels = append(labels, "Email")
+		labels = append(labels, i18n.T(locale, "dfm.destination.email"))
 	}
 	if len(labels) == 0 {
-		return "Discord DM"
+		return i18n.T(locale, "dfm.destination.discord_dm")
 	}
-	return strings.Join(labels, " and ")
+	return strings.Join(labels, i18n.T(locale, "dfm.destination.and"))
 }
 
 // HandleDFMSetReminder configures the recurring reminder of the note
 func HandleDFMSetReminder(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account, options []*discordgo.ApplicationCommandInteractionDataOption) error {
 	repo := database.GetRepositories()
+	locale := utils.Locale(interaction, account)
 
 	recurrenceStr := ""
 	timeStr := "09:00"
@@ -248,33 +254,33 @@ func HandleDFMSetReminder(session *discordgo.Session, interaction *discordgo.Int
 
 	recurrenceValue, exists := services.RecurrenceTypeMap[recurrenceStr]
 	if !exists || recurrenceValue == services.RecurrenceOnce {
-		return utils.SendError(session, interaction, "Invalid Recurrence", "Please choose a valid recurrence.")
+		return utils.SendError(session, interaction, i18n.T(locale, "dfm.error.invalid_recurrence_title"), i18n.T(locale, "dfm.error.invalid_recurrence"))
 	}
 
 	sendDiscordDM := destinationChoice == "discord_dm" || destinationChoice == "both"
 	sendEmail := destinationChoice == "email" || destinationChoice == "both"
 	if !sendDiscordDM && !sendEmail {
-		return utils.SendError(session, interaction, "Invalid Destination", "The destination must be Discord DM, Email or Both.")
+		return utils.SendError(session, interaction, i18n.T(locale, "dfm.error.invalid_destination_title"), i18n.T(locale, "dfm.error.invalid_destination"))
 	}
 
 	// Email delivery requires an account-level email address
 	if sendEmail && account.Email == nil {
-		return utils.SendError(session, interaction, "No Email Linked", "You need a Chronos web account with an email address to receive your note by email. You can link one from the web application.")
+		return utils.SendError(session, interaction, i18n.T(locale, "dfm.error.no_email_title"), i18n.T(locale, "dfm.error.no_email"))
 	}
 
 	fullAccount, err := repo.Account.GetWithTimezone(account.ID)
 	if err != nil || fullAccount == nil || fullAccount.Timezone == nil {
-		return utils.SendError(session, interaction, "Timezone Missing", "Please set your timezone first with `/timezones`.")
+		return utils.SendError(session, interaction, i18n.T(locale, "dfm.error.timezone_title"), i18n.T(locale, "dfm.error.timezone"))
 	}
 
 	firstFire, err := services.ComputeDFMReminderSchedule(dateStr, timeStr, recurrenceValue, fullAccount.Timezone.IANALocation)
 	if err != nil {
-		return utils.SendError(session, interaction, "Invalid Date or Time", "The provided date or time could not be parsed. Use YYYY-MM-DD for the date and HH:MM for the time, for example 2026-06-19 09:00.")
+		return utils.SendError(session, interaction, i18n.T(locale, "dfm.error.invalid_date_title"), i18n.T(locale, "dfm.error.invalid_date"))
 	}
 
 	note, err := repo.DFMNote.GetOrCreateByAccountID(account.ID)
 	if err != nil {
-		return utils.SendError(session, interaction, "Database Error", "Failed to access your note.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), i18n.T(locale, "dfm.error.access"))
 	}
 
 	note.RemindAtUTC = &firstFire
@@ -283,7 +289,7 @@ func HandleDFMSetReminder(session *discordgo.Session, interaction *discordgo.Int
 	note.SendDiscordDM = sendDiscordDM
 	note.SendEmail = sendEmail
 	if err := repo.DFMNote.Update(note); err != nil {
-		return utils.SendError(session, interaction, "Database Error", "Failed to set the reminder.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), i18n.T(locale, "dfm.error.set_reminder"))
 	}
 
 	localFire := firstFire
@@ -292,26 +298,26 @@ func HandleDFMSetReminder(session *discordgo.Session, interaction *discordgo.Int
 	}
 
 	embed := &discordgo.MessageEmbed{
-		Title: "💭 Don't Forget Me",
-		Description: fmt.Sprintf(
-			"🔔 Your note will now be sent to you **%s** via **%s**.\nNext delivery: **%s**",
-			strings.ToLower(services.GetRecurrenceTypeLabel(recurrenceValue)),
-			dfmDestinationsLabel(note),
-			localFire.Format("Jan 02, 15:04 MST"),
+		Title: "💭 " + i18n.T(locale, "dfm.title"),
+		Description: "🔔 " + i18n.T(locale, "dfm.reminder_set",
+			strings.ToLower(services.LocalizedRecurrenceTypeLabel(locale, recurrenceValue)),
+			dfmDestinationsLabel(note, locale),
+			i18n.FormatShortDateTime(locale, localFire),
 		),
 		Color: utils.ColorSuccess,
 	}
-	return sendDFMEmbed(session, interaction, embed)
+	return sendDFMEmbed(session, interaction, embed, locale)
 }
 
 // HandleDFMSend dispatches the note to the user immediately
 func HandleDFMSend(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
 	repo := database.GetRepositories()
+	locale := utils.Locale(interaction, account)
 	const cooldownDuration = 5 * time.Minute
 
 	note, err := repo.DFMNote.GetOrCreateByAccountID(account.ID)
 	if err != nil {
-		return utils.SendError(session, interaction, "Database Error", "Failed to access your note.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), i18n.T(locale, "dfm.error.access"))
 	}
 
 	// Check cooldown
@@ -321,16 +327,16 @@ func HandleDFMSend(session *discordgo.Session, interaction *discordgo.Interactio
 			remainingTime := cooldownDuration - timeSinceLastSend
 			minutes := int(remainingTime.Minutes())
 			seconds := int(remainingTime.Seconds()) % 60
-			return utils.SendError(session, interaction, "Cooldown Active", 
-				fmt.Sprintf("Please wait %d:%02d before sending your note again.", minutes, seconds))
+			return utils.SendError(session, interaction, i18n.T(locale, "dfm.error.cooldown_title"), 
+				i18n.T(locale, "dfm.error.cooldown", minutes, seconds))
 		}
 	}
 
 	if services.DFMSendNow == nil {
-		return utils.SendError(session, interaction, "Send Failed", "The reminder engine is not available. Please try again later.")
+		return utils.SendError(session, interaction, i18n.T(locale, "dfm.error.send_failed_title"), i18n.T(locale, "dfm.error.engine_unavailable"))
 	}
 	if err := services.DFMSendNow(account.ID); err != nil {
-		return utils.SendError(session, interaction, "Send Failed", "Your note could not be sent. Please try again later.")
+		return utils.SendError(session, interaction, i18n.T(locale, "dfm.error.send_failed_title"), i18n.T(locale, "dfm.error.send_failed"))
 	}
 
 	// Update last sent timestamp
@@ -342,37 +348,38 @@ func HandleDFMSend(session *discordgo.Session, interaction *discordgo.Interactio
 	}
 
 	embed := &discordgo.MessageEmbed{
-		Title:       "💭 Don't Forget Me",
-		Description: "📨 Your note has been sent!",
+		Title:       "💭 " + i18n.T(locale, "dfm.title"),
+		Description: "📨 " + i18n.T(locale, "dfm.sent"),
 		Color:       utils.ColorSuccess,
 	}
-	return sendDFMEmbed(session, interaction, embed)
+	return sendDFMEmbed(session, interaction, embed, locale)
 }
 
 // HandleDFMRemoveReminder clears the reminder of the note
 func HandleDFMRemoveReminder(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
 	repo := database.GetRepositories()
+	locale := utils.Locale(interaction, account)
 
 	note, err := repo.DFMNote.GetOrCreateByAccountID(account.ID)
 	if err != nil {
-		return utils.SendError(session, interaction, "Database Error", "Failed to access your note.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), i18n.T(locale, "dfm.error.access"))
 	}
 
 	if !note.HasReminder() {
-		return utils.SendError(session, interaction, "No Reminder", "Your note has no reminder to remove.")
+		return utils.SendError(session, interaction, i18n.T(locale, "dfm.error.no_reminder_title"), i18n.T(locale, "dfm.error.no_reminder"))
 	}
 
 	note.RemindAtUTC = nil
 	note.NextFireUTC = nil
 	note.Recurrence = 0
 	if err := repo.DFMNote.Update(note); err != nil {
-		return utils.SendError(session, interaction, "Database Error", "Failed to remove the reminder.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), i18n.T(locale, "dfm.error.remove_reminder"))
 	}
 
 	embed := &discordgo.MessageEmbed{
-		Title:       "💭 Don't Forget Me",
-		Description: "🔕 The reminder of your note has been removed.",
+		Title:       "💭 " + i18n.T(locale, "dfm.title"),
+		Description: "🔕 " + i18n.T(locale, "dfm.reminder_removed"),
 		Color:       utils.ColorSuccess,
 	}
-	return sendDFMEmbed(session, interaction, embed)
+	return sendDFMEmbed(session, interaction, embed, locale)
 }
@@ -7,8 +7,10 @@ import (
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/ericp/chronos-bot-reminder/internal/bot/utils"
 	"github.com/ericp/chronos-bot-reminder/internal/config"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 )
 
 const (
@@ -33,6 +35,7 @@ type CommandInfo struct {
 // commandsData should be []*commands.Command
 func HelpHandlerWithCommands(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account, commandsData interface{}) error {
 	options := interaction.ApplicationCommandData().Options
+	locale := utils.Locale(interaction, account)
 	
 	// Check if a specific command was requested
 	var requestedCommand string
@@ -41,21 +44,45 @@ func HelpHandlerWithCommands(session *discordgo.Session, interaction *discordgo.
 	}
 
 	// Convert commandsData to CommandInfo slice using reflection
-	allCommands := convertCommandsData(commandsData)
+	allCommands := localizeCommands(convertCommandsData(commandsData), locale)
 
 	if requestedCommand != "" {
 		// Show help for a specific command
 		for _, cmd := range allCommands {
 			if strings.EqualFold(cmd.Name, requestedCommand) {
-				return sendCommandDetailedHelp(session, interaction, cmd)
+				return sendCommandDetailedHelp(session, interaction, cmd, locale)
 			}
 		}
 		// Command not found
-		return sendCommandNotFound(session, interaction, requestedCommand)
+		return sendCommandNotFound(session, interaction, requestedCommand, locale)
 	}
 
 	// Show general help with all commands organized by category
-	return sendGeneralHelp(session, interaction, allCommands)
+	return sendGeneralHelp(session, interaction, allCommands, locale)
+}
+
+// localizeCommands translates the descriptions and categories of the commands,
+// keeping the registered English texts when the catalogue has no entry
+func localizeCommands(commands []CommandInfo, locale string) []CommandInfo {
+	for i := range commands {
+		cmd := &commands[i]
+		if short, ok := i18n.Lookup(locale, "command."+cmd.Name+".short"); ok {
+			cmd.ShortDescription = short
+		}
+		if full, ok := i18n.Lookup(locale, "command."+cmd.Name+".full"); ok {
+			cmd.FullDescription = full
+		}
+		if category, ok := i18n.Lookup(locale, "help.category."+helpCategoryKey(cmd.CategoryName)); ok {
+			cmd.CategoryName = category
+		}
+	}
+	return commands
+}
+
+// helpCategoryKey turns a category name such as "Don't Forget Me" into its catalogue key
+func helpCategoryKey(category string) string {
+	key := strings.ToLower(strings.ReplaceAll(category, "'", ""))
+	return strings.ReplaceAll(key, " ", "_")
 }
 
 // convertCommandsData converts raw commands data to CommandInfo using reflection
@@ -141,13 +168,13 @@ func getString(v reflect.Value, fieldName string) string {
 }
 
 // sendGeneralHelp sends the main help menu with all commands grouped by category
-func sendGeneralHelp(session *discordgo.Session, interaction *discordgo.InteractionCreate, allCommands []CommandInfo) error {
+func sendGeneralHelp(session *discordgo.Session, interaction *discordgo.InteractionCreate, allCommands []CommandInfo, locale string) error {
 	// Group commands by category
 	categories := make(map[string][]CommandInfo)
 	for _, cmd := range allCommands {
 		category := cmd.CategoryName
 		if category == "" {
-			category = "Other"
+			category = i18n.T(locale, "help.category.other")
 		}
 		categories[category] = append(categories[category], cmd)
 	}
@@ -161,8 +188,8 @@ func sendGeneralHelp(session *discordgo.Session, interaction *discordgo.Interact
 
 	// Build embed with all commands
 	embed := &discordgo.MessageEmbed{
-		Title:       "📚 Chronos Bot - Complete Help Guide",
-		Description: "Welcome to **Chronos Bot**! Your ultimate reminder management solution. Select a command below to learn more, or visit our web platform to enhance your experience.",
+		Title:       "📚 " + i18n.T(locale, "help.general.title"),
+		Description: i18n.T(locale, "help.general.description"),
 		Color:       helpColorPrimary,
 		Thumbnail: &discordgo.MessageEmbedThumbnail{
 			URL: chronosLogoURL,
@@ -194,13 +221,13 @@ func sendGeneralHelp(session *discordgo.Session, interaction *discordgo.Interact
 
 	// Add a tips section
 	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-		Name:  "💡 Quick Tips",
-		Value: "• Use `/help command:<name>` to learn more about a specific command\n• All times are converted to your timezone automatically\n• Visit our web platform for advanced features and analytics",
+		Name:  "💡 " + i18n.T(locale, "help.general.tips_title"),
+		Value: i18n.T(locale, "help.general.tips"),
 		Inline: false,
 	})
 
 	embed.Footer = &discordgo.MessageEmbedFooter{
-		Text:    "Chronos Bot Reminder • Type /help command:<name> for more details",
+		Text:    "Chronos Bot Reminder • " + i18n.T(locale, "help.general.footer"),
 		IconURL: session.State.User.AvatarURL(""),
 	}
 
@@ -214,7 +241,7 @@ func sendGeneralHelp(session *discordgo.Session, interaction *discordgo.Interact
 					Components: []discordgo.MessageComponent{
 						discordgo.Button{
 							Style: discordgo.LinkButton,
-							Label: "🌐 Visit Chronos Web Platform",
+							Label: "🌐 " + i18n.T(locale, "help.general.web_button"),
 							URL:   config.URLWebApp,
 						},
 					},
@@ -225,7 +252,7 @@ func sendGeneralHelp(session *discordgo.Session, interaction *discordgo.Interact
 }
 
 // sendCommandDetailedHelp sends detailed help for a specific command
-func sendCommandDetailedHelp(session *discordgo.Session, interaction *discordgo.InteractionCreate, cmd CommandInfo) error {
+func sendCommandDetailedHelp(session *discordgo.Session, interaction *discordgo.InteractionCreate, cmd CommandInfo, locale string) error {
 	// Build the detailed description
 	embed := &discordgo.MessageEmbed{
 		Title:       fmt.Sprintf("%s %s", cmd.Emoji, strings.ToUpper(cmd.Name)),
@@ -238,31 +265,31 @@ func sendCommandDetailedHelp(session *discordgo.Session, interaction *discordgo.
 
 	// Add category
 	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-		Name:   "📂 Category",
+		Name:   "📂 " + i18n.T(locale, "help.detail.category"),
 		Value:  cmd.CategoryName,
 		Inline: true,
 	})
 
 	// Add usage
 	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-		Name:   "💬 Usage",
+		Name:   "💬 " + i18n.T(locale, "help.detail.usage"),
 		Value:  fmt.Sprintf("`%s`", cmd.Usage),
 		Inline: false,
 	})
 
 	// Add example
 	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-		Name:   "📝 Example",
+		Name:   "📝 " + i18n.T(locale, "help.detail.example"),
 		Value:  fmt.Sprintf("`%s`", cmd.Example),
 		Inline: false,
 	})
 
 	// Add options if available
 	if len(cmd.Options) > 0 {
-		optionsStr := buildOptionsDescription(cmd.Options)
+		optionsStr := buildOptionsDescription(cmd.Options, locale)
 		if optionsStr != "" {
 			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-				Name:   "⚙️ Options",
+				Name:   "⚙️ " + i18n.T(locale, "help.detail.options"),
 				Value:  optionsStr,
 				Inline: false,
 			})
@@ -271,13 +298,13 @@ func sendCommandDetailedHelp(session *discordgo.Session, interaction *discordgo.
 
 	// Add a helpful note about the web platform
 	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-		Name:   "🚀 Enhanced Features",
-		Value:  "Visit our web platform for advanced reminder management, analytics, and more customization options!",
+		Name:   "🚀 " + i18n.T(locale, "help.detail.features_title"),
+		Value:  i18n.T(locale, "help.detail.features"),
 		Inline: false,
 	})
 
 	embed.Footer = &discordgo.MessageEmbedFooter{
-		Text:    "Chronos Bot Reminder 1.0.0 • Learn more on our web platform",
+		Text:    "Chronos Bot Reminder 1.0.0 • " + i18n.T(locale, "help.detail.footer"),
 		IconURL: session.State.User.AvatarURL(""),
 	}
 
@@ -291,7 +318,7 @@ func sendCommandDetailedHelp(session *discordgo.Session, interaction *discordgo.
 					Components: []discordgo.MessageComponent{
 						discordgo.Button{
 							Style: discordgo.LinkButton,
-							Label: "🌐 Web Platform",
+							Label: "🌐 " + i18n.T(locale, "help.detail.web_button"),
 							URL:   config.URLWebApp,
 						},
 					},
@@ -302,10 +329,10 @@ func sendCommandDetailedHelp(session *discordgo.Session, interaction *discordgo.
 }
 
 // sendCommandNotFound sends an error message when command is not found
-func sendCommandNotFound(session *discordgo.Session, interaction *discordgo.InteractionCreate, commandName string) error {
+func sendCommandNotFound(session *discordgo.Session, interaction *discordgo.InteractionCreate, commandName string, locale string) error {
 	embed := &discordgo.MessageEmbed{
-		Title:       "❌ Command Not Found",
-		Description: fmt.Sprintf("The command `%s` was not found. Use `/help` to see all available commands.", commandName),
+		Title:       "❌ " + i18n.T(locale, "help.not_found.title"),
+		Description: i18n.T(locale, "help.not_found.description", commandName),
 		Color:       0xf57c76, // Error color
 		Thumbnail: &discordgo.MessageEmbedThumbnail{
 			URL: chronosLogoURL,
@@ -326,19 +353,19 @@ func sendCommandNotFound(session *discordgo.Session, interaction *discordgo.Inte
 }
 
 // buildOptionsDescription builds a formatted string describing command options
-func buildOptionsDescription(options []*discordgo.ApplicationCommandOption) string {
+func buildOptionsDescription(options []*discordgo.ApplicationCommandOption, locale string) string {
 	if len(options) == 0 {
 		return ""
 	}
 
 	var description string
 	for _, option := range options {
-		required := "optional"
+		required := i18n.T(locale, "help.option.optional")
 		if option.Required {
-			required = "required"
+			required = i18n.T(locale, "help.option.required")
 		}
 
-		optionType := getOptionTypeName(option.Type)
+		optionType := i18n.T(locale, "help.option.type."+getOptionTypeName(option.Type))
 
 		if option.Type == discordgo.ApplicationCommandOptionSubCommand || option.Type == discordgo.ApplicationCommandOptionSubCommandGroup {
 			// For subcommands, just list the name and description
@@ -374,7 +401,7 @@ func getOptionTypeName(optType discordgo.ApplicationCommandOptionType) string {
 	case discordgo.ApplicationCommandOptionSubCommand:
 		return "subcommand"
 	case discordgo.ApplicationCommandOptionSubCommandGroup:
-		return "subcommand group"
+		return "subcommand_group"
 	default:
 		return "unknown"
 	}
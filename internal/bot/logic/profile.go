@@ -14,12 +14,14 @@ import (
 	"github.com/ericp/chronos-bot-reminder/internal/bot/utils"
 	"github.com/ericp/chronos-bot-reminder/internal/config"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 )
 
 // ProfileHandler handles the profile command
 func ProfileHandler(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
 	options := interaction.ApplicationCommandData().Options
+	locale := utils.Locale(interaction, account)
 
 	var targetUser *discordgo.User
 	var targetAccount *models.Account
@@ -33,8 +35,8 @@ func ProfileHandler(session *discordgo.Session, interaction *discordgo.Interacti
 		targetAccount, err = services.GetAccountFromDiscordUser(targetUser)
 		// If error or no account, display a message saying that user has no account
 		if err != nil || targetAccount == nil {
-			msg := "They can create one by setting a reminder or calling that command !"
-			return utils.SendErrorDetailed(session, interaction, "No Account", fmt.Sprintf("User %s does not have an account yet !", targetUser.Username), &msg)
+			msg := i18n.T(locale, "profile.no_account.hint")
+			return utils.SendErrorDetailed(session, interaction, i18n.T(locale, "profile.no_account.title"), i18n.T(locale, "profile.no_account.description", targetUser.Username), &msg)
 		}
 	} else {
 		// Use the command invoker's account
@@ -48,7 +50,7 @@ func ProfileHandler(session *discordgo.Session, interaction *discordgo.Interacti
 	}
 
 	if targetUser == nil || targetAccount == nil {
-		return utils.SendError(session, interaction, "Error", "Unable to determine target user.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.title"), i18n.T(locale, "profile.error.no_target"))
 	}
 
 	// Fetch user's reminder count
@@ -83,6 +85,7 @@ func ProfileHandler(session *discordgo.Session, interaction *discordgo.Interacti
 		CreatedAt:     targetAccount.CreatedAt,
 		ReminderCount: reminderCount,
 		Badges:        badges,
+		Locale:        locale,
 	}
 
 	// Generate profile image
@@ -90,13 +93,13 @@ func ProfileHandler(session *discordgo.Session, interaction *discordgo.Interacti
 	profileImage, err := drawService.GenerateProfileImage(profileData)
 	if err != nil {
 		log.Println("Error generating profile image:", err)
-		return utils.SendError(session, interaction, "Error", "Failed to generate profile image.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.title"), i18n.T(locale, "profile.error.generate"))
 	}
 
 	// Convert image to bytes
 	var buf bytes.Buffer
 	if err := png.Encode(&buf, profileImage); err != nil {
-		return utils.SendError(session, interaction, "Error", "Failed to encode profile image.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.title"), i18n.T(locale, "profile.error.encode"))
 	}
 
 	// Build the response data with the profile image.
@@ -112,12 +115,12 @@ func ProfileHandler(session *discordgo.Session, interaction *discordgo.Interacti
 	// If the invoker's own account is Discord-only (no email/password login),
 	// nudge them to add web/mobile access so the same account works everywhere.
 	if isSelf && !services.DiscordUserUsesApp(targetAccount) {
-		responseData.Content = "💡 Your account is Discord-only. Add an email & password on the web app to use Chronos on the **web and mobile apps** with the same reminders — just sign in with Discord there, then set up your login."
+		responseData.Content = "💡 " + i18n.T(locale, "profile.discord_only.content")
 		responseData.Components = []discordgo.MessageComponent{
 			discordgo.ActionsRow{
 				Components: []discordgo.MessageComponent{
 					discordgo.Button{
-						Label: "Set up web & mobile login",
+						Label: i18n.T(locale, "profile.discord_only.button"),
 						Style: discordgo.LinkButton,
 						URL:   config.URLWebApp + "/account",
 					},
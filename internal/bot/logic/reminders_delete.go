@@ -1,26 +1,28 @@
 package logic
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/ericp/chronos-bot-reminder/internal/bot/utils"
 	"github.com/ericp/chronos-bot-reminder/internal/database"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 	"github.com/google/uuid"
 )
 
 // handleDeleteReminder handles the delete subcommand
 func HandleDeleteReminder(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account, options []*discordgo.ApplicationCommandInteractionDataOption) error {
+	locale := utils.Locale(interaction, account)
+
 	if len(options) == 0 {
-		return utils.SendError(session, interaction, "Missing Parameter", "Please specify a reminder to delete.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.missing_parameter"), i18n.T(locale, "reminder.delete.missing"))
 	}
 
 	reminderIDStr := options[0].StringValue()
 	reminderID, err := uuid.Parse(reminderIDStr)
 	if err != nil {
-		return utils.SendError(session, interaction, "Invalid Reminder ID", "The provided reminder ID is not valid.")
+		return utils.SendError(session, interaction, i18n.T(locale, "reminder.error.invalid_id_title"), i18n.T(locale, "reminder.error.invalid_id_description"))
 	}
 
 	repo := database.GetRepositories()
@@ -28,23 +30,23 @@ func HandleDeleteReminder(session *discordgo.Session, interaction *discordgo.Int
 	// Get the reminder with account and destinations
 	reminder, err := repo.Reminder.GetWithAccountAndDestinations(reminderID)
 	if err != nil {
-		return utils.SendError(session, interaction, "Database Error", "Failed to retrieve reminder information.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), i18n.T(locale, "reminder.error.retrieve_info_failed"))
 	}
 
 	if reminder == nil {
-		return utils.SendError(session, interaction, "Reminder Not Found", "The specified reminder does not exist.")
+		return utils.SendError(session, interaction, i18n.T(locale, "reminder.error.not_found_title"), i18n.T(locale, "reminder.error.not_exist"))
 	}
 
 	// Check if user has permission to delete this reminder
 	if !CanAccessReminder(interaction, account, reminder) {
-		return utils.SendError(session, interaction, "Permission Denied", "You don't have permission to delete this reminder.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.permission_denied"), i18n.T(locale, "reminder.delete.no_permission"))
 	}
 
 	// Show confirmation message with buttons
-	embed := utils.BuildWarningEmbed(session, "Confirm Deletion", 
-		fmt.Sprintf("Are you sure you want to delete the reminder:\n\n**Message:** %s\n**Remind Time:** %s\n\nThis action cannot be undone.", 
+	embed := utils.BuildWarningEmbed(session, i18n.T(locale, "reminder.delete.confirm_title"), 
+		i18n.T(locale, "reminder.delete.confirm_description", 
 			reminder.Message, 
-			reminder.RemindAtUTC.Format("Monday, January 2, 2006 at 15:04 MST")))
+			i18n.FormatDateTime(locale, reminder.RemindAtUTC)+reminder.RemindAtUTC.Format(" MST")))
 
 	return session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -55,13 +57,13 @@ func HandleDeleteReminder(session *discordgo.Session, interaction *discordgo.Int
 					Components: []discordgo.MessageComponent{
 						discordgo.Button{
 							CustomID: "confirm_delete_" + reminderIDStr,
-							Label:    "Yes, Delete",
+							Label:    i18n.T(locale, "reminder.delete.confirm_button"),
 							Style:    discordgo.DangerButton,
 							Emoji:    &discordgo.ComponentEmoji{Name: "🗑️"},
 						},
 						discordgo.Button{
 							CustomID: "cancel_delete_" + reminderIDStr,
-							Label:    "Cancel",
+							Label:    i18n.T(locale, "common.cancel"),
 							Style:    discordgo.SecondaryButton,
 							Emoji:    &discordgo.ComponentEmoji{Name: "❌"},
 						},
@@ -75,13 +77,15 @@ func HandleDeleteReminder(session *discordgo.Session, interaction *discordgo.Int
 
 // HandleConfirmDelete handles the confirmation button click
 func HandleConfirmDelete(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
+	locale := utils.Locale(interaction, account)
+
 	// Extract reminder ID from custom ID
 	customID := interaction.MessageComponentData().CustomID
 	reminderIDStr := strings.TrimPrefix(customID, "confirm_delete_")
 	
 	reminderID, err := uuid.Parse(reminderIDStr)
 	if err != nil {
-		return utils.SendError(session, interaction, "Invalid Reminder ID", "The reminder ID is not valid.")
+		return utils.SendError(session, interaction, i18n.T(locale, "reminder.error.invalid_id_title"), i18n.T(locale, "reminder.error.invalid_id_description"))
 	}
 
 	repo := database.GetRepositories()
@@ -89,27 +93,27 @@ func HandleConfirmDelete(session *discordgo.Session, interaction *discordgo.Inte
 	// Get the reminder to verify it still exists and user has permission
 	reminder, err := repo.Reminder.GetWithAccountAndDestinations(reminderID)
 	if err != nil {
-		return utils.SendError(session, interaction, "Database Error", "Failed to retrieve reminder information.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), i18n.T(locale, "reminder.error.retrieve_info_failed"))
 	}
 
 	if reminder == nil {
-		return utils.SendError(session, interaction, "Reminder Not Found", "The reminder has already been deleted or does not exist.")
+		return utils.SendError(session, interaction, i18n.T(locale, "reminder.error.not_found_title"), i18n.T(locale, "reminder.delete.already_deleted"))
 	}
 
 	// Re-check permissions
 	if !CanAccessReminder(interaction, account, reminder) {
-		return utils.SendError(session, interaction, "Permission Denied", "You don't have permission to delete this reminder.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.permission_denied"), i18n.T(locale, "reminder.delete.no_permission"))
 	}
 
 	// Delete the reminder (destinations will be cascade deleted)
 	err = repo.Reminder.Delete(reminderID, true)
 	if err != nil {
-		return utils.SendError(session, interaction, "Database Error", "Failed to delete the reminder. Please try again.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), i18n.T(locale, "reminder.delete.failed"))
 	}
 
 	// Update the message to show success
-	successEmbed := utils.BuildSuccessEmbed(session, "Reminder Deleted!", 
-		fmt.Sprintf("The reminder \"%s\" has been successfully deleted.", reminder.Message), nil)
+	successEmbed := utils.BuildSuccessEmbed(session, i18n.T(locale, "reminder.delete.done_title"), 
+		i18n.T(locale, "reminder.delete.done_description", reminder.Message), nil)
 
 	return session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseUpdateMessage,
@@ -123,8 +127,10 @@ func HandleConfirmDelete(session *discordgo.Session, interaction *discordgo.Inte
 
 // HandleCancelDelete handles the cancel button click
 func HandleCancelDelete(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
+	locale := utils.Locale(interaction, account)
+
 	// Update the message to show cancellation
-	cancelEmbed := utils.BuildInfoEmbed(session, "Deletion Cancelled", "The reminder deletion has been cancelled.")
+	cancelEmbed := utils.BuildInfoEmbed(session, i18n.T(locale, "reminder.delete.cancelled_title"), i18n.T(locale, "reminder.delete.cancelled_description"))
 
 	return session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseUpdateMessage,
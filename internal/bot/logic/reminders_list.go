@@ -9,6 +9,7 @@ import (
 	"github.com/ericp/chronos-bot-reminder/internal/bot/utils"
 	"github.com/ericp/chronos-bot-reminder/internal/database"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 )
 
@@ -17,18 +18,19 @@ const RemindersPerPage = 10
 
 // HandleListReminders handles the list subcommand
 func HandleListReminders(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account, options []*discordgo.ApplicationCommandInteractionDataOption) error {
+	locale := utils.Locale(interaction, account)
 	repo := database.GetRepositories()
 
 	// Get all reminders for the account
 	reminders, err := repo.Reminder.GetByAccountIDWithDestinations(account.ID)
 	if err != nil {
-		return utils.SendError(session, interaction, "Database Error", "Failed to retrieve reminders.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), i18n.T(locale, "reminders.error.retrieve_failed"))
 	}
 
 	if len(reminders) == 0 {
 		embed := &discordgo.MessageEmbed{
-			Title:       "📝 Your Reminders",
-			Description: "You don't have any reminders yet. Use `/remindme` to create your first reminder!",
+			Title:       "📝 " + i18n.T(locale, "reminders.list.title"),
+			Description: i18n.T(locale, "reminders.list.empty_hint"),
 			Color:       0x3498db,
 		}
 
@@ -48,7 +50,7 @@ func HandleListReminders(session *discordgo.Session, interaction *discordgo.Inte
 	}
 
 	// Build the reminders list embed
-	embed := BuildRemindersListEmbed(remindersPointers, 1, len(reminders))
+	embed := BuildRemindersListEmbed(remindersPointers, 1, len(reminders), locale)
 
 	// Create components with "Show First Reminder" button
 	components := []discordgo.MessageComponent{
@@ -56,7 +58,7 @@ func HandleListReminders(session *discordgo.Session, interaction *discordgo.Inte
 			Components: []discordgo.MessageComponent{
 				discordgo.Button{
 					CustomID: fmt.Sprintf("show_reminder_%s", reminders[0].ID.String()),
-					Label:    "Show First Reminder",
+					Label:    i18n.T(locale, "reminders.list.show_first"),
 					Style:    discordgo.PrimaryButton,
 					Emoji:    &discordgo.ComponentEmoji{Name: "👁️"},
 				},
@@ -75,23 +77,20 @@ func HandleListReminders(session *discordgo.Session, interaction *discordgo.Inte
 }
 
 // buildRemindersListEmbed creates an embed with a list of reminders
-func BuildRemindersListEmbed(reminders []*models.Reminder, page, total int) *discordgo.MessageEmbed {
+func BuildRemindersListEmbed(reminders []*models.Reminder, page, total int, locale string) *discordgo.MessageEmbed {
 	embed := &discordgo.MessageEmbed{
-		Title: "📝 Your Reminders",
+		Title: "📝 " + i18n.T(locale, "reminders.list.title"),
 		Color: 0x3498db,
 	}
 
 	if total == 0 {
-		embed.Description = "You don't have any reminders yet."
+		embed.Description = i18n.T(locale, "reminders.list.empty")
 		return embed
 	}
 
 	var description strings.Builder
-	description.WriteString(fmt.Sprintf("You have **%d** reminder", total))
-	if total != 1 {
-		description.WriteString("s")
-	}
-	description.WriteString(":\n\n")
+	description.WriteString(i18n.TN(locale, "reminders.list.count", total, total))
+	description.WriteString("\n\n")
 
 	for i, reminder := range reminders {
 		// Status emoji
@@ -110,7 +109,7 @@ func BuildRemindersListEmbed(reminders []*models.Reminder, page, total int) *dis
 		
 		// Add schedule info with correct recurrence type
 		recurrenceType := services.GetRecurrenceType(int(reminder.Recurrence))
-		recurrenceLabel := services.GetRecurrenceTypeLabel(recurrenceType)
+		recurrenceLabel := services.LocalizedRecurrenceTypeLabel(locale, recurrenceType)
 		description.WriteString(fmt.Sprintf("    🕐 %s\n", recurrenceLabel))
 		
 		// Add time in user's timezone
@@ -118,7 +117,7 @@ func BuildRemindersListEmbed(reminders []*models.Reminder, page, total int) *dis
 			loc, err := time.LoadLocation(reminder.Account.Timezone.IANALocation)
 			if err == nil {
 				userTime := reminder.RemindAtUTC.In(loc)
-				description.WriteString(fmt.Sprintf("    � %s\n", userTime.Format("Jan 02, 15:04 MST")))
+				description.WriteString(fmt.Sprintf("    � %s\n", i18n.FormatShortDateTime(locale, userTime)))
 			}
 		}
 		
@@ -127,7 +126,7 @@ func BuildRemindersListEmbed(reminders []*models.Reminder, page, total int) *dis
 
 	embed.Description = description.String()
 	embed.Footer = &discordgo.MessageEmbedFooter{
-		Text: fmt.Sprintf("Page %d - Use the buttons to navigate", page),
+		Text: i18n.T(locale, "reminders.list.footer", page),
 	}
 
 	return embed
@@ -135,6 +134,7 @@ func BuildRemindersListEmbed(reminders []*models.Reminder, page, total int) *dis
 
 // HandleShowReminderFromList handles showing a specific reminder from the list with navigation
 func HandleShowReminderFromList(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
+	locale := utils.Locale(interaction, account)
 	customID := interaction.MessageComponentData().CustomID
 	reminderIDStr := strings.TrimPrefix(customID, "show_reminder_")
 
@@ -143,7 +143,7 @@ func HandleShowReminderFromList(session *discordgo.Session, interaction *discord
 	// Get all reminders for the account to determine position
 	allReminders, err := repo.Reminder.GetByAccountIDWithDestinations(account.ID)
 	if err != nil {
-		return utils.SendError(session, interaction, "Database Error", "Failed to retrieve reminders.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), i18n.T(locale, "reminders.error.retrieve_failed"))
 	}
 
 	// Find the current reminder index
@@ -159,16 +159,16 @@ func HandleShowReminderFromList(session *discordgo.Session, interaction *discord
 	}
 
 	if currentReminder == nil {
-		return utils.SendError(session, interaction, "Reminder Not Found", "The specified reminder could not be found.")
+		return utils.SendError(session, interaction, i18n.T(locale, "reminder.error.not_found_title"), i18n.T(locale, "reminder.error.not_found"))
 	}
 
 	// Check permissions
 	if !CanAccessReminder(interaction, account, currentReminder) {
-		return utils.SendError(session, interaction, "Permission Denied", "You don't have permission to access this reminder.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.permission_denied"), i18n.T(locale, "reminder.error.no_access"))
 	}
 
 	// Build the reminder embed
-	embed := BuildReminderEmbed(session, currentReminder)
+	embed := BuildReminderEmbed(session, currentReminder, locale)
 
 	// Create navigation components
 	var components []discordgo.MessageComponent
@@ -178,7 +178,7 @@ func HandleShowReminderFromList(session *discordgo.Session, interaction *discord
 	if currentIndex > 0 {
 		buttons = append(buttons, discordgo.Button{
 			CustomID: fmt.Sprintf("show_reminder_%s", allReminders[currentIndex-1].ID.String()),
-			Label:    "Previous",
+			Label:    i18n.T(locale, "common.previous"),
 			Style:    discordgo.SecondaryButton,
 			Emoji:    &discordgo.ComponentEmoji{Name: "⬅️"},
 		})
@@ -187,7 +187,7 @@ func HandleShowReminderFromList(session *discordgo.Session, interaction *discord
 	// Back to list button
 	buttons = append(buttons, discordgo.Button{
 		CustomID: "back_to_list",
-		Label:    "Back to List",
+		Label:    i18n.T(locale, "reminders.list.back"),
 		Style:    discordgo.SecondaryButton,
 		Emoji:    &discordgo.ComponentEmoji{Name: "📝"},
 	})
@@ -196,7 +196,7 @@ func HandleShowReminderFromList(session *discordgo.Session, interaction *discord
 	if currentIndex < len(allReminders)-1 {
 		buttons = append(buttons, discordgo.Button{
 			CustomID: fmt.Sprintf("show_reminder_%s", allReminders[currentIndex+1].ID.String()),
-			Label:    "Next",
+			Label:    i18n.T(locale, "common.next"),
 			Style:    discordgo.SecondaryButton,
 			Emoji:    &discordgo.ComponentEmoji{Name: "➡️"},
 		})
@@ -210,7 +210,7 @@ func HandleShowReminderFromList(session *discordgo.Session, interaction *discord
 
 	// Add reminder position info to embed
 	embed.Footer = &discordgo.MessageEmbedFooter{
-		Text: fmt.Sprintf("Reminder %d of %d", currentIndex+1, len(allReminders)),
+		Text: i18n.T(locale, "reminders.list.position", currentIndex+1, len(allReminders)),
 	}
 
 	return session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
@@ -224,18 +224,19 @@ func HandleShowReminderFromList(session *discordgo.Session, interaction *discord
 
 // HandleBackToList handles going back to the reminders list
 func HandleBackToList(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
+	locale := utils.Locale(interaction, account)
 	repo := database.GetRepositories()
 
 	// Get all reminders for the account
 	reminders, err := repo.Reminder.GetByAccountIDWithDestinations(account.ID)
 	if err != nil {
-		return utils.SendError(session, interaction, "Database Error", "Failed to retrieve reminders.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), i18n.T(locale, "reminders.error.retrieve_failed"))
 	}
 
 	if len(reminders) == 0 {
 		embed := &discordgo.MessageEmbed{
-			Title:       "📝 Your Reminders",
-			Description: "You don't have any reminders yet. Use `/remindme` to create your first reminder!",
+			Title:       "📝 " + i18n.T(locale, "reminders.list.title"),
+			Description: i18n.T(locale, "reminders.list.empty_hint"),
 			Color:       0x3498db,
 		}
 
@@ -255,7 +256,7 @@ func HandleBackToList(session *discordgo.Session, interaction *discordgo.Interac
 	}
 
 	// Build the reminders list embed
-	embed := BuildRemindersListEmbed(remindersPointers, 1, len(reminders))
+	embed := BuildRemindersListEmbed(remindersPointers, 1, len(reminders), locale)
 
 	// Create components with "Show First Reminder" button
 	components := []discordgo.MessageComponent{
@@ -263,7 +264,7 @@ func HandleBackToList(session *discordgo.Session, interaction *discordgo.Interac
 			Components: []discordgo.MessageComponent{
 				discordgo.Button{
 					CustomID: fmt.Sprintf("show_reminder_%s", reminders[0].ID.String()),
-					Label:    "Show First Reminder",
+					Label:    i18n.T(locale, "reminders.list.show_first"),
 					Style:    discordgo.PrimaryButton,
 					Emoji:    &discordgo.ComponentEmoji{Name: "👁️"},
 				},
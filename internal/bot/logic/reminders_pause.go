@@ -1,26 +1,28 @@
 package logic
 
 import (
-	"fmt"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/ericp/chronos-bot-reminder/internal/bot/utils"
 	"github.com/ericp/chronos-bot-reminder/internal/database"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 	"github.com/google/uuid"
 )
 
 // HandlePauseReminder handles the pause subcommand
 func HandlePauseReminder(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account, options []*discordgo.ApplicationCommandInteractionDataOption) error {
+	locale := utils.Locale(interaction, account)
+
 	if len(options) == 0 {
-		return utils.SendError(session, interaction, "Missing Parameter", "Please specify a reminder to pause.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.missing_parameter"), i18n.T(locale, "reminder.pause.missing"))
 	}
 
 	reminderIDStr := options[0].StringValue()
 	reminderID, err := uuid.Parse(reminderIDStr)
 	if err != nil {
-		return utils.SendError(session, interaction, "Invalid Reminder ID", "The provided reminder ID is not valid.")
+		return utils.SendError(session, interaction, i18n.T(locale, "reminder.error.invalid_id_title"), i18n.T(locale, "reminder.error.invalid_id_description"))
 	}
 
 	repo := database.GetRepositories()
@@ -28,29 +30,29 @@ func HandlePauseReminder(session *discordgo.Session, interaction *discordgo.Inte
 	// Get the reminder with account and destinations
 	reminder, err := repo.Reminder.GetWithAccountAndDestinations(reminderID)
 	if err != nil {
-		return utils.SendError(session, interaction, "Database Error", "Failed to retrieve reminder information.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), i18n.T(locale, "reminder.error.retrieve_info_failed"))
 	}
 
 	if reminder == nil {
-		return utils.SendError(session, interaction, "Reminder Not Found", "The specified reminder does not exist.")
+		return utils.SendError(session, interaction, i18n.T(locale, "reminder.error.not_found_title"), i18n.T(locale, "reminder.error.not_exist"))
 	}
 
 	// Check if user has permission to modify this reminder
 	if !CanAccessReminder(interaction, account, reminder) {
-		return utils.SendError(session, interaction, "Permission Denied", "You don't have permission to modify this reminder.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.permission_denied"), i18n.T(locale, "reminder.error.no_modify"))
 	}
 
 	// Check if it's a one-time reminder
 	recurrenceType := services.GetRecurrenceType(int(reminder.Recurrence))
 	if recurrenceType == services.RecurrenceOnce {
-		return utils.SendError(session, interaction, "Cannot Pause One-Time Reminder", 
-			"One-time reminders cannot be paused. You can delete them instead if needed.")
+		return utils.SendError(session, interaction, i18n.T(locale, "reminder.pause.once_title"), 
+			i18n.T(locale, "reminder.pause.once_description"))
 	}
 
 	// Check if already paused
 	if services.IsPaused(int(reminder.Recurrence)) {
-		return utils.SendInfo(session, interaction, "Already Paused", 
-			fmt.Sprintf("The reminder \"%s\" is already paused.", reminder.Message))
+		return utils.SendInfo(session, interaction, i18n.T(locale, "reminder.pause.already_title"), 
+			i18n.T(locale, "reminder.pause.already_description", reminder.Message))
 	}
 
 	// Update the recurrence to include the pause bit
@@ -59,12 +61,12 @@ func HandlePauseReminder(session *discordgo.Session, interaction *discordgo.Inte
 	// Save the updated reminder
 	err = repo.Reminder.Update(reminder, true)
 	if err != nil {
-		return utils.SendError(session, interaction, "Database Error", "Failed to pause the reminder. Please try again.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), i18n.T(locale, "reminder.pause.failed"))
 	}
 
 	// Send success message
-	successEmbed := utils.BuildSuccessEmbed(session, "Reminder Paused! ⏸️", 
-		fmt.Sprintf("The reminder \"%s\" has been successfully paused. You can unpause it anytime using `/reminders unpause`.", 
+	successEmbed := utils.BuildSuccessEmbed(session, i18n.T(locale, "reminder.pause.done_title")+" ⏸️", 
+		i18n.T(locale, "reminder.pause.done_description", 
 			reminder.Message), nil)
 
 	return session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
@@ -78,14 +80,16 @@ func HandlePauseReminder(session *discordgo.Session, interaction *discordgo.Inte
 
 // HandleUnpauseReminder handles the unpause subcommand
 func HandleUnpauseReminder(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account, options []*discordgo.ApplicationCommandInteractionDataOption) error {
+	locale := utils.Locale(interaction, account)
+
 	if len(options) == 0 {
-		return utils.SendError(session, interaction, "Missing Parameter", "Please specify a reminder to restart.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.missing_parameter"), i18n.T(locale, "reminder.unpause.missing"))
 	}
 
 	reminderIDStr := options[0].StringValue()
 	reminderID, err := uuid.Parse(reminderIDStr)
 	if err != nil {
-		return utils.SendError(session, interaction, "Invalid Reminder ID", "The provided reminder ID is not valid.")
+		return utils.SendError(session, interaction, i18n.T(locale, "reminder.error.invalid_id_title"), i18n.T(locale, "reminder.error.invalid_id_description"))
 	}
 
 	repo := database.GetRepositories()
@@ -93,29 +97,29 @@ func HandleUnpauseReminder(session *discordgo.Session, interaction *discordgo.In
 	// Get the reminder with account and destinations
 	reminder, err := repo.Reminder.GetWithAccountAndDestinations(reminderID)
 	if err != nil {
-		return utils.SendError(session, interaction, "Database Error", "Failed to retrieve reminder information.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), i18n.T(locale, "reminder.error.retrieve_info_failed"))
 	}
 
 	if reminder == nil {
-		return utils.SendError(session, interaction, "Reminder Not Found", "The specified reminder does not exist.")
+		return utils.SendError(session, interaction, i18n.T(locale, "reminder.error.not_found_title"), i18n.T(locale, "reminder.error.not_exist"))
 	}
 
 	// Check if user has permission to modify this reminder
 	if !CanAccessReminder(interaction, account, reminder) {
-		return utils.SendError(session, interaction, "Permission Denied", "You don't have permission to modify this reminder.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.permission_denied"), i18n.T(locale, "reminder.error.no_modify"))
 	}
 
 	// Check if it's a one-time reminder
 	recurrenceType := services.GetRecurrenceType(int(reminder.Recurrence))
 	if recurrenceType == services.RecurrenceOnce {
-		return utils.SendError(session, interaction, "Cannot Restart One-Time Reminder", 
-			"One-time reminders cannot be paused or restarted.")
+		return utils.SendError(session, interaction, i18n.T(locale, "reminder.unpause.once_title"), 
+			i18n.T(locale, "reminder.unpause.once_description"))
 	}
 
 	// Check if not paused
 	if !services.IsPaused(int(reminder.Recurrence)) {
-		return utils.SendInfo(session, interaction, "Not Paused", 
-			fmt.Sprintf("The reminder \"%s\" is not currently paused.", reminder.Message))
+		return utils.SendInfo(session, interaction, i18n.T(locale, "reminder.unpause.not_paused_title"), 
+			i18n.T(locale, "reminder.unpause.not_paused_description", reminder.Message))
 	}
 
 	// Update the recurrence to remove the pause bit
@@ -128,13 +132,14 @@ func HandleUnpauseReminder(session *discordgo.Session, interaction *discordgo.In
 	}
 
 	// Recalculate the next occurrence from now to avoid catching up
-	nextTime, err := services.GetNextOccurrence(
+	nextTime, err := services.GetNextOccurrenceInSeries(
 		reminder.RemindAtUTC,
 		int(reminder.Recurrence),
+		services.ReminderRecurrenceSeries(reminder),
 		ianaLocation,
 	)
 	if err != nil {
-		return utils.SendError(session, interaction, "Calculation Error", "Failed to recalculate the next reminder time.")
+		return utils.SendError(session, interaction, i18n.T(locale, "reminder.unpause.calculation_title"), i18n.T(locale, "reminder.unpause.calculation_description"))
 	}
 
 	// Update the reminder time
@@ -143,12 +148,12 @@ func HandleUnpauseReminder(session *discordgo.Session, interaction *discordgo.In
 	// Save the updated reminder
 	err = repo.Reminder.Update(reminder, true)
 	if err != nil {
-		return utils.SendError(session, interaction, "Database Error", "Failed to unpause the reminder. Please try again.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), i18n.T(locale, "reminder.unpause.failed"))
 	}
 
 	// Send success message
-	successEmbed := utils.BuildSuccessEmbed(session, "Reminder Resumed! ▶️",
-		fmt.Sprintf("The reminder \"%s\" has been successfully resumed and is now active again.", 
+	successEmbed := utils.BuildSuccessEmbed(session, i18n.T(locale, "reminder.unpause.done_title")+" ▶️",
+		i18n.T(locale, "reminder.unpause.done_description", 
 			reminder.Message), nil)
 
 	return session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
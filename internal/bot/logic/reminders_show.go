@@ -5,19 +5,22 @@ import (
 	"github.com/ericp/chronos-bot-reminder/internal/bot/utils"
 	"github.com/ericp/chronos-bot-reminder/internal/database"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 	"github.com/google/uuid"
 )
 
 // HandleShowReminder handles the show subcommand
 func HandleShowReminder(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account, options []*discordgo.ApplicationCommandInteractionDataOption) error {
+	locale := utils.Locale(interaction, account)
+
 	if len(options) == 0 {
-		return utils.SendError(session, interaction, "Missing Parameter", "Please specify a reminder to show.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.missing_parameter"), i18n.T(locale, "reminder.show.missing"))
 	}
 
 	reminderIDStr := options[0].StringValue()
 	reminderID, err := uuid.Parse(reminderIDStr)
 	if err != nil {
-		return utils.SendError(session, interaction, "Invalid Reminder ID", "The provided reminder ID is not valid.")
+		return utils.SendError(session, interaction, i18n.T(locale, "reminder.error.invalid_id_title"), i18n.T(locale, "reminder.error.invalid_id_description"))
 	}
 
 	repo := database.GetRepositories()
@@ -25,20 +28,20 @@ func HandleShowReminder(session *discordgo.Session, interaction *discordgo.Inter
 	// Get the reminder with account and destinations
 	reminder, err := repo.Reminder.GetWithAccountAndDestinations(reminderID)
 	if err != nil {
-		return utils.SendError(session, interaction, "Database Error", "Failed to retrieve reminder information.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.database"), i18n.T(locale, "reminder.error.retrieve_info_failed"))
 	}
 
 	if reminder == nil {
-		return utils.SendError(session, interaction, "Reminder Not Found", "The specified reminder does not exist.")
+		return utils.SendError(session, interaction, i18n.T(locale, "reminder.error.not_found_title"), i18n.T(locale, "reminder.error.not_exist"))
 	}
 
 	// Check if user has permission to access this reminder
 	if !CanAccessReminder(interaction, account, reminder) {
-		return utils.SendError(session, interaction, "Permission Denied", "You don't have permission to access this reminder.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.permission_denied"), i18n.T(locale, "reminder.error.no_access"))
 	}
 
 	// Build the reminder embed
-	embed := BuildReminderEmbed(session, reminder)
+	embed := BuildReminderEmbed(session, reminder, locale)
 
 	return session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
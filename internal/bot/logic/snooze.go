@@ -9,6 +9,7 @@ import (
 	"github.com/ericp/chronos-bot-reminder/internal/bot/utils"
 	"github.com/ericp/chronos-bot-reminder/internal/database"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 	"github.com/google/uuid"
 )
 
@@ -28,38 +29,40 @@ var SnoozeOptions = []SnoozeOption{
 }
 
 func HandleSnooze(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
+	locale := utils.Locale(interaction, account)
+
 	// Extract reminder ID from custom_id (format: reminder_request_snooze_<reminder_id>)
 	customID := interaction.MessageComponentData().CustomID
 	parts := strings.Split(customID, "_")
 	if len(parts) != 4 {
-		return utils.SendError(session, interaction, "Error", "Invalid snooze button configuration.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.title"), i18n.T(locale, "snooze.error.invalid_button"))
 	}
 
 	reminderID, err := uuid.Parse(parts[3])
 	if err != nil {
-		return utils.SendError(session, interaction, "Error", "Invalid reminder ID.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.title"), i18n.T(locale, "reminder.error.invalid_id"))
 	}
 
 	// Get the reminder with destinations and account
 	repo := database.GetRepositories()
 	reminder, err := repo.Reminder.GetWithAccountAndDestinations(reminderID)
 	if err != nil {
-		return utils.SendError(session, interaction, "Error", "Failed to retrieve reminder.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.title"), i18n.T(locale, "reminder.error.retrieve_failed"))
 	}
 
 	if reminder == nil {
-		return utils.SendError(session, interaction, "Error", "Reminder not found. It may have been deleted.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.title"), i18n.T(locale, "reminder.error.not_found_deleted"))
 	}
 
 	// Check if user can access this reminder
 	if !CanAccessReminder(interaction, account, reminder) {
-		return utils.SendError(session, interaction, "Error", "You don't have permission to snooze this reminder.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.title"), i18n.T(locale, "snooze.error.no_permission"))
 	}
 
 	// Check if reminder can be snoozed
-	canSnooze, reason := CanSnoozeReminder(reminder)
+	canSnooze, reason := CanSnoozeReminder(reminder, locale)
 	if !canSnooze {
-		return utils.SendError(session, interaction, "Error", reason)
+		return utils.SendError(session, interaction, i18n.T(locale, "error.title"), reason)
 	}
 
 	// Create snooze duration selection menu
@@ -68,8 +71,8 @@ func HandleSnooze(session *discordgo.Session, interaction *discordgo.Interaction
 		Data: &discordgo.InteractionResponseData{
 			Embeds: []*discordgo.MessageEmbed{
 				{
-					Title:       "⏰ Snooze Reminder",
-					Description: fmt.Sprintf("**Message:** %s\n\nChoose how long to snooze this reminder:", reminder.Message),
+					Title:       "⏰ " + i18n.T(locale, "snooze.menu.title"),
+					Description: fmt.Sprintf("**%s** %s\n\n%s", i18n.T(locale, "reminder.field.message"), reminder.Message, i18n.T(locale, "snooze.menu.choose")),
 					Color:       utils.ColorInfo,
 					Thumbnail: &discordgo.MessageEmbedThumbnail{
 						URL: utils.ClockLogo,
@@ -80,17 +83,17 @@ func HandleSnooze(session *discordgo.Session, interaction *discordgo.Interaction
 				discordgo.ActionsRow{
 					Components: []discordgo.MessageComponent{
 						discordgo.Button{
-							Label:    "5 minutes",
+							Label:    i18n.T(locale, "snooze.duration.5m"),
 							Style:    discordgo.PrimaryButton,
 							CustomID: fmt.Sprintf("reminder_snooze_duration_%s_5m", reminderID.String()),
 						},
 						discordgo.Button{
-							Label:    "10 minutes",
+							Label:    i18n.T(locale, "snooze.duration.10m"),
 							Style:    discordgo.PrimaryButton,
 							CustomID: fmt.Sprintf("reminder_snooze_duration_%s_10m", reminderID.String()),
 						},
 						discordgo.Button{
-							Label:    "30 minutes",
+							Label:    i18n.T(locale, "snooze.duration.30m"),
 							Style:    discordgo.PrimaryButton,
 							CustomID: fmt.Sprintf("reminder_snooze_duration_%s_30m", reminderID.String()),
 						},
@@ -99,17 +102,17 @@ func HandleSnooze(session *discordgo.Session, interaction *discordgo.Interaction
 				discordgo.ActionsRow{
 					Components: []discordgo.MessageComponent{
 						discordgo.Button{
-							Label:    "1 hour",
+							Label:    i18n.T(locale, "snooze.duration.1h"),
 							Style:    discordgo.PrimaryButton,
 							CustomID: fmt.Sprintf("reminder_snooze_duration_%s_1h", reminderID.String()),
 						},
 						discordgo.Button{
-							Label:    "6 hours",
+							Label:    i18n.T(locale, "snooze.duration.6h"),
 							Style:    discordgo.PrimaryButton,
 							CustomID: fmt.Sprintf("reminder_snooze_duration_%s_6h", reminderID.String()),
 						},
 						discordgo.Button{
-							Label:    "1 day",
+							Label:    i18n.T(locale, "snooze.duration.1d"),
 							Style:    discordgo.PrimaryButton,
 							CustomID: fmt.Sprintf("reminder_snooze_duration_%s_1d", reminderID.String()),
 						},
@@ -118,7 +121,7 @@ func HandleSnooze(session *discordgo.Session, interaction *discordgo.Interaction
 				discordgo.ActionsRow{
 					Components: []discordgo.MessageComponent{
 						discordgo.Button{
-							Label:    "Cancel",
+							Label:    i18n.T(locale, "common.cancel"),
 							Style:    discordgo.SecondaryButton,
 							CustomID: "reminder_snooze_cancel",
 						},
@@ -133,16 +136,18 @@ func HandleSnooze(session *discordgo.Session, interaction *discordgo.Interaction
 }
 
 func HandleSnoozeDuration(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
+	locale := utils.Locale(interaction, account)
+
 	// Extract reminder ID and duration from custom_id (format: reminder_snooze_duration_<reminder_id>_<duration>)
 	customID := interaction.MessageComponentData().CustomID
 	parts := strings.Split(customID, "_")
 	if len(parts) != 5 {
-		return utils.SendError(session, interaction, "Error", "Invalid snooze configuration.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.title"), i18n.T(locale, "snooze.error.invalid_button"))
 	}
 
 	reminderID, err := uuid.Parse(parts[3])
 	if err != nil {
-		return utils.SendError(session, interaction, "Error", "Invalid reminder ID.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.title"), i18n.T(locale, "reminder.error.invalid_id"))
 	}
 
 	durationStr := parts[4]
@@ -163,53 +168,39 @@ func HandleSnoozeDuration(session *discordgo.Session, interaction *discordgo.Int
 	case "1d":
 		duration = 24 * time.Hour
 	default:
-		return utils.SendError(session, interaction, "Error", "Invalid snooze duration.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.title"), i18n.T(locale, "snooze.error.invalid_duration"))
 	}
 
 	// Get the reminder
 	repo := database.GetRepositories()
 	reminder, err := repo.Reminder.GetWithAccountAndDestinations(reminderID)
 	if err != nil {
-		return utils.SendError(session, interaction, "Error", "Failed to retrieve reminder.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.title"), i18n.T(locale, "reminder.error.retrieve_failed"))
 	}
 
 	if reminder == nil {
-		return utils.SendError(session, interaction, "Error", "Reminder not found. It may have been deleted.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.title"), i18n.T(locale, "reminder.error.not_found_deleted"))
 	}
 
 	// Verify permissions again
 	if !CanAccessReminder(interaction, account, reminder) {
-		return utils.SendError(session, interaction, "Error", "You don't have permission to snooze this reminder.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.title"), i18n.T(locale, "snooze.error.no_permission"))
 	}
 
 	// Check if can still snooze (in case state changed)
-	canSnooze, reason := CanSnoozeReminder(reminder)
+	canSnooze, reason := CanSnoozeReminder(reminder, locale)
 	if !canSnooze {
-		return utils.SendError(session, interaction, "Error", reason)
+		return utils.SendError(session, interaction, i18n.T(locale, "error.title"), reason)
 	}
 
 	// Format duration for display
-	var durationLabel string
-	switch durationStr {
-	case "5m":
-		durationLabel = "5 minutes"
-	case "10m":
-		durationLabel = "10 minutes"
-	case "30m":
-		durationLabel = "30 minutes"
-	case "1h":
-		durationLabel = "1 hour"
-	case "6h":
-		durationLabel = "6 hours"
-	case "1d":
-		durationLabel = "1 day"
-	}
+	durationLabel := i18n.T(locale, "snooze.duration."+durationStr)
 
 	// Update the reminder in the database
 	snoozeUntil := time.Now().UTC().Add(duration)
 	err = repo.Reminder.SnoozeReminder(reminder, snoozeUntil)
 	if err != nil {
-		return utils.SendError(session, interaction, "Error", "Failed to update reminder.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.title"), i18n.T(locale, "reminder.error.update_failed"))
 	}
 
 	// Respond with success message
@@ -218,8 +209,8 @@ func HandleSnoozeDuration(session *discordgo.Session, interaction *discordgo.Int
 		Data: &discordgo.InteractionResponseData{
 			Embeds: []*discordgo.MessageEmbed{
 				{
-					Title:       "✅ Reminder Snoozed",
-					Description: fmt.Sprintf("**Message:** %s\n\nThis reminder has been snoozed for **%s**.\n\nYou'll be reminded again at <t:%d:F>.", reminder.Message, durationLabel, snoozeUntil.Unix()),
+					Title:       "✅ " + i18n.T(locale, "snooze.done.title"),
+					Description: fmt.Sprintf("**%s** %s\n\n%s", i18n.T(locale, "reminder.field.message"), reminder.Message, i18n.T(locale, "snooze.done.description", durationLabel, snoozeUntil.Unix())),
 					Color:       utils.ColorSuccess,
 					Thumbnail: &discordgo.MessageEmbedThumbnail{
 						URL: utils.ClockLogo,
@@ -232,13 +223,15 @@ func HandleSnoozeDuration(session *discordgo.Session, interaction *discordgo.Int
 }
 
 func HandleSnoozeCancel(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
+	locale := utils.Locale(interaction, account)
+
 	return session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseUpdateMessage,
 		Data: &discordgo.InteractionResponseData{
 			Embeds: []*discordgo.MessageEmbed{
 				{
-					Title:       "❌ Snooze Cancelled",
-					Description: "The snooze action has been cancelled.",
+					Title:       "❌ " + i18n.T(locale, "snooze.cancelled.title"),
+					Description: i18n.T(locale, "snooze.cancelled.description"),
 					Color:       utils.ColorWarning,
 				},
 			},
@@ -8,6 +8,7 @@ import (
 	"github.com/ericp/chronos-bot-reminder/internal/bot/utils"
 	"github.com/ericp/chronos-bot-reminder/internal/database"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 )
 
@@ -32,7 +33,8 @@ func TimezoneHandler(session *discordgo.Session, interaction *discordgo.Interact
 }
 
 // timezoneListHandler handles the timezone list subcommand
-func TimezoneListHandler(session *discordgo.Session, interaction *discordgo.InteractionCreate, _ *models.Account) error {
+func TimezoneListHandler(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
+	locale := utils.Locale(interaction, account)
 	repo := database.GetRepositories()
 	timezones, err := repo.Timezone.GetAll()
 	if err != nil {
@@ -42,8 +44,8 @@ func TimezoneListHandler(session *discordgo.Session, interaction *discordgo.Inte
 	if len(timezones) == 0 {
 		// Build embed message
 		embed := &discordgo.MessageEmbed{
-			Title:       "No Timezones Available",
-			Description: "There are currently no timezones available.",
+			Title:       i18n.T(locale, "timezone.none.title"),
+			Description: i18n.T(locale, "timezone.none.description"),
 			Color:       0xff0000, // Red color
 		}
 
@@ -69,7 +71,7 @@ func TimezoneListHandler(session *discordgo.Session, interaction *discordgo.Inte
 
 	// Build embed message
 	embed := &discordgo.MessageEmbed{
-		Title:       "Available Timezones",
+		Title:       i18n.T(locale, "timezone.list.title"),
 		Description: timezoneList,
 		Color:       0x00ff00, // Green color
 	}
@@ -83,7 +85,8 @@ func TimezoneListHandler(session *discordgo.Session, interaction *discordgo.Inte
 }
 
 // timezoneChangeHandler handles the timezone change subcommand
-func timezoneChangeHandler(session *discordgo.Session, interaction *discordgo.InteractionCreate, _ *models.Account) error {
+func timezoneChangeHandler(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
+	locale := utils.Locale(interaction, account)
 	repo := database.GetRepositories()
 	timezones, err := repo.Timezone.GetAll()
 	if err != nil {
@@ -92,8 +95,8 @@ func timezoneChangeHandler(session *discordgo.Session, interaction *discordgo.In
 
 	if len(timezones) == 0 {
 		embed := &discordgo.MessageEmbed{
-			Title:       "No Timezones Available",
-			Description: "There are currently no timezones available to change to.",
+			Title:       i18n.T(locale, "timezone.none.title"),
+			Description: i18n.T(locale, "timezone.none.change_description"),
 			Color:       0xff0000, // Red color
 		}
 
@@ -134,11 +137,11 @@ func timezoneChangeHandler(session *discordgo.Session, interaction *discordgo.In
 
 	selectMenu := &discordgo.SelectMenu{
 		CustomID:    "timezone_change_select",
-		Placeholder: "Choose a timezone...",
+		Placeholder: i18n.T(locale, "timezone.change.placeholder"),
 		Options:     options,
 	}
 
-	embed := utils.BuildInfoEmbed(session, "Change Timezone", "Select your new timezone from the dropdown menu below.")
+	embed := utils.BuildInfoEmbed(session, i18n.T(locale, "timezone.change.title"), i18n.T(locale, "timezone.change.description"))
 
 	return session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -154,9 +157,11 @@ func timezoneChangeHandler(session *discordgo.Session, interaction *discordgo.In
 }
 
 func timezoneDisplayHandler(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
+	locale := utils.Locale(interaction, account)
+
 	// Get the user's current timezone
 	if account.Timezone == nil {
-		return utils.SendError(session, interaction, "No Timezone Set", "You currently do not have a timezone set. Please use `/timezone change` to select one.")
+		return utils.SendError(session, interaction, i18n.T(locale, "timezone.display.none_title"), i18n.T(locale, "timezone.display.none_description"))
 	}
 
 	gmtOffsetStr := ""
@@ -166,13 +171,15 @@ func timezoneDisplayHandler(session *discordgo.Session, interaction *discordgo.I
 		gmtOffsetStr = fmt.Sprintf("GMT%.1f", account.Timezone.GMTOffset)
 	}
 
-	message := fmt.Sprintf("Your current timezone is **%s** (%s).", account.Timezone.Name, gmtOffsetStr)
+	message := i18n.T(locale, "timezone.display.description", account.Timezone.Name, gmtOffsetStr)
 
-	return utils.SendSuccess(session, interaction, "Current Timezone", message, nil)
+	return utils.SendSuccess(session, interaction, i18n.T(locale, "timezone.display.title"), message, nil)
 }
 
 // HandleTimezoneSelectMenu handles the timezone selection from the dropdown
 func HandleTimezoneSelectMenu(session *discordgo.Session, interaction *discordgo.InteractionCreate, account *models.Account) error {
+	locale := utils.Locale(interaction, account)
+
 	if len(interaction.MessageComponentData().Values) == 0 {
 		return fmt.Errorf("no timezone selected")
 	}
@@ -186,14 +193,14 @@ func HandleTimezoneSelectMenu(session *discordgo.Session, interaction *discordgo
 	// Change the user's timezone
 	err = services.ChangeAccountTimezone(account, uint(timezoneID))
 	if err != nil {
-		return utils.SendError(session, interaction, "Error", "Failed to change your timezone. Please try again.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.title"), i18n.T(locale, "timezone.changed.failed"))
 	}
 
 	// Get the timezone name for confirmation
 	repo := database.GetRepositories()
 	timezone, err := repo.Timezone.GetByID(uint(timezoneID))
 	if err != nil || timezone == nil {
-		return utils.SendError(session, interaction, "Error", "Failed to retrieve the selected timezone details.")
+		return utils.SendError(session, interaction, i18n.T(locale, "error.title"), i18n.T(locale, "timezone.changed.details_failed"))
 	}
 
 	gmtOffsetStr := ""
@@ -203,5 +210,5 @@ func HandleTimezoneSelectMenu(session *discordgo.Session, interaction *discordgo
 		gmtOffsetStr = fmt.Sprintf("GMT%.1f", timezone.GMTOffset)
 	}
 
-	return utils.SendSuccess(session, interaction, "Timezone Changed", fmt.Sprintf("Your timezone has been successfully changed to **%s** (%s)!", timezone.Name, gmtOffsetStr), nil)
+	return utils.SendSuccess(session, interaction, i18n.T(locale, "timezone.changed.title"), i18n.T(locale, "timezone.changed.description", timezone.Name, gmtOffsetStr), nil)
 }
\ No newline at end of file
@@ -8,6 +8,8 @@ import (
 	"github.com/bwmarrin/discordgo"
 	"github.com/ericp/chronos-bot-reminder/internal/bot/commands"
 	"github.com/ericp/chronos-bot-reminder/internal/bot/events"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/ericp/chronos-bot-reminder/internal/tracing"
 )
 
 var ErrMissingToken = errors.New("[DISCORD_BOT] - missing Discord bot token")
@@ -40,13 +42,18 @@ func GetDiscordSession() *discordgo.Session {
 			log.Fatalf("[DISCORD_BOT] - ❌ Cannot register commands: %v", err)
 		}
 
-		log.Printf("[DISCORD_BOT] - ✅ Registered %d commands", commandsLength)
+		logging.For(logging.Bot).Info("Registered commands", "count", commandsLength)
 
 		discord = session
 	}
 	return discord
 }
 
+// CurrentSession returns the Discord session if it was started, without opening one
+func CurrentSession() *discordgo.Session {
+	return discord
+}
+
 // newDiscordSession creates a new Discord session
 func newDiscordSession(token string) (*discordgo.Session, error) {
 	if token == "" {
@@ -58,6 +65,9 @@ func newDiscordSession(token string) (*discordgo.Session, error) {
 		return nil, err
 	}
 
+	// The REST calls join the trace of the context they are made with
+	session.Client.Transport = tracing.Transport(session.Client.Transport)
+
 	return session, nil
 }
 
@@ -71,7 +81,7 @@ func StopDiscordSession() {
 	if discord != nil {
 		err := discord.Close()
 		if err != nil {
-			log.Printf("[DISCORD_BOT] - ❌ Error closing Discord session: %v", err)
+			logging.For(logging.Bot).Error("Error closing Discord session", "error", err)
 		}
 		discord = nil
 	}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
+)
+
+// Locale returns the language to reply in: the one chosen on the account, then the
+// language of the Discord client, then English. The account may be nil.
+func Locale(interaction *discordgo.InteractionCreate, account *models.Account) string {
+	var accountLocale string
+	if account != nil {
+		accountLocale = account.Locale
+	}
+	return i18n.Resolve(accountLocale, string(interaction.Locale))
+}
+
+// DiscordLocales returns the Discord locales of each supported language but English,
+// to localize command names and descriptions
+func DiscordLocales() map[string][]discordgo.Locale {
+	return map[string][]discordgo.Locale{
+		i18n.French:  {discordgo.French},
+		i18n.Spanish: {discordgo.SpanishES, discordgo.SpanishLATAM},
+	}
+}
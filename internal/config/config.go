@@ -23,6 +23,14 @@ type Config struct {
     DbName          string
     JWTSecret       string
 
+	// DbMigrateOnStart applies the pending schema migrations when the application
+	// starts. When disabled they are applied with "chronos migrate up".
+	DbMigrateOnStart bool `env:"DB_MIGRATE_ON_START" envDefault:"true"`
+
+	// TrustedProxies lists the addresses or CIDR ranges of the reverse proxies, comma
+	// separated. X-Forwarded-For and X-Real-IP are only read from these peers.
+	TrustedProxies string `env:"TRUSTED_PROXIES" envDefault:""`
+
 	// Discord OAuth configuration
 	DiscordClientID     string
 	DiscordClientSecret string
@@ -34,9 +42,24 @@ type Config struct {
 	RedisPassword string `env:"REDIS_PASSWORD" envDefault:""`
 	RedisDB       string `env:"REDIS_DB" envDefault:"0"`
 
+	// Email configuration
+	// MailTransport is resend, smtp, file or log (DEV only). When empty, Resend is used
+	// if an API key is set, then SMTP if a host is set, otherwise emails are only logged
+	// in DEV and disabled elsewhere.
+	MailTransport string `env:"MAIL_TRANSPORT" envDefault:""`
+	MailFrom      string `env:"MAIL_FROM" envDefault:"noreply@noreply.chronosrmd.com"`
+	MailFileDir   string `env:"MAIL_FILE_DIR" envDefault:"mail"`
+
 	// Resend email service configuration
 	ResendAPIKey string
 
+	// SMTP relay configuration, SMTPSecurity is starttls, tls or none
+	SMTPHost     string `env:"SMTP_HOST" envDefault:""`
+	SMTPPort     int    `env:"SMTP_PORT" envDefault:"587"`
+	SMTPUsername string `env:"SMTP_USERNAME" envDefault:""`
+	SMTPPassword string `env:"SMTP_PASSWORD" envDefault:""`
+	SMTPSecurity string `env:"SMTP_TLS" envDefault:"starttls"`
+
 	// Web app configuration
 	WebAppURL string
 
@@ -49,6 +72,79 @@ type Config struct {
 	RateLimitRequestsPerWindow int    `env:"RATE_LIMIT_REQUESTS_PER_WINDOW" envDefault:"100"`
 	RateLimitWindowSeconds     int    `env:"RATE_LIMIT_WINDOW_SECONDS" envDefault:"60"`
 	RateLimitEnabled           bool   `env:"RATE_LIMIT_ENABLED" envDefault:"true"`
+
+	// Dispatch retry configuration (transient delivery failures)
+	// MaxAttempts counts the first delivery, 1 disables retries.
+	DispatchRetryMaxAttempts      int `env:"DISPATCH_RETRY_MAX_ATTEMPTS" envDefault:"5"`
+	DispatchRetryBaseDelaySeconds int `env:"DISPATCH_RETRY_BASE_DELAY_SECONDS" envDefault:"30"`
+	DispatchRetryMaxDelaySeconds  int `env:"DISPATCH_RETRY_MAX_DELAY_SECONDS" envDefault:"900"`
+
+	// Delivery history configuration
+	// Deliveries older than the retention are purged by the garbage collector, 0 keeps them forever.
+	DeliveryRetentionDays int `env:"DELIVERY_RETENTION_DAYS" envDefault:"90"`
+
+	// Calendar recurrences configuration
+	// End of month: clamp (Jan 31 -> Feb 28), skip (Jan 31 -> Mar 31) or rollover (Jan 31 -> Mar 3).
+	// Leap day: feb28, mar1 or skip (Feb 29 reminders only fire on leap years).
+	RecurrenceEndOfMonthPolicy string `env:"RECURRENCE_END_OF_MONTH_POLICY" envDefault:"clamp"`
+	RecurrenceLeapDayPolicy    string `env:"RECURRENCE_LEAP_DAY_POLICY" envDefault:"feb28"`
+
+	// Webhook signing configuration
+	// Key encrypting the per-destination signing secrets, JWT_SECRET is used when empty.
+	WebhookSecretKey string `env:"WEBHOOK_SECRET_KEY" envDefault:""`
+
+	// Telegram bot configuration
+	// When the token is empty, Telegram destinations are disabled.
+	// The API base URL can point to a local fake of the Bot API.
+	TelegramBotToken      string `env:"TELEGRAM_BOT_TOKEN" envDefault:""`
+	TelegramBotUsername   string `env:"TELEGRAM_BOT_USERNAME" envDefault:""`
+	TelegramAPIBaseURL    string `env:"TELEGRAM_API_BASE_URL" envDefault:"https://api.telegram.org"`
+	TelegramWebhookURL    string `env:"TELEGRAM_WEBHOOK_URL" envDefault:""`
+	TelegramWebhookSecret string `env:"TELEGRAM_WEBHOOK_SECRET" envDefault:""`
+
+	// SMS and voice configuration (Twilio-compatible API)
+	// When the credentials or the sender number are empty, SMS and voice destinations are disabled.
+	TelephonyAPIBaseURL        string `env:"TELEPHONY_API_BASE_URL" envDefault:"https://api.twilio.com"`
+	TelephonyAccountSID        string `env:"TELEPHONY_ACCOUNT_SID" envDefault:""`
+	TelephonyAuthToken         string `env:"TELEPHONY_AUTH_TOKEN" envDefault:""`
+	TelephonyFromNumber        string `env:"TELEPHONY_FROM_NUMBER" envDefault:""`
+	TelephonySMSMonthlyQuota   int    `env:"TELEPHONY_SMS_MONTHLY_QUOTA" envDefault:"50"`
+	TelephonyVoiceMonthlyQuota int    `env:"TELEPHONY_VOICE_MONTHLY_QUOTA" envDefault:"10"`
+
+	// Web Push (VAPID) configuration
+	// Base64url P-256 key pair, when empty browser notifications are disabled.
+	// The subject is the contact push services reach in case of problems (mailto: or https:).
+	VAPIDPublicKey  string `env:"VAPID_PUBLIC_KEY" envDefault:""`
+	VAPIDPrivateKey string `env:"VAPID_PRIVATE_KEY" envDefault:""`
+	VAPIDSubject    string `env:"VAPID_SUBJECT" envDefault:"mailto:noreply@noreply.chronosrmd.com"`
+
+	// Multi-instance scheduling
+	// Only the instance holding the Redis leader lease dispatches reminders, the others
+	// take over within the TTL when it dies. The instance ID defaults to the host name
+	// with a random suffix.
+	SchedulerInstanceID       string `env:"SCHEDULER_INSTANCE_ID" envDefault:""`
+	SchedulerLeaderTTLSeconds int    `env:"SCHEDULER_LEADER_TTL_SECONDS" envDefault:"15"`
+
+	// Prometheus metrics
+	// Served on GET /metrics, behind a bearer token. The token can only be left empty in DEV.
+	MetricsEnabled bool   `env:"METRICS_ENABLED" envDefault:"false"`
+	MetricsToken   string `env:"METRICS_TOKEN" envDefault:""`
+
+	// Structured logging
+	// LOG_LEVELS overrides the level per subsystem ("engine=debug,api=warn"). The levels
+	// can be changed at runtime on /api/admin/log-levels, enabled by LOG_ADMIN_TOKEN.
+	LogFormat     string `env:"LOG_FORMAT" envDefault:"json"`
+	LogLevel      string `env:"LOG_LEVEL" envDefault:""`
+	LogLevels     string `env:"LOG_LEVELS" envDefault:""`
+	LogAdminToken string `env:"LOG_ADMIN_TOKEN" envDefault:""`
+
+	// OpenTelemetry tracing
+	// Spans are exported over OTLP/HTTP, the endpoint defaults to http://localhost:4318.
+	// The sample percent applies to the root spans, the children follow their parent.
+	TracingEnabled       bool   `env:"TRACING_ENABLED" envDefault:"false"`
+	TracingEndpoint      string `env:"OTEL_EXPORTER_OTLP_ENDPOINT" envDefault:""`
+	TracingServiceName   string `env:"OTEL_SERVICE_NAME" envDefault:"chronos"`
+	TracingSamplePercent int    `env:"TRACING_SAMPLE_PERCENT" envDefault:"100"`
 }
 
 var (
@@ -81,14 +177,29 @@ func Load() *Config {
         DbName:          getEnv("DB_NAME", "ChronosReminder"),
         JWTSecret:       getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
 
+		DbMigrateOnStart: getEnv("DB_MIGRATE_ON_START", "true") == "true",
+		TrustedProxies:   getEnv("TRUSTED_PROXIES", ""),
+
 		// Discord OAuth configuration
 		DiscordClientID:     getEnv("DISCORD_CLIENT_ID", ""),
 		DiscordClientSecret: getEnv("DISCORD_CLIENT_SECRET", ""),
 		DiscordRedirectURI:  getEnv("DISCORD_REDIRECT_URI", URLWebApp+"/auth/callback/discord"),
 
+		// Email configuration
+		MailTransport: getEnv("MAIL_TRANSPORT", ""),
+		MailFrom:      getEnv("MAIL_FROM", EmailNoreply),
+		MailFileDir:   getEnv("MAIL_FILE_DIR", "mail"),
+
 		// Resend email service configuration
 		ResendAPIKey: getEnv("RESEND_API_KEY", ""),
 
+		// SMTP relay configuration
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     parseInt(getEnv("SMTP_PORT", "587")),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		SMTPSecurity: getEnv("SMTP_TLS", "starttls"),
+
 		// Web app URL for verification links
 		WebAppURL: getEnv("WEB_APP_URL", URLWebApp),
 
@@ -99,6 +210,61 @@ func Load() *Config {
 		RateLimitRequestsPerWindow: parseInt(getEnv("RATE_LIMIT_REQUESTS_PER_WINDOW", "100")),
 		RateLimitWindowSeconds:     parseInt(getEnv("RATE_LIMIT_WINDOW_SECONDS", "60")),
 		RateLimitEnabled:           getEnv("RATE_LIMIT_ENABLED", "true") == "true",
+
+		// Dispatch retry configuration
+		DispatchRetryMaxAttempts:      parseInt(getEnv("DISPATCH_RETRY_MAX_ATTEMPTS", "5")),
+		DispatchRetryBaseDelaySeconds: parseInt(getEnv("DISPATCH_RETRY_BASE_DELAY_SECONDS", "30")),
+		DispatchRetryMaxDelaySeconds:  parseInt(getEnv("DISPATCH_RETRY_MAX_DELAY_SECONDS", "900")),
+
+		// Delivery history
+		DeliveryRetentionDays: parseInt(getEnv("DELIVERY_RETENTION_DAYS", "90")),
+
+		// Calendar recurrences configuration
+		RecurrenceEndOfMonthPolicy: getEnv("RECURRENCE_END_OF_MONTH_POLICY", "clamp"),
+		RecurrenceLeapDayPolicy:    getEnv("RECURRENCE_LEAP_DAY_POLICY", "feb28"),
+
+		// Webhook signing configuration
+		WebhookSecretKey: getEnv("WEBHOOK_SECRET_KEY", ""),
+
+		// Telegram bot configuration
+		TelegramBotToken:      getEnv("TELEGRAM_BOT_TOKEN", ""),
+		TelegramBotUsername:   getEnv("TELEGRAM_BOT_USERNAME", ""),
+		TelegramAPIBaseURL:    getEnv("TELEGRAM_API_BASE_URL", "https://api.telegram.org"),
+		TelegramWebhookURL:    getEnv("TELEGRAM_WEBHOOK_URL", ""),
+		TelegramWebhookSecret: getEnv("TELEGRAM_WEBHOOK_SECRET", ""),
+
+		// SMS and voice configuration
+		TelephonyAPIBaseURL:        getEnv("TELEPHONY_API_BASE_URL", "https://api.twilio.com"),
+		TelephonyAccountSID:        getEnv("TELEPHONY_ACCOUNT_SID", ""),
+		TelephonyAuthToken:         getEnv("TELEPHONY_AUTH_TOKEN", ""),
+		TelephonyFromNumber:        getEnv("TELEPHONY_FROM_NUMBER", ""),
+		TelephonySMSMonthlyQuota:   parseInt(getEnv("TELEPHONY_SMS_MONTHLY_QUOTA", "50")),
+		TelephonyVoiceMonthlyQuota: parseInt(getEnv("TELEPHONY_VOICE_MONTHLY_QUOTA", "10")),
+
+		// Web Push (VAPID) configuration
+		VAPIDPublicKey:  getEnv("VAPID_PUBLIC_KEY", ""),
+		VAPIDPrivateKey: getEnv("VAPID_PRIVATE_KEY", ""),
+		VAPIDSubject:    getEnv("VAPID_SUBJECT", "mailto:"+EmailNoreply),
+
+		// Multi-instance scheduling
+		SchedulerInstanceID:       getEnv("SCHEDULER_INSTANCE_ID", ""),
+		SchedulerLeaderTTLSeconds: parseInt(getEnv("SCHEDULER_LEADER_TTL_SECONDS", "15")),
+
+		// Prometheus metrics
+		MetricsEnabled: getEnv("METRICS_ENABLED", "false") == "true",
+		MetricsToken:   getEnv("METRICS_TOKEN", ""),
+
+		// Structured logging
+		LogFormat:     getEnv("LOG_FORMAT", "json"),
+		LogLevel:      getEnv("LOG_LEVEL", ""),
+		LogLevels:     getEnv("LOG_LEVELS", ""),
+		LogAdminToken: getEnv("LOG_ADMIN_TOKEN", ""),
+
+		// OpenTelemetry tracing
+		TracingEnabled:       getEnv("TRACING_ENABLED", "false") == "true",
+		TracingEndpoint:      getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		TracingServiceName:   getEnv("OTEL_SERVICE_NAME", "chronos"),
+		TracingSamplePercent: parseInt(getEnv("TRACING_SAMPLE_PERCENT", "100")),
     }
 
     return cfg
@@ -111,6 +277,8 @@ func GetDatabaseConfig() *Config {
         DbUser:     os.Getenv("DB_USER"),
         DbPassword: os.Getenv("DB_PASSWORD"),
         DbName:     os.Getenv("DB_NAME"),
+
+		DbMigrateOnStart: getEnv("DB_MIGRATE_ON_START", "true") == "true",
     }
 }
 
@@ -1,13 +1,18 @@
 package database
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"log"
 	"os"
 
 	appConfig "github.com/ericp/chronos-bot-reminder/internal/config"
+	"github.com/ericp/chronos-bot-reminder/internal/database/migrations"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
 	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/ericp/chronos-bot-reminder/internal/metrics"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -18,24 +23,10 @@ var repos *repositories.Repositories
 
 // Initialize sets up the database connection and runs migrations
 func Initialize() error {
-	var err error
-
-	dsn := fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=disable",
-		os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_USER"), os.Getenv("DB_NAME"), os.Getenv("DB_PASSWORD"))
-	
-	// Configure GORM
-	config := &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
-	}
-	
-	// Connect to database
-	DB, err = gorm.Open(postgres.Open(dsn), config)
-	if err != nil {
-		return fmt.Errorf("[DATABASE] - Failed to connect to database: %w", err)
+	if err := Connect(); err != nil {
+		return err
 	}
 
-	log.Println("[DATABASE] - ✅ Connection established")
-
 	// Initialize Redis connection
 	if err := InitializeRedis(); err != nil {
 		return fmt.Errorf("failed to initialize Redis: %w", err)
@@ -50,9 +41,11 @@ func Initialize() error {
 		}
 	}
 
-	// Run auto migrations
-	if err := runMigrations(); err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
+	// Apply the pending migrations, unless they are run with "chronos migrate up"
+	if appConfig.GetDatabaseConfig().DbMigrateOnStart {
+		if err := runMigrations(); err != nil {
+			return fmt.Errorf("failed to run migrations: %w", err)
+		}
 	}
 	
 	// Seed initial data
@@ -62,68 +55,43 @@ func Initialize() error {
 	
 	// Initialize repositories after database connection is established
 	repos = repositories.NewRepositories(DB)
+
+	// Expose the connection pools on /metrics
+	if sqlDB, err := DB.DB(); err == nil {
+		metrics.RegisterPools(sqlDB, RedisClient)
+	}
 	
 	return nil
 }
 
-// runMigrations performs automatic database migrations
+// runMigrations applies the pending schema migrations, one instance at a time
 func runMigrations() error {
-	// Create custom enum types first
-	if err := createEnumTypes(); err != nil {
+	migrator, err := NewMigrator()
+	if err != nil {
 		return err
 	}
-	
-	// AutoMigrate will create tables, missing columns and missing indexes
-	err := DB.AutoMigrate(
-		&models.Timezone{},
-		&models.Account{},
-		&models.Identity{},
-		&models.Reminder{},
-		&models.ReminderDestination{},
-		&models.ReminderError{},
-		&models.EmailVerification{},
-		&models.PasswordReset{},
-		&models.DFMNote{},
-		&models.DFMItem{},
-		&models.FcmToken{},
-	)
-	
+
+	applied, err := migrator.Up(context.Background())
 	if err != nil {
 		return err
 	}
-	
-	// Create unique constraint for provider + external_id combination
-	if err := DB.Exec(`
-		CREATE UNIQUE INDEX IF NOT EXISTS idx_identities_provider_external_id
-		ON identities(provider, external_id)
-	`).Error; err != nil {
-		return err
+	if applied > 0 {
+		logging.For(logging.App).Info("Applied database migrations", "count", applied)
 	}
-
 	return nil
 }
 
-// createEnumTypes creates custom PostgreSQL enum types if they don't already exist.
-func createEnumTypes() error {
-	if err := DB.Exec(`
-		DO $$ BEGIN
-			CREATE TYPE provider_type AS ENUM ('discord', 'app', 'api_key', 'mobile');
-		EXCEPTION WHEN duplicate_object THEN NULL;
-		END $$;
-	`).Error; err != nil {
-		return fmt.Errorf("failed to create provider_type enum: %w", err)
+// NewMigrator returns the migrator of the embedded migrations on the connected database
+func NewMigrator() (*migrations.Migrator, error) {
+	sqlDB, err := GetSQLDB()
+	if err != nil {
+		return nil, err
 	}
-
-	if err := DB.Exec(`
-		DO $$ BEGIN
-			CREATE TYPE destination_type AS ENUM ('discord_dm', 'discord_channel', 'webhook', 'email', 'android_push');
-		EXCEPTION WHEN duplicate_object THEN NULL;
-		END $$;
-	`).Error; err != nil {
-		return fmt.Errorf("failed to create destination_type enum: %w", err)
+	embedded, err := migrations.Embedded()
+	if err != nil {
+		return nil, err
 	}
-
-	return nil
+	return migrations.NewMigrator(sqlDB, embedded), nil
 }
 
 // seedData inserts initial timezone data if it doesn't exist
@@ -190,11 +158,41 @@ func seedData() error {
 	return nil
 }
 
+// Connect opens the database connection, without Redis nor migrations
+func Connect() error {
+	var err error
+
+	dsn := fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=disable",
+		os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_USER"), os.Getenv("DB_NAME"), os.Getenv("DB_PASSWORD"))
+
+	// Configure GORM
+	config := &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	}
+
+	// Connect to database
+	DB, err = gorm.Open(postgres.Open(dsn), config)
+	if err != nil {
+		return fmt.Errorf("[DATABASE] - Failed to connect to database: %w", err)
+	}
+
+	logging.For(logging.App).Info("Database connection established", "host", os.Getenv("DB_HOST"), "database", os.Getenv("DB_NAME"))
+	return nil
+}
+
 // GetDB returns the database instance
 func GetDB() *gorm.DB {
 	return DB
 }
 
+// GetSQLDB returns the connection pool under the GORM instance
+func GetSQLDB() (*sql.DB, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database is not initialized")
+	}
+	return DB.DB()
+}
+
 // GetRepositories returns the repository instances
 func GetRepositories() *repositories.Repositories {
 	return repos
@@ -0,0 +1,173 @@
+// Package migrations versions the database schema. Each migration is a pair of SQL
+// files embedded in the binary, NNNN_name.up.sql and NNNN_name.down.sql, applied in
+// order and recorded in the schema_migrations table with the checksum of its up file.
+package migrations
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed sql/*.sql
+var embedded embed.FS
+
+// fileNamePattern matches the migration files, e.g. 0011_drop_app_provider.up.sql
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is a versioned change of the schema and the statements reverting it
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // SHA-256 of the up statements
+}
+
+// AppliedMigration is a migration recorded in the schema_migrations table
+type AppliedMigration struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// State of a migration in a database
+type State string
+
+const (
+	StatePending  State = "pending"
+	StateApplied  State = "applied"
+	StateModified State = "modified" // applied, but its file changed since
+	StateMissing  State = "missing"  // applied, but unknown to this binary
+)
+
+// Status is the state of a migration in a database
+type Status struct {
+	Version   int64
+	Name      string
+	State     State
+	AppliedAt *time.Time
+}
+
+// Embedded returns the migrations shipped with the binary, in order
+func Embedded() ([]Migration, error) {
+	files, err := fs.Sub(embedded, "sql")
+	if err != nil {
+		return nil, err
+	}
+	return Load(files)
+}
+
+// Load reads the migrations at the root of fsys, in order. Every version needs both
+// files and a version is used once.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q, expected NNNN_name.up.sql or NNNN_name.down.sql", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %q", entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by %q and %q", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+			checksum := sha256.Sum256(content)
+			migration.Checksum = hex.EncodeToString(checksum[:])
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Checksum == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		if migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Statuses compares the migrations of the binary with the ones applied to a
+// database, ordered by version
+func Statuses(migrations []Migration, applied []AppliedMigration) []Status {
+	appliedByVersion := make(map[int64]AppliedMigration, len(applied))
+	for _, migration := range applied {
+		appliedByVersion[migration.Version] = migration
+	}
+
+	statuses := make([]Status, 0, len(migrations)+len(applied))
+	known := make(map[int64]bool, len(migrations))
+	for _, migration := range migrations {
+		known[migration.Version] = true
+		status := Status{Version: migration.Version, Name: migration.Name, State: StatePending}
+		if record, exists := appliedByVersion[migration.Version]; exists {
+			appliedAt := record.AppliedAt
+			status.AppliedAt = &appliedAt
+			status.State = StateApplied
+			if record.Checksum != migration.Checksum {
+				status.State = StateModified
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, record := range applied {
+		if !known[record.Version] {
+			appliedAt := record.AppliedAt
+			statuses = append(statuses, Status{Version: record.Version, Name: record.Name, State: StateMissing, AppliedAt: &appliedAt})
+		}
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses
+}
+
+// Verify refuses to migrate a database whose applied migrations do not match the
+// binary: an edited migration, or a database migrated by a newer release
+func Verify(statuses []Status) error {
+	for _, status := range statuses {
+		switch status.State {
+		case StateModified:
+			return fmt.Errorf("migration %d_%s was modified after being applied", status.Version, status.Name)
+		case StateMissing:
+			return fmt.Errorf("migration %d_%s is applied but unknown to this release", status.Version, status.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+)
+
+// advisoryLockKey identifies the migration lock among the advisory locks of the
+// database, "chronos" in ASCII
+const advisoryLockKey int64 = 0x6368726f6e6f73
+
+// createTableSQL creates the table recording the applied migrations
+const createTableSQL = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version bigint PRIMARY KEY,
+	name text NOT NULL,
+	checksum text NOT NULL,
+	applied_at timestamptz NOT NULL DEFAULT now()
+)`
+
+// Migrator applies the migrations to a PostgreSQL database. Up and Down hold an
+// advisory lock, so instances booting together migrate one after the other.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	logger     *slog.Logger
+}
+
+// NewMigrator creates a migrator of the given migrations, usually Embedded()
+func NewMigrator(db *sql.DB, migrations []Migration) *Migrator {
+	return &Migrator{db: db, migrations: migrations, logger: logging.For(logging.App)}
+}
+
+// SetLogger replaces the logger of the migrator
+func (m *Migrator) SetLogger(logger *slog.Logger) {
+	m.logger = logger
+}
+
+// Up applies the pending migrations in order and returns how many were applied. It
+// refuses to run when an applied migration was modified or is unknown.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		statuses, err := m.status(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := Verify(statuses); err != nil {
+			return err
+		}
+
+		pending := map[int64]bool{}
+		for _, status := range statuses {
+			if status.State == StatePending {
+				pending[status.Version] = true
+			}
+		}
+
+		for _, migration := range m.migrations {
+			if !pending[migration.Version] {
+				continue
+			}
+			if err := m.apply(ctx, conn, migration); err != nil {
+				return err
+			}
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns how
+// many were reverted
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	reverted := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		statuses, err := m.status(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		byVersion := make(map[int64]Migration, len(m.migrations))
+		for _, migration := range m.migrations {
+			byVersion[migration.Version] = migration
+		}
+
+		for i := len(statuses) - 1; i >= 0 && reverted < steps; i-- {
+			status := statuses[i]
+			if status.State == StatePending {
+				continue
+			}
+			if status.State == StateMissing {
+				return fmt.Errorf("cannot revert migration %d_%s: it is unknown to this release", status.Version, status.Name)
+			}
+			if err := m.revert(ctx, conn, byVersion[status.Version]); err != nil {
+				return err
+			}
+			reverted++
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status returns the state of every migration in the database
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return m.status(ctx, conn)
+}
+
+// status reads the applied migrations, creating their table on first use
+func (m *Migrator) status(ctx context.Context, conn *sql.Conn) ([]Status, error) {
+	if _, err := conn.ExecContext(ctx, createTableSQL); err != nil {
+		return nil, fmt.Errorf("failed to create the schema_migrations table: %w", err)
+	}
+
+	rows, err := conn.QueryContext(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var applied []AppliedMigration
+	for rows.Next() {
+		var record AppliedMigration
+		if err := rows.Scan(&record.Version, &record.Name, &record.Checksum, &record.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied = append(applied, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return Statuses(m.migrations, applied), nil
+}
+
+// apply runs a migration and records it, in a single transaction
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	err := inTransaction(ctx, conn, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`,
+			migration.Version, migration.Name, migration.Checksum)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+	}
+
+	m.logger.Info("Migration applied", "version", migration.Version, "name", migration.Name)
+	return nil
+}
+
+// revert runs the down statements of a migration and forgets it, in a single transaction
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	err := inTransaction(ctx, conn, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("reverting migration %d_%s failed: %w", migration.Version, migration.Name, err)
+	}
+
+	m.logger.Info("Migration reverted", "version", migration.Version, "name", migration.Name)
+	return nil
+}
+
+// withLock runs fn on a connection holding the migration lock. The advisory lock
+// belongs to the session, so everything runs on that connection.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, advisoryLockKey); err != nil {
+		return fmt.Errorf("failed to acquire the migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, advisoryLockKey); err != nil {
+			m.logger.Warn("Error releasing the migration lock, closing its connection", "error", err)
+			// The pool closes a connection reported bad, which ends the session and its lock
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+	}()
+
+	return fn(conn)
+}
+
+// inTransaction runs fn in a transaction, committed when fn succeeds
+func inTransaction(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
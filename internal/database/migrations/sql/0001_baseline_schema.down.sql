@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS "fcm_tokens";
+DROP TABLE IF EXISTS "dfm_items";
+DROP TABLE IF EXISTS "dfm_notes";
+DROP TABLE IF EXISTS "password_resets";
+DROP TABLE IF EXISTS "email_verifications";
+DROP TABLE IF EXISTS "reminder_errors";
+DROP TABLE IF EXISTS "reminder_destinations";
+DROP TABLE IF EXISTS "reminders";
+DROP TABLE IF EXISTS "identities";
+DROP TABLE IF EXISTS "accounts";
+DROP TABLE IF EXISTS "timezones";
+
+DROP TYPE IF EXISTS destination_type;
+DROP TYPE IF EXISTS provider_type;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS "reminder_deliveries" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "reminder_id" uuid NOT NULL,
+    "reminder_destination_id" uuid NOT NULL,
+    "account_id" uuid NOT NULL,
+    "destination_type" destination_type NOT NULL,
+    "scheduled_at" timestamptz NOT NULL,
+    "sent_at" timestamptz NOT NULL DEFAULT now(),
+    "latency_ms" bigint NOT NULL DEFAULT 0,
+    "duration_ms" bigint NOT NULL DEFAULT 0,
+    "attempt" bigint NOT NULL DEFAULT 1,
+    "outcome" text NOT NULL,
+    "response_code" bigint DEFAULT null,
+    "message_id" text DEFAULT null,
+    "error" text DEFAULT null,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_reminder_deliveries_account" FOREIGN KEY ("account_id") REFERENCES "accounts"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_reminder_deliveries_sent_at" ON "reminder_deliveries" ("sent_at");
+CREATE INDEX IF NOT EXISTS "idx_reminder_deliveries_account_id" ON "reminder_deliveries" ("account_id");
+CREATE INDEX IF NOT EXISTS "idx_reminder_deliveries_reminder_destination_id" ON "reminder_deliveries" ("reminder_destination_id");
+CREATE INDEX IF NOT EXISTS "idx_reminder_deliveries_reminder_id" ON "reminder_deliveries" ("reminder_id");
@@ -0,0 +1,2 @@
+ALTER TABLE "reminders" DROP COLUMN IF EXISTS "rrule_start_utc";
+ALTER TABLE "reminders" DROP COLUMN IF EXISTS "rrule";
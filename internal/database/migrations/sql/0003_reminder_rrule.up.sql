@@ -0,0 +1,2 @@
+ALTER TABLE "reminders" ADD COLUMN IF NOT EXISTS "rrule" text DEFAULT null;
+ALTER TABLE "reminders" ADD COLUMN IF NOT EXISTS "rrule_start_utc" timestamptz DEFAULT null;
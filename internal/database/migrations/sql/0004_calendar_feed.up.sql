@@ -0,0 +1,2 @@
+ALTER TABLE "accounts" ADD COLUMN IF NOT EXISTS "calendar_token_hash" text;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_accounts_calendar_token_hash" ON "accounts" ("calendar_token_hash");
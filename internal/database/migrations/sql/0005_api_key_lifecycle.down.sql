@@ -0,0 +1,5 @@
+ALTER TABLE "identities" DROP COLUMN IF EXISTS "previous_expires_at";
+ALTER TABLE "identities" DROP COLUMN IF EXISTS "previous_access_token";
+ALTER TABLE "identities" DROP COLUMN IF EXISTS "last_used_ip";
+ALTER TABLE "identities" DROP COLUMN IF EXISTS "last_used_at";
+ALTER TABLE "identities" DROP COLUMN IF EXISTS "expires_at";
@@ -0,0 +1,6 @@
+ALTER TABLE "identities" ADD COLUMN IF NOT EXISTS "expires_at" timestamptz DEFAULT null;
+ALTER TABLE "identities" ADD COLUMN IF NOT EXISTS "last_used_at" timestamptz DEFAULT null;
+ALTER TABLE "identities" ADD COLUMN IF NOT EXISTS "last_used_ip" text DEFAULT null;
+ALTER TABLE "identities" ADD COLUMN IF NOT EXISTS "previous_access_token" text DEFAULT null;
+ALTER TABLE "identities" ADD COLUMN IF NOT EXISTS "previous_expires_at" timestamptz DEFAULT null;
+CREATE INDEX IF NOT EXISTS "idx_identities_previous_access_token" ON "identities" ("previous_access_token");
@@ -0,0 +1,3 @@
+-- Enum values cannot be dropped, only the rows using them
+DELETE FROM "reminder_destinations" WHERE "type" = 'telegram';
+DELETE FROM "identities" WHERE "provider" = 'telegram';
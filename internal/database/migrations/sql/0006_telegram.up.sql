@@ -0,0 +1,2 @@
+ALTER TYPE provider_type ADD VALUE IF NOT EXISTS 'telegram';
+ALTER TYPE destination_type ADD VALUE IF NOT EXISTS 'telegram';
@@ -0,0 +1,5 @@
+-- Enum values cannot be dropped, only the rows using them
+DELETE FROM "reminder_destinations" WHERE "type" IN ('sms', 'voice');
+
+DROP TABLE IF EXISTS "telephony_usage";
+DROP TABLE IF EXISTS "phone_numbers";
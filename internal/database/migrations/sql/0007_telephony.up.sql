@@ -0,0 +1,27 @@
+ALTER TYPE destination_type ADD VALUE IF NOT EXISTS 'sms';
+ALTER TYPE destination_type ADD VALUE IF NOT EXISTS 'voice';
+
+CREATE TABLE IF NOT EXISTS "phone_numbers" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "account_id" uuid NOT NULL,
+    "number" text NOT NULL,
+    "code_hash" text DEFAULT null,
+    "code_expires_at" timestamptz DEFAULT null,
+    "code_sent_at" timestamptz DEFAULT null,
+    "attempts" bigint NOT NULL DEFAULT 0,
+    "verified_at" timestamptz DEFAULT null,
+    "created_at" timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_phone_numbers_account" FOREIGN KEY ("account_id") REFERENCES "accounts"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_phone_numbers_account_number" ON "phone_numbers" ("account_id","number");
+
+CREATE TABLE IF NOT EXISTS "telephony_usage" (
+    "account_id" uuid,
+    "month" char(7),
+    "sms_count" bigint NOT NULL DEFAULT 0,
+    "voice_count" bigint NOT NULL DEFAULT 0,
+    "updated_at" timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY ("account_id","month"),
+    CONSTRAINT "fk_telephony_usage_account" FOREIGN KEY ("account_id") REFERENCES "accounts"("id") ON DELETE CASCADE
+);
@@ -0,0 +1,4 @@
+-- Enum values cannot be dropped, only the rows using them
+DELETE FROM "reminder_destinations" WHERE "type" = 'web_push';
+
+DROP TABLE IF EXISTS "web_push_subscriptions";
@@ -0,0 +1,16 @@
+ALTER TYPE destination_type ADD VALUE IF NOT EXISTS 'web_push';
+
+CREATE TABLE IF NOT EXISTS "web_push_subscriptions" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "account_id" uuid NOT NULL,
+    "endpoint" text NOT NULL,
+    "p256dh" text NOT NULL,
+    "auth" text NOT NULL,
+    "user_agent" text,
+    "created_at" timestamptz NOT NULL DEFAULT now(),
+    "updated_at" timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_web_push_subscriptions_account" FOREIGN KEY ("account_id") REFERENCES "accounts"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_web_push_subscriptions_endpoint" ON "web_push_subscriptions" ("endpoint");
+CREATE INDEX IF NOT EXISTS "idx_web_push_subscriptions_account_id" ON "web_push_subscriptions" ("account_id");
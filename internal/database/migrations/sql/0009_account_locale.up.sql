@@ -0,0 +1 @@
+ALTER TABLE "accounts" ADD COLUMN IF NOT EXISTS "locale" varchar(8) NOT NULL DEFAULT '';
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS "reminder_dispatches" (
+    "id" uuid DEFAULT gen_random_uuid(),
+    "idempotency_key" text NOT NULL,
+    "reminder_id" uuid NOT NULL,
+    "reminder_destination_id" uuid NOT NULL,
+    "scheduled_at" timestamptz NOT NULL,
+    "status" text NOT NULL DEFAULT 'pending',
+    "attempts" bigint NOT NULL DEFAULT 0,
+    "created_at" timestamptz DEFAULT now(),
+    "completed_at" timestamptz DEFAULT null,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_reminder_dispatches_created_at" ON "reminder_dispatches" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_reminder_dispatches_status" ON "reminder_dispatches" ("status");
+CREATE INDEX IF NOT EXISTS "idx_reminder_dispatches_reminder_id" ON "reminder_dispatches" ("reminder_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_reminder_dispatches_key_destination" ON "reminder_dispatches" ("idempotency_key","reminder_destination_id");
@@ -0,0 +1,2 @@
+-- The deleted identities are not restored
+ALTER TYPE provider_type ADD VALUE IF NOT EXISTS 'app' BEFORE 'api_key';
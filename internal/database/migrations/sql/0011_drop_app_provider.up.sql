@@ -0,0 +1,9 @@
+-- The "app" provider was replaced by the email and password of the accounts. Enum
+-- values cannot be dropped: the type is recreated without it. Leftover identities
+-- of that provider cannot be read by the application anymore.
+DELETE FROM "identities" WHERE "provider" = 'app';
+
+ALTER TYPE provider_type RENAME TO provider_type_old;
+CREATE TYPE provider_type AS ENUM ('discord', 'api_key', 'mobile', 'telegram');
+ALTER TABLE "identities" ALTER COLUMN "provider" TYPE provider_type USING "provider"::text::provider_type;
+DROP TYPE provider_type_old;
@@ -0,0 +1,2 @@
+-- The backfilled starts cannot be told apart from the recorded ones, they are kept
+SELECT 1;
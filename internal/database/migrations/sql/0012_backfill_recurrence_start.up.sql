@@ -0,0 +1,4 @@
+-- Monthly and yearly recurrences come back to the day of the first occurrence.
+-- Reminders created before it was stored get their current date as that day, so
+-- a clamped occurrence (January 31 to February 28) no longer becomes the new day.
+UPDATE "reminders" SET "rrule_start_utc" = "remind_at_utc" WHERE "rrule_start_utc" IS NULL;
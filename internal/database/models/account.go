@@ -19,6 +19,8 @@ type Account struct {
 	Username      *string   `json:"username"`                 // display name, nullable
 	PasswordHash  *string   `json:"-"`                        // login password, nullable; hidden in JSON
 	EmailVerified bool      `gorm:"type:boolean;default:false" json:"email_verified"`
+	CalendarTokenHash *string `gorm:"uniqueIndex" json:"-"` // hash of the calendar feed token, nil when the feed is disabled
+	Locale        string    `gorm:"type:varchar(8);not null;default:''" json:"locale"` // language of the bot replies and emails, empty until known
 	CreatedAt     time.Time `gorm:"not null;default:now()" json:"created_at"`
 	UpdatedAt     time.Time `gorm:"not null;default:now()" json:"updated_at"`
 	
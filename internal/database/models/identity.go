@@ -18,12 +18,12 @@ type ProviderType string
 
 // Providers enum.
 // NOTE: the legacy 'app' provider has been removed — email/password now live on
-// the accounts table. The 'app' value may still exist in the DB enum but is
-// never written or read by the application.
+// the accounts table. Migration 0011 dropped it from the DB enum.
 const (
-	ProviderDiscord ProviderType = "discord"
-	ProviderAPIKey  ProviderType = "api_key"
-	ProviderMobile  ProviderType = "mobile"
+	ProviderDiscord  ProviderType = "discord"
+	ProviderAPIKey   ProviderType = "api_key"
+	ProviderMobile   ProviderType = "mobile"
+	ProviderTelegram ProviderType = "telegram"
 )
 
 // Value implements the driver.Valuer interface for database storage
@@ -48,7 +48,7 @@ func (p *ProviderType) Scan(value interface{}) error {
 	}
 	
 	// Validate the scanned value
-	if *p != ProviderDiscord && *p != ProviderAPIKey && *p != ProviderMobile {
+	if !p.IsValid() {
 		return fmt.Errorf("invalid provider type: %s", *p)
 	}
 
@@ -62,7 +62,7 @@ func (p ProviderType) String() string {
 
 // IsValid checks if the provider type is valid
 func (p ProviderType) IsValid() bool {
-	return p == ProviderDiscord || p == ProviderAPIKey || p == ProviderMobile
+	return p == ProviderDiscord || p == ProviderAPIKey || p == ProviderMobile || p == ProviderTelegram
 }
 
 // Identity represents the identities table
@@ -70,12 +70,18 @@ type Identity struct {
 	ID           uuid.UUID    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
 	AccountID    uuid.UUID    `gorm:"type:uuid;not null;index" json:"account_id"`
 	Provider     ProviderType `gorm:"type:provider_type;not null" json:"provider"` // enum type from database
-	ExternalID   string    `gorm:"not null" json:"external_id"`  // discord_id, account-id (mobile), or api-key id
+	ExternalID   string    `gorm:"not null" json:"external_id"`  // discord_id, account-id (mobile), api-key id or telegram chat id
 	Username     *string   `json:"username"`                     // snapshot for display purposes
 	Avatar       *string   `json:"avatar"`                       // optional, snapshot of Discord avatar
 	AccessToken  *string   `json:"-"`                            // Discord OAuth access token or API key hash, hidden in JSON
 	RefreshToken *string   `json:"-"`                            // Discord OAuth refresh token, hidden in JSON
 	Scopes       *string   `gorm:"type:text" json:"scopes,omitempty"`        // comma-separated scopes for API keys (e.g., "reminders.read")
+	// API key lifecycle, unused by the other providers
+	ExpiresAt           *time.Time `gorm:"default:null" json:"expires_at,omitempty"`
+	LastUsedAt          *time.Time `gorm:"default:null" json:"last_used_at,omitempty"`
+	LastUsedIP          *string    `gorm:"default:null" json:"last_used_ip,omitempty"`
+	PreviousAccessToken *string    `gorm:"index;default:null" json:"-"` // hash of the key replaced by a rotation, valid until PreviousExpiresAt
+	PreviousExpiresAt   *time.Time `gorm:"default:null" json:"-"`
 	CreatedAt    time.Time `gorm:"not null;default:now()" json:"created_at"`
 	
 	// Relationships
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func (PhoneNumber) TableName() string {
+	return "phone_numbers"
+}
+
+// e164Pattern matches a phone number in E.164 format, e.g. +33612345678
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// PhoneNumber represents the phone_numbers table: a number an account receives SMS
+// and voice reminders on. It can only be used once verified with a one-time code.
+type PhoneNumber struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	AccountID     uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_phone_numbers_account_number" json:"account_id"`
+	Number        string     `gorm:"type:text;not null;uniqueIndex:idx_phone_numbers_account_number" json:"number"` // E.164
+	CodeHash      *string    `gorm:"type:text;default:null" json:"-"`                                               // SHA-256 of the pending verification code
+	CodeExpiresAt *time.Time `gorm:"type:timestamptz;default:null" json:"-"`
+	CodeSentAt    *time.Time `gorm:"type:timestamptz;default:null" json:"-"`
+	Attempts      int        `gorm:"not null;default:0" json:"-"` // wrong codes entered for the pending code
+	VerifiedAt    *time.Time `gorm:"type:timestamptz;default:null" json:"verified_at,omitempty"`
+	CreatedAt     time.Time  `gorm:"type:timestamptz;not null;default:now()" json:"created_at"`
+
+	// Relationships
+	Account *Account `gorm:"foreignKey:AccountID;references:ID;constraint:OnDelete:CASCADE" json:"account,omitempty"`
+}
+
+// BeforeCreate hooks for setting UUIDs and timestamps
+func (p *PhoneNumber) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	p.CreatedAt = time.Now()
+	return nil
+}
+
+// IsVerified reports whether the number was confirmed with a verification code
+func (p *PhoneNumber) IsVerified() bool {
+	return p.VerifiedAt != nil
+}
+
+// NormalizePhoneNumber strips the usual separators from a phone number and checks it
+// is in E.164 format. A 00 international prefix is accepted in place of +.
+func NormalizePhoneNumber(number string) (string, bool) {
+	normalized := strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "").Replace(strings.TrimSpace(number))
+	if strings.HasPrefix(normalized, "00") {
+		normalized = "+" + normalized[2:]
+	}
+	if !e164Pattern.MatchString(normalized) {
+		return "", false
+	}
+	return normalized, true
+}
@@ -21,6 +21,11 @@ type Reminder struct {
 	Message      string    `gorm:"not null" json:"message"`
 	CreatedAt    time.Time `gorm:"not null;default:now()" json:"created_at"`
 	Recurrence   int16     `gorm:"not null;default:0" json:"recurrence"`
+	// RFC 5545 rule, only used when the recurrence type is RRULE
+	RRule        *string   `gorm:"column:rrule;type:text;default:null" json:"rrule,omitempty"`
+	// First occurrence of the series: DTSTART of the rule, and the day monthly/yearly recurrences come back to.
+	// The column keeps the name it got when only RRULE recurrences used it.
+	RecurrenceStartUTC *time.Time `gorm:"column:rrule_start_utc;default:null" json:"recurrence_start_utc,omitempty"`
 	
 	// Relationships
 	Account      *Account               `gorm:"foreignKey:AccountID;constraint:OnDelete:CASCADE" json:"account,omitempty"`
@@ -34,5 +39,9 @@ func (r *Reminder) BeforeCreate(tx *gorm.DB) error {
 	}
 	r.CreatedAt = time.Now()
 	r.NextFireUTC = &r.RemindAtUTC
+	if r.RecurrenceStartUTC == nil {
+		start := r.RemindAtUTC
+		r.RecurrenceStartUTC = &start
+	}
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func (ReminderDelivery) TableName() string {
+	return "reminder_deliveries"
+}
+
+// DeliveryOutcome represents the result of a single delivery attempt
+type DeliveryOutcome string
+
+// Delivery outcomes
+const (
+	DeliveryOutcomeSuccess DeliveryOutcome = "success"
+	DeliveryOutcomeFailure DeliveryOutcome = "failure"
+)
+
+// DeliveryReceipt holds what a dispatcher learned from the remote end when
+// sending a reminder. Every field is optional: a dispatcher only fills what its
+// transport exposes (HTTP status for webhooks, message ID for Discord/FCM/email).
+type DeliveryReceipt struct {
+	ResponseCode int
+	MessageID    string
+}
+
+// ReminderDelivery represents the reminder_deliveries table.
+// One row is written for every delivery attempt of a reminder to one of its
+// destinations, successful or not. Rows are not tied to the reminder by a
+// foreign key so the history survives the garbage collection of one-time reminders.
+type ReminderDelivery struct {
+	ID                    uuid.UUID       `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ReminderID            uuid.UUID       `gorm:"type:uuid;not null;index" json:"reminder_id"`
+	ReminderDestinationID uuid.UUID       `gorm:"type:uuid;not null;index" json:"reminder_destination_id"`
+	AccountID             uuid.UUID       `gorm:"type:uuid;not null;index" json:"account_id"`
+	DestinationType       DestinationType `gorm:"type:destination_type;not null" json:"destination_type"`
+	ScheduledAt           time.Time       `gorm:"not null" json:"scheduled_at"`                // fire time the engine was aiming for
+	SentAt                time.Time       `gorm:"not null;default:now();index" json:"sent_at"` // when the dispatcher returned
+	LatencyMs             int64           `gorm:"not null;default:0" json:"latency_ms"`        // sent_at - scheduled_at
+	DurationMs            int64           `gorm:"not null;default:0" json:"duration_ms"`       // time spent inside the dispatcher
+	Attempt               int             `gorm:"not null;default:1" json:"attempt"`           // 1 for the first delivery, then one per retry
+	Outcome               DeliveryOutcome `gorm:"type:text;not null" json:"outcome"`
+	ResponseCode          *int            `gorm:"default:null" json:"response_code,omitempty"`
+	MessageID             *string         `gorm:"type:text;default:null" json:"message_id,omitempty"`
+	Error                 *string         `gorm:"type:text;default:null" json:"error,omitempty"`
+
+	// Relationships
+	Account *Account `gorm:"foreignKey:AccountID;constraint:OnDelete:CASCADE" json:"account,omitempty"`
+}
+
+// BeforeCreate hooks for setting timestamps and UUIDs
+func (d *ReminderDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	if d.SentAt.IsZero() {
+		d.SentAt = time.Now()
+	}
+	return nil
+}
+
+// IsSuccess returns true when the delivery reached its destination
+func (d *ReminderDelivery) IsSuccess() bool {
+	return d.Outcome == DeliveryOutcomeSuccess
+}
@@ -4,6 +4,8 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -23,6 +25,10 @@ const (
 	DestinationWebhook        DestinationType = "webhook"
 	DestinationEmail          DestinationType = "email"
 	DestinationAndroidPush    DestinationType = "android_push"
+	DestinationTelegram       DestinationType = "telegram"
+	DestinationSMS            DestinationType = "sms"
+	DestinationVoice          DestinationType = "voice"
+	DestinationWebPush        DestinationType = "web_push"
 )
 
 // WebhookPlatform represents the optional platform for webhook destinations
@@ -30,14 +36,26 @@ type WebhookPlatform string
 
 // Webhook platform types
 const (
-	WebhookPlatformGeneric WebhookPlatform = "generic"
-	WebhookPlatformDiscord WebhookPlatform = "discord"
-	WebhookPlatformSlack   WebhookPlatform = "slack"
+	WebhookPlatformGeneric    WebhookPlatform = "generic"
+	WebhookPlatformDiscord    WebhookPlatform = "discord"
+	WebhookPlatformSlack      WebhookPlatform = "slack"
+	WebhookPlatformTeams      WebhookPlatform = "teams"
+	WebhookPlatformMattermost WebhookPlatform = "mattermost"
+	WebhookPlatformGoogleChat WebhookPlatform = "google_chat"
+	WebhookPlatformNtfy       WebhookPlatform = "ntfy"
 )
 
+// NtfyPriorities are the priorities accepted by ntfy, by name or number
+var NtfyPriorities = []string{"1", "2", "3", "4", "5", "min", "low", "default", "high", "urgent", "max"}
+
 // IsValid checks if the webhook platform is valid
 func (w WebhookPlatform) IsValid() bool {
-	return w == WebhookPlatformGeneric || w == WebhookPlatformDiscord || w == WebhookPlatformSlack
+	switch w {
+	case WebhookPlatformGeneric, WebhookPlatformDiscord, WebhookPlatformSlack,
+		WebhookPlatformTeams, WebhookPlatformMattermost, WebhookPlatformGoogleChat, WebhookPlatformNtfy:
+		return true
+	}
+	return false
 }
 
 // String returns the string representation of WebhookPlatform
@@ -81,7 +99,8 @@ func (d DestinationType) String() string {
 
 // IsValid checks if the destination type is valid
 func (d DestinationType) IsValid() bool {
-	return d == DestinationDiscordDM || d == DestinationDiscordChannel || d == DestinationWebhook || d == DestinationEmail || d == DestinationAndroidPush
+	return d == DestinationDiscordDM || d == DestinationDiscordChannel || d == DestinationWebhook || d == DestinationEmail || d == DestinationAndroidPush || d == DestinationTelegram ||
+		d == DestinationSMS || d == DestinationVoice || d == DestinationWebPush
 }
 
 // JSONB is a custom type for JSONB fields
@@ -161,7 +180,7 @@ func (rd *ReminderDestination) ValidateMetadata() error {
 			}
 			platform := WebhookPlatform(platformStr)
 			if !platform.IsValid() {
-				return fmt.Errorf("invalid webhook platform: %s (must be 'generic', 'discord', or 'slack')", platformStr)
+				return fmt.Errorf("invalid webhook platform: %s (must be 'generic', 'discord', 'slack', 'teams', 'mattermost', 'google_chat' or 'ntfy')", platformStr)
 			}
 			
 			// Platform-specific validation
@@ -172,10 +191,26 @@ func (rd *ReminderDestination) ValidateMetadata() error {
 			case WebhookPlatformSlack:
 				// Slack webhooks can optionally have channel override
 				// No strict requirements beyond the URL
+			case WebhookPlatformTeams, WebhookPlatformGoogleChat:
+				// Teams and Google Chat webhooks only need the URL
+			case WebhookPlatformMattermost:
+				// Mattermost webhooks can optionally have channel, username and icon_url overrides
+			case WebhookPlatformNtfy:
+				// ntfy can optionally have a priority and tags, sent as headers
+				if err := validateNtfyMetadata(rd.Metadata); err != nil {
+					return err
+				}
 			case WebhookPlatformGeneric:
 				// Generic webhooks have no additional requirements
 			}
 		}
+
+		// Validate optional payload template
+		if templateVal, exists := rd.Metadata["template"]; exists {
+			if err := validateWebhookTemplate(templateVal); err != nil {
+				return err
+			}
+		}
 	case DestinationEmail:
 		if _, exists := rd.Metadata["email"]; !exists {
 			return fmt.Errorf("email destination requires email in metadata")
@@ -184,12 +219,64 @@ func (rd *ReminderDestination) ValidateMetadata() error {
 		if _, exists := rd.Metadata["account_id"]; !exists {
 			return fmt.Errorf("android_push destination requires account_id in metadata")
 		}
+	case DestinationWebPush:
+		if _, exists := rd.Metadata["account_id"]; !exists {
+			return fmt.Errorf("web_push destination requires account_id in metadata")
+		}
+	case DestinationTelegram:
+		chatID, exists := rd.Metadata["chat_id"]
+		if !exists {
+			return fmt.Errorf("telegram destination requires chat_id in metadata")
+		}
+		if chatIDStr, ok := chatID.(string); !ok || strings.TrimSpace(chatIDStr) == "" {
+			return fmt.Errorf("telegram chat_id must be a non-empty string")
+		}
+	case DestinationSMS, DestinationVoice:
+		phoneNumber, exists := rd.Metadata["phone_number"]
+		if !exists {
+			return fmt.Errorf("%s destination requires phone_number in metadata", rd.Type)
+		}
+		if phoneNumberStr, ok := phoneNumber.(string); !ok || !e164Pattern.MatchString(phoneNumberStr) {
+			return fmt.Errorf("%s phone_number must be in E.164 format (e.g. +33612345678)", rd.Type)
+		}
 	default:
 		return fmt.Errorf("invalid destination type: %s", rd.Type)
 	}
 	return nil
 }
 
+// validateNtfyMetadata checks the optional priority and tags of an ntfy destination
+func validateNtfyMetadata(metadata JSONB) error {
+	if priorityVal, exists := metadata["priority"]; exists {
+		priority := fmt.Sprint(priorityVal)
+		if _, isNumber := priorityVal.(float64); !isNumber {
+			if _, isString := priorityVal.(string); !isString {
+				return fmt.Errorf("ntfy priority must be a string or a number")
+			}
+		}
+		if !slices.Contains(NtfyPriorities, strings.ToLower(priority)) {
+			return fmt.Errorf("invalid ntfy priority: %s (must be 1-5 or min, low, default, high, urgent, max)", priority)
+		}
+	}
+
+	if tagsVal, exists := metadata["tags"]; exists {
+		switch tags := tagsVal.(type) {
+		case string:
+		case []interface{}:
+			for _, tag := range tags {
+				if _, ok := tag.(string); !ok {
+					return fmt.Errorf("ntfy tags must be strings")
+				}
+			}
+		case []string:
+		default:
+			return fmt.Errorf("ntfy tags must be a string or a list of strings")
+		}
+	}
+
+	return nil
+}
+
 // BeforeUpdate and BeforeCreate validation
 func (rd *ReminderDestination) BeforeUpdate(tx *gorm.DB) error {
 	return rd.ValidateMetadata()
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func (ReminderDispatch) TableName() string {
+	return "reminder_dispatches"
+}
+
+// DispatchStatus represents the state of an outbox entry
+type DispatchStatus string
+
+// Dispatch statuses
+const (
+	DispatchStatusPending DispatchStatus = "pending" // written before sending, or waiting for a retry
+	DispatchStatusSent    DispatchStatus = "sent"
+	DispatchStatusFailed  DispatchStatus = "failed"
+)
+
+// ReminderDispatch represents the reminder_dispatches table, the outbox of the engine.
+// An entry is written for every destination before a fire of a reminder goes out and
+// is marked once the dispatcher answers. A fire interrupted by a crash is therefore
+// resumed without sending twice what already went out. The idempotency key identifies
+// the fire (reminder ID and fire time) and is handed to the providers that dedupe.
+type ReminderDispatch struct {
+	ID                    uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	IdempotencyKey        string         `gorm:"type:text;not null;uniqueIndex:idx_reminder_dispatches_key_destination" json:"idempotency_key"`
+	ReminderID            uuid.UUID      `gorm:"type:uuid;not null;index" json:"reminder_id"`
+	ReminderDestinationID uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_reminder_dispatches_key_destination" json:"reminder_destination_id"`
+	ScheduledAt           time.Time      `gorm:"not null" json:"scheduled_at"` // fire time the key was derived from
+	Status                DispatchStatus `gorm:"type:text;not null;default:'pending';index" json:"status"`
+	Attempts              int            `gorm:"not null;default:0" json:"attempts"` // attempts made so far
+	CreatedAt             time.Time      `gorm:"default:now();index" json:"created_at"`
+	CompletedAt           *time.Time     `gorm:"default:null" json:"completed_at,omitempty"` // when it was marked sent or failed
+}
+
+// BeforeCreate hooks for setting timestamps and UUIDs
+func (d *ReminderDispatch) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = time.Now()
+	}
+	if d.Status == "" {
+		d.Status = DispatchStatusPending
+	}
+	return nil
+}
+
+// IsPending returns true while the outcome of the delivery is unknown
+func (d *ReminderDispatch) IsPending() bool {
+	return d.Status == DispatchStatusPending
+}
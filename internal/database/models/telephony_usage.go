@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (TelephonyUsage) TableName() string {
+	return "telephony_usage"
+}
+
+// TelephonyUsage represents the telephony_usage table: the SMS and voice calls sent
+// for an account during a calendar month (UTC), checked against the monthly quotas.
+type TelephonyUsage struct {
+	AccountID  uuid.UUID `gorm:"type:uuid;primaryKey" json:"account_id"`
+	Month      string    `gorm:"type:char(7);primaryKey" json:"month"` // YYYY-MM
+	SMSCount   int       `gorm:"not null;default:0" json:"sms_count"`
+	VoiceCount int       `gorm:"not null;default:0" json:"voice_count"`
+	UpdatedAt  time.Time `gorm:"type:timestamptz;not null;default:now()" json:"updated_at"`
+
+	// Relationships
+	Account *Account `gorm:"foreignKey:AccountID;references:ID;constraint:OnDelete:CASCADE" json:"account,omitempty"`
+}
+
+// TelephonyUsageMonth returns the usage month key of t
+func TelephonyUsageMonth(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
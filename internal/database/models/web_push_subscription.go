@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func (WebPushSubscription) TableName() string {
+	return "web_push_subscriptions"
+}
+
+// WebPushSubscription represents a browser subscribed to Web Push notifications.
+// The endpoint is the push service URL of that browser; P256dh and Auth are the
+// keys the payload is encrypted with (base64url, as returned by PushSubscription).
+type WebPushSubscription struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	AccountID uuid.UUID `gorm:"type:uuid;not null;index" json:"account_id"`
+	Endpoint  string    `gorm:"type:text;not null;uniqueIndex" json:"endpoint"`
+	P256dh    string    `gorm:"type:text;not null" json:"-"`
+	Auth      string    `gorm:"type:text;not null" json:"-"`
+	UserAgent string    `gorm:"type:text" json:"user_agent,omitempty"`
+	CreatedAt time.Time `gorm:"type:timestamptz;not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"type:timestamptz;not null;default:now()" json:"updated_at"`
+
+	// Relationships
+	Account *Account `gorm:"foreignKey:AccountID;references:ID;constraint:OnDelete:CASCADE" json:"account,omitempty"`
+}
+
+// BeforeCreate hooks for setting UUIDs and timestamps
+func (s *WebPushSubscription) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	now := time.Now()
+	s.CreatedAt = now
+	s.UpdatedAt = now
+	return nil
+}
+
+// BeforeUpdate keeps UpdatedAt fresh
+func (s *WebPushSubscription) BeforeUpdate(tx *gorm.DB) error {
+	s.UpdatedAt = time.Now()
+	return nil
+}
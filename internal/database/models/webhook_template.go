@@ -0,0 +1,253 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+const (
+	// MaxWebhookTemplateLength bounds the size of a webhook template
+	MaxWebhookTemplateLength = 8 << 10
+	// MaxWebhookTemplateOutput bounds the size of a rendered webhook payload
+	MaxWebhookTemplateOutput = 64 << 10
+)
+
+// WebhookTemplateData is what a webhook template is rendered with. Times are in the
+// timezone of the account, except RemindAtUTC.
+type WebhookTemplateData struct {
+	ReminderID   string
+	AccountID    string
+	Message      string
+	RemindAtUTC  time.Time
+	RemindAt     time.Time
+	Timezone     string
+	Recurrence   string // recurrence label: ONCE, DAILY, WEEKLY... or RRULE
+	RRule        string
+	IsPaused     bool
+	IsSnoozed    bool
+	SnoozedUntil *time.Time
+	NextFire     *time.Time
+	FiredAt      time.Time
+}
+
+// SampleWebhookTemplateData returns the data templates are checked against
+func SampleWebhookTemplateData() WebhookTemplateData {
+	remindAt := time.Date(2030, time.January, 15, 9, 30, 0, 0, time.UTC)
+	snoozedUntil := remindAt.Add(10 * time.Minute)
+	return WebhookTemplateData{
+		ReminderID:   "00000000-0000-0000-0000-000000000000",
+		AccountID:    "00000000-0000-0000-0000-000000000000",
+		Message:      "Sample reminder with \"quotes\"",
+		RemindAtUTC:  remindAt,
+		RemindAt:     remindAt,
+		Timezone:     "UTC",
+		Recurrence:   "WEEKLY",
+		IsSnoozed:    true,
+		SnoozedUntil: &snoozedUntil,
+		NextFire:     &snoozedUntil,
+		FiredAt:      remindAt,
+	}
+}
+
+// bannedWebhookTemplateFuncs are the builtins that can build values of any size:
+// printf "%01000000d" allocates a megabyte from a few bytes of template
+var bannedWebhookTemplateFuncs = map[string]bool{
+	"print":   true,
+	"printf":  true,
+	"println": true,
+}
+
+// capWebhookTemplateValue fails the rendering when a function builds a value larger than
+// a payload. Nested calls can grow a value at every step (json escapes each backslash of
+// its argument), the limit keeps what a rendering holds in memory bounded.
+func capWebhookTemplateValue(value string) (string, error) {
+	if len(value) > MaxWebhookTemplateOutput {
+		return "", fmt.Errorf("template value exceeds %d bytes", MaxWebhookTemplateOutput)
+	}
+	return value, nil
+}
+
+// webhookTemplateFuncs are the functions available to webhook templates, besides the
+// text/template builtins. The escaping builtins are replaced by capped versions.
+var webhookTemplateFuncs = template.FuncMap{
+	// json encodes a value as a JSON literal, quotes included for strings
+	"json": func(value interface{}) (string, error) {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		return capWebhookTemplateValue(string(encoded))
+	},
+	"upper": func(value string) (string, error) {
+		return capWebhookTemplateValue(strings.ToUpper(value))
+	},
+	"lower": func(value string) (string, error) {
+		return capWebhookTemplateValue(strings.ToLower(value))
+	},
+	"trim": strings.TrimSpace,
+	// formatTime formats a time with a Go layout: {{formatTime "2006-01-02" .RemindAt}}
+	"formatTime": func(layout string, value interface{}) (string, error) {
+		switch t := value.(type) {
+		case time.Time:
+			return capWebhookTemplateValue(t.Format(layout))
+		case *time.Time:
+			if t == nil {
+				return "", nil
+			}
+			return capWebhookTemplateValue(t.Format(layout))
+		default:
+			return "", fmt.Errorf("formatTime expects a time, got %T", value)
+		}
+	},
+	"html": func(args ...interface{}) (string, error) {
+		return capWebhookTemplateValue(template.HTMLEscaper(args...))
+	},
+	"js": func(args ...interface{}) (string, error) {
+		return capWebhookTemplateValue(template.JSEscaper(args...))
+	},
+	"urlquery": func(args ...interface{}) (string, error) {
+		return capWebhookTemplateValue(template.URLQueryEscaper(args...))
+	},
+}
+
+// ParseWebhookTemplate parses a webhook payload template written with text/template.
+// Templates are sandboxed: range loops, nested templates, the print builtins and variable
+// reassignments are rejected, so rendering always ends quickly and within bounded memory.
+func ParseWebhookTemplate(text string) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.New("webhook template is empty")
+	}
+	if len(text) > MaxWebhookTemplateLength {
+		return nil, fmt.Errorf("webhook template exceeds %d bytes", MaxWebhookTemplateLength)
+	}
+
+	tmpl, err := template.New("webhook").Option("missingkey=error").Funcs(webhookTemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook template: %w", err)
+	}
+	if len(tmpl.Templates()) > 1 {
+		return nil, errors.New("invalid webhook template: define and block are not allowed")
+	}
+	if err := checkWebhookTemplateNode(tmpl.Tree.Root); err != nil {
+		return nil, err
+	}
+
+	return tmpl, nil
+}
+
+// checkWebhookTemplateNode rejects the actions that could make rendering unbounded
+func checkWebhookTemplateNode(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkWebhookTemplateNode(child); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return checkWebhookTemplatePipe(n.Pipe)
+	case *parse.RangeNode:
+		return errors.New("invalid webhook template: range is not allowed")
+	case *parse.TemplateNode:
+		return errors.New("invalid webhook template: template is not allowed")
+	case *parse.IfNode:
+		return checkWebhookTemplateBranch(&n.BranchNode)
+	case *parse.WithNode:
+		return checkWebhookTemplateBranch(&n.BranchNode)
+	}
+	return nil
+}
+
+// checkWebhookTemplatePipe rejects the banned functions and the variable reassignments,
+// which would let a value grow at every step of a template
+func checkWebhookTemplatePipe(pipe *parse.PipeNode) error {
+	if pipe == nil {
+		return nil
+	}
+	if pipe.IsAssign {
+		return errors.New("invalid webhook template: variables cannot be reassigned")
+	}
+	for _, cmd := range pipe.Cmds {
+		for _, arg := range cmd.Args {
+			switch a := arg.(type) {
+			case *parse.IdentifierNode:
+				if bannedWebhookTemplateFuncs[a.Ident] {
+					return fmt.Errorf("invalid webhook template: %s is not allowed", a.Ident)
+				}
+			case *parse.PipeNode:
+				if err := checkWebhookTemplatePipe(a); err != nil {
+					return err
+				}
+			case *parse.ChainNode:
+				if inner, ok := a.Node.(*parse.PipeNode); ok {
+					if err := checkWebhookTemplatePipe(inner); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func checkWebhookTemplateBranch(branch *parse.BranchNode) error {
+	if err := checkWebhookTemplatePipe(branch.Pipe); err != nil {
+		return err
+	}
+	if err := checkWebhookTemplateNode(branch.List); err != nil {
+		return err
+	}
+	return checkWebhookTemplateNode(branch.ElseList)
+}
+
+// limitedBuffer fails the rendering once the payload grows past its limit
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		return 0, fmt.Errorf("rendered payload exceeds %d bytes", b.limit)
+	}
+	return b.Buffer.Write(p)
+}
+
+// RenderWebhookTemplate renders a parsed template and checks that it produces a JSON object
+func RenderWebhookTemplate(tmpl *template.Template, data WebhookTemplateData) ([]byte, error) {
+	output := &limitedBuffer{limit: MaxWebhookTemplateOutput}
+	if err := tmpl.Execute(output, data); err != nil {
+		return nil, fmt.Errorf("failed to render webhook template: %w", err)
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(output.Bytes(), &object); err != nil {
+		return nil, fmt.Errorf("webhook template does not render a JSON object: %w", err)
+	}
+
+	return output.Bytes(), nil
+}
+
+// validateWebhookTemplate checks the template of a webhook destination against sample data
+func validateWebhookTemplate(value interface{}) error {
+	text, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("webhook template must be a string")
+	}
+
+	tmpl, err := ParseWebhookTemplate(text)
+	if err != nil {
+		return err
+	}
+	_, err = RenderWebhookTemplate(tmpl, SampleWebhookTemplateData())
+	return err
+}
@@ -56,6 +56,10 @@ func (r *accountRepository) UpdateTimezone(accountID uuid.UUID, timezoneID uint)
 	return r.db.Model(&models.Account{}).Where("id = ?", accountID).Update("timezone_id", timezoneID).Error
 }
 
+func (r *accountRepository) UpdateLocale(accountID uuid.UUID, locale string) error {
+	return r.db.Model(&models.Account{}).Where("id = ?", accountID).Update("locale", locale).Error
+}
+
 func (r *accountRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&models.Account{}, "id = ?", id).Error
 }
@@ -83,3 +87,16 @@ func (r *accountRepository) GetWithIdentities(id uuid.UUID) (*models.Account, er
 	}
 	return &account, nil
 }
+
+// GetByCalendarTokenHash looks up the account owning a calendar feed token
+func (r *accountRepository) GetByCalendarTokenHash(hash string) (*models.Account, error) {
+	var account models.Account
+	err := r.db.Preload("Timezone").First(&account, "calendar_token_hash = ?", hash).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &account, nil
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"errors"
+	"time"
 
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
 	"github.com/google/uuid"
@@ -72,3 +73,24 @@ func (r *identityRepository) GetByAccessToken(hashedToken string) (*models.Ident
 	}
 	return &identity, nil
 }
+
+func (r *identityRepository) GetByPreviousAccessToken(hashedToken string) (*models.Identity, error) {
+	var identity models.Identity
+	err := r.db.Where("previous_access_token = ?", hashedToken).First(&identity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// UpdateLastUsed records when and from where an API key was last used, without
+// touching the other columns
+func (r *identityRepository) UpdateLastUsed(id uuid.UUID, usedAt time.Time, ip string) error {
+	return r.db.Model(&models.Identity{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_used_at": usedAt,
+		"last_used_ip": ip,
+	}).Error
+}
@@ -23,9 +23,11 @@ type AccountRepository interface {
 	GetByEmail(email string) (*models.Account, error)
 	Update(account *models.Account) error
 	UpdateTimezone(accountID uuid.UUID, timezoneID uint) error
+	UpdateLocale(accountID uuid.UUID, locale string) error
 	Delete(id uuid.UUID) error
 	GetWithTimezone(id uuid.UUID) (*models.Account, error)
 	GetWithIdentities(id uuid.UUID) (*models.Account, error)
+	GetByCalendarTokenHash(hash string) (*models.Account, error)
 }
 
 // IdentityRepository defines the interface for identity database operations
@@ -37,6 +39,8 @@ type IdentityRepository interface {
 	Update(identity *models.Identity) error
 	Delete(id uuid.UUID) error
 	GetByAccessToken(hashedToken string) (*models.Identity, error)
+	GetByPreviousAccessToken(hashedToken string) (*models.Identity, error)
+	UpdateLastUsed(id uuid.UUID, usedAt time.Time, ip string) error
 }
 
 // ReminderRepository interface defines operations for reminder data
@@ -56,6 +60,7 @@ type ReminderRepository interface {
 	RescheduleReminder(reminder *models.Reminder, newTime time.Time, notify bool) error
 	Snooze(id uuid.UUID, snoozeUntil time.Time) error
 	SnoozeReminder(reminder *models.Reminder, snoozeUntil time.Time) error
+	NotifyCreated(reminderID uuid.UUID)
 }
 
 // ReminderDestinationRepository interface defines operations for reminder destination data
@@ -86,6 +91,23 @@ type ReminderErrorRepository interface {
 	MarkMultipleAsFixed(ids []uuid.UUID) error
 }
 
+// ReminderDeliveryRepository interface defines operations for reminder delivery history
+type ReminderDeliveryRepository interface {
+	Create(delivery *models.ReminderDelivery) error
+	GetByID(id uuid.UUID) (*models.ReminderDelivery, error)
+	GetByAccountAndReminder(accountID, reminderID uuid.UUID, limit int) ([]models.ReminderDelivery, error)
+	GetByAccountID(accountID uuid.UUID, limit int) ([]models.ReminderDelivery, error)
+	DeleteOlderThan(before time.Time) error
+}
+
+// ReminderDispatchRepository interface defines operations for the dispatch outbox
+type ReminderDispatchRepository interface {
+	CreatePending(dispatches []models.ReminderDispatch) ([]models.ReminderDispatch, error)
+	UpdateStatus(idempotencyKey string, destinationID uuid.UUID, status models.DispatchStatus, attempts int) error
+	GetPending() ([]models.ReminderDispatch, error)
+	DeleteOlderThan(before time.Time) error
+}
+
 // DFMNoteRepository interface defines operations for "Don't Forget Me" notes
 type DFMNoteRepository interface {
 	GetOrCreateByAccountID(accountID uuid.UUID) (*models.DFMNote, error)
@@ -129,6 +151,32 @@ type FcmTokenRepository interface {
 	DeleteByAccountAndDevice(accountID uuid.UUID, deviceID string) error
 }
 
+// WebPushSubscriptionRepository interface defines operations for browser push subscriptions
+type WebPushSubscriptionRepository interface {
+	// Upsert registers a subscription or refreshes the keys of a known endpoint.
+	Upsert(subscription *models.WebPushSubscription) error
+	GetByAccountID(accountID uuid.UUID) ([]models.WebPushSubscription, error)
+	DeleteByEndpoint(endpoint string) error
+	DeleteByAccountAndEndpoint(accountID uuid.UUID, endpoint string) error
+}
+
+// PhoneNumberRepository interface defines operations for the phone numbers of SMS and voice destinations
+type PhoneNumberRepository interface {
+	Create(phoneNumber *models.PhoneNumber) error
+	GetByAccountAndNumber(accountID uuid.UUID, number string) (*models.PhoneNumber, error)
+	GetByAccountID(accountID uuid.UUID) ([]models.PhoneNumber, error)
+	Update(phoneNumber *models.PhoneNumber) error
+	Delete(id uuid.UUID) error
+}
+
+// TelephonyUsageRepository interface defines operations for the monthly SMS and voice usage
+type TelephonyUsageRepository interface {
+	GetByAccountAndMonth(accountID uuid.UUID, month string) (*models.TelephonyUsage, error)
+	// Reserve counts one send unless the limit is reached, in which case it returns false.
+	Reserve(accountID uuid.UUID, month string, channel models.DestinationType, limit int) (bool, error)
+	Release(accountID uuid.UUID, month string, channel models.DestinationType) error
+}
+
 // PasswordResetRepository interface defines operations for password reset data
 type PasswordResetRepository interface {
 	Create(reset *models.PasswordReset) error
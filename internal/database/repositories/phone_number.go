@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PhoneNumberRepositoryImpl implements PhoneNumberRepository
+type PhoneNumberRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewPhoneNumberRepository creates a new phone number repository
+func NewPhoneNumberRepository(db *gorm.DB) PhoneNumberRepository {
+	return &PhoneNumberRepositoryImpl{db: db}
+}
+
+// Create creates a new phone number
+func (r *PhoneNumberRepositoryImpl) Create(phoneNumber *models.PhoneNumber) error {
+	if phoneNumber == nil {
+		return fmt.Errorf("phone number cannot be nil")
+	}
+
+	if err := r.db.Create(phoneNumber).Error; err != nil {
+		log.Printf("[DATABASE] - ❌ Error creating phone number: %v", err)
+		return fmt.Errorf("failed to create phone number: %w", err)
+	}
+	return nil
+}
+
+// GetByAccountAndNumber retrieves the phone number of an account, nil when not registered
+func (r *PhoneNumberRepositoryImpl) GetByAccountAndNumber(accountID uuid.UUID, number string) (*models.PhoneNumber, error) {
+	var phoneNumber models.PhoneNumber
+	if err := r.db.Where("account_id = ? AND number = ?", accountID, number).First(&phoneNumber).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		log.Printf("[DATABASE] - ❌ Error getting phone number: %v", err)
+		return nil, err
+	}
+	return &phoneNumber, nil
+}
+
+// GetByAccountID returns the phone numbers of an account, oldest first
+func (r *PhoneNumberRepositoryImpl) GetByAccountID(accountID uuid.UUID) ([]models.PhoneNumber, error) {
+	var phoneNumbers []models.PhoneNumber
+	if err := r.db.Where("account_id = ?", accountID).Order("created_at ASC").Find(&phoneNumbers).Error; err != nil {
+		log.Printf("[DATABASE] - ❌ Error getting phone numbers by account ID: %v", err)
+		return nil, err
+	}
+	return phoneNumbers, nil
+}
+
+// Update saves a phone number and its pending verification
+func (r *PhoneNumberRepositoryImpl) Update(phoneNumber *models.PhoneNumber) error {
+	if err := r.db.Save(phoneNumber).Error; err != nil {
+		log.Printf("[DATABASE] - ❌ Error updating phone number: %v", err)
+		return fmt.Errorf("failed to update phone number: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes a phone number by ID
+func (r *PhoneNumberRepositoryImpl) Delete(id uuid.UUID) error {
+	if err := r.db.Where("id = ?", id).Delete(&models.PhoneNumber{}).Error; err != nil {
+		log.Printf("[DATABASE] - ❌ Error deleting phone number: %v", err)
+		return fmt.Errorf("failed to delete phone number: %w", err)
+	}
+	return nil
+}
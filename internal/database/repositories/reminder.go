@@ -1,11 +1,15 @@
 package repositories
 
 import (
+	"context"
 	"errors"
 	"time"
 
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/tracing"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 )
 
@@ -16,6 +20,25 @@ type SchedulerNotifier interface {
 	NotifyReminderDeleted(reminderID uuid.UUID)
 }
 
+// ContextSchedulerNotifier is implemented by the scheduler notifiers that carry the
+// trace of the change along with the notification
+type ContextSchedulerNotifier interface {
+	NotifyReminderCreatedContext(ctx context.Context, reminderID uuid.UUID)
+	NotifyReminderUpdatedContext(ctx context.Context, reminderID uuid.UUID)
+	NotifyReminderDeletedContext(ctx context.Context, reminderID uuid.UUID)
+}
+
+// ReminderRepositoryWithContext returns the repository bound to the context of a
+// request when it supports it, so its queries and notifications join the request trace
+func ReminderRepositoryWithContext(ctx context.Context, repo ReminderRepository) ReminderRepository {
+	if binder, ok := repo.(interface {
+		WithContext(ctx context.Context) ReminderRepository
+	}); ok {
+		return binder.WithContext(ctx)
+	}
+	return repo
+}
+
 // GarbageCollectorNotifier interface for notifying the garbage collector
 type GarbageCollectorNotifier interface {
 	NotifyReminderUpdated(reminderID uuid.UUID)
@@ -24,13 +47,50 @@ type GarbageCollectorNotifier interface {
 // reminderRepository implementation
 type reminderRepository struct {
 	db               *gorm.DB
+	ctx              context.Context
 	scheduler        SchedulerNotifier
 	garbageCollector GarbageCollectorNotifier
 }
 
 // NewReminderRepository creates a new reminder repository instance
 func NewReminderRepository(db *gorm.DB) ReminderRepository {
-	return &reminderRepository{db: db}
+	return &reminderRepository{db: db, ctx: context.Background()}
+}
+
+// WithContext returns a copy of the repository running its queries and notifications
+// in ctx. The notifiers must be set before, the copy does not see later changes.
+func (r *reminderRepository) WithContext(ctx context.Context) ReminderRepository {
+	bound := *r
+	bound.db = r.db.WithContext(ctx)
+	bound.ctx = ctx
+	return &bound
+}
+
+// startSpan starts the span of a write on a reminder
+func (r *reminderRepository) startSpan(name string, reminderID uuid.UUID) (context.Context, trace.Span) {
+	return tracing.Start(r.ctx, name, trace.WithAttributes(attribute.String("reminder_id", reminderID.String())))
+}
+
+// notifyScheduler notifies the scheduler of a change, within ctx when the notifier supports it
+func (r *reminderRepository) notifyScheduler(ctx context.Context, event string, reminderID uuid.UUID) {
+	if r.scheduler == nil {
+		return
+	}
+	contextNotifier, withContext := r.scheduler.(ContextSchedulerNotifier)
+	switch {
+	case event == "created" && withContext:
+		contextNotifier.NotifyReminderCreatedContext(ctx, reminderID)
+	case event == "created":
+		r.scheduler.NotifyReminderCreated(reminderID)
+	case event == "updated" && withContext:
+		contextNotifier.NotifyReminderUpdatedContext(ctx, reminderID)
+	case event == "updated":
+		r.scheduler.NotifyReminderUpdated(reminderID)
+	case event == "deleted" && withContext:
+		contextNotifier.NotifyReminderDeletedContext(ctx, reminderID)
+	case event == "deleted":
+		r.scheduler.NotifyReminderDeleted(reminderID)
+	}
 }
 
 // SetScheduler sets the scheduler notifier for the repository
@@ -45,15 +105,27 @@ func (r *reminderRepository) SetGarbageCollector(gc GarbageCollectorNotifier) {
 
 // Reminder Repository Implementation
 func (r *reminderRepository) Create(reminder *models.Reminder, notify bool) error {
-	err := r.db.Create(reminder).Error
+	// The ID is usually set by the BeforeCreate hook, it is needed earlier for the span
+	if reminder.ID == uuid.Nil {
+		reminder.ID = uuid.New()
+	}
+	ctx, span := r.startSpan("reminderRepo.Create", reminder.ID)
+	err := r.db.WithContext(ctx).Create(reminder).Error
 	if err == nil && notify {
-		if r.scheduler != nil {
-			r.scheduler.NotifyReminderCreated(reminder.ID)
-		}
+		r.notifyScheduler(ctx, "created", reminder.ID)
 	}
+	tracing.End(span, err)
 	return err
 }
 
+// NotifyCreated wakes the scheduler for a reminder inserted outside of Create, in a
+// transaction of the caller once it is committed
+func (r *reminderRepository) NotifyCreated(reminderID uuid.UUID) {
+	if r.scheduler != nil {
+		r.scheduler.NotifyReminderCreated(reminderID)
+	}
+}
+
 func (r *reminderRepository) GetByID(id uuid.UUID) (*models.Reminder, error) {
 	var reminder models.Reminder
 	err := r.db.First(&reminder, "id = ?", id).Error
@@ -115,22 +187,22 @@ func (r *reminderRepository) GetWithAccountAndDestinations(id uuid.UUID) (*model
 }
 
 func (r *reminderRepository) Update(reminder *models.Reminder, notify bool) error {
-	err := r.db.Save(reminder).Error
+	ctx, span := r.startSpan("reminderRepo.Update", reminder.ID)
+	err := r.db.WithContext(ctx).Save(reminder).Error
 	if err == nil && notify {
-		if r.scheduler != nil {
-			r.scheduler.NotifyReminderUpdated(reminder.ID)
-		}
+		r.notifyScheduler(ctx, "updated", reminder.ID)
 	}
+	tracing.End(span, err)
 	return err
 }
 
 func (r *reminderRepository) Delete(id uuid.UUID, notify bool) error {
-	err := r.db.Delete(&models.Reminder{}, "id = ?", id).Error
-	if err == nil {
-		if r.scheduler != nil && notify {
-			r.scheduler.NotifyReminderDeleted(id)
-		}
+	ctx, span := r.startSpan("reminderRepo.Delete", id)
+	err := r.db.WithContext(ctx).Delete(&models.Reminder{}, "id = ?", id).Error
+	if err == nil && notify {
+		r.notifyScheduler(ctx, "deleted", id)
 	}
+	tracing.End(span, err)
 	return err
 }
 
@@ -149,6 +221,7 @@ func (r *reminderRepository) GetNextReminders() ([]models.Reminder, error) {
 
 	// Find the next reminder(s) to process in a single query
 	// Priority: past due reminders first (including snoozed), then earliest future reminders
+	// Reminders are skipped only once every destination has an unfixed error
 	var reminders []models.Reminder
 	now := time.Now().UTC()
 	pauseBit := 128 // PauseBit
@@ -169,10 +242,15 @@ func (r *reminderRepository) GetNextReminders() ([]models.Reminder, error) {
 				)
 			)
 			AND (recurrence & ?) = 0
-			AND id NOT IN (
-				SELECT reminder_id
-				FROM reminder_errors
-				WHERE fixed = false
+			AND EXISTS (
+				SELECT 1
+				FROM reminder_destinations d
+				WHERE d.reminder_id = reminders.id
+				AND d.id NOT IN (
+					SELECT reminder_destination_id
+					FROM reminder_errors
+					WHERE fixed = false
+				)
 			)
 		`, now, now, pauseBit, pauseBit).
 		Order("next_fire_utc ASC").
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// reminderDeliveryRepository implementation
+type reminderDeliveryRepository struct {
+	db *gorm.DB
+}
+
+// NewReminderDeliveryRepository creates a new reminder delivery repository instance
+func NewReminderDeliveryRepository(db *gorm.DB) ReminderDeliveryRepository {
+	return &reminderDeliveryRepository{db: db}
+}
+
+// Create records a new delivery attempt
+func (r *reminderDeliveryRepository) Create(delivery *models.ReminderDelivery) error {
+	return r.db.Create(delivery).Error
+}
+
+// GetByID retrieves a delivery by ID
+func (r *reminderDeliveryRepository) GetByID(id uuid.UUID) (*models.ReminderDelivery, error) {
+	var delivery models.ReminderDelivery
+	err := r.db.First(&delivery, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// GetByAccountAndReminder retrieves the most recent deliveries of a reminder of an account, newest first.
+// A limit <= 0 returns every delivery.
+func (r *reminderDeliveryRepository) GetByAccountAndReminder(accountID, reminderID uuid.UUID, limit int) ([]models.ReminderDelivery, error) {
+	var deliveries []models.ReminderDelivery
+	query := r.db.Where("account_id = ? AND reminder_id = ?", accountID, reminderID).Order("sent_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&deliveries).Error
+	return deliveries, err
+}
+
+// GetByAccountID retrieves the most recent deliveries of every reminder of an account, newest first.
+// A limit <= 0 returns every delivery.
+func (r *reminderDeliveryRepository) GetByAccountID(accountID uuid.UUID, limit int) ([]models.ReminderDelivery, error) {
+	var deliveries []models.ReminderDelivery
+	query := r.db.Where("account_id = ?", accountID).Order("sent_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&deliveries).Error
+	return deliveries, err
+}
+
+// DeleteOlderThan removes every delivery sent before the given time
+func (r *reminderDeliveryRepository) DeleteOlderThan(before time.Time) error {
+	return r.db.Where("sent_at < ?", before).Delete(&models.ReminderDelivery{}).Error
+}
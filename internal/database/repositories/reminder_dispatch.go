@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// reminderDispatchRepository implementation
+type reminderDispatchRepository struct {
+	db *gorm.DB
+}
+
+// NewReminderDispatchRepository creates a new reminder dispatch (outbox) repository instance
+func NewReminderDispatchRepository(db *gorm.DB) ReminderDispatchRepository {
+	return &reminderDispatchRepository{db: db}
+}
+
+// CreatePending writes the outbox entries of a fire in one transaction. Entries already
+// written for the same key and destination are kept as they are, so the returned
+// entries tell which destinations a previous run already handled.
+func (r *reminderDispatchRepository) CreatePending(dispatches []models.ReminderDispatch) ([]models.ReminderDispatch, error) {
+	if len(dispatches) == 0 {
+		return nil, nil
+	}
+
+	var stored []models.ReminderDispatch
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&dispatches).Error; err != nil {
+			return err
+		}
+
+		destinationIDs := make([]uuid.UUID, len(dispatches))
+		for i := range dispatches {
+			destinationIDs[i] = dispatches[i].ReminderDestinationID
+		}
+		return tx.Where("idempotency_key = ? AND reminder_destination_id IN ?", dispatches[0].IdempotencyKey, destinationIDs).
+			Find(&stored).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to write dispatch outbox: %w", err)
+	}
+	return stored, nil
+}
+
+// UpdateStatus records the outcome of an attempt. Sent and failed entries are completed.
+func (r *reminderDispatchRepository) UpdateStatus(idempotencyKey string, destinationID uuid.UUID, status models.DispatchStatus, attempts int) error {
+	updates := map[string]interface{}{
+		"status":   status,
+		"attempts": attempts,
+	}
+	if status != models.DispatchStatusPending {
+		updates["completed_at"] = time.Now()
+	}
+
+	return r.db.Model(&models.ReminderDispatch{}).
+		Where("idempotency_key = ? AND reminder_destination_id = ?", idempotencyKey, destinationID).
+		Updates(updates).Error
+}
+
+// GetPending retrieves the entries whose outcome is unknown, oldest first
+func (r *reminderDispatchRepository) GetPending() ([]models.ReminderDispatch, error) {
+	var dispatches []models.ReminderDispatch
+	err := r.db.Where("status = ?", models.DispatchStatusPending).Order("created_at ASC").Find(&dispatches).Error
+	return dispatches, err
+}
+
+// DeleteOlderThan removes every entry written before the given time
+func (r *reminderDispatchRepository) DeleteOlderThan(before time.Time) error {
+	return r.db.Where("created_at < ?", before).Delete(&models.ReminderDispatch{}).Error
+}
@@ -10,11 +10,16 @@ type Repositories struct {
 	Reminder            ReminderRepository
 	ReminderDestination ReminderDestinationRepository
 	ReminderError       ReminderErrorRepository
+	ReminderDelivery    ReminderDeliveryRepository
+	ReminderDispatch    ReminderDispatchRepository
 	EmailVerification   EmailVerificationRepository
 	PasswordReset       PasswordResetRepository
 	DFMNote             DFMNoteRepository
 	DFMItem             DFMItemRepository
 	FcmToken            FcmTokenRepository
+	PhoneNumber         PhoneNumberRepository
+	TelephonyUsage      TelephonyUsageRepository
+	WebPushSubscription WebPushSubscriptionRepository
 }
 
 // NewRepositories creates new repository instances
@@ -26,10 +31,15 @@ func NewRepositories(db *gorm.DB) *Repositories {
 		Reminder:            NewReminderRepository(db),
 		ReminderDestination: NewReminderDestinationRepository(db),
 		ReminderError:       NewReminderErrorRepository(db),
+		ReminderDelivery:    NewReminderDeliveryRepository(db),
+		ReminderDispatch:    NewReminderDispatchRepository(db),
 		EmailVerification:   NewEmailVerificationRepository(db),
 		PasswordReset:       NewPasswordResetRepository(db),
 		DFMNote:             NewDFMNoteRepository(db),
 		DFMItem:             NewDFMItemRepository(db),
 		FcmToken:            NewFcmTokenRepository(db),
+		PhoneNumber:         NewPhoneNumberRepository(db),
+		TelephonyUsage:      NewTelephonyUsageRepository(db),
+		WebPushSubscription: NewWebPushSubscriptionRepository(db),
 	}
 }
@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TelephonyUsageRepositoryImpl implements TelephonyUsageRepository
+type TelephonyUsageRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewTelephonyUsageRepository creates a new telephony usage repository
+func NewTelephonyUsageRepository(db *gorm.DB) TelephonyUsageRepository {
+	return &TelephonyUsageRepositoryImpl{db: db}
+}
+
+// usageColumn returns the counter column of a telephony destination type
+func usageColumn(channel models.DestinationType) (string, error) {
+	switch channel {
+	case models.DestinationSMS:
+		return "sms_count", nil
+	case models.DestinationVoice:
+		return "voice_count", nil
+	}
+	return "", fmt.Errorf("no telephony usage for destination type: %s", channel)
+}
+
+// GetByAccountAndMonth returns the usage of an account for a month, nil when nothing was sent
+func (r *TelephonyUsageRepositoryImpl) GetByAccountAndMonth(accountID uuid.UUID, month string) (*models.TelephonyUsage, error) {
+	var usage models.TelephonyUsage
+	if err := r.db.Where("account_id = ? AND month = ?", accountID, month).First(&usage).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		log.Printf("[DATABASE] - ❌ Error getting telephony usage: %v", err)
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// Reserve counts one send against the monthly limit. It returns false, without
+// counting it, when the limit is already reached. The check and the increment are
+// a single statement so concurrent sends cannot exceed the limit.
+func (r *TelephonyUsageRepositoryImpl) Reserve(accountID uuid.UUID, month string, channel models.DestinationType, limit int) (bool, error) {
+	column, err := usageColumn(channel)
+	if err != nil {
+		return false, err
+	}
+
+	reserved := false
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		usage := &models.TelephonyUsage{AccountID: accountID, Month: month, UpdatedAt: time.Now()}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(usage).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&models.TelephonyUsage{}).
+			Where("account_id = ? AND month = ? AND "+column+" < ?", accountID, month, limit).
+			Updates(map[string]interface{}{column: gorm.Expr(column + " + 1"), "updated_at": time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+		reserved = result.RowsAffected == 1
+		return nil
+	})
+	if err != nil {
+		log.Printf("[DATABASE] - ❌ Error reserving telephony usage: %v", err)
+		return false, fmt.Errorf("failed to reserve telephony usage: %w", err)
+	}
+	return reserved, nil
+}
+
+// Release gives back a send reserved with Reserve that could not be delivered
+func (r *TelephonyUsageRepositoryImpl) Release(accountID uuid.UUID, month string, channel models.DestinationType) error {
+	column, err := usageColumn(channel)
+	if err != nil {
+		return err
+	}
+
+	if err := r.db.Model(&models.TelephonyUsage{}).
+		Where("account_id = ? AND month = ? AND "+column+" > 0", accountID, month).
+		Updates(map[string]interface{}{column: gorm.Expr(column + " - 1"), "updated_at": time.Now()}).Error; err != nil {
+		log.Printf("[DATABASE] - ❌ Error releasing telephony usage: %v", err)
+		return fmt.Errorf("failed to release telephony usage: %w", err)
+	}
+	return nil
+}
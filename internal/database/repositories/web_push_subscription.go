@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WebPushSubscriptionRepositoryImpl implements WebPushSubscriptionRepository
+type WebPushSubscriptionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewWebPushSubscriptionRepository creates a new web push subscription repository
+func NewWebPushSubscriptionRepository(db *gorm.DB) WebPushSubscriptionRepository {
+	return &WebPushSubscriptionRepositoryImpl{db: db}
+}
+
+// Upsert registers a subscription. The endpoint identifies the browser, so a
+// known endpoint is moved to the given account with its new keys; browsers
+// rotate keys when they resubscribe and a shared computer may change user.
+func (r *WebPushSubscriptionRepositoryImpl) Upsert(subscription *models.WebPushSubscription) error {
+	if subscription == nil {
+		return fmt.Errorf("subscription cannot be nil")
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.WebPushSubscription
+		err := tx.Where("endpoint = ?", subscription.Endpoint).First(&existing).Error
+
+		switch {
+		case err == gorm.ErrRecordNotFound:
+			return tx.Create(subscription).Error
+		case err != nil:
+			return err
+		default:
+			subscription.ID = existing.ID
+			subscription.CreatedAt = existing.CreatedAt
+			return tx.Model(&existing).Updates(map[string]interface{}{
+				"account_id": subscription.AccountID,
+				"p256dh":     subscription.P256dh,
+				"auth":       subscription.Auth,
+				"user_agent": subscription.UserAgent,
+			}).Error
+		}
+	})
+}
+
+// GetByAccountID returns all browser subscriptions of an account
+func (r *WebPushSubscriptionRepositoryImpl) GetByAccountID(accountID uuid.UUID) ([]models.WebPushSubscription, error) {
+	var subscriptions []models.WebPushSubscription
+	if err := r.db.Where("account_id = ?", accountID).Find(&subscriptions).Error; err != nil {
+		log.Printf("[DATABASE] - ❌ Error getting web push subscriptions by account ID: %v", err)
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// DeleteByEndpoint removes a subscription by its endpoint (used for expired subscriptions)
+func (r *WebPushSubscriptionRepositoryImpl) DeleteByEndpoint(endpoint string) error {
+	if err := r.db.Where("endpoint = ?", endpoint).Delete(&models.WebPushSubscription{}).Error; err != nil {
+		log.Printf("[DATABASE] - ❌ Error deleting web push subscription: %v", err)
+		return fmt.Errorf("failed to delete web push subscription: %w", err)
+	}
+	return nil
+}
+
+// DeleteByAccountAndEndpoint removes a subscription of the account (browser unsubscribed)
+func (r *WebPushSubscriptionRepositoryImpl) DeleteByAccountAndEndpoint(accountID uuid.UUID, endpoint string) error {
+	if err := r.db.Where("account_id = ? AND endpoint = ?", accountID, endpoint).
+		Delete(&models.WebPushSubscription{}).Error; err != nil {
+		log.Printf("[DATABASE] - ❌ Error deleting web push subscription by account: %v", err)
+		return fmt.Errorf("failed to delete web push subscription: %w", err)
+	}
+	return nil
+}
@@ -4,10 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"strings"
 
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
 	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 	"github.com/google/uuid"
 )
@@ -34,60 +35,71 @@ func (d *AndroidPushDispatcher) GetSupportedType() models.DestinationType {
 // Dispatch sends the reminder as a push notification to every registered token
 // for the account in the destination metadata. Tokens that FCM reports as
 // unregistered are pruned from the database.
-func (d *AndroidPushDispatcher) Dispatch(reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) error {
+func (d *AndroidPushDispatcher) Dispatch(ctx context.Context, reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) (*models.DeliveryReceipt, error) {
 	if destination.Type != models.DestinationAndroidPush {
-		return fmt.Errorf("invalid destination type for android push dispatcher: %s", destination.Type)
+		return nil, fmt.Errorf("invalid destination type for android push dispatcher: %s", destination.Type)
 	}
 
 	if d.fcmService == nil || !d.fcmService.IsEnabled() {
-		return fmt.Errorf("push notifications are not configured")
+		return nil, fmt.Errorf("push notifications are not configured")
 	}
 
 	accountIDVal, exists := destination.Metadata["account_id"]
 	if !exists {
-		return fmt.Errorf("account_id not found in destination metadata")
+		return nil, fmt.Errorf("account_id not found in destination metadata")
 	}
 	accountIDStr, ok := accountIDVal.(string)
 	if !ok || accountIDStr == "" {
-		return fmt.Errorf("account_id in destination metadata is not a valid string")
+		return nil, fmt.Errorf("account_id in destination metadata is not a valid string")
 	}
 	accountID, err := uuid.Parse(accountIDStr)
 	if err != nil {
-		return fmt.Errorf("account_id in destination metadata is not a valid UUID: %w", err)
+		return nil, fmt.Errorf("account_id in destination metadata is not a valid UUID: %w", err)
 	}
 
 	tokens, err := d.fcmTokenRepo.GetByAccountID(accountID)
 	if err != nil {
-		return fmt.Errorf("failed to load FCM tokens: %w", err)
+		return nil, fmt.Errorf("failed to load FCM tokens: %w", err)
 	}
 	if len(tokens) == 0 {
-		return fmt.Errorf("no FCM tokens registered for account %s", accountID)
+		return nil, fmt.Errorf("no FCM tokens registered for account %s", accountID)
 	}
 
-	ctx := context.Background()
 	data := map[string]string{"reminder_id": reminder.ID.String()}
 
 	var sendErrors []error
+	var messageIDs []string
+	unavailable := 0
 	for _, token := range tokens {
-		err := d.fcmService.Send(ctx, token.Token, "Chronos", reminder.Message, data)
+		messageID, err := d.fcmService.Send(ctx, token.Token, "Chronos", reminder.Message, data)
 		if err == nil {
+			messageIDs = append(messageIDs, messageID)
 			continue
 		}
 
 		if errors.Is(err, services.ErrTokenUnregistered) {
 			// Stale token — remove it so we stop trying to reach a dead device.
 			if delErr := d.fcmTokenRepo.DeleteByToken(token.Token); delErr != nil {
-				log.Printf("[ANDROID_PUSH] - Failed to delete unregistered token: %v", delErr)
+				logging.For(logging.Dispatcher).Warn("Failed to delete unregistered android push token", "reminder_id", reminder.ID, "error", delErr)
 			}
 			continue
 		}
 
+		if errors.Is(err, services.ErrFcmUnavailable) {
+			unavailable++
+		}
 		sendErrors = append(sendErrors, err)
 	}
 
 	if len(sendErrors) > 0 {
-		return fmt.Errorf("failed to deliver push to %d/%d devices: %v", len(sendErrors), len(tokens), sendErrors[0])
+		err := fmt.Errorf("failed to deliver push to %d/%d devices: %w", len(sendErrors), len(tokens), sendErrors[0])
+		// Only retry when FCM itself was the problem and no device got the push yet,
+		// otherwise the retry would notify the reached devices twice
+		if unavailable == len(sendErrors) && len(messageIDs) == 0 {
+			return nil, Transient(err)
+		}
+		return nil, err
 	}
 
-	return nil
+	return &models.DeliveryReceipt{MessageID: strings.Join(messageIDs, ",")}, nil
 }
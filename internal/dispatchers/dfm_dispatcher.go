@@ -9,6 +9,7 @@ import (
 	"github.com/bwmarrin/discordgo"
 	"github.com/ericp/chronos-bot-reminder/internal/bot"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 )
 
@@ -38,8 +39,8 @@ func (d *DFMDispatcher) NoteWebURL() string {
 // Dispatch sends the note to its owner on every enabled private channel
 // (Discord DM and/or email). discordUserID and email may be empty string if
 // the respective channel is not available. A failure on one channel does not
-// prevent the other from being attempted.
-func (d *DFMDispatcher) Dispatch(note *models.DFMNote, discordUserID string, email string) error {
+// prevent the other from being attempted. The note is rendered in locale.
+func (d *DFMDispatcher) Dispatch(note *models.DFMNote, discordUserID string, email string, locale string) error {
 	if !note.SendDiscordDM && !note.SendEmail {
 		return fmt.Errorf("no destination enabled for DFM note %s", note.ID)
 	}
@@ -49,7 +50,7 @@ func (d *DFMDispatcher) Dispatch(note *models.DFMNote, discordUserID string, ema
 	if note.SendDiscordDM {
 		if discordUserID == "" || d.session == nil {
 			errs = append(errs, fmt.Errorf("no Discord identity linked for DFM note %s", note.ID))
-		} else if err := d.dispatchDiscordDM(note, discordUserID); err != nil {
+		} else if err := d.dispatchDiscordDM(note, discordUserID, locale); err != nil {
 			errs = append(errs, err)
 		}
 	}
@@ -57,7 +58,7 @@ func (d *DFMDispatcher) Dispatch(note *models.DFMNote, discordUserID string, ema
 	if note.SendEmail {
 		if email == "" {
 			errs = append(errs, fmt.Errorf("no email linked for DFM note %s", note.ID))
-		} else if err := d.dispatchEmail(note, email); err != nil {
+		} else if err := d.dispatchEmail(note, email, locale); err != nil {
 			errs = append(errs, err)
 		}
 	}
@@ -66,9 +67,9 @@ func (d *DFMDispatcher) Dispatch(note *models.DFMNote, discordUserID string, ema
 }
 
 // RenderDFMNoteText renders the note items as a plain text checklist
-func RenderDFMNoteText(note *models.DFMNote) string {
+func RenderDFMNoteText(note *models.DFMNote, locale string) string {
 	if len(note.Items) == 0 {
-		return "Your note is empty."
+		return i18n.T(locale, "dfm.note_empty")
 	}
 
 	var builder strings.Builder
@@ -83,7 +84,7 @@ func RenderDFMNoteText(note *models.DFMNote) string {
 }
 
 // dispatchDiscordDM sends the note content as a private Discord message
-func (d *DFMDispatcher) dispatchDiscordDM(note *models.DFMNote, discordUserID string) error {
+func (d *DFMDispatcher) dispatchDiscordDM(note *models.DFMNote, discordUserID string, locale string) error {
 	dmChannel, err := d.session.UserChannelCreate(discordUserID)
 	if err != nil {
 		return fmt.Errorf("failed to create DM channel with user %s: %w", discordUserID, err)
@@ -91,7 +92,7 @@ func (d *DFMDispatcher) dispatchDiscordDM(note *models.DFMNote, discordUserID st
 
 	var description strings.Builder
 	if len(note.Items) == 0 {
-		description.WriteString("Your note is empty.")
+		description.WriteString(i18n.T(locale, "dfm.note_empty"))
 	} else {
 		for _, item := range note.Items {
 			if item.Checked {
@@ -101,10 +102,10 @@ func (d *DFMDispatcher) dispatchDiscordDM(note *models.DFMNote, discordUserID st
 			}
 		}
 	}
-	description.WriteString("\nYou can edit your note, check items and manage the reminder from the web application.")
+	description.WriteString("\n" + i18n.T(locale, "dfm.delivery.edit_hint"))
 
 	embed := &discordgo.MessageEmbed{
-		Title:       "💭 Don't Forget Me - Your note",
+		Title:       "💭 " + i18n.T(locale, "dfm.note_title"),
 		Description: description.String(),
 		Color:       0xCEA04D,
 		Footer: &discordgo.MessageEmbedFooter{
@@ -118,7 +119,7 @@ func (d *DFMDispatcher) dispatchDiscordDM(note *models.DFMNote, discordUserID st
 			discordgo.ActionsRow{
 				Components: []discordgo.MessageComponent{
 					discordgo.Button{
-						Label: "Open in the web app",
+						Label: i18n.T(locale, "dfm.open_web_app"),
 						Style: discordgo.LinkButton,
 						URL:   d.NoteWebURL(),
 					},
@@ -134,10 +135,10 @@ func (d *DFMDispatcher) dispatchDiscordDM(note *models.DFMNote, discordUserID st
 }
 
 // dispatchEmail sends the note content to the user's email address
-func (d *DFMDispatcher) dispatchEmail(note *models.DFMNote, email string) error {
+func (d *DFMDispatcher) dispatchEmail(note *models.DFMNote, email string, locale string) error {
 	var itemsHTML strings.Builder
 	if len(note.Items) == 0 {
-		itemsHTML.WriteString("<p>Your note is empty.</p>")
+		itemsHTML.WriteString("<p>" + i18n.T(locale, "dfm.note_empty") + "</p>")
 	} else {
 		itemsHTML.WriteString("<ul style=\"list-style: none; padding-left: 0;\">")
 		for _, item := range note.Items {
@@ -151,33 +152,35 @@ func (d *DFMDispatcher) dispatchEmail(note *models.DFMNote, email string) error
 		itemsHTML.WriteString("</ul>")
 	}
 
+	title := i18n.T(locale, "dfm.note_title")
 	htmlBody := fmt.Sprintf(`
 <!DOCTYPE html>
-<html>
+<html lang="%s">
 <head>
 	<meta charset="UTF-8">
-	<title>Don't Forget Me</title>
+	<title>%s</title>
 </head>
 <body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
 	<div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-		<h2 style="color: #CEA04D;">Don't Forget Me - Your note</h2>
+		<h2 style="color: #CEA04D;">%s</h2>
 		%s
 		<p style="margin: 30px 0;">
 			<a href="%s" style="background-color: #CEA04D; color: white; padding: 10px 20px; text-decoration: none; border-radius: 5px; display: inline-block;">
-				Open in the web app
+				%s
 			</a>
 		</p>
-		<p style="margin-top: 30px; color: #999; font-size: 12px;">This is an automated reminder from Chronos Reminder</p>
+		<p style="margin-top: 30px; color: #999; font-size: 12px;">%s</p>
 	</div>
 </body>
 </html>
-	`, itemsHTML.String(), d.NoteWebURL())
+	`, i18n.Resolve(locale), i18n.T(locale, "dfm.title"), title, itemsHTML.String(), d.NoteWebURL(),
+		i18n.T(locale, "dfm.open_web_app"), i18n.T(locale, "email.automated"))
 
-	textBody := fmt.Sprintf("Don't Forget Me - Your note\n\n%s\n\nEdit your note: %s", RenderDFMNoteText(note), d.NoteWebURL())
+	textBody := fmt.Sprintf("%s\n\n%s\n\n%s", title, RenderDFMNoteText(note, locale), i18n.T(locale, "dfm.delivery.edit_link", d.NoteWebURL()))
 
 	_, err := d.mailer.SendEmail(&services.EmailRequest{
 		To:       email,
-		Subject:  "Don't Forget Me: your note reminder",
+		Subject:  i18n.T(locale, "dfm.delivery.email_subject"),
 		HtmlBody: htmlBody,
 		TextBody: textBody,
 	})
@@ -1,6 +1,7 @@
 package dispatchers
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/bwmarrin/discordgo"
@@ -24,21 +25,21 @@ func (d *DiscordChannelDispatcher) GetSupportedType() models.DestinationType {
 }
 
 // Dispatch sends a reminder message to a Discord channel
-func (d *DiscordChannelDispatcher) Dispatch(reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) error {
+func (d *DiscordChannelDispatcher) Dispatch(ctx context.Context, reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) (*models.DeliveryReceipt, error) {
 	// Validate destination type
 	if destination.Type != models.DestinationDiscordChannel {
-		return fmt.Errorf("invalid destination type for Discord channel dispatcher: %s", destination.Type)
+		return nil, fmt.Errorf("invalid destination type for Discord channel dispatcher: %s", destination.Type)
 	}
 
 	// Build the data from the metadata
 	// {"guild_id": "912661874871533588", "channel_id": "913222458251837441", "mention_role_id": "role_id"}
 	channelID, exists := destination.Metadata["channel_id"]
 	if !exists {
-		return fmt.Errorf("channel_id not found in destination metadata")
+		return nil, fmt.Errorf("channel_id not found in destination metadata")
 	}
 	channelIDStr, ok := channelID.(string)
 	if !ok {
-		return fmt.Errorf("channel_id is not a string: %v", channelID)
+		return nil, fmt.Errorf("channel_id is not a string: %v", channelID)
 	}
 
 	// Extract optional role mention ID
@@ -49,7 +50,10 @@ func (d *DiscordChannelDispatcher) Dispatch(reminder *models.Reminder, destinati
 		}
 	}
 
-	DiscordSend(d.session, reminder, channelIDStr, account, roleMentionID)
+	sent, err := DiscordSend(ctx, d.session, reminder, channelIDStr, account, roleMentionID)
+	if err != nil {
+		return nil, err
+	}
 
-	return nil
+	return &models.DeliveryReceipt{MessageID: sent.ID}, nil
 }
\ No newline at end of file
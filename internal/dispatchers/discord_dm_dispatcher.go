@@ -1,6 +1,7 @@
 package dispatchers
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/bwmarrin/discordgo"
@@ -26,30 +27,33 @@ func (d *DiscordDMDispatcher) GetSupportedType() models.DestinationType {
 }
 
 // Dispatch sends a reminder message via Discord DM
-func (d *DiscordDMDispatcher) Dispatch(reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) error {
+func (d *DiscordDMDispatcher) Dispatch(ctx context.Context, reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) (*models.DeliveryReceipt, error) {
 	// Validate destination type
 	if destination.Type != models.DestinationDiscordDM {
-		return fmt.Errorf("invalid destination type for Discord DM dispatcher: %s", destination.Type)
+		return nil, fmt.Errorf("invalid destination type for Discord DM dispatcher: %s", destination.Type)
 	}
 
 	// Extract user ID from metadata
 	userID, exists := destination.Metadata["user_id"]
 	if !exists {
-		return fmt.Errorf("user_id not found in destination metadata")
+		return nil, fmt.Errorf("user_id not found in destination metadata")
 	}
 
 	userIDStr, ok := userID.(string)
 	if !ok {
-		return fmt.Errorf("user_id is not a string: %v", userID)
+		return nil, fmt.Errorf("user_id is not a string: %v", userID)
 	}
 
 	// Create DM channel with the user
-	dmChannel, err := d.session.UserChannelCreate(userIDStr)
+	dmChannel, err := d.session.UserChannelCreate(userIDStr, discordgo.WithContext(ctx))
 	if err != nil {
-		return fmt.Errorf("failed to create DM channel with user %s: %w", userIDStr, err)
+		return nil, classifyDiscordError(fmt.Errorf("failed to create DM channel with user %s: %w", userIDStr, err))
 	}
 
-	DiscordSend(d.session, reminder, dmChannel.ID, account)
+	sent, err := DiscordSend(ctx, d.session, reminder, dmChannel.ID, account)
+	if err != nil {
+		return nil, err
+	}
 
-	return nil
+	return &models.DeliveryReceipt{MessageID: sent.ID}, nil
 }
@@ -1,10 +1,16 @@
 package dispatchers
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
+	"time"
 
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/resend/resend-go/v3"
 )
 
 // EmailDispatcher handles sending reminders via email
@@ -23,21 +29,66 @@ func (d *EmailDispatcher) GetSupportedType() models.DestinationType {
 }
 
 // Dispatch sends a reminder notification via email
-func (d *EmailDispatcher) Dispatch(reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) error {
+func (d *EmailDispatcher) Dispatch(ctx context.Context, reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) (*models.DeliveryReceipt, error) {
+	return d.DispatchWithKey(ctx, reminder, destination, account, dueFireKey(reminder))
+}
+
+// DispatchWithKey sends a reminder notification via email with the idempotency key of
+// the fire, Resend drops a second send of the key and SMTP relays see the same Message-ID
+func (d *EmailDispatcher) DispatchWithKey(ctx context.Context, reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account, idempotencyKey string) (*models.DeliveryReceipt, error) {
 	if destination.Type != models.DestinationEmail {
-		return fmt.Errorf("invalid destination type for email dispatcher: %s", destination.Type)
+		return nil, fmt.Errorf("invalid destination type for email dispatcher: %s", destination.Type)
 	}
 
 	emailVal, exists := destination.Metadata["email"]
 	if !exists {
-		return fmt.Errorf("email not found in destination metadata")
+		return nil, fmt.Errorf("email not found in destination metadata")
 	}
 
 	email, ok := emailVal.(string)
 	if !ok || email == "" {
-		return fmt.Errorf("email in destination metadata is not a valid string")
+		return nil, fmt.Errorf("email in destination metadata is not a valid string")
+	}
+
+	// Render the time in the language and timezone of the account when it is known
+	locale := i18n.Default
+	remindAt := reminder.RemindAtUTC.UTC()
+	zone := "UTC"
+	if account != nil {
+		locale = i18n.Resolve(account.Locale)
+		if account.Timezone != nil {
+			if loc, err := time.LoadLocation(account.Timezone.IANALocation); err == nil {
+				remindAt = remindAt.In(loc)
+				zone = account.Timezone.IANALocation
+			}
+		}
+	}
+	reminderTime := fmt.Sprintf("%s (%s)", i18n.FormatDateTime(locale, remindAt), zone)
+
+	messageID, err := d.mailer.SendReminderNotificationEmail(ctx, email, reminder.Message, reminderTime, locale, idempotencyKey)
+	if err != nil {
+		return nil, classifyMailError(err)
+	}
+
+	return &models.DeliveryReceipt{MessageID: messageID}, nil
+}
+
+// classifyMailError marks Resend throttling, SMTP 4xx replies and network failures
+// as transient. Anything else (bad address, rejected domain...) won't clear up.
+func classifyMailError(err error) error {
+	var rateLimitErr *resend.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return TransientAfter(err, parseRetryAfter(rateLimitErr.RetryAfter))
+	}
+
+	if services.IsTemporarySMTPError(err) {
+		return Transient(err)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return Transient(err)
 	}
 
-	_, err := d.mailer.SendReminderNotificationEmail(email, reminder.Message, reminder.RemindAtUTC.String())
 	return err
 }
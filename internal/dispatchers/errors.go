@@ -0,0 +1,47 @@
+package dispatchers
+
+import (
+	"errors"
+	"time"
+)
+
+// TransientError marks a dispatch failure that is expected to go away on its own
+// (remote 5xx, timeout, rate limit...). The engine retries such failures with a
+// backoff instead of flagging the destination as broken.
+type TransientError struct {
+	Err        error
+	RetryAfter time.Duration // minimum wait requested by the remote end, 0 when unknown
+}
+
+func (e *TransientError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransientError) Unwrap() error {
+	return e.Err
+}
+
+// Transient wraps err as a TransientError. A nil error stays nil.
+func Transient(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &TransientError{Err: err}
+}
+
+// TransientAfter wraps err as a TransientError that should not be retried before retryAfter
+func TransientAfter(err error, retryAfter time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &TransientError{Err: err, RetryAfter: retryAfter}
+}
+
+// AsTransient returns the TransientError found in err's chain, if any
+func AsTransient(err error) (*TransientError, bool) {
+	var transientErr *TransientError
+	if errors.As(err, &transientErr) {
+		return transientErr, true
+	}
+	return nil, false
+}
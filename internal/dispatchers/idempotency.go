@@ -0,0 +1,25 @@
+package dispatchers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/google/uuid"
+)
+
+// IdempotencyKey identifies one fire of a reminder. It is the same on every attempt and
+// on every instance, so a provider receiving it twice can drop the duplicate.
+func IdempotencyKey(reminderID uuid.UUID, fireTime time.Time) string {
+	return fmt.Sprintf("%s:%d", reminderID, fireTime.UTC().Unix())
+}
+
+// dueFireKey returns the key of the fire a reminder is due for, used when a dispatcher
+// is called without the key of the engine
+func dueFireKey(reminder *models.Reminder) string {
+	fireTime := reminder.RemindAtUTC
+	if reminder.NextFireUTC != nil {
+		fireTime = *reminder.NextFireUTC
+	}
+	return IdempotencyKey(reminder.ID, fireTime)
+}
@@ -0,0 +1,82 @@
+package dispatchers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+)
+
+// TelegramDispatcher delivers reminders to linked Telegram chats through the Bot API
+type TelegramDispatcher struct {
+	telegramService *services.TelegramService
+}
+
+// NewTelegramDispatcher creates a new Telegram dispatcher
+func NewTelegramDispatcher(telegramService *services.TelegramService) *TelegramDispatcher {
+	return &TelegramDispatcher{
+		telegramService: telegramService,
+	}
+}
+
+// GetSupportedType returns the destination type this dispatcher supports
+func (d *TelegramDispatcher) GetSupportedType() models.DestinationType {
+	return models.DestinationTelegram
+}
+
+// Dispatch sends the reminder with its snooze buttons to the chat in the destination
+// metadata. The chat must still be linked to the reminder's account.
+func (d *TelegramDispatcher) Dispatch(ctx context.Context, reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) (*models.DeliveryReceipt, error) {
+	if destination.Type != models.DestinationTelegram {
+		return nil, fmt.Errorf("invalid destination type for telegram dispatcher: %s", destination.Type)
+	}
+
+	if !d.telegramService.IsEnabled() {
+		return nil, services.ErrTelegramNotConfigured
+	}
+
+	chatID, ok := destination.Metadata["chat_id"].(string)
+	if !ok || chatID == "" {
+		return nil, fmt.Errorf("chat_id not found in destination metadata")
+	}
+
+	linked, err := d.telegramService.IsChatLinked(reminder.AccountID, chatID)
+	if err != nil {
+		return nil, Transient(fmt.Errorf("failed to check the telegram chat link: %w", err))
+	}
+	if !linked {
+		return nil, services.ErrTelegramChatNotLinked
+	}
+
+	message, err := d.telegramService.SendReminder(reminder, chatID, account)
+	if err != nil {
+		return nil, classifyTelegramError(fmt.Errorf("failed to send telegram message: %w", err))
+	}
+
+	logging.For(logging.Dispatcher).Debug("Telegram message sent", "reminder_id", reminder.ID, "chat_id", chatID)
+	return &models.DeliveryReceipt{MessageID: strconv.FormatInt(message.MessageID, 10)}, nil
+}
+
+// classifyTelegramError marks rate limits, Bot API outages and network failures as transient.
+// Other API errors (blocked bot, unknown chat...) will not go away on retry.
+func classifyTelegramError(err error) error {
+	var apiErr *services.TelegramAPIError
+	if errors.As(err, &apiErr) {
+		if isRetryableStatus(apiErr.StatusCode) {
+			return TransientAfter(err, apiErr.RetryAfter)
+		}
+		return err
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return Transient(err)
+	}
+
+	return err
+}
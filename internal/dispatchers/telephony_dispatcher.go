@@ -0,0 +1,96 @@
+package dispatchers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+)
+
+// TelephonyDispatcher delivers reminders by SMS or voice call to a verified phone number
+type TelephonyDispatcher struct {
+	telephonyService *services.TelephonyService
+	destinationType  models.DestinationType
+}
+
+// NewSMSDispatcher creates a dispatcher texting reminders
+func NewSMSDispatcher(telephonyService *services.TelephonyService) *TelephonyDispatcher {
+	return &TelephonyDispatcher{
+		telephonyService: telephonyService,
+		destinationType:  models.DestinationSMS,
+	}
+}
+
+// NewVoiceDispatcher creates a dispatcher calling and reading reminders aloud
+func NewVoiceDispatcher(telephonyService *services.TelephonyService) *TelephonyDispatcher {
+	return &TelephonyDispatcher{
+		telephonyService: telephonyService,
+		destinationType:  models.DestinationVoice,
+	}
+}
+
+// GetSupportedType returns the destination type this dispatcher supports
+func (d *TelephonyDispatcher) GetSupportedType() models.DestinationType {
+	return d.destinationType
+}
+
+// Dispatch sends the reminder to the phone number in the destination metadata. The
+// number must be verified by the reminder's account and the monthly quota not reached.
+func (d *TelephonyDispatcher) Dispatch(ctx context.Context, reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) (*models.DeliveryReceipt, error) {
+	if destination.Type != d.destinationType {
+		return nil, fmt.Errorf("invalid destination type for %s dispatcher: %s", d.destinationType, destination.Type)
+	}
+
+	if !d.telephonyService.IsEnabled() {
+		return nil, services.ErrTelephonyNotConfigured
+	}
+
+	phoneNumber, ok := destination.Metadata["phone_number"].(string)
+	if !ok || phoneNumber == "" {
+		return nil, fmt.Errorf("phone_number not found in destination metadata")
+	}
+
+	verified, err := d.telephonyService.IsPhoneNumberVerified(reminder.AccountID, phoneNumber)
+	if err != nil {
+		return nil, Transient(fmt.Errorf("failed to check the phone number: %w", err))
+	}
+	if !verified {
+		return nil, services.ErrPhoneNumberNotVerified
+	}
+
+	var messageID string
+	if d.destinationType == models.DestinationVoice {
+		messageID, err = d.telephonyService.Call(ctx, reminder.AccountID, phoneNumber, "This is a Chronos reminder. "+reminder.Message)
+	} else {
+		messageID, err = d.telephonyService.SendSMS(ctx, reminder.AccountID, phoneNumber, "⏰ Chronos reminder: "+reminder.Message)
+	}
+	if err != nil {
+		return nil, classifyTelephonyError(fmt.Errorf("failed to send %s: %w", d.destinationType, err))
+	}
+
+	logging.For(logging.Dispatcher).Debug("Telephony message sent", "destination_type", d.destinationType, "reminder_id", reminder.ID)
+	return &models.DeliveryReceipt{MessageID: messageID}, nil
+}
+
+// classifyTelephonyError marks rate limits, provider outages and network failures as
+// transient. Quota and invalid number errors will not go away on retry.
+func classifyTelephonyError(err error) error {
+	var telephonyErr *services.TelephonyError
+	if errors.As(err, &telephonyErr) {
+		if isRetryableStatus(telephonyErr.StatusCode) {
+			return TransientAfter(err, telephonyErr.RetryAfter)
+		}
+		return err
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return Transient(err)
+	}
+
+	return err
+}
@@ -2,12 +2,17 @@ package dispatchers
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"image/png"
+	"net"
+	"net/http"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 )
 
@@ -15,20 +20,18 @@ import (
 // Contains everything that may be used in multiple dispatchers
 // =====================================================================
 
-// DiscordSend handles sending reminders via Discord
-func DiscordSend(session *discordgo.Session, reminder *models.Reminder, channelID string, account *models.Account, roleMentionID ...string) error {
+// DiscordSend handles sending reminders via Discord and returns the reminder message.
+// The requests to the Discord API run within ctx, which carries the trace of the fire.
+// The embed and the image go out in a single message so a retried delivery never posts half of it twice.
+func DiscordSend(ctx context.Context, session *discordgo.Session, reminder *models.Reminder, channelID string, account *models.Account, roleMentionID ...string) (*discordgo.Message, error) {
+	locale := i18n.Resolve(account.Locale)
+
 	// Create the reminder message
 	embed := &discordgo.MessageEmbed{
-		Title:       "⌛ | You have a new reminder ! ⌛",
+		Title:       "⌛ | " + i18n.T(locale, "reminder.delivery.title") + " ⌛",
 		Color:       0xCEA04D,
 	}
 
-	// Send the message
-	_, err := session.ChannelMessageSendEmbed(channelID, embed)
-	if err != nil {
-		return fmt.Errorf("failed to send DM  %w", err)
-	}
-
 	// Convert the due date to the user's local timezone if available
 	loc, err := time.LoadLocation(account.Timezone.IANALocation)
 	if err == nil {
@@ -43,18 +46,18 @@ func DiscordSend(session *discordgo.Session, reminder *models.Reminder, channelI
 
 	// Check for errors
 	if err != nil {
-		return fmt.Errorf("failed to generate reminder image: %w", err)
+		return nil, fmt.Errorf("failed to generate reminder image: %w", err)
 	}
 
 	// Encode img (image.Image) to PNG and wrap in io.Reader
 	var buf bytes.Buffer
 	if err := png.Encode(&buf, img); err != nil {
-		return fmt.Errorf("failed to encode reminder image: %w", err)
+		return nil, fmt.Errorf("failed to encode reminder image: %w", err)
 	}
 
 		// Add a button to the message
 	button := discordgo.Button{
-		Label:   "Snooze",
+		Label:   i18n.T(locale, "snooze.button"),
 		Style:   discordgo.SecondaryButton,
 		CustomID: "reminder_request_snooze_" + fmt.Sprint(reminder.ID),
 	}
@@ -72,6 +75,7 @@ func DiscordSend(session *discordgo.Session, reminder *models.Reminder, channelI
 	
 	msg := &discordgo.MessageSend{
 		Content: messageContent,
+		Embeds:  []*discordgo.MessageEmbed{embed},
 		File: &discordgo.File{
 			Name:        "reminder.png",
 			ContentType: "image/png",
@@ -79,10 +83,37 @@ func DiscordSend(session *discordgo.Session, reminder *models.Reminder, channelI
 		},
 		Components: components,
 	}
-	_, err = session.ChannelMessageSendComplex(channelID, msg)
+	sent, err := session.ChannelMessageSendComplex(channelID, msg, discordgo.WithContext(ctx))
 	if err != nil {
-		return fmt.Errorf("failed to send reminder: %w", err)
+		return nil, classifyDiscordError(fmt.Errorf("failed to send reminder: %w", err))
+	}
+
+	return sent, nil
+}
+
+// classifyDiscordError marks Discord API failures worth retrying (rate limits, 5xx, network timeouts) as transient
+func classifyDiscordError(err error) error {
+	var rateLimitErr *discordgo.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		retryAfter := time.Duration(0)
+		if rateLimitErr.RateLimit != nil && rateLimitErr.TooManyRequests != nil {
+			retryAfter = rateLimitErr.RetryAfter
+		}
+		return TransientAfter(err, retryAfter)
+	}
+
+	var restErr *discordgo.RESTError
+	if errors.As(err, &restErr) && restErr.Response != nil {
+		if restErr.Response.StatusCode == http.StatusTooManyRequests || restErr.Response.StatusCode >= 500 {
+			return Transient(err)
+		}
+		return err
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return Transient(err)
 	}
 
-	return nil
-}
\ No newline at end of file
+	return err
+}
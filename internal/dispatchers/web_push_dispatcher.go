@@ -0,0 +1,134 @@
+package dispatchers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/google/uuid"
+)
+
+// WebPushDispatcher delivers reminders to a user's browsers via Web Push.
+type WebPushDispatcher struct {
+	webPushService   *services.WebPushService
+	subscriptionRepo repositories.WebPushSubscriptionRepository
+}
+
+// NewWebPushDispatcher creates a new web push dispatcher
+func NewWebPushDispatcher(webPushService *services.WebPushService, subscriptionRepo repositories.WebPushSubscriptionRepository) *WebPushDispatcher {
+	return &WebPushDispatcher{
+		webPushService:   webPushService,
+		subscriptionRepo: subscriptionRepo,
+	}
+}
+
+// GetSupportedType returns the destination type this dispatcher supports
+func (d *WebPushDispatcher) GetSupportedType() models.DestinationType {
+	return models.DestinationWebPush
+}
+
+// webPushPayload is the JSON message the service worker of the web app displays
+type webPushPayload struct {
+	Title      string `json:"title"`
+	Body       string `json:"body"`
+	ReminderID string `json:"reminder_id"`
+}
+
+// Dispatch sends the reminder as a browser notification to every subscription
+// of the account in the destination metadata. Subscriptions the push service
+// reports as gone are pruned from the database.
+func (d *WebPushDispatcher) Dispatch(ctx context.Context, reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) (*models.DeliveryReceipt, error) {
+	if destination.Type != models.DestinationWebPush {
+		return nil, fmt.Errorf("invalid destination type for web push dispatcher: %s", destination.Type)
+	}
+
+	if !d.webPushService.IsEnabled() {
+		return nil, fmt.Errorf("browser notifications are not configured")
+	}
+
+	accountIDStr, ok := destination.Metadata["account_id"].(string)
+	if !ok || accountIDStr == "" {
+		return nil, fmt.Errorf("account_id in destination metadata is not a valid string")
+	}
+	accountID, err := uuid.Parse(accountIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("account_id in destination metadata is not a valid UUID: %w", err)
+	}
+
+	subscriptions, err := d.subscriptionRepo.GetByAccountID(accountID)
+	if err != nil {
+		return nil, Transient(fmt.Errorf("failed to load web push subscriptions: %w", err))
+	}
+	if len(subscriptions) == 0 {
+		return nil, fmt.Errorf("no web push subscriptions registered for account %s", accountID)
+	}
+
+	payload, err := json.Marshal(webPushPayload{
+		Title:      "Chronos",
+		Body:       truncateWebPushBody(reminder.Message),
+		ReminderID: reminder.ID.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode web push payload: %w", err)
+	}
+
+	var sendErrors []error
+	var messageIDs []string
+	unavailable := 0
+	for _, subscription := range subscriptions {
+		keys := services.WebPushKeys{P256dh: subscription.P256dh, Auth: subscription.Auth}
+		messageID, err := d.webPushService.Send(ctx, subscription.Endpoint, keys, payload)
+		if err == nil {
+			messageIDs = append(messageIDs, messageID)
+			continue
+		}
+
+		if errors.Is(err, services.ErrWebPushSubscriptionGone) {
+			// The browser unsubscribed or the subscription expired, stop pushing to it.
+			if delErr := d.subscriptionRepo.DeleteByEndpoint(subscription.Endpoint); delErr != nil {
+				logging.For(logging.Dispatcher).Warn("Failed to delete expired web push subscription", "reminder_id", reminder.ID, "error", delErr)
+			}
+			continue
+		}
+
+		if errors.Is(err, services.ErrWebPushUnavailable) {
+			unavailable++
+		}
+		sendErrors = append(sendErrors, err)
+	}
+
+	if len(sendErrors) > 0 {
+		err := fmt.Errorf("failed to deliver web push to %d/%d browsers: %w", len(sendErrors), len(subscriptions), sendErrors[0])
+		// Same rule as Android push: retrying after a partial delivery would notify twice
+		if unavailable == len(sendErrors) && len(messageIDs) == 0 {
+			return nil, Transient(err)
+		}
+		return nil, err
+	}
+
+	if len(messageIDs) == 0 {
+		return nil, fmt.Errorf("every web push subscription of account %s has expired", accountID)
+	}
+
+	return &models.DeliveryReceipt{MessageID: strings.Join(messageIDs, ",")}, nil
+}
+
+// truncateWebPushBody keeps the encrypted payload under the size push services accept
+func truncateWebPushBody(message string) string {
+	const maxBody = 3000
+	if len(message) <= maxBody {
+		return message
+	}
+	cut := maxBody
+	for !utf8.RuneStart(message[cut]) {
+		cut--
+	}
+	return message[:cut] + "…"
+}
@@ -2,13 +2,20 @@ package dispatchers
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/ericp/chronos-bot-reminder/internal/tracing"
+	"github.com/ericp/chronos-bot-reminder/pkg/webhook"
+	"github.com/google/uuid"
 )
 
 // WebhookDispatcher handles sending reminders via webhooks
@@ -22,7 +29,8 @@ func NewWebhookDispatcher() *WebhookDispatcher {
 	return &WebhookDispatcher{
 		formatter: services.NewWebhookFormatter(),
 		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Transport: tracing.Transport(nil),
+			Timeout:   10 * time.Second,
 		},
 	}
 }
@@ -33,27 +41,33 @@ func (d *WebhookDispatcher) GetSupportedType() models.DestinationType {
 }
 
 // Dispatch sends the reminder via webhook
-func (d *WebhookDispatcher) Dispatch(reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) error {
+func (d *WebhookDispatcher) Dispatch(ctx context.Context, reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) (*models.DeliveryReceipt, error) {
+	return d.DispatchWithKey(ctx, reminder, destination, account, dueFireKey(reminder))
+}
+
+// DispatchWithKey sends the reminder via webhook with the idempotency key of the fire,
+// which receivers can use to drop a delivery they already processed
+func (d *WebhookDispatcher) DispatchWithKey(ctx context.Context, reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account, idempotencyKey string) (*models.DeliveryReceipt, error) {
 	// Extract webhook URL from metadata
 	urlVal, exists := destination.Metadata["url"]
 	if !exists {
-		return fmt.Errorf("webhook URL not found in metadata")
+		return nil, fmt.Errorf("webhook URL not found in metadata")
 	}
 
 	url, ok := urlVal.(string)
 	if !ok {
-		return fmt.Errorf("webhook URL is not a string")
+		return nil, fmt.Errorf("webhook URL is not a string")
 	}
 
 	// Format the payload based on the platform
 	payload, err := d.formatter.FormatPayload(reminder, destination, account)
 	if err != nil {
-		return fmt.Errorf("failed to format webhook payload: %w", err)
+		return nil, fmt.Errorf("failed to format webhook payload: %w", err)
 	}
 
 	// Validate the payload
-	if err := d.formatter.ValidatePayload(payload); err != nil {
-		return fmt.Errorf("invalid webhook payload: %w", err)
+	if err := d.formatter.ValidatePayload(destination, payload); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
 	}
 
 	// Log the request (for debugging)
@@ -63,18 +77,23 @@ func (d *WebhookDispatcher) Dispatch(reminder *models.Reminder, destination *mod
 			platform = platformStr
 		}
 	}
-	log.Printf("[WEBHOOK_DISPATCHER] Sending %s webhook for reminder %s to %s", platform, reminder.ID, maskURL(url))
+	logging.For(logging.Dispatcher).DebugContext(ctx, "Sending webhook", "platform", platform, "reminder_id", reminder.ID, "url", maskURL(url))
 
 	// Create HTTP request
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payload))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(payload))
 	if err != nil {
-		return fmt.Errorf("failed to create webhook request: %w", err)
+		return nil, fmt.Errorf("failed to create webhook request: %w", err)
 	}
 
 	// Set headers
 	req.Header.Set("Content-Type", d.formatter.GetContentType(destination))
 	req.Header.Set("User-Agent", "Chronos-Reminder/1.0")
 
+	// Add the headers the platform expects (ntfy title, priority and tags)
+	for key, value := range d.formatter.GetHeaders(destination) {
+		req.Header.Set(key, value)
+	}
+
 	// Add any custom headers from metadata
 	if headersVal, exists := destination.Metadata["headers"]; exists {
 		if headers, ok := headersVal.(map[string]interface{}); ok {
@@ -86,22 +105,81 @@ func (d *WebhookDispatcher) Dispatch(reminder *models.Reminder, destination *mod
 		}
 	}
 
+	// The delivery ID changes on every attempt, the idempotency key stays the same for the fire
+	req.Header.Set(webhook.IdempotencyKeyHeader, idempotencyKey)
+
+	// Sign the request, after the custom headers so they cannot override the signature
+	deliveryID := uuid.New().String()
+	if err := signWebhookRequest(req, destination, payload, deliveryID); err != nil {
+		return nil, err
+	}
+
 	// Send the request
 	resp, err := d.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send webhook request: %w", err)
+		err = fmt.Errorf("failed to send webhook request: %w", err)
+		// An unknown host will not resolve itself, any other network failure (timeout, refused connection...) may
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return nil, err
+		}
+		return nil, Transient(err)
 	}
 	defer resp.Body.Close()
 
+	receipt := &models.DeliveryReceipt{ResponseCode: resp.StatusCode, MessageID: deliveryID}
+
 	// Check response status
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("webhook returned non-success status code: %d", resp.StatusCode)
+		err := fmt.Errorf("webhook returned non-success status code: %d", resp.StatusCode)
+		if isRetryableStatus(resp.StatusCode) {
+			return receipt, TransientAfter(err, parseRetryAfter(resp.Header.Get("Retry-After")))
+		}
+		return receipt, err
 	}
 
-	log.Printf("[WEBHOOK_DISPATCHER] Successfully sent webhook for reminder %s (status: %d)", reminder.ID, resp.StatusCode)
+	logging.For(logging.Dispatcher).Debug("Webhook sent", "reminder_id", reminder.ID, "status", resp.StatusCode)
+	return receipt, nil
+}
+
+// signWebhookRequest sets the delivery ID, timestamp and signature headers of a webhook
+// request. Destinations created before signing existed are sent unsigned until their
+// secret is rotated.
+func signWebhookRequest(req *http.Request, destination *models.ReminderDestination, payload []byte, deliveryID string) error {
+	now := time.Now()
+	req.Header.Set(webhook.DeliveryIDHeader, deliveryID)
+	req.Header.Set(webhook.TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+
+	secret, err := services.WebhookSigningSecret(destination)
+	if errors.Is(err, services.ErrWebhookSecretUnavailable) {
+		logging.For(logging.Dispatcher).Warn("Destination has no signing secret, sending unsigned", "destination_id", destination.ID)
+		return nil
+	}
+	if err != nil {
+		// A secret encrypted with another key will not decrypt on retry either
+		return fmt.Errorf("failed to read webhook signing secret: %w", err)
+	}
+
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(secret, now, payload))
 	return nil
 }
 
+// isRetryableStatus reports whether an HTTP status means the receiver may accept the same request later
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusRequestTimeout ||
+		statusCode == http.StatusTooManyRequests ||
+		statusCode >= 500
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds, 0 when absent or in another format
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
 // maskURL masks sensitive parts of a URL for logging
 func maskURL(url string) string {
 	// Simple masking - show only the domain
@@ -0,0 +1,217 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/ericp/chronos-bot-reminder/internal/tracing"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// reminderEventsChannel is the Redis pub/sub channel of the reminder changes
+const reminderEventsChannel = "scheduler:reminder_events"
+
+// ReminderEvent is a reminder change broadcast to every instance
+type ReminderEvent struct {
+	InstanceID string    `json:"instance_id"` // instance the change was made on
+	Type       string    `json:"type"`        // "created", "updated", "deleted"
+	ReminderID uuid.UUID `json:"reminder_id"`
+	// Trace is the trace context of the change, continued by the receiving instances
+	Trace map[string]string `json:"trace,omitempty"`
+}
+
+// ReminderEventBus carries the reminder events between instances
+type ReminderEventBus interface {
+	Publish(ctx context.Context, event ReminderEvent) error
+	// Subscribe calls handler for every event published from now on, until ctx is done
+	Subscribe(ctx context.Context, handler func(ReminderEvent)) error
+}
+
+// RedisReminderEventBus broadcasts the reminder events with Redis pub/sub
+type RedisReminderEventBus struct {
+	client  *redis.Client
+	channel string
+	logger  *slog.Logger
+}
+
+// NewRedisReminderEventBus creates an event bus on the given Redis client
+func NewRedisReminderEventBus(client *redis.Client) *RedisReminderEventBus {
+	return &RedisReminderEventBus{client: client, channel: reminderEventsChannel, logger: logging.For(logging.Engine)}
+}
+
+// Publish sends the event to every subscribed instance
+func (b *RedisReminderEventBus) Publish(ctx context.Context, event ReminderEvent) error {
+	if b.client == nil {
+		return fmt.Errorf("redis is not initialized")
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, b.channel, payload).Err()
+}
+
+// Subscribe waits for the subscription to be confirmed, then handles the events in
+// the background. The client resubscribes by itself after a connection loss.
+func (b *RedisReminderEventBus) Subscribe(ctx context.Context, handler func(ReminderEvent)) error {
+	if b.client == nil {
+		return fmt.Errorf("redis is not initialized")
+	}
+
+	pubsub := b.client.Subscribe(ctx, b.channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return fmt.Errorf("failed to subscribe to %s: %w", b.channel, err)
+	}
+
+	go func() {
+		defer pubsub.Close()
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event ReminderEvent
+				if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+					b.logger.Warn("Ignoring malformed reminder event", "error", err)
+					continue
+				}
+				handler(event)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// ClusterNotifier notifies the local scheduler of the reminder changes and broadcasts
+// them, so the instance holding the leadership reschedules whichever instance
+// served the request. It is the scheduler notifier of the reminder repository.
+type ClusterNotifier struct {
+	instanceID string
+	local      repositories.SchedulerNotifier
+	bus        ReminderEventBus
+	logger     *slog.Logger
+}
+
+// NewClusterNotifier creates a notifier forwarding to the local scheduler and the bus
+func NewClusterNotifier(instanceID string, local repositories.SchedulerNotifier, bus ReminderEventBus) *ClusterNotifier {
+	return &ClusterNotifier{
+		instanceID: instanceID,
+		local:      local,
+		bus:        bus,
+		logger:     logging.For(logging.Engine).With("instance_id", instanceID),
+	}
+}
+
+// SetLogger replaces the logger of the notifier
+func (n *ClusterNotifier) SetLogger(logger *slog.Logger) {
+	n.logger = logger
+}
+
+// Listen forwards the events of the other instances to the local scheduler until ctx is done
+func (n *ClusterNotifier) Listen(ctx context.Context) error {
+	return n.bus.Subscribe(ctx, n.handleEvent)
+}
+
+// NotifyReminderCreated notifies every instance that a new reminder was created
+func (n *ClusterNotifier) NotifyReminderCreated(reminderID uuid.UUID) {
+	n.NotifyReminderCreatedContext(context.Background(), reminderID)
+}
+
+// NotifyReminderUpdated notifies every instance that a reminder was updated
+func (n *ClusterNotifier) NotifyReminderUpdated(reminderID uuid.UUID) {
+	n.NotifyReminderUpdatedContext(context.Background(), reminderID)
+}
+
+// NotifyReminderDeleted notifies every instance that a reminder was deleted
+func (n *ClusterNotifier) NotifyReminderDeleted(reminderID uuid.UUID) {
+	n.NotifyReminderDeletedContext(context.Background(), reminderID)
+}
+
+// NotifyReminderCreatedContext notifies every instance that a new reminder was
+// created, within the trace of ctx
+func (n *ClusterNotifier) NotifyReminderCreatedContext(ctx context.Context, reminderID uuid.UUID) {
+	n.notify(ctx, "created", reminderID)
+}
+
+// NotifyReminderUpdatedContext notifies every instance that a reminder was updated,
+// within the trace of ctx
+func (n *ClusterNotifier) NotifyReminderUpdatedContext(ctx context.Context, reminderID uuid.UUID) {
+	n.notify(ctx, "updated", reminderID)
+}
+
+// NotifyReminderDeletedContext notifies every instance that a reminder was deleted,
+// within the trace of ctx
+func (n *ClusterNotifier) NotifyReminderDeletedContext(ctx context.Context, reminderID uuid.UUID) {
+	n.notify(ctx, "deleted", reminderID)
+}
+
+// notify delivers a change to the local scheduler then broadcasts it
+func (n *ClusterNotifier) notify(ctx context.Context, eventType string, reminderID uuid.UUID) {
+	ctx, span := tracing.Start(ctx, "scheduler.notify", trace.WithAttributes(
+		attribute.String("event", eventType),
+		attribute.String("reminder_id", reminderID.String()),
+	))
+	defer span.End()
+
+	n.deliver(eventType, reminderID)
+	n.publish(ctx, eventType, reminderID)
+}
+
+// deliver hands a change to the local scheduler
+func (n *ClusterNotifier) deliver(eventType string, reminderID uuid.UUID) bool {
+	switch eventType {
+	case "created":
+		n.local.NotifyReminderCreated(reminderID)
+	case "updated":
+		n.local.NotifyReminderUpdated(reminderID)
+	case "deleted":
+		n.local.NotifyReminderDeleted(reminderID)
+	default:
+		return false
+	}
+	return true
+}
+
+// publish broadcasts a change, a failure only delays it until the next fallback poll of the leader
+func (n *ClusterNotifier) publish(ctx context.Context, eventType string, reminderID uuid.UUID) {
+	event := ReminderEvent{InstanceID: n.instanceID, Type: eventType, ReminderID: reminderID, Trace: map[string]string{}}
+	tracing.Inject(ctx, event.Trace)
+	if err := n.bus.Publish(context.WithoutCancel(ctx), event); err != nil {
+		n.logger.WarnContext(ctx, "Error broadcasting reminder event", "event", eventType, "reminder_id", reminderID, "error", err)
+	}
+}
+
+// handleEvent hands an event of another instance to the local scheduler. The
+// instance's own events were already delivered locally when published.
+func (n *ClusterNotifier) handleEvent(event ReminderEvent) {
+	if event.InstanceID == n.instanceID {
+		return
+	}
+
+	ctx := tracing.Extract(context.Background(), event.Trace)
+	ctx, span := tracing.Start(ctx, "scheduler.notify.receive", trace.WithSpanKind(trace.SpanKindConsumer), trace.WithAttributes(
+		attribute.String("event", event.Type),
+		attribute.String("reminder_id", event.ReminderID.String()),
+		attribute.String("from_instance_id", event.InstanceID),
+	))
+	defer span.End()
+
+	n.logger.DebugContext(ctx, "Received reminder event", "event", event.Type, "reminder_id", event.ReminderID, "from_instance_id", event.InstanceID)
+
+	if !n.deliver(event.Type, event.ReminderID) {
+		n.logger.WarnContext(ctx, "Ignoring unknown reminder event type", "event", event.Type)
+	}
+}
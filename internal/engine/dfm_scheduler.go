@@ -3,13 +3,15 @@ package engine
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
-	"github.com/ericp/chronos-bot-reminder/internal/config"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
 	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
 	"github.com/ericp/chronos-bot-reminder/internal/dispatchers"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/ericp/chronos-bot-reminder/internal/metrics"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 	"github.com/google/uuid"
 )
@@ -26,6 +28,7 @@ type DFMScheduler struct {
 	dispatcher   *dispatchers.DFMDispatcher
 	stopChan     chan struct{}
 	running      bool
+	logger       *slog.Logger
 }
 
 // NewDFMScheduler creates a new DFM scheduler instance
@@ -41,18 +44,25 @@ func NewDFMScheduler(
 		accountRepo:  accountRepo,
 		dispatcher:   dispatcher,
 		stopChan:     make(chan struct{}),
+		logger:       logging.For(logging.Engine),
 	}
 }
 
+// SetLogger replaces the logger of the DFM scheduler
+func (s *DFMScheduler) SetLogger(logger *slog.Logger) {
+	s.logger = logger
+}
+
 // Start begins the DFM scheduling loop
 func (s *DFMScheduler) Start(ctx context.Context) {
 	if s.running {
-		log.Println("[ENGINE] - DFM scheduler already running")
+		s.logger.Info("DFM scheduler already running")
 		return
 	}
+	s.stopChan = make(chan struct{})
 	s.running = true
 
-	go func() {
+	go func(stopChan <-chan struct{}) {
 		ticker := time.NewTicker(dfmPollInterval)
 		defer ticker.Stop()
 
@@ -63,16 +73,15 @@ func (s *DFMScheduler) Start(ctx context.Context) {
 			case <-ctx.Done():
 				s.running = false
 				return
-			case <-s.stopChan:
-				s.running = false
+			case <-stopChan:
 				return
 			case <-ticker.C:
 				s.processDueNotes()
 			}
 		}
-	}()
+	}(s.stopChan)
 
-	log.Println("[ENGINE] - ✅ DFM scheduler started")
+	s.logger.Info("DFM scheduler started")
 }
 
 // Stop gracefully stops the DFM scheduler
@@ -100,12 +109,14 @@ func (s *DFMScheduler) SendNoteNow(accountID uuid.UUID) error {
 		return fmt.Errorf("no DFM note found for account %s", accountID)
 	}
 
-	discordID, email, err := s.resolveDeliveryAddresses(accountID)
+	discordID, email, locale, err := s.resolveDeliveryAddresses(accountID)
 	if err != nil {
 		return err
 	}
 
-	return s.dispatcher.Dispatch(note, discordID, email)
+	err = s.dispatcher.Dispatch(note, discordID, email, locale)
+	metrics.IncDFMSends(err)
+	return err
 }
 
 // SendDFMNoteNow dispatches the account's note immediately through the running scheduler service
@@ -122,7 +133,7 @@ func (s *DFMScheduler) processDueNotes() {
 	now := time.Now().UTC()
 	notes, err := s.noteRepo.GetDueNotes(now)
 	if err != nil {
-		log.Printf("[ENGINE] - Error fetching due DFM notes: %v", err)
+		s.logger.Error("Error fetching due DFM notes", "error", err)
 		return
 	}
 
@@ -131,12 +142,12 @@ func (s *DFMScheduler) processDueNotes() {
 	}
 }
 
-// resolveDeliveryAddresses returns the Discord user ID and email for an account.
-// Either may be empty string if not linked.
-func (s *DFMScheduler) resolveDeliveryAddresses(accountID uuid.UUID) (discordID string, email string, err error) {
+// resolveDeliveryAddresses returns the Discord user ID and email for an account,
+// with the language the note is sent in. Either address may be empty string if not linked.
+func (s *DFMScheduler) resolveDeliveryAddresses(accountID uuid.UUID) (discordID string, email string, locale string, err error) {
 	identities, err := s.identityRepo.GetByAccountID(accountID)
 	if err != nil {
-		return "", "", err
+		return "", "", "", err
 	}
 	for _, id := range identities {
 		if id.Provider == models.ProviderDiscord {
@@ -145,26 +156,32 @@ func (s *DFMScheduler) resolveDeliveryAddresses(accountID uuid.UUID) (discordID
 	}
 	account, err := s.accountRepo.GetByID(accountID)
 	if err != nil {
-		return "", "", err
+		return "", "", "", err
 	}
-	if account != nil && account.Email != nil {
-		email = *account.Email
+	locale = i18n.Default
+	if account != nil {
+		locale = i18n.Resolve(account.Locale)
+		if account.Email != nil {
+			email = *account.Email
+		}
 	}
-	return discordID, email, nil
+	return discordID, email, locale, nil
 }
 
 // processNote dispatches a single note, records the delivery and computes the next fire time
 func (s *DFMScheduler) processNote(note *models.DFMNote) {
-	discordID, email, err := s.resolveDeliveryAddresses(note.AccountID)
+	discordID, email, locale, err := s.resolveDeliveryAddresses(note.AccountID)
 	if err != nil {
-		log.Printf("[ENGINE] - Error fetching delivery addresses for DFM note %s: %v", note.ID, err)
+		s.logger.Error("Error fetching delivery addresses for DFM note", "note_id", note.ID, "error", err)
 		return
 	}
 
-	if err := s.dispatcher.Dispatch(note, discordID, email); err != nil {
-		log.Printf("[ENGINE] - Error dispatching DFM note %s: %v", note.ID, err)
-	} else if config.IsDebugMode() {
-		log.Printf("[ENGINE] - DFM note %s dispatched", note.ID)
+	err = s.dispatcher.Dispatch(note, discordID, email, locale)
+	metrics.IncDFMSends(err)
+	if err != nil {
+		s.logger.Error("Error dispatching DFM note", "note_id", note.ID, "error", err)
+	} else {
+		s.logger.Debug("DFM note dispatched", "note_id", note.ID)
 	}
 
 	// Always reschedule, even after a dispatch failure, so a broken
@@ -191,7 +208,7 @@ func (s *DFMScheduler) rescheduleNote(note *models.DFMNote) {
 
 		nextTime, err := services.GetNextOccurrence(*note.RemindAtUTC, int(note.Recurrence), ianaLocation)
 		if err != nil {
-			log.Printf("[ENGINE] - Error computing next occurrence for DFM note %s: %v", note.ID, err)
+			s.logger.Error("Error computing next occurrence for DFM note", "note_id", note.ID, "error", err)
 			return
 		}
 
@@ -201,6 +218,6 @@ func (s *DFMScheduler) rescheduleNote(note *models.DFMNote) {
 	}
 
 	if err := s.noteRepo.Update(note); err != nil {
-		log.Printf("[ENGINE] - Error rescheduling DFM note %s: %v", note.ID, err)
+		s.logger.Error("Error rescheduling DFM note", "note_id", note.ID, "error", err)
 	}
 }
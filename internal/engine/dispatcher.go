@@ -1,78 +1,202 @@
 package engine
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"runtime/debug"
 	"time"
 
-	"github.com/ericp/chronos-bot-reminder/internal/config"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
 	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/dispatchers"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/ericp/chronos-bot-reminder/internal/metrics"
+	"github.com/ericp/chronos-bot-reminder/internal/tracing"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// Dispatcher interface defines how reminders are sent to different destinations
+// Dispatcher interface defines how reminders are sent to different destinations.
+// Dispatch returns a receipt describing what the remote end answered; it may be
+// nil when the transport does not expose anything useful. The context carries the
+// trace of the fire and is passed on to the outgoing calls.
 type Dispatcher interface {
-	Dispatch(reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) error
+	Dispatch(ctx context.Context, reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) (*models.DeliveryReceipt, error)
 	GetSupportedType() models.DestinationType
 }
 
+// IdempotentDispatcher is implemented by the dispatchers whose transport can drop
+// duplicates. The key identifies the fire and stays the same on every attempt.
+type IdempotentDispatcher interface {
+	DispatchWithKey(ctx context.Context, reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account, idempotencyKey string) (*models.DeliveryReceipt, error)
+}
+
 // DispatcherRegistry manages all available dispatchers
 type DispatcherRegistry struct {
-	dispatchers       map[models.DestinationType]Dispatcher
-	reminderErrorRepo repositories.ReminderErrorRepository
+	dispatchers          map[models.DestinationType]Dispatcher
+	reminderErrorRepo    repositories.ReminderErrorRepository
+	reminderDeliveryRepo repositories.ReminderDeliveryRepository
+	reminderDispatchRepo repositories.ReminderDispatchRepository
+	retryQueue           *RetryQueue
+	logger               *slog.Logger
+}
+
+// fireContext returns ctx with the correlation ID a fire of a reminder is logged with:
+// the idempotency key of the fire, shared by the scheduler, the dispatcher, the retry
+// queue and the providers receiving the key.
+func fireContext(ctx context.Context, reminderID uuid.UUID, scheduledAt time.Time) context.Context {
+	return logging.WithCorrelationID(ctx, dispatchers.IdempotencyKey(reminderID, scheduledAt))
 }
 
 // NewDispatcherRegistry creates a new dispatcher registry
-func NewDispatcherRegistry(reminderErrorRepo repositories.ReminderErrorRepository) *DispatcherRegistry {
+func NewDispatcherRegistry(reminderErrorRepo repositories.ReminderErrorRepository, reminderDeliveryRepo repositories.ReminderDeliveryRepository) *DispatcherRegistry {
 	return &DispatcherRegistry{
-		dispatchers:       make(map[models.DestinationType]Dispatcher),
-		reminderErrorRepo: reminderErrorRepo,
+		dispatchers:          make(map[models.DestinationType]Dispatcher),
+		reminderErrorRepo:    reminderErrorRepo,
+		reminderDeliveryRepo: reminderDeliveryRepo,
+		logger:               logging.For(logging.Dispatcher),
 	}
 }
 
+// SetLogger replaces the logger of the registry
+func (dr *DispatcherRegistry) SetLogger(logger *slog.Logger) {
+	dr.logger = logger
+}
+
+// SetRetryQueue sets the queue transient failures are handed to.
+// Without a retry queue every failure is recorded as an error right away.
+func (dr *DispatcherRegistry) SetRetryQueue(retryQueue *RetryQueue) {
+	dr.retryQueue = retryQueue
+}
+
+// SetDispatchRepository sets the outbox written before each fire goes out.
+// Without it a fire interrupted by a crash may be sent again in full.
+func (dr *DispatcherRegistry) SetDispatchRepository(reminderDispatchRepo repositories.ReminderDispatchRepository) {
+	dr.reminderDispatchRepo = reminderDispatchRepo
+}
+
 // RegisterDispatcher registers a new dispatcher for a specific destination type
 func (dr *DispatcherRegistry) RegisterDispatcher(dispatcher Dispatcher) {
 	dr.dispatchers[dispatcher.GetSupportedType()] = dispatcher
 }
 
 // DispatchReminder dispatches a reminder to all its destinations
-func (dr *DispatcherRegistry) DispatchReminder(reminder *models.Reminder) error {
-	if len(reminder.Destinations) == 0 {
-		return fmt.Errorf("reminder %s has no destinations", reminder.ID)
-	}
-
-	var errors []error
-	for _, destination := range reminder.Destinations {
-		dispatcher, exists := dr.dispatchers[destination.Type]
-		if !exists {
-			log.Printf("[DISPATCHER] - No dispatcher found for type %s, skipping", destination.Type)
-			
-			// Create error record for missing dispatcher
-			dr.createErrorRecord(reminder.ID, destination.ID, fmt.Sprintf("No dispatcher found for type %s", destination.Type))
-			continue
-		}
+func (dr *DispatcherRegistry) DispatchReminder(ctx context.Context, reminder *models.Reminder) error {
+	_, err := dr.DispatchReminderTo(ctx, reminder, reminder.Destinations)
+	return err
+}
 
-		if err := dispatcher.Dispatch(reminder, &destination, reminder.Account); err != nil {
-			log.Printf("[DISPATCHER] - Error dispatching to %s: %v", destination.Type, err)
-			errors = append(errors, err)
-			
-			// Create error record for dispatch failure
-			stackTrace := fmt.Sprintf("Dispatch error: %v\nStack trace:\n%s", err, string(debug.Stack()))
-			dr.createErrorRecord(reminder.ID, destination.ID, stackTrace)
-			
-			continue
+// DispatchReminderTo dispatches a reminder to the given subset of its destinations.
+// Each destination fails on its own: it returns how many destinations were reached
+// (or handed to the retry queue) along with an error when at least one failed. Each
+// delivery is traced as a child span of ctx. A destination that already failed this
+// fire is sent to again: the scheduler only passes it once its errors were fixed.
+func (dr *DispatcherRegistry) DispatchReminderTo(ctx context.Context, reminder *models.Reminder, destinations []models.ReminderDestination) (int, error) {
+	if len(destinations) == 0 {
+		return 0, fmt.Errorf("reminder %s has no destinations", reminder.ID)
+	}
+
+	// Capture the targeted fire time before dispatchers get a chance to touch the reminder
+	scheduledAt := reminder.RemindAtUTC
+	if reminder.NextFireUTC != nil {
+		scheduledAt = *reminder.NextFireUTC
+	}
+
+	// Write the outbox before anything goes out: a fire resumed after a crash skips the
+	// destinations it already reached and sends to the others with the same key
+	outbox, err := dr.beginDispatch(ctx, reminder, destinations, scheduledAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write the dispatch outbox of reminder %s: %w", reminder.ID, err)
+	}
+
+	failed := 0
+	for i := range destinations {
+		attempt := 1
+		if entry, exists := outbox[destinations[i].ID]; exists {
+			if entry.Status == models.DispatchStatusSent || dr.retryQueue.Contains(reminder.ID, destinations[i].ID, scheduledAt) {
+				dr.logger.InfoContext(fireContext(ctx, reminder.ID, scheduledAt), "Reminder already handled for destination, skipping", "reminder_id", reminder.ID, "destination_id", destinations[i].ID)
+				continue
+			}
+			if entry.Status == models.DispatchStatusFailed {
+				dr.markDispatch(reminder.ID, destinations[i].ID, scheduledAt, models.DispatchStatusPending, entry.Attempts)
+			}
+			attempt = entry.Attempts + 1
 		}
 
-		if config.IsDebugMode() {
-			log.Printf("[DISPATCHER] - [DEBUG] Dispatched reminder %s to %s", reminder.ID, destination.Type)
+		if err := dr.dispatchToDestination(ctx, reminder, &destinations[i], scheduledAt, attempt); err != nil {
+			failed++
 		}
 	}
 
-	if len(errors) > 0 {
-		return fmt.Errorf("failed to dispatch to %d destinations", len(errors))
+	if failed > 0 {
+		return len(destinations) - failed, fmt.Errorf("failed to dispatch to %d destinations", failed)
+	}
+
+	return len(destinations), nil
+}
+
+// dispatchToDestination makes one delivery attempt of a reminder to a single destination.
+// Transient failures are handed to the retry queue while attempts remain and do not
+// count as failures; anything else creates an error record and is returned.
+func (dr *DispatcherRegistry) dispatchToDestination(ctx context.Context, reminder *models.Reminder, destination *models.ReminderDestination, scheduledAt time.Time, attempt int) (err error) {
+	ctx, span := tracing.Start(fireContext(ctx, reminder.ID, scheduledAt), "dispatch "+string(destination.Type), trace.WithAttributes(
+		attribute.String("reminder_id", reminder.ID.String()),
+		attribute.String("destination_id", destination.ID.String()),
+		attribute.String("destination_type", string(destination.Type)),
+		attribute.Int("attempt", attempt),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	logger := dr.logger.With("reminder_id", reminder.ID, "destination_id", destination.ID, "destination_type", destination.Type, "attempt", attempt)
+
+	dispatcher, exists := dr.dispatchers[destination.Type]
+	if !exists {
+		logger.ErrorContext(ctx, "No dispatcher found for the destination type, skipping")
+
+		// Create error record for missing dispatcher
+		dr.createErrorRecord(reminder.ID, destination.ID, fmt.Sprintf("No dispatcher found for type %s", destination.Type))
+		metrics.ObserveDispatch(string(destination.Type), metrics.OutcomeFailure, 0)
+		dr.markDispatch(reminder.ID, destination.ID, scheduledAt, models.DispatchStatusFailed, attempt)
+		return fmt.Errorf("no dispatcher found for type %s", destination.Type)
+	}
+
+	startedAt := time.Now()
+	var receipt *models.DeliveryReceipt
+	if idempotentDispatcher, ok := dispatcher.(IdempotentDispatcher); ok {
+		receipt, err = idempotentDispatcher.DispatchWithKey(ctx, reminder, destination, reminder.Account, dispatchers.IdempotencyKey(reminder.ID, scheduledAt))
+	} else {
+		receipt, err = dispatcher.Dispatch(ctx, reminder, destination, reminder.Account)
 	}
+	duration := time.Since(startedAt)
+	dr.recordDelivery(ctx, reminder, destination, scheduledAt, startedAt, attempt, receipt, err)
+
+	if err != nil {
+		errorClass := ClassifyDispatchError(err)
+		logger.WarnContext(ctx, "Error dispatching reminder", "error_class", errorClass.String(), "error", err, "duration", duration)
+
+		if errorClass == ErrorClassTransient && dr.retryQueue != nil && dr.retryQueue.Schedule(reminder.ID, destination.ID, scheduledAt, attempt, err) {
+			metrics.ObserveDispatch(string(destination.Type), metrics.OutcomeRetried, duration)
+			dr.markDispatch(reminder.ID, destination.ID, scheduledAt, models.DispatchStatusPending, attempt)
+			// The attempt still failed, the span says so even though the fire goes on
+			tracing.RecordError(span, err)
+			return nil
+		}
+		metrics.ObserveDispatch(string(destination.Type), metrics.OutcomeFailure, duration)
+
+		// Create error record for dispatch failure
+		stackTrace := fmt.Sprintf("Dispatch error (attempt %d, %s): %v\nStack trace:\n%s", attempt, errorClass, err, string(debug.Stack()))
+		dr.createErrorRecord(reminder.ID, destination.ID, stackTrace)
+		dr.markDispatch(reminder.ID, destination.ID, scheduledAt, models.DispatchStatusFailed, attempt)
+
+		return err
+	}
+
+	metrics.ObserveDispatch(string(destination.Type), metrics.OutcomeSuccess, duration)
+	dr.markDispatch(reminder.ID, destination.ID, scheduledAt, models.DispatchStatusSent, attempt)
+
+	logger.DebugContext(ctx, "Dispatched reminder", "duration", duration)
 
 	return nil
 }
@@ -86,7 +210,7 @@ func (dr *DispatcherRegistry) GetDispatcher(destinationType models.DestinationTy
 // createErrorRecord creates a reminder error record
 func (dr *DispatcherRegistry) createErrorRecord(reminderID, destinationID uuid.UUID, stacktrace string) {
 	if dr.reminderErrorRepo == nil {
-		log.Printf("[DISPATCHER] - Warning: Cannot create error record - reminder error repository is nil")
+		dr.logger.Warn("Cannot create error record: reminder error repository is nil", "reminder_id", reminderID, "destination_id", destinationID)
 		return
 	}
 
@@ -99,8 +223,50 @@ func (dr *DispatcherRegistry) createErrorRecord(reminderID, destinationID uuid.U
 	}
 
 	if err := dr.reminderErrorRepo.Create(reminderError); err != nil {
-		log.Printf("[DISPATCHER] - Error creating reminder error record: %v", err)
-	} else if config.IsDebugMode() {
-		log.Printf("[DISPATCHER] - [DEBUG] Created error record for reminder %s, destination %s", reminderID, destinationID)
+		dr.logger.Error("Error creating reminder error record", "reminder_id", reminderID, "destination_id", destinationID, "error", err)
+	} else {
+		dr.logger.Debug("Created error record", "reminder_id", reminderID, "destination_id", destinationID)
 	}
-}
\ No newline at end of file
+}
+
+// recordDelivery stores the outcome of a single delivery attempt in the delivery history
+func (dr *DispatcherRegistry) recordDelivery(ctx context.Context, reminder *models.Reminder, destination *models.ReminderDestination, scheduledAt, startedAt time.Time, attempt int, receipt *models.DeliveryReceipt, dispatchErr error) {
+	if dr.reminderDeliveryRepo == nil {
+		return
+	}
+
+	sentAt := time.Now()
+	delivery := &models.ReminderDelivery{
+		ReminderID:            reminder.ID,
+		ReminderDestinationID: destination.ID,
+		AccountID:             reminder.AccountID,
+		DestinationType:       destination.Type,
+		ScheduledAt:           scheduledAt,
+		SentAt:                sentAt,
+		LatencyMs:             sentAt.Sub(scheduledAt).Milliseconds(),
+		DurationMs:            sentAt.Sub(startedAt).Milliseconds(),
+		Attempt:               attempt,
+		Outcome:               models.DeliveryOutcomeSuccess,
+	}
+
+	if receipt != nil {
+		if receipt.ResponseCode != 0 {
+			code := receipt.ResponseCode
+			delivery.ResponseCode = &code
+		}
+		if receipt.MessageID != "" {
+			messageID := receipt.MessageID
+			delivery.MessageID = &messageID
+		}
+	}
+
+	if dispatchErr != nil {
+		errMsg := dispatchErr.Error()
+		delivery.Outcome = models.DeliveryOutcomeFailure
+		delivery.Error = &errMsg
+	}
+
+	if err := dr.reminderDeliveryRepo.Create(delivery); err != nil {
+		dr.logger.ErrorContext(ctx, "Error recording delivery", "reminder_id", reminder.ID, "destination_id", destination.ID, "error", err)
+	}
+}
@@ -2,27 +2,33 @@ package engine
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"time"
 
-	"github.com/ericp/chronos-bot-reminder/internal/config"
 	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/ericp/chronos-bot-reminder/internal/metrics"
 	"github.com/google/uuid"
 )
 
 const (
 	GarbageCollectionDelay = 30 * time.Minute
+	DeliveryPurgeInterval  = time.Hour
 )
 
 // GarbageCollector manages the delayed deletion of dispatched one-time reminders
+// and the purge of the delivery history past its retention
 type GarbageCollector struct {
-	reminderRepo repositories.ReminderRepository
-	stopChan     chan struct{}
+	reminderRepo      repositories.ReminderRepository
+	deliveryRepo      repositories.ReminderDeliveryRepository
+	deliveryRetention time.Duration
+	stopChan          chan struct{}
 	updateChan   chan uuid.UUID // Reminder ID that was updated/snoozed
 	addChan      chan uuid.UUID // Reminder ID to add to deletion queue
 	running      bool
 	currentTimer *time.Timer
 	ctx          context.Context
+	logger       *slog.Logger
 }
 
 // NewGarbageCollector creates a new garbage collector instance
@@ -34,23 +40,37 @@ func NewGarbageCollector(reminderRepo repositories.ReminderRepository) *GarbageC
 		addChan:      make(chan uuid.UUID, 100),
 		running:      false,
 		currentTimer: nil,
+		logger:       logging.For(logging.Engine),
 	}
 }
 
+// SetLogger replaces the logger of the garbage collector
+func (gc *GarbageCollector) SetLogger(logger *slog.Logger) {
+	gc.logger = logger
+}
+
+// SetDeliveryRetention makes the garbage collector delete the deliveries older than
+// the retention. Without it, or with a retention <= 0, the history is kept forever.
+func (gc *GarbageCollector) SetDeliveryRetention(deliveryRepo repositories.ReminderDeliveryRepository, retention time.Duration) {
+	gc.deliveryRepo = deliveryRepo
+	gc.deliveryRetention = retention
+}
+
 // Start begins the garbage collector's main loop
 func (gc *GarbageCollector) Start(ctx context.Context) {
 	if gc.running {
-		log.Println("[ENGINE] - Garbage collector already running")
+		gc.logger.Info("Garbage collector already running")
 		return
 	}
 
+	gc.stopChan = make(chan struct{})
 	gc.running = true
 	gc.ctx = ctx
 
 	// Start the main collection loop
-	go gc.collectionLoop(ctx)
+	go gc.collectionLoop(ctx, gc.stopChan)
 
-	log.Println("[ENGINE] - ✅ Garbage collector started")
+	gc.logger.Info("Garbage collector started")
 }
 
 // Stop gracefully stops the garbage collector
@@ -66,7 +86,7 @@ func (gc *GarbageCollector) Stop() {
 
 	close(gc.stopChan)
 	gc.running = false
-	log.Println("[ENGINE] - Garbage collector stopped")
+	gc.logger.Info("Garbage collector stopped")
 }
 
 // IsRunning returns whether the garbage collector is currently running
@@ -82,11 +102,9 @@ func (gc *GarbageCollector) NotifyReminderDispatched(reminderID uuid.UUID) {
 
 	select {
 	case gc.addChan <- reminderID:
-		if config.IsDebugMode() {
-			log.Printf("[ENGINE] - Reminder %s added to deletion queue", reminderID)
-		}
+		gc.logger.Debug("Reminder added to deletion queue", "reminder_id", reminderID)
 	default:
-		log.Printf("[ENGINE] - Add channel full, skipping reminder %s", reminderID)
+		gc.logger.Warn("Add channel full, skipping reminder", "reminder_id", reminderID)
 	}
 }
 
@@ -99,49 +117,63 @@ func (gc *GarbageCollector) NotifyReminderUpdated(reminderID uuid.UUID) {
 
 	select {
 	case gc.updateChan <- reminderID:
-		if config.IsDebugMode() {
-			log.Printf("[ENGINE] - Reminder %s updated, checking deletion queue", reminderID)
-		}
+		gc.logger.Debug("Reminder updated, checking deletion queue", "reminder_id", reminderID)
 	default:
-		log.Printf("[ENGINE] - Update channel full, skipping update for reminder %s", reminderID)
+		gc.logger.Warn("Update channel full, skipping update for reminder", "reminder_id", reminderID)
 	}
 }
 
 // collectionLoop is the main loop that manages the deletion queue
-func (gc *GarbageCollector) collectionLoop(ctx context.Context) {
+func (gc *GarbageCollector) collectionLoop(ctx context.Context, stopChan <-chan struct{}) {
 	// Initial schedule setup
 	gc.scheduleNext()
 
+	// The delivery history is purged on start, then on a fixed interval
+	var purgeChan <-chan time.Time
+	if gc.deliveryRepo != nil && gc.deliveryRetention > 0 {
+		gc.purgeDeliveries()
+		purgeTicker := time.NewTicker(DeliveryPurgeInterval)
+		defer purgeTicker.Stop()
+		purgeChan = purgeTicker.C
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("[ENGINE] - Context cancelled, stopping garbage collector")
+			gc.logger.Info("Context cancelled, stopping garbage collector")
 			gc.running = false
 			return
-		case <-gc.stopChan:
-			gc.running = false
+		case <-stopChan:
 			return
 		case <-gc.addChan:
 			// New reminder added to queue, reschedule
-			if config.IsDebugMode() {
-				log.Println("[ENGINE] - Reminder added, rescheduling...")
-			}
+			gc.logger.Debug("Reminder added, rescheduling")
 			gc.scheduleNext()
 		case <-gc.updateChan:
 			// Reminder was updated, reschedule to check if it should still be deleted
-			if config.IsDebugMode() {
-				log.Println("[ENGINE] - Reminder updated, rescheduling...")
-			}
+			gc.logger.Debug("Reminder updated, rescheduling")
 			gc.scheduleNext()
 		case <-gc.getTimerChan():
 			// Timer fired, process deletions
 			gc.checkAndDeleteReminders()
 			// Schedule the next batch
 			gc.scheduleNext()
+		case <-purgeChan:
+			gc.purgeDeliveries()
 		}
 	}
 }
 
+// purgeDeliveries deletes the deliveries sent before the retention
+func (gc *GarbageCollector) purgeDeliveries() {
+	before := time.Now().UTC().Add(-gc.deliveryRetention)
+	if err := gc.deliveryRepo.DeleteOlderThan(before); err != nil {
+		gc.logger.Error("Error purging the delivery history", "error", err)
+		return
+	}
+	gc.logger.Debug("Purged the delivery history", "before", before)
+}
+
 // getTimerChan returns the timer channel or a nil channel if no timer is set
 func (gc *GarbageCollector) getTimerChan() <-chan time.Time {
 	if gc.currentTimer != nil {
@@ -161,14 +193,12 @@ func (gc *GarbageCollector) scheduleNext() {
 	// Get reminders ready to be deleted
 	remindersToDelete, err := gc.reminderRepo.GetNextsRemindersToDelete()
 	if err != nil {
-		log.Printf("[ENGINE] - Error fetching reminders to delete: %v", err)
+		gc.logger.Error("Error fetching reminders to delete", "error", err)
 		return
 	}
 
 	if len(remindersToDelete) == 0 {
-		if config.IsDebugMode() {
-			log.Println("[ENGINE] - No reminders pending deletion")
-		}
+		gc.logger.Debug("No reminders pending deletion")
 		return
 	}
 
@@ -188,18 +218,14 @@ func (gc *GarbageCollector) scheduleNext() {
 	}
 
 	if nextDeletionTime == nil {
-		if config.IsDebugMode() {
-			log.Println("[ENGINE] - No valid deletion times found")
-		}
+		gc.logger.Debug("No valid deletion times found")
 		return
 	}
 
 	duration := nextDeletionTime.Sub(now)
 	if duration <= 0 {
 		// Ready to delete now
-		if config.IsDebugMode() {
-			log.Println("[ENGINE] - Reminders ready for deletion, processing immediately")
-		}
+		gc.logger.Debug("Reminders ready for deletion, processing immediately")
 		go func() {
 			time.Sleep(10 * time.Millisecond)
 			select {
@@ -213,9 +239,7 @@ func (gc *GarbageCollector) scheduleNext() {
 		return
 	}
 
-	if config.IsDebugMode() {
-		log.Printf("[ENGINE] - Next deletion scheduled in %v", duration)
-	}
+	gc.logger.Debug("Next deletion scheduled", "in", duration)
 
 	gc.currentTimer = time.NewTimer(duration)
 }
@@ -224,7 +248,7 @@ func (gc *GarbageCollector) scheduleNext() {
 func (gc *GarbageCollector) checkAndDeleteReminders() {
 	remindersToDelete, err := gc.reminderRepo.GetNextsRemindersToDelete()
 	if err != nil {
-		log.Printf("[ENGINE] - Error fetching reminders to delete: %v", err)
+		gc.logger.Error("Error fetching reminders to delete", "error", err)
 		return
 	}
 
@@ -246,19 +270,17 @@ func (gc *GarbageCollector) checkAndDeleteReminders() {
 			// Delete the reminder (don't notify scheduler to avoid circular updates)
 			err := gc.reminderRepo.Delete(reminder.ID, false)
 			if err != nil {
-				log.Printf("[ENGINE] - Error deleting reminder %s: %v", reminder.ID, err)
+				gc.logger.Error("Error deleting reminder", "reminder_id", reminder.ID, "error", err)
 				continue
 			}
 
-			if config.IsDebugMode() {
-				log.Printf("[ENGINE] - Deleted one-time reminder %s",
-					reminder.ID)
-			}
+			gc.logger.Info("Deleted dispatched one-time reminder", "reminder_id", reminder.ID)
 			deletedCount++
+			metrics.IncGarbageCollectorDeletions()
 		}
 	}
 
-	if deletedCount > 0 && config.IsDebugMode() {
-		log.Printf("[ENGINE] - Deleted %d one-time reminder(s)", deletedCount)
+	if deletedCount > 0 {
+		gc.logger.Debug("Deleted one-time reminders", "count", deletedCount)
 	}
 }
\ No newline at end of file
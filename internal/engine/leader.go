@@ -0,0 +1,271 @@
+package engine
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/config"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/redis/go-redis/v9"
+)
+
+// schedulerLeaderKey is the Redis key holding the ID of the instance running the engine
+const schedulerLeaderKey = "scheduler:leader"
+
+// defaultLeaderTTL is the lease duration used when the configured one is too short
+const defaultLeaderTTL = 15 * time.Second
+
+// LeaderLock is a lease that at most one instance holds at a time. A holder that
+// stops renewing it loses it once the TTL expires, which lets another instance take over.
+type LeaderLock interface {
+	// Acquire takes the lease for ttl when it is free, or extends it when instanceID already holds it
+	Acquire(ctx context.Context, instanceID string, ttl time.Duration) (bool, error)
+	// Release frees the lease if instanceID holds it
+	Release(ctx context.Context, instanceID string) error
+}
+
+// acquireLeaderScript extends the lease of its holder or takes a free one atomically
+var acquireLeaderScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return 1
+end
+return 0
+`)
+
+// releaseLeaderScript deletes the lease only when it still belongs to the caller
+var releaseLeaderScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisLeaderLock stores the lease in Redis
+type RedisLeaderLock struct {
+	client *redis.Client
+	key    string
+}
+
+// NewRedisLeaderLock creates a leader lock on the given Redis client
+func NewRedisLeaderLock(client *redis.Client) *RedisLeaderLock {
+	return &RedisLeaderLock{client: client, key: schedulerLeaderKey}
+}
+
+// Acquire takes or extends the lease
+func (l *RedisLeaderLock) Acquire(ctx context.Context, instanceID string, ttl time.Duration) (bool, error) {
+	if l.client == nil {
+		return false, fmt.Errorf("redis is not initialized")
+	}
+	result, err := acquireLeaderScript.Run(ctx, l.client, []string{l.key}, instanceID, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return result == 1, nil
+}
+
+// Release frees the lease so another instance can take over without waiting for the TTL
+func (l *RedisLeaderLock) Release(ctx context.Context, instanceID string) error {
+	if l.client == nil {
+		return fmt.Errorf("redis is not initialized")
+	}
+	return releaseLeaderScript.Run(ctx, l.client, []string{l.key}, instanceID).Err()
+}
+
+// LeaderElector campaigns for the leader lock and runs the engine while it holds it.
+// The lease is renewed every third of its TTL. The leader steps down as soon as a
+// renewal is refused, or after two failed renewals while its lease still runs, and
+// its engine stops once the deliveries in flight are done. Only a delivery outlasting
+// the rest of the lease can overlap the next leader, which sends it with the same
+// idempotency key.
+type LeaderElector struct {
+	lock       LeaderLock
+	instanceID string
+	ttl        time.Duration
+	onElected  func()
+	onDemoted  func()
+	mutex      sync.Mutex
+	leader     bool
+	leaseUntil time.Time
+	stopChan   chan struct{}
+	doneChan   chan struct{}
+	running    bool
+	logger     *slog.Logger
+}
+
+// NewLeaderElector creates an elector calling onElected when the instance becomes
+// the leader and onDemoted when it stops being the leader
+func NewLeaderElector(lock LeaderLock, instanceID string, ttl time.Duration, onElected, onDemoted func()) *LeaderElector {
+	return &LeaderElector{
+		lock:       lock,
+		instanceID: instanceID,
+		ttl:        ttl,
+		onElected:  onElected,
+		onDemoted:  onDemoted,
+		logger:     logging.For(logging.Engine).With("instance_id", instanceID),
+	}
+}
+
+// SetLogger replaces the logger of the elector
+func (e *LeaderElector) SetLogger(logger *slog.Logger) {
+	e.logger = logger
+}
+
+// InstanceID returns the ID the elector campaigns with
+func (e *LeaderElector) InstanceID() string {
+	return e.instanceID
+}
+
+// Start begins campaigning for the leader lock
+func (e *LeaderElector) Start(ctx context.Context) {
+	if e.running {
+		e.logger.Info("Leader elector already running")
+		return
+	}
+
+	e.stopChan = make(chan struct{})
+	e.doneChan = make(chan struct{})
+	e.running = true
+	go e.campaignLoop(ctx, e.stopChan, e.doneChan)
+
+	e.logger.Info("Leader elector started")
+}
+
+// Stop steps down and releases the lock so another instance takes over right away.
+// It returns once the engine is stopped.
+func (e *LeaderElector) Stop() {
+	if !e.running {
+		return
+	}
+
+	close(e.stopChan)
+	<-e.doneChan
+	e.running = false
+	e.logger.Info("Leader elector stopped")
+}
+
+// IsRunning returns whether the elector is campaigning
+func (e *LeaderElector) IsRunning() bool {
+	return e.running
+}
+
+// IsLeader returns whether the instance holds a lease that has not run out
+func (e *LeaderElector) IsLeader() bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.leader && time.Now().Before(e.leaseUntil)
+}
+
+// campaignLoop tries to acquire or renew the lease until stopped
+func (e *LeaderElector) campaignLoop(ctx context.Context, stopChan <-chan struct{}, doneChan chan<- struct{}) {
+	defer close(doneChan)
+
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	e.campaign(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			e.stepDown(context.Background())
+			return
+		case <-stopChan:
+			e.stepDown(ctx)
+			return
+		case <-ticker.C:
+			e.campaign(ctx)
+		}
+	}
+}
+
+// campaign acquires or renews the lease and starts or stops the engine accordingly
+func (e *LeaderElector) campaign(ctx context.Context) {
+	attemptedAt := time.Now()
+	acquired, err := e.lock.Acquire(ctx, e.instanceID, e.ttl)
+
+	e.mutex.Lock()
+	wasLeader := e.leader
+	switch {
+	case err != nil:
+		// Redis may only be briefly unreachable: keep leading while the lease lasts,
+		// but step down once less than half of it is left so the next leader never overlaps
+		e.logger.Warn("Error renewing the leader lock", "error", err)
+		if wasLeader && e.leaseUntil.Sub(attemptedAt) < e.ttl/2 {
+			e.leader = false
+		}
+	case acquired:
+		e.leader = true
+		e.leaseUntil = attemptedAt.Add(e.ttl)
+	default:
+		e.leader = false
+	}
+	isLeader := e.leader
+	e.mutex.Unlock()
+
+	switch {
+	case isLeader && !wasLeader:
+		e.logger.Info("Instance elected leader")
+		if e.onElected != nil {
+			e.onElected()
+		}
+	case !isLeader && wasLeader:
+		e.logger.Warn("Instance lost the leadership")
+		if e.onDemoted != nil {
+			e.onDemoted()
+		}
+	}
+}
+
+// stepDown stops the engine and releases the lease if the instance is the leader
+func (e *LeaderElector) stepDown(ctx context.Context) {
+	e.mutex.Lock()
+	wasLeader := e.leader
+	e.leader = false
+	e.mutex.Unlock()
+
+	if !wasLeader {
+		return
+	}
+
+	if e.onDemoted != nil {
+		e.onDemoted()
+	}
+	if err := e.lock.Release(ctx, e.instanceID); err != nil {
+		e.logger.Warn("Error releasing the leader lock", "error", err)
+	}
+	e.logger.Info("Instance stepped down")
+}
+
+// LeaderTTLFromConfig returns the lease duration of the leader lock
+func LeaderTTLFromConfig(cfg *config.Config) time.Duration {
+	ttl := time.Duration(cfg.SchedulerLeaderTTLSeconds) * time.Second
+	if ttl < 3*time.Second {
+		return defaultLeaderTTL
+	}
+	return ttl
+}
+
+// NewInstanceID returns the configured instance ID, or the host name with a random
+// suffix so that replicas sharing a host name stay distinct
+func NewInstanceID(configured string) string {
+	if configured != "" {
+		return configured
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "chronos"
+	}
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%s", hostname, hex.EncodeToString(suffix))
+}
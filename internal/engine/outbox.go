@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/dispatchers"
+	"github.com/google/uuid"
+)
+
+// outboxRetention is how long the outbox entries are kept once written
+const outboxRetention = 7 * 24 * time.Hour
+
+// beginDispatch writes the pending outbox entries of a fire and returns the stored
+// entries by destination. Without an outbox repository nothing is tracked.
+func (dr *DispatcherRegistry) beginDispatch(ctx context.Context, reminder *models.Reminder, destinations []models.ReminderDestination, scheduledAt time.Time) (map[uuid.UUID]models.ReminderDispatch, error) {
+	if dr.reminderDispatchRepo == nil {
+		return nil, nil
+	}
+
+	key := dispatchers.IdempotencyKey(reminder.ID, scheduledAt)
+	pending := make([]models.ReminderDispatch, len(destinations))
+	for i := range destinations {
+		pending[i] = models.ReminderDispatch{
+			IdempotencyKey:        key,
+			ReminderID:            reminder.ID,
+			ReminderDestinationID: destinations[i].ID,
+			ScheduledAt:           scheduledAt,
+			Status:                models.DispatchStatusPending,
+		}
+	}
+
+	stored, err := dr.reminderDispatchRepo.CreatePending(pending)
+	if err != nil {
+		dr.logger.ErrorContext(fireContext(ctx, reminder.ID, scheduledAt), "Error writing dispatch outbox", "reminder_id", reminder.ID, "error", err)
+		return nil, err
+	}
+
+	outbox := make(map[uuid.UUID]models.ReminderDispatch, len(stored))
+	for _, entry := range stored {
+		outbox[entry.ReminderDestinationID] = entry
+	}
+	return outbox, nil
+}
+
+// markDispatch records the outcome of an attempt in the outbox. A failure only means
+// the delivery may be sent again with the same key if the engine restarts.
+func (dr *DispatcherRegistry) markDispatch(reminderID, destinationID uuid.UUID, scheduledAt time.Time, status models.DispatchStatus, attempts int) {
+	if dr.reminderDispatchRepo == nil {
+		return
+	}
+
+	key := dispatchers.IdempotencyKey(reminderID, scheduledAt)
+	if err := dr.reminderDispatchRepo.UpdateStatus(key, destinationID, status, attempts); err != nil {
+		dr.logger.ErrorContext(fireContext(context.Background(), reminderID, scheduledAt), "Error marking dispatch", "reminder_id", reminderID, "destination_id", destinationID, "status", status, "error", err)
+	}
+}
+
+// RecoverPendingDispatches resumes the deliveries left pending by a crash or by the
+// previous leader, it runs when the engine starts. A reminder still due for the
+// fire is left to the scheduler, which skips the destinations already reached. A
+// reminder that moved on lost its delivery mid-send or in the retry queue: the
+// delivery is queued again with the key of its fire.
+func (q *RetryQueue) RecoverPendingDispatches() {
+	outboxRepo := q.registry.reminderDispatchRepo
+	if outboxRepo == nil {
+		return
+	}
+
+	if err := outboxRepo.DeleteOlderThan(time.Now().Add(-outboxRetention)); err != nil {
+		q.logger.Error("Error purging the dispatch outbox", "error", err)
+	}
+
+	pending, err := outboxRepo.GetPending()
+	if err != nil {
+		q.logger.Error("Error fetching pending dispatches", "error", err)
+		return
+	}
+
+	resumed := 0
+	for _, entry := range pending {
+		reminder, err := q.reminderRepo.GetByID(entry.ReminderID)
+		if err != nil {
+			q.logger.ErrorContext(fireContext(context.Background(), entry.ReminderID, entry.ScheduledAt), "Error loading reminder of pending dispatch", "reminder_id", entry.ReminderID, "error", err)
+			continue
+		}
+
+		if reminder == nil {
+			q.registry.markDispatch(entry.ReminderID, entry.ReminderDestinationID, entry.ScheduledAt, models.DispatchStatusFailed, entry.Attempts)
+			continue
+		}
+
+		if reminder.NextFireUTC != nil && reminder.NextFireUTC.Equal(entry.ScheduledAt) {
+			continue
+		}
+
+		if q.Contains(entry.ReminderID, entry.ReminderDestinationID, entry.ScheduledAt) {
+			continue
+		}
+
+		if !q.Schedule(entry.ReminderID, entry.ReminderDestinationID, entry.ScheduledAt, entry.Attempts, nil) {
+			q.registry.createErrorRecord(entry.ReminderID, entry.ReminderDestinationID,
+				fmt.Sprintf("Delivery interrupted after %d attempts, no attempt left", entry.Attempts))
+			q.registry.markDispatch(entry.ReminderID, entry.ReminderDestinationID, entry.ScheduledAt, models.DispatchStatusFailed, entry.Attempts)
+			continue
+		}
+		resumed++
+	}
+
+	level := slog.LevelDebug
+	if resumed > 0 {
+		level = slog.LevelInfo
+	}
+	q.logger.Log(context.Background(), level, "Resumed interrupted deliveries", "resumed", resumed, "pending", len(pending))
+}
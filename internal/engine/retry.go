@@ -0,0 +1,371 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/config"
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/dispatchers"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/ericp/chronos-bot-reminder/internal/tracing"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrorClass tells the engine what to do with a failed delivery
+type ErrorClass int
+
+const (
+	// ErrorClassPermanent failures need a human (bad URL, deleted channel...) and block the destination
+	ErrorClassPermanent ErrorClass = iota
+	// ErrorClassTransient failures are expected to clear up by themselves and are retried
+	ErrorClassTransient
+)
+
+// String returns the class name used in logs
+func (c ErrorClass) String() string {
+	if c == ErrorClassTransient {
+		return "transient"
+	}
+	return "permanent"
+}
+
+// ClassifyDispatchError decides whether a dispatch error is worth retrying.
+// Dispatchers flag what they know to be temporary (webhook 5xx, Discord rate
+// limits, FCM outages...) with dispatchers.TransientError; network timeouts are
+// treated as transient whatever the dispatcher. Everything else is permanent.
+func ClassifyDispatchError(err error) ErrorClass {
+	if err == nil {
+		return ErrorClassPermanent
+	}
+
+	if _, ok := dispatchers.AsTransient(err); ok {
+		return ErrorClassTransient
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassTransient
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorClassTransient
+	}
+
+	return ErrorClassPermanent
+}
+
+// RetryPolicy describes how transient failures are retried
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts, the first delivery included
+	BaseDelay   time.Duration // wait before the second attempt
+	MaxDelay    time.Duration // upper bound of a single wait
+}
+
+// NewRetryPolicyFromConfig builds the retry policy from the application configuration
+func NewRetryPolicyFromConfig(cfg *config.Config) RetryPolicy {
+	policy := RetryPolicy{
+		MaxAttempts: cfg.DispatchRetryMaxAttempts,
+		BaseDelay:   time.Duration(cfg.DispatchRetryBaseDelaySeconds) * time.Second,
+		MaxDelay:    time.Duration(cfg.DispatchRetryMaxDelaySeconds) * time.Second,
+	}
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = 30 * time.Second
+	}
+	if policy.MaxDelay < policy.BaseDelay {
+		policy.MaxDelay = policy.BaseDelay
+	}
+	return policy
+}
+
+// CanRetry reports whether a delivery that just failed at the given attempt may be tried again
+func (p RetryPolicy) CanRetry(attempt int) bool {
+	return attempt < p.MaxAttempts
+}
+
+// Backoff returns the wait after the given failed attempt: BaseDelay doubled at
+// each attempt (1 -> BaseDelay, 2 -> 2*BaseDelay, ...) and capped to MaxDelay
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay || delay <= 0 {
+			return p.MaxDelay
+		}
+	}
+
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// retryEntry is a delivery waiting to be attempted again
+type retryEntry struct {
+	ReminderID    uuid.UUID
+	DestinationID uuid.UUID
+	ScheduledAt   time.Time // original fire time, kept for the delivery history
+	Attempt       int       // attempts already made
+	DueAt         time.Time
+}
+
+// RetryQueue holds deliveries that failed transiently and attempts them again
+// once their backoff expires. The queue lives in memory: the retries lost on a
+// restart are queued again from the dispatch outbox when the engine starts.
+type RetryQueue struct {
+	reminderRepo repositories.ReminderRepository
+	registry     *DispatcherRegistry
+	policy       RetryPolicy
+	mutex        sync.Mutex
+	entries      []retryEntry
+	stopChan     chan struct{}
+	doneChan     chan struct{}
+	wakeChan     chan struct{}
+	running      bool
+	logger       *slog.Logger
+}
+
+// NewRetryQueue creates a new retry queue dispatching through the given registry
+func NewRetryQueue(reminderRepo repositories.ReminderRepository, registry *DispatcherRegistry, policy RetryPolicy) *RetryQueue {
+	return &RetryQueue{
+		reminderRepo: reminderRepo,
+		registry:     registry,
+		policy:       policy,
+		stopChan:     make(chan struct{}),
+		wakeChan:     make(chan struct{}, 1),
+		running:      false,
+		logger:       logging.For(logging.Engine),
+	}
+}
+
+// SetLogger replaces the logger of the retry queue
+func (q *RetryQueue) SetLogger(logger *slog.Logger) {
+	q.logger = logger
+}
+
+// Policy returns the retry policy of the queue
+func (q *RetryQueue) Policy() RetryPolicy {
+	return q.policy
+}
+
+// Start begins the retry queue's main loop
+func (q *RetryQueue) Start(ctx context.Context) {
+	if q.running {
+		q.logger.Info("Retry queue already running")
+		return
+	}
+
+	q.stopChan = make(chan struct{})
+	q.doneChan = make(chan struct{})
+	q.running = true
+	go q.retryLoop(ctx, q.stopChan, q.doneChan)
+
+	q.logger.Info("Retry queue started")
+}
+
+// Stop gracefully stops the retry queue. It returns once the retries being sent are
+// done, the queued ones stay in the outbox for the next leader.
+func (q *RetryQueue) Stop() {
+	if !q.running {
+		return
+	}
+
+	close(q.stopChan)
+	<-q.doneChan
+	q.running = false
+	q.logger.Info("Retry queue stopped")
+}
+
+// IsRunning returns whether the retry queue is currently running
+func (q *RetryQueue) IsRunning() bool {
+	return q.running
+}
+
+// Len returns the number of deliveries waiting for a retry
+func (q *RetryQueue) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return len(q.entries)
+}
+
+// Contains reports whether a delivery of the given fire waits for a retry. A nil queue holds nothing.
+func (q *RetryQueue) Contains(reminderID, destinationID uuid.UUID, scheduledAt time.Time) bool {
+	if q == nil {
+		return false
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for _, entry := range q.entries {
+		if entry.ReminderID == reminderID && entry.DestinationID == destinationID && entry.ScheduledAt.Equal(scheduledAt) {
+			return true
+		}
+	}
+	return false
+}
+
+// Schedule queues another attempt of a delivery that failed transiently at the
+// given attempt. It returns false when the attempt cap is reached, in which case
+// the caller must treat the failure as permanent.
+func (q *RetryQueue) Schedule(reminderID, destinationID uuid.UUID, scheduledAt time.Time, attempt int, dispatchErr error) bool {
+	if !q.policy.CanRetry(attempt) {
+		return false
+	}
+
+	delay := q.policy.Backoff(attempt)
+	if transientErr, ok := dispatchers.AsTransient(dispatchErr); ok && transientErr.RetryAfter > delay {
+		delay = transientErr.RetryAfter
+	}
+
+	entry := retryEntry{
+		ReminderID:    reminderID,
+		DestinationID: destinationID,
+		ScheduledAt:   scheduledAt,
+		Attempt:       attempt,
+		DueAt:         time.Now().Add(delay),
+	}
+
+	q.push(entry)
+
+	q.logger.DebugContext(fireContext(context.Background(), reminderID, scheduledAt), "Retry scheduled", "reminder_id", reminderID, "destination_id", destinationID, "attempt", attempt+1, "max_attempts", q.policy.MaxAttempts, "in", delay)
+
+	return true
+}
+
+// push inserts an entry in due order and wakes the loop up so it can shorten its timer
+func (q *RetryQueue) push(entry retryEntry) {
+	q.mutex.Lock()
+	q.entries = append(q.entries, entry)
+	sort.Slice(q.entries, func(i, j int) bool {
+		return q.entries[i].DueAt.Before(q.entries[j].DueAt)
+	})
+	q.mutex.Unlock()
+
+	select {
+	case q.wakeChan <- struct{}{}:
+	default:
+	}
+}
+
+// retryLoop waits for the earliest due entry and attempts it again
+func (q *RetryQueue) retryLoop(ctx context.Context, stopChan <-chan struct{}, doneChan chan<- struct{}) {
+	defer close(doneChan)
+
+	for {
+		var timerChan <-chan time.Time
+		var timer *time.Timer
+		if next, ok := q.nextDueAt(); ok {
+			timer = time.NewTimer(time.Until(next))
+			timerChan = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			stopTimer(timer)
+			q.running = false
+			return
+		case <-stopChan:
+			stopTimer(timer)
+			return
+		case <-q.wakeChan:
+			stopTimer(timer)
+		case <-timerChan:
+			for _, entry := range q.popDue(time.Now()) {
+				q.retry(entry)
+			}
+		}
+	}
+}
+
+// nextDueAt returns the due time of the earliest entry
+func (q *RetryQueue) nextDueAt() (time.Time, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if len(q.entries) == 0 {
+		return time.Time{}, false
+	}
+	return q.entries[0].DueAt, true
+}
+
+// popDue removes and returns every entry due at the given time
+func (q *RetryQueue) popDue(now time.Time) []retryEntry {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	count := 0
+	for count < len(q.entries) && !q.entries[count].DueAt.After(now) {
+		count++
+	}
+
+	due := make([]retryEntry, count)
+	copy(due, q.entries[:count])
+	q.entries = q.entries[count:]
+	return due
+}
+
+// retry reloads the reminder and attempts the delivery again. Reminders or
+// destinations that disappeared or got paused in the meantime are dropped.
+func (q *RetryQueue) retry(entry retryEntry) {
+	ctx, span := tracing.Start(context.Background(), "scheduler.retry", trace.WithAttributes(
+		attribute.String("reminder_id", entry.ReminderID.String()),
+		attribute.String("scheduled_at", entry.ScheduledAt.UTC().Format(time.RFC3339)),
+		attribute.Int("attempt", entry.Attempt+1),
+	))
+	defer span.End()
+	ctx = fireContext(ctx, entry.ReminderID, entry.ScheduledAt)
+	logger := q.logger.With("reminder_id", entry.ReminderID, "destination_id", entry.DestinationID)
+
+	reminder, err := q.reminderRepo.GetWithAccountAndDestinations(entry.ReminderID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error loading reminder for retry", "error", err)
+		// The database hiccup is not the destination's fault, try again later without consuming an attempt
+		entry.DueAt = time.Now().Add(q.policy.Backoff(entry.Attempt))
+		q.push(entry)
+		return
+	}
+
+	if reminder == nil || services.IsPaused(int(reminder.Recurrence)) {
+		logger.DebugContext(ctx, "Dropping retry: reminder deleted or paused")
+		q.registry.markDispatch(entry.ReminderID, entry.DestinationID, entry.ScheduledAt, models.DispatchStatusFailed, entry.Attempt)
+		return
+	}
+
+	var destination *models.ReminderDestination
+	for i := range reminder.Destinations {
+		if reminder.Destinations[i].ID == entry.DestinationID {
+			destination = &reminder.Destinations[i]
+			break
+		}
+	}
+	if destination == nil {
+		logger.DebugContext(ctx, "Dropping retry: destination removed")
+		q.registry.markDispatch(entry.ReminderID, entry.DestinationID, entry.ScheduledAt, models.DispatchStatusFailed, entry.Attempt)
+		return
+	}
+
+	q.registry.dispatchToDestination(ctx, reminder, destination, entry.ScheduledAt, entry.Attempt+1)
+}
+
+// stopTimer stops a timer that may be nil
+func stopTimer(timer *time.Timer) {
+	if timer != nil {
+		timer.Stop()
+	}
+}
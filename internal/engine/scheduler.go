@@ -2,14 +2,19 @@ package engine
 
 import (
 	"context"
-	"log"
+	"errors"
+	"log/slog"
 	"time"
 
-	"github.com/ericp/chronos-bot-reminder/internal/config"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
 	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/ericp/chronos-bot-reminder/internal/metrics"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/ericp/chronos-bot-reminder/internal/tracing"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // fallbackPollInterval is the maximum time the scheduler will wait before
@@ -24,13 +29,21 @@ type QueueEvent struct {
 	ReminderID uuid.UUID // ID of the affected reminder (for updated/deleted events)
 }
 
+// Leadership tells whether the instance is the one allowed to dispatch reminders
+type Leadership interface {
+	IsLeader() bool
+}
+
 // Scheduler manages the timing and dispatching of reminders
 type Scheduler struct {
 	reminderRepo       repositories.ReminderRepository
 	reminderErrorRepo  repositories.ReminderErrorRepository
 	dispatcherRegistry *DispatcherRegistry
 	garbageCollector   *GarbageCollector
+	leadership         Leadership
+	logger             *slog.Logger
 	stopChan           chan struct{}
+	doneChan           chan struct{}
 	updateChan         chan QueueEvent
 	running            bool
 	currentTimer       *time.Timer
@@ -43,6 +56,7 @@ func NewScheduler(reminderRepo repositories.ReminderRepository, reminderErrorRep
 		reminderErrorRepo:  reminderErrorRepo,
 		dispatcherRegistry: dispatcherRegistry,
 		garbageCollector:   garbageCollector,
+		logger:             logging.For(logging.Engine),
 		stopChan:           make(chan struct{}),
 		updateChan:         make(chan QueueEvent, 100), // Buffered channel for updates
 		running:            false,
@@ -50,33 +64,43 @@ func NewScheduler(reminderRepo repositories.ReminderRepository, reminderErrorRep
 	}
 }
 
+// SetLeadership makes the scheduler check the leadership before each dispatch, so an
+// instance that lost its lease while processing a batch stops right away
+func (s *Scheduler) SetLeadership(leadership Leadership) {
+	s.leadership = leadership
+}
+
+// SetLogger replaces the logger of the scheduler
+func (s *Scheduler) SetLogger(logger *slog.Logger) {
+	s.logger = logger
+}
+
 // Start begins the scheduler's main loop
 func (s *Scheduler) Start(ctx context.Context) {
 	if s.running {
-		log.Println("[ENGINE] - Scheduler already running")
+		s.logger.Info("Scheduler already running")
 		return
 	}
 
+	// Fresh channels let the scheduler start again after a stop, e.g. on re-election
+	s.stopChan = make(chan struct{})
+	s.doneChan = make(chan struct{})
 	s.running = true
 
 	// Start the main scheduling loop
-	go s.scheduleLoop(ctx)
+	go s.scheduleLoop(ctx, s.stopChan, s.doneChan)
 }
 
-// Stop gracefully stops the scheduler
+// Stop gracefully stops the scheduler. It returns once the reminders being processed
+// are done, so a demoted leader is no longer dispatching when it returns.
 func (s *Scheduler) Stop() {
 	if !s.running {
-		log.Println("[ENGINE] - Scheduler already stopped")
+		s.logger.Info("Scheduler already stopped")
 		return
 	}
-	
-	// Stop the current timer if it exists
-	if s.currentTimer != nil {
-		s.currentTimer.Stop()
-		s.currentTimer = nil
-	}
-	
+
 	close(s.stopChan)
+	<-s.doneChan
 	s.running = false
 }
 
@@ -93,11 +117,10 @@ func (s *Scheduler) NotifyReminderCreated(reminderID uuid.UUID) {
 	
 	select {
 	case s.updateChan <- QueueEvent{Type: "created"}:
-		if config.IsDebugMode() {
-			log.Println("[ENGINE] - Notified of reminder creation")
-		}
+		s.logger.Debug("Notified of reminder creation", "reminder_id", reminderID)
 	default:
-		log.Println("[ENGINE] - Update channel full, skipping creation notification")
+		s.logger.Warn("Update channel full, skipping creation notification", "reminder_id", reminderID)
+		metrics.IncUpdateDrops("created")
 	}
 }
 
@@ -109,15 +132,14 @@ func (s *Scheduler) NotifyReminderUpdated(reminderID uuid.UUID) {
 	
 	select {
 	case s.updateChan <- QueueEvent{Type: "updated", ReminderID: reminderID}:
-		if config.IsDebugMode() {
-			log.Printf("[ENGINE] - Notified of reminder update: %s", reminderID)
-		}
+		s.logger.Debug("Notified of reminder update", "reminder_id", reminderID)
 		// Notify garbage collector to cancel any pending deletion
 		if s.garbageCollector != nil {
 			s.garbageCollector.NotifyReminderUpdated(reminderID)
 		}
 	default:
-		log.Println("[ENGINE] - Update channel full, skipping update notification")
+		s.logger.Warn("Update channel full, skipping update notification", "reminder_id", reminderID)
+		metrics.IncUpdateDrops("updated")
 	}
 }
 
@@ -129,36 +151,41 @@ func (s *Scheduler) NotifyReminderDeleted(reminderID uuid.UUID) {
 	
 	select {
 	case s.updateChan <- QueueEvent{Type: "deleted", ReminderID: reminderID}:
-		if config.IsDebugMode() {
-			log.Printf("[ENGINE] - Notified of reminder deletion: %s", reminderID)
-		}
+		s.logger.Debug("Notified of reminder deletion", "reminder_id", reminderID)
 		// Notify garbage collector to cancel any pending deletion
 		if s.garbageCollector != nil {
 			s.garbageCollector.NotifyReminderUpdated(reminderID)
 		}
 	default:
-		log.Println("[ENGINE] - Update channel full, skipping deletion notification")
+		s.logger.Warn("Update channel full, skipping deletion notification", "reminder_id", reminderID)
+		metrics.IncUpdateDrops("deleted")
 	}
 }
 
 // scheduleLoop is the main loop that waits for the next reminder or updates
-func (s *Scheduler) scheduleLoop(ctx context.Context) {
+func (s *Scheduler) scheduleLoop(ctx context.Context, stopChan <-chan struct{}, doneChan chan<- struct{}) {
+	defer close(doneChan)
+	defer func() {
+		// Only the loop touches the timer, it is stopped on the way out
+		if s.currentTimer != nil {
+			s.currentTimer.Stop()
+			s.currentTimer = nil
+		}
+	}()
+
 	// Initial schedule setup
 	s.scheduleNext()
 
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("[ENGINE] - Context cancelled, stopping scheduler")
+			s.logger.Info("Context cancelled, stopping scheduler")
 			s.running = false
 			return
-		case <-s.stopChan:
-			s.running = false
+		case <-stopChan:
 			return
 		case <-s.updateChan:
-			if config.IsDebugMode() {
-				log.Println("[ENGINE] - Received update event, rescheduling...")
-			}
+			s.logger.Debug("Received update event, rescheduling")
 			s.scheduleNext()
 		case <-s.getTimerChan():
 			// Timer fired, process due reminders
@@ -189,17 +216,14 @@ func (s *Scheduler) scheduleNext() {
 	// Get the next reminders
 	nextReminders, err := s.reminderRepo.GetNextReminders()
 	if err != nil {
-		log.Printf("[ENGINE] - Error fetching next reminders: %v", err)
 		// Set fallback timer to retry later
-		log.Printf("[ENGINE] - Setting fallback poll timer (%v) due to error", fallbackPollInterval)
+		s.logger.Error("Error fetching next reminders, setting fallback poll timer", "error", err, "retry_in", fallbackPollInterval)
 		s.currentTimer = time.NewTimer(fallbackPollInterval)
 		return
 	}
 
 	if len(nextReminders) == 0 {
-		if config.IsDebugMode() {
-			log.Println("[ENGINE] - No upcoming reminders, waiting for updates...")
-		}
+		s.logger.Debug("No upcoming reminders, waiting for updates")
 		// Set fallback timer to periodically check for new reminders
 		// This handles cases where reminders are added externally or notifications are missed
 		s.currentTimer = time.NewTimer(fallbackPollInterval)
@@ -215,7 +239,7 @@ func (s *Scheduler) scheduleNext() {
 		// Reminder is already due, set a very short timer to process it
 		// We use a timer instead of a goroutine to ensure currentTimer is always set
 		// and the main loop handles all processing consistently
-		log.Printf("[ENGINE] - Reminder is already due, processing shortly")
+		s.logger.Info("Reminder is already due, processing shortly", "reminder_id", nextReminders[0].ID)
 		s.currentTimer = time.NewTimer(100 * time.Millisecond)
 		return
 	}
@@ -223,10 +247,10 @@ func (s *Scheduler) scheduleNext() {
 	// Cap the duration to fallbackPollInterval to handle system sleep/restart scenarios
 	// where a long timer might get orphaned
 	if duration > fallbackPollInterval {
-		log.Printf("[ENGINE] - Next reminder at %v (in %v), using fallback poll interval (%v)", nextTime, duration, fallbackPollInterval)
+		s.logger.Info("Next reminder beyond the fallback poll interval", "reminder_id", nextReminders[0].ID, "next_fire_utc", nextTime, "in", duration, "poll_in", fallbackPollInterval)
 		s.currentTimer = time.NewTimer(fallbackPollInterval)
 	} else {
-		log.Printf("[ENGINE] - Next reminder at %v (in %v)", nextTime, duration)
+		s.logger.Info("Next reminder scheduled", "reminder_id", nextReminders[0].ID, "next_fire_utc", nextTime, "in", duration)
 		s.currentTimer = time.NewTimer(duration)
 	}
 }
@@ -236,17 +260,15 @@ func (s *Scheduler) checkAndProcessReminders() {
 	// Get the next reminders (ones with the closest time)
 	nextReminders, err := s.reminderRepo.GetNextReminders()
 	if err != nil {
-		log.Printf("[ENGINE] - Error fetching next reminders: %v", err)
+		s.logger.Error("Error fetching next reminders", "error", err)
 		return
 	}
 
 	if len(nextReminders) == 0 {
-		log.Println("[ENGINE] - No upcoming reminders found")
+		s.logger.Info("No upcoming reminders found")
 		return
 	}
 
-	log.Printf("[ENGINE] - Next reminder: %s", nextReminders[0].Message)
-
 	// Check if any of the next reminders are due (they should be, since timer fired)
 	now := time.Now().UTC()
 	tolerance := time.Minute // Allow 1 minute tolerance
@@ -259,44 +281,61 @@ func (s *Scheduler) checkAndProcessReminders() {
 	}
 
 	if len(dueReminders) == 0 {
-		log.Println("[ENGINE] - Timer fired but no reminders are actually due")
+		s.logger.Info("Timer fired but no reminders are actually due")
 		return
 	}
 
-	if (config.IsDebugMode()) {
-		log.Printf("[ENGINE] - Found %d due reminders", len(dueReminders))
-	}
+	s.logger.Debug("Found due reminders", "count", len(dueReminders))
 
 	// Process each due reminder
+	metrics.SetDueReminders(len(dueReminders))
+	defer metrics.SetDueReminders(0)
 	for _, reminder := range dueReminders {
+		if s.leadership != nil && !s.leadership.IsLeader() {
+			s.logger.Warn("Leadership lost, leaving the remaining reminders to the new leader")
+			return
+		}
+		if reminder.NextFireUTC != nil {
+			metrics.ObserveSchedulerLag(time.Since(*reminder.NextFireUTC))
+		}
 		s.processReminder(&reminder)
+		metrics.DecDueReminders()
 	}
 }
 
 // processReminder handles the dispatching of a single reminder
 func (s *Scheduler) processReminder(reminder *models.Reminder) {
-	// Check for unfixed errors before dispatching
-	unfixedErrors, err := s.reminderErrorRepo.GetUnfixedByReminderID(reminder.ID)
-	if err != nil {
-		log.Printf("[ENGINE] - Error checking unfixed errors for reminder %s: %v", reminder.ID, err)
-		// Continue with dispatch attempt despite error checking failure
-	} else if len(unfixedErrors) > 0 {
-		if config.IsDebugMode() {
-			log.Printf("[ENGINE] - Skipping reminder %s due to %d unfixed errors", reminder.ID, len(unfixedErrors))
-		}
+	// The fire is a trace of its own, logged with its idempotency key like the dispatcher and the retry queue
+	ctx, span := tracing.Start(context.Background(), "scheduler.fire", trace.WithAttributes(
+		attribute.String("reminder_id", reminder.ID.String()),
+		attribute.String("scheduled_at", reminder.NextFireUTC.UTC().Format(time.RFC3339)),
+	))
+	defer span.End()
+	ctx = fireContext(ctx, reminder.ID, *reminder.NextFireUTC)
+	logger := s.logger.With("reminder_id", reminder.ID)
+	logger.InfoContext(ctx, "Processing due reminder", "next_fire_utc", *reminder.NextFireUTC)
+
+	// Only the destinations without unfixed errors are dispatched, a broken one must not silence the others
+	destinations := s.healthyDestinations(ctx, reminder)
+	if len(destinations) == 0 {
+		logger.DebugContext(ctx, "Skipping reminder: every destination has unfixed errors")
 		return
 	}
 
-	// Dispatch the reminder to all its destinations
-	err = s.dispatcherRegistry.DispatchReminder(reminder)
+	// Dispatch the reminder to its healthy destinations
+	delivered, err := s.dispatcherRegistry.DispatchReminderTo(ctx, reminder, destinations)
 	if err != nil {
-		log.Printf("[ENGINE] - Error dispatching reminder %s: %v", reminder.ID, err)
-		return
+		tracing.RecordError(span, err)
+		logger.ErrorContext(ctx, "Error dispatching reminder", "error", err, "delivered", delivered)
+		// Nothing went out: keep the reminder due so it fires once its destinations are fixed
+		if delivered == 0 {
+			return
+		}
 	}
 
 	// isFromSnooze returns if the reminder was sent due to snooze expiration (so a snooze time earlier than the original remind time)
 	isFromSnooze := reminder.SnoozedAtUTC != nil && reminder.NextFireUTC != nil && reminder.SnoozedAtUTC.Equal(*reminder.NextFireUTC)
-	log.Printf("[ENGINE] - Reminder %s dispatched (from snooze: %v)", reminder.ID, isFromSnooze)
+	logger.InfoContext(ctx, "Reminder dispatched", "from_snooze", isFromSnooze, "delivered", delivered)
 
 	// If it's from a snooze we don't want to touch the reminder more than necessary
 	if isFromSnooze {
@@ -312,7 +351,7 @@ func (s *Scheduler) processReminder(reminder *models.Reminder) {
 		
 		err = s.reminderRepo.Update(reminder, false)
 		if err != nil {
-			log.Printf("[ENGINE] - Error updating reminder %s after snooze dispatch: %v", reminder.ID, err)
+			logger.ErrorContext(ctx, "Error updating reminder after snooze dispatch", "error", err)
 		}
 
 		// If it's a one-time reminder from snooze, add to garbage collector
@@ -325,7 +364,7 @@ func (s *Scheduler) processReminder(reminder *models.Reminder) {
 
 	if reminder.Recurrence != 0 {
 		// Handle recurrence only if not from snooze
-		s.handleRecurrence(reminder)
+		s.handleRecurrence(ctx, reminder)
 	} else {
 		// One-time reminder dispatched, add to garbage collector queue
 		reminder.NextFireUTC = nil
@@ -337,8 +376,25 @@ func (s *Scheduler) processReminder(reminder *models.Reminder) {
 	}
 }
 
+// healthyDestinations returns the destinations of a reminder that have no unfixed error.
+// A destination whose errors cannot be checked is kept so a database hiccup does not drop a delivery.
+func (s *Scheduler) healthyDestinations(ctx context.Context, reminder *models.Reminder) []models.ReminderDestination {
+	healthy := make([]models.ReminderDestination, 0, len(reminder.Destinations))
+	for _, destination := range reminder.Destinations {
+		unfixedErrors, err := s.reminderErrorRepo.GetUnfixedByReminderDestinationID(destination.ID)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Error checking unfixed errors for destination", "reminder_id", reminder.ID, "destination_id", destination.ID, "error", err)
+		} else if len(unfixedErrors) > 0 {
+			s.logger.DebugContext(ctx, "Suspending destination due to unfixed errors", "reminder_id", reminder.ID, "destination_id", destination.ID, "destination_type", destination.Type, "unfixed_errors", len(unfixedErrors))
+			continue
+		}
+		healthy = append(healthy, destination)
+	}
+	return healthy
+}
+
 // handleRecurrence manages recurring reminders
-func (s *Scheduler) handleRecurrence(reminder *models.Reminder) {
+func (s *Scheduler) handleRecurrence(ctx context.Context, reminder *models.Reminder) {
 	if reminder.Recurrence == 0 {
 		return // No recurrence
 	}
@@ -349,16 +405,30 @@ func (s *Scheduler) handleRecurrence(reminder *models.Reminder) {
 		ianaLocation = reminder.Account.Timezone.IANALocation
 	}
 
-	newTime, err := services.GetNextOccurrence(reminder.RemindAtUTC, int(reminder.Recurrence), ianaLocation)
+	newTime, err := services.GetNextOccurrenceInSeries(reminder.RemindAtUTC, int(reminder.Recurrence), services.ReminderRecurrenceSeries(reminder), ianaLocation)
+	if errors.Is(err, services.ErrRecurrenceEnded) {
+		// The rule reached its COUNT/UNTIL: the reminder now behaves like a dispatched one-time reminder
+		s.logger.InfoContext(ctx, "Recurrence of reminder has ended", "reminder_id", reminder.ID)
+		reminder.Recurrence = services.RecurrenceOnce
+		reminder.NextFireUTC = nil
+		if err := s.reminderRepo.Update(reminder, false); err != nil {
+			s.logger.ErrorContext(ctx, "Error ending recurrence of reminder", "reminder_id", reminder.ID, "error", err)
+			return
+		}
+		if s.garbageCollector != nil {
+			s.garbageCollector.NotifyReminderDispatched(reminder.ID)
+		}
+		return
+	}
 	if err != nil {
-		log.Printf("[ENGINE] - Error getting next occurrence for reminder %s: %v", reminder.ID, err)
+		s.logger.ErrorContext(ctx, "Error getting next occurrence for reminder", "reminder_id", reminder.ID, "error", err)
 		return
 	}
 
 	// Update the reminder with the new time
 	err = s.reminderRepo.RescheduleReminder(reminder, newTime, false)
 	if err != nil {
-		log.Printf("[ENGINE] - Error rescheduling recurring reminder %s: %v", reminder.ID, err)
+		s.logger.ErrorContext(ctx, "Error rescheduling recurring reminder", "reminder_id", reminder.ID, "error", err)
 	}
 }
 
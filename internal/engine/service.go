@@ -4,11 +4,13 @@ import (
 	"context"
 	"log"
 	"sync"
+	"time"
 
 	"github.com/ericp/chronos-bot-reminder/internal/config"
 	"github.com/ericp/chronos-bot-reminder/internal/database"
 	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
 	"github.com/ericp/chronos-bot-reminder/internal/dispatchers"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
 	"github.com/ericp/chronos-bot-reminder/internal/services"
 )
 
@@ -16,9 +18,13 @@ import (
 type SchedulerService struct {
 	Scheduler          *Scheduler
 	GarbageCollector   *GarbageCollector
+	RetryQueue         *RetryQueue
 	DispatcherRegistry *DispatcherRegistry
 	ReminderRepo       repositories.ReminderRepository
 	DFMScheduler       *DFMScheduler
+	Elector            *LeaderElector
+	Notifier           *ClusterNotifier
+	ctx                context.Context
 }
 
 var (
@@ -59,27 +65,62 @@ func StartSchedulerService() {
 		schedulerService = NewSchedulerService(repos.Reminder, repos.ReminderError)
 	}
 
-	if schedulerService.Scheduler.IsRunning() {
-		log.Println("[ENGINE] - Already running")
+	if schedulerService.Elector.IsRunning() {
+		logging.For(logging.Engine).Info("Already running")
 		return
 	}
 
 	// Create context for the scheduler
 	schedulerCtx, schedulerCancel = context.WithCancel(context.Background())
+	schedulerService.ctx = schedulerCtx
 
 	// Initialize the repository notifier
 	initializeRepositoryNotifier(schedulerService)
 
+	// Listen to the reminder changes made on the other instances
+	if err := schedulerService.Notifier.Listen(schedulerCtx); err != nil {
+		logging.For(logging.Engine).Warn("Cannot listen to the other instances, their changes wait for the fallback poll", "error", err)
+	}
+
+	// Campaign for the leadership, only the leader runs the engine components
+	schedulerService.Elector.Start(schedulerCtx)
+}
+
+// startWorkers starts the components dispatching reminders once the instance is elected
+func (s *SchedulerService) startWorkers() {
+	// Start the retry queue for transient delivery failures
+	s.RetryQueue.Start(s.ctx)
+
+	// Resume the deliveries a crash or the previous leader left unfinished, before
+	// the scheduler moves any reminder on
+	s.RetryQueue.RecoverPendingDispatches()
+
 	// Start the scheduler
-	schedulerService.Scheduler.Start(schedulerCtx)
-	log.Println("[ENGINE] - ✅ Scheduler started")
+	s.Scheduler.Start(s.ctx)
+	logging.For(logging.Engine).Info("Scheduler started")
 
 	// Start the garbage collector
-	schedulerService.GarbageCollector.Start(schedulerCtx)
+	s.GarbageCollector.Start(s.ctx)
 
 	// Start the Don't Forget Me scheduler
-	if schedulerService.DFMScheduler != nil {
-		schedulerService.DFMScheduler.Start(schedulerCtx)
+	if s.DFMScheduler != nil {
+		s.DFMScheduler.Start(s.ctx)
+	}
+}
+
+// stopWorkers stops the components dispatching reminders when the instance steps down
+func (s *SchedulerService) stopWorkers() {
+	if s.Scheduler.IsRunning() {
+		s.Scheduler.Stop()
+	}
+	if s.GarbageCollector.IsRunning() {
+		s.GarbageCollector.Stop()
+	}
+	if s.RetryQueue.IsRunning() {
+		s.RetryQueue.Stop()
+	}
+	if s.DFMScheduler != nil && s.DFMScheduler.IsRunning() {
+		s.DFMScheduler.Stop()
 	}
 }
 
@@ -89,15 +130,9 @@ func StopSchedulerService() {
 	defer schedulerMutex.Unlock()
 
 	if schedulerService != nil {
-		if schedulerService.Scheduler.IsRunning() {
-			schedulerService.Scheduler.Stop()
-		}
-		if schedulerService.GarbageCollector.IsRunning() {
-			schedulerService.GarbageCollector.Stop()
-		}
-		if schedulerService.DFMScheduler != nil && schedulerService.DFMScheduler.IsRunning() {
-			schedulerService.DFMScheduler.Stop()
-		}
+		// Stepping down releases the leader lock so another instance takes over right away
+		schedulerService.Elector.Stop()
+		schedulerService.stopWorkers()
 	}
 
 	if schedulerCancel != nil {
@@ -123,7 +158,7 @@ func GetReminderRepository() repositories.ReminderRepository {
 func InitializeRepositoryNotifier() {
 	service := GetSchedulerService()
 	if service == nil {
-		log.Printf("[ENGINE] - ⚠️ Cannot initialize repository notifier: scheduler service not available")
+		logging.For(logging.Engine).Warn("Cannot initialize repository notifier: scheduler service not available")
 		return
 	}
 
@@ -136,16 +171,16 @@ func initializeRepositoryNotifier(service *SchedulerService) {
 	// Get the base repositories
 	repos := database.GetRepositories()
 	if repos == nil {
-		log.Printf("[ENGINE] - ⚠️ Cannot initialize repository notifier: repositories not available")
+		logging.For(logging.Engine).Warn("Cannot initialize repository notifier: repositories not available")
 		return
 	}
 
 	// Set the scheduler notifier in the base reminder repository
 	if reminderRepo, ok := repos.Reminder.(interface{ SetScheduler(repositories.SchedulerNotifier) }); ok {
-		reminderRepo.SetScheduler(service.Scheduler)
-		log.Printf("[ENGINE] - ✅ Repository notifier initialized")
+		reminderRepo.SetScheduler(service.Notifier)
+		logging.For(logging.Engine).Info("Repository notifier initialized")
 	} else {
-		log.Printf("[ENGINE] - ⚠️ Reminder repository does not support scheduler notification")
+		logging.For(logging.Engine).Warn("Reminder repository does not support scheduler notification")
 	}
 }
 
@@ -166,16 +201,84 @@ func GetSchedulerAwareReminderRepository() repositories.ReminderRepository {
 	return nil
 }
 
-// IsSchedulerNotificationEnabled returns true if the scheduler is running and can receive notifications
+// WorkerStatus is the state of an engine component on this instance
+type WorkerStatus string
+
+// Worker statuses
+const (
+	WorkerRunning  WorkerStatus = "running"
+	WorkerStandby  WorkerStatus = "standby"  // follower instance, the leader runs the component
+	WorkerStopped  WorkerStatus = "stopped"  // the component should run but does not
+	WorkerDisabled WorkerStatus = "disabled" // the component is not set up on this instance
+)
+
+// Engine components reported by WorkerStatuses
+const (
+	WorkerScheduler        = "scheduler"
+	WorkerGarbageCollector = "garbage_collector"
+	WorkerDFMScheduler     = "dfm_scheduler"
+)
+
+// WorkerStatuses reports the state of each engine component. On a follower the
+// components are expected to be stopped, on the leader they must all be running.
+func (s *SchedulerService) WorkerStatuses() map[string]WorkerStatus {
+	statuses := map[string]WorkerStatus{}
+	status := func(running bool) WorkerStatus {
+		switch {
+		case s.Elector == nil || !s.Elector.IsRunning():
+			return WorkerStopped
+		case !s.Elector.IsLeader():
+			return WorkerStandby
+		case running:
+			return WorkerRunning
+		default:
+			return WorkerStopped
+		}
+	}
+
+	statuses[WorkerScheduler] = status(s.Scheduler.IsRunning())
+	statuses[WorkerGarbageCollector] = status(s.GarbageCollector.IsRunning())
+	if s.DFMScheduler != nil {
+		statuses[WorkerDFMScheduler] = status(s.DFMScheduler.IsRunning())
+	} else {
+		statuses[WorkerDFMScheduler] = WorkerDisabled
+	}
+	return statuses
+}
+
+// CurrentWorkerStatuses reports the engine components of the started scheduler service,
+// or nil when it was not started. Unlike GetSchedulerService it never creates the service.
+func CurrentWorkerStatuses() map[string]WorkerStatus {
+	schedulerMutex.Lock()
+	defer schedulerMutex.Unlock()
+
+	if schedulerService == nil {
+		return nil
+	}
+	return schedulerService.WorkerStatuses()
+}
+
+// IsSchedulerNotificationEnabled returns true if the scheduler service is running and can receive notifications
 func IsSchedulerNotificationEnabled() bool {
 	service := GetSchedulerService()
-	return service != nil && service.Scheduler.IsRunning()
+	return service != nil && service.Elector.IsRunning()
 }
 
 // NewSchedulerService creates a new complete scheduler service with all dispatchers registered
 func NewSchedulerService(reminderRepo repositories.ReminderRepository, reminderErrorRepo repositories.ReminderErrorRepository) *SchedulerService {
+	// Delivery history is optional: without repositories the registry only records errors
+	var reminderDeliveryRepo repositories.ReminderDeliveryRepository
+	if repos := database.GetRepositories(); repos != nil {
+		reminderDeliveryRepo = repos.ReminderDelivery
+	}
+
 	// Create dispatcher registry
-	dispatcherRegistry := NewDispatcherRegistry(reminderErrorRepo)
+	dispatcherRegistry := NewDispatcherRegistry(reminderErrorRepo, reminderDeliveryRepo)
+
+	// Each fire is written to the outbox before it goes out so a crash never sends it twice
+	if repos := database.GetRepositories(); repos != nil {
+		dispatcherRegistry.SetDispatchRepository(repos.ReminderDispatch)
+	}
 
 	// Register all dispatchers
 	dispatcherRegistry.RegisterDispatcher(dispatchers.NewDiscordDMDispatcher())
@@ -183,7 +286,12 @@ func NewSchedulerService(reminderRepo repositories.ReminderRepository, reminderE
 	dispatcherRegistry.RegisterDispatcher(dispatchers.NewDiscordChannelDispatcher())
 
 	cfg := config.Load()
-	mailer := services.NewMailerService(cfg.ResendAPIKey, config.EmailNoreply)
+
+	// Transient failures are retried with an exponential backoff before becoming errors
+	retryQueue := NewRetryQueue(reminderRepo, dispatcherRegistry, NewRetryPolicyFromConfig(cfg))
+	dispatcherRegistry.SetRetryQueue(retryQueue)
+
+	mailer := services.NewMailerServiceFromConfig(cfg)
 	dispatcherRegistry.RegisterDispatcher(dispatchers.NewEmailDispatcher(mailer))
 
 	// Android push delivery via Firebase Cloud Messaging
@@ -192,15 +300,47 @@ func NewSchedulerService(reminderRepo repositories.ReminderRepository, reminderE
 		dispatcherRegistry.RegisterDispatcher(dispatchers.NewAndroidPushDispatcher(fcmService, repos.FcmToken))
 	}
 
+	// Browser delivery via Web Push, authenticated with the VAPID keys
+	if repos := database.GetRepositories(); repos != nil {
+		webPushService := services.NewWebPushService(cfg.VAPIDPublicKey, cfg.VAPIDPrivateKey, cfg.VAPIDSubject)
+		dispatcherRegistry.RegisterDispatcher(dispatchers.NewWebPushDispatcher(webPushService, repos.WebPushSubscription))
+	}
+
+	// Telegram delivery via the Bot API, the snooze buttons are answered by the API server
+	if repos := database.GetRepositories(); repos != nil && cfg.TelegramBotToken != "" {
+		telegramService := services.NewTelegramService(
+			services.NewTelegramClient(cfg.TelegramAPIBaseURL, cfg.TelegramBotToken),
+			repos.Identity,
+			reminderRepo,
+			services.NewRedisTelegramLinkCodeStore(),
+			cfg.TelegramBotUsername,
+		)
+		dispatcherRegistry.RegisterDispatcher(dispatchers.NewTelegramDispatcher(telegramService))
+	}
+
+	// SMS and voice delivery, the dispatchers report a configuration error while the provider is not set up
+	if repos := database.GetRepositories(); repos != nil {
+		telephonyService := services.NewTelephonyServiceFromConfig(cfg, repos)
+		dispatcherRegistry.RegisterDispatcher(dispatchers.NewSMSDispatcher(telephonyService))
+		dispatcherRegistry.RegisterDispatcher(dispatchers.NewVoiceDispatcher(telephonyService))
+	}
+
 	// Create garbage collector
 	garbageCollector := NewGarbageCollector(reminderRepo)
+	if reminderDeliveryRepo != nil {
+		garbageCollector.SetDeliveryRetention(reminderDeliveryRepo, time.Duration(cfg.DeliveryRetentionDays)*24*time.Hour)
+	}
 
 	// Create scheduler
 	scheduler := NewScheduler(reminderRepo, reminderErrorRepo, dispatcherRegistry, garbageCollector)
 
-	// Set the scheduler in the repository if it supports it
+	// Reminder changes reach the local scheduler and, through Redis pub/sub, the leader instance
+	instanceID := NewInstanceID(cfg.SchedulerInstanceID)
+	notifier := NewClusterNotifier(instanceID, scheduler, NewRedisReminderEventBus(database.GetRedisClient()))
+
+	// Set the notifier in the repository if it supports it
 	if schedulerAwareRepo, ok := reminderRepo.(interface{ SetScheduler(repositories.SchedulerNotifier) }); ok {
-		schedulerAwareRepo.SetScheduler(scheduler)
+		schedulerAwareRepo.SetScheduler(notifier)
 	}
 
 	// Create the Don't Forget Me scheduler
@@ -212,11 +352,20 @@ func NewSchedulerService(reminderRepo repositories.ReminderRepository, reminderE
 		services.DFMSendNow = dfmScheduler.SendNoteNow
 	}
 
-	return &SchedulerService{
+	service := &SchedulerService{
 		Scheduler:          scheduler,
 		GarbageCollector:   garbageCollector,
+		RetryQueue:         retryQueue,
 		DispatcherRegistry: dispatcherRegistry,
 		ReminderRepo:       reminderRepo,
 		DFMScheduler:       dfmScheduler,
+		Notifier:           notifier,
+		ctx:                context.Background(),
 	}
+
+	// Only the instance holding the leader lease runs the engine, so replicas never fire a reminder twice
+	service.Elector = NewLeaderElector(NewRedisLeaderLock(database.GetRedisClient()), instanceID, LeaderTTLFromConfig(cfg), service.startWorkers, service.stopWorkers)
+	scheduler.SetLeadership(service.Elector)
+
+	return service
 }
@@ -0,0 +1,198 @@
+// Package i18n holds the message catalogues of the bot and the emails and renders
+// them in the language of an account.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported locales, the catalogue of the default locale is the reference for the others
+const (
+	English = "en"
+	French  = "fr"
+	Spanish = "es"
+
+	Default = English
+)
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// catalogues maps a locale to its messages indexed by dotted key, e.g. "dfm.title"
+var catalogues = map[string]map[string]string{}
+
+func init() {
+	for _, locale := range Supported() {
+		data, err := localeFiles.ReadFile(path.Join("locales", locale+".json"))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: missing catalogue for %s: %v", locale, err))
+		}
+		var tree map[string]any
+		if err := json.Unmarshal(data, &tree); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalogue for %s: %v", locale, err))
+		}
+		messages := map[string]string{}
+		flatten("", tree, messages)
+		catalogues[locale] = messages
+	}
+}
+
+// flatten turns the nested catalogue into dotted keys
+func flatten(prefix string, tree map[string]any, messages map[string]string) {
+	for key, value := range tree {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch value := value.(type) {
+		case string:
+			messages[key] = value
+		case map[string]any:
+			flatten(key, value, messages)
+		}
+	}
+}
+
+// Supported returns the supported locales, the default one first
+func Supported() []string {
+	return []string{English, French, Spanish}
+}
+
+// IsSupported reports whether locale is one of the supported locales
+func IsSupported(locale string) bool {
+	for _, supported := range Supported() {
+		if locale == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// Normalize maps a language tag such as "fr-FR", "es-419" or "en_US" to a supported
+// locale. It returns an empty string when the language is not supported.
+func Normalize(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	if IsSupported(tag) {
+		return tag
+	}
+	return ""
+}
+
+// Resolve returns the first candidate that maps to a supported locale, or the default
+// locale. Candidates are given by order of preference, e.g. account then Discord client.
+func Resolve(candidates ...string) string {
+	for _, candidate := range candidates {
+		if locale := Normalize(candidate); locale != "" {
+			return locale
+		}
+	}
+	return Default
+}
+
+// MatchAcceptLanguage returns the supported locale the client prefers according to an
+// Accept-Language header, or an empty string when it accepts none of them
+func MatchAcceptLanguage(header string) string {
+	type weightedTag struct {
+		tag    string
+		weight float64
+	}
+
+	var tags []weightedTag
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		weight := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				weight = parsed
+			}
+		}
+		if tag != "" && weight > 0 {
+			tags = append(tags, weightedTag{tag: tag, weight: weight})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].weight > tags[j].weight })
+
+	for _, tag := range tags {
+		if locale := Normalize(tag.tag); locale != "" {
+			return locale
+		}
+	}
+	return ""
+}
+
+// Keys returns the sorted message keys of a locale
+func Keys(locale string) []string {
+	keys := make([]string, 0, len(catalogues[locale]))
+	for key := range catalogues[locale] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Lookup returns the message of a locale without falling back to another language
+func Lookup(locale, key string) (string, bool) {
+	message, ok := catalogues[Normalize(locale)][key]
+	return message, ok
+}
+
+// T renders the message key in locale with fmt-style arguments. Messages missing from
+// the locale fall back to English, and to the key itself so a gap stays visible.
+func T(locale, key string, args ...any) string {
+	message, ok := Lookup(locale, key)
+	if !ok {
+		message, ok = catalogues[Default][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// TN renders the plural form of the message key for the count n: key.one or key.other.
+// French uses the singular for zero too.
+func TN(locale, key string, n int, args ...any) string {
+	one := n == 1
+	if Resolve(locale) == French {
+		one = n == 0 || n == 1
+	}
+	if one {
+		return T(locale, key+".one", args...)
+	}
+	return T(locale, key+".other", args...)
+}
+
+// Name returns the name of a locale in its own language
+func Name(locale string) string {
+	return T(locale, "language.name")
+}
+
+// FormatDateTime renders t as a long date with the time, e.g. "Monday, January 2, 2006 at 15:04"
+func FormatDateTime(locale string, t time.Time) string {
+	return T(locale, "date.long",
+		T(locale, fmt.Sprintf("date.weekday.%d", t.Weekday())),
+		t.Day(),
+		T(locale, fmt.Sprintf("date.month.%d", t.Month())),
+		t.Year(),
+		t.Format("15:04"))
+}
+
+// FormatShortDateTime renders t as a short date with the time and zone, e.g. "Jan 02, 15:04 MST"
+func FormatShortDateTime(locale string, t time.Time) string {
+	return T(locale, "date.short",
+		t.Day(),
+		T(locale, fmt.Sprintf("date.month_short.%d", t.Month())),
+		t.Format("15:04 MST"))
+}
@@ -0,0 +1,97 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// correlationIDAttr is the attribute holding the correlation ID in the records
+const correlationIDAttr = "correlation_id"
+
+// correlationIDKey is the context key of the correlation ID
+type correlationIDKey struct{}
+
+// loggerKey is the context key of a request-scoped logger
+type loggerKey struct{}
+
+// NewCorrelationID returns a random ID for a request or an interaction
+func NewCorrelationID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
+
+// WithCorrelationID returns a context whose log records carry the correlation ID
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, correlationID)
+}
+
+// CorrelationID returns the correlation ID of the context, or an empty string
+func CorrelationID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	correlationID, _ := ctx.Value(correlationIDKey{}).(string)
+	return correlationID
+}
+
+// WithLogger returns a context holding the logger, for the code below a request handler.
+// A logger carrying the correlation ID lets that code log without passing the context.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger of the context, or the App logger
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return For(App)
+}
+
+// subsystemHandler filters the records with the level of its subsystem and adds the
+// correlation ID of the context, unless the logger already carries one, and the
+// trace of the context when it is sampled
+type subsystemHandler struct {
+	level      *slog.LevelVar
+	next       slog.Handler
+	correlated bool
+}
+
+// Enabled implements slog.Handler
+func (h *subsystemHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle implements slog.Handler
+func (h *subsystemHandler) Handle(ctx context.Context, record slog.Record) error {
+	if correlationID := CorrelationID(ctx); correlationID != "" && !h.correlated {
+		record.AddAttrs(slog.String(correlationIDAttr, correlationID))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsSampled() {
+		record.AddAttrs(slog.String("trace_id", spanContext.TraceID().String()), slog.String("span_id", spanContext.SpanID().String()))
+	}
+	return h.next.Handle(ctx, record)
+}
+
+// WithAttrs implements slog.Handler
+func (h *subsystemHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	correlated := h.correlated
+	for _, attr := range attrs {
+		correlated = correlated || attr.Key == correlationIDAttr
+	}
+	return &subsystemHandler{level: h.level, next: h.next.WithAttrs(attrs), correlated: correlated}
+}
+
+// WithGroup implements slog.Handler
+func (h *subsystemHandler) WithGroup(name string) slog.Handler {
+	return &subsystemHandler{level: h.level, next: h.next.WithGroup(name), correlated: h.correlated}
+}
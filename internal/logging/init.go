@@ -0,0 +1,38 @@
+package logging
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/ericp/chronos-bot-reminder/internal/config"
+)
+
+// Init configures the loggers from the configuration. Without LOG_LEVEL, debug records
+// are logged in the DEV and DEBUG environments only.
+func Init(cfg *config.Config) error {
+	if err := Configure(os.Stderr, cfg.LogFormat); err != nil {
+		return err
+	}
+
+	level := slog.LevelInfo
+	if cfg.Environment == "DEV" || cfg.Environment == "DEBUG" {
+		level = slog.LevelDebug
+	}
+	if cfg.LogLevel != "" {
+		parsed, err := ParseLevel(cfg.LogLevel)
+		if err != nil {
+			return err
+		}
+		level = parsed
+	}
+	SetAllLevels(level)
+
+	subsystemLevels, err := ParseLevels(cfg.LogLevels)
+	if err != nil {
+		return err
+	}
+	for subsystem, subsystemLevel := range subsystemLevels {
+		SetLevel(subsystem, subsystemLevel)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+// Package logging provides the structured loggers of the application. Each subsystem
+// has its own logger whose level can be changed at runtime, and the records logged
+// with a context carry the correlation ID it holds.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Subsystem identifies the part of the application a log record comes from
+type Subsystem string
+
+// Subsystems with their own level
+const (
+	App        Subsystem = "app" // everything else, including the standard log package
+	Engine     Subsystem = "engine"
+	Dispatcher Subsystem = "dispatcher"
+	API        Subsystem = "api"
+	Bot        Subsystem = "bot"
+)
+
+// Subsystems lists every subsystem
+var Subsystems = []Subsystem{App, Engine, Dispatcher, API, Bot}
+
+// Output formats
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+var (
+	mutex  sync.RWMutex
+	base   slog.Handler = newBaseHandler(os.Stderr, FormatJSON)
+	levels              = newLevels()
+)
+
+func newLevels() map[Subsystem]*slog.LevelVar {
+	vars := make(map[Subsystem]*slog.LevelVar, len(Subsystems))
+	for _, subsystem := range Subsystems {
+		vars[subsystem] = &slog.LevelVar{}
+	}
+	return vars
+}
+
+// newBaseHandler lets every record through, the subsystem handlers filter them
+func newBaseHandler(w io.Writer, format string) slog.Handler {
+	options := &slog.HandlerOptions{Level: slog.LevelDebug}
+	if format == FormatText {
+		return slog.NewTextHandler(w, options)
+	}
+	return slog.NewJSONHandler(w, options)
+}
+
+// Configure sets the output of the loggers created from now on, and routes the
+// standard log package through the App logger
+func Configure(w io.Writer, format string) error {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = FormatJSON
+	}
+	if format != FormatJSON && format != FormatText {
+		return fmt.Errorf("unknown log format %q, expected json or text", format)
+	}
+
+	mutex.Lock()
+	base = newBaseHandler(w, format)
+	mutex.Unlock()
+
+	slog.SetDefault(For(App))
+	return nil
+}
+
+// For returns the logger of a subsystem. Unknown subsystems share the App level.
+func For(subsystem Subsystem) *slog.Logger {
+	mutex.RLock()
+	next := base
+	mutex.RUnlock()
+
+	level, exists := levels[subsystem]
+	if !exists {
+		level = levels[App]
+	}
+	handler := &subsystemHandler{level: level, next: next.WithAttrs([]slog.Attr{slog.String("subsystem", string(subsystem))})}
+	return slog.New(handler)
+}
+
+// SetLevel changes the level of a subsystem, the loggers already created follow it
+func SetLevel(subsystem Subsystem, level slog.Level) error {
+	levelVar, exists := levels[subsystem]
+	if !exists {
+		return fmt.Errorf("unknown log subsystem %q", subsystem)
+	}
+	levelVar.Set(level)
+	return nil
+}
+
+// SetAllLevels changes the level of every subsystem
+func SetAllLevels(level slog.Level) {
+	for _, levelVar := range levels {
+		levelVar.Set(level)
+	}
+}
+
+// Levels returns the current level of each subsystem
+func Levels() map[Subsystem]slog.Level {
+	current := make(map[Subsystem]slog.Level, len(levels))
+	for subsystem, levelVar := range levels {
+		current[subsystem] = levelVar.Level()
+	}
+	return current
+}
+
+// ParseLevel reads a level name: debug, info, warn or error
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+		return 0, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", name)
+	}
+	return level, nil
+}
+
+// ParseSubsystem reads a subsystem name
+func ParseSubsystem(name string) (Subsystem, error) {
+	subsystem := Subsystem(strings.ToLower(strings.TrimSpace(name)))
+	if _, exists := levels[subsystem]; !exists {
+		return "", fmt.Errorf("unknown log subsystem %q, expected one of %s", name, subsystemNames())
+	}
+	return subsystem, nil
+}
+
+// ParseLevels reads per-subsystem levels written as "engine=debug,api=warn"
+func ParseLevels(spec string) (map[Subsystem]slog.Level, error) {
+	parsed := map[Subsystem]slog.Level{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid log level %q, expected subsystem=level", entry)
+		}
+		subsystem, err := ParseSubsystem(name)
+		if err != nil {
+			return nil, err
+		}
+		level, err := ParseLevel(value)
+		if err != nil {
+			return nil, err
+		}
+		parsed[subsystem] = level
+	}
+	return parsed, nil
+}
+
+func subsystemNames() string {
+	names := make([]string, 0, len(Subsystems))
+	for _, subsystem := range Subsystems {
+		names = append(names, string(subsystem))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,177 @@
+// Package metrics holds the Prometheus collectors of the engine, the dispatchers
+// and the API, exposed in the exposition format on GET /metrics.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+)
+
+const namespace = "chronos"
+
+// Dispatch outcomes
+const (
+	OutcomeSuccess = "success"
+	OutcomeRetried = "retried" // transient failure handed to the retry queue
+	OutcomeFailure = "failure"
+)
+
+// Registry is the registry served on /metrics. A dedicated registry keeps the
+// collectors of the libraries we depend on out of the exposition.
+var Registry = prometheus.NewRegistry()
+
+var (
+	schedulerLag = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "scheduler",
+		Name:      "lag_seconds",
+		Help:      "Delay between the planned fire time of a reminder (NextFireUTC) and the moment it was processed.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300},
+	})
+
+	schedulerDueReminders = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "scheduler",
+		Name:      "due_reminders",
+		Help:      "Due reminders of the current batch still waiting to be processed.",
+	})
+
+	schedulerUpdateDrops = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "scheduler",
+		Name:      "update_drops_total",
+		Help:      "Reminder change notifications dropped because the update channel was full.",
+	}, []string{"event"})
+
+	dispatches = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "dispatch",
+		Name:      "total",
+		Help:      "Delivery attempts per destination type and outcome.",
+	}, []string{"destination_type", "outcome"})
+
+	dispatchDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "dispatch",
+		Name:      "duration_seconds",
+		Help:      "Time spent by the dispatcher on a delivery attempt, per destination type and outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"destination_type", "outcome"})
+
+	garbageCollectorDeletions = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "garbage_collector",
+		Name:      "deleted_reminders_total",
+		Help:      "Dispatched one-time reminders deleted by the garbage collector.",
+	})
+
+	dfmSends = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "dfm",
+		Name:      "sends_total",
+		Help:      "Don't Forget Me notes sent, per outcome.",
+	}, []string{"outcome"})
+
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "API requests per route pattern, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "API request duration per route pattern and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		schedulerLag,
+		schedulerDueReminders,
+		schedulerUpdateDrops,
+		dispatches,
+		dispatchDuration,
+		garbageCollectorDeletions,
+		dfmSends,
+		httpRequests,
+		httpRequestDuration,
+	)
+}
+
+// Handler serves the registry in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RegisterPools exposes the connection pool statistics of Postgres and Redis.
+// Either may be nil, a pool registered twice is ignored.
+func RegisterPools(db *sql.DB, redisClient *redis.Client) {
+	if db != nil {
+		Registry.Register(collectors.NewDBStatsCollector(db, "postgres"))
+	}
+	if redisClient != nil {
+		Registry.Register(newRedisPoolCollector(redisClient))
+	}
+}
+
+// ObserveSchedulerLag records how late a reminder was processed compared to its fire time
+func ObserveSchedulerLag(lag time.Duration) {
+	if lag < 0 {
+		lag = 0
+	}
+	schedulerLag.Observe(lag.Seconds())
+}
+
+// SetDueReminders sets the number of due reminders waiting in the current batch
+func SetDueReminders(count int) {
+	schedulerDueReminders.Set(float64(count))
+}
+
+// DecDueReminders marks one due reminder of the current batch as processed
+func DecDueReminders() {
+	schedulerDueReminders.Dec()
+}
+
+// IncUpdateDrops counts a reminder change notification lost to a full update channel
+func IncUpdateDrops(event string) {
+	schedulerUpdateDrops.WithLabelValues(event).Inc()
+}
+
+// ObserveDispatch records a delivery attempt and the time the dispatcher took
+func ObserveDispatch(destinationType string, outcome string, duration time.Duration) {
+	dispatches.WithLabelValues(destinationType, outcome).Inc()
+	dispatchDuration.WithLabelValues(destinationType, outcome).Observe(duration.Seconds())
+}
+
+// IncGarbageCollectorDeletions counts a reminder deleted by the garbage collector
+func IncGarbageCollectorDeletions() {
+	garbageCollectorDeletions.Inc()
+}
+
+// IncDFMSends counts a Don't Forget Me note sent, or failed to be sent
+func IncDFMSends(err error) {
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeFailure
+	}
+	dfmSends.WithLabelValues(outcome).Inc()
+}
+
+// ObserveHTTPRequest records a served API request
+func ObserveHTTPRequest(route string, method string, status int, duration time.Duration) {
+	httpRequests.WithLabelValues(route, method, strconv.Itoa(status)).Inc()
+	httpRequestDuration.WithLabelValues(route, method).Observe(duration.Seconds())
+}
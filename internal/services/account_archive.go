@@ -0,0 +1,708 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database"
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AccountArchiveVersion is the version of the archive format written by Export. Import
+// reads this version and the previous ones.
+const AccountArchiveVersion = 1
+
+// ArchiveConflictMode tells Import what to do with data the account already has
+type ArchiveConflictMode string
+
+const (
+	// ArchiveConflictSkip keeps the existing reminders (same ID, or same message, date
+	// and recurrence), items and note schedule, only what is missing is added
+	ArchiveConflictSkip ArchiveConflictMode = "skip"
+	// ArchiveConflictOverwrite replaces the existing reminders with the same ID and the
+	// note schedule with the archived ones, everything else is kept
+	ArchiveConflictOverwrite ArchiveConflictMode = "overwrite"
+	// ArchiveConflictReplace deletes the reminders and note items of the account first
+	ArchiveConflictReplace ArchiveConflictMode = "replace"
+)
+
+// IsValid checks if the conflict mode is valid
+func (m ArchiveConflictMode) IsValid() bool {
+	return m == ArchiveConflictSkip || m == ArchiveConflictOverwrite || m == ArchiveConflictReplace
+}
+
+// AccountArchive is the portable copy of an account's data. Credentials, tokens and
+// API key hashes are never part of it.
+type AccountArchive struct {
+	Version        int                    `json:"version"`
+	ExportedAt     time.Time              `json:"exported_at"`
+	Account        ArchiveAccount         `json:"account"`
+	Timezone       *ArchiveTimezone       `json:"timezone,omitempty"`
+	Identities     []ArchiveIdentity      `json:"identities"`
+	Reminders      []ArchiveReminder      `json:"reminders"`
+	ReminderErrors []ArchiveReminderError `json:"reminder_errors"`
+	DFMNote        *ArchiveDFMNote        `json:"dfm_note,omitempty"`
+}
+
+// ArchiveAccount holds the profile of the exported account
+type ArchiveAccount struct {
+	ID            uuid.UUID `json:"id"`
+	Email         *string   `json:"email,omitempty"`
+	Username      *string   `json:"username,omitempty"`
+	EmailVerified bool      `json:"email_verified"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ArchiveTimezone identifies the timezone of the account
+type ArchiveTimezone struct {
+	Name         string `json:"name"`
+	IANALocation string `json:"iana_location"`
+}
+
+// ArchiveIdentity is a login identity of the account, without its tokens
+type ArchiveIdentity struct {
+	Provider   string    `json:"provider"`
+	ExternalID string    `json:"external_id"`
+	Username   *string   `json:"username,omitempty"`
+	Avatar     *string   `json:"avatar,omitempty"`
+	Scopes     *string   `json:"scopes,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ArchiveReminder is a reminder with its destinations
+type ArchiveReminder struct {
+	ID                 uuid.UUID            `json:"id"`
+	RemindAtUTC        time.Time            `json:"remind_at_utc"`
+	SnoozedAtUTC       *time.Time           `json:"snoozed_at_utc,omitempty"`
+	NextFireUTC        *time.Time           `json:"next_fire_utc,omitempty"`
+	Message            string               `json:"message"`
+	Recurrence         int16                `json:"recurrence"` // recurrence state, pause bit included
+	RecurrenceType     string               `json:"recurrence_type"`
+	RRule              *string              `json:"rrule,omitempty"`
+	RecurrenceStartUTC *time.Time           `json:"recurrence_start_utc,omitempty"`
+	CreatedAt          time.Time            `json:"created_at"`
+	Destinations       []ArchiveDestination `json:"destinations"`
+}
+
+// ArchiveDestination is a delivery target of an archived reminder
+type ArchiveDestination struct {
+	ID       uuid.UUID    `json:"id"`
+	Type     string       `json:"type"`
+	Metadata models.JSONB `json:"metadata"`
+}
+
+// ArchiveReminderError is a delivery failure of an archived reminder. Unfixed errors
+// keep their destination suspended once restored.
+type ArchiveReminderError struct {
+	ReminderID            uuid.UUID `json:"reminder_id"`
+	ReminderDestinationID uuid.UUID `json:"reminder_destination_id"`
+	Timestamp             time.Time `json:"timestamp"`
+	Stacktrace            string    `json:"stacktrace"`
+	Fixed                 bool      `json:"fixed"`
+}
+
+// ArchiveDFMNote is the "Don't Forget Me" note with its items
+type ArchiveDFMNote struct {
+	RemindAtUTC   *time.Time       `json:"remind_at_utc,omitempty"`
+	Recurrence    int16            `json:"recurrence"`
+	SendDiscordDM bool             `json:"send_discord_dm"`
+	SendEmail     bool             `json:"send_email"`
+	Items         []ArchiveDFMItem `json:"items"`
+}
+
+// ArchiveDFMItem is an entry of the "Don't Forget Me" note
+type ArchiveDFMItem struct {
+	Content  string `json:"content"`
+	Checked  bool   `json:"checked"`
+	Position int    `json:"position"`
+}
+
+// AccountImportReport counts what Import restored
+type AccountImportReport struct {
+	Mode                ArchiveConflictMode `json:"mode"`
+	TimezoneUpdated     bool                `json:"timezone_updated"`
+	RemindersCreated    int                 `json:"reminders_created"`
+	RemindersOverwrote  int                 `json:"reminders_overwritten"`
+	RemindersSkipped    int                 `json:"reminders_skipped"`
+	DestinationsSkipped int                 `json:"destinations_skipped"`
+	ErrorsRestored      int                 `json:"errors_restored"`
+	DFMItemsCreated     int                 `json:"dfm_items_created"`
+	DFMItemsSkipped     int                 `json:"dfm_items_skipped"`
+	DFMScheduleRestored bool                `json:"dfm_schedule_restored"`
+	Warnings            []string            `json:"warnings"`
+}
+
+func (r *AccountImportReport) warn(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// NewAccountArchive assembles the archive of an account. The account is expected with
+// its timezone and identities loaded, the reminders with their destinations.
+func NewAccountArchive(account *models.Account, reminders []models.Reminder, reminderErrors []models.ReminderError, note *models.DFMNote) *AccountArchive {
+	archive := &AccountArchive{
+		Version:    AccountArchiveVersion,
+		ExportedAt: time.Now().UTC(),
+		Account: ArchiveAccount{
+			ID:            account.ID,
+			Email:         account.Email,
+			Username:      account.Username,
+			EmailVerified: account.EmailVerified,
+			CreatedAt:     account.CreatedAt,
+		},
+		Identities:     []ArchiveIdentity{},
+		Reminders:      []ArchiveReminder{},
+		ReminderErrors: []ArchiveReminderError{},
+	}
+
+	if account.Timezone != nil {
+		archive.Timezone = &ArchiveTimezone{Name: account.Timezone.Name, IANALocation: account.Timezone.IANALocation}
+	}
+
+	for _, identity := range account.Identities {
+		archive.Identities = append(archive.Identities, ArchiveIdentity{
+			Provider:   identity.Provider.String(),
+			ExternalID: identity.ExternalID,
+			Username:   identity.Username,
+			Avatar:     identity.Avatar,
+			Scopes:     identity.Scopes,
+			CreatedAt:  identity.CreatedAt,
+		})
+	}
+
+	for _, reminder := range reminders {
+		archived := ArchiveReminder{
+			ID:                 reminder.ID,
+			RemindAtUTC:        reminder.RemindAtUTC,
+			SnoozedAtUTC:       reminder.SnoozedAtUTC,
+			NextFireUTC:        reminder.NextFireUTC,
+			Message:            reminder.Message,
+			Recurrence:         reminder.Recurrence,
+			RecurrenceType:     GetRecurrenceTypeName(GetRecurrenceType(int(reminder.Recurrence))),
+			RRule:              reminder.RRule,
+			RecurrenceStartUTC: reminder.RecurrenceStartUTC,
+			CreatedAt:          reminder.CreatedAt,
+			Destinations:       []ArchiveDestination{},
+		}
+		for _, destination := range reminder.Destinations {
+			archived.Destinations = append(archived.Destinations, ArchiveDestination{
+				ID:       destination.ID,
+				Type:     destination.Type.String(),
+				Metadata: RedactDestinationMetadata(destination.Metadata), // signing secrets stay on this instance
+			})
+		}
+		archive.Reminders = append(archive.Reminders, archived)
+	}
+
+	for _, reminderError := range reminderErrors {
+		archive.ReminderErrors = append(archive.ReminderErrors, ArchiveReminderError{
+			ReminderID:            reminderError.ReminderID,
+			ReminderDestinationID: reminderError.ReminderDestinationID,
+			Timestamp:             reminderError.Timestamp,
+			Stacktrace:            reminderError.Stacktrace,
+			Fixed:                 reminderError.Fixed,
+		})
+	}
+
+	if note != nil {
+		archive.DFMNote = &ArchiveDFMNote{
+			RemindAtUTC:   note.RemindAtUTC,
+			Recurrence:    note.Recurrence,
+			SendDiscordDM: note.SendDiscordDM,
+			SendEmail:     note.SendEmail,
+			Items:         []ArchiveDFMItem{},
+		}
+		for _, item := range note.Items {
+			archive.DFMNote.Items = append(archive.DFMNote.Items, ArchiveDFMItem{
+				Content:  item.Content,
+				Checked:  item.Checked,
+				Position: item.Position,
+			})
+		}
+	}
+
+	return archive
+}
+
+// WriteRemindersCSV writes the reminders of an archive as CSV, one row per reminder
+func WriteRemindersCSV(w io.Writer, archive *AccountArchive) error {
+	writer := csv.NewWriter(w)
+	header := []string{"id", "message", "remind_at_utc", "next_fire_utc", "recurrence_type", "rrule", "paused", "destinations", "created_at"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, reminder := range archive.Reminders {
+		nextFire := ""
+		if reminder.NextFireUTC != nil {
+			nextFire = reminder.NextFireUTC.UTC().Format(time.RFC3339)
+		}
+		rule := ""
+		if reminder.RRule != nil {
+			rule = *reminder.RRule
+		}
+		destinationTypes := make([]string, 0, len(reminder.Destinations))
+		for _, destination := range reminder.Destinations {
+			destinationTypes = append(destinationTypes, destination.Type)
+		}
+
+		row := []string{
+			reminder.ID.String(),
+			csvSafe(reminder.Message),
+			reminder.RemindAtUTC.UTC().Format(time.RFC3339),
+			nextFire,
+			reminder.RecurrenceType,
+			rule,
+			strconv.FormatBool(IsPaused(int(reminder.Recurrence))),
+			strings.Join(destinationTypes, ";"),
+			reminder.CreatedAt.UTC().Format(time.RFC3339),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvSafe keeps spreadsheets from evaluating a message as a formula
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// ParseAccountArchive decodes an archive and checks it can be imported
+func ParseAccountArchive(r io.Reader) (*AccountArchive, error) {
+	var archive AccountArchive
+	if err := json.NewDecoder(r).Decode(&archive); err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+
+	if archive.Version < 1 {
+		return nil, errors.New("invalid archive: version is missing")
+	}
+	if archive.Version > AccountArchiveVersion {
+		return nil, fmt.Errorf("archive version %d is newer than the supported version %d", archive.Version, AccountArchiveVersion)
+	}
+
+	return &archive, nil
+}
+
+// RestoreArchiveReminder turns an archived reminder into a reminder of the account. Its
+// destinations keep their archived IDs until saved. Destinations that fail validation are left out
+// and reported as warnings. Reminders due in the past resume at their next occurrence;
+// a returned nil reminder means there is none left (warnings say why). Snoozes are
+// not restored.
+func RestoreArchiveReminder(archived *ArchiveReminder, accountID uuid.UUID, ianaLocation string) (*models.Reminder, []string, error) {
+	if strings.TrimSpace(archived.Message) == "" {
+		return nil, nil, errors.New("message is empty")
+	}
+
+	state := int(archived.Recurrence)
+	if recurrenceType := GetRecurrenceType(state); recurrenceType < RecurrenceOnce || recurrenceType > RecurrenceRRule {
+		return nil, nil, fmt.Errorf("invalid recurrence %d", archived.Recurrence)
+	}
+
+	reminder := &models.Reminder{
+		ID:                 archived.ID,
+		AccountID:          accountID,
+		RemindAtUTC:        archived.RemindAtUTC.UTC(),
+		Message:            archived.Message,
+		Recurrence:         archived.Recurrence,
+		RecurrenceStartUTC: archived.RecurrenceStartUTC,
+	}
+
+	if GetRecurrenceType(state) == RecurrenceRRule {
+		if archived.RRule == nil {
+			return nil, nil, errors.New("rrule is required for the RRULE recurrence")
+		}
+		normalized, err := ParseRRule(*archived.RRule)
+		if err != nil {
+			return nil, nil, err
+		}
+		reminder.RRule = &normalized
+	}
+
+	var warnings []string
+	if !reminder.RemindAtUTC.After(time.Now()) && !IsPaused(state) {
+		if GetRecurrenceType(state) == RecurrenceOnce {
+			return nil, []string{fmt.Sprintf("reminder %s is in the past", archived.ID)}, nil
+		}
+		next, err := GetNextOccurrenceInSeries(reminder.RemindAtUTC, state, ReminderRecurrenceSeries(reminder), ianaLocation)
+		if errors.Is(err, ErrRecurrenceEnded) {
+			return nil, []string{fmt.Sprintf("reminder %s has no occurrence left", archived.ID)}, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		reminder.RemindAtUTC = next.UTC()
+	}
+
+	for _, archivedDestination := range archived.Destinations {
+		destination := models.ReminderDestination{
+			ID:       archivedDestination.ID,
+			Type:     models.DestinationType(archivedDestination.Type),
+			Metadata: archivedDestination.Metadata,
+		}
+		if destination.Metadata == nil {
+			destination.Metadata = models.JSONB{}
+		}
+		// Push notifications go to the devices of the importing account
+		if destination.Type == models.DestinationAndroidPush || destination.Type == models.DestinationWebPush {
+			destination.Metadata["account_id"] = accountID.String()
+		}
+
+		if err := destination.ValidateMetadata(); err != nil {
+			warnings = append(warnings, fmt.Sprintf("reminder %s: destination %s left out: %v", archived.ID, archivedDestination.ID, err))
+			continue
+		}
+		// Same guard as reminder creation
+		if destination.Type == models.DestinationEmail && IsHourlyReminder(reminder) {
+			warnings = append(warnings, fmt.Sprintf("reminder %s: email destination left out of an hourly reminder", archived.ID))
+			continue
+		}
+		// Archives carry no signing secret, webhooks get a new one
+		if secret, err := PrepareWebhookSigning(&destination, nil); err != nil {
+			return nil, nil, err
+		} else if secret != "" {
+			warnings = append(warnings, fmt.Sprintf("reminder %s: webhook destination %s has a new signing secret, rotate it to read it", archived.ID, archivedDestination.ID))
+		}
+		reminder.Destinations = append(reminder.Destinations, destination)
+	}
+
+	return reminder, warnings, nil
+}
+
+// reminderSignature identifies the same reminder across accounts
+func reminderSignature(message string, remindAt time.Time, recurrence int16) string {
+	return fmt.Sprintf("%s|%d|%d", message, remindAt.Unix(), GetRecurrenceType(int(recurrence)))
+}
+
+// AccountArchiveService exports the data of an account and restores it from an archive
+type AccountArchiveService struct {
+	repos *repositories.Repositories
+}
+
+// NewAccountArchiveService creates a new account archive service
+func NewAccountArchiveService(repos *repositories.Repositories) *AccountArchiveService {
+	return &AccountArchiveService{repos: repos}
+}
+
+// Export builds the archive of an account
+func (s *AccountArchiveService) Export(accountID uuid.UUID) (*AccountArchive, error) {
+	account, err := s.repos.Account.GetWithTimezone(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch account: %w", err)
+	}
+	if account == nil {
+		return nil, errors.New("account not found")
+	}
+
+	identities, err := s.repos.Identity.GetByAccountID(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch identities: %w", err)
+	}
+	account.Identities = identities
+
+	reminders, err := s.repos.Reminder.GetByAccountIDWithDestinations(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch reminders: %w", err)
+	}
+
+	var reminderErrors []models.ReminderError
+	for _, reminder := range reminders {
+		errs, err := s.repos.ReminderError.GetByReminderID(reminder.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch errors of reminder %s: %w", reminder.ID, err)
+		}
+		reminderErrors = append(reminderErrors, errs...)
+	}
+
+	note, err := s.repos.DFMNote.GetWithItems(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch note: %w", err)
+	}
+
+	return NewAccountArchive(account, reminders, reminderErrors, note), nil
+}
+
+// Import restores an archive into an account in a single transaction. The account
+// profile and identities of the archive are informational and never restored: they
+// would let an archive attach someone else's login to the account.
+func (s *AccountArchiveService) Import(ctx context.Context, accountID uuid.UUID, archive *AccountArchive, mode ArchiveConflictMode) (*AccountImportReport, error) {
+	if !mode.IsValid() {
+		return nil, fmt.Errorf("invalid conflict mode: %s", mode)
+	}
+
+	account, err := s.repos.Account.GetWithTimezone(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch account: %w", err)
+	}
+	if account == nil {
+		return nil, errors.New("account not found")
+	}
+
+	report := &AccountImportReport{Mode: mode, Warnings: []string{}}
+
+	// The timezone of the archive only replaces an existing one outside skip mode
+	var timezone *models.Timezone
+	if archive.Timezone != nil && (account.Timezone == nil || mode != ArchiveConflictSkip) {
+		timezone, err = s.repos.Timezone.GetByIANALocation(archive.Timezone.IANALocation)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch timezone: %w", err)
+		}
+		if timezone == nil {
+			report.warn("timezone %s is not supported", archive.Timezone.IANALocation)
+		}
+	}
+
+	ianaLocation := "UTC"
+	switch {
+	case timezone != nil:
+		ianaLocation = timezone.IANALocation
+	case account.Timezone != nil:
+		ianaLocation = account.Timezone.IANALocation
+	}
+
+	var restored []uuid.UUID
+	db := database.GetDB()
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if timezone != nil && (account.TimezoneID == nil || *account.TimezoneID != timezone.ID) {
+			if err := tx.Model(&models.Account{}).Where("id = ?", accountID).Update("timezone_id", timezone.ID).Error; err != nil {
+				return fmt.Errorf("updating timezone: %w", err)
+			}
+			report.TimezoneUpdated = true
+		}
+
+		if mode == ArchiveConflictReplace {
+			// Destinations and errors follow through the foreign key cascade
+			if err := tx.Where("account_id = ?", accountID).Delete(&models.Reminder{}).Error; err != nil {
+				return fmt.Errorf("deleting reminders: %w", err)
+			}
+		}
+
+		restored, err = importArchiveReminders(tx, accountID, archive, mode, ianaLocation, report)
+		if err != nil {
+			return err
+		}
+
+		if archive.DFMNote != nil {
+			return importArchiveDFMNote(tx, accountID, archive.DFMNote, mode, ianaLocation, report)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if report.TimezoneUpdated {
+		if err := invalidateCacheByAccountID(accountID); err != nil {
+			log.Printf("[ARCHIVE] Warning: failed to invalidate account cache: %v", err)
+		}
+	}
+	// The rows were not visible to the scheduler before the commit
+	if len(restored) > 0 {
+		s.repos.Reminder.NotifyCreated(restored[0])
+	}
+
+	return report, nil
+}
+
+// importArchiveReminders restores the reminders of an archive, then the errors of the
+// destinations that were restored. It returns the IDs of the restored reminders.
+func importArchiveReminders(tx *gorm.DB, accountID uuid.UUID, archive *AccountArchive, mode ArchiveConflictMode, ianaLocation string, report *AccountImportReport) ([]uuid.UUID, error) {
+	var existing []models.Reminder
+	if err := tx.Where("account_id = ?", accountID).Find(&existing).Error; err != nil {
+		return nil, fmt.Errorf("fetching reminders: %w", err)
+	}
+	existingIDs := make(map[uuid.UUID]bool, len(existing))
+	signatures := make(map[string]bool, len(existing))
+	for _, reminder := range existing {
+		existingIDs[reminder.ID] = true
+		signatures[reminderSignature(reminder.Message, reminder.RemindAtUTC, reminder.Recurrence)] = true
+	}
+
+	// Archived destination ID -> restored destination ID
+	restoredDestinations := make(map[uuid.UUID]uuid.UUID)
+	restoredReminders := make(map[uuid.UUID]uuid.UUID)
+	var restored []uuid.UUID
+
+	for i := range archive.Reminders {
+		archived := &archive.Reminders[i]
+
+		if mode == ArchiveConflictSkip && (existingIDs[archived.ID] || signatures[reminderSignature(archived.Message, archived.RemindAtUTC, archived.Recurrence)]) {
+			report.RemindersSkipped++
+			continue
+		}
+
+		reminder, warnings, err := RestoreArchiveReminder(archived, accountID, ianaLocation)
+		report.Warnings = append(report.Warnings, warnings...)
+		if err != nil {
+			report.warn("reminder %s: %v", archived.ID, err)
+		}
+		if reminder == nil {
+			report.RemindersSkipped++
+			continue
+		}
+		report.DestinationsSkipped += len(archived.Destinations) - len(reminder.Destinations)
+
+		overwrite := existingIDs[archived.ID]
+		if overwrite {
+			if err := tx.Where("id = ? AND account_id = ?", archived.ID, accountID).Delete(&models.Reminder{}).Error; err != nil {
+				return nil, fmt.Errorf("overwriting reminder %s: %w", archived.ID, err)
+			}
+		} else {
+			// IDs are global: an archive restored next to its source account gets new ones
+			var count int64
+			if err := tx.Model(&models.Reminder{}).Where("id = ?", archived.ID).Count(&count).Error; err != nil {
+				return nil, fmt.Errorf("checking reminder %s: %w", archived.ID, err)
+			}
+			if count > 0 || reminder.ID == uuid.Nil {
+				reminder.ID = uuid.New()
+			}
+		}
+
+		// Destinations are created explicitly once the reminder row exists
+		destinations := reminder.Destinations
+		reminder.Destinations = nil
+		if err := tx.Create(reminder).Error; err != nil {
+			return nil, fmt.Errorf("creating reminder %s: %w", archived.ID, err)
+		}
+		for j := range destinations {
+			archivedID := destinations[j].ID
+			destinations[j].ID = uuid.New()
+			destinations[j].ReminderID = reminder.ID
+			if err := tx.Create(&destinations[j]).Error; err != nil {
+				return nil, fmt.Errorf("creating destination of reminder %s: %w", archived.ID, err)
+			}
+			restoredDestinations[archivedID] = destinations[j].ID
+		}
+
+		restoredReminders[archived.ID] = reminder.ID
+		restored = append(restored, reminder.ID)
+
+		existingIDs[reminder.ID] = true
+		if overwrite {
+			report.RemindersOverwrote++
+		} else {
+			report.RemindersCreated++
+		}
+	}
+
+	for _, archivedError := range archive.ReminderErrors {
+		reminderID, reminderRestored := restoredReminders[archivedError.ReminderID]
+		destinationID, destinationRestored := restoredDestinations[archivedError.ReminderDestinationID]
+		if !reminderRestored || !destinationRestored {
+			continue
+		}
+
+		reminderError := &models.ReminderError{
+			ReminderID:            reminderID,
+			ReminderDestinationID: destinationID,
+			Timestamp:             archivedError.Timestamp,
+			Stacktrace:            archivedError.Stacktrace,
+			Fixed:                 archivedError.Fixed,
+		}
+		if err := tx.Create(reminderError).Error; err != nil {
+			return nil, fmt.Errorf("restoring reminder error: %w", err)
+		}
+		report.ErrorsRestored++
+	}
+
+	return restored, nil
+}
+
+// importArchiveDFMNote restores the note schedule and adds the items the note lacks
+func importArchiveDFMNote(tx *gorm.DB, accountID uuid.UUID, archived *ArchiveDFMNote, mode ArchiveConflictMode, ianaLocation string, report *AccountImportReport) error {
+	var note models.DFMNote
+	err := tx.Where("account_id = ?", accountID).First(&note).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		note = models.DFMNote{AccountID: accountID, SendDiscordDM: true}
+		if err := tx.Create(&note).Error; err != nil {
+			return fmt.Errorf("creating dfm note: %w", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("fetching dfm note: %w", err)
+	}
+
+	if mode == ArchiveConflictReplace {
+		if err := tx.Where("note_id = ?", note.ID).Delete(&models.DFMItem{}).Error; err != nil {
+			return fmt.Errorf("deleting dfm items: %w", err)
+		}
+	}
+
+	if archived.RemindAtUTC != nil && (!note.HasReminder() || mode != ArchiveConflictSkip) {
+		remindAt := archived.RemindAtUTC.UTC()
+		keep := true
+		if !remindAt.After(time.Now()) {
+			if GetRecurrenceType(int(archived.Recurrence)) == RecurrenceOnce {
+				report.warn("the note reminder is in the past")
+				keep = false
+			} else if next, err := GetNextOccurrence(remindAt, int(archived.Recurrence), ianaLocation); err == nil {
+				remindAt = next.UTC()
+			} else {
+				report.warn("the note reminder cannot be restored: %v", err)
+				keep = false
+			}
+		}
+
+		if keep {
+			note.RemindAtUTC = &remindAt
+			note.NextFireUTC = &remindAt
+			note.Recurrence = archived.Recurrence
+			note.SendDiscordDM = archived.SendDiscordDM
+			note.SendEmail = archived.SendEmail
+			if err := tx.Save(&note).Error; err != nil {
+				return fmt.Errorf("restoring dfm schedule: %w", err)
+			}
+			report.DFMScheduleRestored = true
+		}
+	}
+
+	var items []models.DFMItem
+	if err := tx.Where("note_id = ?", note.ID).Find(&items).Error; err != nil {
+		return fmt.Errorf("fetching dfm items: %w", err)
+	}
+	contents := make(map[string]bool, len(items))
+	position := -1
+	for _, item := range items {
+		contents[item.Content] = true
+		if item.Position > position {
+			position = item.Position
+		}
+	}
+
+	for _, archivedItem := range archived.Items {
+		if strings.TrimSpace(archivedItem.Content) == "" || contents[archivedItem.Content] {
+			report.DFMItemsSkipped++
+			continue
+		}
+		position++
+		item := &models.DFMItem{
+			NoteID:   note.ID,
+			Content:  archivedItem.Content,
+			Checked:  archivedItem.Checked,
+			Position: position,
+		}
+		if err := tx.Create(item).Error; err != nil {
+			return fmt.Errorf("creating dfm item: %w", err)
+		}
+		contents[item.Content] = true
+		report.DFMItemsCreated++
+	}
+
+	return nil
+}
@@ -2,8 +2,10 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/ericp/chronos-bot-reminder/internal/database"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
@@ -14,7 +16,8 @@ import (
 
 
 // MergeAccounts merges mergedID into survivorID in a single transaction.
-// All data (reminders, identities, FCM tokens, DFM note/items) is moved to the
+// All data (reminders and their deliveries, identities, FCM tokens, Web Push
+// subscriptions, phone numbers and telephony usage, DFM note/items) is moved to the
 // survivor. The merged account is deleted. If the survivor already has
 // email/password credentials, they are kept; otherwise the merged account's
 // credentials are adopted.
@@ -33,6 +36,40 @@ func MergeAccounts(ctx context.Context, repos *repositories.Repositories, surviv
 			return fmt.Errorf("re-pointing reminders: %w", err)
 		}
 
+		// Re-point the delivery history, it would otherwise be lost with the merged account
+		if err := tx.Model(&models.ReminderDelivery{}).
+			Where("account_id = ?", mergedID).
+			Update("account_id", survivorID).Error; err != nil {
+			return fmt.Errorf("re-pointing reminder deliveries: %w", err)
+		}
+
+		// Push destinations name the account whose devices they notify, the moved ones now
+		// notify the survivor's
+		var pushDestinations []models.ReminderDestination
+		if err := tx.Where("type IN ? AND reminder_id IN (?)",
+			[]models.DestinationType{models.DestinationWebPush, models.DestinationAndroidPush},
+			tx.Model(&models.Reminder{}).Select("id").Where("account_id = ?", survivorID)).
+			Find(&pushDestinations).Error; err != nil {
+			return fmt.Errorf("fetching push destinations: %w", err)
+		}
+		for i := range pushDestinations {
+			dest := &pushDestinations[i]
+			if accountID, _ := dest.Metadata["account_id"].(string); accountID != mergedID.String() {
+				continue
+			}
+			dest.Metadata["account_id"] = survivorID.String()
+			if err := tx.Model(dest).Update("metadata", dest.Metadata).Error; err != nil {
+				return fmt.Errorf("re-pointing push destination %s: %w", dest.ID, err)
+			}
+		}
+
+		// Re-point Web Push subscriptions, an endpoint belongs to a single account
+		if err := tx.Model(&models.WebPushSubscription{}).
+			Where("account_id = ?", mergedID).
+			Update("account_id", survivorID).Error; err != nil {
+			return fmt.Errorf("re-pointing web push subscriptions: %w", err)
+		}
+
 		// Re-point FCM tokens
 		if err := tx.Model(&models.FcmToken{}).
 			Where("account_id = ?", mergedID).
@@ -40,6 +77,68 @@ func MergeAccounts(ctx context.Context, repos *repositories.Repositories, surviv
 			return fmt.Errorf("re-pointing fcm tokens: %w", err)
 		}
 
+		// Re-point phone numbers, a number both accounts have is kept once and stays verified
+		var mergedNumbers []models.PhoneNumber
+		if err := tx.Where("account_id = ?", mergedID).Find(&mergedNumbers).Error; err != nil {
+			return fmt.Errorf("fetching merged phone numbers: %w", err)
+		}
+		for i := range mergedNumbers {
+			number := &mergedNumbers[i]
+			var existing models.PhoneNumber
+			err := tx.Where("account_id = ? AND number = ?", survivorID, number.Number).First(&existing).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				if err := tx.Model(number).Update("account_id", survivorID).Error; err != nil {
+					return fmt.Errorf("moving phone number %s: %w", number.ID, err)
+				}
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("fetching survivor phone number: %w", err)
+			}
+
+			// Survivor already has the number — carry over the verification, drop the duplicate
+
+			if !existing.IsVerified() && number.IsVerified() {
+				if err := tx.Model(&existing).Update("verified_at", number.VerifiedAt).Error; err != nil {
+					return fmt.Errorf("verifying survivor phone number: %w", err)
+				}
+			}
+			if err := tx.Delete(number).Error; err != nil {
+				return fmt.Errorf("deleting duplicate phone number: %w", err)
+			}
+		}
+
+		// Re-point the telephony usage, the counts of a month both accounts used are summed
+		// so the merge does not reset the monthly quotas
+		var mergedUsages []models.TelephonyUsage
+		if err := tx.Where("account_id = ?", mergedID).Find(&mergedUsages).Error; err != nil {
+			return fmt.Errorf("fetching merged telephony usage: %w", err)
+		}
+		for _, usage := range mergedUsages {
+			result := tx.Model(&models.TelephonyUsage{}).
+				Where("account_id = ? AND month = ?", survivorID, usage.Month).
+				Updates(map[string]interface{}{
+					"sms_count":   gorm.Expr("sms_count + ?", usage.SMSCount),
+					"voice_count": gorm.Expr("voice_count + ?", usage.VoiceCount),
+					"updated_at":  time.Now(),
+				})
+			if result.Error != nil {
+				return fmt.Errorf("adding telephony usage of %s: %w", usage.Month, result.Error)
+			}
+
+			if result.RowsAffected > 0 {
+				if err := tx.Where("account_id = ? AND month = ?", mergedID, usage.Month).Delete(&models.TelephonyUsage{}).Error; err != nil {
+					return fmt.Errorf("deleting merged telephony usage of %s: %w", usage.Month, err)
+				}
+				continue
+			}
+			if err := tx.Model(&models.TelephonyUsage{}).
+				Where("account_id = ? AND month = ?", mergedID, usage.Month).
+				Update("account_id", survivorID).Error; err != nil {
+				return fmt.Errorf("moving telephony usage of %s: %w", usage.Month, err)
+			}
+		}
+
 		// Re-point identities (discord / mobile / api_key rows of merged account)
 		var mergedIdentities []models.Identity
 		if err := tx.Where("account_id = ?", mergedID).Find(&mergedIdentities).Error; err != nil {
@@ -6,11 +6,13 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
 	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
 	"github.com/google/uuid"
 )
 
@@ -33,12 +35,16 @@ func NewAPIKeyService(
 
 // APIKeyMetadata holds metadata about an API key for responses
 type APIKeyMetadata struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Scopes    string    `json:"scopes"`
-	CreatedAt time.Time `json:"created_at"`
-	LastUsed  *time.Time `json:"last_used,omitempty"`
-	Key       string    `json:"key,omitempty"` // Only populated on creation
+	ID                   string     `json:"id"`
+	Name                 string     `json:"name"`
+	Scopes               string     `json:"scopes"`
+	CreatedAt            time.Time  `json:"created_at"`
+	LastUsed             *time.Time `json:"last_used,omitempty"`
+	LastUsedIP           *string    `json:"last_used_ip,omitempty"`
+	ExpiresAt            *time.Time `json:"expires_at,omitempty"`
+	Expired              bool       `json:"expired"`
+	PreviousKeyExpiresAt *time.Time `json:"previous_key_expires_at,omitempty"` // end of the grace period after a rotation
+	Key                  string     `json:"key,omitempty"`                     // Only populated on creation and rotation
 }
 
 const (
@@ -46,8 +52,110 @@ const (
 	APIKeyPrefix = "ck_"
 	// APIKeyLength is the length of the random part of the API key
 	APIKeyLength = 48
+	// DefaultAPIKeyRotationGrace is how long the replaced key keeps working after a rotation
+	DefaultAPIKeyRotationGrace = 24 * time.Hour
+	// MaxAPIKeyRotationGrace bounds the grace period of a rotation
+	MaxAPIKeyRotationGrace = 7 * 24 * time.Hour
+	// apiKeyLastUsedResolution limits the writes recording the last use of a key
+	apiKeyLastUsedResolution = time.Minute
 )
 
+var (
+	// ErrAPIKeyExpired is returned when an expired API key is used or rotated
+	ErrAPIKeyExpired = errors.New("API key expired")
+	// ErrInvalidAPIKeyExpiry is returned when a key is requested with an expiry in the past
+	ErrInvalidAPIKeyExpiry = errors.New("API key expiry must be in the future")
+	// ErrInvalidAPIKeyGracePeriod is returned for a rotation grace period out of bounds
+	ErrInvalidAPIKeyGracePeriod = fmt.Errorf("grace period must be between 0 and %d minutes", int(MaxAPIKeyRotationGrace.Minutes()))
+)
+
+// API key scopes. A write scope also grants the read scope of the same resource.
+const (
+	ScopeRemindersRead  = "reminders.read"
+	ScopeRemindersWrite = "reminders.write"
+	ScopeDFMRead        = "dfm.read"
+	ScopeDFMWrite       = "dfm.write"
+	ScopeAccountRead    = "account.read"
+	ScopeAccountWrite   = "account.write"
+
+	// DefaultAPIKeyScope is granted to keys created without scopes, and to keys created
+	// before scopes could be chosen
+	DefaultAPIKeyScope = ScopeRemindersRead
+)
+
+// APIKeyScopes lists the scopes an API key can be granted
+var APIKeyScopes = []string{
+	ScopeRemindersRead,
+	ScopeRemindersWrite,
+	ScopeDFMRead,
+	ScopeDFMWrite,
+	ScopeAccountRead,
+	ScopeAccountWrite,
+}
+
+// ErrInvalidAPIKeyScope is returned when a key is requested with an unknown scope
+var ErrInvalidAPIKeyScope = errors.New("invalid API key scope")
+
+// NormalizeAPIKeyScopes validates requested scopes and returns them sorted and
+// deduplicated. No scope at all means DefaultAPIKeyScope.
+func NormalizeAPIKeyScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return []string{DefaultAPIKeyScope}, nil
+	}
+
+	seen := make(map[string]bool, len(scopes))
+	var normalized []string
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		valid := false
+		for _, known := range APIKeyScopes {
+			if scope == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("%w: %q (must be one of %s)", ErrInvalidAPIKeyScope, scope, strings.Join(APIKeyScopes, ", "))
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			normalized = append(normalized, scope)
+		}
+	}
+
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// ParseAPIKeyScopes splits the comma-separated scopes stored on an API key identity
+func ParseAPIKeyScopes(stored *string) []string {
+	if stored == nil || strings.TrimSpace(*stored) == "" {
+		return []string{DefaultAPIKeyScope}
+	}
+
+	var scopes []string
+	for _, scope := range strings.Split(*stored, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// HasAPIKeyScope reports whether granted scopes allow required, write implying read
+func HasAPIKeyScope(granted []string, required string) bool {
+	resource, access, _ := strings.Cut(required, ".")
+	for _, scope := range granted {
+		if scope == required {
+			return true
+		}
+		if access == "read" && scope == resource+".write" {
+			return true
+		}
+	}
+	return false
+}
+
 // GenerateAPIKey generates a new API key
 func GenerateAPIKey() (string, error) {
 	randomBytes := make([]byte, APIKeyLength)
@@ -70,8 +178,18 @@ func HashAPIKey(key string) string {
 	return base64.StdEncoding.EncodeToString(hash[:])
 }
 
-// CreateAPIKey creates a new API key for an account
-func (s *APIKeyService) CreateAPIKey(accountID uuid.UUID, name string) (*APIKeyMetadata, error) {
+// CreateAPIKey creates a new API key for an account with the given scopes. A nil
+// expiresAt creates a key that never expires.
+func (s *APIKeyService) CreateAPIKey(accountID uuid.UUID, name string, scopes []string, expiresAt *time.Time) (*APIKeyMetadata, error) {
+	scopes, err := NormalizeAPIKeyScopes(scopes)
+	if err != nil {
+		return nil, err
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, ErrInvalidAPIKeyExpiry
+	}
+	storedScopes := strings.Join(scopes, ",")
+
 	// Check if account already has 5 API keys
 	identities, err := s.identityRepo.GetByAccountID(accountID)
 	if err != nil {
@@ -106,7 +224,8 @@ func (s *APIKeyService) CreateAPIKey(accountID uuid.UUID, name string) (*APIKeyM
 		ExternalID: fmt.Sprintf("%s_%s", name, uuid.New().String()[:8]), // Make it human-readable
 		Username:  &name,
 		AccessToken: &hashedKey,
-		Scopes:    stringPtr("reminders.read"), // Default scope
+		Scopes:    &storedScopes,
+		ExpiresAt: expiresAt,
 		CreatedAt: time.Now(),
 	}
 
@@ -114,13 +233,33 @@ func (s *APIKeyService) CreateAPIKey(accountID uuid.UUID, name string) (*APIKeyM
 		return nil, fmt.Errorf("failed to create API key identity: %w", err)
 	}
 
-	return &APIKeyMetadata{
-		ID:        identity.ID.String(),
-		Name:      name,
-		Scopes:    "reminders.read",
-		CreatedAt: identity.CreatedAt,
-		Key:       plainKey, // Only return on creation
-	}, nil
+	metadata := apiKeyMetadata(identity)
+	metadata.Key = plainKey // Only return on creation
+	return metadata, nil
+}
+
+// apiKeyMetadata describes an API key identity, without its secret
+func apiKeyMetadata(identity *models.Identity) *APIKeyMetadata {
+	name := ""
+	if identity.Username != nil {
+		name = *identity.Username
+	}
+
+	now := time.Now()
+	metadata := &APIKeyMetadata{
+		ID:         identity.ID.String(),
+		Name:       name,
+		Scopes:     strings.Join(ParseAPIKeyScopes(identity.Scopes), ","),
+		CreatedAt:  identity.CreatedAt,
+		LastUsed:   identity.LastUsedAt,
+		LastUsedIP: identity.LastUsedIP,
+		ExpiresAt:  identity.ExpiresAt,
+		Expired:    identity.ExpiresAt != nil && !identity.ExpiresAt.After(now),
+	}
+	if identity.PreviousAccessToken != nil && identity.PreviousExpiresAt != nil && identity.PreviousExpiresAt.After(now) {
+		metadata.PreviousKeyExpiresAt = identity.PreviousExpiresAt
+	}
+	return metadata
 }
 
 // GetAPIKeys retrieves all API keys for an account (masked)
@@ -131,88 +270,171 @@ func (s *APIKeyService) GetAPIKeys(accountID uuid.UUID) ([]APIKeyMetadata, error
 	}
 
 	var keys []APIKeyMetadata
-	for _, identity := range identities {
-		if identity.Provider == models.ProviderAPIKey {
-			name := ""
-			if identity.Username != nil {
-				name = *identity.Username
-			}
-
-			scopes := "reminders.read"
-			if identity.Scopes != nil {
-				scopes = *identity.Scopes
-			}
-
-			keys = append(keys, APIKeyMetadata{
-				ID:        identity.ID.String(),
-				Name:      name,
-				Scopes:    scopes,
-				CreatedAt: identity.CreatedAt,
-			})
+	for i := range identities {
+		if identities[i].Provider == models.ProviderAPIKey {
+			keys = append(keys, *apiKeyMetadata(&identities[i]))
 		}
 	}
 
 	return keys, nil
 }
 
-// RevokeAPIKey revokes (deletes) an API key
-func (s *APIKeyService) RevokeAPIKey(accountID uuid.UUID, keyID string) error {
+// ownedAPIKey loads an API key identity and checks that it belongs to the account
+func (s *APIKeyService) ownedAPIKey(accountID uuid.UUID, keyID string) (*models.Identity, error) {
 	keyUUID, err := uuid.Parse(keyID)
 	if err != nil {
-		return fmt.Errorf("invalid key ID: %w", err)
+		return nil, fmt.Errorf("invalid key ID: %w", err)
 	}
 
 	identity, err := s.identityRepo.GetByID(keyUUID)
 	if err != nil {
-		return fmt.Errorf("failed to fetch identity: %w", err)
+		return nil, fmt.Errorf("failed to fetch identity: %w", err)
 	}
 
 	if identity == nil {
-		return errors.New("API key not found")
+		return nil, errors.New("API key not found")
 	}
 
 	// Verify ownership
 	if identity.AccountID != accountID {
-		return errors.New("unauthorized")
+		return nil, errors.New("unauthorized")
 	}
 
 	if identity.Provider != models.ProviderAPIKey {
-		return errors.New("not an API key")
+		return nil, errors.New("not an API key")
+	}
+
+	return identity, nil
+}
+
+// RevokeAPIKey revokes (deletes) an API key
+func (s *APIKeyService) RevokeAPIKey(accountID uuid.UUID, keyID string) error {
+	identity, err := s.ownedAPIKey(accountID, keyID)
+	if err != nil {
+		return err
 	}
 
 	// Delete the identity
-	if err := s.identityRepo.Delete(keyUUID); err != nil {
+	if err := s.identityRepo.Delete(identity.ID); err != nil {
 		return fmt.Errorf("failed to revoke API key: %w", err)
 	}
 
 	return nil
 }
 
-// ValidateAPIKey validates an API key and returns the associated account ID
-func (s *APIKeyService) ValidateAPIKey(key string) (uuid.UUID, error) {
-	
+// RotateAPIKey replaces the secret of an API key, keeping its name, scopes and expiry.
+// The replaced secret keeps working for the grace period so clients can be redeployed;
+// a zero grace period invalidates it immediately.
+func (s *APIKeyService) RotateAPIKey(accountID uuid.UUID, keyID string, grace time.Duration) (*APIKeyMetadata, error) {
+	if grace < 0 || grace > MaxAPIKeyRotationGrace {
+		return nil, ErrInvalidAPIKeyGracePeriod
+	}
+
+	identity, err := s.ownedAPIKey(accountID, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if identity.ExpiresAt != nil && !identity.ExpiresAt.After(now) {
+		return nil, ErrAPIKeyExpired
+	}
+
+	plainKey, err := GenerateAPIKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	hashedKey := HashAPIKey(plainKey)
+
+	if grace > 0 && identity.AccessToken != nil {
+		previousExpiresAt := now.Add(grace)
+		identity.PreviousAccessToken = identity.AccessToken
+		identity.PreviousExpiresAt = &previousExpiresAt
+	} else {
+		identity.PreviousAccessToken = nil
+		identity.PreviousExpiresAt = nil
+	}
+	identity.AccessToken = &hashedKey
+
+	if err := s.identityRepo.Update(identity); err != nil {
+		return nil, fmt.Errorf("failed to rotate API key: %w", err)
+	}
+
+	metadata := apiKeyMetadata(identity)
+	metadata.Key = plainKey // Only return on rotation
+	return metadata, nil
+}
+
+// ValidateAPIKey validates an API key used from the given IP and returns the associated
+// account ID
+func (s *APIKeyService) ValidateAPIKey(key string, ip string) (uuid.UUID, error) {
+	identity, err := s.AuthenticateAPIKey(key, ip)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return identity.AccountID, nil
+}
+
+// AuthenticateAPIKey validates an API key used from the given IP and returns its identity,
+// which carries the account ID and the scopes of the key. The secret replaced by a rotation
+// is accepted until its grace period ends. The last use of the key is recorded.
+func (s *APIKeyService) AuthenticateAPIKey(key string, ip string) (*models.Identity, error) {
 	if !strings.HasPrefix(key, APIKeyPrefix) {
-		return uuid.Nil, errors.New("invalid API key format")
+		return nil, errors.New("invalid API key format")
 	}
 
 	// Hash the provided key
 	hashedKey := HashAPIKey(key)
+	now := time.Now()
 
 	// Find identity with matching hashed key
 	identity, err := s.identityRepo.GetByAccessToken(hashedKey)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("failed to validate API key: %w", err)
+		return nil, fmt.Errorf("failed to validate API key: %w", err)
 	}
 
+	// Fall back on the secret replaced by a rotation
 	if identity == nil {
-		return uuid.Nil, errors.New("API key not found")
+		identity, err = s.identityRepo.GetByPreviousAccessToken(hashedKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to validate API key: %w", err)
+		}
+		if identity != nil && (identity.PreviousExpiresAt == nil || !identity.PreviousExpiresAt.After(now)) {
+			identity = nil
+		}
+	}
+
+	if identity == nil {
+		return nil, errors.New("API key not found")
 	}
 
 	if identity.Provider != models.ProviderAPIKey {
-		return uuid.Nil, errors.New("invalid API key")
+		return nil, errors.New("invalid API key")
 	}
 
-	return identity.AccountID, nil
+	if identity.ExpiresAt != nil && !identity.ExpiresAt.After(now) {
+		return nil, ErrAPIKeyExpired
+	}
+
+	s.recordUsage(identity, now, ip)
+
+	return identity, nil
+}
+
+// recordUsage stores the last use of an API key. Writes are limited to one per
+// apiKeyLastUsedResolution unless the IP changes, and failures never reject the request.
+func (s *APIKeyService) recordUsage(identity *models.Identity, usedAt time.Time, ip string) {
+	sameIP := identity.LastUsedIP != nil && *identity.LastUsedIP == ip
+	if sameIP && identity.LastUsedAt != nil && usedAt.Sub(*identity.LastUsedAt) < apiKeyLastUsedResolution {
+		return
+	}
+
+	if err := s.identityRepo.UpdateLastUsed(identity.ID, usedAt, ip); err != nil {
+		logging.For(logging.API).Warn("Failed to record usage of API key", "api_key_id", identity.ID, "account_id", identity.AccountID, "error", err)
+		return
+	}
+	identity.LastUsedAt = &usedAt
+	identity.LastUsedIP = &ip
 }
 
 // Helper function to create string pointer
@@ -7,6 +7,7 @@ import (
 
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
 	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -37,6 +38,7 @@ type RegisterUserRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 	Timezone string `json:"timezone"`
+	Locale   string `json:"locale"` // language tag, ignored when not supported
 }
 
 // RegisterUser creates a new app provider account with the given credentials
@@ -78,6 +80,7 @@ func (s *AuthService) RegisterUser(ctx context.Context, req *RegisterUserRequest
 		Email:        &email,
 		Username:     &username,
 		PasswordHash: &hashedPassword,
+		Locale:       i18n.Normalize(req.Locale),
 	}
 
 	if err := s.accountRepo.Create(account); err != nil {
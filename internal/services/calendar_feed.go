@@ -0,0 +1,142 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/google/uuid"
+)
+
+const (
+	// CalendarTokenPrefix is the prefix of calendar feed tokens
+	CalendarTokenPrefix = "cal_"
+	// calendarTokenLength is the length of the random part of a calendar feed token
+	calendarTokenLength = 32
+)
+
+// ErrCalendarTokenInvalid is returned for unknown or revoked calendar feed tokens
+var ErrCalendarTokenInvalid = errors.New("invalid calendar token")
+
+// CalendarFeedService serves the reminders of an account as a subscribable iCalendar feed.
+// Calendar clients cannot send an Authorization header, so the feed URL carries a
+// per-account token. Only its hash is stored; creating a new token revokes the previous one.
+type CalendarFeedService struct {
+	accountRepo  repositories.AccountRepository
+	reminderRepo repositories.ReminderRepository
+	noteRepo     repositories.DFMNoteRepository
+}
+
+// NewCalendarFeedService creates a new calendar feed service
+func NewCalendarFeedService(
+	accountRepo repositories.AccountRepository,
+	reminderRepo repositories.ReminderRepository,
+	noteRepo repositories.DFMNoteRepository,
+) *CalendarFeedService {
+	return &CalendarFeedService{
+		accountRepo:  accountRepo,
+		reminderRepo: reminderRepo,
+		noteRepo:     noteRepo,
+	}
+}
+
+// CreateToken generates the calendar feed token of an account, replacing the previous one
+func (s *CalendarFeedService) CreateToken(accountID uuid.UUID) (string, error) {
+	account, err := s.accountRepo.GetByID(accountID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch account: %w", err)
+	}
+	if account == nil {
+		return "", errors.New("account not found")
+	}
+
+	randomBytes := make([]byte, calendarTokenLength)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	token := CalendarTokenPrefix + base64.RawURLEncoding.EncodeToString(randomBytes)
+
+	hash := HashAPIKey(token)
+	account.CalendarTokenHash = &hash
+	if err := s.accountRepo.Update(account); err != nil {
+		return "", fmt.Errorf("failed to save calendar token: %w", err)
+	}
+
+	return token, nil
+}
+
+// RevokeToken disables the calendar feed of an account
+func (s *CalendarFeedService) RevokeToken(accountID uuid.UUID) error {
+	account, err := s.accountRepo.GetByID(accountID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch account: %w", err)
+	}
+	if account == nil {
+		return errors.New("account not found")
+	}
+
+	account.CalendarTokenHash = nil
+	if err := s.accountRepo.Update(account); err != nil {
+		return fmt.Errorf("failed to revoke calendar token: %w", err)
+	}
+	return nil
+}
+
+// ValidateToken returns the account owning a calendar feed token
+func (s *CalendarFeedService) ValidateToken(token string) (uuid.UUID, error) {
+	if !strings.HasPrefix(token, CalendarTokenPrefix) {
+		return uuid.Nil, ErrCalendarTokenInvalid
+	}
+
+	account, err := s.accountRepo.GetByCalendarTokenHash(HashAPIKey(token))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to validate calendar token: %w", err)
+	}
+	if account == nil {
+		return uuid.Nil, ErrCalendarTokenInvalid
+	}
+
+	return account.ID, nil
+}
+
+// BuildFeed returns the reminders and the "Don't Forget Me" note reminder of an account as an iCalendar document
+func (s *CalendarFeedService) BuildFeed(accountID uuid.UUID) (string, error) {
+	account, err := s.accountRepo.GetWithTimezone(accountID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch account: %w", err)
+	}
+	if account == nil {
+		return "", errors.New("account not found")
+	}
+
+	ianaLocation := "UTC"
+	if account.Timezone != nil {
+		ianaLocation = account.Timezone.IANALocation
+	}
+
+	calendar, err := NewICalendar("Chronos reminders", ianaLocation)
+	if err != nil {
+		return "", err
+	}
+
+	reminders, err := s.reminderRepo.GetByAccountID(accountID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch reminders: %w", err)
+	}
+	for i := range reminders {
+		calendar.AddReminder(&reminders[i])
+	}
+
+	note, err := s.noteRepo.GetWithItems(accountID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch note: %w", err)
+	}
+	if note != nil {
+		calendar.AddDFMNote(note)
+	}
+
+	return calendar.String(), nil
+}
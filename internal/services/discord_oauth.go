@@ -12,6 +12,7 @@ import (
 
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
 	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 	"github.com/google/uuid"
 )
 
@@ -39,6 +40,8 @@ type DiscordUserInfo struct {
 	Discriminator string `json:"discriminator"`
 	// Verified reports whether Discord has verified the user's email address.
 	Verified bool `json:"verified"`
+	// Locale is the language chosen in the Discord client, e.g. "fr" or "es-ES".
+	Locale string `json:"locale"`
 }
 
 // DiscordGuild represents a Discord guild (server)
@@ -326,6 +329,7 @@ func (s *DiscordOAuthService) ProcessDiscordAuth(ctx context.Context, userInfo *
 		ID:            uuid.New(),
 		TimezoneID:    &timezone.ID,
 		EmailVerified: emailVerified,
+		Locale:        i18n.Normalize(userInfo.Locale),
 	}
 
 	if err := s.accountRepo.Create(account); err != nil {
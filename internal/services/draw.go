@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 	"github.com/fogleman/gg"
 )
 
@@ -35,6 +36,7 @@ type ProfileData struct {
 	CreatedAt    time.Time
 	ReminderCount int
 	Badges       []string // "Discord", "App"
+	Locale       string   // language of the texts, English when empty
 }
 
 // GenerateReminderImage creates a reminder image with text overlay
@@ -128,7 +130,7 @@ func (ds *DrawService) GenerateProfileImage(data ProfileData) (image.Image, erro
 			return nil, err
 		}
 		
-		dateText := fmt.Sprintf("Created on %s", ds.formatProfileDate(data.CreatedAt))
+		dateText := i18n.T(data.Locale, "profile.image.created_on", ds.formatProfileDate(data.CreatedAt))
 		dateX := 125.0 
 		dateY := 255.0
 		ds.drawLeftAlignedText(dc, dateText, dateX, dateY)
@@ -140,12 +142,10 @@ func (ds *DrawService) GenerateProfileImage(data ProfileData) (image.Image, erro
 		return nil, err
 	}
 	
-	reminderText := "No Reminders yet"
+	reminderText := i18n.T(data.Locale, "profile.image.no_reminders")
 
-	if data.ReminderCount == 1 {
-		reminderText = "1 Reminder"
-	} else if data.ReminderCount > 1 {
-		reminderText = fmt.Sprintf("%d Reminders", data.ReminderCount)
+	if data.ReminderCount > 0 {
+		reminderText = i18n.TN(data.Locale, "profile.image.reminders", data.ReminderCount, data.ReminderCount)
 	}
 	reminderX := 210.0 // Same X as date
 	reminderY := 178.0 // Below the date
@@ -7,6 +7,8 @@ import (
 
 	firebase "firebase.google.com/go/v4"
 	"firebase.google.com/go/v4/messaging"
+	"github.com/ericp/chronos-bot-reminder/internal/tracing"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/api/option"
 )
 
@@ -54,16 +56,22 @@ func (s *FcmService) IsEnabled() bool {
 // be removed from storage.
 var ErrTokenUnregistered = fmt.Errorf("fcm token unregistered")
 
-// Send delivers a single notification to one device token. When the token is no
-// longer registered it returns ErrTokenUnregistered so the caller can prune it.
+// ErrFcmUnavailable indicates FCM could not process the message right now
+// (outage, internal error or exceeded quota); the same send may succeed later.
+var ErrFcmUnavailable = fmt.Errorf("fcm temporarily unavailable")
+
+// Send delivers a single notification to one device token and returns the FCM
+// message ID. When the token is no longer registered it returns
+// ErrTokenUnregistered so the caller can prune it; temporary FCM failures are
+// reported as ErrFcmUnavailable.
 //
 // We send a data-only message (no Notification field) so that onMessageReceived
 // is always the sole delivery path on the client. This prevents the double-notification
 // problem that occurs when Firebase surfaces a Notification payload as a system
 // notification independently of the app's own display logic.
-func (s *FcmService) Send(ctx context.Context, token, title, body string, data map[string]string) error {
+func (s *FcmService) Send(ctx context.Context, token, title, body string, data map[string]string) (string, error) {
 	if !s.enabled {
-		return fmt.Errorf("fcm service is disabled")
+		return "", fmt.Errorf("fcm service is disabled")
 	}
 
 	// Merge title/body into the data map so the client can read them in onMessageReceived.
@@ -82,12 +90,17 @@ func (s *FcmService) Send(ctx context.Context, token, title, body string, data m
 		},
 	}
 
-	_, err := s.client.Send(ctx, message)
+	ctx, span := tracing.Start(ctx, "fcm.send", trace.WithSpanKind(trace.SpanKindClient))
+	messageID, err := s.client.Send(ctx, message)
+	tracing.End(span, err)
 	if err != nil {
 		if messaging.IsUnregistered(err) {
-			return ErrTokenUnregistered
+			return "", ErrTokenUnregistered
+		}
+		if messaging.IsUnavailable(err) || messaging.IsInternal(err) || messaging.IsQuotaExceeded(err) {
+			return "", fmt.Errorf("%w: %v", ErrFcmUnavailable, err)
 		}
-		return err
+		return "", err
 	}
-	return nil
+	return messageID, nil
 }
@@ -0,0 +1,351 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+)
+
+const (
+	// ICalProductID identifies Chronos as the producer of the calendars (PRODID)
+	ICalProductID = "-//Chronos//Chronos Reminder//EN"
+	// icalUIDDomain makes event UIDs globally unique
+	icalUIDDomain = "chronosrmd.com"
+	// icalLineLength is the maximum length of a content line in octets, CRLF excluded
+	icalLineLength = 75
+)
+
+// icalWeekdays maps time.Weekday to the RFC 5545 day names
+var icalWeekdays = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// ICalendar builds an RFC 5545 calendar from reminders. Events are written in the
+// user's timezone so calendar clients expand recurrences on the local wall clock,
+// the same way the scheduler does across DST transitions.
+type ICalendar struct {
+	name    string
+	loc     *time.Location
+	options RecurrenceOptions
+	stamp   time.Time
+	events  []string
+}
+
+// NewICalendar creates an empty calendar displayed under name, in the given timezone
+func NewICalendar(name string, ianaLocation string) (*ICalendar, error) {
+	loc, err := time.LoadLocation(ianaLocation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load timezone %s: %w", ianaLocation, err)
+	}
+
+	return &ICalendar{
+		name:    name,
+		loc:     loc,
+		options: DefaultRecurrenceOptions(),
+		stamp:   time.Now().UTC(),
+	}, nil
+}
+
+// AddReminder adds a reminder as a VEVENT with its recurrence as RRULE and one
+// VALARM firing at the reminder time. Paused reminders are left out since they
+// will not fire until resumed.
+func (c *ICalendar) AddReminder(reminder *models.Reminder) {
+	if IsPaused(int(reminder.Recurrence)) {
+		return
+	}
+
+	series := ReminderRecurrenceSeries(reminder)
+	start := reminder.RemindAtUTC
+	if GetRecurrenceType(int(reminder.Recurrence)) == RecurrenceRRule {
+		// COUNT and INTERVAL are counted from the start of the series
+		start = series.Start
+	}
+
+	c.addEvent(icalEvent{
+		uid:     "reminder-" + reminder.ID.String(),
+		created: reminder.CreatedAt,
+		start:   start,
+		rrule:   RecurrenceToRRule(int(reminder.Recurrence), series, c.loc, c.options),
+		summary: reminder.Message,
+	})
+}
+
+// AddDFMNote adds the reminder of a "Don't Forget Me" note, listing the unchecked
+// items in the description. Notes without a reminder are ignored.
+func (c *ICalendar) AddDFMNote(note *models.DFMNote) {
+	if !note.HasReminder() || IsPaused(int(note.Recurrence)) {
+		return
+	}
+
+	start := *note.RemindAtUTC
+	if note.NextFireUTC != nil {
+		start = *note.NextFireUTC
+	}
+
+	var items []string
+	for _, item := range note.Items {
+		if !item.Checked {
+			items = append(items, "- "+item.Content)
+		}
+	}
+
+	c.addEvent(icalEvent{
+		uid:         "dfm-" + note.ID.String(),
+		created:     note.CreatedAt,
+		start:       start,
+		rrule:       RecurrenceToRRule(int(note.Recurrence), &RecurrenceSeries{Start: start}, c.loc, c.options),
+		summary:     "Don't Forget Me",
+		description: strings.Join(items, "\n"),
+	})
+}
+
+// icalEvent holds the properties of a VEVENT
+type icalEvent struct {
+	uid         string
+	created     time.Time
+	start       time.Time
+	rrule       string
+	summary     string
+	description string
+}
+
+// addEvent serializes an event and its alarm
+func (c *ICalendar) addEvent(event icalEvent) {
+	lines := []string{
+		"BEGIN:VEVENT",
+		"UID:" + event.uid + "@" + icalUIDDomain,
+		"DTSTAMP:" + icalUTCTime(c.stamp),
+		"CREATED:" + icalUTCTime(event.created),
+		c.dateTime("DTSTART", event.start),
+	}
+	if event.rrule != "" {
+		lines = append(lines, "RRULE:"+event.rrule)
+	}
+	lines = append(lines, "SUMMARY:"+icalEscape(event.summary))
+	if event.description != "" {
+		lines = append(lines, "DESCRIPTION:"+icalEscape(event.description))
+	}
+	lines = append(lines,
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		"DESCRIPTION:"+icalEscape(event.summary),
+		"TRIGGER:PT0S",
+		"END:VALARM",
+		"END:VEVENT",
+	)
+	c.events = append(c.events, lines...)
+}
+
+// String returns the calendar, CRLF separated with long lines folded
+func (c *ICalendar) String() string {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + ICalProductID,
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + icalEscape(c.name),
+		"X-WR-TIMEZONE:" + c.loc.String(),
+	}
+	lines = append(lines, c.timezone()...)
+	lines = append(lines, c.events...)
+	lines = append(lines, "END:VCALENDAR")
+
+	var builder strings.Builder
+	for _, line := range lines {
+		builder.WriteString(icalFold(line))
+		builder.WriteString("\r\n")
+	}
+	return builder.String()
+}
+
+// dateTime formats a DATE-TIME property in the calendar timezone
+func (c *ICalendar) dateTime(name string, t time.Time) string {
+	if c.loc == time.UTC {
+		return name + ":" + icalUTCTime(t)
+	}
+	return name + ";TZID=" + c.loc.String() + ":" + t.In(c.loc).Format("20060102T150405")
+}
+
+// timezone describes the calendar timezone as a VTIMEZONE. The observances are
+// taken from this year's transitions and repeated yearly, the way most calendar
+// producers write them; clients that know the IANA name use their own rules anyway.
+func (c *ICalendar) timezone() []string {
+	if c.loc == time.UTC {
+		return nil
+	}
+
+	lines := []string{
+		"BEGIN:VTIMEZONE",
+		"TZID:" + c.loc.String(),
+		"X-LIC-LOCATION:" + c.loc.String(),
+	}
+
+	year := time.Now().Year()
+	current := time.Date(year, time.January, 1, 0, 0, 0, 0, c.loc)
+	transitions := 0
+	for {
+		_, end := current.ZoneBounds()
+		if end.IsZero() || end.Year() != year {
+			break
+		}
+		lines = append(lines, icalObservance(end)...)
+		transitions++
+		current = end
+	}
+
+	if transitions == 0 {
+		name, offset := current.Zone()
+		lines = append(lines,
+			"BEGIN:STANDARD",
+			"DTSTART:19700101T000000",
+			"TZOFFSETFROM:"+icalOffset(offset),
+			"TZOFFSETTO:"+icalOffset(offset),
+			"TZNAME:"+name,
+			"END:STANDARD",
+		)
+	}
+
+	return append(lines, "END:VTIMEZONE")
+}
+
+// icalObservance describes the zone starting at transition as a yearly STANDARD or
+// DAYLIGHT observance ("last Sunday of March at 02:00")
+func icalObservance(transition time.Time) []string {
+	_, fromOffset := transition.Add(-time.Second).Zone()
+	name, toOffset := transition.Zone()
+
+	kind := "STANDARD"
+	if toOffset > fromOffset {
+		kind = "DAYLIGHT"
+	}
+
+	// The onset is expressed in the wall-clock time in force before the change
+	onset := transition.In(time.FixedZone("", fromOffset))
+	week := (onset.Day()-1)/7 + 1
+	if onset.Day()+7 > daysInMonth(onset.Year(), onset.Month()) {
+		week = -1
+	}
+
+	return []string{
+		"BEGIN:" + kind,
+		"DTSTART:" + nthWeekday(1970, onset.Month(), onset.Weekday(), week).Format("20060102") + onset.Format("T150405"),
+		fmt.Sprintf("RRULE:FREQ=YEARLY;BYMONTH=%d;BYDAY=%d%s", int(onset.Month()), week, icalWeekdays[onset.Weekday()]),
+		"TZOFFSETFROM:" + icalOffset(fromOffset),
+		"TZOFFSETTO:" + icalOffset(toOffset),
+		"TZNAME:" + name,
+		"END:" + kind,
+	}
+}
+
+// nthWeekday returns the nth weekday of a month, counted from the end when n is negative
+func nthWeekday(year int, month time.Month, weekday time.Weekday, n int) time.Time {
+	if n < 0 {
+		last := time.Date(year, month, daysInMonth(year, month), 0, 0, 0, 0, time.UTC)
+		return last.AddDate(0, 0, -((int(last.Weekday()) - int(weekday) + 7) % 7))
+	}
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	return first.AddDate(0, 0, (int(weekday)-int(first.Weekday())+7)%7+7*(n-1))
+}
+
+// RecurrenceToRRule translates a recurrence state into an RRULE value, empty for
+// one-time reminders. The end of month and leap day policies are written with
+// BYSETPOS where RFC 5545 can express them; a rollover has no equivalent and is
+// exported as the plain rule, which skips the short months instead.
+func RecurrenceToRRule(recurrenceState int, series *RecurrenceSeries, loc *time.Location, options RecurrenceOptions) string {
+	start := series.Start.In(loc)
+
+	switch GetRecurrenceType(recurrenceState) {
+	case RecurrenceRRule:
+		return series.RRule
+	case RecurrenceHourly:
+		return "FREQ=HOURLY"
+	case RecurrenceDaily:
+		return "FREQ=DAILY"
+	case RecurrenceWeekly:
+		return "FREQ=WEEKLY"
+	case RecurrenceWorkdays:
+		return "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"
+	case RecurrenceWeekend:
+		return "FREQ=WEEKLY;BYDAY=SA,SU"
+	case RecurrenceMonthly:
+		day := start.Day()
+		if day <= 28 {
+			return fmt.Sprintf("FREQ=MONTHLY;BYMONTHDAY=%d", day)
+		}
+		if options.EndOfMonth == EndOfMonthClamp {
+			// The latest existing day among 28..day is the anchor day or the last day of a shorter month
+			days := make([]string, 0, day-27)
+			for d := 28; d <= day; d++ {
+				days = append(days, fmt.Sprint(d))
+			}
+			return "FREQ=MONTHLY;BYMONTHDAY=" + strings.Join(days, ",") + ";BYSETPOS=-1"
+		}
+		return fmt.Sprintf("FREQ=MONTHLY;BYMONTHDAY=%d", day)
+	case RecurrenceYearly:
+		if start.Month() != time.February || start.Day() != 29 {
+			return fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d;BYMONTHDAY=%d", int(start.Month()), start.Day())
+		}
+		switch options.LeapDay {
+		case LeapDayMar1:
+			// The 60th day of the year is Feb 29 on leap years and Mar 1 otherwise
+			return "FREQ=YEARLY;BYYEARDAY=60"
+		case LeapDaySkip:
+			return "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29"
+		}
+		return "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=28,29;BYSETPOS=-1"
+	}
+	return ""
+}
+
+// icalUTCTime formats a UTC DATE-TIME value
+func icalUTCTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// icalOffset formats a UTC offset in seconds as +hhmm
+func icalOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset%3600/60)
+}
+
+// icalEscape escapes a TEXT value
+func icalEscape(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(text)
+}
+
+// icalFold splits a content line longer than 75 octets, continuation lines starting
+// with a space. Lines are only cut between UTF-8 characters.
+func icalFold(line string) string {
+	if len(line) <= icalLineLength {
+		return line
+	}
+
+	var builder strings.Builder
+	limit := icalLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		builder.WriteString(line[:cut])
+		builder.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space counts in the next line's 75 octets
+		limit = icalLineLength - 1
+	}
+	builder.WriteString(line)
+	return builder.String()
+}
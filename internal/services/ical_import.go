@@ -0,0 +1,502 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/teambition/rrule-go"
+)
+
+const (
+	// MaxICalImportEntries bounds the number of entries read from one document
+	MaxICalImportEntries = 500
+	// icalAllDayHour is the local hour an all-day entry without alarm reminds at
+	icalAllDayHour = 9
+)
+
+// ICalEntry is a VEVENT or VTODO read from an iCalendar document
+type ICalEntry struct {
+	Component    string // VEVENT or VTODO
+	UID          string
+	Summary      string
+	Start        time.Time // DTSTART, or DUE for a task without start; zero when missing
+	AllDay       bool      // DATE value, Start is midnight in the entry timezone
+	RRule        string
+	Status       string
+	RecurrenceID bool        // RECURRENCE-ID: a modified occurrence of another entry
+	Alarms       []time.Time // VALARM triggers resolved against Start
+	Warnings     []string    // parts of the entry that were ignored
+}
+
+// ICalImportIssue explains why an entry does not become a reminder
+type ICalImportIssue struct {
+	Unsupported bool // the entry needs something Chronos cannot represent, otherwise it was skipped on purpose
+	Reason      string
+}
+
+func (e *ICalImportIssue) Error() string {
+	return e.Reason
+}
+
+func skipEntry(reason string) error {
+	return &ICalImportIssue{Reason: reason}
+}
+
+func unsupportedEntry(reason string) error {
+	return &ICalImportIssue{Unsupported: true, Reason: reason}
+}
+
+// icalProperty is a content line split into its name, parameters and value
+type icalProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// ParseICalendar reads the VEVENT and VTODO entries of an iCalendar document.
+// Times without TZID, and TZIDs that are not IANA names, are read in defaultLoc.
+func ParseICalendar(data string, defaultLoc *time.Location) ([]ICalEntry, error) {
+	lines := unfoldICal(data)
+	if len(lines) == 0 || !strings.EqualFold(strings.TrimSpace(lines[0]), "BEGIN:VCALENDAR") {
+		return nil, errors.New("not an iCalendar document: BEGIN:VCALENDAR expected")
+	}
+
+	var entries []ICalEntry
+	var entry *ICalEntry
+	var alarm []icalProperty
+	var stack []string
+	var startProp, dueProp *icalProperty
+	var offsets []time.Duration // relative VALARM triggers of the current entry
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		prop, err := parseICalLine(line)
+		if err != nil {
+			return nil, err
+		}
+
+		switch prop.name {
+		case "BEGIN":
+			component := strings.ToUpper(prop.value)
+			stack = append(stack, component)
+			switch {
+			case (component == "VEVENT" || component == "VTODO") && entry == nil:
+				if len(entries) >= MaxICalImportEntries {
+					return nil, fmt.Errorf("too many entries, at most %d can be imported at once", MaxICalImportEntries)
+				}
+				entry = &ICalEntry{Component: component}
+				startProp, dueProp, offsets = nil, nil, nil
+			case component == "VALARM" && entry != nil:
+				alarm = []icalProperty{}
+			}
+			continue
+		case "END":
+			component := strings.ToUpper(prop.value)
+			if len(stack) == 0 || stack[len(stack)-1] != component {
+				return nil, fmt.Errorf("unexpected END:%s", prop.value)
+			}
+			stack = stack[:len(stack)-1]
+			switch {
+			case component == "VALARM" && alarm != nil:
+				if offset, relative := entry.addAlarm(alarm); relative {
+					offsets = append(offsets, offset)
+				}
+				alarm = nil
+			case (component == "VEVENT" || component == "VTODO") && entry != nil:
+				entry.resolveStart(startProp, dueProp, offsets, defaultLoc)
+				entries = append(entries, *entry)
+				entry = nil
+			}
+			continue
+		}
+
+		if alarm != nil {
+			alarm = append(alarm, prop)
+			continue
+		}
+		if entry == nil || stack[len(stack)-1] != entry.Component {
+			continue
+		}
+
+		switch prop.name {
+		case "UID":
+			entry.UID = prop.value
+		case "SUMMARY":
+			entry.Summary = unescapeICalText(prop.value)
+		case "STATUS":
+			entry.Status = strings.ToUpper(prop.value)
+		case "RRULE":
+			entry.RRule = prop.value
+		case "DTSTART":
+			startProp = &prop
+		case "DUE":
+			dueProp = &prop
+		case "RECURRENCE-ID":
+			entry.RecurrenceID = true
+		case "EXDATE", "RDATE", "EXRULE":
+			entry.warn(prop.name + " is not supported, every occurrence of the rule is kept")
+		}
+	}
+
+	if len(stack) != 0 {
+		return nil, fmt.Errorf("unterminated %s component", stack[len(stack)-1])
+	}
+	return entries, nil
+}
+
+func (e *ICalEntry) warn(warning string) {
+	for _, existing := range e.Warnings {
+		if existing == warning {
+			return
+		}
+	}
+	e.Warnings = append(e.Warnings, warning)
+}
+
+// resolveStart sets the start of the entry from DTSTART, or DUE for a task, and the
+// alarms at an offset from it
+func (e *ICalEntry) resolveStart(startProp, dueProp *icalProperty, offsets []time.Duration, defaultLoc *time.Location) {
+	prop := startProp
+	if prop == nil && e.Component == "VTODO" {
+		prop = dueProp
+	}
+	if prop == nil {
+		return
+	}
+
+	start, allDay, warning, err := parseICalDateTime(*prop, defaultLoc)
+	if err != nil {
+		e.warn(err.Error())
+		return
+	}
+	if warning != "" {
+		e.warn(warning)
+	}
+	e.Start = start
+	e.AllDay = allDay
+
+	for _, offset := range offsets {
+		e.Alarms = append(e.Alarms, start.Add(offset))
+	}
+}
+
+// addAlarm records the absolute trigger of a VALARM, a relative one is returned as an
+// offset to apply once the start of the entry is known
+func (e *ICalEntry) addAlarm(props []icalProperty) (time.Duration, bool) {
+	for _, prop := range props {
+		if prop.name != "TRIGGER" {
+			continue
+		}
+
+		if strings.EqualFold(prop.params["VALUE"], "DATE-TIME") {
+			trigger, _, _, err := parseICalDateTime(prop, time.UTC)
+			if err != nil {
+				e.warn("invalid VALARM trigger: " + err.Error())
+				return 0, false
+			}
+			e.Alarms = append(e.Alarms, trigger)
+			return 0, false
+		}
+
+		offset, err := parseICalDuration(prop.value)
+		if err != nil {
+			e.warn("invalid VALARM trigger: " + err.Error())
+			return 0, false
+		}
+		if strings.EqualFold(prop.params["RELATED"], "END") {
+			e.warn("VALARM relative to the end is read relative to the start")
+		}
+		return offset, true
+	}
+	return 0, false
+}
+
+// unfoldICal splits a document into content lines, joining folded ones
+func unfoldICal(data string) []string {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	data = strings.ReplaceAll(data, "\r", "\n")
+
+	var lines []string
+	for _, line := range strings.Split(data, "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseICalLine splits "NAME;PARAM=VALUE:value", quoted parameter values may contain ':' and ';'
+func parseICalLine(line string) (icalProperty, error) {
+	prop := icalProperty{params: map[string]string{}}
+
+	inQuotes := false
+	valueStart := -1
+	for i, char := range line {
+		if char == '"' {
+			inQuotes = !inQuotes
+		} else if char == ':' && !inQuotes {
+			valueStart = i
+			break
+		}
+	}
+	if valueStart < 0 {
+		return prop, fmt.Errorf("invalid content line: %q", line)
+	}
+
+	parts := strings.Split(line[:valueStart], ";")
+	prop.name = strings.ToUpper(parts[0])
+	prop.value = line[valueStart+1:]
+	for _, param := range parts[1:] {
+		if key, value, found := strings.Cut(param, "="); found {
+			prop.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+	return prop, nil
+}
+
+// parseICalDateTime reads a DATE or DATE-TIME value in its TZID, UTC or defaultLoc.
+// The warning is set when the TZID could not be loaded.
+func parseICalDateTime(prop icalProperty, defaultLoc *time.Location) (time.Time, bool, string, error) {
+	value := strings.TrimSpace(prop.value)
+	loc := defaultLoc
+	warning := ""
+
+	if tzid := prop.params["TZID"]; tzid != "" {
+		if tzLoc, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+			loc = tzLoc
+		} else {
+			warning = fmt.Sprintf("unknown timezone %s, the account timezone is used", tzid)
+		}
+	}
+
+	if strings.EqualFold(prop.params["VALUE"], "DATE") || len(value) == len("20060102") {
+		date, err := time.ParseInLocation("20060102", value, loc)
+		if err != nil {
+			return time.Time{}, false, "", fmt.Errorf("invalid date %s", value)
+		}
+		return date, true, warning, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		date, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false, "", fmt.Errorf("invalid date-time %s", value)
+		}
+		return date, false, "", nil
+	}
+
+	date, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return time.Time{}, false, "", fmt.Errorf("invalid date-time %s", value)
+	}
+	return date, false, warning, nil
+}
+
+// parseICalDuration reads a DURATION value such as "-PT15M" or "P1DT2H"
+func parseICalDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(strings.ToUpper(value))
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(value, "-"):
+		sign = -1
+		value = value[1:]
+	case strings.HasPrefix(value, "+"):
+		value = value[1:]
+	}
+	if !strings.HasPrefix(value, "P") || len(value) < 3 {
+		return 0, fmt.Errorf("invalid duration %s", value)
+	}
+
+	var total time.Duration
+	inTime := false
+	number := ""
+	for _, char := range value[1:] {
+		switch {
+		case char == 'T':
+			inTime = true
+			continue
+		case char >= '0' && char <= '9':
+			number += string(char)
+			continue
+		}
+
+		amount, err := strconv.Atoi(number)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %s", value)
+		}
+		number = ""
+
+		switch {
+		case char == 'W' && !inTime:
+			total += time.Duration(amount) * 7 * 24 * time.Hour
+		case char == 'D' && !inTime:
+			total += time.Duration(amount) * 24 * time.Hour
+		case char == 'H' && inTime:
+			total += time.Duration(amount) * time.Hour
+		case char == 'M' && inTime:
+			total += time.Duration(amount) * time.Minute
+		case char == 'S' && inTime:
+			total += time.Duration(amount) * time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration %s", value)
+		}
+	}
+	if number != "" {
+		return 0, fmt.Errorf("invalid duration %s", value)
+	}
+	return sign * total, nil
+}
+
+// unescapeICalText reverses icalEscape
+func unescapeICalText(text string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(text)
+}
+
+// simpleRecurrence maps the rules a fixed recurrence type expresses exactly
+// ("FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR" is WORKDAYS) to that type
+func simpleRecurrence(rule string, start time.Time) (int, bool) {
+	option, err := rrule.StrToROption(rule)
+	if err != nil || option.Interval > 1 || option.Count > 0 || !option.Until.IsZero() {
+		return 0, false
+	}
+
+	parts := strings.Split(strings.ToUpper(rule), ";")
+	var byDay string
+	for _, part := range parts {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "FREQ", "INTERVAL", "WKST":
+		case "BYDAY":
+			byDay = value
+		default:
+			return 0, false
+		}
+	}
+
+	switch option.Freq {
+	case rrule.HOURLY:
+		return RecurrenceHourly, byDay == ""
+	case rrule.DAILY:
+		return RecurrenceDaily, byDay == ""
+	case rrule.MONTHLY:
+		// RFC 5545 skips the months too short for the start day, the calendar policies may not
+		return RecurrenceMonthly, byDay == "" && start.Day() <= 28
+	case rrule.YEARLY:
+		return RecurrenceYearly, byDay == "" && !(start.Month() == time.February && start.Day() == 29)
+	case rrule.WEEKLY:
+		switch byDay {
+		case "", icalWeekdays[start.Weekday()]:
+			return RecurrenceWeekly, true
+		case "MO,TU,WE,TH,FR":
+			return RecurrenceWorkdays, true
+		case "SA,SU", "SU,SA":
+			return RecurrenceWeekend, true
+		}
+	}
+	return 0, false
+}
+
+// ICalEntryToReminder maps an entry onto a reminder of the account: SUMMARY becomes the
+// message, the earliest VALARM the reminder time (the start itself without alarm, 09:00
+// for all-day entries) and RRULE the recurrence, as a fixed type when one matches.
+// Recurring entries that started in the past resume at their next occurrence. The
+// returned error is an *ICalImportIssue when the entry is skipped or unsupported.
+func ICalEntryToReminder(entry *ICalEntry, ianaLocation string) (*models.Reminder, error) {
+	switch {
+	case entry.RecurrenceID:
+		return nil, skipEntry("modified occurrence of a recurring entry")
+	case entry.Status == "CANCELLED":
+		return nil, skipEntry("cancelled")
+	case entry.Component == "VTODO" && entry.Status == "COMPLETED":
+		return nil, skipEntry("completed task")
+	case entry.Start.IsZero():
+		return nil, unsupportedEntry("no DTSTART")
+	case strings.TrimSpace(entry.Summary) == "":
+		return nil, unsupportedEntry("no SUMMARY")
+	}
+
+	loc, err := time.LoadLocation(ianaLocation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load timezone %s: %w", ianaLocation, err)
+	}
+
+	remindAt := entry.Start
+	if entry.AllDay {
+		remindAt = time.Date(remindAt.Year(), remindAt.Month(), remindAt.Day(), icalAllDayHour, 0, 0, 0, remindAt.Location())
+	}
+	if len(entry.Alarms) > 0 {
+		remindAt = entry.Alarms[0]
+		for _, alarm := range entry.Alarms[1:] {
+			if alarm.Before(remindAt) {
+				remindAt = alarm
+			}
+		}
+		if len(entry.Alarms) > 1 {
+			entry.warn("several VALARMs, the earliest one is used")
+		}
+	}
+
+	// The series starts at the entry date even when it resumes later on
+	start := remindAt.UTC()
+	reminder := &models.Reminder{
+		RemindAtUTC: start,
+		Message:     strings.TrimSpace(entry.Summary),
+		Recurrence:  RecurrenceOnce,
+	}
+
+	if entry.RRule != "" {
+		if err := checkAlarmShift(entry.RRule, entry.Start.In(loc), remindAt.In(loc)); err != nil {
+			return nil, err
+		}
+		if recurrenceType, ok := simpleRecurrence(entry.RRule, remindAt.In(loc)); ok {
+			reminder.Recurrence = int16(recurrenceType)
+		} else if err := ApplyRRule(reminder, entry.RRule, ianaLocation); err != nil {
+			return nil, unsupportedEntry(err.Error())
+		}
+	}
+
+	reminder.RecurrenceStartUTC = &start
+
+	if !reminder.RemindAtUTC.After(time.Now()) {
+		if reminder.Recurrence == RecurrenceOnce {
+			return nil, skipEntry("in the past")
+		}
+		next, err := GetNextOccurrenceInSeries(reminder.RemindAtUTC, int(reminder.Recurrence), ReminderRecurrenceSeries(reminder), ianaLocation)
+		if errors.Is(err, ErrRecurrenceEnded) {
+			return nil, skipEntry("the recurrence has ended")
+		}
+		if err != nil {
+			return nil, unsupportedEntry(err.Error())
+		}
+		reminder.RemindAtUTC = next.UTC()
+	}
+
+	return reminder, nil
+}
+
+// checkAlarmShift refuses alarms that would break a rule once the series starts at the
+// alarm instead of the entry: a weekly BYDAY=MO rule cannot fire on the Sunday before
+func checkAlarmShift(rule string, start, remindAt time.Time) error {
+	if start.Equal(remindAt) {
+		return nil
+	}
+
+	upperRule := strings.ToUpper(rule)
+	if strings.Contains(upperRule, "BYHOUR") || strings.Contains(upperRule, "BYMINUTE") {
+		return unsupportedEntry("VALARM before a rule with BYHOUR or BYMINUTE")
+	}
+
+	sameDay := start.Year() == remindAt.Year() && start.YearDay() == remindAt.YearDay()
+	if !sameDay && strings.Contains(upperRule, "BY") {
+		return unsupportedEntry("VALARM on another day than a rule with BYxxx parts")
+	}
+	return nil
+}
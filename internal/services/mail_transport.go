@@ -0,0 +1,393 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/config"
+	"github.com/ericp/chronos-bot-reminder/internal/tracing"
+	"github.com/google/uuid"
+	"github.com/resend/resend-go/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Mail transports selectable with MAIL_TRANSPORT
+const (
+	MailTransportResend = "resend"
+	MailTransportSMTP   = "smtp"
+	MailTransportFile   = "file"
+	MailTransportLog    = "log"
+)
+
+// SMTP connection security modes selectable with SMTP_TLS
+const (
+	SMTPSecurityStartTLS = "starttls" // plain connection upgraded with STARTTLS, usually port 587
+	SMTPSecurityTLS      = "tls"      // implicit TLS, usually port 465
+	SMTPSecurityNone     = "none"     // no encryption, only for a local relay
+)
+
+const smtpTimeout = 30 * time.Second
+
+// MailTransport delivers a composed email. It returns the ID of the sent message,
+// as assigned by the provider or generated for the Message-ID header. The context
+// carries the trace the delivery belongs to.
+type MailTransport interface {
+	Send(ctx context.Context, from string, req *EmailRequest) (string, error)
+	Name() string
+}
+
+// ErrMailNotConfigured is returned when no mail transport is configured outside DEV
+var ErrMailNotConfigured = errors.New("no mail transport configured, set RESEND_API_KEY, SMTP_HOST or MAIL_TRANSPORT")
+
+// NewMailTransportFromConfig returns the transport selected by MAIL_TRANSPORT. When it
+// is not set, Resend is used if an API key is configured, then SMTP if a host is set.
+// Otherwise emails are only logged in DEV so that development setups need no provider,
+// and ErrMailNotConfigured is returned elsewhere. The log transport prints the whole
+// email, verification links included, so it is refused outside DEV.
+func NewMailTransportFromConfig(cfg *config.Config) (MailTransport, error) {
+	transport := strings.ToLower(strings.TrimSpace(cfg.MailTransport))
+	if transport == "" {
+		switch {
+		case cfg.ResendAPIKey != "":
+			transport = MailTransportResend
+		case cfg.SMTPHost != "":
+			transport = MailTransportSMTP
+		case cfg.Environment == "DEV":
+			transport = MailTransportLog
+		default:
+			return nil, ErrMailNotConfigured
+		}
+	}
+
+	switch transport {
+	case MailTransportResend:
+		if cfg.ResendAPIKey == "" {
+			return nil, fmt.Errorf("MAIL_TRANSPORT=resend requires RESEND_API_KEY")
+		}
+		return NewResendTransport(cfg.ResendAPIKey), nil
+	case MailTransportSMTP:
+		return NewSMTPTransport(SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			Security: cfg.SMTPSecurity,
+		})
+	case MailTransportFile:
+		return NewFileTransport(cfg.MailFileDir), nil
+	case MailTransportLog:
+		if cfg.Environment != "DEV" {
+			return nil, fmt.Errorf("MAIL_TRANSPORT=log is only available in DEV, it writes emails to the logs")
+		}
+		return NewFileTransport(""), nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_TRANSPORT %q, expected resend, smtp, file or log", cfg.MailTransport)
+	}
+}
+
+// ResendTransport sends emails through the Resend API
+type ResendTransport struct {
+	client *resend.Client
+}
+
+// NewResendTransport creates a Resend transport, its API calls are traced
+func NewResendTransport(apiKey string) *ResendTransport {
+	httpClient := &http.Client{Transport: tracing.Transport(nil), Timeout: time.Minute}
+	return &ResendTransport{client: resend.NewCustomClient(httpClient, strings.Trim(strings.TrimSpace(apiKey), "'"))}
+}
+
+// Name returns the transport name
+func (t *ResendTransport) Name() string {
+	return MailTransportResend
+}
+
+// Send sends the email with the Resend API, errors keep the Resend error types
+func (t *ResendTransport) Send(ctx context.Context, from string, req *EmailRequest) (string, error) {
+	params := &resend.SendEmailRequest{
+		From:    from,
+		To:      []string{req.To},
+		Subject: req.Subject,
+		Html:    req.HtmlBody,
+		Text:    req.TextBody,
+	}
+
+	var sent *resend.SendEmailResponse
+	var err error
+	if req.IdempotencyKey != "" {
+		sent, err = t.client.Emails.SendWithOptions(ctx, params, &resend.SendEmailOptions{IdempotencyKey: req.IdempotencyKey})
+	} else {
+		sent, err = t.client.Emails.SendWithContext(ctx, params)
+	}
+	if err != nil {
+		return "", err
+	}
+	return sent.Id, nil
+}
+
+// SMTPConfig holds the settings of an SMTP relay
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // authentication is skipped when empty
+	Password string
+	Security string // starttls (default), tls or none
+}
+
+// SMTPTransport sends emails through an SMTP relay
+type SMTPTransport struct {
+	config SMTPConfig
+}
+
+// NewSMTPTransport creates an SMTP transport. STARTTLS is mandatory unless the
+// security mode is explicitly set to none: credentials never travel in clear text.
+func NewSMTPTransport(cfg SMTPConfig) (*SMTPTransport, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("SMTP transport requires SMTP_HOST")
+	}
+	cfg.Security = strings.ToLower(strings.TrimSpace(cfg.Security))
+	if cfg.Security == "" {
+		cfg.Security = SMTPSecurityStartTLS
+	}
+	switch cfg.Security {
+	case SMTPSecurityStartTLS, SMTPSecurityTLS, SMTPSecurityNone:
+	default:
+		return nil, fmt.Errorf("unknown SMTP_TLS %q, expected starttls, tls or none", cfg.Security)
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+		if cfg.Security == SMTPSecurityTLS {
+			cfg.Port = 465
+		}
+	}
+	return &SMTPTransport{config: cfg}, nil
+}
+
+// Name returns the transport name
+func (t *SMTPTransport) Name() string {
+	return MailTransportSMTP
+}
+
+// Send delivers the email to the relay. Failures keep the SMTP reply as a
+// *textproto.Error so callers can tell temporary (4xx) from permanent (5xx) ones.
+func (t *SMTPTransport) Send(ctx context.Context, from string, req *EmailRequest) (messageID string, err error) {
+	_, span := tracing.Start(ctx, "smtp.send", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("server.address", t.config.Host),
+		attribute.Int("server.port", t.config.Port),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	messageID, message, err := buildMIMEMessage(from, req, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	client, err := t.dial()
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	if t.config.Username != "" {
+		auth := smtp.PlainAuth("", t.config.Username, t.config.Password, t.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return "", fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(envelopeAddress(from)); err != nil {
+		return "", err
+	}
+	if err := client.Rcpt(envelopeAddress(req.To)); err != nil {
+		return "", err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return "", err
+	}
+	if _, err := writer.Write(message); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	// The message is accepted once DATA is acknowledged, a failed QUIT changes nothing
+	client.Quit()
+	return messageID, nil
+}
+
+// dial opens the connection and secures it according to the configured mode
+func (t *SMTPTransport) dial() (*smtp.Client, error) {
+	address := net.JoinHostPort(t.config.Host, strconv.Itoa(t.config.Port))
+	tlsConfig := &tls.Config{ServerName: t.config.Host}
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+
+	var conn net.Conn
+	var err error
+	if t.config.Security == SMTPSecurityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+
+	client, err := smtp.NewClient(conn, t.config.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+
+	if t.config.Security == SMTPSecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("SMTP server %s does not support STARTTLS", t.config.Host)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("SMTP STARTTLS failed: %w", err)
+		}
+	}
+
+	return client, nil
+}
+
+// IsTemporarySMTPError reports whether the relay rejected the message with a 4xx reply
+func IsTemporarySMTPError(err error) bool {
+	var protoErr *textproto.Error
+	return errors.As(err, &protoErr) && protoErr.Code >= 400 && protoErr.Code < 500
+}
+
+// FileTransport writes emails as .eml files for development. Without a directory
+// the emails are only logged, which keeps verification links usable locally, so
+// NewMailTransportFromConfig only selects the log mode in DEV.
+type FileTransport struct {
+	dir string
+}
+
+// NewFileTransport creates a file transport writing into dir, or logging when dir is empty
+func NewFileTransport(dir string) *FileTransport {
+	return &FileTransport{dir: dir}
+}
+
+// Name returns the transport name
+func (t *FileTransport) Name() string {
+	if t.dir == "" {
+		return MailTransportLog
+	}
+	return MailTransportFile
+}
+
+// Send writes the email to the directory or the log
+func (t *FileTransport) Send(ctx context.Context, from string, req *EmailRequest) (string, error) {
+	messageID, message, err := buildMIMEMessage(from, req, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	if t.dir == "" {
+		body := req.TextBody
+		if body == "" {
+			body = req.HtmlBody
+		}
+		log.Printf("[MAILER] - 📧 Email to %s: %s\n%s", req.To, req.Subject, strings.TrimSpace(body))
+		return messageID, nil
+	}
+
+	if err := os.MkdirAll(t.dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), strings.Trim(messageID, "<>"))
+	if err := os.WriteFile(filepath.Join(t.dir, name), message, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write email: %w", err)
+	}
+	return messageID, nil
+}
+
+// buildMIMEMessage renders the email as a multipart/alternative RFC 5322 message
+// and returns it with its Message-ID, derived from the idempotency key when there is one
+func buildMIMEMessage(from string, req *EmailRequest, date time.Time) (string, []byte, error) {
+	for _, value := range []string{from, req.To, req.Subject} {
+		if strings.ContainsAny(value, "\r\n") {
+			return "", nil, fmt.Errorf("email headers cannot contain line breaks")
+		}
+	}
+
+	domain := "localhost"
+	if at := strings.LastIndex(envelopeAddress(from), "@"); at >= 0 {
+		domain = envelopeAddress(from)[at+1:]
+	}
+	localPart := uuid.New().String()
+	if req.IdempotencyKey != "" {
+		// The key of a reminder fire is "<uuid>:<unix time>", a colon is not allowed in a Message-ID
+		localPart = strings.ReplaceAll(req.IdempotencyKey, ":", ".")
+	}
+	messageID := fmt.Sprintf("<%s@%s>", localPart, domain)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=UTF-8", req.TextBody},
+		{"text/html; charset=UTF-8", req.HtmlBody},
+	} {
+		if part.content == "" {
+			continue
+		}
+		partWriter, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		encoder := quotedprintable.NewWriter(partWriter)
+		encoder.Write([]byte(part.content))
+		encoder.Close()
+	}
+	writer.Close()
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", req.To)
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", req.Subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "Message-ID: %s\r\n", messageID)
+	fmt.Fprintf(&message, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&message, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", writer.Boundary())
+	message.Write(body.Bytes())
+
+	return messageID, message.Bytes(), nil
+}
+
+// envelopeAddress extracts the bare address of "Name <address>" for the SMTP envelope
+func envelopeAddress(address string) string {
+	if start := strings.LastIndex(address, "<"); start >= 0 {
+		if end := strings.LastIndex(address, ">"); end > start {
+			return address[start+1 : end]
+		}
+	}
+	return strings.TrimSpace(address)
+}
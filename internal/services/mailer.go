@@ -1,15 +1,18 @@
 package services
 
 import (
+	"context"
 	"fmt"
+	"html"
 	"log"
 
-	"github.com/resend/resend-go/v3"
+	"github.com/ericp/chronos-bot-reminder/internal/config"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 )
 
-// MailerService handles email sending operations using Resend
+// MailerService composes the emails of the application and sends them with a MailTransport
 type MailerService struct {
-	client    *resend.Client
+	transport MailTransport
 	fromEmail string
 }
 
@@ -19,18 +22,44 @@ type EmailRequest struct {
 	Subject  string
 	HtmlBody string
 	TextBody string
+
+	// IdempotencyKey is optional. Resend drops a second send of the same key within
+	// a day, and the other transports derive the Message-ID from it.
+	IdempotencyKey string
 }
 
 // NewMailerService creates a new mailer service instance
-func NewMailerService(apiKey string, fromEmail string) *MailerService {
+func NewMailerService(transport MailTransport, fromEmail string) *MailerService {
 	return &MailerService{
-		client:    resend.NewClient(apiKey),
+		transport: transport,
 		fromEmail: fromEmail,
 	}
 }
 
-// SendEmail sends an email using the Resend API
+// NewMailerServiceFromConfig creates the mailer with the transport selected in the
+// configuration. An invalid configuration falls back to logging the emails in DEV,
+// elsewhere email is disabled and every send fails with ErrMailNotConfigured.
+func NewMailerServiceFromConfig(cfg *config.Config) *MailerService {
+	transport, err := NewMailTransportFromConfig(cfg)
+	if err != nil {
+		if cfg.Environment != "DEV" {
+			log.Printf("[MAILER] - ⚠️  %v, emails are disabled", err)
+			return NewMailerService(nil, cfg.MailFrom)
+		}
+		log.Printf("[MAILER] - ⚠️  %v, emails will only be logged", err)
+		transport = NewFileTransport("")
+	}
+	log.Printf("[MAILER] - ✅ Sending emails with the %s transport", transport.Name())
+	return NewMailerService(transport, cfg.MailFrom)
+}
+
+// SendEmail sends an email with the configured transport
 func (m *MailerService) SendEmail(req *EmailRequest) (string, error) {
+	return m.SendEmailContext(context.Background(), req)
+}
+
+// SendEmailContext sends an email with the configured transport, within the trace of ctx
+func (m *MailerService) SendEmailContext(ctx context.Context, req *EmailRequest) (string, error) {
 	if req == nil {
 		return "", fmt.Errorf("email request is nil")
 	}
@@ -43,35 +72,22 @@ func (m *MailerService) SendEmail(req *EmailRequest) (string, error) {
 		return "", fmt.Errorf("email subject is required")
 	}
 
-	// Build the email request
-	params := &resend.SendEmailRequest{
-		From:    m.fromEmail,
-		To:      []string{req.To},
-		Subject: req.Subject,
-	}
-
-	// Set the body - prefer HTML, fall back to text
-	if req.HtmlBody != "" {
-		params.Html = req.HtmlBody
-	} else if req.TextBody != "" {
-		params.Text = req.TextBody
-	} else {
+	if req.HtmlBody == "" && req.TextBody == "" {
 		return "", fmt.Errorf("either HTML or text body is required")
 	}
 
-	// If both are provided, set text as well
-	if req.TextBody != "" {
-		params.Text = req.TextBody
+	if m.transport == nil {
+		return "", ErrMailNotConfigured
 	}
 
 	// Send the email
-	sent, err := m.client.Emails.Send(params)
+	messageID, err := m.transport.Send(ctx, m.fromEmail, req)
 	if err != nil {
 		log.Printf("[MAILER] - ❌ Failed to send email to %s: %v", req.To, err)
 		return "", fmt.Errorf("failed to send email: %w", err)
 	}
 
-	return sent.Id, nil
+	return messageID, nil
 }
 
 // SendEmailWithTemplate sends an email with custom template support
@@ -85,51 +101,75 @@ func (m *MailerService) SendEmailWithTemplate(to string, subject string, htmlBod
 }
 
 // SendWelcomeEmail sends a welcome email to a new user
-func (m *MailerService) SendWelcomeEmail(email string, username string) (string, error) {
-	subject := "Welcome to Chronos Reminder!"
+func (m *MailerService) SendWelcomeEmail(email string, username string, locale string) (string, error) {
+	subject := i18n.T(locale, "email.welcome.subject")
 	htmlBody := fmt.Sprintf(`
 <!DOCTYPE html>
-<html>
+<html lang="%s">
 <head>
 	<meta charset="UTF-8">
-	<title>Welcome to Chronos Reminder</title>
+	<title>%s</title>
 </head>
 <body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
 	<div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-		<h1 style="color: #4CAF50;">Welcome to Chronos Reminder! ⏰</h1>
-		<p>Hi <strong>%s</strong>,</p>
-		<p>Thank you for signing up! We're thrilled to have you on board.</p>
-		<p>With Chronos Reminder, you can:</p>
+		<h1 style="color: #4CAF50;">%s ⏰</h1>
+		<p>%s</p>
+		<p>%s</p>
+		<p>%s</p>
 		<ul>
-			<li>Create and manage reminders effortlessly</li>
-			<li>Get timely notifications via Discord</li>
-			<li>Organize your schedule across multiple timezones</li>
+			<li>%s</li>
+			<li>%s</li>
+			<li>%s</li>
 		</ul>
-		<p>Get started now and never miss an important moment!</p>
+		<p>%s</p>
 		<br>
-		<p style="color: #666; font-size: 12px;">Best regards,<br>The Chronos Reminder Team</p>
+		<p style="color: #666; font-size: 12px;">%s<br>%s</p>
 	</div>
 </body>
 </html>
-	`, username)
+	`,
+		i18n.Resolve(locale),
+		subject,
+		subject,
+		i18n.T(locale, "email.welcome.greeting", "<strong>"+html.EscapeString(username)+"</strong>"),
+		i18n.T(locale, "email.welcome.thanks"),
+		i18n.T(locale, "email.welcome.features"),
+		i18n.T(locale, "email.welcome.feature_manage"),
+		i18n.T(locale, "email.welcome.feature_notify"),
+		i18n.T(locale, "email.welcome.feature_timezones"),
+		i18n.T(locale, "email.welcome.get_started"),
+		i18n.T(locale, "email.signature.regards"),
+		i18n.T(locale, "email.signature.team"),
+	)
 
 	textBody := fmt.Sprintf(`
-Welcome to Chronos Reminder!
+%s
 
-Hi %s,
+%s
 
-Thank you for signing up! We're thrilled to have you on board.
+%s
 
-With Chronos Reminder, you can:
-- Create and manage reminders effortlessly
-- Get timely notifications via Discord
-- Organize your schedule across multiple timezones
+%s
+- %s
+- %s
+- %s
 
-Get started now and never miss an important moment!
+%s
 
-Best regards,
-The Chronos Reminder Team
-	`, username)
+%s
+%s
+	`,
+		subject,
+		i18n.T(locale, "email.welcome.greeting", username),
+		i18n.T(locale, "email.welcome.thanks"),
+		i18n.T(locale, "email.welcome.features"),
+		i18n.T(locale, "email.welcome.feature_manage"),
+		i18n.T(locale, "email.welcome.feature_notify"),
+		i18n.T(locale, "email.welcome.feature_timezones"),
+		i18n.T(locale, "email.welcome.get_started"),
+		i18n.T(locale, "email.signature.regards"),
+		i18n.T(locale, "email.signature.team"),
+	)
 
 	return m.SendEmail(&EmailRequest{
 		To:       email,
@@ -140,40 +180,51 @@ The Chronos Reminder Team
 }
 
 // SendPasswordResetEmail sends a password reset email
-func (m *MailerService) SendPasswordResetEmail(email string, resetLink string) (string, error) {
-	subject := "Reset your Chronos Reminder password"
+func (m *MailerService) SendPasswordResetEmail(email string, resetLink string, locale string) (string, error) {
+	subject := i18n.T(locale, "email.password_reset.subject")
 	htmlBody := fmt.Sprintf(`
 <!DOCTYPE html>
-<html>
+<html lang="%s">
 <head>
 	<meta charset="UTF-8">
-	<title>Reset Your Password</title>
+	<title>%s</title>
 </head>
 <body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
 	<div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-		<h1 style="color: #FF9800;">Password Reset Request</h1>
-		<p>We received a request to reset your password. Click the button below to proceed:</p>
+		<h1 style="color: #FF9800;">%s</h1>
+		<p>%s</p>
 		<p style="margin: 30px 0;">
 			<a href="%s" style="background-color: #4CAF50; color: white; padding: 10px 20px; text-decoration: none; border-radius: 5px; display: inline-block;">
-				Reset Password
+				%s
 			</a>
 		</p>
-		<p style="color: #666; font-size: 12px;">If you didn't request this, please ignore this email. This link will expire in 1 hour.</p>
+		<p style="color: #666; font-size: 12px;">%s</p>
 	</div>
 </body>
 </html>
-	`, resetLink)
+	`,
+		i18n.Resolve(locale),
+		i18n.T(locale, "email.password_reset.title"),
+		i18n.T(locale, "email.password_reset.heading"),
+		i18n.T(locale, "email.password_reset.intro_button"),
+		resetLink,
+		i18n.T(locale, "email.password_reset.button"),
+		i18n.T(locale, "email.password_reset.ignore_1h"),
+	)
 
 	textBody := fmt.Sprintf(`
-Password Reset Request
-
-We received a request to reset your password. Use this link to proceed:
 %s
 
-This link will expire in 1 hour.
+%s
+%s
 
-If you didn't request this, please ignore this email.
-	`, resetLink)
+%s
+	`,
+		i18n.T(locale, "email.password_reset.heading"),
+		i18n.T(locale, "email.password_reset.intro_link"),
+		resetLink,
+		i18n.T(locale, "email.password_reset.ignore_1h"),
+	)
 
 	return m.SendEmail(&EmailRequest{
 		To:       email,
@@ -183,43 +234,57 @@ If you didn't request this, please ignore this email.
 	})
 }
 
-// SendReminderNotificationEmail sends a reminder notification email
-func (m *MailerService) SendReminderNotificationEmail(email string, reminderTitle string, reminderTime string) (string, error) {
-	subject := fmt.Sprintf("Reminder: %s", reminderTitle)
+// SendReminderNotificationEmail sends a reminder notification email. The idempotency key
+// identifies the reminder fire so that sending it again does not deliver a second email.
+func (m *MailerService) SendReminderNotificationEmail(ctx context.Context, email string, reminderTitle string, reminderTime string, locale string, idempotencyKey string) (string, error) {
+	subject := i18n.T(locale, "email.reminder.subject", reminderTitle)
 	htmlBody := fmt.Sprintf(`
 <!DOCTYPE html>
-<html>
+<html lang="%s">
 <head>
 	<meta charset="UTF-8">
-	<title>Reminder Notification</title>
+	<title>%s</title>
 </head>
 <body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
 	<div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-		<h2 style="color: #2196F3;">⏰ Reminder Notification</h2>
+		<h2 style="color: #2196F3;">⏰ %s</h2>
 		<p style="font-size: 18px; margin: 20px 0;">
 			<strong>%s</strong>
 		</p>
-		<p style="color: #666;">Scheduled for: <strong>%s</strong></p>
-		<p style="margin-top: 30px; color: #999; font-size: 12px;">This is an automated reminder from Chronos Reminder</p>
+		<p style="color: #666;">%s</p>
+		<p style="margin-top: 30px; color: #999; font-size: 12px;">%s</p>
 	</div>
 </body>
 </html>
-	`, reminderTitle, reminderTime)
+	`,
+		i18n.Resolve(locale),
+		i18n.T(locale, "email.reminder.title"),
+		i18n.T(locale, "email.reminder.title"),
+		html.EscapeString(reminderTitle),
+		i18n.T(locale, "email.reminder.scheduled_for", "<strong>"+reminderTime+"</strong>"),
+		i18n.T(locale, "email.automated"),
+	)
 
 	textBody := fmt.Sprintf(`
-Reminder Notification
-
 %s
 
-Scheduled for: %s
+%s
 
-This is an automated reminder from Chronos Reminder
-	`, reminderTitle, reminderTime)
+%s
 
-	return m.SendEmail(&EmailRequest{
-		To:       email,
-		Subject:  subject,
-		HtmlBody: htmlBody,
-		TextBody: textBody,
+%s
+	`,
+		i18n.T(locale, "email.reminder.title"),
+		reminderTitle,
+		i18n.T(locale, "email.reminder.scheduled_for", reminderTime),
+		i18n.T(locale, "email.automated"),
+	)
+
+	return m.SendEmailContext(ctx, &EmailRequest{
+		To:             email,
+		Subject:        subject,
+		HtmlBody:       htmlBody,
+		TextBody:       textBody,
+		IdempotencyKey: idempotencyKey,
 	})
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/ericp/chronos-bot-reminder/internal/config"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
 	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 )
 
 // PasswordResetService handles password reset operations
@@ -79,7 +80,7 @@ func (p *PasswordResetService) RequestPasswordReset(email string) error {
 	resetLink := fmt.Sprintf("%s/reset-password?token=%s&email=%s", config.URLWebApp, token, email)
 
 	// Send reset email
-	_, err = p.SendPasswordResetEmail(email, resetLink)
+	_, err = p.SendPasswordResetEmail(email, resetLink, i18n.Resolve(account.Locale))
 	if err != nil {
 		log.Printf("[PASSWORD_RESET] - ⚠️ Failed to send password reset email: %v", err)
 		// Don't fail the request even if email fails - token is still created
@@ -89,47 +90,64 @@ func (p *PasswordResetService) RequestPasswordReset(email string) error {
 }
 
 // SendPasswordResetEmail sends a password reset email to the user
-func (p *PasswordResetService) SendPasswordResetEmail(email string, resetLink string) (string, error) {
-	subject := "Reset your Chronos Reminder password"
+func (p *PasswordResetService) SendPasswordResetEmail(email string, resetLink string, locale string) (string, error) {
+	subject := i18n.T(locale, "email.password_reset.subject")
 	htmlBody := fmt.Sprintf(`
 <!DOCTYPE html>
-<html>
+<html lang="%s">
 <head>
 	<meta charset="UTF-8">
-	<title>Reset Your Password</title>
+	<title>%s</title>
 </head>
 <body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
 	<div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-		<h1 style="color: #4CAF50;">Reset Your Password 🔐</h1>
-		<p>We received a request to reset your Chronos Reminder account password.</p>
-		<p>Click the button below to reset your password:</p>
+		<h1 style="color: #4CAF50;">%s 🔐</h1>
+		<p>%s</p>
+		<p>%s</p>
 		
 		<p style="margin: 30px 0;">
 			<a href="%s" style="background-color: #4CAF50; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; display: inline-block; font-size: 16px; font-weight: bold;">
-				Reset Password
+				%s
 			</a>
 		</p>
 		
-		<p style="color: #666; margin: 20px 0;">Or copy this link: <br><span style="word-break: break-all;">%s</span></p>
+		<p style="color: #666; margin: 20px 0;">%s <br><span style="word-break: break-all;">%s</span></p>
 		
 		<p style="color: #999; font-size: 12px; margin-top: 30px;">
-			This link will expire in 24 hours. If you didn't request a password reset, please ignore this email or contact our support team.
+			%s
 		</p>
 	</div>
 </body>
 </html>
-	`, resetLink, resetLink)
+	`,
+		i18n.Resolve(locale),
+		i18n.T(locale, "email.password_reset.title"),
+		i18n.T(locale, "email.password_reset.title"),
+		i18n.T(locale, "email.password_reset.request_received"),
+		i18n.T(locale, "email.password_reset.click_button"),
+		resetLink,
+		i18n.T(locale, "email.password_reset.button"),
+		i18n.T(locale, "email.password_reset.copy_link"),
+		resetLink,
+		i18n.T(locale, "email.password_reset.ignore_24h"),
+	)
 
 	textBody := fmt.Sprintf(`
-Reset Your Password
+%s
 
-We received a request to reset your Chronos Reminder account password.
+%s
 
-Please visit this link to reset your password:
+%s
 %s
 
-This link will expire in 24 hours. If you didn't request a password reset, please ignore this email.
-	`, resetLink)
+%s
+	`,
+		i18n.T(locale, "email.password_reset.title"),
+		i18n.T(locale, "email.password_reset.request_received"),
+		i18n.T(locale, "email.password_reset.visit_link"),
+		resetLink,
+		i18n.T(locale, "email.password_reset.ignore_24h"),
+	)
 
 	return p.mailerService.SendEmail(&EmailRequest{
 		To:       email,
@@ -2,7 +2,14 @@ package services
 
 import (
 	"fmt"
+	"log"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/config"
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 )
 
 // Recurrence type constants
@@ -16,22 +23,12 @@ const (
 	RecurrenceHourly   = 5 // 5/133
 	RecurrenceWorkdays = 6 // 6/134
 	RecurrenceWeekend  = 7 // 7/135
+	RecurrenceRRule    = 8 // 8/136, custom RFC 5545 rule stored next to the state
 )
 
 // Pause bit flag (8th bit)
 const PauseBit = 1 << 7 // 128
 
-// Map of all the recurrences and their implementation (WORKDAYS, WEEKEND, etc)
-var Recurrences = map[string]Recurrence{
-	"YEARLY":   YearlyRecurrence{},
-	"MONTHLY":  MonthlyRecurrence{},
-	"WEEKLY":   WeeklyRecurrence{},
-	"DAILY":    DailyRecurrence{},
-	"HOURLY":   HourlyRecurrence{},
-	"WORKDAYS": WorkdaysRecurrence{},
-	"WEEKEND":  WeekendRecurrence{},
-}
-
 // RecurrenceTypeMap maps string names to type constants
 var RecurrenceTypeMap = map[string]int{
 	"ONCE":     RecurrenceOnce,
@@ -55,6 +52,7 @@ func GetRecurrenceTypeName(recurrenceType int) string {
 		RecurrenceHourly:   "HOURLY",
 		RecurrenceWorkdays: "WORKDAYS",
 		RecurrenceWeekend:  "WEEKEND",
+		RecurrenceRRule:    "RRULE",
 	}
 	if name, exists := typeNames[recurrenceType]; exists {
 		return name
@@ -100,6 +98,7 @@ func GetRecurrenceTypeLabel(recurrenceType int) string {
 		RecurrenceHourly:   "Hourly",
 		RecurrenceWorkdays: "Workdays",
 		RecurrenceWeekend:  "Weekend",
+		RecurrenceRRule:    "Custom rule",
 	}
 	if name, exists := typeNames[recurrenceType]; exists {
 		return name
@@ -107,220 +106,389 @@ func GetRecurrenceTypeLabel(recurrenceType int) string {
 	return "UNKNOWN"
 }
 
-// Recurrence interface for different recurrence types
+// LocalizedRecurrenceTypeLabel returns the label of a recurrence type in the given locale
+func LocalizedRecurrenceTypeLabel(locale string, recurrenceType int) string {
+	return i18n.T(locale, "recurrence."+strings.ToLower(GetRecurrenceTypeName(recurrenceType)))
+}
+
+// EndOfMonthPolicy tells a monthly recurrence what to do when its day of month
+// does not exist in the target month (the 31st in April, the 30th in February...)
+type EndOfMonthPolicy int
+
+const (
+	// EndOfMonthClamp fires on the last day of shorter months: Jan 31 -> Feb 28 -> Mar 31
+	EndOfMonthClamp EndOfMonthPolicy = iota
+	// EndOfMonthSkip only fires in months that have the day: Jan 31 -> Mar 31 -> May 31
+	EndOfMonthSkip
+	// EndOfMonthRollover lets the missing days spill into the next month: Jan 31 -> Mar 3
+	EndOfMonthRollover
+)
+
+// LeapDayPolicy tells a yearly recurrence started on February 29 where to fire on common years
+type LeapDayPolicy int
+
+const (
+	LeapDayFeb28 LeapDayPolicy = iota // February 28 on common years
+	LeapDayMar1                       // March 1 on common years
+	LeapDaySkip                       // leap years only
+)
+
+// ParseEndOfMonthPolicy reads an end of month policy name: "clamp", "skip" or "rollover"
+func ParseEndOfMonthPolicy(name string) (EndOfMonthPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "clamp":
+		return EndOfMonthClamp, nil
+	case "skip":
+		return EndOfMonthSkip, nil
+	case "rollover":
+		return EndOfMonthRollover, nil
+	}
+	return EndOfMonthClamp, fmt.Errorf("unknown end of month policy: %s", name)
+}
+
+// ParseLeapDayPolicy reads a leap day policy name: "feb28", "mar1" or "skip"
+func ParseLeapDayPolicy(name string) (LeapDayPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "feb28":
+		return LeapDayFeb28, nil
+	case "mar1":
+		return LeapDayMar1, nil
+	case "skip":
+		return LeapDaySkip, nil
+	}
+	return LeapDayFeb28, fmt.Errorf("unknown leap day policy: %s", name)
+}
+
+// RecurrenceOptions holds the calendar policies of monthly and yearly recurrences
+type RecurrenceOptions struct {
+	EndOfMonth EndOfMonthPolicy
+	LeapDay    LeapDayPolicy
+}
+
+var (
+	recurrenceOptions      *RecurrenceOptions
+	recurrenceOptionsMutex sync.RWMutex
+)
+
+// DefaultRecurrenceOptions returns the policies set with RECURRENCE_END_OF_MONTH_POLICY
+// and RECURRENCE_LEAP_DAY_POLICY. The configuration is read once and cached.
+func DefaultRecurrenceOptions() RecurrenceOptions {
+	recurrenceOptionsMutex.RLock()
+	cached := recurrenceOptions
+	recurrenceOptionsMutex.RUnlock()
+	if cached != nil {
+		return *cached
+	}
+
+	cfg := config.Load()
+	var options RecurrenceOptions
+	var err error
+	if options.EndOfMonth, err = ParseEndOfMonthPolicy(cfg.RecurrenceEndOfMonthPolicy); err != nil {
+		log.Printf("[RECURRENCE] - ⚠️ %v, falling back to clamp", err)
+	}
+	if options.LeapDay, err = ParseLeapDayPolicy(cfg.RecurrenceLeapDayPolicy); err != nil {
+		log.Printf("[RECURRENCE] - ⚠️ %v, falling back to feb28", err)
+	}
+
+	SetDefaultRecurrenceOptions(options)
+	return options
+}
+
+// SetDefaultRecurrenceOptions replaces the policies used by GetNextOccurrence
+func SetDefaultRecurrenceOptions(options RecurrenceOptions) {
+	recurrenceOptionsMutex.Lock()
+	defer recurrenceOptionsMutex.Unlock()
+	recurrenceOptions = &options
+}
+
+// Recurrence interface for different recurrence types.
+// Times are handled in the user's location: calendar steps (days, months, years)
+// keep the wall-clock time of from across DST transitions.
 type Recurrence interface {
-	NextOccurrence(from int64, interval int) int64
+	// NextOccurrence returns the occurrence interval steps after from
+	NextOccurrence(from time.Time, interval int, loc *time.Location) time.Time
+}
+
+// NewRecurrence returns the implementation of a recurrence type, nil for ONCE and RRULE.
+// anchor is the first occurrence of the series: monthly and yearly recurrences go back
+// to its day after a shorter month or a common year. A zero anchor uses from instead.
+func NewRecurrence(recurrenceType int, anchor time.Time, options RecurrenceOptions) Recurrence {
+	switch recurrenceType {
+	case RecurrenceYearly:
+		return YearlyRecurrence{Anchor: anchor, LeapDay: options.LeapDay}
+	case RecurrenceMonthly:
+		return MonthlyRecurrence{Anchor: anchor, EndOfMonth: options.EndOfMonth}
+	case RecurrenceWeekly:
+		return WeeklyRecurrence{}
+	case RecurrenceDaily:
+		return DailyRecurrence{}
+	case RecurrenceHourly:
+		return HourlyRecurrence{}
+	case RecurrenceWorkdays:
+		return WorkdaysRecurrence{}
+	case RecurrenceWeekend:
+		return WeekendRecurrence{}
+	}
+	return nil
 }
 
 // YearlyRecurrence struct
-type YearlyRecurrence struct{}
+type YearlyRecurrence struct {
+	Anchor  time.Time     // first occurrence of the series, zero to use from
+	LeapDay LeapDayPolicy // where a February 29 series fires on common years
+}
+
+// NextOccurrence returns the same day interval years later
+func (r YearlyRecurrence) NextOccurrence(from time.Time, interval int, loc *time.Location) time.Time {
+	from = from.In(loc)
+	year, month, day := from.Date()
+
+	leapDay := r.isLeapDaySeries(from, loc)
+	if leapDay {
+		// Feb 28 and Mar 1 of a common year stand for that year's missing Feb 29
+		month, day = time.February, 29
+	}
+
+	for step := 0; step < interval; {
+		year++
+		if leapDay && r.LeapDay == LeapDaySkip && !isLeapYear(year) {
+			continue
+		}
+		step++
+	}
+
+	if leapDay && !isLeapYear(year) {
+		if r.LeapDay == LeapDayMar1 {
+			month, day = time.March, 1
+		} else {
+			day = 28
+		}
+	}
 
-// NextOccurrence returns the next occurrence timestamp for yearly recurrence
-func (r YearlyRecurrence) NextOccurrence(from int64, interval int) int64 {
-	return from + int64(interval*31536000) // 365 days
+	return time.Date(year, month, day, from.Hour(), from.Minute(), from.Second(), from.Nanosecond(), loc)
+}
+
+// isLeapDaySeries reports whether from belongs to a series started on February 29
+func (r YearlyRecurrence) isLeapDaySeries(from time.Time, loc *time.Location) bool {
+	if from.Month() == time.February && from.Day() == 29 {
+		return true
+	}
+	if r.Anchor.IsZero() || isLeapYear(from.Year()) {
+		return false
+	}
+
+	anchor := r.Anchor.In(loc)
+	if anchor.Month() != time.February || anchor.Day() != 29 {
+		return false
+	}
+	return (from.Month() == time.February && from.Day() == 28) || (from.Month() == time.March && from.Day() == 1)
 }
 
 // MonthlyRecurrence struct
-type MonthlyRecurrence struct{}
+type MonthlyRecurrence struct {
+	Anchor     time.Time        // first occurrence of the series, zero to use from
+	EndOfMonth EndOfMonthPolicy // what to do in months too short for the anchor day
+}
+
+// NextOccurrence returns the anchor day interval months later, the end of month
+// policy deciding what happens in months that do not have that day
+func (r MonthlyRecurrence) NextOccurrence(from time.Time, interval int, loc *time.Location) time.Time {
+	from = from.In(loc)
+	year, month, day := r.nominalDate(from, loc)
+
+	for step := 0; step < interval; {
+		month++
+		if month > time.December {
+			month = time.January
+			year++
+		}
+		if r.EndOfMonth == EndOfMonthSkip && day > daysInMonth(year, month) {
+			continue
+		}
+		step++
+	}
 
-// NextOccurrence returns the next occurrence timestamp for monthly recurrence
-func (r MonthlyRecurrence) NextOccurrence(from int64, interval int) int64 {
-	return from + int64(interval*2592000) // 30 days
+	if r.EndOfMonth == EndOfMonthClamp {
+		day = min(day, daysInMonth(year, month))
+	}
+
+	// With EndOfMonthRollover, time.Date carries a missing day over into the next month
+	return time.Date(year, month, day, from.Hour(), from.Minute(), from.Second(), from.Nanosecond(), loc)
+}
+
+// nominalDate returns the date from stands for in the series: Feb 28 is the 31st of
+// February when the series started on a 31st and got clamped, Mar 3 too when it rolled
+// over. A date that does not match the anchor (the reminder moved) starts over from itself.
+func (r MonthlyRecurrence) nominalDate(from time.Time, loc *time.Location) (int, time.Month, int) {
+	year, month, day := from.Date()
+	if r.Anchor.IsZero() {
+		return year, month, day
+	}
+
+	anchorDay := r.Anchor.In(loc).Day()
+	switch {
+	case anchorDay <= day:
+		return year, month, day
+	case r.EndOfMonth == EndOfMonthClamp && day == daysInMonth(year, month):
+		return year, month, anchorDay
+	case r.EndOfMonth == EndOfMonthRollover:
+		previousYear, previousMonth := year, month-1
+		if previousMonth < time.January {
+			previousYear, previousMonth = year-1, time.December
+		}
+		rolledYear, rolledMonth, rolledDay := time.Date(previousYear, previousMonth, anchorDay, 0, 0, 0, 0, time.UTC).Date()
+		if rolledYear == year && rolledMonth == month && rolledDay == day {
+			return previousYear, previousMonth, anchorDay
+		}
+	}
+	return year, month, day
 }
 
 // WeeklyRecurrence struct
 type WeeklyRecurrence struct{}
 
-// NextOccurrence returns the next occurrence timestamp for weekly recurrence
-func (r WeeklyRecurrence) NextOccurrence(from int64, interval int) int64 {
-	return from + int64(interval*604800) // 7 days
+// NextOccurrence returns the same weekday interval weeks later
+func (r WeeklyRecurrence) NextOccurrence(from time.Time, interval int, loc *time.Location) time.Time {
+	return addDays(from.In(loc), 7*interval, loc)
 }
 
 // DailyRecurrence struct
 type DailyRecurrence struct{}
 
-// NextOccurrence returns the next occurrence timestamp for daily recurrence
-func (r DailyRecurrence) NextOccurrence(from int64, interval int) int64 {
-	return from + int64(interval*86400) // 1 day
+// NextOccurrence returns the same time interval days later
+func (r DailyRecurrence) NextOccurrence(from time.Time, interval int, loc *time.Location) time.Time {
+	return addDays(from.In(loc), interval, loc)
 }
 
 // HourlyRecurrence struct
 type HourlyRecurrence struct{}
 
-// NextOccurrence returns the next occurrence timestamp for hourly recurrence
-func (r HourlyRecurrence) NextOccurrence(from int64, interval int) int64 {
-	return from + int64(interval*3600) // 1 hour
+// NextOccurrence returns the instant interval hours later; hourly reminders follow
+// the elapsed time rather than the wall clock, so no hour is skipped or repeated
+func (r HourlyRecurrence) NextOccurrence(from time.Time, interval int, loc *time.Location) time.Time {
+	return from.Add(time.Duration(interval) * time.Hour).In(loc)
 }
 
 // WorkdaysRecurrence struct
 type WorkdaysRecurrence struct{}
 
-// NextOccurrence returns the next occurrence timestamp for workdays recurrence
-func (r WorkdaysRecurrence) NextOccurrence(from int64, interval int) int64 {
-	daysAdded := 0
-	current := time.Unix(from, 0).UTC()
-	for daysAdded < interval {
-		current = current.AddDate(0, 0, 1)
+// NextOccurrence returns the interval-th weekday (Monday to Friday) after from
+func (r WorkdaysRecurrence) NextOccurrence(from time.Time, interval int, loc *time.Location) time.Time {
+	current := from.In(loc)
+	for daysAdded := 0; daysAdded < interval; {
+		current = addDays(current, 1, loc)
 		if current.Weekday() != time.Saturday && current.Weekday() != time.Sunday {
 			daysAdded++
 		}
 	}
-	return current.Unix()
+	return current
 }
 
 // WeekendRecurrence struct
 type WeekendRecurrence struct{}
 
-// NextOccurrence returns the next occurrence timestamp for weekend recurrence
-func (r WeekendRecurrence) NextOccurrence(from int64, interval int) int64 {
-	daysAdded := 0
-	current := time.Unix(from, 0).UTC()
-	for daysAdded < interval {
-		current = current.AddDate(0, 0, 1)
+// NextOccurrence returns the interval-th Saturday or Sunday after from
+func (r WeekendRecurrence) NextOccurrence(from time.Time, interval int, loc *time.Location) time.Time {
+	current := from.In(loc)
+	for daysAdded := 0; daysAdded < interval; {
+		current = addDays(current, 1, loc)
 		if current.Weekday() == time.Saturday || current.Weekday() == time.Sunday {
 			daysAdded++
 		}
 	}
-	return current.Unix()
+	return current
+}
+
+// addDays moves t by whole calendar days, keeping its wall-clock time in loc
+func addDays(t time.Time, days int, loc *time.Location) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day+days, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// daysInMonth returns the number of days of a month
+func daysInMonth(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// isLeapYear reports whether February has 29 days in the given year
+func isLeapYear(year int) bool {
+	return daysInMonth(year, time.February) == 29
 }
 
-// findNextFutureOccurrence calculates the next occurrence that is in the future
+// findNextFutureOccurrence returns the first occurrence after both from and now.
+// Occurrences missed while the bot was down are skipped, not caught up.
 func findNextFutureOccurrence(from time.Time, recurrence Recurrence, loc *time.Location) time.Time {
 	now := time.Now().In(loc)
 
-	// Determine if we should preserve time-of-day (not for hourly recurrence)
-	preserveTimeOfDay := true
-	if _, isHourly := recurrence.(HourlyRecurrence); isHourly {
-		preserveTimeOfDay = false
+	next := recurrence.NextOccurrence(from, 1, loc)
+	if next.After(now) {
+		return next
 	}
 
-	// If 'from' is already in the future, calculate next occurrence from it
-	if from.After(now) {
-		var nextTime time.Time
-		if preserveTimeOfDay {
-			// For daily+ recurrences, add days while preserving time-of-day
-			nextTime = addInterval(from, recurrence, 1, loc)
-		} else {
-			// For hourly, just add the fixed seconds
-			nextTimestamp := recurrence.NextOccurrence(from.Unix(), 1)
-			nextTime = time.Unix(nextTimestamp, 0).In(loc)
-		}
-		return nextTime
-	}
-
-	// If 'from' is in the past or equal to now, calculate how many intervals to skip
-	var nextTime time.Time
-
-	if preserveTimeOfDay {
-		// For daily+ recurrences, calculate approximate intervals to skip
-		var intervalsToSkip int
-		switch recurrence.(type) {
-		case DailyRecurrence:
-			days := int(now.Sub(from).Hours() / 24)
-			intervalsToSkip = days + 1
-		case WeeklyRecurrence:
-			days := int(now.Sub(from).Hours() / 24)
-			intervalsToSkip = (days / 7) + 1
-		case MonthlyRecurrence:
-			months := (now.Year()-from.Year())*12 + int(now.Month()-from.Month())
-			intervalsToSkip = months + 1
-		case YearlyRecurrence:
-			years := now.Year() - from.Year()
-			intervalsToSkip = years + 1
-		default:
-			// For workdays/weekend, approximate with days
-			days := int(now.Sub(from).Hours() / 24)
-			intervalsToSkip = days + 1
-		}
-		
-		// Jump ahead by the calculated intervals
-		nextTime = addInterval(from, recurrence, intervalsToSkip, loc)
-		
-		// Fine-tune: if we're still in the past, keep adding intervals
-		maxAttempts := 10
-		for attempt := 0; attempt < maxAttempts && !nextTime.After(now); attempt++ {
-			nextTime = addInterval(nextTime, recurrence, 1, loc)
-		}
-	} else {
-		// For hourly recurrence, use fixed seconds calculation
-		secondsDiff := now.Unix() - from.Unix()
-		intervalSeconds := int64(3600)
-		intervalsToSkip := int(secondsDiff/intervalSeconds) + 2
-
-		nextTimestamp := recurrence.NextOccurrence(from.Unix(), intervalsToSkip)
-		nextTime = time.Unix(nextTimestamp, 0).In(loc)
-
-		// Safety check
-		maxAttempts := 10
-		for attempt := 0; !nextTime.After(now) && attempt < maxAttempts; attempt++ {
-			nextTimestamp = recurrence.NextOccurrence(nextTime.Unix(), 1)
-			nextTime = time.Unix(nextTimestamp, 0).In(loc)
-		}
+	// Jump over most of the missed occurrences at once, then step to the first future one
+	if missed := missedOccurrences(from, now, recurrence); missed > 1 {
+		next = recurrence.NextOccurrence(from, missed, loc)
+	}
+	for !next.After(now) {
+		next = recurrence.NextOccurrence(next, 1, loc)
 	}
 
-	return nextTime
+	return next
 }
 
-// addInterval adds one interval to the given time while preserving time-of-day and handling DST
-func addInterval(t time.Time, recurrence Recurrence, intervals int, loc *time.Location) time.Time {
-	// Preserve the time-of-day components
-	hour, minute, second := t.Hour(), t.Minute(), t.Second()
-	nanosecond := t.Nanosecond()
+// missedOccurrences returns a lower bound of the occurrences between from and now,
+// low enough to never jump over a future occurrence
+func missedOccurrences(from, now time.Time, recurrence Recurrence) int {
+	elapsed := now.Sub(from)
+	days := int(elapsed.Hours()/24) - 1 // days around DST transitions last 23 or 25 hours
 
-	var nextTime time.Time
 	switch recurrence.(type) {
+	case HourlyRecurrence:
+		return int(elapsed.Hours())
 	case DailyRecurrence:
-		nextTime = t.AddDate(0, 0, intervals)
+		return days
 	case WeeklyRecurrence:
-		nextTime = t.AddDate(0, 0, 7*intervals)
-	case MonthlyRecurrence:
-		nextTime = t.AddDate(0, intervals, 0)
-	case YearlyRecurrence:
-		nextTime = t.AddDate(intervals, 0, 0)
+		return days / 7
 	case WorkdaysRecurrence:
-		// Add days while skipping weekends
-		daysAdded := 0
-		currentTime := t
-		for daysAdded < intervals {
-			currentTime = currentTime.AddDate(0, 0, 1)
-			// Check if the day is a weekday (Monday-Friday)
-			// time.Weekday: Sunday=0, Monday=1, ..., Friday=5, Saturday=6
-			if currentTime.Weekday() > 0 && currentTime.Weekday() < 6 {
-				daysAdded++
-			}
-		}
-		nextTime = currentTime
+		return days / 7 * 5
 	case WeekendRecurrence:
-		// Add days while skipping weekdays
-		daysAdded := 0
-		currentTime := t
-		for daysAdded < intervals {
-			currentTime = currentTime.AddDate(0, 0, 1)
-			// Check if the day is a weekend day (Saturday or Sunday)
-			// time.Weekday: Sunday=0, Saturday=6
-			if currentTime.Weekday() == 0 || currentTime.Weekday() == 6 {
-				daysAdded++
-			}
-		}
-		nextTime = currentTime
-	default:
-		// Fallback
-		nextTime = t.AddDate(0, 0, intervals)
+		return days / 7 * 2
 	}
 
-	// Restore the original time-of-day to handle DST properly
-	nextTime = time.Date(
-		nextTime.Year(), nextTime.Month(), nextTime.Day(),
-		hour, minute, second, nanosecond,
-		loc,
-	)
+	// Monthly and yearly recurrences step through the calendar, a few hundred steps at most
+	return 0
+}
 
-	return nextTime
+// RecurrenceSeries describes the series an occurrence belongs to. Start is the first
+// occurrence: the DTSTART of an RRULE, and the day monthly and yearly recurrences come
+// back to after a shorter month. RRule is only used by the RRULE recurrence type.
+type RecurrenceSeries struct {
+	Start time.Time
+	RRule string
+}
+
+// ReminderRecurrenceSeries returns the series of a reminder
+func ReminderRecurrenceSeries(reminder *models.Reminder) *RecurrenceSeries {
+	series := &RecurrenceSeries{Start: reminder.RemindAtUTC}
+	if reminder.RecurrenceStartUTC != nil {
+		series.Start = *reminder.RecurrenceStartUTC
+	}
+	if reminder.RRule != nil {
+		series.RRule = *reminder.RRule
+	}
+	return series
 }
 
 // GetNextOccurrence calculates the next occurrence timestamp based on recurrence state (with bits) and interval
 // ianaLocation is the IANA timezone identifier for the user (e.g., "Europe/Paris")
 func GetNextOccurrence(from time.Time, recurrenceState int, ianaLocation string) (time.Time, error) {
+	return GetNextOccurrenceInSeries(from, recurrenceState, nil, ianaLocation)
+}
+
+// GetNextOccurrenceInSeries is GetNextOccurrence for an occurrence of a known series.
+// The series anchors monthly and yearly recurrences and carries the rule of the RRULE
+// type; ErrRecurrenceEnded is returned once that rule's COUNT or UNTIL is exhausted.
+func GetNextOccurrenceInSeries(from time.Time, recurrenceState int, series *RecurrenceSeries, ianaLocation string) (time.Time, error) {
 	// Extract the actual recurrence type from the bit-encoded state
 	recurrenceType := GetRecurrenceType(recurrenceState)
 	isPaused := IsPaused(recurrenceState)
@@ -336,16 +504,23 @@ func GetNextOccurrence(from time.Time, recurrenceState int, ianaLocation string)
 		return time.Time{}, fmt.Errorf("failed to load timezone %s: %w", ianaLocation, err)
 	}
 
-	// Convert the UTC instant to the user's local timezone
-	fromLocal := from.In(loc)
+	if recurrenceType == RecurrenceRRule {
+		if series == nil || series.RRule == "" {
+			return time.Time{}, fmt.Errorf("recurrence state %d requires an rrule", recurrenceState)
+		}
+		return nextRRuleOccurrence(from, series, loc)
+	}
 
-	recurrence := Recurrences[GetRecurrenceTypeName(recurrenceType)]
+	var anchor time.Time
+	if series != nil {
+		anchor = series.Start
+	}
+
+	recurrence := NewRecurrence(recurrenceType, anchor, DefaultRecurrenceOptions())
 	if recurrence == nil {
 		return time.Time{}, fmt.Errorf("invalid recurrence type: %d (extracted from state: %d)", recurrenceType, recurrenceState)
 	}
 
-	// Find the next future occurrence by iterating through past ones if needed
-	// maxIterations prevents infinite loops for edge cases (set to 1000 as safety limit)
-	nextTimeLocal := findNextFutureOccurrence(fromLocal, recurrence, loc)
-	return nextTimeLocal, nil
-}
\ No newline at end of file
+	// Skip the occurrences already in the past, from is converted to the user's timezone
+	return findNextFutureOccurrence(from.In(loc), recurrence, loc), nil
+}
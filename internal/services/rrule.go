@@ -0,0 +1,186 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/teambition/rrule-go"
+)
+
+// ErrRecurrenceEnded is returned when a rule with COUNT or UNTIL has no occurrence left
+var ErrRecurrenceEnded = errors.New("recurrence has no further occurrences")
+
+// maxRRuleLength bounds the size of a stored rule
+const maxRRuleLength = 512
+
+// ParseRRule validates a user supplied RRULE and returns its normalized form.
+// The "RRULE:" prefix is optional; DTSTART must not be part of the rule since the
+// series starts at the reminder date. An UNTIL without "Z" is read in the user's
+// timezone, and a date-only UNTIL includes that whole day. Frequencies below one hour are refused, the
+// same way the fixed recurrences stop at HOURLY.
+func ParseRRule(rule string) (string, error) {
+	rule = strings.TrimSpace(rule)
+	rule = strings.TrimPrefix(strings.ToUpper(rule), "RRULE:")
+	if rule == "" {
+		return "", fmt.Errorf("rrule is empty")
+	}
+	if len(rule) > maxRRuleLength {
+		return "", fmt.Errorf("rrule is longer than %d characters", maxRRuleLength)
+	}
+	if strings.Contains(rule, "\n") || strings.Contains(rule, "DTSTART") {
+		return "", fmt.Errorf("rrule must not contain DTSTART, the reminder date is used as the start of the series")
+	}
+
+	option, err := rrule.StrToROption(rule)
+	if err != nil {
+		return "", fmt.Errorf("invalid rrule: %w", err)
+	}
+
+	if option.Freq == rrule.MINUTELY || option.Freq == rrule.SECONDLY {
+		return "", fmt.Errorf("invalid rrule: the shortest supported frequency is HOURLY")
+	}
+	if len(option.Bysecond) > 0 {
+		return "", fmt.Errorf("invalid rrule: BYSECOND is not supported")
+	}
+	if option.Interval < 0 || option.Count < 0 {
+		return "", fmt.Errorf("invalid rrule: INTERVAL and COUNT must be positive")
+	}
+
+	// Build the rule once to let the library check the BYxxx bounds
+	if _, err := rrule.NewRRule(*option); err != nil {
+		return "", fmt.Errorf("invalid rrule: %w", err)
+	}
+
+	// Keep the rule as written: a floating UNTIL must stay in the user's timezone
+	return rule, nil
+}
+
+// IsHourlyRRule reports whether a rule fires more than once a day
+func IsHourlyRRule(rule string) bool {
+	option, err := rrule.StrToROption(rule)
+	if err != nil {
+		return false
+	}
+	return option.Freq == rrule.HOURLY || len(option.Byhour) > 1 || len(option.Byminute) > 1
+}
+
+// buildRRule compiles the rule of a series ("FREQ=MONTHLY;BYDAY=-1FR") with the series
+// start as DTSTART; COUNT and INTERVAL are counted from it. The rule is evaluated in the
+// user's timezone so every occurrence keeps its wall-clock time across DST transitions.
+func buildRRule(series *RecurrenceSeries, loc *time.Location) (*rrule.RRule, error) {
+	option, err := rrule.StrToROptionInLocation(series.RRule, loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rrule: %w", err)
+	}
+	option.Dtstart = series.Start.In(loc)
+
+	// "UNTIL=20261231" means "up to Dec 31 included", not "before Dec 31 00:00"
+	if !option.Until.IsZero() && untilIsDateOnly(series.RRule) {
+		option.Until = time.Date(option.Until.Year(), option.Until.Month(), option.Until.Day(), 23, 59, 59, 0, loc)
+	}
+
+	compiled, err := rrule.NewRRule(*option)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rrule: %w", err)
+	}
+	return compiled, nil
+}
+
+// untilIsDateOnly reports whether the UNTIL part of a rule is a DATE value (YYYYMMDD)
+func untilIsDateOnly(rule string) bool {
+	for _, part := range strings.Split(rule, ";") {
+		if value, found := strings.CutPrefix(part, "UNTIL="); found {
+			return len(value) == len("20060102")
+		}
+	}
+	return false
+}
+
+// FirstRRuleOccurrence returns the first occurrence of the rule at or after its
+// start, which becomes the first fire time of a newly created reminder
+func FirstRRuleOccurrence(series *RecurrenceSeries, ianaLocation string) (time.Time, error) {
+	loc, err := time.LoadLocation(ianaLocation)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to load timezone %s: %w", ianaLocation, err)
+	}
+
+	compiled, err := buildRRule(series, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	first := compiled.After(series.Start.In(loc), true)
+	if first.IsZero() {
+		return time.Time{}, ErrRecurrenceEnded
+	}
+	return first, nil
+}
+
+// nextRRuleOccurrence returns the first occurrence strictly after both from and now
+func nextRRuleOccurrence(from time.Time, series *RecurrenceSeries, loc *time.Location) (time.Time, error) {
+	compiled, err := buildRRule(series, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	after := from.In(loc)
+	if now := time.Now().In(loc); now.After(after) {
+		after = now
+	}
+
+	next := compiled.After(after, false)
+	if next.IsZero() {
+		return time.Time{}, ErrRecurrenceEnded
+	}
+	return next, nil
+}
+
+// ApplyRRule validates a rule and attaches it to the reminder, starting the series at
+// the reminder date. The reminder switches to the RRULE recurrence type and its first
+// fire time moves to the first occurrence of the rule. An empty rule detaches the
+// current one, unless the recurrence type is RRULE which cannot go without a rule.
+func ApplyRRule(reminder *models.Reminder, rule string, ianaLocation string) error {
+	start := reminder.RemindAtUTC
+	if rule == "" {
+		if GetRecurrenceType(int(reminder.Recurrence)) == RecurrenceRRule {
+			return fmt.Errorf("rrule is required for the RRULE recurrence")
+		}
+		reminder.RRule = nil
+		reminder.RecurrenceStartUTC = &start
+		return nil
+	}
+
+	normalized, err := ParseRRule(rule)
+	if err != nil {
+		return err
+	}
+
+	reminder.RRule = &normalized
+	reminder.RecurrenceStartUTC = &start
+	reminder.Recurrence = int16(BuildRecurrenceState(RecurrenceRRule, IsPaused(int(reminder.Recurrence))))
+
+	first, err := FirstRRuleOccurrence(ReminderRecurrenceSeries(reminder), ianaLocation)
+	if errors.Is(err, ErrRecurrenceEnded) {
+		return fmt.Errorf("rrule has no occurrence after the reminder date")
+	}
+	if err != nil {
+		return err
+	}
+	reminder.RemindAtUTC = first.UTC()
+	reminder.NextFireUTC = &reminder.RemindAtUTC
+	return nil
+}
+
+// IsHourlyReminder reports whether a reminder fires several times a day
+func IsHourlyReminder(reminder *models.Reminder) bool {
+	switch GetRecurrenceType(int(reminder.Recurrence)) {
+	case RecurrenceHourly:
+		return true
+	case RecurrenceRRule:
+		return reminder.RRule != nil && IsHourlyRRule(*reminder.RRule)
+	}
+	return false
+}
@@ -0,0 +1,419 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"html"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database"
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// TelegramLinkCodeTTL is how long a chat link code can be redeemed
+	TelegramLinkCodeTTL = 10 * time.Minute
+	// telegramSnoozeCallbackPrefix prefixes the callback data of the snooze buttons
+	telegramSnoozeCallbackPrefix = "snooze"
+)
+
+// TelegramSnoozeOptions are the snooze durations offered under a reminder, in minutes
+var TelegramSnoozeOptions = []int{10, 60, 1440}
+
+var (
+	// ErrTelegramNotConfigured is returned when no bot token is set
+	ErrTelegramNotConfigured = errors.New("telegram is not configured")
+	// ErrTelegramChatNotLinked is returned when a chat is not linked to the account
+	ErrTelegramChatNotLinked = errors.New("telegram chat is not linked to this account")
+)
+
+// TelegramLinkCodeStore keeps the one-time codes linking a Telegram chat to an account
+type TelegramLinkCodeStore interface {
+	Save(code string, accountID uuid.UUID, ttl time.Duration) error
+	// Consume returns the account of the code and invalidates it, uuid.Nil when unknown or expired
+	Consume(code string) (uuid.UUID, error)
+}
+
+// RedisTelegramLinkCodeStore stores the link codes in Redis
+type RedisTelegramLinkCodeStore struct {
+	client *redis.Client
+}
+
+// NewRedisTelegramLinkCodeStore creates a link code store on the shared Redis client
+func NewRedisTelegramLinkCodeStore() *RedisTelegramLinkCodeStore {
+	return &RedisTelegramLinkCodeStore{client: database.GetRedisClient()}
+}
+
+func (s *RedisTelegramLinkCodeStore) key(code string) string {
+	return fmt.Sprintf("telegram_link:%s", code)
+}
+
+// Save stores the code until ttl expires
+func (s *RedisTelegramLinkCodeStore) Save(code string, accountID uuid.UUID, ttl time.Duration) error {
+	if s.client == nil {
+		return fmt.Errorf("redis is not initialized")
+	}
+	return s.client.Set(context.Background(), s.key(code), accountID.String(), ttl).Err()
+}
+
+// Consume reads and deletes the code atomically so it can only be redeemed once
+func (s *RedisTelegramLinkCodeStore) Consume(code string) (uuid.UUID, error) {
+	if s.client == nil {
+		return uuid.Nil, fmt.Errorf("redis is not initialized")
+	}
+
+	value, err := s.client.GetDel(context.Background(), s.key(code)).Result()
+	if err == redis.Nil {
+		return uuid.Nil, nil
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	accountID, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, nil
+	}
+	return accountID, nil
+}
+
+// TelegramLinkCode is a code the user sends to the bot to link a chat
+type TelegramLinkCode struct {
+	Code      string    `json:"code"`
+	Link      string    `json:"link,omitempty"` // t.me deep link starting the bot with the code
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// TelegramService links Telegram chats to accounts and handles the bot updates
+type TelegramService struct {
+	client       *TelegramClient
+	identityRepo repositories.IdentityRepository
+	reminderRepo repositories.ReminderRepository
+	codes        TelegramLinkCodeStore
+	botUsername  string
+}
+
+// NewTelegramService creates a new Telegram service
+func NewTelegramService(
+	client *TelegramClient,
+	identityRepo repositories.IdentityRepository,
+	reminderRepo repositories.ReminderRepository,
+	codes TelegramLinkCodeStore,
+	botUsername string,
+) *TelegramService {
+	return &TelegramService{
+		client:       client,
+		identityRepo: identityRepo,
+		reminderRepo: reminderRepo,
+		codes:        codes,
+		botUsername:  strings.TrimPrefix(botUsername, "@"),
+	}
+}
+
+// IsEnabled reports whether the bot is configured
+func (s *TelegramService) IsEnabled() bool {
+	return s != nil && s.client.IsEnabled()
+}
+
+// CreateLinkCode generates a one-time code linking the chat it is sent from to the account
+func (s *TelegramService) CreateLinkCode(accountID uuid.UUID) (*TelegramLinkCode, error) {
+	if !s.IsEnabled() {
+		return nil, ErrTelegramNotConfigured
+	}
+
+	bytes := make([]byte, 5)
+	if _, err := rand.Read(bytes); err != nil {
+		return nil, fmt.Errorf("failed to generate link code: %w", err)
+	}
+	code := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(bytes)
+
+	if err := s.codes.Save(code, accountID, TelegramLinkCodeTTL); err != nil {
+		return nil, fmt.Errorf("failed to store link code: %w", err)
+	}
+
+	linkCode := &TelegramLinkCode{
+		Code:      code,
+		ExpiresAt: time.Now().UTC().Add(TelegramLinkCodeTTL),
+	}
+	if s.botUsername != "" {
+		linkCode.Link = fmt.Sprintf("https://t.me/%s?start=%s", s.botUsername, code)
+	}
+	return linkCode, nil
+}
+
+// LinkedChats returns the Telegram chats linked to the account
+func (s *TelegramService) LinkedChats(accountID uuid.UUID) ([]models.Identity, error) {
+	identities, err := s.identityRepo.GetByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	chats := []models.Identity{}
+	for _, identity := range identities {
+		if identity.Provider == models.ProviderTelegram {
+			chats = append(chats, identity)
+		}
+	}
+	return chats, nil
+}
+
+// UnlinkChat removes the link between a chat and the account
+func (s *TelegramService) UnlinkChat(accountID uuid.UUID, chatID string) error {
+	identity, err := s.identityRepo.GetByProviderAndExternalID(models.ProviderTelegram, chatID)
+	if err != nil {
+		return err
+	}
+	if identity == nil || identity.AccountID != accountID {
+		return ErrTelegramChatNotLinked
+	}
+
+	return s.identityRepo.Delete(identity.ID)
+}
+
+// IsChatLinked reports whether the chat is linked to the account
+func (s *TelegramService) IsChatLinked(accountID uuid.UUID, chatID string) (bool, error) {
+	identity, err := s.identityRepo.GetByProviderAndExternalID(models.ProviderTelegram, chatID)
+	if err != nil {
+		return false, err
+	}
+	return identity != nil && identity.AccountID == accountID, nil
+}
+
+// SendReminder sends the reminder to a chat with its snooze buttons
+func (s *TelegramService) SendReminder(reminder *models.Reminder, chatID string, account *models.Account) (*TelegramMessage, error) {
+	if !s.IsEnabled() {
+		return nil, ErrTelegramNotConfigured
+	}
+	return s.client.SendMessage(chatID, FormatTelegramReminder(reminder, account), TelegramSnoozeKeyboard(reminder.ID))
+}
+
+// HandleUpdate processes an update received on the bot webhook, the logs carry the
+// correlation ID of ctx
+func (s *TelegramService) HandleUpdate(ctx context.Context, update *TelegramUpdate) error {
+	if !s.IsEnabled() {
+		return ErrTelegramNotConfigured
+	}
+
+	switch {
+	case update.CallbackQuery != nil:
+		return s.handleCallbackQuery(ctx, update.CallbackQuery)
+	case update.Message != nil:
+		return s.handleMessage(ctx, update.Message)
+	}
+	return nil
+}
+
+// handleMessage answers the bot commands: /start <code>, /link <code> and /unlink
+func (s *TelegramService) handleMessage(ctx context.Context, message *TelegramMessage) error {
+	fields := strings.Fields(message.Text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return nil
+	}
+
+	// Commands sent in groups may be addressed as /link@botname
+	command, _, _ := strings.Cut(strings.ToLower(fields[0]), "@")
+	chatID := strconv.FormatInt(message.Chat.ID, 10)
+
+	switch command {
+	case "/start", "/link":
+		if len(fields) < 2 {
+			return s.reply(chatID, "👋 Send the code shown in Chronos with <code>/link CODE</code> to receive your reminders here.")
+		}
+		return s.linkChat(ctx, message, strings.ToUpper(fields[1]))
+	case "/unlink":
+		identity, err := s.identityRepo.GetByProviderAndExternalID(models.ProviderTelegram, chatID)
+		if err != nil {
+			return err
+		}
+		if identity == nil {
+			return s.reply(chatID, "This chat is not linked to a Chronos account.")
+		}
+		if err := s.identityRepo.Delete(identity.ID); err != nil {
+			return err
+		}
+		logging.FromContext(ctx).InfoContext(ctx, "Telegram chat unlinked", "chat_id", chatID, "account_id", identity.AccountID)
+		return s.reply(chatID, "✅ This chat is no longer linked to your Chronos account.")
+	}
+	return nil
+}
+
+// linkChat redeems a link code and links the chat to its account
+func (s *TelegramService) linkChat(ctx context.Context, message *TelegramMessage, code string) error {
+	chatID := strconv.FormatInt(message.Chat.ID, 10)
+
+	accountID, err := s.codes.Consume(code)
+	if err != nil {
+		return fmt.Errorf("failed to read link code: %w", err)
+	}
+	if accountID == uuid.Nil {
+		return s.reply(chatID, "❌ This code is invalid or has expired. Generate a new one from Chronos.")
+	}
+
+	existing, err := s.identityRepo.GetByProviderAndExternalID(models.ProviderTelegram, chatID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		if existing.AccountID == accountID {
+			return s.reply(chatID, "This chat is already linked to your Chronos account.")
+		}
+		return s.reply(chatID, "❌ This chat is linked to another Chronos account. Send /unlink first.")
+	}
+
+	identity := &models.Identity{
+		AccountID:  accountID,
+		Provider:   models.ProviderTelegram,
+		ExternalID: chatID,
+	}
+	if name := telegramChatName(message); name != "" {
+		identity.Username = &name
+	}
+	if err := s.identityRepo.Create(identity); err != nil {
+		return fmt.Errorf("failed to link telegram chat: %w", err)
+	}
+
+	logging.FromContext(ctx).InfoContext(ctx, "Telegram chat linked", "chat_id", chatID, "account_id", accountID)
+	return s.reply(chatID, "✅ This chat is now linked to your Chronos account. Add it as a Telegram destination to receive your reminders here.")
+}
+
+// handleCallbackQuery snoozes the reminder of a pressed snooze button
+func (s *TelegramService) handleCallbackQuery(ctx context.Context, query *TelegramCallbackQuery) error {
+	reminderID, minutes, ok := ParseTelegramSnoozeCallback(query.Data)
+	if !ok || query.Message == nil {
+		return s.client.AnswerCallbackQuery(query.ID, "This button is no longer available.")
+	}
+	chatID := strconv.FormatInt(query.Message.Chat.ID, 10)
+
+	reminder, err := s.reminderRepo.GetWithDestinations(reminderID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch reminder: %w", err)
+	}
+	if reminder == nil {
+		return s.client.AnswerCallbackQuery(query.ID, "Reminder not found. It may have been deleted.")
+	}
+
+	// Only the chats the reminder is delivered to, still linked to its account, may snooze it
+	allowed, err := s.canSnoozeFromChat(reminder, chatID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return s.client.AnswerCallbackQuery(query.ID, "You don't have permission to snooze this reminder.")
+	}
+
+	if reminder.SnoozedAtUTC != nil {
+		return s.client.AnswerCallbackQuery(query.ID, "This reminder is already snoozed.")
+	}
+
+	snoozeUntil := time.Now().UTC().Add(time.Duration(minutes) * time.Minute)
+	if err := s.reminderRepo.SnoozeReminder(reminder, snoozeUntil); err != nil {
+		return fmt.Errorf("failed to snooze reminder: %w", err)
+	}
+
+	if err := s.client.RemoveInlineKeyboard(chatID, query.Message.MessageID); err != nil {
+		logging.FromContext(ctx).WarnContext(ctx, "Failed to remove the Telegram snooze buttons", "chat_id", chatID, "reminder_id", reminder.ID, "error", err)
+	}
+	return s.client.AnswerCallbackQuery(query.ID, "⏰ Snoozed for "+formatSnoozeDuration(minutes))
+}
+
+// canSnoozeFromChat checks the reminder has a Telegram destination for the chat and the chat belongs to its account
+func (s *TelegramService) canSnoozeFromChat(reminder *models.Reminder, chatID string) (bool, error) {
+	delivered := slices.ContainsFunc(reminder.Destinations, func(destination models.ReminderDestination) bool {
+		return destination.Type == models.DestinationTelegram && fmt.Sprint(destination.Metadata["chat_id"]) == chatID
+	})
+	if !delivered {
+		return false, nil
+	}
+	return s.IsChatLinked(reminder.AccountID, chatID)
+}
+
+func (s *TelegramService) reply(chatID string, text string) error {
+	_, err := s.client.SendMessage(chatID, text, nil)
+	return err
+}
+
+// FormatTelegramReminder renders the reminder as an HTML Telegram message
+func FormatTelegramReminder(reminder *models.Reminder, account *models.Account) string {
+	remindAt := reminder.RemindAtUTC
+	if account != nil && account.Timezone != nil {
+		if loc, err := time.LoadLocation(account.Timezone.IANALocation); err == nil {
+			remindAt = remindAt.In(loc)
+		}
+	}
+
+	return fmt.Sprintf("⏰ <b>You have a new reminder!</b>\n\n%s\n\n<i>%s</i>",
+		html.EscapeString(reminder.Message),
+		remindAt.Format("Monday, January 2, 2006 at 15:04 MST"))
+}
+
+// TelegramSnoozeKeyboard returns the snooze buttons of a reminder message
+func TelegramSnoozeKeyboard(reminderID uuid.UUID) *TelegramInlineKeyboardMarkup {
+	row := make([]TelegramInlineKeyboardButton, 0, len(TelegramSnoozeOptions))
+	for _, minutes := range TelegramSnoozeOptions {
+		row = append(row, TelegramInlineKeyboardButton{
+			Text:         "💤 " + formatSnoozeDuration(minutes),
+			CallbackData: fmt.Sprintf("%s:%s:%d", telegramSnoozeCallbackPrefix, reminderID, minutes),
+		})
+	}
+	return &TelegramInlineKeyboardMarkup{InlineKeyboard: [][]TelegramInlineKeyboardButton{row}}
+}
+
+// ParseTelegramSnoozeCallback reads the callback data of a snooze button, only the offered durations are accepted
+func ParseTelegramSnoozeCallback(data string) (uuid.UUID, int, bool) {
+	parts := strings.Split(data, ":")
+	if len(parts) != 3 || parts[0] != telegramSnoozeCallbackPrefix {
+		return uuid.Nil, 0, false
+	}
+
+	reminderID, err := uuid.Parse(parts[1])
+	if err != nil {
+		return uuid.Nil, 0, false
+	}
+	minutes, err := strconv.Atoi(parts[2])
+	if err != nil || !slices.Contains(TelegramSnoozeOptions, minutes) {
+		return uuid.Nil, 0, false
+	}
+	return reminderID, minutes, true
+}
+
+// formatSnoozeDuration renders a snooze duration in minutes as a button label
+func formatSnoozeDuration(minutes int) string {
+	switch {
+	case minutes%1440 == 0:
+		if minutes == 1440 {
+			return "1 day"
+		}
+		return fmt.Sprintf("%d days", minutes/1440)
+	case minutes%60 == 0:
+		if minutes == 60 {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", minutes/60)
+	}
+	return fmt.Sprintf("%d min", minutes)
+}
+
+// telegramChatName returns a display name for the linked chat
+func telegramChatName(message *TelegramMessage) string {
+	switch {
+	case message.Chat.Title != "":
+		return message.Chat.Title
+	case message.Chat.Username != "":
+		return "@" + message.Chat.Username
+	case message.From != nil && message.From.Username != "":
+		return "@" + message.From.Username
+	case message.From != nil:
+		return message.From.FirstName
+	}
+	return ""
+}
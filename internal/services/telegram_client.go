@@ -0,0 +1,211 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TelegramDefaultAPIBaseURL is the official Telegram Bot API endpoint
+const TelegramDefaultAPIBaseURL = "https://api.telegram.org"
+
+// TelegramAPIError is a failure answered by the Telegram Bot API
+type TelegramAPIError struct {
+	Method      string
+	StatusCode  int
+	Description string
+	RetryAfter  time.Duration // set on 429 responses
+}
+
+func (e *TelegramAPIError) Error() string {
+	return fmt.Sprintf("telegram %s failed with status %d: %s", e.Method, e.StatusCode, e.Description)
+}
+
+// TelegramUser is the sender of a message or a callback query
+type TelegramUser struct {
+	ID        int64  `json:"id"`
+	Username  string `json:"username,omitempty"`
+	FirstName string `json:"first_name,omitempty"`
+}
+
+// TelegramChat is a private chat, group or channel
+type TelegramChat struct {
+	ID       int64  `json:"id"`
+	Type     string `json:"type"`
+	Title    string `json:"title,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// TelegramMessage is a message sent or received by the bot
+type TelegramMessage struct {
+	MessageID int64         `json:"message_id"`
+	From      *TelegramUser `json:"from,omitempty"`
+	Chat      TelegramChat  `json:"chat"`
+	Text      string        `json:"text,omitempty"`
+}
+
+// TelegramCallbackQuery is sent when a user presses an inline keyboard button
+type TelegramCallbackQuery struct {
+	ID      string           `json:"id"`
+	From    TelegramUser     `json:"from"`
+	Message *TelegramMessage `json:"message,omitempty"`
+	Data    string           `json:"data,omitempty"`
+}
+
+// TelegramUpdate is an incoming update delivered to the bot webhook
+type TelegramUpdate struct {
+	UpdateID      int64                  `json:"update_id"`
+	Message       *TelegramMessage       `json:"message,omitempty"`
+	CallbackQuery *TelegramCallbackQuery `json:"callback_query,omitempty"`
+}
+
+// TelegramInlineKeyboardButton is a button attached under a message
+type TelegramInlineKeyboardButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data"`
+}
+
+// TelegramInlineKeyboardMarkup is the inline keyboard of a message
+type TelegramInlineKeyboardMarkup struct {
+	InlineKeyboard [][]TelegramInlineKeyboardButton `json:"inline_keyboard"`
+}
+
+// telegramResponse is the envelope of every Bot API response
+type telegramResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	ErrorCode   int             `json:"error_code"`
+	Description string          `json:"description"`
+	Parameters  *struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+// TelegramClient calls the Telegram Bot API. The base URL can point to a local fake.
+type TelegramClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewTelegramClient creates a Bot API client, the official endpoint is used when baseURL is empty
+func NewTelegramClient(baseURL, token string) *TelegramClient {
+	if baseURL == "" {
+		baseURL = TelegramDefaultAPIBaseURL
+	}
+
+	return &TelegramClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// IsEnabled reports whether a bot token is configured
+func (c *TelegramClient) IsEnabled() bool {
+	return c != nil && c.token != ""
+}
+
+// SendMessage sends an HTML formatted message with an optional inline keyboard
+func (c *TelegramClient) SendMessage(chatID string, text string, keyboard *TelegramInlineKeyboardMarkup) (*TelegramMessage, error) {
+	params := map[string]interface{}{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	}
+	if keyboard != nil {
+		params["reply_markup"] = keyboard
+	}
+
+	var message TelegramMessage
+	if err := c.call("sendMessage", params, &message); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// AnswerCallbackQuery acknowledges a button press, showing text as a toast
+func (c *TelegramClient) AnswerCallbackQuery(callbackQueryID string, text string) error {
+	return c.call("answerCallbackQuery", map[string]interface{}{
+		"callback_query_id": callbackQueryID,
+		"text":              text,
+	}, nil)
+}
+
+// RemoveInlineKeyboard removes the buttons of a message sent by the bot
+func (c *TelegramClient) RemoveInlineKeyboard(chatID string, messageID int64) error {
+	return c.call("editMessageReplyMarkup", map[string]interface{}{
+		"chat_id":      chatID,
+		"message_id":   messageID,
+		"reply_markup": TelegramInlineKeyboardMarkup{InlineKeyboard: [][]TelegramInlineKeyboardButton{}},
+	}, nil)
+}
+
+// SetWebhook registers the URL Telegram delivers the bot updates to
+func (c *TelegramClient) SetWebhook(webhookURL string, secretToken string) error {
+	params := map[string]interface{}{
+		"url":             webhookURL,
+		"allowed_updates": []string{"message", "callback_query"},
+	}
+	if secretToken != "" {
+		params["secret_token"] = secretToken
+	}
+	return c.call("setWebhook", params, nil)
+}
+
+// call sends a Bot API method and decodes its result into result when not nil
+func (c *TelegramClient) call(method string, params interface{}, result interface{}) error {
+	if !c.IsEnabled() {
+		return fmt.Errorf("telegram bot token is not configured")
+	}
+
+	body, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to encode telegram %s request: %w", method, err)
+	}
+
+	resp, err := c.httpClient.Post(c.baseURL+"/bot"+c.token+"/"+method, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// The request URL embeds the bot token, keep it out of the logs
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = c.baseURL + "/bot<redacted>/" + method
+		}
+		return fmt.Errorf("telegram %s request failed: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var decoded telegramResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return &TelegramAPIError{Method: method, StatusCode: resp.StatusCode, Description: http.StatusText(resp.StatusCode)}
+		}
+		return fmt.Errorf("failed to decode telegram %s response: %w", method, err)
+	}
+
+	if !decoded.OK {
+		apiErr := &TelegramAPIError{Method: method, StatusCode: decoded.ErrorCode, Description: decoded.Description}
+		if apiErr.StatusCode == 0 {
+			apiErr.StatusCode = resp.StatusCode
+		}
+		if decoded.Parameters != nil && decoded.Parameters.RetryAfter > 0 {
+			apiErr.RetryAfter = time.Duration(decoded.Parameters.RetryAfter) * time.Second
+		}
+		return apiErr
+	}
+
+	if result != nil && len(decoded.Result) > 0 {
+		if err := json.Unmarshal(decoded.Result, result); err != nil {
+			return fmt.Errorf("failed to decode telegram %s result: %w", method, err)
+		}
+	}
+	return nil
+}
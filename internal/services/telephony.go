@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/config"
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/google/uuid"
+)
+
+const (
+	// PhoneVerificationTTL is how long a phone verification code can be entered
+	PhoneVerificationTTL = 10 * time.Minute
+	// PhoneVerificationCooldown is the minimum wait before sending another code to a number
+	PhoneVerificationCooldown = time.Minute
+	// MaxPhoneVerificationAttempts is the number of wrong codes accepted before a new one must be sent
+	MaxPhoneVerificationAttempts = 5
+	// MaxSMSLength caps the characters of an SMS, longer texts are truncated
+	MaxSMSLength = 1600
+)
+
+var (
+	// ErrTelephonyNotConfigured is returned when no telephony provider is configured
+	ErrTelephonyNotConfigured = errors.New("sms and voice are not configured")
+	// ErrInvalidPhoneNumber is returned for numbers not in E.164 format
+	ErrInvalidPhoneNumber = errors.New("phone number must be in E.164 format (e.g. +33612345678)")
+	// ErrPhoneNumberNotFound is returned when the account has not registered the number
+	ErrPhoneNumberNotFound = errors.New("phone number not found")
+	// ErrPhoneNumberNotVerified is returned when sending to a number not verified by the account
+	ErrPhoneNumberNotVerified = errors.New("phone number is not verified")
+	// ErrPhoneNumberAlreadyVerified is returned when requesting a code for a verified number
+	ErrPhoneNumberAlreadyVerified = errors.New("phone number is already verified")
+	// ErrPhoneVerificationCooldown is returned when a code was sent less than a minute ago
+	ErrPhoneVerificationCooldown = errors.New("a verification code was just sent, please wait before requesting another one")
+	// ErrInvalidPhoneVerificationCode is returned for a wrong or expired code
+	ErrInvalidPhoneVerificationCode = errors.New("invalid or expired verification code")
+	// ErrTooManyPhoneVerificationAttempts is returned once too many wrong codes were entered
+	ErrTooManyPhoneVerificationAttempts = errors.New("too many wrong codes, request a new one")
+	// ErrTelephonyQuotaExceeded is returned when the monthly quota of the account is used up
+	ErrTelephonyQuotaExceeded = errors.New("monthly quota exceeded")
+)
+
+// TelephonyQuota is the number of SMS and voice calls an account may send per month
+type TelephonyQuota struct {
+	SMS   int
+	Voice int
+}
+
+// TelephonyUsageReport is the usage of an account for the current month
+type TelephonyUsageReport struct {
+	Month      string `json:"month"`
+	SMSUsed    int    `json:"sms_used"`
+	SMSQuota   int    `json:"sms_quota"`
+	VoiceUsed  int    `json:"voice_used"`
+	VoiceQuota int    `json:"voice_quota"`
+}
+
+// TelephonyService verifies phone numbers and sends SMS and voice reminders within the monthly quotas
+type TelephonyService struct {
+	provider        TelephonyProvider
+	phoneNumberRepo repositories.PhoneNumberRepository
+	usageRepo       repositories.TelephonyUsageRepository
+	quota           TelephonyQuota
+}
+
+// NewTelephonyService creates a new telephony service instance
+func NewTelephonyService(
+	provider TelephonyProvider,
+	phoneNumberRepo repositories.PhoneNumberRepository,
+	usageRepo repositories.TelephonyUsageRepository,
+	quota TelephonyQuota,
+) *TelephonyService {
+	return &TelephonyService{
+		provider:        provider,
+		phoneNumberRepo: phoneNumberRepo,
+		usageRepo:       usageRepo,
+		quota:           quota,
+	}
+}
+
+// NewTelephonyServiceFromConfig creates the telephony service configured by the TELEPHONY_* settings
+func NewTelephonyServiceFromConfig(cfg *config.Config, repos *repositories.Repositories) *TelephonyService {
+	return NewTelephonyService(
+		NewTwilioProvider(cfg.TelephonyAPIBaseURL, cfg.TelephonyAccountSID, cfg.TelephonyAuthToken, cfg.TelephonyFromNumber),
+		repos.PhoneNumber,
+		repos.TelephonyUsage,
+		TelephonyQuota{SMS: cfg.TelephonySMSMonthlyQuota, Voice: cfg.TelephonyVoiceMonthlyQuota},
+	)
+}
+
+// IsEnabled reports whether SMS and voice can be sent
+func (s *TelephonyService) IsEnabled() bool {
+	return s != nil && s.provider != nil && s.provider.IsEnabled()
+}
+
+// StartPhoneVerification registers a number for the account and texts it a one-time code.
+// The code counts against the SMS quota.
+func (s *TelephonyService) StartPhoneVerification(ctx context.Context, accountID uuid.UUID, number string) (*models.PhoneNumber, error) {
+	if !s.IsEnabled() {
+		return nil, ErrTelephonyNotConfigured
+	}
+
+	normalized, ok := models.NormalizePhoneNumber(number)
+	if !ok {
+		return nil, ErrInvalidPhoneNumber
+	}
+
+	phoneNumber, err := s.phoneNumberRepo.GetByAccountAndNumber(accountID, normalized)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if phoneNumber != nil {
+		if phoneNumber.IsVerified() {
+			return nil, ErrPhoneNumberAlreadyVerified
+		}
+		if phoneNumber.CodeSentAt != nil && now.Sub(*phoneNumber.CodeSentAt) < PhoneVerificationCooldown {
+			return nil, ErrPhoneVerificationCooldown
+		}
+	}
+
+	code, err := generatePhoneVerificationCode()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.SendSMS(ctx, accountID, normalized, fmt.Sprintf("Your Chronos verification code is %s. It expires in %d minutes.", code, int(PhoneVerificationTTL.Minutes()))); err != nil {
+		return nil, err
+	}
+
+	codeHash := hashPhoneVerificationCode(code)
+	expiresAt := now.Add(PhoneVerificationTTL)
+	isNew := phoneNumber == nil
+	if isNew {
+		phoneNumber = &models.PhoneNumber{AccountID: accountID, Number: normalized}
+	}
+	phoneNumber.CodeHash = &codeHash
+	phoneNumber.CodeExpiresAt = &expiresAt
+	phoneNumber.CodeSentAt = &now
+	phoneNumber.Attempts = 0
+
+	if isNew {
+		err = s.phoneNumberRepo.Create(phoneNumber)
+	} else {
+		err = s.phoneNumberRepo.Update(phoneNumber)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return phoneNumber, nil
+}
+
+// ConfirmPhoneVerification checks the code sent to the number and marks it as verified
+func (s *TelephonyService) ConfirmPhoneVerification(ctx context.Context, accountID uuid.UUID, number string, code string) (*models.PhoneNumber, error) {
+	normalized, ok := models.NormalizePhoneNumber(number)
+	if !ok {
+		return nil, ErrInvalidPhoneNumber
+	}
+
+	phoneNumber, err := s.phoneNumberRepo.GetByAccountAndNumber(accountID, normalized)
+	if err != nil {
+		return nil, err
+	}
+	if phoneNumber == nil {
+		return nil, ErrPhoneNumberNotFound
+	}
+	if phoneNumber.IsVerified() {
+		return phoneNumber, nil
+	}
+	if phoneNumber.CodeHash == nil || phoneNumber.CodeExpiresAt == nil || time.Now().After(*phoneNumber.CodeExpiresAt) {
+		return nil, ErrInvalidPhoneVerificationCode
+	}
+	if phoneNumber.Attempts >= MaxPhoneVerificationAttempts {
+		return nil, ErrTooManyPhoneVerificationAttempts
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashPhoneVerificationCode(code)), []byte(*phoneNumber.CodeHash)) != 1 {
+		phoneNumber.Attempts++
+		if err := s.phoneNumberRepo.Update(phoneNumber); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidPhoneVerificationCode
+	}
+
+	verifiedAt := time.Now().UTC()
+	phoneNumber.VerifiedAt = &verifiedAt
+	phoneNumber.CodeHash = nil
+	phoneNumber.CodeExpiresAt = nil
+	phoneNumber.Attempts = 0
+	if err := s.phoneNumberRepo.Update(phoneNumber); err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).InfoContext(ctx, "Phone number verified", "account_id", accountID, "phone_number_id", phoneNumber.ID)
+	return phoneNumber, nil
+}
+
+// PhoneNumbers returns the phone numbers registered by the account
+func (s *TelephonyService) PhoneNumbers(accountID uuid.UUID) ([]models.PhoneNumber, error) {
+	return s.phoneNumberRepo.GetByAccountID(accountID)
+}
+
+// RemovePhoneNumber deletes a registered phone number, its destinations stop receiving reminders
+func (s *TelephonyService) RemovePhoneNumber(accountID uuid.UUID, number string) error {
+	normalized, ok := models.NormalizePhoneNumber(number)
+	if !ok {
+		return ErrPhoneNumberNotFound
+	}
+
+	phoneNumber, err := s.phoneNumberRepo.GetByAccountAndNumber(accountID, normalized)
+	if err != nil {
+		return err
+	}
+	if phoneNumber == nil {
+		return ErrPhoneNumberNotFound
+	}
+	return s.phoneNumberRepo.Delete(phoneNumber.ID)
+}
+
+// IsPhoneNumberVerified reports whether the account verified the number
+func (s *TelephonyService) IsPhoneNumberVerified(accountID uuid.UUID, number string) (bool, error) {
+	phoneNumber, err := s.phoneNumberRepo.GetByAccountAndNumber(accountID, number)
+	if err != nil {
+		return false, err
+	}
+	return phoneNumber != nil && phoneNumber.IsVerified(), nil
+}
+
+// Usage returns the SMS and voice calls sent by the account this month
+func (s *TelephonyService) Usage(accountID uuid.UUID) (*TelephonyUsageReport, error) {
+	month := models.TelephonyUsageMonth(time.Now())
+	report := &TelephonyUsageReport{Month: month, SMSQuota: s.quota.SMS, VoiceQuota: s.quota.Voice}
+
+	usage, err := s.usageRepo.GetByAccountAndMonth(accountID, month)
+	if err != nil {
+		return nil, err
+	}
+	if usage != nil {
+		report.SMSUsed = usage.SMSCount
+		report.VoiceUsed = usage.VoiceCount
+	}
+	return report, nil
+}
+
+// SendSMS texts a number on behalf of the account, within its monthly SMS quota
+func (s *TelephonyService) SendSMS(ctx context.Context, accountID uuid.UUID, to string, body string) (string, error) {
+	if runes := []rune(body); len(runes) > MaxSMSLength {
+		body = string(runes[:MaxSMSLength-1]) + "…"
+	}
+	return s.send(ctx, accountID, models.DestinationSMS, s.quota.SMS, func(ctx context.Context) (string, error) {
+		return s.provider.SendSMS(ctx, to, body)
+	})
+}
+
+// Call places a voice call on behalf of the account, within its monthly voice quota
+func (s *TelephonyService) Call(ctx context.Context, accountID uuid.UUID, to string, message string) (string, error) {
+	return s.send(ctx, accountID, models.DestinationVoice, s.quota.Voice, func(ctx context.Context) (string, error) {
+		return s.provider.Call(ctx, to, message)
+	})
+}
+
+// send reserves one unit of the quota and gives it back when the provider fails
+func (s *TelephonyService) send(ctx context.Context, accountID uuid.UUID, channel models.DestinationType, limit int, deliver func(ctx context.Context) (string, error)) (string, error) {
+	if !s.IsEnabled() {
+		return "", ErrTelephonyNotConfigured
+	}
+
+	month := models.TelephonyUsageMonth(time.Now())
+	reserved, err := s.usageRepo.Reserve(accountID, month, channel, limit)
+	if err != nil {
+		return "", err
+	}
+	if !reserved {
+		return "", fmt.Errorf("%s %w (%d per month)", channel, ErrTelephonyQuotaExceeded, limit)
+	}
+
+	id, err := deliver(ctx)
+	if err != nil {
+		if releaseErr := s.usageRepo.Release(accountID, month, channel); releaseErr != nil {
+			logging.FromContext(ctx).ErrorContext(ctx, "Failed to release telephony usage", "account_id", accountID, "channel", channel, "month", month, "error", releaseErr)
+		}
+		return "", err
+	}
+	return id, nil
+}
+
+// generatePhoneVerificationCode generates a 6-digit verification code
+func generatePhoneVerificationCode() (string, error) {
+	num, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate verification code: %w", err)
+	}
+	return fmt.Sprintf("%06d", num.Int64()), nil
+}
+
+// hashPhoneVerificationCode returns the SHA-256 hex digest stored instead of the code
+func hashPhoneVerificationCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TwilioDefaultAPIBaseURL is the official Twilio REST API endpoint
+const TwilioDefaultAPIBaseURL = "https://api.twilio.com"
+
+// TelephonyProvider sends SMS and places voice calls
+type TelephonyProvider interface {
+	// SendSMS sends a text message and returns the provider's message ID
+	SendSMS(ctx context.Context, to string, body string) (string, error)
+	// Call places a voice call reading message aloud and returns the provider's call ID
+	Call(ctx context.Context, to string, message string) (string, error)
+	IsEnabled() bool
+}
+
+// TelephonyError is a failure answered by the telephony provider
+type TelephonyError struct {
+	StatusCode int
+	Code       int // provider error code, 0 when unknown
+	Message    string
+	RetryAfter time.Duration // set on 429 responses carrying a Retry-After header
+}
+
+func (e *TelephonyError) Error() string {
+	if e.Code != 0 {
+		return fmt.Sprintf("telephony provider returned status %d (code %d): %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("telephony provider returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// TwilioProvider implements TelephonyProvider with the Twilio REST API. Any service
+// exposing the same API can be used by changing the base URL, e.g. a local mock.
+type TwilioProvider struct {
+	baseURL    string
+	accountSID string
+	authToken  string
+	fromNumber string
+	httpClient *http.Client
+}
+
+// NewTwilioProvider creates a Twilio-compatible provider, the official endpoint is used when baseURL is empty
+func NewTwilioProvider(baseURL, accountSID, authToken, fromNumber string) *TwilioProvider {
+	if baseURL == "" {
+		baseURL = TwilioDefaultAPIBaseURL
+	}
+
+	return &TwilioProvider{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		accountSID: accountSID,
+		authToken:  authToken,
+		fromNumber: fromNumber,
+		httpClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}
+}
+
+// IsEnabled reports whether the credentials and the sender number are configured
+func (p *TwilioProvider) IsEnabled() bool {
+	return p != nil && p.accountSID != "" && p.authToken != "" && p.fromNumber != ""
+}
+
+// SendSMS sends a text message through the Messages resource
+func (p *TwilioProvider) SendSMS(ctx context.Context, to string, body string) (string, error) {
+	return p.create(ctx, "Messages.json", url.Values{
+		"To":   {to},
+		"From": {p.fromNumber},
+		"Body": {body},
+	})
+}
+
+// Call places a call through the Calls resource, the message is read twice
+func (p *TwilioProvider) Call(ctx context.Context, to string, message string) (string, error) {
+	twiml := fmt.Sprintf(`<Response><Say loop="2">%s</Say></Response>`, html.EscapeString(message))
+	return p.create(ctx, "Calls.json", url.Values{
+		"To":    {to},
+		"From":  {p.fromNumber},
+		"Twiml": {twiml},
+	})
+}
+
+// create posts a form to an account resource and returns the SID of the created item
+func (p *TwilioProvider) create(ctx context.Context, resource string, form url.Values) (string, error) {
+	if !p.IsEnabled() {
+		return "", ErrTelephonyNotConfigured
+	}
+
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/%s", p.baseURL, url.PathEscape(p.accountSID), resource)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create telephony request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", "Chronos-Reminder/1.0")
+	req.SetBasicAuth(p.accountSID, p.authToken)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("telephony request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var decoded struct {
+		SID     string `json:"sid"`
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	decodeErr := json.NewDecoder(resp.Body).Decode(&decoded)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		telephonyErr := &TelephonyError{StatusCode: resp.StatusCode, Code: decoded.Code, Message: decoded.Message}
+		if telephonyErr.Message == "" {
+			telephonyErr.Message = http.StatusText(resp.StatusCode)
+		}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			telephonyErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return "", telephonyErr
+	}
+	if decodeErr != nil {
+		return "", fmt.Errorf("failed to decode telephony response: %w", decodeErr)
+	}
+
+	return decoded.SID, nil
+}
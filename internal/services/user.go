@@ -7,6 +7,7 @@ import (
 	"github.com/ericp/chronos-bot-reminder/internal/config"
 	"github.com/ericp/chronos-bot-reminder/internal/database"
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 	"gorm.io/gorm"
 )
 
@@ -41,6 +42,41 @@ func ChangeAccountTimezone(account *models.Account, timezoneID uint) error {
 	return nil
 }
 
+// ChangeAccountLocale changes the language of an account, given as a locale or a language tag
+func ChangeAccountLocale(account *models.Account, locale string) error {
+	normalized := i18n.Normalize(locale)
+	if normalized == "" {
+		return fmt.Errorf("unsupported locale: %s", locale)
+	}
+
+	repo := database.GetRepositories()
+	if err := repo.Account.UpdateLocale(account.ID, normalized); err != nil {
+		return err
+	}
+
+	// Update the in-memory account object
+	account.Locale = normalized
+
+	// Invalidate cache after account changes
+	if err := InvalidateAccountCache(account); err != nil {
+		// Log warning but don't fail the operation
+		fmt.Printf("[CACHE] Warning: Failed to invalidate cache after locale change: %v\n", err)
+	}
+
+	return nil
+}
+
+// SyncAccountLocale stores the locale of the Discord client on accounts that have no
+// language yet, so reminders and emails sent outside an interaction use it too
+func SyncAccountLocale(account *models.Account, discordLocale discordgo.Locale) {
+	if account == nil || account.Locale != "" || i18n.Normalize(string(discordLocale)) == "" {
+		return
+	}
+	if err := ChangeAccountLocale(account, string(discordLocale)); err != nil {
+		fmt.Printf("[USER] Warning: Failed to store the Discord locale of account %s: %v\n", account.ID, err)
+	}
+}
+
 func GetAccountFromDiscordUser(discordUser *discordgo.User) (*models.Account, error) {
 	// Try to get from cache first
 	cachedAccount, err := GetCachedAccountByDiscordID(discordUser.ID)
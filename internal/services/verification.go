@@ -9,6 +9,7 @@ import (
 
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
 	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
 	"github.com/google/uuid"
 )
 
@@ -48,7 +49,7 @@ func (v *VerificationService) SendAccountVerification(email string, accountID st
 		return err
 	}
 	verificationLink := v.webAppURL + "/verify?email=" + email + "&code=" + code
-	if _, err := v.SendVerificationEmail(email, code, verificationLink); err != nil {
+	if _, err := v.SendVerificationEmail(email, code, verificationLink, v.accountLocale(accountID)); err != nil {
 		return err
 	}
 	return nil
@@ -77,6 +78,19 @@ func (v *VerificationService) ResendVerification(email string) (bool, error) {
 	return true, nil
 }
 
+// accountLocale returns the language of the account the email is sent to
+func (v *VerificationService) accountLocale(accountID string) string {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return i18n.Default
+	}
+	account, err := v.accountRepo.GetByID(id)
+	if err != nil || account == nil {
+		return i18n.Default
+	}
+	return i18n.Resolve(account.Locale)
+}
+
 // resolveAccountID finds the account owning an email, via the account-level
 // email first and then any prior verification record (covers accounts created
 // via Discord before credentials were set).
@@ -106,49 +120,66 @@ func (v *VerificationService) GenerateVerificationCode() (string, error) {
 }
 
 // SendVerificationEmail sends a verification code to the user's email
-func (v *VerificationService) SendVerificationEmail(email string, verificationCode string, verificationLink string) (string, error) {
-	subject := "Verify your Chronos Reminder account"
+func (v *VerificationService) SendVerificationEmail(email string, verificationCode string, verificationLink string, locale string) (string, error) {
+	subject := i18n.T(locale, "email.verification.subject")
 	htmlBody := fmt.Sprintf(`
 <!DOCTYPE html>
-<html>
+<html lang="%s">
 <head>
 	<meta charset="UTF-8">
-	<title>Verify Your Email</title>
+	<title>%s</title>
 </head>
 <body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
 	<div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-		<h1 style="color: #4CAF50;">Verify Your Email ✉️</h1>
-		<p>Welcome to Chronos Reminder!</p>
-		<p>Please verify your email address by clicking the button below:</p>
+		<h1 style="color: #4CAF50;">%s ✉️</h1>
+		<p>%s</p>
+		<p>%s</p>
 		
 		<p style="margin: 30px 0;">
 			<a href="%s" style="background-color: #4CAF50; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; display: inline-block; font-size: 16px; font-weight: bold;">
-				Verify Email
+				%s
 			</a>
 		</p>
 		
-		<p style="color: #666; margin: 20px 0;">Or use this code: <strong style="font-size: 18px; letter-spacing: 2px;">%s</strong></p>
+		<p style="color: #666; margin: 20px 0;">%s</p>
 		
 		<p style="color: #999; font-size: 12px; margin-top: 30px;">
-			This link will expire in 24 hours. If you didn't create this account, please ignore this email.
+			%s
 		</p>
 	</div>
 </body>
 </html>
-	`, verificationLink, verificationCode)
+	`,
+		i18n.Resolve(locale),
+		i18n.T(locale, "email.verification.title"),
+		i18n.T(locale, "email.verification.title"),
+		i18n.T(locale, "email.verification.welcome"),
+		i18n.T(locale, "email.verification.intro_button"),
+		verificationLink,
+		i18n.T(locale, "email.verification.button"),
+		i18n.T(locale, "email.verification.code", `<strong style="font-size: 18px; letter-spacing: 2px;">`+verificationCode+`</strong>`),
+		i18n.T(locale, "email.verification.expiry"),
+	)
 
 	textBody := fmt.Sprintf(`
-Verify Your Email
+%s
 
-Welcome to Chronos Reminder!
+%s
 
-Please verify your email address by visiting this link:
+%s
 %s
 
-Or use this verification code: %s
+%s
 
-This link will expire in 24 hours. If you didn't create this account, please ignore this email.
-	`, verificationLink, verificationCode)
+%s
+	`,
+		i18n.T(locale, "email.verification.title"),
+		i18n.T(locale, "email.verification.welcome"),
+		i18n.T(locale, "email.verification.intro_link"),
+		verificationLink,
+		i18n.T(locale, "email.verification.code", verificationCode),
+		i18n.T(locale, "email.verification.expiry"),
+	)
 
 	return v.mailerService.SendEmail(&EmailRequest{
 		To:       email,
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"html"
+	"strings"
 	"time"
 
 	"github.com/ericp/chronos-bot-reminder/internal/database/models"
@@ -23,21 +25,26 @@ func NewWebhookFormatter() *WebhookFormatter {
 	return &WebhookFormatter{}
 }
 
-// FormatPayload formats a reminder message for the specified webhook platform
+// FormatPayload formats a reminder message for the specified webhook platform, or with
+// the template of the destination when it has one
 func (f *WebhookFormatter) FormatPayload(reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) ([]byte, error) {
-	// Get platform from metadata, default to generic
-	platform := models.WebhookPlatformGeneric
-	if platformVal, exists := destination.Metadata["platform"]; exists {
-		if platformStr, ok := platformVal.(string); ok {
-			platform = models.WebhookPlatform(platformStr)
-		}
+	if templateStr := webhookTemplate(destination); templateStr != "" {
+		return f.formatTemplateWebhook(templateStr, reminder, account)
 	}
 
-	switch platform {
+	switch webhookPlatform(destination) {
 	case models.WebhookPlatformDiscord:
 		return f.formatDiscordWebhook(reminder, destination, account)
 	case models.WebhookPlatformSlack:
 		return f.formatSlackWebhook(reminder, destination, account)
+	case models.WebhookPlatformTeams:
+		return f.formatTeamsWebhook(reminder, destination, account)
+	case models.WebhookPlatformMattermost:
+		return f.formatMattermostWebhook(reminder, destination, account)
+	case models.WebhookPlatformGoogleChat:
+		return f.formatGoogleChatWebhook(reminder, destination, account)
+	case models.WebhookPlatformNtfy:
+		return f.formatNtfyWebhook(reminder, destination, account)
 	case models.WebhookPlatformGeneric:
 		return f.formatGenericWebhook(reminder, destination, account)
 	default:
@@ -45,6 +52,26 @@ func (f *WebhookFormatter) FormatPayload(reminder *models.Reminder, destination
 	}
 }
 
+// webhookPlatform returns the platform of a webhook destination, generic by default
+func webhookPlatform(destination *models.ReminderDestination) models.WebhookPlatform {
+	if platformVal, exists := destination.Metadata["platform"]; exists {
+		if platformStr, ok := platformVal.(string); ok && platformStr != "" {
+			return models.WebhookPlatform(platformStr)
+		}
+	}
+	return models.WebhookPlatformGeneric
+}
+
+// webhookTemplate returns the payload template of a webhook destination, if any
+func webhookTemplate(destination *models.ReminderDestination) string {
+	if templateVal, exists := destination.Metadata["template"]; exists {
+		if templateStr, ok := templateVal.(string); ok {
+			return templateStr
+		}
+	}
+	return ""
+}
+
 // formatDiscordWebhook formats a reminder for Discord webhook
 func (f *WebhookFormatter) formatDiscordWebhook(reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) ([]byte, error) {
 	// Build Discord embed
@@ -166,6 +193,159 @@ func (f *WebhookFormatter) formatSlackWebhook(reminder *models.Reminder, destina
 	return json.Marshal(payload)
 }
 
+// formatTeamsWebhook formats a reminder as a Microsoft Teams Adaptive Card message
+func (f *WebhookFormatter) formatTeamsWebhook(reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) ([]byte, error) {
+	facts := []map[string]interface{}{
+		{
+			"title": "Scheduled Time",
+			"value": reminder.RemindAtUTC.Format("Monday, January 2, 2006 at 15:04 MST"),
+		},
+	}
+
+	if reminder.SnoozedAtUTC != nil {
+		facts = append(facts, map[string]interface{}{
+			"title": "Snoozed Until",
+			"value": reminder.SnoozedAtUTC.Format("Monday, January 2, 2006 at 15:04 MST"),
+		})
+	}
+
+	card := map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body": []map[string]interface{}{
+			{
+				"type":   "TextBlock",
+				"text":   "⏰ Reminder",
+				"weight": "Bolder",
+				"size":   "Medium",
+			},
+			{
+				"type": "TextBlock",
+				"text": reminder.Message,
+				"wrap": true,
+			},
+			{
+				"type":  "FactSet",
+				"facts": facts,
+			},
+		},
+	}
+
+	payload := map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"contentUrl":  nil,
+				"content":     card,
+			},
+		},
+	}
+
+	return json.Marshal(payload)
+}
+
+// formatMattermostWebhook formats a reminder as a Mattermost message attachment
+func (f *WebhookFormatter) formatMattermostWebhook(reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) ([]byte, error) {
+	fields := []map[string]interface{}{
+		{
+			"short": false,
+			"title": "Scheduled Time",
+			"value": reminder.RemindAtUTC.Format("Monday, January 2, 2006 at 15:04 MST"),
+		},
+	}
+
+	if reminder.SnoozedAtUTC != nil {
+		fields = append(fields, map[string]interface{}{
+			"short": false,
+			"title": "Snoozed Until",
+			"value": reminder.SnoozedAtUTC.Format("Monday, January 2, 2006 at 15:04 MST"),
+		})
+	}
+
+	payload := map[string]interface{}{
+		"attachments": []map[string]interface{}{
+			{
+				"fallback": fmt.Sprintf("⏰ Reminder: %s", reminder.Message),
+				"color":    "#3498DB",
+				"title":    "⏰ Reminder",
+				"text":     reminder.Message,
+				"fields":   fields,
+				"footer":   "Chronos Reminder",
+			},
+		},
+	}
+
+	// Add optional channel, username and icon overrides
+	for _, key := range []string{"channel", "username", "icon_url", "icon_emoji"} {
+		if value, exists := destination.Metadata[key]; exists {
+			if valueStr, ok := value.(string); ok && valueStr != "" {
+				payload[key] = valueStr
+			}
+		}
+	}
+
+	return json.Marshal(payload)
+}
+
+// formatGoogleChatWebhook formats a reminder as a Google Chat cardsV2 message
+func (f *WebhookFormatter) formatGoogleChatWebhook(reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) ([]byte, error) {
+	widgets := []map[string]interface{}{
+		{
+			"textParagraph": map[string]interface{}{
+				// Card text accepts a subset of HTML
+				"text": html.EscapeString(reminder.Message),
+			},
+		},
+		{
+			"decoratedText": map[string]interface{}{
+				"topLabel": "Scheduled Time",
+				"text":     reminder.RemindAtUTC.Format("Monday, January 2, 2006 at 15:04 MST"),
+			},
+		},
+	}
+
+	if reminder.SnoozedAtUTC != nil {
+		widgets = append(widgets, map[string]interface{}{
+			"decoratedText": map[string]interface{}{
+				"topLabel": "Snoozed Until",
+				"text":     reminder.SnoozedAtUTC.Format("Monday, January 2, 2006 at 15:04 MST"),
+			},
+		})
+	}
+
+	payload := map[string]interface{}{
+		"text": fmt.Sprintf("⏰ Reminder: %s", reminder.Message),
+		"cardsV2": []map[string]interface{}{
+			{
+				"cardId": "reminder-" + reminder.ID.String(),
+				"card": map[string]interface{}{
+					"header": map[string]interface{}{
+						"title":    "⏰ Reminder",
+						"subtitle": "Chronos Reminder",
+					},
+					"sections": []map[string]interface{}{
+						{"widgets": widgets},
+					},
+				},
+			},
+		},
+	}
+
+	return json.Marshal(payload)
+}
+
+// formatNtfyWebhook formats a reminder for ntfy: the message is the plain text body, the
+// title, priority and tags travel as headers (see GetHeaders)
+func (f *WebhookFormatter) formatNtfyWebhook(reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) ([]byte, error) {
+	message := reminder.Message
+	if reminder.SnoozedAtUTC != nil {
+		message += fmt.Sprintf("\n\nSnoozed until %s", reminder.SnoozedAtUTC.Format("Monday, January 2, 2006 at 15:04 MST"))
+	}
+	return []byte(message), nil
+}
+
 // formatGenericWebhook formats a reminder for generic webhook
 func (f *WebhookFormatter) formatGenericWebhook(reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) ([]byte, error) {
 	// Simple JSON payload with all reminder information
@@ -201,25 +381,126 @@ func (f *WebhookFormatter) formatGenericWebhook(reminder *models.Reminder, desti
 	return json.Marshal(payload)
 }
 
+// formatTemplateWebhook renders a reminder with a user-defined template
+func (f *WebhookFormatter) formatTemplateWebhook(text string, reminder *models.Reminder, account *models.Account) ([]byte, error) {
+	tmpl, err := models.ParseWebhookTemplate(text)
+	if err != nil {
+		return nil, err
+	}
+	return models.RenderWebhookTemplate(tmpl, NewWebhookTemplateData(reminder, account, time.Now()))
+}
+
+// NewWebhookTemplateData builds the data a webhook template is rendered with, using
+// the timezone of the account (UTC when it has none)
+func NewWebhookTemplateData(reminder *models.Reminder, account *models.Account, firedAt time.Time) models.WebhookTemplateData {
+	loc := time.UTC
+	if account != nil && account.Timezone != nil {
+		if accountLoc, err := time.LoadLocation(account.Timezone.IANALocation); err == nil {
+			loc = accountLoc
+		}
+	}
+
+	inLocation := func(t *time.Time) *time.Time {
+		if t == nil {
+			return nil
+		}
+		local := t.In(loc)
+		return &local
+	}
+
+	data := models.WebhookTemplateData{
+		ReminderID:   reminder.ID.String(),
+		Message:      reminder.Message,
+		RemindAtUTC:  reminder.RemindAtUTC.UTC(),
+		RemindAt:     reminder.RemindAtUTC.In(loc),
+		Timezone:     loc.String(),
+		Recurrence:   GetRecurrenceTypeName(GetRecurrenceType(int(reminder.Recurrence))),
+		IsPaused:     IsPaused(int(reminder.Recurrence)),
+		IsSnoozed:    reminder.SnoozedAtUTC != nil,
+		SnoozedUntil: inLocation(reminder.SnoozedAtUTC),
+		NextFire:     inLocation(reminder.NextFireUTC),
+		FiredAt:      firedAt.In(loc),
+	}
+	if reminder.RRule != nil {
+		data.RRule = *reminder.RRule
+	}
+	if account != nil {
+		data.AccountID = account.ID.String()
+	}
+
+	return data
+}
+
 // GetContentType returns the appropriate Content-Type header for the webhook platform
 func (f *WebhookFormatter) GetContentType(destination *models.ReminderDestination) string {
-	// All platforms use JSON
+	// ntfy takes the message as plain text, templates and every other platform use JSON
+	if webhookPlatform(destination) == models.WebhookPlatformNtfy && webhookTemplate(destination) == "" {
+		return "text/plain; charset=utf-8"
+	}
 	return "application/json"
 }
 
-// ValidatePayload validates that a payload can be sent successfully
-func (f *WebhookFormatter) ValidatePayload(payload []byte) error {
-	// Basic validation: ensure it's valid JSON
-	var jsonData map[string]interface{}
-	if err := json.Unmarshal(payload, &jsonData); err != nil {
-		return fmt.Errorf("invalid JSON payload: %w", err)
+// GetHeaders returns the headers a webhook platform expects besides the payload
+func (f *WebhookFormatter) GetHeaders(destination *models.ReminderDestination) map[string]string {
+	if webhookPlatform(destination) != models.WebhookPlatformNtfy {
+		return nil
+	}
+
+	headers := map[string]string{
+		"Title": "Chronos Reminder",
+		"Tags":  "alarm_clock",
+	}
+
+	if title, ok := destination.Metadata["title"].(string); ok && title != "" {
+		headers["Title"] = headerValue(title)
+	}
+	if priority, exists := destination.Metadata["priority"]; exists {
+		headers["Priority"] = strings.ToLower(fmt.Sprint(priority))
 	}
 
+	switch tags := destination.Metadata["tags"].(type) {
+	case string:
+		if tags != "" {
+			headers["Tags"] = headerValue(tags)
+		}
+	case []interface{}:
+		var values []string
+		for _, tag := range tags {
+			if tagStr, ok := tag.(string); ok && tagStr != "" {
+				values = append(values, headerValue(tagStr))
+			}
+		}
+		if len(values) > 0 {
+			headers["Tags"] = strings.Join(values, ",")
+		}
+	}
+
+	return headers
+}
+
+// headerValue keeps a user value on a single header line
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
+
+// ValidatePayload validates that a payload can be sent successfully
+func (f *WebhookFormatter) ValidatePayload(destination *models.ReminderDestination, payload []byte) error {
 	// Check if payload is not empty
 	if len(payload) == 0 {
 		return fmt.Errorf("empty payload")
 	}
 
+	// Plain text payloads have nothing else to check
+	if f.GetContentType(destination) != "application/json" {
+		return nil
+	}
+
+	// Basic validation: ensure it's valid JSON
+	var jsonData map[string]interface{}
+	if err := json.Unmarshal(payload, &jsonData); err != nil {
+		return fmt.Errorf("invalid JSON payload: %w", err)
+	}
+
 	return nil
 }
 
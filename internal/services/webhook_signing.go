@@ -0,0 +1,200 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/ericp/chronos-bot-reminder/internal/config"
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+)
+
+const (
+	// WebhookSecretPrefix marks the signing secrets handed to users
+	WebhookSecretPrefix = "whsec_"
+	// WebhookSecretMetadataKey holds the encrypted signing secret in the destination metadata
+	WebhookSecretMetadataKey = "signing_secret"
+	// encryptedSecretPrefix versions the encryption of stored secrets
+	encryptedSecretPrefix = "enc:v1:"
+)
+
+// ErrWebhookSecretUnavailable is returned when a destination has no usable signing secret
+var ErrWebhookSecretUnavailable = errors.New("webhook signing secret unavailable")
+
+var (
+	webhookSecretKey      []byte
+	webhookSecretKeyMutex sync.RWMutex
+)
+
+// webhookEncryptionKey returns the AES-256 key protecting the signing secrets, derived
+// from WEBHOOK_SECRET_KEY or, when unset, from JWT_SECRET. It is read once and cached.
+func webhookEncryptionKey() []byte {
+	webhookSecretKeyMutex.RLock()
+	cached := webhookSecretKey
+	webhookSecretKeyMutex.RUnlock()
+	if cached != nil {
+		return cached
+	}
+
+	cfg := config.Load()
+	key := cfg.WebhookSecretKey
+	if key == "" {
+		log.Printf("[WEBHOOK] - ⚠️ WEBHOOK_SECRET_KEY is not set, deriving the signing secrets key from JWT_SECRET")
+		key = cfg.JWTSecret
+	}
+
+	SetWebhookSecretKey(key)
+	return webhookEncryptionKey()
+}
+
+// SetWebhookSecretKey replaces the key the signing secrets are encrypted with. Secrets
+// stored with the previous key can no longer be read and must be rotated.
+func SetWebhookSecretKey(key string) {
+	sum := sha256.Sum256([]byte(key))
+
+	webhookSecretKeyMutex.Lock()
+	defer webhookSecretKeyMutex.Unlock()
+	webhookSecretKey = sum[:]
+}
+
+// GenerateWebhookSecret creates a new signing secret
+func GenerateWebhookSecret() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return WebhookSecretPrefix + base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// EncryptWebhookSecret encrypts a signing secret with AES-GCM for storage
+func EncryptWebhookSecret(secret string) (string, error) {
+	gcm, err := webhookCipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), nil)
+	return encryptedSecretPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptWebhookSecret reads a signing secret stored by EncryptWebhookSecret
+func DecryptWebhookSecret(stored string) (string, error) {
+	encoded, found := strings.CutPrefix(stored, encryptedSecretPrefix)
+	if !found {
+		return "", fmt.Errorf("unknown signing secret format")
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("invalid signing secret encoding: %w", err)
+	}
+
+	gcm, err := webhookCipher()
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("signing secret too short")
+	}
+
+	secret, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt signing secret: %w", err)
+	}
+	return string(secret), nil
+}
+
+func webhookCipher() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(webhookEncryptionKey())
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// WebhookSigningSecret returns the decrypted signing secret of a webhook destination,
+// ErrWebhookSecretUnavailable when it has none (destinations created before signing)
+func WebhookSigningSecret(destination *models.ReminderDestination) (string, error) {
+	stored, ok := destination.Metadata[WebhookSecretMetadataKey].(string)
+	if !ok || stored == "" {
+		return "", ErrWebhookSecretUnavailable
+	}
+	return DecryptWebhookSecret(stored)
+}
+
+// PrepareWebhookSigning gives a new webhook destination its signing secret. A secret
+// sent by the client is discarded; the one of a previous destination posting to the same
+// URL is kept, so editing a reminder does not break its receivers. It returns the new
+// plain secret, or an empty string when a previous one was kept or the destination is
+// not a webhook.
+func PrepareWebhookSigning(destination *models.ReminderDestination, previous []models.ReminderDestination) (string, error) {
+	if destination.Type != models.DestinationWebhook {
+		return "", nil
+	}
+	if destination.Metadata == nil {
+		destination.Metadata = models.JSONB{}
+	}
+	delete(destination.Metadata, WebhookSecretMetadataKey)
+
+	url, _ := destination.Metadata["url"].(string)
+	for _, prev := range previous {
+		if prev.Type != models.DestinationWebhook {
+			continue
+		}
+		prevURL, _ := prev.Metadata["url"].(string)
+		if stored, ok := prev.Metadata[WebhookSecretMetadataKey].(string); ok && stored != "" && prevURL == url {
+			destination.Metadata[WebhookSecretMetadataKey] = stored
+			return "", nil
+		}
+	}
+
+	return SetWebhookSigningSecret(destination)
+}
+
+// SetWebhookSigningSecret generates a new signing secret for a webhook destination,
+// replacing the current one, and returns it in plain form
+func SetWebhookSigningSecret(destination *models.ReminderDestination) (string, error) {
+	secret, err := GenerateWebhookSecret()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate signing secret: %w", err)
+	}
+
+	encrypted, err := EncryptWebhookSecret(secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt signing secret: %w", err)
+	}
+
+	if destination.Metadata == nil {
+		destination.Metadata = models.JSONB{}
+	}
+	destination.Metadata[WebhookSecretMetadataKey] = encrypted
+	return secret, nil
+}
+
+// RedactDestinationMetadata returns a copy of destination metadata without the signing
+// secret, for API responses and exports
+func RedactDestinationMetadata(metadata models.JSONB) models.JSONB {
+	if _, exists := metadata[WebhookSecretMetadataKey]; !exists {
+		return metadata
+	}
+
+	redacted := make(models.JSONB, len(metadata))
+	for key, value := range metadata {
+		if key != WebhookSecretMetadataKey {
+			redacted[key] = value
+		}
+	}
+	return redacted
+}
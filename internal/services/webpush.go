@@ -0,0 +1,307 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/ericp/chronos-bot-reminder/internal/tracing"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// webPushRecordSize is the aes128gcm record size, payloads always fit in a single record
+	webPushRecordSize = 4096
+	// MaxWebPushPayload is the largest payload push services must accept (RFC 8291)
+	MaxWebPushPayload = 3993
+	// webPushTTL is how long the push service keeps a notification for an offline browser
+	webPushTTL = 24 * time.Hour
+	// vapidTokenLifetime is the validity of the VAPID JWT, push services refuse more than 24h
+	vapidTokenLifetime = 12 * time.Hour
+)
+
+var (
+	// ErrWebPushSubscriptionGone indicates the browser unsubscribed, the subscription should be removed
+	ErrWebPushSubscriptionGone = errors.New("web push subscription expired or unsubscribed")
+	// ErrWebPushUnavailable indicates the push service could not take the message right now
+	ErrWebPushUnavailable = errors.New("web push service temporarily unavailable")
+	// ErrInvalidWebPushSubscription is returned for subscriptions with unusable keys or endpoint
+	ErrInvalidWebPushSubscription = errors.New("invalid web push subscription")
+)
+
+// WebPushKeys are the keys a browser returns with its push subscription, base64url encoded
+type WebPushKeys struct {
+	P256dh string `json:"p256dh"` // user agent public key, uncompressed P-256 point
+	Auth   string `json:"auth"`   // 16-byte authentication secret
+}
+
+// decodeWebPushKey decodes a base64url key, padded or not as browsers differ
+func decodeWebPushKey(value string) ([]byte, error) {
+	if decoded, err := base64.RawURLEncoding.DecodeString(value); err == nil {
+		return decoded, nil
+	}
+	return base64.URLEncoding.DecodeString(value)
+}
+
+// ValidateWebPushSubscription checks the endpoint is an https URL and the keys can encrypt a payload
+func ValidateWebPushSubscription(endpoint string, keys WebPushKeys) error {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return fmt.Errorf("%w: endpoint must be an https URL", ErrInvalidWebPushSubscription)
+	}
+
+	publicKey, err := decodeWebPushKey(keys.P256dh)
+	if err != nil {
+		return fmt.Errorf("%w: p256dh is not base64url", ErrInvalidWebPushSubscription)
+	}
+	if _, err := ecdh.P256().NewPublicKey(publicKey); err != nil {
+		return fmt.Errorf("%w: p256dh is not a P-256 public key", ErrInvalidWebPushSubscription)
+	}
+
+	auth, err := decodeWebPushKey(keys.Auth)
+	if err != nil || len(auth) != 16 {
+		return fmt.Errorf("%w: auth must be a 16-byte base64url secret", ErrInvalidWebPushSubscription)
+	}
+	return nil
+}
+
+// EncryptWebPushPayload encrypts a payload for a subscription with the aes128gcm content
+// coding (RFC 8188) and the Web Push key derivation (RFC 8291). The salt and the sender's
+// ephemeral key must be fresh for every message.
+func EncryptWebPushPayload(plaintext []byte, keys WebPushKeys, salt []byte, senderKey *ecdh.PrivateKey) ([]byte, error) {
+	if len(plaintext) > MaxWebPushPayload {
+		return nil, fmt.Errorf("web push payload is %d bytes, the limit is %d", len(plaintext), MaxWebPushPayload)
+	}
+	if len(salt) != 16 {
+		return nil, fmt.Errorf("web push salt must be 16 bytes")
+	}
+
+	userAgentKeyBytes, err := decodeWebPushKey(keys.P256dh)
+	if err != nil {
+		return nil, ErrInvalidWebPushSubscription
+	}
+	userAgentKey, err := ecdh.P256().NewPublicKey(userAgentKeyBytes)
+	if err != nil {
+		return nil, ErrInvalidWebPushSubscription
+	}
+	authSecret, err := decodeWebPushKey(keys.Auth)
+	if err != nil {
+		return nil, ErrInvalidWebPushSubscription
+	}
+
+	sharedSecret, err := senderKey.ECDH(userAgentKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute the web push shared secret: %w", err)
+	}
+
+	// Combine the shared secret with the authentication secret (RFC 8291 section 3.3)
+	senderPublicKey := senderKey.PublicKey().Bytes()
+	keyInfo := "WebPush: info\x00" + string(userAgentKeyBytes) + string(senderPublicKey)
+	ikm, err := hkdf.Key(sha256.New, sharedSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	// Derive the content encryption key and nonce (RFC 8188 section 2.2 and 2.3)
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+	contentKey, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Single record: the payload followed by the last record delimiter, without padding
+	record := append(append([]byte{}, plaintext...), 0x02)
+
+	var body bytes.Buffer
+	body.Write(salt)
+	binary.Write(&body, binary.BigEndian, uint32(webPushRecordSize))
+	body.WriteByte(byte(len(senderPublicKey)))
+	body.Write(senderPublicKey)
+	body.Write(gcm.Seal(nil, nonce, record, nil))
+	return body.Bytes(), nil
+}
+
+// GenerateVAPIDKeys creates a VAPID key pair, base64url encoded as expected in the configuration
+func GenerateVAPIDKeys() (publicKey string, privateKey string, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	publicBytes, err := key.PublicKey.Bytes()
+	if err != nil {
+		return "", "", err
+	}
+	privateBytes, err := key.Bytes()
+	if err != nil {
+		return "", "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(publicBytes), base64.RawURLEncoding.EncodeToString(privateBytes), nil
+}
+
+// WebPushService sends browser notifications with the Web Push protocol, authenticated
+// with VAPID. When no keys are configured the service is created in a disabled state.
+type WebPushService struct {
+	privateKey *ecdsa.PrivateKey
+	publicKey  string // base64url application server key handed to browsers
+	subject    string // contact of the sender for the push services, mailto: or https:
+	httpClient *http.Client
+	enabled    bool
+}
+
+// NewWebPushService loads the VAPID key pair. Missing or invalid keys yield a disabled
+// service rather than an error: web push is an optional delivery channel.
+func NewWebPushService(publicKey, privateKey, subject string) *WebPushService {
+	service := &WebPushService{
+		subject:    subject,
+		httpClient: &http.Client{Transport: tracing.Transport(nil), Timeout: 10 * time.Second},
+	}
+
+	if publicKey == "" || privateKey == "" {
+		logging.For(logging.App).Info("VAPID keys not set, browser notifications disabled")
+		return service
+	}
+
+	privateBytes, err := decodeWebPushKey(privateKey)
+	if err != nil {
+		logging.For(logging.App).Warn("Invalid VAPID private key, browser notifications disabled", "error", err)
+		return service
+	}
+	key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), privateBytes)
+	if err != nil {
+		logging.For(logging.App).Warn("Invalid VAPID private key, browser notifications disabled", "error", err)
+		return service
+	}
+
+	// The public key must be the one of the private key, browsers subscribed with it
+	publicBytes, err := key.PublicKey.Bytes()
+	if err != nil || base64.RawURLEncoding.EncodeToString(publicBytes) != trimBase64Padding(publicKey) {
+		logging.For(logging.App).Warn("VAPID public key does not match the private key, browser notifications disabled")
+		return service
+	}
+
+	service.privateKey = key
+	service.publicKey = base64.RawURLEncoding.EncodeToString(publicBytes)
+	service.enabled = true
+	logging.For(logging.App).Info("VAPID keys loaded, browser notifications enabled")
+	return service
+}
+
+// IsEnabled reports whether browser notifications are available
+func (s *WebPushService) IsEnabled() bool {
+	return s != nil && s.enabled
+}
+
+// PublicKey returns the application server key browsers subscribe with
+func (s *WebPushService) PublicKey() string {
+	return s.publicKey
+}
+
+// Send encrypts the payload for the subscription and posts it to its push service.
+// It returns ErrWebPushSubscriptionGone when the subscription no longer exists and
+// ErrWebPushUnavailable when the push service asks to retry later.
+func (s *WebPushService) Send(ctx context.Context, endpoint string, keys WebPushKeys, payload []byte) (string, error) {
+	if !s.IsEnabled() {
+		return "", fmt.Errorf("web push service is disabled")
+	}
+
+	salt := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", err
+	}
+	senderKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	body, err := EncryptWebPushPayload(payload, keys, salt, senderKey)
+	if err != nil {
+		return "", err
+	}
+
+	authorization, err := s.vapidAuthorization(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create web push request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", fmt.Sprint(int(webPushTTL.Seconds())))
+	req.Header.Set("Urgency", "high")
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrWebPushUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return "", ErrWebPushSubscriptionGone
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return "", fmt.Errorf("%w: push service returned status %d", ErrWebPushUnavailable, resp.StatusCode)
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return "", fmt.Errorf("push service returned status %d", resp.StatusCode)
+	}
+
+	// Push services answer 201 with the message URL in Location
+	return resp.Header.Get("Location"), nil
+}
+
+// vapidAuthorization returns the VAPID Authorization header for the push service of endpoint (RFC 8292)
+func (s *WebPushService) vapidAuthorization(endpoint string) (string, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidWebPushSubscription, err)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": parsed.Scheme + "://" + parsed.Host,
+		"exp": time.Now().Add(vapidTokenLifetime).Unix(),
+		"sub": s.subject,
+	})
+	signed, err := token.SignedString(s.privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign the VAPID token: %w", err)
+	}
+	return fmt.Sprintf("vapid t=%s, k=%s", signed, s.publicKey), nil
+}
+
+func trimBase64Padding(value string) string {
+	return string(bytes.TrimRight([]byte(value), "="))
+}
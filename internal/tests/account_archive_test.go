@@ -0,0 +1,198 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func archiveFixture() *services.AccountArchive {
+	email := "jane@example.com"
+	password := "bcrypt-hash"
+	calendarToken := "calendar-token-hash"
+	accessToken := "discord-access-token"
+	refreshToken := "discord-refresh-token"
+	rule := "FREQ=WEEKLY;BYDAY=MO,WE"
+	remindAt := time.Date(2030, time.June, 3, 7, 0, 0, 0, time.UTC)
+	reminderID := uuid.New()
+	destinationID := uuid.New()
+
+	account := &models.Account{
+		ID:                uuid.New(),
+		Email:             &email,
+		PasswordHash:      &password,
+		CalendarTokenHash: &calendarToken,
+		Timezone:          &models.Timezone{Name: "Paris", IANALocation: "Europe/Paris"},
+		Identities: []models.Identity{
+			{Provider: models.ProviderDiscord, ExternalID: "1234", AccessToken: &accessToken, RefreshToken: &refreshToken},
+		},
+	}
+	reminders := []models.Reminder{
+		{
+			ID:          reminderID,
+			RemindAtUTC: remindAt,
+			Message:     "=HYPERLINK(\"http://evil\")",
+			Recurrence:  services.RecurrenceRRule,
+			RRule:       &rule,
+			Destinations: []models.ReminderDestination{
+				{ID: destinationID, Type: models.DestinationWebhook, Metadata: models.JSONB{"url": "https://example.com/hook"}},
+				{ID: uuid.New(), Type: models.DestinationDiscordDM, Metadata: models.JSONB{"user_id": "1234"}},
+			},
+		},
+	}
+	reminderErrors := []models.ReminderError{
+		{ReminderID: reminderID, ReminderDestinationID: destinationID, Stacktrace: "timeout"},
+	}
+	note := &models.DFMNote{
+		Recurrence:    services.RecurrenceDaily,
+		SendDiscordDM: true,
+		Items:         []models.DFMItem{{Content: "Keys", Position: 0}, {Content: "Wallet", Checked: true, Position: 1}},
+	}
+
+	return services.NewAccountArchive(account, reminders, reminderErrors, note)
+}
+
+func TestAccountArchive(t *testing.T) {
+	archive := archiveFixture()
+
+	data, err := json.Marshal(archive)
+	require.NoError(t, err)
+	serialized := string(data)
+
+	for _, secret := range []string{"bcrypt-hash", "calendar-token-hash", "discord-access-token", "discord-refresh-token"} {
+		assert.NotContains(t, serialized, secret)
+	}
+
+	assert.Equal(t, services.AccountArchiveVersion, archive.Version)
+	assert.Equal(t, "Europe/Paris", archive.Timezone.IANALocation)
+	require.Len(t, archive.Identities, 1)
+	assert.Equal(t, "discord", archive.Identities[0].Provider)
+	require.Len(t, archive.Reminders, 1)
+	assert.Equal(t, "RRULE", archive.Reminders[0].RecurrenceType)
+	assert.Len(t, archive.Reminders[0].Destinations, 2)
+	assert.Len(t, archive.ReminderErrors, 1)
+	require.NotNil(t, archive.DFMNote)
+	assert.Len(t, archive.DFMNote.Items, 2)
+
+	// The archive reads back as it was written
+	parsed, err := services.ParseAccountArchive(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, archive.Reminders[0].ID, parsed.Reminders[0].ID)
+	assert.Equal(t, archive.Reminders[0].Destinations[0].Metadata["url"], parsed.Reminders[0].Destinations[0].Metadata["url"])
+}
+
+func TestParseAccountArchiveVersion(t *testing.T) {
+	_, err := services.ParseAccountArchive(strings.NewReader(`{"reminders": []}`))
+	assert.Error(t, err, "missing version")
+
+	_, err = services.ParseAccountArchive(strings.NewReader(`{"version": 99}`))
+	assert.Error(t, err, "newer version")
+
+	_, err = services.ParseAccountArchive(strings.NewReader(`not json`))
+	assert.Error(t, err)
+}
+
+func TestWriteRemindersCSV(t *testing.T) {
+	var buffer bytes.Buffer
+	require.NoError(t, services.WriteRemindersCSV(&buffer, archiveFixture()))
+
+	rows, err := csv.NewReader(&buffer).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	assert.Equal(t, "message", rows[0][1])
+	assert.Equal(t, `'=HYPERLINK("http://evil")`, rows[1][1], "formulas are neutralized")
+	assert.Equal(t, "2030-06-03T07:00:00Z", rows[1][2])
+	assert.Equal(t, "RRULE", rows[1][4])
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,WE", rows[1][5])
+	assert.Equal(t, "false", rows[1][6])
+	assert.Equal(t, "webhook;discord_dm", rows[1][7])
+}
+
+func TestRestoreArchiveReminder(t *testing.T) {
+	services.SetDefaultRecurrenceOptions(services.RecurrenceOptions{})
+	accountID := uuid.New()
+	future := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	past := time.Date(2021, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+	t.Run("Destinations are validated", func(t *testing.T) {
+		archived := &services.ArchiveReminder{
+			ID:          uuid.New(),
+			RemindAtUTC: future,
+			Message:     "Call",
+			Destinations: []services.ArchiveDestination{
+				{ID: uuid.New(), Type: "android_push", Metadata: models.JSONB{"account_id": "someone-else"}},
+				{ID: uuid.New(), Type: "webhook", Metadata: models.JSONB{}},
+				{ID: uuid.New(), Type: "carrier_pigeon"},
+			},
+		}
+
+		reminder, warnings, err := services.RestoreArchiveReminder(archived, accountID, "Europe/Paris")
+		require.NoError(t, err)
+		require.NotNil(t, reminder)
+		assert.Equal(t, accountID, reminder.AccountID)
+		assert.True(t, reminder.RemindAtUTC.Equal(future))
+		require.Len(t, reminder.Destinations, 1)
+		assert.Equal(t, accountID.String(), reminder.Destinations[0].Metadata["account_id"])
+		assert.Len(t, warnings, 2)
+	})
+
+	t.Run("Email is left out of hourly reminders", func(t *testing.T) {
+		archived := &services.ArchiveReminder{
+			RemindAtUTC:  future,
+			Message:      "Drink water",
+			Recurrence:   services.RecurrenceHourly,
+			Destinations: []services.ArchiveDestination{{Type: "email", Metadata: models.JSONB{"email": "jane@example.com"}}},
+		}
+		reminder, warnings, err := services.RestoreArchiveReminder(archived, accountID, "UTC")
+		require.NoError(t, err)
+		assert.Empty(t, reminder.Destinations)
+		assert.Len(t, warnings, 1)
+	})
+
+	t.Run("Past recurring reminder resumes", func(t *testing.T) {
+		archived := &services.ArchiveReminder{RemindAtUTC: past, Message: "Rent", Recurrence: services.RecurrenceMonthly}
+		reminder, _, err := services.RestoreArchiveReminder(archived, accountID, "UTC")
+		require.NoError(t, err)
+		assert.True(t, reminder.RemindAtUTC.After(time.Now()))
+		assert.Equal(t, 1, reminder.RemindAtUTC.Day())
+		assert.Equal(t, 8, reminder.RemindAtUTC.Hour())
+	})
+
+	t.Run("Paused reminder keeps its date", func(t *testing.T) {
+		state := int16(services.BuildRecurrenceState(services.RecurrenceDaily, true))
+		archived := &services.ArchiveReminder{RemindAtUTC: past, Message: "Paused", Recurrence: state}
+		reminder, _, err := services.RestoreArchiveReminder(archived, accountID, "UTC")
+		require.NoError(t, err)
+		assert.True(t, reminder.RemindAtUTC.Equal(past))
+	})
+
+	t.Run("Past one-time reminder is dropped", func(t *testing.T) {
+		archived := &services.ArchiveReminder{RemindAtUTC: past, Message: "Gone"}
+		reminder, warnings, err := services.RestoreArchiveReminder(archived, accountID, "UTC")
+		require.NoError(t, err)
+		assert.Nil(t, reminder)
+		assert.Len(t, warnings, 1)
+	})
+
+	t.Run("Invalid reminders are rejected", func(t *testing.T) {
+		invalid := []*services.ArchiveReminder{
+			{RemindAtUTC: future, Message: " "},
+			{RemindAtUTC: future, Message: "No rule", Recurrence: services.RecurrenceRRule},
+			{RemindAtUTC: future, Message: "Unknown", Recurrence: 42},
+		}
+		for _, archived := range invalid {
+			_, _, err := services.RestoreArchiveReminder(archived, accountID, "UTC")
+			assert.Error(t, err, archived.Message)
+		}
+	})
+}
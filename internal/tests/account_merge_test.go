@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database"
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// mergeDatabase points the database package at a migrated test database. The cache
+// invalidation of the merge goes to a Redis that is not running and only logs.
+func mergeDatabase(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db := migratedDatabase(t)
+	previousDB, previousRedis := database.DB, database.RedisClient
+	database.DB = db
+	database.RedisClient = redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 10 * time.Millisecond})
+	t.Cleanup(func() {
+		database.RedisClient.Close()
+		database.DB, database.RedisClient = previousDB, previousRedis
+	})
+	return db
+}
+
+func TestMergeAccountsMovesTheTelephonyData(t *testing.T) {
+	db := mergeDatabase(t)
+	survivor, merged := createTestAccount(t, db), createTestAccount(t, db)
+	verifiedAt := time.Now().UTC().Truncate(time.Second)
+
+	// Both accounts have +33612345678, only the merged one verified it
+	require.NoError(t, db.Create(&models.PhoneNumber{AccountID: survivor.ID, Number: "+33612345678"}).Error)
+	require.NoError(t, db.Create(&models.PhoneNumber{AccountID: merged.ID, Number: "+33612345678", VerifiedAt: &verifiedAt}).Error)
+	require.NoError(t, db.Create(&models.PhoneNumber{AccountID: merged.ID, Number: "+33687654321", VerifiedAt: &verifiedAt}).Error)
+
+	require.NoError(t, db.Create(&models.TelephonyUsage{AccountID: survivor.ID, Month: "2026-03", SMSCount: 3, VoiceCount: 1}).Error)
+	require.NoError(t, db.Create(&models.TelephonyUsage{AccountID: merged.ID, Month: "2026-03", SMSCount: 4, VoiceCount: 2}).Error)
+	require.NoError(t, db.Create(&models.TelephonyUsage{AccountID: merged.ID, Month: "2026-02", SMSCount: 5}).Error)
+
+	reminder := createTestReminder(t, db, merged.ID, time.Now().UTC().Add(time.Hour), 1)
+	require.NoError(t, db.Create(&models.ReminderDelivery{
+		ReminderID:            reminder.ID,
+		ReminderDestinationID: reminder.Destinations[0].ID,
+		AccountID:             merged.ID,
+		DestinationType:       models.DestinationWebhook,
+		ScheduledAt:           time.Now().UTC(),
+		Outcome:               models.DeliveryOutcomeSuccess,
+	}).Error)
+
+	require.NoError(t, services.MergeAccounts(context.Background(), repositories.NewRepositories(db), survivor.ID, merged.ID))
+
+	var numbers []models.PhoneNumber
+	require.NoError(t, db.Where("account_id = ?", survivor.ID).Order("number").Find(&numbers).Error)
+	require.Len(t, numbers, 2)
+	assert.Equal(t, "+33612345678", numbers[0].Number)
+	assert.True(t, numbers[0].IsVerified(), "the verification of the merged number is kept")
+	assert.True(t, numbers[1].IsVerified())
+
+	var usages []models.TelephonyUsage
+	require.NoError(t, db.Where("account_id = ?", survivor.ID).Order("month").Find(&usages).Error)
+	require.Len(t, usages, 2)
+	assert.Equal(t, 5, usages[0].SMSCount)
+	assert.Equal(t, 7, usages[1].SMSCount, "the usage of a month both accounts used is summed")
+	assert.Equal(t, 3, usages[1].VoiceCount)
+
+	deliveries, err := repositories.NewReminderDeliveryRepository(db).GetByAccountID(survivor.ID, 0)
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 1, "the delivery history follows the reminders")
+}
+
+func TestMergeAccountsMovesTheWebPushSubscriptions(t *testing.T) {
+	db := mergeDatabase(t)
+	survivor, merged := createTestAccount(t, db), createTestAccount(t, db)
+
+	require.NoError(t, db.Create(&models.WebPushSubscription{AccountID: merged.ID, Endpoint: "https://push.example.com/" + merged.ID.String(), P256dh: "key", Auth: "auth"}).Error)
+	reminder := &models.Reminder{
+		AccountID:   merged.ID,
+		RemindAtUTC: time.Now().UTC().Add(time.Hour),
+		Message:     "Stand-up",
+		Destinations: []models.ReminderDestination{
+			{Type: models.DestinationWebPush, Metadata: models.JSONB{"account_id": merged.ID.String()}},
+		},
+	}
+	require.NoError(t, repositories.NewReminderRepository(db).Create(reminder, false))
+
+	require.NoError(t, services.MergeAccounts(context.Background(), repositories.NewRepositories(db), survivor.ID, merged.ID))
+
+	subscriptions, err := repositories.NewWebPushSubscriptionRepository(db).GetByAccountID(survivor.ID)
+	require.NoError(t, err)
+	assert.Len(t, subscriptions, 1)
+
+	destination, err := repositories.NewReminderDestinationRepository(db).GetByID(reminder.Destinations[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, destination)
+	assert.Equal(t, survivor.ID.String(), destination.Metadata["account_id"], "the destination notifies the survivor's browsers")
+}
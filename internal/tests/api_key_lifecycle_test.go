@@ -0,0 +1,165 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/api"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyExpiry(t *testing.T) {
+	identityRepo := &fakeIdentityRepository{}
+	apiKeyService := services.NewAPIKeyService(identityRepo, nil)
+	accountID := uuid.New()
+
+	past := time.Now().Add(-time.Hour)
+	_, err := apiKeyService.CreateAPIKey(accountID, "stale", nil, &past)
+	assert.ErrorIs(t, err, services.ErrInvalidAPIKeyExpiry)
+
+	future := time.Now().Add(time.Hour)
+	key, err := apiKeyService.CreateAPIKey(accountID, "ci", nil, &future)
+	require.NoError(t, err)
+	require.NotNil(t, key.ExpiresAt)
+
+	_, err = apiKeyService.AuthenticateAPIKey(key.Key, "203.0.113.7")
+	require.NoError(t, err)
+
+	// The key expires while in use
+	identityRepo.identities[0].ExpiresAt = &past
+	_, err = apiKeyService.AuthenticateAPIKey(key.Key, "203.0.113.7")
+	assert.ErrorIs(t, err, services.ErrAPIKeyExpired)
+
+	keys, err := apiKeyService.GetAPIKeys(accountID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.True(t, keys[0].Expired)
+
+	_, err = apiKeyService.RotateAPIKey(accountID, key.ID, time.Hour)
+	assert.ErrorIs(t, err, services.ErrAPIKeyExpired, "expired keys are recreated, not rotated")
+
+	// The middleware tells an expired key from an unknown one
+	authMiddleware := api.AuthMiddleware(nil, apiKeyService)
+	handler := api.RequireAPIKeyScopes(services.ScopeRemindersRead)(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+	request := httptest.NewRequest(http.MethodGet, "/api/reminders", nil)
+	request.Header.Set("Authorization", "Bearer "+key.Key)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "API key expired")
+}
+
+func TestAPIKeyRotation(t *testing.T) {
+	identityRepo := &fakeIdentityRepository{}
+	apiKeyService := services.NewAPIKeyService(identityRepo, nil)
+	accountID := uuid.New()
+
+	original, err := apiKeyService.CreateAPIKey(accountID, "deploy", []string{services.ScopeRemindersWrite}, nil)
+	require.NoError(t, err)
+
+	_, err = apiKeyService.RotateAPIKey(uuid.New(), original.ID, time.Hour)
+	assert.Error(t, err, "other accounts cannot rotate the key")
+	_, err = apiKeyService.RotateAPIKey(accountID, original.ID, services.MaxAPIKeyRotationGrace+time.Minute)
+	assert.ErrorIs(t, err, services.ErrInvalidAPIKeyGracePeriod)
+
+	rotated, err := apiKeyService.RotateAPIKey(accountID, original.ID, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, original.ID, rotated.ID)
+	assert.Equal(t, "reminders.write", rotated.Scopes)
+	assert.NotEqual(t, original.Key, rotated.Key)
+	require.NotNil(t, rotated.PreviousKeyExpiresAt)
+
+	// Both secrets work during the grace period
+	_, err = apiKeyService.AuthenticateAPIKey(rotated.Key, "198.51.100.1")
+	assert.NoError(t, err)
+	_, err = apiKeyService.AuthenticateAPIKey(original.Key, "198.51.100.1")
+	assert.NoError(t, err)
+
+	// The old secret stops working once the grace period ends
+	ended := time.Now().Add(-time.Second)
+	identityRepo.identities[0].PreviousExpiresAt = &ended
+	_, err = apiKeyService.AuthenticateAPIKey(original.Key, "198.51.100.1")
+	assert.Error(t, err)
+
+	// Without grace period the old secret is revoked immediately
+	again, err := apiKeyService.RotateAPIKey(accountID, original.ID, 0)
+	require.NoError(t, err)
+	assert.Nil(t, again.PreviousKeyExpiresAt)
+	_, err = apiKeyService.AuthenticateAPIKey(rotated.Key, "198.51.100.1")
+	assert.Error(t, err)
+	_, err = apiKeyService.AuthenticateAPIKey(again.Key, "198.51.100.1")
+	assert.NoError(t, err)
+}
+
+func TestAPIKeyLastUsed(t *testing.T) {
+	identityRepo := &fakeIdentityRepository{}
+	apiKeyService := services.NewAPIKeyService(identityRepo, nil)
+	accountID := uuid.New()
+
+	key, err := apiKeyService.CreateAPIKey(accountID, "cron", nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, key.LastUsed)
+
+	trustedProxies, err := api.ParseTrustedProxies("192.0.2.1, 10.0.0.0/8")
+	require.NoError(t, err)
+	authMiddleware := api.AuthMiddleware(nil, apiKeyService)
+	handler := api.ClientIPMiddleware(trustedProxies)(api.RequireAPIKeyScopes(services.ScopeRemindersRead)(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))))
+	request := httptest.NewRequest(http.MethodGet, "/api/reminders", nil)
+	request.Header.Set("Authorization", "Bearer "+key.Key)
+	request.Header.Set("X-Forwarded-For", "203.0.113.9, 10.0.0.1")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	keys, err := apiKeyService.GetAPIKeys(accountID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.NotNil(t, keys[0].LastUsed)
+	require.NotNil(t, keys[0].LastUsedIP)
+	assert.Equal(t, "203.0.113.9", *keys[0].LastUsedIP)
+	assert.WithinDuration(t, time.Now(), *keys[0].LastUsed, time.Minute)
+
+	// A new address is recorded right away
+	_, err = apiKeyService.AuthenticateAPIKey(key.Key, "198.51.100.4")
+	require.NoError(t, err)
+	keys, err = apiKeyService.GetAPIKeys(accountID)
+	require.NoError(t, err)
+	assert.Equal(t, "198.51.100.4", *keys[0].LastUsedIP)
+}
+
+func TestClientIPIgnoresForwardingHeadersFromUntrustedPeers(t *testing.T) {
+	identityRepo := &fakeIdentityRepository{}
+	apiKeyService := services.NewAPIKeyService(identityRepo, nil)
+	accountID := uuid.New()
+	key, err := apiKeyService.CreateAPIKey(accountID, "cron", nil, nil)
+	require.NoError(t, err)
+
+	trustedProxies, err := api.ParseTrustedProxies("10.0.0.0/8")
+	require.NoError(t, err)
+	handler := api.ClientIPMiddleware(trustedProxies)(api.APIKeyAuthMiddleware(apiKeyService)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	// httptest requests come from 192.0.2.1, which is not a trusted proxy
+	request := httptest.NewRequest(http.MethodGet, "/api/reminders", nil)
+	request.Header.Set("Authorization", "Bearer "+key.Key)
+	request.Header.Set("X-Forwarded-For", "203.0.113.9")
+	request.Header.Set("X-Real-IP", "203.0.113.10")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	keys, err := apiKeyService.GetAPIKeys(accountID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.NotNil(t, keys[0].LastUsedIP)
+	assert.Equal(t, "192.0.2.1", *keys[0].LastUsedIP)
+
+	_, err = api.ParseTrustedProxies("10.0.0.0/8, not-an-ip")
+	assert.Error(t, err)
+}
@@ -0,0 +1,187 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/api"
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeIdentityRepository serves the identities of the tests
+type fakeIdentityRepository struct {
+	identities []models.Identity
+}
+
+func (f *fakeIdentityRepository) Create(identity *models.Identity) error {
+	if identity.ID == uuid.Nil {
+		identity.ID = uuid.New()
+	}
+	f.identities = append(f.identities, *identity)
+	return nil
+}
+
+func (f *fakeIdentityRepository) GetByID(id uuid.UUID) (*models.Identity, error) {
+	for i := range f.identities {
+		if f.identities[i].ID == id {
+			return &f.identities[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeIdentityRepository) GetByProviderAndExternalID(provider models.ProviderType, externalID string) (*models.Identity, error) {
+	for i := range f.identities {
+		if f.identities[i].Provider == provider && f.identities[i].ExternalID == externalID {
+			return &f.identities[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeIdentityRepository) GetByAccountID(accountID uuid.UUID) ([]models.Identity, error) {
+	var identities []models.Identity
+	for _, identity := range f.identities {
+		if identity.AccountID == accountID {
+			identities = append(identities, identity)
+		}
+	}
+	return identities, nil
+}
+
+func (f *fakeIdentityRepository) Update(identity *models.Identity) error {
+	for i := range f.identities {
+		if f.identities[i].ID == identity.ID {
+			f.identities[i] = *identity
+		}
+	}
+	return nil
+}
+
+func (f *fakeIdentityRepository) Delete(id uuid.UUID) error {
+	for i := range f.identities {
+		if f.identities[i].ID == id {
+			f.identities = append(f.identities[:i], f.identities[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (f *fakeIdentityRepository) GetByAccessToken(hashedToken string) (*models.Identity, error) {
+	for i := range f.identities {
+		if f.identities[i].AccessToken != nil && *f.identities[i].AccessToken == hashedToken {
+			return &f.identities[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeIdentityRepository) GetByPreviousAccessToken(hashedToken string) (*models.Identity, error) {
+	for i := range f.identities {
+		if f.identities[i].PreviousAccessToken != nil && *f.identities[i].PreviousAccessToken == hashedToken {
+			return &f.identities[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeIdentityRepository) UpdateLastUsed(id uuid.UUID, usedAt time.Time, ip string) error {
+	for i := range f.identities {
+		if f.identities[i].ID == id {
+			f.identities[i].LastUsedAt = &usedAt
+			f.identities[i].LastUsedIP = &ip
+		}
+	}
+	return nil
+}
+
+func TestNormalizeAPIKeyScopes(t *testing.T) {
+	scopes, err := services.NormalizeAPIKeyScopes(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{services.ScopeRemindersRead}, scopes)
+
+	scopes, err = services.NormalizeAPIKeyScopes([]string{"reminders.write", " DFM.read ", "reminders.write"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dfm.read", "reminders.write"}, scopes)
+
+	_, err = services.NormalizeAPIKeyScopes([]string{"reminders.admin"})
+	assert.ErrorIs(t, err, services.ErrInvalidAPIKeyScope)
+}
+
+func TestHasAPIKeyScope(t *testing.T) {
+	granted := []string{services.ScopeRemindersWrite, services.ScopeDFMRead}
+
+	assert.True(t, services.HasAPIKeyScope(granted, services.ScopeRemindersWrite))
+	assert.True(t, services.HasAPIKeyScope(granted, services.ScopeRemindersRead), "write implies read")
+	assert.True(t, services.HasAPIKeyScope(granted, services.ScopeDFMRead))
+	assert.False(t, services.HasAPIKeyScope(granted, services.ScopeDFMWrite), "read does not imply write")
+	assert.False(t, services.HasAPIKeyScope(granted, services.ScopeAccountRead))
+
+	// Keys created before scopes existed keep their read access
+	assert.Equal(t, []string{services.ScopeRemindersRead}, services.ParseAPIKeyScopes(nil))
+}
+
+func TestAPIKeyScopeEnforcement(t *testing.T) {
+	identityRepo := &fakeIdentityRepository{}
+	apiKeyService := services.NewAPIKeyService(identityRepo, nil)
+	accountID := uuid.New()
+
+	readKey, err := apiKeyService.CreateAPIKey(accountID, "reader", nil, nil)
+	require.NoError(t, err)
+	writeKey, err := apiKeyService.CreateAPIKey(accountID, "automation", []string{services.ScopeRemindersWrite}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "reminders.write", writeKey.Scopes)
+
+	authMiddleware := api.AuthMiddleware(nil, apiKeyService)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, accountID, r.Context().Value(api.AccountIDKey))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	routes := map[string]http.Handler{
+		"read":    api.RequireAPIKeyScopes(services.ScopeRemindersRead)(authMiddleware(handler)),
+		"write":   api.RequireAPIKeyScopes(services.ScopeRemindersWrite)(authMiddleware(handler)),
+		"session": authMiddleware(handler),
+		"empty":   api.RequireAPIKeyScopes()(authMiddleware(handler)),
+	}
+
+	tests := []struct {
+		name     string
+		key      string
+		route    string
+		expected int
+	}{
+		{"Read key reads", readKey.Key, "read", http.StatusNoContent},
+		{"Read key cannot write", readKey.Key, "write", http.StatusForbidden},
+		{"Write key writes", writeKey.Key, "write", http.StatusNoContent},
+		{"Write key reads", writeKey.Key, "read", http.StatusNoContent},
+		{"Session-only route", writeKey.Key, "session", http.StatusForbidden},
+		{"Route without scopes", writeKey.Key, "empty", http.StatusForbidden},
+		{"Unknown key", services.APIKeyPrefix + "unknown", "read", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/api/reminders", nil)
+			request.Header.Set("Authorization", "Bearer "+tt.key)
+			recorder := httptest.NewRecorder()
+
+			routes[tt.route].ServeHTTP(recorder, request)
+			assert.Equal(t, tt.expected, recorder.Code)
+
+			if tt.expected == http.StatusForbidden {
+				var response api.APIKeyScopeErrorResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				assert.NotEmpty(t, response.Error)
+				assert.NotEmpty(t, response.GrantedScopes)
+			}
+		})
+	}
+}
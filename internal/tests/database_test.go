@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/migrations"
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// schemaModels are the models whose tables the migrations create
+var schemaModels = []interface{ TableName() string }{
+	&models.Timezone{}, &models.Account{}, &models.Identity{}, &models.Reminder{},
+	&models.ReminderDestination{}, &models.ReminderError{}, &models.ReminderDelivery{},
+	&models.ReminderDispatch{}, &models.EmailVerification{}, &models.PasswordReset{},
+	&models.DFMNote{}, &models.DFMItem{}, &models.FcmToken{}, &models.PhoneNumber{},
+	&models.TelephonyUsage{}, &models.WebPushSubscription{},
+}
+
+// testDatabase opens an empty schema of its own in the PostgreSQL database of
+// TEST_DATABASE_URL, dropped when the test ends. Without it the test is skipped.
+func testDatabase(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	config := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+
+	admin, err := gorm.Open(postgres.Open(dsn), config)
+	require.NoError(t, err)
+	schema := "test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	require.NoError(t, admin.Exec(`CREATE SCHEMA "`+schema+`"`).Error)
+
+	db, err := gorm.Open(postgres.Open(withSearchPath(dsn, schema)), config)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		admin.Exec(`DROP SCHEMA "` + schema + `" CASCADE`)
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// migratedDatabase opens a test database with every migration applied
+func migratedDatabase(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db := testDatabase(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	embedded, err := migrations.Embedded()
+	require.NoError(t, err)
+	_, err = migrations.NewMigrator(sqlDB, embedded).Up(context.Background())
+	require.NoError(t, err)
+	return db
+}
+
+// withSearchPath points the connections of dsn, a URL or key/value string, at schema
+func withSearchPath(dsn, schema string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		if parsed, err := url.Parse(dsn); err == nil {
+			query := parsed.Query()
+			query.Set("search_path", schema)
+			parsed.RawQuery = query.Encode()
+			return parsed.String()
+		}
+	}
+	return dsn + " search_path=" + schema
+}
+
+// requireSchemaMatchesModels checks every column and index the models expect exists
+func requireSchemaMatchesModels(t *testing.T, db *gorm.DB) {
+	t.Helper()
+
+	for _, model := range schemaModels {
+		statement := &gorm.Statement{DB: db}
+		require.NoError(t, statement.Parse(model))
+		require.True(t, db.Migrator().HasTable(model), "table %s", model.TableName())
+		for _, field := range statement.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			require.True(t, db.Migrator().HasColumn(model, field.DBName), "column %s.%s", model.TableName(), field.DBName)
+		}
+		for _, index := range statement.Schema.ParseIndexes() {
+			require.True(t, db.Migrator().HasIndex(model, index.Name), "index %s", index.Name)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/dispatchers"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDiscordAPI records the messages posted to a channel, the first ones carrying
+// the reminder image fail with the given statuses
+type fakeDiscordAPI struct {
+	mu       sync.Mutex
+	failures []int
+	payloads []string
+}
+
+func (f *fakeDiscordAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/messages") {
+		http.NotFound(w, r)
+		return
+	}
+	withImage := r.ParseMultipartForm(1<<20) == nil
+	if withImage {
+		f.payloads = append(f.payloads, r.FormValue("payload_json"))
+	} else {
+		body, _ := io.ReadAll(r.Body)
+		f.payloads = append(f.payloads, string(body))
+	}
+
+	if withImage && len(f.failures) > 0 {
+		status := f.failures[0]
+		f.failures = f.failures[1:]
+		http.Error(w, `{"message": "upstream error"}`, status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"id": "1", "channel_id": "42"}`))
+}
+
+func (f *fakeDiscordAPI) messages() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.payloads...)
+}
+
+// redirectTransport sends every request to the test server
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.URL.Scheme = t.target.Scheme
+	r.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestDiscordSendRetryPostsTheReminderOnce(t *testing.T) {
+	// The reminder image is drawn from the assets at the root of the repository
+	t.Chdir("../..")
+
+	fake := &fakeDiscordAPI{failures: []int{http.StatusServiceUnavailable}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	session, err := discordgo.New("Bot token")
+	require.NoError(t, err)
+	session.Client = &http.Client{Transport: redirectTransport{target: target}}
+	session.MaxRestRetries = 0
+
+	reminder := &models.Reminder{ID: uuid.New(), Message: "Stand-up"}
+	account := &models.Account{Timezone: &models.Timezone{IANALocation: "Europe/Paris"}}
+
+	_, err = dispatchers.DiscordSend(context.Background(), session, reminder, "42", account)
+	require.Error(t, err)
+	_, transient := dispatchers.AsTransient(err)
+	assert.True(t, transient, "a 5xx from Discord is retried")
+
+	sent, err := dispatchers.DiscordSend(context.Background(), session, reminder, "42", account)
+	require.NoError(t, err)
+	assert.Equal(t, "1", sent.ID)
+
+	// One message per attempt carrying both the embed and the image: the failed one left nothing behind
+	messages := fake.messages()
+	require.Len(t, messages, 2)
+	for _, payload := range messages {
+		assert.Contains(t, payload, `"embeds"`)
+	}
+}
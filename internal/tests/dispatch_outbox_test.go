@@ -0,0 +1,368 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/dispatchers"
+	"github.com/ericp/chronos-bot-reminder/internal/engine"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeReminderDispatchRepository keeps the outbox in memory with its unique key
+type fakeReminderDispatchRepository struct {
+	mutex   sync.Mutex
+	entries []models.ReminderDispatch
+}
+
+func (f *fakeReminderDispatchRepository) find(key string, destinationID uuid.UUID) *models.ReminderDispatch {
+	for i := range f.entries {
+		if f.entries[i].IdempotencyKey == key && f.entries[i].ReminderDestinationID == destinationID {
+			return &f.entries[i]
+		}
+	}
+	return nil
+}
+
+func (f *fakeReminderDispatchRepository) CreatePending(dispatches []models.ReminderDispatch) ([]models.ReminderDispatch, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var stored []models.ReminderDispatch
+	for _, dispatch := range dispatches {
+		if existing := f.find(dispatch.IdempotencyKey, dispatch.ReminderDestinationID); existing != nil {
+			stored = append(stored, *existing)
+			continue
+		}
+		dispatch.ID = uuid.New()
+		dispatch.CreatedAt = time.Now()
+		f.entries = append(f.entries, dispatch)
+		stored = append(stored, dispatch)
+	}
+	return stored, nil
+}
+
+func (f *fakeReminderDispatchRepository) UpdateStatus(key string, destinationID uuid.UUID, status models.DispatchStatus, attempts int) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if entry := f.find(key, destinationID); entry != nil {
+		entry.Status = status
+		entry.Attempts = attempts
+	}
+	return nil
+}
+
+func (f *fakeReminderDispatchRepository) GetPending() ([]models.ReminderDispatch, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var pending []models.ReminderDispatch
+	for _, entry := range f.entries {
+		if entry.IsPending() {
+			pending = append(pending, entry)
+		}
+	}
+	return pending, nil
+}
+
+func (f *fakeReminderDispatchRepository) DeleteOlderThan(before time.Time) error {
+	return nil
+}
+
+func (f *fakeReminderDispatchRepository) status(key string, destinationID uuid.UUID) models.DispatchStatus {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if entry := f.find(key, destinationID); entry != nil {
+		return entry.Status
+	}
+	return ""
+}
+
+// recordingDispatcher records the idempotency keys it is called with
+type recordingDispatcher struct {
+	mutex sync.Mutex
+	calls map[uuid.UUID][]string // destination ID -> keys
+	err   error
+}
+
+func newRecordingDispatcher() *recordingDispatcher {
+	return &recordingDispatcher{calls: map[uuid.UUID][]string{}}
+}
+
+func (d *recordingDispatcher) GetSupportedType() models.DestinationType {
+	return models.DestinationWebhook
+}
+
+func (d *recordingDispatcher) Dispatch(ctx context.Context, reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) (*models.DeliveryReceipt, error) {
+	return d.DispatchWithKey(ctx, reminder, destination, account, "")
+}
+
+func (d *recordingDispatcher) DispatchWithKey(ctx context.Context, reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account, idempotencyKey string) (*models.DeliveryReceipt, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.calls[destination.ID] = append(d.calls[destination.ID], idempotencyKey)
+	return nil, d.err
+}
+
+// fail makes the next deliveries return err, nil to succeed again
+func (d *recordingDispatcher) fail(err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.err = err
+}
+
+func (d *recordingDispatcher) callsTo(destinationID uuid.UUID) []string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.calls[destinationID]
+}
+
+// fakeReminderErrorRepository records the created errors and lets tests fix them
+type fakeReminderErrorRepository struct {
+	repositories.ReminderErrorRepository
+	mutex   sync.Mutex
+	created []models.ReminderError
+}
+
+func (f *fakeReminderErrorRepository) Create(reminderError *models.ReminderError) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.created = append(f.created, *reminderError)
+	return nil
+}
+
+func (f *fakeReminderErrorRepository) GetUnfixedByReminderDestinationID(reminderDestinationID uuid.UUID) ([]models.ReminderError, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var unfixed []models.ReminderError
+	for _, reminderError := range f.created {
+		if reminderError.ReminderDestinationID == reminderDestinationID && !reminderError.Fixed {
+			unfixed = append(unfixed, reminderError)
+		}
+	}
+	return unfixed, nil
+}
+
+// fixAll marks every error as fixed, as the user does once the destination works again
+func (f *fakeReminderErrorRepository) fixAll() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for i := range f.created {
+		f.created[i].Fixed = true
+	}
+}
+
+// fakeOutboxReminderRepository serves the reminders the outbox recovery looks up
+type fakeOutboxReminderRepository struct {
+	repositories.ReminderRepository
+	reminders map[uuid.UUID]*models.Reminder
+}
+
+func (f *fakeOutboxReminderRepository) GetByID(id uuid.UUID) (*models.Reminder, error) {
+	return f.reminders[id], nil
+}
+
+type outboxFixture struct {
+	outbox     *fakeReminderDispatchRepository
+	dispatcher *recordingDispatcher
+	errors     *fakeReminderErrorRepository
+	reminders  *fakeOutboxReminderRepository
+	registry   *engine.DispatcherRegistry
+	retryQueue *engine.RetryQueue
+}
+
+func newOutboxFixture() *outboxFixture {
+	f := &outboxFixture{
+		outbox:     &fakeReminderDispatchRepository{},
+		dispatcher: newRecordingDispatcher(),
+		errors:     &fakeReminderErrorRepository{},
+		reminders:  &fakeOutboxReminderRepository{reminders: map[uuid.UUID]*models.Reminder{}},
+	}
+	f.registry = engine.NewDispatcherRegistry(f.errors, nil)
+	f.registry.SetDispatchRepository(f.outbox)
+	f.registry.RegisterDispatcher(f.dispatcher)
+	f.retryQueue = engine.NewRetryQueue(f.reminders, f.registry, engine.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour})
+	f.registry.SetRetryQueue(f.retryQueue)
+	return f
+}
+
+// dueReminder returns a reminder due now with the given number of webhook destinations
+func dueReminder(destinations int) *models.Reminder {
+	fireTime := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+	reminder := &models.Reminder{ID: uuid.New(), Message: "Stand-up", RemindAtUTC: fireTime, NextFireUTC: &fireTime}
+	for i := 0; i < destinations; i++ {
+		reminder.Destinations = append(reminder.Destinations, models.ReminderDestination{
+			ID:         uuid.New(),
+			ReminderID: reminder.ID,
+			Type:       models.DestinationWebhook,
+		})
+	}
+	return reminder
+}
+
+func TestDispatchIsWrittenToTheOutboxWithItsKey(t *testing.T) {
+	f := newOutboxFixture()
+	reminder := dueReminder(1)
+	destination := reminder.Destinations[0]
+	key := dispatchers.IdempotencyKey(reminder.ID, *reminder.NextFireUTC)
+
+	delivered, err := f.registry.DispatchReminderTo(context.Background(), reminder, reminder.Destinations)
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+
+	assert.Equal(t, []string{key}, f.dispatcher.callsTo(destination.ID))
+	assert.Equal(t, models.DispatchStatusSent, f.outbox.status(key, destination.ID))
+}
+
+func TestFireProcessedAgainIsNotSentTwice(t *testing.T) {
+	f := newOutboxFixture()
+	reminder := dueReminder(1)
+
+	// The engine stopped after sending but before the reminder moved on: the fire is due again
+	_, err := f.registry.DispatchReminderTo(context.Background(), reminder, reminder.Destinations)
+	require.NoError(t, err)
+	delivered, err := f.registry.DispatchReminderTo(context.Background(), reminder, reminder.Destinations)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, delivered, "the destination already reached still counts as delivered")
+	assert.Len(t, f.dispatcher.callsTo(reminder.Destinations[0].ID), 1)
+}
+
+func TestInterruptedFireOnlyResendsUnconfirmedDestinations(t *testing.T) {
+	f := newOutboxFixture()
+	reminder := dueReminder(2)
+	sent, interrupted := reminder.Destinations[0], reminder.Destinations[1]
+	key := dispatchers.IdempotencyKey(reminder.ID, *reminder.NextFireUTC)
+
+	// A crash happened while sending to the second destination
+	_, err := f.outbox.CreatePending([]models.ReminderDispatch{
+		{IdempotencyKey: key, ReminderID: reminder.ID, ReminderDestinationID: sent.ID, ScheduledAt: *reminder.NextFireUTC, Status: models.DispatchStatusSent, Attempts: 1},
+		{IdempotencyKey: key, ReminderID: reminder.ID, ReminderDestinationID: interrupted.ID, ScheduledAt: *reminder.NextFireUTC, Status: models.DispatchStatusPending},
+	})
+	require.NoError(t, err)
+
+	delivered, err := f.registry.DispatchReminderTo(context.Background(), reminder, reminder.Destinations)
+	require.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+
+	assert.Empty(t, f.dispatcher.callsTo(sent.ID))
+	assert.Equal(t, []string{key}, f.dispatcher.callsTo(interrupted.ID), "the resend carries the key of the interrupted attempt")
+	assert.Equal(t, models.DispatchStatusSent, f.outbox.status(key, interrupted.ID))
+}
+
+func TestPermanentFailureIsMarkedInTheOutbox(t *testing.T) {
+	f := newOutboxFixture()
+	f.dispatcher.err = errors.New("webhook returned non-success status code: 404")
+	reminder := dueReminder(1)
+	key := dispatchers.IdempotencyKey(reminder.ID, *reminder.NextFireUTC)
+
+	delivered, err := f.registry.DispatchReminderTo(context.Background(), reminder, reminder.Destinations)
+	assert.Error(t, err)
+	assert.Equal(t, 0, delivered)
+	assert.Equal(t, models.DispatchStatusFailed, f.outbox.status(key, reminder.Destinations[0].ID))
+	assert.Len(t, f.errors.created, 1)
+}
+
+func TestFailedDestinationIsSentAgainOnceFixed(t *testing.T) {
+	f := newOutboxFixture()
+	f.dispatcher.fail(errors.New("webhook returned non-success status code: 404"))
+	reminder := dueReminder(1)
+	destination := reminder.Destinations[0]
+	key := dispatchers.IdempotencyKey(reminder.ID, *reminder.NextFireUTC)
+
+	_, err := f.registry.DispatchReminderTo(context.Background(), reminder, reminder.Destinations)
+	require.Error(t, err)
+	require.Equal(t, models.DispatchStatusFailed, f.outbox.status(key, destination.ID))
+
+	// The destination was fixed: the scheduler passes it again for the same fire
+	f.dispatcher.fail(nil)
+	delivered, err := f.registry.DispatchReminderTo(context.Background(), reminder, reminder.Destinations)
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, []string{key, key}, f.dispatcher.callsTo(destination.ID))
+	assert.Equal(t, models.DispatchStatusSent, f.outbox.status(key, destination.ID))
+	assert.Equal(t, 2, f.outbox.find(key, destination.ID).Attempts)
+}
+
+func TestTransientFailureStaysPendingForTheRetryQueue(t *testing.T) {
+	f := newOutboxFixture()
+	f.dispatcher.err = dispatchers.Transient(errors.New("webhook returned non-success status code: 503"))
+	reminder := dueReminder(1)
+	key := dispatchers.IdempotencyKey(reminder.ID, *reminder.NextFireUTC)
+
+	delivered, err := f.registry.DispatchReminderTo(context.Background(), reminder, reminder.Destinations)
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered, "a queued retry counts as handled")
+	assert.Equal(t, models.DispatchStatusPending, f.outbox.status(key, reminder.Destinations[0].ID))
+	assert.True(t, f.retryQueue.Contains(reminder.ID, reminder.Destinations[0].ID, *reminder.NextFireUTC))
+
+	// Processing the same fire again does not bypass the queued retry
+	_, err = f.registry.DispatchReminderTo(context.Background(), reminder, reminder.Destinations)
+	require.NoError(t, err)
+	assert.Len(t, f.dispatcher.callsTo(reminder.Destinations[0].ID), 1)
+}
+
+func TestRecoverPendingDispatches(t *testing.T) {
+	f := newOutboxFixture()
+
+	// Still due for the pending fire: left to the scheduler
+	due := dueReminder(1)
+	f.reminders.reminders[due.ID] = due
+
+	// Moved on to its next occurrence while its delivery was in flight
+	movedOn := dueReminder(1)
+	interruptedFire := *movedOn.NextFireUTC
+	nextFire := interruptedFire.Add(24 * time.Hour)
+	movedOn.NextFireUTC = &nextFire
+	f.reminders.reminders[movedOn.ID] = movedOn
+
+	// Deleted since
+	deleted := dueReminder(1)
+
+	// Out of attempts
+	exhausted := dueReminder(1)
+	exhaustedFire := *exhausted.NextFireUTC
+	exhausted.NextFireUTC = nil
+	f.reminders.reminders[exhausted.ID] = exhausted
+
+	for _, entry := range []struct {
+		reminder *models.Reminder
+		fireTime time.Time
+		attempts int
+	}{
+		{due, *due.NextFireUTC, 0},
+		{movedOn, interruptedFire, 1},
+		{deleted, *deleted.NextFireUTC, 0},
+		{exhausted, exhaustedFire, 3},
+	} {
+		_, err := f.outbox.CreatePending([]models.ReminderDispatch{{
+			IdempotencyKey:        dispatchers.IdempotencyKey(entry.reminder.ID, entry.fireTime),
+			ReminderID:            entry.reminder.ID,
+			ReminderDestinationID: entry.reminder.Destinations[0].ID,
+			ScheduledAt:           entry.fireTime,
+			Status:                models.DispatchStatusPending,
+			Attempts:              entry.attempts,
+		}})
+		require.NoError(t, err)
+	}
+
+	f.retryQueue.RecoverPendingDispatches()
+
+	assert.Equal(t, 1, f.retryQueue.Len())
+	assert.True(t, f.retryQueue.Contains(movedOn.ID, movedOn.Destinations[0].ID, interruptedFire))
+
+	assert.Equal(t, models.DispatchStatusPending, f.outbox.status(dispatchers.IdempotencyKey(due.ID, *due.NextFireUTC), due.Destinations[0].ID))
+	assert.Equal(t, models.DispatchStatusFailed, f.outbox.status(dispatchers.IdempotencyKey(deleted.ID, *deleted.NextFireUTC), deleted.Destinations[0].ID))
+	assert.Equal(t, models.DispatchStatusFailed, f.outbox.status(dispatchers.IdempotencyKey(exhausted.ID, exhaustedFire), exhausted.Destinations[0].ID))
+	assert.Len(t, f.errors.created, 1, "running out of attempts is reported as an error")
+}
@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/api"
+	"github.com/ericp/chronos-bot-reminder/internal/config"
+	"github.com/ericp/chronos-bot-reminder/internal/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type healthReport struct {
+	Status     string                         `json:"status"`
+	Components map[string]api.ComponentHealth `json:"components"`
+}
+
+func probeReturning(status api.ComponentStatus, detail string) api.HealthProbe {
+	return func(ctx context.Context) (api.ComponentStatus, string) {
+		return status, detail
+	}
+}
+
+func callHealth(t *testing.T, handler http.HandlerFunc) (int, healthReport) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, "/api/health/ready", nil))
+
+	var report healthReport
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+	return recorder.Code, report
+}
+
+func TestReadinessReportsEveryComponent(t *testing.T) {
+	handler := api.NewHealthHandler()
+	handler.AddCheck(api.HealthCheck{Name: "database", Probe: probeReturning(api.ComponentUp, ""), Critical: true})
+	handler.AddCheck(api.HealthCheck{Name: "scheduler", Probe: probeReturning(api.ComponentStandby, "another instance holds the leadership"), Critical: true, Liveness: true})
+	handler.AddCheck(api.HealthCheck{Name: "fcm", Probe: probeReturning(api.ComponentDisabled, "")})
+
+	code, report := callHealth(t, handler.Ready)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", report.Status)
+	require.Len(t, report.Components, 3)
+	assert.Equal(t, api.ComponentStandby, report.Components["scheduler"].Status)
+	assert.True(t, report.Components["database"].Critical)
+	assert.GreaterOrEqual(t, report.Components["database"].LatencyMs, 0.0)
+}
+
+func TestReadinessIsDegradedByAnOptionalComponent(t *testing.T) {
+	handler := api.NewHealthHandler()
+	handler.AddCheck(api.HealthCheck{Name: "database", Probe: probeReturning(api.ComponentUp, ""), Critical: true})
+	handler.AddCheck(api.HealthCheck{Name: "discord", Probe: probeReturning(api.ComponentDown, "gateway disconnected")})
+
+	code, report := callHealth(t, handler.Ready)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "degraded", report.Status)
+	assert.Equal(t, "gateway disconnected", report.Components["discord"].Detail)
+}
+
+func TestReadinessFailsWhenACriticalComponentIsDown(t *testing.T) {
+	handler := api.NewHealthHandler()
+	handler.AddCheck(api.HealthCheck{Name: "redis", Probe: func(ctx context.Context) (api.ComponentStatus, string) {
+		// A hanging dependency is cut by the probe timeout
+		<-ctx.Done()
+		return api.ComponentDown, ctx.Err().Error()
+	}, Critical: true})
+
+	startedAt := time.Now()
+	code, report := callHealth(t, handler.Ready)
+	assert.Less(t, time.Since(startedAt), 5*time.Second)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "unavailable", report.Status)
+	assert.Equal(t, api.ComponentDown, report.Components["redis"].Status)
+}
+
+func TestLivenessOnlyChecksTheEngine(t *testing.T) {
+	handler := api.NewHealthHandler()
+	handler.AddCheck(api.HealthCheck{Name: "database", Probe: probeReturning(api.ComponentDown, "connection refused"), Critical: true})
+	handler.AddCheck(api.HealthCheck{Name: "scheduler", Probe: probeReturning(api.ComponentUp, ""), Critical: true, Liveness: true})
+	handler.AddCheck(api.HealthCheck{Name: "dfm_scheduler", Probe: probeReturning(api.ComponentUp, ""), Liveness: true})
+
+	code, report := callHealth(t, handler.Live)
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotContains(t, report.Components, "database", "a database outage must not restart the instance")
+
+	handler.AddCheck(api.HealthCheck{Name: "garbage_collector", Probe: probeReturning(api.ComponentDown, "garbage_collector is not running"), Liveness: true})
+	code, _ = callHealth(t, handler.Live)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+}
+
+func TestEngineWorkerProbe(t *testing.T) {
+	statuses := map[string]engine.WorkerStatus{
+		engine.WorkerScheduler:        engine.WorkerRunning,
+		engine.WorkerGarbageCollector: engine.WorkerStopped,
+		engine.WorkerDFMScheduler:     engine.WorkerStandby,
+	}
+	current := func() map[string]engine.WorkerStatus { return statuses }
+
+	status, _ := api.EngineWorkerProbe(engine.WorkerScheduler, current)(context.Background())
+	assert.Equal(t, api.ComponentUp, status)
+	status, detail := api.EngineWorkerProbe(engine.WorkerGarbageCollector, current)(context.Background())
+	assert.Equal(t, api.ComponentDown, status)
+	assert.Equal(t, "garbage_collector is not running", detail)
+	status, _ = api.EngineWorkerProbe(engine.WorkerDFMScheduler, current)(context.Background())
+	assert.Equal(t, api.ComponentStandby, status)
+
+	notStarted := func() map[string]engine.WorkerStatus { return nil }
+	status, _ = api.EngineWorkerProbe(engine.WorkerScheduler, notStarted)(context.Background())
+	assert.Equal(t, api.ComponentDown, status)
+}
+
+func TestWorkerStatusesFollowTheLeadership(t *testing.T) {
+	lock := newFakeLeaderLock()
+	service := &engine.SchedulerService{
+		Scheduler:        engine.NewScheduler(nil, nil, nil, nil),
+		GarbageCollector: engine.NewGarbageCollector(nil),
+	}
+
+	// Not campaigning: the engine is not started at all
+	service.Elector = engine.NewLeaderElector(lock, "instance-b", testLeaderTTL, func() {}, func() {})
+	assert.Equal(t, engine.WorkerStopped, service.WorkerStatuses()[engine.WorkerScheduler])
+	assert.Equal(t, engine.WorkerDisabled, service.WorkerStatuses()[engine.WorkerDFMScheduler])
+
+	// Another instance leads: the components are expected to be stopped here
+	_, err := lock.Acquire(context.Background(), "instance-a", time.Minute)
+	require.NoError(t, err)
+	service.Elector.Start(context.Background())
+	t.Cleanup(service.Elector.Stop)
+	assert.Equal(t, engine.WorkerStandby, service.WorkerStatuses()[engine.WorkerScheduler])
+
+	// Leading without running the components means they died
+	require.NoError(t, lock.Release(context.Background(), "instance-a"))
+	require.Eventually(t, service.Elector.IsLeader, time.Second, 10*time.Millisecond)
+	assert.Equal(t, engine.WorkerStopped, service.WorkerStatuses()[engine.WorkerGarbageCollector])
+}
+
+func TestMailerAndFcmProbesCheckTheConfiguration(t *testing.T) {
+	status, _ := api.MailerProbe(&config.Config{})(context.Background())
+	assert.Equal(t, api.ComponentDisabled, status)
+	status, detail := api.MailerProbe(&config.Config{SMTPHost: "smtp.example.com"})(context.Background())
+	assert.Equal(t, api.ComponentUp, status)
+	assert.Equal(t, "smtp", detail)
+	status, _ = api.MailerProbe(&config.Config{MailTransport: "carrier-pigeon"})(context.Background())
+	assert.Equal(t, api.ComponentDown, status)
+
+	status, _ = api.FcmProbe(&config.Config{})(context.Background())
+	assert.Equal(t, api.ComponentDisabled, status)
+	status, _ = api.FcmProbe(&config.Config{GoogleAppCredentials: "/nonexistent/service-account.json"})(context.Background())
+	assert.Equal(t, api.ComponentDown, status)
+}
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"regexp"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// formatVerb matches fmt verbs, the explicit argument indexes are captured apart
+var formatVerb = regexp.MustCompile(`%(\[\d+\])?([-+# 0]*\d*(?:\.\d+)?[a-zA-Z%])`)
+
+// commandDescriptionKey matches the keys sent to Discord as command description localizations
+var commandDescriptionKey = regexp.MustCompile(`^command\.[a-z]+\.description$`)
+
+// verbsOf returns the sorted fmt verbs of a message without their argument indexes
+func verbsOf(message string) []string {
+	var verbs []string
+	for _, match := range formatVerb.FindAllStringSubmatch(message, -1) {
+		verbs = append(verbs, match[2])
+	}
+	sort.Strings(verbs)
+	return verbs
+}
+
+func TestCataloguesHaveTheSameKeysAndVerbs(t *testing.T) {
+	reference := i18n.Keys(i18n.English)
+	require.NotEmpty(t, reference)
+
+	for _, locale := range i18n.Supported()[1:] {
+		assert.Equal(t, reference, i18n.Keys(locale), "catalogue %s must define exactly the English keys", locale)
+
+		for _, key := range reference {
+			english, _ := i18n.Lookup(i18n.English, key)
+			translated, ok := i18n.Lookup(locale, key)
+			if !ok {
+				continue
+			}
+			assert.Equal(t, verbsOf(english), verbsOf(translated), "%s: %s uses other format verbs than English", locale, key)
+		}
+	}
+}
+
+func TestCommandDescriptionsFitDiscordLimit(t *testing.T) {
+	for _, locale := range i18n.Supported() {
+		for _, key := range i18n.Keys(locale) {
+			if !commandDescriptionKey.MatchString(key) {
+				continue
+			}
+			description, _ := i18n.Lookup(locale, key)
+			assert.LessOrEqual(t, len([]rune(description)), 100, "%s: %s", locale, key)
+		}
+	}
+}
+
+func TestNormalizeAndResolve(t *testing.T) {
+	assert.Equal(t, i18n.French, i18n.Normalize("fr"))
+	assert.Equal(t, i18n.French, i18n.Normalize("fr-FR"))
+	assert.Equal(t, i18n.Spanish, i18n.Normalize("es-419"))
+	assert.Equal(t, i18n.English, i18n.Normalize("en_US"))
+	assert.Equal(t, "", i18n.Normalize("de"))
+	assert.Equal(t, "", i18n.Normalize(""))
+
+	assert.Equal(t, i18n.Spanish, i18n.Resolve("", "de", "es-ES", "fr"))
+	assert.Equal(t, i18n.Default, i18n.Resolve("", "ja"))
+	assert.Equal(t, i18n.Default, i18n.Resolve())
+}
+
+func TestMatchAcceptLanguage(t *testing.T) {
+	assert.Equal(t, i18n.French, i18n.MatchAcceptLanguage("fr-CH, fr;q=0.9, en;q=0.8"))
+	assert.Equal(t, i18n.Spanish, i18n.MatchAcceptLanguage("de;q=0.9, en;q=0.5, es;q=0.7"))
+	assert.Equal(t, i18n.English, i18n.MatchAcceptLanguage("de-DE, en-GB;q=0.3"))
+	assert.Equal(t, "", i18n.MatchAcceptLanguage("de, ja;q=0.5"))
+	assert.Equal(t, "", i18n.MatchAcceptLanguage("fr;q=0"))
+	assert.Equal(t, "", i18n.MatchAcceptLanguage(""))
+}
+
+func TestTranslateWithFallbacks(t *testing.T) {
+	assert.Equal(t, "Reporter", i18n.T(i18n.French, "snooze.button"))
+	assert.Equal(t, "Rappel : Dentiste", i18n.T("fr-FR", "email.reminder.subject", "Dentiste"))
+
+	// Unsupported locales use English, unknown keys are rendered as is
+	assert.Equal(t, "Snooze", i18n.T("de", "snooze.button"))
+	assert.Equal(t, "missing.key", i18n.T(i18n.Spanish, "missing.key"))
+
+	_, ok := i18n.Lookup("de", "snooze.button")
+	assert.False(t, ok)
+}
+
+func TestPluralForms(t *testing.T) {
+	assert.Equal(t, "0 items", i18n.TN(i18n.English, "dfm.list.items", 0, 0))
+	assert.Equal(t, "1 item", i18n.TN(i18n.English, "dfm.list.items", 1, 1))
+	assert.Equal(t, "2 items", i18n.TN(i18n.English, "dfm.list.items", 2, 2))
+
+	// French uses the singular for zero
+	assert.Equal(t, "0 élément", i18n.TN(i18n.French, "dfm.list.items", 0, 0))
+	assert.Equal(t, "2 éléments", i18n.TN(i18n.French, "dfm.list.items", 2, 2))
+
+	assert.Equal(t, "0 elementos", i18n.TN(i18n.Spanish, "dfm.list.items", 0, 0))
+}
+
+func TestFormatDateTime(t *testing.T) {
+	date := time.Date(2026, time.March, 2, 9, 5, 0, 0, time.UTC)
+
+	assert.Equal(t, "Monday, March 2, 2026 at 09:05", i18n.FormatDateTime(i18n.English, date))
+	assert.Equal(t, "lundi 2 mars 2026 à 09:05", i18n.FormatDateTime(i18n.French, date))
+	assert.Equal(t, "lunes, 2 de marzo de 2026 a las 09:05", i18n.FormatDateTime(i18n.Spanish, date))
+
+	assert.Equal(t, "Mar 02, 09:05 UTC", i18n.FormatShortDateTime(i18n.English, date))
+	assert.Equal(t, "02 mars, 09:05 UTC", i18n.FormatShortDateTime(i18n.French, date))
+}
+
+func TestLanguageNames(t *testing.T) {
+	assert.Equal(t, "English", i18n.Name(i18n.English))
+	assert.Equal(t, "Français", i18n.Name(i18n.French))
+	assert.Equal(t, "Español", i18n.Name(i18n.Spanish))
+}
@@ -0,0 +1,305 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/api"
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func icalDocument(components ...string) string {
+	lines := []string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//Test//EN"}
+	lines = append(lines, components...)
+	lines = append(lines, "END:VCALENDAR")
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+func TestParseICalendar(t *testing.T) {
+	parisLoc, _ := time.LoadLocation("Europe/Paris")
+
+	document := icalDocument(
+		"BEGIN:VTIMEZONE", "TZID:Europe/Paris", "BEGIN:STANDARD", "DTSTART:19701025T030000", "END:STANDARD", "END:VTIMEZONE",
+		"BEGIN:VEVENT",
+		"UID:standup@example.com",
+		"SUMMARY:Stand-up\\, room 4\\; bring",
+		"  notes",
+		"DTSTART;TZID=\"Europe/Paris\":20300107T093000",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,WE",
+		"EXDATE;TZID=Europe/Paris:20300109T093000",
+		"BEGIN:VALARM", "ACTION:DISPLAY", "TRIGGER:-PT15M", "END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VTODO",
+		"UID:task",
+		"SUMMARY:Renew passport",
+		"DUE;VALUE=DATE:20300301",
+		"END:VTODO",
+		"BEGIN:VEVENT",
+		"SUMMARY:Launch",
+		"DTSTART:20300115T120000Z",
+		"BEGIN:VALARM", "TRIGGER;VALUE=DATE-TIME:20300114T120000Z", "END:VALARM",
+		"END:VEVENT",
+	)
+
+	entries, err := services.ParseICalendar(document, parisLoc)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	standup := entries[0]
+	assert.Equal(t, "VEVENT", standup.Component)
+	assert.Equal(t, "standup@example.com", standup.UID)
+	assert.Equal(t, "Stand-up, room 4; bring notes", standup.Summary)
+	assert.True(t, standup.Start.Equal(time.Date(2030, time.January, 7, 9, 30, 0, 0, parisLoc)))
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,WE", standup.RRule)
+	require.Len(t, standup.Alarms, 1)
+	assert.True(t, standup.Alarms[0].Equal(time.Date(2030, time.January, 7, 9, 15, 0, 0, parisLoc)))
+	assert.Len(t, standup.Warnings, 1, "EXDATE is reported")
+
+	task := entries[1]
+	assert.Equal(t, "VTODO", task.Component)
+	assert.True(t, task.AllDay)
+	assert.True(t, task.Start.Equal(time.Date(2030, time.March, 1, 0, 0, 0, 0, parisLoc)))
+
+	launch := entries[2]
+	assert.True(t, launch.Start.Equal(time.Date(2030, time.January, 15, 12, 0, 0, 0, time.UTC)))
+	require.Len(t, launch.Alarms, 1)
+	assert.True(t, launch.Alarms[0].Equal(time.Date(2030, time.January, 14, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestParseICalendarErrors(t *testing.T) {
+	_, err := services.ParseICalendar("hello", time.UTC)
+	assert.Error(t, err)
+
+	_, err = services.ParseICalendar("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nSUMMARY:x\r\n", time.UTC)
+	assert.Error(t, err, "unterminated component")
+
+	_, err = services.ParseICalendar(icalDocument("BEGIN:VEVENT", "END:VTODO"), time.UTC)
+	assert.Error(t, err, "mismatched END")
+}
+
+func TestICalEntryToReminder(t *testing.T) {
+	services.SetDefaultRecurrenceOptions(services.RecurrenceOptions{})
+	parisLoc, _ := time.LoadLocation("Europe/Paris")
+	future := time.Date(time.Now().Year()+2, time.January, 10, 9, 0, 0, 0, parisLoc)
+	past := time.Date(2020, time.January, 6, 9, 0, 0, 0, parisLoc)
+
+	tests := []struct {
+		name        string
+		entry       services.ICalEntry
+		issue       string // expected reason, empty when a reminder is created
+		unsupported bool
+		recurrence  int
+		rrule       bool
+		remindAt    time.Time
+	}{
+		{
+			name:     "One-time event",
+			entry:    services.ICalEntry{Component: "VEVENT", Summary: "Dentist", Start: future},
+			remindAt: future,
+		},
+		{
+			name:     "Earliest alarm",
+			entry:    services.ICalEntry{Component: "VEVENT", Summary: "Dentist", Start: future, Alarms: []time.Time{future.Add(-time.Hour), future.Add(-24 * time.Hour)}},
+			remindAt: future.Add(-24 * time.Hour),
+		},
+		{
+			name:     "All-day entry at 09:00",
+			entry:    services.ICalEntry{Component: "VTODO", Summary: "Taxes", Start: time.Date(future.Year(), time.May, 15, 0, 0, 0, 0, parisLoc), AllDay: true},
+			remindAt: time.Date(future.Year(), time.May, 15, 9, 0, 0, 0, parisLoc),
+		},
+		{
+			name:       "Workdays rule maps to its type",
+			entry:      services.ICalEntry{Component: "VEVENT", Summary: "Stand-up", Start: future, RRule: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"},
+			recurrence: services.RecurrenceWorkdays,
+			remindAt:   future,
+		},
+		{
+			name:       "Custom rule is kept",
+			entry:      services.ICalEntry{Component: "VEVENT", Summary: "Sync", Start: future, RRule: "FREQ=WEEKLY;INTERVAL=2"},
+			recurrence: services.RecurrenceRRule,
+			rrule:      true,
+			remindAt:   future,
+		},
+		{
+			name:       "Past series resumes",
+			entry:      services.ICalEntry{Component: "VEVENT", Summary: "Weekly", Start: past, RRule: "FREQ=WEEKLY"},
+			recurrence: services.RecurrenceWeekly,
+		},
+		{name: "Past one-time entry", entry: services.ICalEntry{Summary: "Old", Start: past}, issue: "in the past"},
+		{name: "Ended series", entry: services.ICalEntry{Summary: "Old", Start: past, RRule: "FREQ=DAILY;COUNT=3"}, issue: "the recurrence has ended"},
+		{name: "Cancelled", entry: services.ICalEntry{Summary: "Off", Start: future, Status: "CANCELLED"}, issue: "cancelled"},
+		{name: "Completed task", entry: services.ICalEntry{Component: "VTODO", Summary: "Done", Start: future, Status: "COMPLETED"}, issue: "completed task"},
+		{name: "Modified occurrence", entry: services.ICalEntry{Summary: "Moved", Start: future, RecurrenceID: true}, issue: "modified occurrence of a recurring entry"},
+		{name: "No start", entry: services.ICalEntry{Summary: "Someday"}, issue: "no DTSTART", unsupported: true},
+		{name: "No summary", entry: services.ICalEntry{Start: future}, issue: "no SUMMARY", unsupported: true},
+		{
+			name:        "Alarm the day before a BYDAY rule",
+			entry:       services.ICalEntry{Summary: "Gym", Start: future, RRule: "FREQ=WEEKLY;BYDAY=MO,TH", Alarms: []time.Time{future.Add(-24 * time.Hour)}},
+			issue:       "VALARM on another day than a rule with BYxxx parts",
+			unsupported: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reminder, err := services.ICalEntryToReminder(&tt.entry, "Europe/Paris")
+			if tt.issue != "" {
+				var issue *services.ICalImportIssue
+				require.True(t, errors.As(err, &issue), "expected an import issue, got %v", err)
+				assert.Equal(t, tt.issue, issue.Reason)
+				assert.Equal(t, tt.unsupported, issue.Unsupported)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, strings.TrimSpace(tt.entry.Summary), reminder.Message)
+			assert.Equal(t, tt.recurrence, services.GetRecurrenceType(int(reminder.Recurrence)))
+			assert.Equal(t, tt.rrule, reminder.RRule != nil)
+			assert.True(t, reminder.RemindAtUTC.After(time.Now()))
+			if !tt.remindAt.IsZero() {
+				assert.True(t, reminder.RemindAtUTC.Equal(tt.remindAt), "expected %v, got %v", tt.remindAt, reminder.RemindAtUTC)
+			}
+		})
+	}
+
+	// A series that resumes keeps its original start and weekday
+	reminder, err := services.ICalEntryToReminder(&services.ICalEntry{Summary: "Weekly", Start: past, RRule: "FREQ=WEEKLY"}, "Europe/Paris")
+	require.NoError(t, err)
+	require.NotNil(t, reminder.RecurrenceStartUTC)
+	assert.True(t, reminder.RecurrenceStartUTC.Equal(past))
+	assert.Equal(t, time.Monday, reminder.RemindAtUTC.In(parisLoc).Weekday())
+	assert.Equal(t, 9, reminder.RemindAtUTC.In(parisLoc).Hour())
+}
+
+// An exported calendar must import back to the same reminders
+func TestICalRoundTrip(t *testing.T) {
+	services.SetDefaultRecurrenceOptions(services.RecurrenceOptions{})
+	parisLoc, _ := time.LoadLocation("Europe/Paris")
+	remindAt := time.Date(time.Now().Year()+1, time.March, 3, 18, 45, 0, 0, parisLoc).UTC()
+
+	calendar, err := services.NewICalendar("Chronos reminders", "Europe/Paris")
+	require.NoError(t, err)
+	calendar.AddReminder(&models.Reminder{ID: uuid.New(), RemindAtUTC: remindAt, Message: "Call mom, then dad", Recurrence: services.RecurrenceWeekly})
+	calendar.AddReminder(&models.Reminder{ID: uuid.New(), RemindAtUTC: remindAt, Message: "Once", Recurrence: services.RecurrenceOnce})
+
+	entries, err := services.ParseICalendar(calendar.String(), parisLoc)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	for i, expected := range []int{services.RecurrenceWeekly, services.RecurrenceOnce} {
+		reminder, err := services.ICalEntryToReminder(&entries[i], "Europe/Paris")
+		require.NoError(t, err)
+		assert.True(t, reminder.RemindAtUTC.Equal(remindAt))
+		assert.Equal(t, expected, services.GetRecurrenceType(int(reminder.Recurrence)))
+	}
+	assert.Equal(t, "Call mom, then dad", entries[0].Summary)
+}
+
+// fakeImportAccountRepository serves the account importing, in Paris time
+type fakeImportAccountRepository struct {
+	repositories.AccountRepository
+}
+
+func (f *fakeImportAccountRepository) GetWithTimezone(id uuid.UUID) (*models.Account, error) {
+	return &models.Account{ID: id, Timezone: &models.Timezone{IANALocation: "Europe/Paris"}}, nil
+}
+
+// fakeImportReminderRepository stores the imported reminders, the ones with failMessage cannot be saved
+type fakeImportReminderRepository struct {
+	repositories.ReminderRepository
+	failMessage string
+	created     []*models.Reminder
+}
+
+func (f *fakeImportReminderRepository) Create(reminder *models.Reminder, notify bool) error {
+	if reminder.Message == f.failMessage {
+		return errors.New("insert failed")
+	}
+	reminder.ID = uuid.New()
+	f.created = append(f.created, reminder)
+	return nil
+}
+
+// importReminders uploads an iCalendar document to POST /api/reminders/import with the given destinations
+func importReminders(t *testing.T, reminderRepo *fakeImportReminderRepository, document string, destinations string) api.ImportRemindersResponse {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, err := form.CreateFormFile("file", "calendar.ics")
+	require.NoError(t, err)
+	_, err = file.Write([]byte(document))
+	require.NoError(t, err)
+	require.NoError(t, form.WriteField("destinations", destinations))
+	require.NoError(t, form.Close())
+
+	handler := api.NewUserHandler(reminderRepo, nil, &fakeImportAccountRepository{}, nil)
+	request := httptest.NewRequest(http.MethodPost, "/api/reminders/import", &body)
+	request.Header.Set("Content-Type", form.FormDataContentType())
+	request = request.WithContext(context.WithValue(request.Context(), api.AccountIDKey, uuid.New()))
+	recorder := httptest.NewRecorder()
+	handler.ImportReminders(recorder, request)
+
+	var response api.ImportRemindersResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response), recorder.Body.String())
+	return response
+}
+
+func TestImportedRemindersGetTheirOwnWebhookSecret(t *testing.T) {
+	services.SetWebhookSecretKey("test-key")
+	reminderRepo := &fakeImportReminderRepository{}
+	document := icalDocument(
+		"BEGIN:VEVENT", "SUMMARY:Stand-up", "DTSTART:20300107T093000Z", "END:VEVENT",
+		"BEGIN:VEVENT", "SUMMARY:Retro", "DTSTART:20300110T160000Z", "END:VEVENT",
+	)
+
+	response := importReminders(t, reminderRepo, document, `[{"type": "webhook", "metadata": {"url": "https://example.com/hook"}}]`)
+	assert.Equal(t, 2, response.Created)
+	require.Len(t, reminderRepo.created, 2)
+
+	first, second := reminderRepo.created[0].Destinations, reminderRepo.created[1].Destinations
+	require.Len(t, first, 1)
+	require.Len(t, second, 1)
+	first[0].Metadata["label"] = "changed"
+	assert.NotContains(t, second[0].Metadata, "label", "the reminders do not share their metadata")
+
+	firstSecret, err := services.WebhookSigningSecret(&first[0])
+	require.NoError(t, err)
+	secondSecret, err := services.WebhookSigningSecret(&second[0])
+	require.NoError(t, err)
+	assert.NotEqual(t, firstSecret, secondSecret)
+
+	// The secrets are shown in the report, like on creation
+	require.Len(t, response.Items[0].Destinations, 1)
+	assert.Equal(t, firstSecret, response.Items[0].Destinations[0].SigningSecret)
+	assert.Equal(t, secondSecret, response.Items[1].Destinations[0].SigningSecret)
+}
+
+func TestImportReportsTheRemindersThatFailedToSave(t *testing.T) {
+	services.SetWebhookSecretKey("test-key")
+	reminderRepo := &fakeImportReminderRepository{failMessage: "Retro"}
+	document := icalDocument(
+		"BEGIN:VEVENT", "SUMMARY:Stand-up", "DTSTART:20300107T093000Z", "END:VEVENT",
+		"BEGIN:VEVENT", "SUMMARY:Retro", "DTSTART:20300110T160000Z", "END:VEVENT",
+	)
+
+	response := importReminders(t, reminderRepo, document, `[{"type": "webhook", "metadata": {"url": "https://example.com/hook"}}]`)
+	assert.Equal(t, 1, response.Created)
+	assert.Equal(t, 1, response.Failed)
+	assert.Zero(t, response.Unsupported)
+	assert.Equal(t, api.ImportStatusFailed, response.Items[1].Status)
+	assert.Empty(t, response.Items[1].Destinations)
+}
@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecurrenceToRRule(t *testing.T) {
+	parisLoc, _ := time.LoadLocation("Europe/Paris")
+	jan31 := time.Date(2027, time.January, 31, 10, 0, 0, 0, parisLoc)
+	feb29 := time.Date(2028, time.February, 29, 10, 0, 0, 0, parisLoc)
+
+	tests := []struct {
+		name     string
+		state    int
+		start    time.Time
+		options  services.RecurrenceOptions
+		expected string
+	}{
+		{"Once has no rule", services.RecurrenceOnce, jan31, services.RecurrenceOptions{}, ""},
+		{"Workdays", services.RecurrenceWorkdays, jan31, services.RecurrenceOptions{}, "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"},
+		{"Weekend", services.RecurrenceWeekend, jan31, services.RecurrenceOptions{}, "FREQ=WEEKLY;BYDAY=SA,SU"},
+		{
+			name:     "Monthly clamp on the 31st",
+			state:    services.RecurrenceMonthly,
+			start:    jan31,
+			options:  services.RecurrenceOptions{EndOfMonth: services.EndOfMonthClamp},
+			expected: "FREQ=MONTHLY;BYMONTHDAY=28,29,30,31;BYSETPOS=-1",
+		},
+		{
+			name:     "Monthly skip on the 31st",
+			state:    services.RecurrenceMonthly,
+			start:    jan31,
+			options:  services.RecurrenceOptions{EndOfMonth: services.EndOfMonthSkip},
+			expected: "FREQ=MONTHLY;BYMONTHDAY=31",
+		},
+		{
+			name:     "Yearly leap day on Feb 28",
+			state:    services.RecurrenceYearly,
+			start:    feb29,
+			options:  services.RecurrenceOptions{LeapDay: services.LeapDayFeb28},
+			expected: "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=28,29;BYSETPOS=-1",
+		},
+		{
+			name:     "Yearly leap day on Mar 1",
+			state:    services.RecurrenceYearly,
+			start:    feb29,
+			options:  services.RecurrenceOptions{LeapDay: services.LeapDayMar1},
+			expected: "FREQ=YEARLY;BYYEARDAY=60",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series := &services.RecurrenceSeries{Start: tt.start}
+			assert.Equal(t, tt.expected, services.RecurrenceToRRule(tt.state, series, parisLoc, tt.options))
+		})
+	}
+}
+
+// The exported rules must expand to the dates the scheduler fires on
+func TestRecurrenceToRRuleMatchesScheduler(t *testing.T) {
+	parisLoc, _ := time.LoadLocation("Europe/Paris")
+	options := services.RecurrenceOptions{EndOfMonth: services.EndOfMonthClamp, LeapDay: services.LeapDayMar1}
+	rruleState := services.BuildRecurrenceState(services.RecurrenceRRule, false)
+
+	// The series start in the future so the scheduler never skips an occurrence as missed
+	leapYear := time.Now().Year() + 1
+	for time.Date(leapYear, time.February, 29, 0, 0, 0, 0, time.UTC).Month() != time.February {
+		leapYear++
+	}
+	starts := map[int]time.Time{
+		services.RecurrenceMonthly: time.Date(time.Now().Year()+1, time.January, 30, 9, 0, 0, 0, parisLoc),
+		services.RecurrenceYearly:  time.Date(leapYear, time.February, 29, 9, 0, 0, 0, parisLoc),
+	}
+
+	for recurrenceType, start := range starts {
+		series := &services.RecurrenceSeries{Start: start.UTC()}
+		series.RRule = services.RecurrenceToRRule(recurrenceType, series, parisLoc, options)
+		recurrence := services.NewRecurrence(recurrenceType, start, options)
+
+		expected, fromRule := start, start
+		for step := 0; step < 14; step++ {
+			expected = recurrence.NextOccurrence(expected, 1, parisLoc)
+			next, err := services.GetNextOccurrenceInSeries(fromRule.UTC(), rruleState, series, "Europe/Paris")
+			require.NoError(t, err)
+			require.True(t, next.Equal(expected), "%s: expected %v, got %v", series.RRule, expected, next.In(parisLoc))
+			fromRule = next
+		}
+	}
+}
+
+func TestICalendar(t *testing.T) {
+	services.SetDefaultRecurrenceOptions(services.RecurrenceOptions{})
+	parisLoc, _ := time.LoadLocation("Europe/Paris")
+	remindAt := time.Date(2027, time.March, 1, 8, 30, 0, 0, parisLoc).UTC()
+
+	calendar, err := services.NewICalendar("Chronos reminders", "Europe/Paris")
+	require.NoError(t, err)
+
+	calendar.AddReminder(&models.Reminder{
+		ID:          uuid.MustParse("8d4a5c1e-6f0b-4a57-9a4e-2f4f0f7b9a01"),
+		RemindAtUTC: remindAt,
+		Message:     "Pay rent; call Bob, then water the plants\nand " + strings.Repeat("ç", 60),
+		Recurrence:  services.RecurrenceMonthly,
+		CreatedAt:   remindAt,
+	})
+	calendar.AddReminder(&models.Reminder{
+		ID:          uuid.New(),
+		RemindAtUTC: remindAt,
+		Message:     "Paused",
+		Recurrence:  int16(services.BuildRecurrenceState(services.RecurrenceDaily, true)),
+	})
+	calendar.AddDFMNote(&models.DFMNote{
+		ID:          uuid.New(),
+		RemindAtUTC: &remindAt,
+		Recurrence:  services.RecurrenceWeekly,
+		Items: []models.DFMItem{
+			{Content: "Passport"},
+			{Content: "Keys", Checked: true},
+		},
+	})
+
+	ics := calendar.String()
+
+	for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, "line too long: %q", line)
+	}
+	unfolded := strings.ReplaceAll(ics, "\r\n ", "")
+
+	assert.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(ics, "END:VCALENDAR\r\n"))
+	assert.Contains(t, unfolded, "UID:reminder-8d4a5c1e-6f0b-4a57-9a4e-2f4f0f7b9a01@chronosrmd.com\r\n")
+	assert.Contains(t, unfolded, "DTSTART;TZID=Europe/Paris:20270301T083000\r\n")
+	assert.Contains(t, unfolded, "RRULE:FREQ=MONTHLY;BYMONTHDAY=1\r\n")
+	assert.Contains(t, unfolded, `SUMMARY:Pay rent\; call Bob\, then water the plants\nand `+strings.Repeat("ç", 60)+"\r\n")
+	assert.Contains(t, unfolded, "TRIGGER:PT0S\r\n")
+	assert.NotContains(t, unfolded, "Paused")
+
+	// The DFM note lists its unchecked items
+	assert.Contains(t, unfolded, "SUMMARY:Don't Forget Me\r\nDESCRIPTION:- Passport\r\n")
+	assert.Contains(t, unfolded, "RRULE:FREQ=WEEKLY\r\n")
+	assert.Equal(t, 2, strings.Count(unfolded, "BEGIN:VEVENT"))
+	assert.Equal(t, 2, strings.Count(unfolded, "BEGIN:VALARM"))
+
+	// Paris switches on the last Sunday of March and October
+	assert.Contains(t, unfolded, "BEGIN:DAYLIGHT\r\nDTSTART:19700329T020000\r\nRRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU\r\nTZOFFSETFROM:+0100\r\nTZOFFSETTO:+0200\r\n")
+	assert.Contains(t, unfolded, "BEGIN:STANDARD\r\nDTSTART:19701025T030000\r\nRRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU\r\nTZOFFSETFROM:+0200\r\nTZOFFSETTO:+0100\r\n")
+}
@@ -0,0 +1,250 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/config"
+	"github.com/ericp/chronos-bot-reminder/internal/engine"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testLeaderTTL = 300 * time.Millisecond
+
+// fakeLeaderLock behaves like the Redis lease, instances can be cut off from it
+type fakeLeaderLock struct {
+	mutex       sync.Mutex
+	holder      string
+	expiresAt   time.Time
+	unreachable map[string]bool
+}
+
+func newFakeLeaderLock() *fakeLeaderLock {
+	return &fakeLeaderLock{unreachable: map[string]bool{}}
+}
+
+func (l *fakeLeaderLock) Acquire(ctx context.Context, instanceID string, ttl time.Duration) (bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.unreachable[instanceID] {
+		return false, errors.New("connection refused")
+	}
+	now := time.Now()
+	if l.holder != "" && l.holder != instanceID && now.Before(l.expiresAt) {
+		return false, nil
+	}
+	l.holder = instanceID
+	l.expiresAt = now.Add(ttl)
+	return true, nil
+}
+
+func (l *fakeLeaderLock) Release(ctx context.Context, instanceID string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.unreachable[instanceID] {
+		return errors.New("connection refused")
+	}
+	if l.holder == instanceID {
+		l.holder = ""
+	}
+	return nil
+}
+
+func (l *fakeLeaderLock) cutOff(instanceID string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.unreachable[instanceID] = true
+}
+
+// engineTracker counts the instances running the engine at the same time
+type engineTracker struct {
+	mutex     sync.Mutex
+	running   map[string]bool
+	maxActive int
+	elections map[string]int
+}
+
+func newEngineTracker() *engineTracker {
+	return &engineTracker{running: map[string]bool{}, elections: map[string]int{}}
+}
+
+func (t *engineTracker) elector(lock engine.LeaderLock, instanceID string) *engine.LeaderElector {
+	return engine.NewLeaderElector(lock, instanceID, testLeaderTTL,
+		func() {
+			t.mutex.Lock()
+			defer t.mutex.Unlock()
+			t.running[instanceID] = true
+			t.elections[instanceID]++
+			if len(t.running) > t.maxActive {
+				t.maxActive = len(t.running)
+			}
+		},
+		func() {
+			t.mutex.Lock()
+			defer t.mutex.Unlock()
+			delete(t.running, instanceID)
+		})
+}
+
+func (t *engineTracker) isRunning(instanceID string) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.running[instanceID]
+}
+
+func (t *engineTracker) electionsOf(instanceID string) int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.elections[instanceID]
+}
+
+func (t *engineTracker) maxConcurrent() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.maxActive
+}
+
+func startElectors(t *testing.T, tracker *engineTracker, lock engine.LeaderLock) (*engine.LeaderElector, *engine.LeaderElector) {
+	first := tracker.elector(lock, "instance-a")
+	first.Start(context.Background())
+	require.Eventually(t, first.IsLeader, time.Second, 10*time.Millisecond)
+
+	second := tracker.elector(lock, "instance-b")
+	second.Start(context.Background())
+	t.Cleanup(func() {
+		first.Stop()
+		second.Stop()
+	})
+	return first, second
+}
+
+func TestLeaderElectionKeepsASingleLeader(t *testing.T) {
+	tracker := newEngineTracker()
+	first, second := startElectors(t, tracker, newFakeLeaderLock())
+
+	// Several renewals later the first instance still leads alone
+	time.Sleep(2 * testLeaderTTL)
+	assert.True(t, first.IsLeader())
+	assert.False(t, second.IsLeader())
+	assert.True(t, tracker.isRunning("instance-a"))
+	assert.False(t, tracker.isRunning("instance-b"))
+	assert.Equal(t, 1, tracker.electionsOf("instance-a"))
+	assert.Equal(t, 1, tracker.maxConcurrent())
+}
+
+func TestLeaderStepsDownGracefully(t *testing.T) {
+	tracker := newEngineTracker()
+	lock := newFakeLeaderLock()
+	first, second := startElectors(t, tracker, lock)
+
+	stoppedAt := time.Now()
+	first.Stop()
+	assert.False(t, first.IsLeader())
+	assert.False(t, tracker.isRunning("instance-a"), "the engine must be stopped when Stop returns")
+
+	// The lease was released: the follower takes over at its next campaign, not after the TTL
+	require.Eventually(t, second.IsLeader, testLeaderTTL, 10*time.Millisecond)
+	assert.Less(t, time.Since(stoppedAt), testLeaderTTL)
+	assert.True(t, tracker.isRunning("instance-b"))
+	assert.Equal(t, 1, tracker.maxConcurrent())
+}
+
+func TestLeaderFailoverWhenTheLeaderIsCutOff(t *testing.T) {
+	tracker := newEngineTracker()
+	lock := newFakeLeaderLock()
+	first, second := startElectors(t, tracker, lock)
+
+	// The leader can no longer renew its lease, as when it hangs or loses Redis
+	lock.cutOff("instance-a")
+
+	require.Eventually(t, second.IsLeader, 3*testLeaderTTL, 10*time.Millisecond)
+	assert.False(t, first.IsLeader())
+	assert.False(t, tracker.isRunning("instance-a"))
+	assert.True(t, tracker.isRunning("instance-b"))
+
+	// The old leader stepped down before its lease expired, both never ran together
+	assert.Equal(t, 1, tracker.maxConcurrent())
+}
+
+func TestLeaderTTLFromConfig(t *testing.T) {
+	assert.Equal(t, 30*time.Second, engine.LeaderTTLFromConfig(&config.Config{SchedulerLeaderTTLSeconds: 30}))
+	assert.Equal(t, 15*time.Second, engine.LeaderTTLFromConfig(&config.Config{}))
+	assert.Equal(t, 15*time.Second, engine.LeaderTTLFromConfig(&config.Config{SchedulerLeaderTTLSeconds: 1}))
+}
+
+func TestInstanceIDsAreUnique(t *testing.T) {
+	assert.Equal(t, "api-1", engine.NewInstanceID("api-1"))
+	assert.NotEqual(t, engine.NewInstanceID(""), engine.NewInstanceID(""))
+}
+
+// fakeReminderEventBus delivers the events to every subscriber synchronously
+type fakeReminderEventBus struct {
+	mutex    sync.Mutex
+	handlers []func(engine.ReminderEvent)
+}
+
+func (b *fakeReminderEventBus) Publish(ctx context.Context, event engine.ReminderEvent) error {
+	b.mutex.Lock()
+	handlers := append([]func(engine.ReminderEvent){}, b.handlers...)
+	b.mutex.Unlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+	return nil
+}
+
+func (b *fakeReminderEventBus) Subscribe(ctx context.Context, handler func(engine.ReminderEvent)) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.handlers = append(b.handlers, handler)
+	return nil
+}
+
+// recordingSchedulerNotifier records the notifications reaching a local scheduler
+type recordingSchedulerNotifier struct {
+	events []string
+}
+
+func (n *recordingSchedulerNotifier) NotifyReminderCreated(reminderID uuid.UUID) {
+	n.events = append(n.events, "created:"+reminderID.String())
+}
+
+func (n *recordingSchedulerNotifier) NotifyReminderUpdated(reminderID uuid.UUID) {
+	n.events = append(n.events, "updated:"+reminderID.String())
+}
+
+func (n *recordingSchedulerNotifier) NotifyReminderDeleted(reminderID uuid.UUID) {
+	n.events = append(n.events, "deleted:"+reminderID.String())
+}
+
+func TestClusterNotifierPropagatesToOtherInstances(t *testing.T) {
+	bus := &fakeReminderEventBus{}
+	follower, leader := &recordingSchedulerNotifier{}, &recordingSchedulerNotifier{}
+
+	followerNotifier := engine.NewClusterNotifier("instance-a", follower, bus)
+	leaderNotifier := engine.NewClusterNotifier("instance-b", leader, bus)
+	require.NoError(t, followerNotifier.Listen(context.Background()))
+	require.NoError(t, leaderNotifier.Listen(context.Background()))
+
+	created, updated, deleted := uuid.New(), uuid.New(), uuid.New()
+	followerNotifier.NotifyReminderCreated(created)
+	followerNotifier.NotifyReminderUpdated(updated)
+	leaderNotifier.NotifyReminderDeleted(deleted)
+
+	expected := []string{
+		"created:" + created.String(),
+		"updated:" + updated.String(),
+		"deleted:" + deleted.String(),
+	}
+	// Each instance hears every change exactly once, its own ones are not echoed back
+	assert.Equal(t, expected, follower.events)
+	assert.Equal(t, expected, leader.events)
+}
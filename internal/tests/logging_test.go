@@ -0,0 +1,186 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ericp/chronos-bot-reminder/internal/api"
+	"github.com/ericp/chronos-bot-reminder/internal/dispatchers"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLogs sends the records of the loggers created from now on to a buffer,
+// with every subsystem at the given level
+func captureLogs(t *testing.T, level slog.Level) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, logging.Configure(&buf, logging.FormatJSON))
+	logging.SetAllLevels(level)
+	t.Cleanup(func() {
+		logging.Configure(os.Stderr, logging.FormatJSON)
+		logging.SetAllLevels(slog.LevelInfo)
+	})
+	return &buf
+}
+
+// logRecords decodes the JSON records written to the buffer
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLogLevelsArePerSubsystem(t *testing.T) {
+	buf := captureLogs(t, slog.LevelInfo)
+	require.NoError(t, logging.SetLevel(logging.Engine, slog.LevelDebug))
+	require.NoError(t, logging.SetLevel(logging.API, slog.LevelWarn))
+
+	engineLogger := logging.For(logging.Engine)
+	apiLogger := logging.For(logging.API)
+	engineLogger.Debug("engine debug")
+	apiLogger.Info("api info")
+	apiLogger.Warn("api warn")
+
+	// Loggers already handed out follow a level changed at runtime
+	require.NoError(t, logging.SetLevel(logging.API, slog.LevelInfo))
+	apiLogger.Info("api info after change")
+
+	records := logRecords(t, buf)
+	require.Len(t, records, 3)
+	assert.Equal(t, "engine debug", records[0]["msg"])
+	assert.Equal(t, "engine", records[0]["subsystem"])
+	assert.Equal(t, "api warn", records[1]["msg"])
+	assert.Equal(t, "api info after change", records[2]["msg"])
+}
+
+func TestParseLogLevels(t *testing.T) {
+	levels, err := logging.ParseLevels("engine=debug, API=warn")
+	require.NoError(t, err)
+	assert.Equal(t, map[logging.Subsystem]slog.Level{logging.Engine: slog.LevelDebug, logging.API: slog.LevelWarn}, levels)
+
+	_, err = logging.ParseLevels("scheduler=debug")
+	assert.Error(t, err)
+	_, err = logging.ParseLevels("engine=verbose")
+	assert.Error(t, err)
+	_, err = logging.ParseLevels("engine")
+	assert.Error(t, err)
+}
+
+func TestCorrelationIDComesFromTheContext(t *testing.T) {
+	buf := captureLogs(t, slog.LevelInfo)
+
+	ctx := logging.WithCorrelationID(context.Background(), "abc123")
+	logging.For(logging.Bot).InfoContext(ctx, "interaction handled")
+	logging.For(logging.Bot).Info("no context")
+
+	records := logRecords(t, buf)
+	require.Len(t, records, 2)
+	assert.Equal(t, "abc123", records[0]["correlation_id"])
+	assert.NotContains(t, records[1], "correlation_id")
+}
+
+func TestDispatchRecordsCarryTheKeyOfTheFire(t *testing.T) {
+	buf := captureLogs(t, slog.LevelDebug)
+
+	f := newOutboxFixture()
+	f.dispatcher.err = dispatchers.Transient(errors.New("webhook returned non-success status code: 503"))
+	reminder := dueReminder(1)
+	_, err := f.registry.DispatchReminderTo(context.Background(), reminder, reminder.Destinations)
+	require.NoError(t, err, "a transient failure is handed to the retry queue")
+
+	key := dispatchers.IdempotencyKey(reminder.ID, *reminder.NextFireUTC)
+	found := 0
+	for _, record := range logRecords(t, buf) {
+		if record["reminder_id"] == reminder.ID.String() {
+			assert.Equal(t, key, record["correlation_id"], record["msg"])
+			found++
+		}
+	}
+	assert.Positive(t, found)
+}
+
+func TestRequestLoggingMiddlewarePropagatesTheRequestID(t *testing.T) {
+	buf := captureLogs(t, slog.LevelInfo)
+
+	mux := api.NewWrappedMux()
+	mux.Use(api.RequestLoggingMiddleware(mux))
+	var seen string
+	mux.HandleFunc("GET /api/reminders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		seen = logging.CorrelationID(r.Context())
+		logging.FromContext(r.Context()).Info("Reminder fetched")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/api/reminders/42", nil)
+	request.Header.Set(api.RequestIDHeader, "client-request-1")
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, request)
+
+	assert.Equal(t, "client-request-1", seen)
+	assert.Equal(t, "client-request-1", recorder.Header().Get(api.RequestIDHeader))
+	records := logRecords(t, buf)
+	require.Len(t, records, 2)
+	for _, record := range records {
+		assert.Equal(t, "client-request-1", record["correlation_id"])
+	}
+	assert.Equal(t, "GET /api/reminders/{id}", records[1]["route"])
+	assert.EqualValues(t, http.StatusNoContent, records[1]["status"])
+
+	// An unusable ID is replaced by a generated one
+	request = httptest.NewRequest(http.MethodGet, "/api/reminders/42", nil)
+	request.Header.Set(api.RequestIDHeader, "bad id\nwith a newline")
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, request)
+	assert.Len(t, recorder.Header().Get(api.RequestIDHeader), 16)
+	assert.Equal(t, recorder.Header().Get(api.RequestIDHeader), seen)
+}
+
+func TestLogLevelsEndpoint(t *testing.T) {
+	captureLogs(t, slog.LevelInfo)
+	handler := api.NewLogLevelsHandler()
+	update := api.AdminTokenMiddleware("admin-secret")(http.HandlerFunc(handler.UpdateLogLevels))
+
+	put := func(body string, token string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPut, "/api/admin/log-levels", strings.NewReader(body))
+		request.Header.Set("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		update.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, put(`{"engine": "debug"}`, "wrong").Code)
+	assert.Equal(t, slog.LevelInfo, logging.Levels()[logging.Engine])
+
+	// Nothing changes when one of the entries is invalid
+	assert.Equal(t, http.StatusBadRequest, put(`{"engine": "debug", "scheduler": "warn"}`, "admin-secret").Code)
+	assert.Equal(t, slog.LevelInfo, logging.Levels()[logging.Engine])
+
+	recorder := put(`{"engine": "debug", "api": "error"}`, "admin-secret")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var levels map[string]string
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &levels))
+	assert.Equal(t, "debug", levels["engine"])
+	assert.Equal(t, "error", levels["api"])
+	assert.Equal(t, "info", levels["dispatcher"])
+	assert.Equal(t, slog.LevelDebug, logging.Levels()[logging.Engine])
+}
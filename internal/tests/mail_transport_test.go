@@ -0,0 +1,279 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/config"
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/dispatchers"
+	"github.com/ericp/chronos-bot-reminder/internal/i18n"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTPServer is a minimal SMTP relay recording the envelope and the message data
+type fakeSMTPServer struct {
+	listener  net.Listener
+	rcptReply string // reply to RCPT TO, 250 when empty
+
+	mu       sync.Mutex
+	mailFrom string
+	rcptTo   string
+	data     string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &fakeSMTPServer{listener: listener}
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+	return server
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 fake.smtp ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(command, "EHLO"):
+			reply("250 fake.smtp")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			s.mu.Lock()
+			s.mailFrom = strings.TrimSpace(line[len("MAIL FROM:"):])
+			s.mu.Unlock()
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			if s.rcptReply != "" {
+				reply(s.rcptReply)
+				continue
+			}
+			s.mu.Lock()
+			s.rcptTo = strings.TrimSpace(line[len("RCPT TO:"):])
+			s.mu.Unlock()
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 Go ahead")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			s.mu.Lock()
+			s.data = data.String()
+			s.mu.Unlock()
+			reply("250 Queued")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// readMultipartBodies returns the text and HTML parts of a composed email
+func readMultipartBodies(t *testing.T, message *mail.Message) map[string]string {
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	bodies := map[string]string{}
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(part) // quoted-printable is decoded by the reader
+		require.NoError(t, err)
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		bodies[partType] = string(content)
+	}
+	return bodies
+}
+
+func TestSMTPTransportSendsMultipartEmail(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	transport, err := services.NewSMTPTransport(services.SMTPConfig{Host: "127.0.0.1", Port: server.port(), Security: services.SMTPSecurityNone})
+	require.NoError(t, err)
+
+	mailer := services.NewMailerService(transport, "Chronos <noreply@example.com>")
+	messageID, err := mailer.SendEmail(&services.EmailRequest{
+		To:       "user@example.com",
+		Subject:  "Réunion à 10h",
+		HtmlBody: "<p>Don't forget the meeting</p>",
+		TextBody: "Don't forget the meeting",
+	})
+	require.NoError(t, err)
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.Equal(t, "<noreply@example.com>", server.mailFrom)
+	assert.Equal(t, "<user@example.com>", server.rcptTo)
+
+	message, err := mail.ReadMessage(strings.NewReader(server.data))
+	require.NoError(t, err)
+	assert.Equal(t, messageID, message.Header.Get("Message-ID"))
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Réunion à 10h", subject)
+
+	bodies := readMultipartBodies(t, message)
+	assert.Equal(t, "Don't forget the meeting", bodies["text/plain"])
+	assert.Equal(t, "<p>Don't forget the meeting</p>", bodies["text/html"])
+}
+
+func TestSMTPTransportRequiresStartTLSByDefault(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	transport, err := services.NewSMTPTransport(services.SMTPConfig{Host: "127.0.0.1", Port: server.port()})
+	require.NoError(t, err)
+
+	_, err = transport.Send(context.Background(), "noreply@example.com", &services.EmailRequest{To: "user@example.com", Subject: "Hi", TextBody: "Hi"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "STARTTLS")
+}
+
+func TestEmailDispatcherClassifiesSMTPReplies(t *testing.T) {
+	reminder := &models.Reminder{ID: uuid.New(), Message: "Pay the rent"}
+	destination := &models.ReminderDestination{Type: models.DestinationEmail, Metadata: models.JSONB{"email": "user@example.com"}}
+
+	for reply, transient := range map[string]bool{
+		"451 4.7.1 Try again later": true,
+		"550 5.1.1 Mailbox unknown": false,
+	} {
+		server := newFakeSMTPServer(t)
+		server.rcptReply = reply
+		transport, err := services.NewSMTPTransport(services.SMTPConfig{Host: "127.0.0.1", Port: server.port(), Security: services.SMTPSecurityNone})
+		require.NoError(t, err)
+
+		dispatcher := dispatchers.NewEmailDispatcher(services.NewMailerService(transport, "noreply@example.com"))
+		_, err = dispatcher.Dispatch(context.Background(), reminder, destination, nil)
+		require.Error(t, err, reply)
+		_, isTransient := dispatchers.AsTransient(err)
+		assert.Equal(t, transient, isTransient, reply)
+	}
+}
+
+func TestEmailDispatcherFailsWithoutTransportOutsideDev(t *testing.T) {
+	reminder := &models.Reminder{ID: uuid.New(), Message: "Pay the rent"}
+	destination := &models.ReminderDestination{Type: models.DestinationEmail, Metadata: models.JSONB{"email": "user@example.com"}}
+
+	mailer := services.NewMailerServiceFromConfig(&config.Config{Environment: "PROD", MailFrom: "noreply@example.com"})
+	dispatcher := dispatchers.NewEmailDispatcher(mailer)
+	receipt, err := dispatcher.Dispatch(context.Background(), reminder, destination, nil)
+	assert.Nil(t, receipt)
+	assert.ErrorIs(t, err, services.ErrMailNotConfigured)
+	_, isTransient := dispatchers.AsTransient(err)
+	assert.False(t, isTransient, "a missing transport will not clear up on retry")
+}
+
+func TestFileTransportWritesEmlFiles(t *testing.T) {
+	dir := t.TempDir()
+	mailer := services.NewMailerService(services.NewFileTransport(dir), "noreply@example.com")
+
+	messageID, err := mailer.SendPasswordResetEmail("user@example.com", "https://app.example.com/reset?token=abc", i18n.English)
+	require.NoError(t, err)
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, ".eml", filepath.Ext(files[0].Name()))
+
+	content, err := os.Open(filepath.Join(dir, files[0].Name()))
+	require.NoError(t, err)
+	defer content.Close()
+	message, err := mail.ReadMessage(content)
+	require.NoError(t, err)
+	assert.Equal(t, messageID, message.Header.Get("Message-ID"))
+	assert.Equal(t, "user@example.com", message.Header.Get("To"))
+	assert.Contains(t, readMultipartBodies(t, message)["text/plain"], "https://app.example.com/reset?token=abc")
+}
+
+func TestIdempotencyKeyGivesADeterministicMessageID(t *testing.T) {
+	transport := services.NewFileTransport(t.TempDir())
+	key := dispatchers.IdempotencyKey(uuid.MustParse("6f1c2a9e-3b4d-4e5f-8a7b-9c0d1e2f3a4b"), time.Unix(1767225600, 0))
+	request := &services.EmailRequest{To: "user@example.com", Subject: "Reminder", TextBody: "Hi", IdempotencyKey: key}
+
+	first, err := transport.Send(context.Background(), "Chronos <noreply@example.com>", request)
+	require.NoError(t, err)
+	second, err := transport.Send(context.Background(), "Chronos <noreply@example.com>", request)
+	require.NoError(t, err)
+
+	assert.Equal(t, "<6f1c2a9e-3b4d-4e5f-8a7b-9c0d1e2f3a4b.1767225600@example.com>", first)
+	assert.Equal(t, first, second, "a resent fire keeps its Message-ID")
+}
+
+func TestMailTransportFromConfig(t *testing.T) {
+	transport, err := services.NewMailTransportFromConfig(&config.Config{Environment: "DEV"})
+	require.NoError(t, err)
+	assert.Equal(t, services.MailTransportLog, transport.Name())
+
+	// Outside DEV nothing is silently logged instead of sent
+	_, err = services.NewMailTransportFromConfig(&config.Config{Environment: "PROD"})
+	assert.ErrorIs(t, err, services.ErrMailNotConfigured)
+	_, err = services.NewMailTransportFromConfig(&config.Config{Environment: "PROD", MailTransport: "log"})
+	assert.Error(t, err)
+
+	transport, err = services.NewMailTransportFromConfig(&config.Config{ResendAPIKey: "re_123"})
+	require.NoError(t, err)
+	assert.Equal(t, services.MailTransportResend, transport.Name())
+
+	transport, err = services.NewMailTransportFromConfig(&config.Config{SMTPHost: "smtp.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, services.MailTransportSMTP, transport.Name())
+
+	_, err = services.NewMailTransportFromConfig(&config.Config{MailTransport: "smtp"})
+	assert.Error(t, err)
+	_, err = services.NewMailTransportFromConfig(&config.Config{MailTransport: "carrier-pigeon"})
+	assert.Error(t, err)
+
+	// Header injection through the subject is refused
+	_, err = services.NewFileTransport("").Send(context.Background(), "noreply@example.com", &services.EmailRequest{
+		To: "user@example.com", Subject: "Hi\r\nBcc: victim@example.com", TextBody: "Hi",
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/api"
+	"github.com/ericp/chronos-bot-reminder/internal/config"
+	"github.com/ericp/chronos-bot-reminder/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// metricValue returns the value of a counter or gauge, or the sample count of a
+// histogram, for the series matching the labels. Missing series read as 0.
+func metricValue(t *testing.T, name string, labels map[string]string) float64 {
+	t.Helper()
+
+	families, err := metrics.Registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			matches := 0
+			for _, pair := range metric.GetLabel() {
+				if value, ok := labels[pair.GetName()]; ok && value == pair.GetValue() {
+					matches++
+				}
+			}
+			if matches != len(labels) {
+				continue
+			}
+			switch {
+			case metric.Counter != nil:
+				return metric.GetCounter().GetValue()
+			case metric.Gauge != nil:
+				return metric.GetGauge().GetValue()
+			case metric.Histogram != nil:
+				return float64(metric.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+	return 0
+}
+
+func TestDispatchMetricsPerDestinationTypeAndOutcome(t *testing.T) {
+	success := map[string]string{"destination_type": "webhook", "outcome": metrics.OutcomeSuccess}
+	failure := map[string]string{"destination_type": "webhook", "outcome": metrics.OutcomeFailure}
+	sentBefore := metricValue(t, "chronos_dispatch_total", success)
+	failedBefore := metricValue(t, "chronos_dispatch_total", failure)
+	timedBefore := metricValue(t, "chronos_dispatch_duration_seconds", success)
+
+	f := newOutboxFixture()
+	reminder := dueReminder(2)
+	_, err := f.registry.DispatchReminderTo(context.Background(), reminder, reminder.Destinations)
+	require.NoError(t, err)
+
+	f.dispatcher.err = errors.New("webhook returned status 400")
+	reminder = dueReminder(1)
+	_, err = f.registry.DispatchReminderTo(context.Background(), reminder, reminder.Destinations)
+	require.Error(t, err)
+
+	assert.Equal(t, sentBefore+2, metricValue(t, "chronos_dispatch_total", success))
+	assert.Equal(t, failedBefore+1, metricValue(t, "chronos_dispatch_total", failure))
+	assert.Equal(t, timedBefore+2, metricValue(t, "chronos_dispatch_duration_seconds", success))
+}
+
+func TestHTTPMetricsAreLabelledWithTheRoutePattern(t *testing.T) {
+	mux := api.NewWrappedMux()
+	mux.Use(api.MetricsMiddleware(mux))
+	mux.HandleFunc("GET /api/reminders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	route := map[string]string{"route": "GET /api/reminders/{id}", "method": "GET", "status": "404"}
+	before := metricValue(t, "chronos_http_requests_total", route)
+
+	for _, path := range []string{"/api/reminders/1", "/api/reminders/2"} {
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown", nil))
+
+	// Both IDs share a single series, unknown paths do not create new ones
+	assert.Equal(t, before+2, metricValue(t, "chronos_http_requests_total", route))
+	assert.Positive(t, metricValue(t, "chronos_http_requests_total", map[string]string{"route": "unmatched"}))
+}
+
+func TestHTTPMetricsGroupNonStandardMethods(t *testing.T) {
+	mux := api.NewWrappedMux()
+	mux.Use(api.MetricsMiddleware(mux))
+
+	other := map[string]string{"route": "unmatched", "method": "OTHER"}
+	before := metricValue(t, "chronos_http_requests_total", other)
+
+	for _, method := range []string{"PROPFIND", "X-RANDOM-1", "X-RANDOM-2"} {
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/unknown", nil))
+	}
+
+	assert.Equal(t, before+3, metricValue(t, "chronos_http_requests_total", other))
+	assert.Zero(t, metricValue(t, "chronos_http_requests_total", map[string]string{"method": "X-RANDOM-1"}))
+}
+
+func TestEngineMetrics(t *testing.T) {
+	deletedBefore := metricValue(t, "chronos_garbage_collector_deleted_reminders_total", nil)
+	dfmFailedBefore := metricValue(t, "chronos_dfm_sends_total", map[string]string{"outcome": metrics.OutcomeFailure})
+	dropsBefore := metricValue(t, "chronos_scheduler_update_drops_total", map[string]string{"event": "updated"})
+	lagBefore := metricValue(t, "chronos_scheduler_lag_seconds", nil)
+
+	metrics.IncGarbageCollectorDeletions()
+	metrics.IncDFMSends(errors.New("smtp unavailable"))
+	metrics.IncUpdateDrops("updated")
+	metrics.ObserveSchedulerLag(-time.Second)
+	metrics.SetDueReminders(3)
+	metrics.DecDueReminders()
+
+	assert.Equal(t, deletedBefore+1, metricValue(t, "chronos_garbage_collector_deleted_reminders_total", nil))
+	assert.Equal(t, dfmFailedBefore+1, metricValue(t, "chronos_dfm_sends_total", map[string]string{"outcome": metrics.OutcomeFailure}))
+	assert.Equal(t, dropsBefore+1, metricValue(t, "chronos_scheduler_update_drops_total", map[string]string{"event": "updated"}))
+	assert.Equal(t, lagBefore+1, metricValue(t, "chronos_scheduler_lag_seconds", nil))
+	assert.Equal(t, 2.0, metricValue(t, "chronos_scheduler_due_reminders", nil))
+}
+
+func TestMetricsEndpointServesTheExpositionFormat(t *testing.T) {
+	metrics.IncGarbageCollectorDeletions()
+	handler := api.MetricsTokenMiddleware("scrape-secret")(metrics.Handler())
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	request.Header.Set("Authorization", "Bearer scrape-secret")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Header().Get("Content-Type"), "text/plain")
+
+	body, err := io.ReadAll(recorder.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "# TYPE chronos_garbage_collector_deleted_reminders_total counter")
+	assert.Contains(t, string(body), "go_goroutines")
+}
+
+func TestMetricsEndpointRequiresATokenOutsideDev(t *testing.T) {
+	_, err := api.NewMetricsHandler(&config.Config{Environment: "PROD"})
+	assert.ErrorIs(t, err, api.ErrMetricsTokenRequired)
+
+	handler, err := api.NewMetricsHandler(&config.Config{Environment: "PROD", MetricsToken: "scrape-secret"})
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	// A local Prometheus can scrape a DEV instance without a token
+	handler, err = api.NewMetricsHandler(&config.Config{Environment: "DEV"})
+	require.NoError(t, err)
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/migrations"
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedMigrationsAreSequentialAndReversible(t *testing.T) {
+	embedded, err := migrations.Embedded()
+	require.NoError(t, err)
+	require.NotEmpty(t, embedded)
+
+	for i, migration := range embedded {
+		assert.Equal(t, int64(i+1), migration.Version, "versions have no gap")
+		assert.NotEmpty(t, migration.Down, "migration %d has down statements", migration.Version)
+		assert.Len(t, migration.Checksum, 64)
+	}
+}
+
+func TestMigrationsCreateEveryModelTable(t *testing.T) {
+	embedded, err := migrations.Embedded()
+	require.NoError(t, err)
+
+	for _, model := range schemaModels {
+		created, dropped := 0, 0
+		for _, migration := range embedded {
+			created += strings.Count(migration.Up, `CREATE TABLE IF NOT EXISTS "`+model.TableName()+`"`)
+			dropped += strings.Count(migration.Down, `DROP TABLE IF EXISTS "`+model.TableName()+`"`)
+		}
+		assert.Equal(t, 1, created, "table %s is created once", model.TableName())
+		assert.Equal(t, 1, dropped, "table %s is dropped once", model.TableName())
+	}
+}
+
+func TestMigrationsUpgradeABaselineDatabase(t *testing.T) {
+	db := testDatabase(t)
+	baseline, err := os.ReadFile("testdata/baseline_schema.sql")
+	require.NoError(t, err)
+	require.NoError(t, db.Exec(string(baseline)).Error)
+
+	// Data written by the baseline release
+	accountID, reminderID := uuid.New(), uuid.New()
+	require.NoError(t, db.Exec(`INSERT INTO accounts (id, email) VALUES (?, 'ada@example.com')`, accountID).Error)
+	require.NoError(t, db.Exec(`INSERT INTO identities (account_id, provider, external_id) VALUES (?, 'discord', '42'), (?, 'app', 'legacy')`, accountID, accountID).Error)
+	require.NoError(t, db.Exec(`INSERT INTO reminders (id, account_id, remind_at_utc, next_fire_utc, message, recurrence) VALUES (?, ?, '2026-01-31 09:00:00+00', '2026-01-31 09:00:00+00', 'Rent', 2)`, reminderID, accountID).Error)
+	require.NoError(t, db.Exec(`INSERT INTO reminder_destinations (reminder_id, type, metadata) VALUES (?, 'webhook', '{"url": "https://example.com"}')`, reminderID).Error)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	embedded, err := migrations.Embedded()
+	require.NoError(t, err)
+	migrator := migrations.NewMigrator(sqlDB, embedded)
+
+	applied, err := migrator.Up(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, len(embedded), applied)
+	requireSchemaMatchesModels(t, db)
+
+	var providers []string
+	require.NoError(t, db.Raw(`SELECT provider::text FROM identities WHERE account_id = ?`, accountID).Scan(&providers).Error)
+	assert.Equal(t, []string{"discord"}, providers, "identities of the dropped provider are removed")
+	// The series of existing reminders starts at their current date, not at the next clamped one
+	var reminder models.Reminder
+	require.NoError(t, db.First(&reminder, "id = ?", reminderID).Error)
+	require.NotNil(t, reminder.RecurrenceStartUTC)
+	assert.True(t, reminder.RemindAtUTC.Equal(*reminder.RecurrenceStartUTC))
+
+	applied, err = migrator.Up(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, applied)
+}
+
+func TestMigrationsCanBeRevertedAndReapplied(t *testing.T) {
+	db := testDatabase(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	embedded, err := migrations.Embedded()
+	require.NoError(t, err)
+	migrator := migrations.NewMigrator(sqlDB, embedded)
+
+	_, err = migrator.Up(context.Background())
+	require.NoError(t, err)
+	requireSchemaMatchesModels(t, db)
+
+	reverted, err := migrator.Down(context.Background(), len(embedded))
+	require.NoError(t, err)
+	assert.Equal(t, len(embedded), reverted)
+	assert.False(t, db.Migrator().HasTable("accounts"))
+
+	applied, err := migrator.Up(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, len(embedded), applied)
+	requireSchemaMatchesModels(t, db)
+
+	statuses, err := migrator.Status(context.Background())
+	require.NoError(t, err)
+	for _, status := range statuses {
+		assert.Equal(t, migrations.StateApplied, status.State, "migration %d", status.Version)
+	}
+}
+
+func TestLoadRejectsInvalidMigrationSets(t *testing.T) {
+	sql := &fstest.MapFile{Data: []byte("SELECT 1;")}
+	cases := map[string]fstest.MapFS{
+		"invalid name":      {"0001-init.up.sql": sql, "0001-init.down.sql": sql},
+		"missing down file": {"0001_init.up.sql": sql},
+		"missing up file":   {"0001_init.down.sql": sql},
+		"duplicate version": {"0001_init.up.sql": sql, "0001_init.down.sql": sql, "0001_other.up.sql": sql, "0001_other.down.sql": sql},
+		"zero version":      {"0000_init.up.sql": sql, "0000_init.down.sql": sql},
+	}
+	for name, fsys := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := migrations.Load(fsys)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadOrdersMigrationsByVersion(t *testing.T) {
+	loaded, err := migrations.Load(fstest.MapFS{
+		"0010_later.up.sql":   {Data: []byte("SELECT 10;")},
+		"0010_later.down.sql": {Data: []byte("SELECT -10;")},
+		"0002_first.up.sql":   {Data: []byte("SELECT 2;")},
+		"0002_first.down.sql": {Data: []byte("SELECT -2;")},
+		"README.md":           {Data: []byte("ignored")},
+	})
+	require.NoError(t, err)
+	require.Len(t, loaded, 2)
+	assert.Equal(t, "first", loaded[0].Name)
+	assert.Equal(t, "SELECT 2;", loaded[0].Up)
+	assert.Equal(t, "SELECT -2;", loaded[0].Down)
+	assert.Equal(t, int64(10), loaded[1].Version)
+}
+
+func TestStatusesCompareTheBinaryWithTheDatabase(t *testing.T) {
+	known := []migrations.Migration{
+		{Version: 1, Name: "init", Checksum: "a"},
+		{Version: 2, Name: "edited", Checksum: "b"},
+		{Version: 3, Name: "pending", Checksum: "c"},
+	}
+	appliedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	applied := []migrations.AppliedMigration{
+		{Version: 1, Name: "init", Checksum: "a", AppliedAt: appliedAt},
+		{Version: 2, Name: "edited", Checksum: "changed", AppliedAt: appliedAt},
+		{Version: 4, Name: "newer", Checksum: "d", AppliedAt: appliedAt},
+	}
+
+	statuses := migrations.Statuses(known, applied)
+	require.Len(t, statuses, 4)
+	assert.Equal(t, migrations.StateApplied, statuses[0].State)
+	assert.Equal(t, appliedAt, *statuses[0].AppliedAt)
+	assert.Equal(t, migrations.StateModified, statuses[1].State)
+	assert.Equal(t, migrations.StatePending, statuses[2].State)
+	assert.Nil(t, statuses[2].AppliedAt)
+	assert.Equal(t, migrations.StateMissing, statuses[3].State)
+	assert.Equal(t, "newer", statuses[3].Name)
+
+	assert.ErrorContains(t, migrations.Verify(statuses), "2_edited")
+	assert.ErrorContains(t, migrations.Verify(statuses[2:]), "4_newer")
+	assert.NoError(t, migrations.Verify(statuses[:1]))
+	assert.NoError(t, migrations.Verify(statuses[2:3]))
+}
@@ -1,10 +1,14 @@
 package tests
 
 import (
+	"fmt"
+	"math/rand/v2"
 	"testing"
 	"time"
 
 	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRecalculateNextOccurrence(t *testing.T) {
@@ -304,6 +308,7 @@ func TestNoCatchUpLoop(t *testing.T) {
 // always takes the simple "advance by one interval" path — no catch-up logic involved.
 func TestRecurrenceEdgeCases(t *testing.T) {
 	parisLoc, _ := time.LoadLocation("Europe/Paris")
+	services.SetDefaultRecurrenceOptions(services.RecurrenceOptions{})
 
 	// Jan 1, 2027 = Friday; Jan 2 = Sat, Jan 3 = Sun, Jan 4 = Mon, Jan 6 = Wed, Jan 7 = Thu, Jan 9 = Sat
 	tests := []struct {
@@ -370,18 +375,18 @@ func TestRecurrenceEdgeCases(t *testing.T) {
 			expected: time.Date(2027, time.January, 16, 15, 30, 0, 0, parisLoc), // next Saturday
 		},
 		{
-			name:     "Monthly - Jan 31 overflows to March 3 (Feb has 28 days in 2027)",
+			name:     "Monthly - Jan 31 clamps to Feb 28 (Feb has 28 days in 2027)",
 			from:     time.Date(2027, time.January, 31, 10, 0, 0, 0, parisLoc),
 			timezone: "Europe/Paris",
 			state:    services.RecurrenceMonthly,
-			expected: time.Date(2027, time.March, 3, 10, 0, 0, 0, parisLoc),
+			expected: time.Date(2027, time.February, 28, 10, 0, 0, 0, parisLoc),
 		},
 		{
-			name:     "Yearly - Feb 29 2028 (leap) overflows to March 1 2029 (non-leap)",
+			name:     "Yearly - Feb 29 2028 (leap) clamps to Feb 28 2029 (non-leap)",
 			from:     time.Date(2028, time.February, 29, 10, 0, 0, 0, parisLoc),
 			timezone: "Europe/Paris",
 			state:    services.RecurrenceYearly,
-			expected: time.Date(2029, time.March, 1, 10, 0, 0, 0, parisLoc),
+			expected: time.Date(2029, time.February, 28, 10, 0, 0, 0, parisLoc),
 		},
 		{
 			name:     "Weekly - Dec 26 2027 (Sunday) crosses year boundary to Jan 2 2028",
@@ -447,4 +452,329 @@ func TestPausedRecurrence(t *testing.T) {
 			t.Logf("✓ Paused: returned same time %v", result.In(parisLoc))
 		})
 	}
-}
\ No newline at end of file
+}
+// TestCalendarPolicies checks the end of month and leap day policies on exact dates.
+// The series anchor is the first occurrence, so a clamped month goes back to the 31st.
+func TestCalendarPolicies(t *testing.T) {
+	parisLoc, _ := time.LoadLocation("Europe/Paris")
+	jan31 := time.Date(2027, time.January, 31, 10, 0, 0, 0, parisLoc)
+	feb29 := time.Date(2028, time.February, 29, 10, 0, 0, 0, parisLoc)
+
+	tests := []struct {
+		name       string
+		recurrence services.Recurrence
+		from       time.Time
+		expected   []time.Time
+	}{
+		{
+			name:       "Clamp - Jan 31 -> Feb 28 -> Mar 31 -> Apr 30",
+			recurrence: services.MonthlyRecurrence{Anchor: jan31, EndOfMonth: services.EndOfMonthClamp},
+			from:       jan31,
+			expected: []time.Time{
+				time.Date(2027, time.February, 28, 10, 0, 0, 0, parisLoc),
+				time.Date(2027, time.March, 31, 10, 0, 0, 0, parisLoc),
+				time.Date(2027, time.April, 30, 10, 0, 0, 0, parisLoc),
+			},
+		},
+		{
+			name:       "Skip - Jan 31 -> Mar 31 -> May 31 -> Jul 31",
+			recurrence: services.MonthlyRecurrence{Anchor: jan31, EndOfMonth: services.EndOfMonthSkip},
+			from:       jan31,
+			expected: []time.Time{
+				time.Date(2027, time.March, 31, 10, 0, 0, 0, parisLoc),
+				time.Date(2027, time.May, 31, 10, 0, 0, 0, parisLoc),
+				time.Date(2027, time.July, 31, 10, 0, 0, 0, parisLoc),
+			},
+		},
+		{
+			name:       "Rollover - Jan 31 -> Mar 3 -> Mar 31 -> May 1",
+			recurrence: services.MonthlyRecurrence{Anchor: jan31, EndOfMonth: services.EndOfMonthRollover},
+			from:       jan31,
+			expected: []time.Time{
+				time.Date(2027, time.March, 3, 10, 0, 0, 0, parisLoc),
+				time.Date(2027, time.March, 31, 10, 0, 0, 0, parisLoc),
+				time.Date(2027, time.May, 1, 10, 0, 0, 0, parisLoc),
+			},
+		},
+		{
+			name:       "Leap day Feb 28 - back to Feb 29 on the next leap year",
+			recurrence: services.YearlyRecurrence{Anchor: feb29, LeapDay: services.LeapDayFeb28},
+			from:       feb29,
+			expected: []time.Time{
+				time.Date(2029, time.February, 28, 10, 0, 0, 0, parisLoc),
+				time.Date(2030, time.February, 28, 10, 0, 0, 0, parisLoc),
+				time.Date(2031, time.February, 28, 10, 0, 0, 0, parisLoc),
+				time.Date(2032, time.February, 29, 10, 0, 0, 0, parisLoc),
+			},
+		},
+		{
+			name:       "Leap day Mar 1",
+			recurrence: services.YearlyRecurrence{Anchor: feb29, LeapDay: services.LeapDayMar1},
+			from:       feb29,
+			expected: []time.Time{
+				time.Date(2029, time.March, 1, 10, 0, 0, 0, parisLoc),
+				time.Date(2030, time.March, 1, 10, 0, 0, 0, parisLoc),
+				time.Date(2031, time.March, 1, 10, 0, 0, 0, parisLoc),
+				time.Date(2032, time.February, 29, 10, 0, 0, 0, parisLoc),
+			},
+		},
+		{
+			name:       "Leap day skip - leap years only, 2100 is not one",
+			recurrence: services.YearlyRecurrence{Anchor: feb29, LeapDay: services.LeapDaySkip},
+			from:       time.Date(2096, time.February, 29, 10, 0, 0, 0, parisLoc),
+			expected: []time.Time{
+				time.Date(2104, time.February, 29, 10, 0, 0, 0, parisLoc),
+				time.Date(2108, time.February, 29, 10, 0, 0, 0, parisLoc),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := tt.from
+			for _, expected := range tt.expected {
+				current = tt.recurrence.NextOccurrence(current, 1, parisLoc)
+				assert.True(t, current.Equal(expected), "expected %v, got %v", expected, current)
+			}
+
+			// Jumping several steps at once lands on the same date as stepping
+			last := tt.recurrence.NextOccurrence(tt.from, len(tt.expected), parisLoc)
+			assert.True(t, last.Equal(current), "expected %v, got %v", current, last)
+		})
+	}
+}
+
+func TestSeriesAnchor(t *testing.T) {
+	parisLoc, _ := time.LoadLocation("Europe/Paris")
+	services.SetDefaultRecurrenceOptions(services.RecurrenceOptions{})
+
+	// Jan 31 series clamped to Feb 28: the series brings March back to the 31st
+	series := &services.RecurrenceSeries{Start: time.Date(2027, time.January, 31, 10, 0, 0, 0, parisLoc).UTC()}
+	from := time.Date(2027, time.February, 28, 10, 0, 0, 0, parisLoc).UTC()
+	state := services.BuildRecurrenceState(services.RecurrenceMonthly, false)
+
+	next, err := services.GetNextOccurrenceInSeries(from, state, series, "Europe/Paris")
+	require.NoError(t, err)
+	assert.True(t, next.Equal(time.Date(2027, time.March, 31, 10, 0, 0, 0, parisLoc)), "got %v", next.In(parisLoc))
+
+	// A reminder moved away from its anchor day keeps its new day
+	from = time.Date(2027, time.February, 15, 10, 0, 0, 0, parisLoc).UTC()
+	next, err = services.GetNextOccurrenceInSeries(from, state, series, "Europe/Paris")
+	require.NoError(t, err)
+	assert.True(t, next.Equal(time.Date(2027, time.March, 15, 10, 0, 0, 0, parisLoc)), "got %v", next.In(parisLoc))
+}
+
+func TestParseCalendarPolicies(t *testing.T) {
+	endOfMonth, err := services.ParseEndOfMonthPolicy(" Skip ")
+	require.NoError(t, err)
+	assert.Equal(t, services.EndOfMonthSkip, endOfMonth)
+
+	endOfMonth, err = services.ParseEndOfMonthPolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, services.EndOfMonthClamp, endOfMonth)
+
+	_, err = services.ParseEndOfMonthPolicy("nearest")
+	assert.Error(t, err)
+
+	leapDay, err := services.ParseLeapDayPolicy("mar1")
+	require.NoError(t, err)
+	assert.Equal(t, services.LeapDayMar1, leapDay)
+
+	_, err = services.ParseLeapDayPolicy("feb29")
+	assert.Error(t, err)
+}
+
+// propertyRuns is the number of random dates each recurrence property is checked against
+const propertyRuns = 2000
+
+// propertyLocations mixes DST rules: none, northern, southern and a 30 minutes shift
+var propertyLocations = []string{"UTC", "Europe/Paris", "America/Los_Angeles", "Australia/Sydney", "Australia/Lord_Howe", "Asia/Kolkata"}
+
+// forRandomDates runs check against random dates between 2000 and 2099. The seed is
+// fixed so a failure can be replayed; the logged date is enough to write a regular case.
+// Times stay between 04:00 and 22:59 so no DST gap moves the wall clock.
+func forRandomDates(t *testing.T, check func(from time.Time, loc *time.Location) error) {
+	t.Helper()
+	random := rand.New(rand.NewPCG(20270131, 20280229))
+
+	for run := 0; run < propertyRuns; run++ {
+		loc, err := time.LoadLocation(propertyLocations[random.IntN(len(propertyLocations))])
+		require.NoError(t, err)
+
+		year := 2000 + random.IntN(100)
+		month := time.Month(1 + random.IntN(12))
+		day := 1 + random.IntN(daysIn(year, month))
+		from := time.Date(year, month, day, 4+random.IntN(19), random.IntN(60), 0, 0, loc)
+
+		if err := check(from, loc); err != nil {
+			t.Fatalf("property failed from %v (%s): %v", from, loc, err)
+		}
+	}
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func sameWallClock(a, b time.Time) bool {
+	return a.Hour() == b.Hour() && a.Minute() == b.Minute() && a.Second() == b.Second()
+}
+
+// checkSteps walks steps occurrences from from and checks each one against want,
+// then checks that a single jump of steps intervals lands on the last one
+func checkSteps(recurrence services.Recurrence, from time.Time, loc *time.Location, steps int, want func(step int, previous, next time.Time) error) error {
+	current := from
+	for step := 1; step <= steps; step++ {
+		next := recurrence.NextOccurrence(current, 1, loc)
+		if !next.After(current) {
+			return fmt.Errorf("step %d: %v is not after %v", step, next, current)
+		}
+		if !sameWallClock(next, from) {
+			return fmt.Errorf("step %d: %v lost the time of day of %v", step, next, from)
+		}
+		if err := want(step, current, next); err != nil {
+			return fmt.Errorf("step %d: %w", step, err)
+		}
+		current = next
+	}
+
+	if jumped := recurrence.NextOccurrence(from, steps, loc); !jumped.Equal(current) {
+		return fmt.Errorf("jumping %d steps gives %v, stepping gives %v", steps, jumped, current)
+	}
+	return nil
+}
+
+func TestRecurrenceProperties(t *testing.T) {
+	t.Run("Monthly clamp fires every month on min(anchor day, month length)", func(t *testing.T) {
+		forRandomDates(t, func(from time.Time, loc *time.Location) error {
+			recurrence := services.MonthlyRecurrence{Anchor: from, EndOfMonth: services.EndOfMonthClamp}
+			return checkSteps(recurrence, from, loc, 24, func(step int, _, next time.Time) error {
+				year, month, _ := time.Date(from.Year(), from.Month()+time.Month(step), 1, 0, 0, 0, 0, time.UTC).Date()
+				if next.Year() != year || next.Month() != month {
+					return fmt.Errorf("%v is not in %s %d", next, month, year)
+				}
+				if expected := min(from.Day(), daysIn(year, month)); next.Day() != expected {
+					return fmt.Errorf("%v should be on day %d", next, expected)
+				}
+				return nil
+			})
+		})
+	})
+
+	t.Run("Monthly skip always fires on the anchor day and only skips shorter months", func(t *testing.T) {
+		forRandomDates(t, func(from time.Time, loc *time.Location) error {
+			recurrence := services.MonthlyRecurrence{Anchor: from, EndOfMonth: services.EndOfMonthSkip}
+			return checkSteps(recurrence, from, loc, 24, func(_ int, previous, next time.Time) error {
+				if next.Day() != from.Day() {
+					return fmt.Errorf("%v should be on day %d", next, from.Day())
+				}
+				for month := previous.AddDate(0, 0, 1-previous.Day()).AddDate(0, 1, 0); month.Month() != next.Month(); month = month.AddDate(0, 1, 0) {
+					if daysIn(month.Year(), month.Month()) >= from.Day() {
+						return fmt.Errorf("%s %d was skipped but has a day %d", month.Month(), month.Year(), from.Day())
+					}
+				}
+				return nil
+			})
+		})
+	})
+
+	t.Run("Monthly rollover matches Go date normalisation of the anchor day", func(t *testing.T) {
+		forRandomDates(t, func(from time.Time, loc *time.Location) error {
+			recurrence := services.MonthlyRecurrence{Anchor: from, EndOfMonth: services.EndOfMonthRollover}
+			return checkSteps(recurrence, from, loc, 24, func(step int, _, next time.Time) error {
+				expected := time.Date(from.Year(), from.Month()+time.Month(step), from.Day(), from.Hour(), from.Minute(), 0, 0, loc)
+				if !next.Equal(expected) {
+					return fmt.Errorf("expected %v, got %v", expected, next)
+				}
+				return nil
+			})
+		})
+	})
+
+	t.Run("Yearly keeps the date, leap days follow their policy", func(t *testing.T) {
+		policies := []services.LeapDayPolicy{services.LeapDayFeb28, services.LeapDayMar1, services.LeapDaySkip}
+		forRandomDates(t, func(from time.Time, loc *time.Location) error {
+			for _, policy := range policies {
+				recurrence := services.YearlyRecurrence{Anchor: from, LeapDay: policy}
+				leapDay := from.Month() == time.February && from.Day() == 29
+				err := checkSteps(recurrence, from, loc, 8, func(_ int, previous, next time.Time) error {
+					if !leapDay {
+						if next.Year() != previous.Year()+1 || next.Month() != from.Month() || next.Day() != from.Day() {
+							return fmt.Errorf("%v should be %s %d of the next year", next, from.Month(), from.Day())
+						}
+						return nil
+					}
+
+					leapYear := daysIn(next.Year(), time.February) == 29
+					switch {
+					case leapYear && (next.Month() != time.February || next.Day() != 29):
+						return fmt.Errorf("%v should be on Feb 29", next)
+					case !leapYear && policy == services.LeapDaySkip:
+						return fmt.Errorf("%v is not in a leap year", next)
+					case !leapYear && policy == services.LeapDayFeb28 && (next.Month() != time.February || next.Day() != 28):
+						return fmt.Errorf("%v should be on Feb 28", next)
+					case !leapYear && policy == services.LeapDayMar1 && (next.Month() != time.March || next.Day() != 1):
+						return fmt.Errorf("%v should be on Mar 1", next)
+					}
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+
+	t.Run("Daily and weekly move by whole calendar days", func(t *testing.T) {
+		forRandomDates(t, func(from time.Time, loc *time.Location) error {
+			for recurrence, days := range map[services.Recurrence]int{services.DailyRecurrence{}: 1, services.WeeklyRecurrence{}: 7} {
+				err := checkSteps(recurrence, from, loc, 10, func(_ int, previous, next time.Time) error {
+					if expected := previous.AddDate(0, 0, days); next.YearDay() != expected.YearDay() || next.Year() != expected.Year() {
+						return fmt.Errorf("%v should be %d days after %v", next, days, previous)
+					}
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+
+	t.Run("Workdays and weekend fire on the next matching day", func(t *testing.T) {
+		isWeekend := func(day time.Time) bool {
+			return day.Weekday() == time.Saturday || day.Weekday() == time.Sunday
+		}
+		forRandomDates(t, func(from time.Time, loc *time.Location) error {
+			for recurrence, weekend := range map[services.Recurrence]bool{services.WorkdaysRecurrence{}: false, services.WeekendRecurrence{}: true} {
+				err := checkSteps(recurrence, from, loc, 10, func(_ int, previous, next time.Time) error {
+					if isWeekend(next) != weekend {
+						return fmt.Errorf("%v is a %s", next, next.Weekday())
+					}
+					for day := previous.AddDate(0, 0, 1); day.Before(next) && day.YearDay() != next.YearDay(); day = day.AddDate(0, 0, 1) {
+						if isWeekend(day) == weekend {
+							return fmt.Errorf("%v was skipped", day)
+						}
+					}
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+
+	t.Run("Hourly moves by exactly one hour", func(t *testing.T) {
+		forRandomDates(t, func(from time.Time, loc *time.Location) error {
+			next := services.HourlyRecurrence{}.NextOccurrence(from, 1, loc)
+			if next.Sub(from) != time.Hour {
+				return fmt.Errorf("%v is not one hour after %v", next, from)
+			}
+			return nil
+		})
+	})
+}
@@ -0,0 +1,268 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/api"
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/engine"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeReminderDeliveryRepository stores the deliveries in memory, newest first on reads
+type fakeReminderDeliveryRepository struct {
+	repositories.ReminderDeliveryRepository
+	mu           sync.Mutex
+	deliveries   []models.ReminderDelivery
+	purgedBefore []time.Time
+}
+
+func (f *fakeReminderDeliveryRepository) find(limit int, match func(models.ReminderDelivery) bool) []models.ReminderDelivery {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var deliveries []models.ReminderDelivery
+	for _, delivery := range f.deliveries {
+		if match(delivery) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].SentAt.After(deliveries[j].SentAt) })
+	if limit > 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries
+}
+
+func (f *fakeReminderDeliveryRepository) GetByAccountAndReminder(accountID, reminderID uuid.UUID, limit int) ([]models.ReminderDelivery, error) {
+	return f.find(limit, func(delivery models.ReminderDelivery) bool {
+		return delivery.AccountID == accountID && delivery.ReminderID == reminderID
+	}), nil
+}
+
+func (f *fakeReminderDeliveryRepository) GetByAccountID(accountID uuid.UUID, limit int) ([]models.ReminderDelivery, error) {
+	return f.find(limit, func(delivery models.ReminderDelivery) bool {
+		return delivery.AccountID == accountID
+	}), nil
+}
+
+func (f *fakeReminderDeliveryRepository) DeleteOlderThan(before time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.purgedBefore = append(f.purgedBefore, before)
+	return nil
+}
+
+func (f *fakeReminderDeliveryRepository) purges() []time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]time.Time(nil), f.purgedBefore...)
+}
+
+type deliveryFixture struct {
+	deliveries *fakeReminderDeliveryRepository
+	reminders  *fakeReminderRepository
+	accountID  uuid.UUID
+}
+
+func newDeliveryFixture() *deliveryFixture {
+	return &deliveryFixture{
+		deliveries: &fakeReminderDeliveryRepository{},
+		reminders:  &fakeReminderRepository{reminders: map[uuid.UUID]*models.Reminder{}},
+		accountID:  uuid.New(),
+	}
+}
+
+// deliver records n deliveries of a reminder of the account, one minute apart
+func (f *deliveryFixture) deliver(accountID, reminderID uuid.UUID, n int) {
+	sentAt := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		f.deliveries.deliveries = append(f.deliveries.deliveries, models.ReminderDelivery{
+			ID:              uuid.New(),
+			ReminderID:      reminderID,
+			AccountID:       accountID,
+			DestinationType: models.DestinationWebhook,
+			SentAt:          sentAt.Add(time.Duration(i) * time.Minute),
+			Outcome:         models.DeliveryOutcomeSuccess,
+		})
+	}
+}
+
+// getReminderDeliveries calls GET /api/reminders/{id}/deliveries as the account
+func (f *deliveryFixture) getReminderDeliveries(accountID, reminderID uuid.UUID, query string) *httptest.ResponseRecorder {
+	handler := api.NewReminderHandler(f.reminders, nil, nil)
+	handler.SetReminderDeliveryRepository(f.deliveries)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/reminders/"+reminderID.String()+"/deliveries"+query, nil)
+	request.SetPathValue("id", reminderID.String())
+	request = request.WithContext(context.WithValue(request.Context(), api.AccountIDKey, accountID))
+	recorder := httptest.NewRecorder()
+	handler.GetReminderDeliveries(recorder, request)
+	return recorder
+}
+
+type deliveriesResponse struct {
+	Deliveries []models.ReminderDelivery `json:"deliveries"`
+	Count      int                       `json:"count"`
+}
+
+func decodeDeliveries(t *testing.T, recorder *httptest.ResponseRecorder) deliveriesResponse {
+	t.Helper()
+
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var response deliveriesResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	return response
+}
+
+func TestReminderDeliveriesOutliveTheReminder(t *testing.T) {
+	f := newDeliveryFixture()
+	reminderID := uuid.New()
+	f.deliver(f.accountID, reminderID, 3)
+
+	// The one-time reminder was garbage collected, its history is still served
+	response := decodeDeliveries(t, f.getReminderDeliveries(f.accountID, reminderID, "?limit=2"))
+	assert.Equal(t, 2, response.Count)
+	require.Len(t, response.Deliveries, 2)
+	assert.True(t, response.Deliveries[0].SentAt.After(response.Deliveries[1].SentAt), "newest first")
+}
+
+func TestReminderDeliveriesOfAnotherAccountAreNotFound(t *testing.T) {
+	f := newDeliveryFixture()
+	otherAccountID := uuid.New()
+	reminderID := uuid.New()
+	f.reminders.reminders[reminderID] = &models.Reminder{ID: reminderID, AccountID: otherAccountID}
+	f.deliver(otherAccountID, reminderID, 2)
+
+	recorder := f.getReminderDeliveries(f.accountID, reminderID, "")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "deliveries")
+}
+
+func TestReminderDeliveriesAreFilteredOnTheAccount(t *testing.T) {
+	f := newDeliveryFixture()
+	reminderID := uuid.New()
+	f.reminders.reminders[reminderID] = &models.Reminder{ID: reminderID, AccountID: f.accountID}
+	// The newest deliveries belong to another account, only the rows of the caller are returned
+	f.deliver(f.accountID, reminderID, 1)
+	f.deliver(uuid.New(), reminderID, 2)
+
+	response := decodeDeliveries(t, f.getReminderDeliveries(f.accountID, reminderID, ""))
+	require.Len(t, response.Deliveries, 1)
+	assert.Equal(t, f.accountID, response.Deliveries[0].AccountID)
+}
+
+func TestReminderWithoutDeliveries(t *testing.T) {
+	f := newDeliveryFixture()
+	ownReminderID, otherReminderID := uuid.New(), uuid.New()
+	f.reminders.reminders[ownReminderID] = &models.Reminder{ID: ownReminderID, AccountID: f.accountID}
+	f.reminders.reminders[otherReminderID] = &models.Reminder{ID: otherReminderID, AccountID: uuid.New()}
+
+	response := decodeDeliveries(t, f.getReminderDeliveries(f.accountID, ownReminderID, ""))
+	assert.Zero(t, response.Count)
+
+	assert.Equal(t, http.StatusNotFound, f.getReminderDeliveries(f.accountID, otherReminderID, "").Code)
+	assert.Equal(t, http.StatusNotFound, f.getReminderDeliveries(f.accountID, uuid.New(), "").Code)
+}
+
+func TestReminderDeliveriesRejectAnInvalidLimit(t *testing.T) {
+	f := newDeliveryFixture()
+
+	recorder := f.getReminderDeliveries(f.accountID, uuid.New(), "?limit=-1")
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestAccountDeliveriesOnlyServeTheAccount(t *testing.T) {
+	f := newDeliveryFixture()
+	f.deliver(f.accountID, uuid.New(), 2)
+	f.deliver(f.accountID, uuid.New(), 1)
+	f.deliver(uuid.New(), uuid.New(), 4)
+
+	handler := api.NewUserHandler(nil, nil, nil, nil)
+	handler.SetReminderDeliveryRepository(f.deliveries)
+	request := httptest.NewRequest(http.MethodGet, "/api/reminders/deliveries", nil)
+	request = request.WithContext(context.WithValue(request.Context(), api.AccountIDKey, f.accountID))
+	recorder := httptest.NewRecorder()
+	handler.GetReminderDeliveries(recorder, request)
+
+	response := decodeDeliveries(t, recorder)
+	assert.Equal(t, 3, response.Count)
+	for _, delivery := range response.Deliveries {
+		assert.Equal(t, f.accountID, delivery.AccountID)
+	}
+}
+
+func TestGarbageCollectorPurgesDeliveriesPastTheRetention(t *testing.T) {
+	f := newDeliveryFixture()
+	gc := engine.NewGarbageCollector(f.reminders)
+	gc.SetDeliveryRetention(f.deliveries, 90*24*time.Hour)
+
+	gc.Start(context.Background())
+	t.Cleanup(gc.Stop)
+
+	require.Eventually(t, func() bool { return len(f.deliveries.purges()) > 0 }, time.Second, 10*time.Millisecond)
+	expected := time.Now().UTC().AddDate(0, 0, -90)
+	assert.WithinDuration(t, expected, f.deliveries.purges()[0], time.Minute)
+}
+
+func TestGarbageCollectorKeepsDeliveriesWithoutRetention(t *testing.T) {
+	f := newDeliveryFixture()
+	gc := engine.NewGarbageCollector(f.reminders)
+	gc.SetDeliveryRetention(f.deliveries, 0)
+
+	gc.Start(context.Background())
+	t.Cleanup(gc.Stop)
+
+	assert.Never(t, func() bool { return len(f.deliveries.purges()) > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+}
+
+func TestDeliveryRepositoryFiltersOnTheAccount(t *testing.T) {
+	db := migratedDatabase(t)
+	repo := repositories.NewReminderDeliveryRepository(db)
+	account, otherAccount := createTestAccount(t, db), createTestAccount(t, db)
+	reminder := createTestReminder(t, db, account.ID, time.Now().UTC().Add(time.Hour), 1)
+
+	now := time.Now().UTC()
+	record := func(accountID uuid.UUID, sentAt time.Time) {
+		require.NoError(t, repo.Create(&models.ReminderDelivery{
+			ReminderID:            reminder.ID,
+			ReminderDestinationID: reminder.Destinations[0].ID,
+			AccountID:             accountID,
+			DestinationType:       models.DestinationWebhook,
+			ScheduledAt:           sentAt,
+			SentAt:                sentAt,
+			Outcome:               models.DeliveryOutcomeSuccess,
+		}))
+	}
+	record(account.ID, now.AddDate(0, 0, -100))
+	record(account.ID, now.Add(-2*time.Hour))
+	record(account.ID, now.Add(-time.Hour))
+	record(otherAccount.ID, now)
+
+	deliveries, err := repo.GetByAccountAndReminder(account.ID, reminder.ID, 2)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.WithinDuration(t, now.Add(-time.Hour), deliveries[0].SentAt, time.Second, "newest first")
+	for _, delivery := range deliveries {
+		assert.Equal(t, account.ID, delivery.AccountID)
+	}
+
+	deliveries, err = repo.GetByAccountAndReminder(otherAccount.ID, reminder.ID, 0)
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+
+	require.NoError(t, repo.DeleteOlderThan(now.AddDate(0, 0, -90)))
+	deliveries, err = repo.GetByAccountID(account.ID, 0)
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 2, "the delivery past the retention is purged")
+}
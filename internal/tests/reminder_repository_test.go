@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// createTestAccount stores an account with an email of its own
+func createTestAccount(t *testing.T, db *gorm.DB) *models.Account {
+	t.Helper()
+
+	email := uuid.NewString() + "@example.com"
+	account := &models.Account{Email: &email}
+	require.NoError(t, repositories.NewAccountRepository(db).Create(account))
+	return account
+}
+
+// createTestReminder stores a reminder due at the given time with webhook destinations
+func createTestReminder(t *testing.T, db *gorm.DB, accountID uuid.UUID, remindAt time.Time, destinations int) *models.Reminder {
+	t.Helper()
+
+	reminder := &models.Reminder{AccountID: accountID, RemindAtUTC: remindAt, Message: "Stand-up"}
+	for i := 0; i < destinations; i++ {
+		reminder.Destinations = append(reminder.Destinations, models.ReminderDestination{
+			Type:     models.DestinationWebhook,
+			Metadata: models.JSONB{"url": "https://example.com/hook"},
+		})
+	}
+	require.NoError(t, repositories.NewReminderRepository(db).Create(reminder, false))
+	return reminder
+}
+
+func TestNextRemindersSkipOnlyRemindersWithEveryDestinationBroken(t *testing.T) {
+	db := migratedDatabase(t)
+	reminderRepo := repositories.NewReminderRepository(db)
+	errorRepo := repositories.NewReminderErrorRepository(db)
+	account := createTestAccount(t, db)
+
+	now := time.Now().UTC()
+	allBroken := createTestReminder(t, db, account.ID, now.Add(-2*time.Minute), 1)
+	partlyBroken := createTestReminder(t, db, account.ID, now.Add(-time.Minute), 2)
+
+	brokenError := &models.ReminderError{ReminderID: allBroken.ID, ReminderDestinationID: allBroken.Destinations[0].ID, Stacktrace: "404"}
+	require.NoError(t, errorRepo.Create(brokenError))
+	require.NoError(t, errorRepo.Create(&models.ReminderError{ReminderID: partlyBroken.ID, ReminderDestinationID: partlyBroken.Destinations[0].ID, Stacktrace: "404"}))
+
+	next, err := reminderRepo.GetNextReminders()
+	require.NoError(t, err)
+	require.Len(t, next, 1)
+	assert.Equal(t, partlyBroken.ID, next[0].ID, "a reminder with a healthy destination still fires")
+	assert.Len(t, next[0].Destinations, 2, "the scheduler filters the destinations itself")
+
+	// Once its error is fixed, the earlier reminder is due again
+	require.NoError(t, errorRepo.MarkAsFixed(brokenError.ID))
+	next, err = reminderRepo.GetNextReminders()
+	require.NoError(t, err)
+	require.Len(t, next, 1)
+	assert.Equal(t, allBroken.ID, next[0].ID)
+}
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ericp/chronos-bot-reminder/internal/api"
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeUpdateReminderRepository serves the reminder being edited
+type fakeUpdateReminderRepository struct {
+	repositories.ReminderRepository
+	reminder *models.Reminder
+}
+
+func (f *fakeUpdateReminderRepository) GetWithAccountAndDestinations(id uuid.UUID) (*models.Reminder, error) {
+	if f.reminder == nil || f.reminder.ID != id {
+		return nil, nil
+	}
+	return f.reminder, nil
+}
+
+func (f *fakeUpdateReminderRepository) Update(reminder *models.Reminder, notify bool) error {
+	f.reminder = reminder
+	return nil
+}
+
+// fakeUpdateAccountRepository serves the account editing, in Paris time with its linked identities
+type fakeUpdateAccountRepository struct {
+	repositories.AccountRepository
+	identities []models.Identity
+}
+
+func (f *fakeUpdateAccountRepository) GetWithTimezone(id uuid.UUID) (*models.Account, error) {
+	return &models.Account{ID: id, Timezone: &models.Timezone{IANALocation: "Europe/Paris"}}, nil
+}
+
+func (f *fakeUpdateAccountRepository) GetWithIdentities(id uuid.UUID) (*models.Account, error) {
+	return &models.Account{ID: id, Identities: f.identities}, nil
+}
+
+// fakeDestinationRepository stores the destinations of the edited reminder
+type fakeDestinationRepository struct {
+	repositories.ReminderDestinationRepository
+	destinations []models.ReminderDestination
+}
+
+func (f *fakeDestinationRepository) DeleteByReminderID(reminderID uuid.UUID) error {
+	f.destinations = nil
+	return nil
+}
+
+func (f *fakeDestinationRepository) CreateMultiple(destinations []models.ReminderDestination) error {
+	f.destinations = append(f.destinations, destinations...)
+	return nil
+}
+
+// updateReminder calls PUT /api/reminders/{id} as the owner of the reminder
+func updateReminder(reminderRepo *fakeUpdateReminderRepository, accountRepo *fakeUpdateAccountRepository, destinationRepo *fakeDestinationRepository, body string) *httptest.ResponseRecorder {
+	handler := api.NewReminderHandler(reminderRepo, destinationRepo, nil)
+	handler.SetAccountRepository(accountRepo)
+
+	reminderID := reminderRepo.reminder.ID
+	request := httptest.NewRequest(http.MethodPut, "/api/reminders/"+reminderID.String(), strings.NewReader(body))
+	request.SetPathValue("id", reminderID.String())
+	request = request.WithContext(context.WithValue(request.Context(), api.AccountIDKey, reminderRepo.reminder.AccountID))
+	recorder := httptest.NewRecorder()
+	handler.UpdateReminder(recorder, request)
+	return recorder
+}
+
+func newUpdateFixture() (*fakeUpdateReminderRepository, *fakeUpdateAccountRepository, *fakeDestinationRepository) {
+	previous := models.ReminderDestination{ID: uuid.New(), Type: models.DestinationDiscordDM, Metadata: models.JSONB{"user_id": "123"}}
+	reminder := &models.Reminder{ID: uuid.New(), AccountID: uuid.New(), Message: "Water the plants", Destinations: []models.ReminderDestination{previous}}
+	return &fakeUpdateReminderRepository{reminder: reminder}, &fakeUpdateAccountRepository{}, &fakeDestinationRepository{destinations: []models.ReminderDestination{previous}}
+}
+
+func TestUpdateReminderNormalizesPhoneNumbers(t *testing.T) {
+	reminderRepo, accountRepo, destinationRepo := newUpdateFixture()
+
+	recorder := updateReminder(reminderRepo, accountRepo, destinationRepo, `{"destinations": [{"type": "sms", "metadata": {"phone_number": "+33 6 12-34-56-78"}}]}`)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	require.Len(t, destinationRepo.destinations, 1)
+	assert.Equal(t, models.DestinationSMS, destinationRepo.destinations[0].Type)
+	assert.Equal(t, "+33612345678", destinationRepo.destinations[0].Metadata["phone_number"])
+
+	var response api.ReminderResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.Len(t, response.Destinations, 1)
+	assert.Equal(t, "+33612345678", response.Destinations[0].Metadata["phone_number"])
+}
+
+func TestUpdateReminderRejectsInvalidDestinations(t *testing.T) {
+	reminderRepo, accountRepo, destinationRepo := newUpdateFixture()
+
+	for _, body := range []string{
+		`{"destinations": [{"type": "sms", "metadata": {"phone_number": "not a number"}}]}`,
+		`{"destinations": [{"type": "webhook", "metadata": {}}]}`,
+		`{"destinations": [{"type": "carrier_pigeon"}]}`,
+	} {
+		recorder := updateReminder(reminderRepo, accountRepo, destinationRepo, body)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, body)
+	}
+
+	// The reminder keeps its destinations
+	require.Len(t, destinationRepo.destinations, 1)
+	assert.Equal(t, models.DestinationDiscordDM, destinationRepo.destinations[0].Type)
+}
+
+func TestUpdateReminderFillsInTheTelegramChat(t *testing.T) {
+	reminderRepo, accountRepo, destinationRepo := newUpdateFixture()
+	accountRepo.identities = []models.Identity{
+		{Provider: "discord", ExternalID: "123"},
+		{Provider: models.ProviderTelegram, ExternalID: "987654321"},
+	}
+
+	recorder := updateReminder(reminderRepo, accountRepo, destinationRepo, `{"destinations": [{"type": "telegram"}]}`)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.Len(t, destinationRepo.destinations, 1)
+	assert.Equal(t, models.DestinationTelegram, destinationRepo.destinations[0].Type)
+	assert.Equal(t, "987654321", destinationRepo.destinations[0].Metadata["chat_id"])
+
+	// Without a linked chat the destination cannot be delivered
+	accountRepo.identities = nil
+	recorder = updateReminder(reminderRepo, accountRepo, destinationRepo, `{"destinations": [{"type": "telegram"}]}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
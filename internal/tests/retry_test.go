@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/dispatchers"
+	"github.com/ericp/chronos-bot-reminder/internal/engine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := engine.RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   30 * time.Second,
+		MaxDelay:    3 * time.Minute,
+	}
+
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{attempt: 0, expected: 30 * time.Second},
+		{attempt: 1, expected: 30 * time.Second},
+		{attempt: 2, expected: 1 * time.Minute},
+		{attempt: 3, expected: 2 * time.Minute},
+		{attempt: 4, expected: 3 * time.Minute}, // capped
+		{attempt: 40, expected: 3 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("attempt %d", tt.attempt), func(t *testing.T) {
+			assert.Equal(t, tt.expected, policy.Backoff(tt.attempt))
+		})
+	}
+
+	assert.True(t, policy.CanRetry(4))
+	assert.False(t, policy.CanRetry(5))
+}
+
+func TestClassifyDispatchError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected engine.ErrorClass
+	}{
+		{
+			name:     "plain error is permanent",
+			err:      errors.New("webhook URL not found in metadata"),
+			expected: engine.ErrorClassPermanent,
+		},
+		{
+			name:     "flagged by the dispatcher",
+			err:      dispatchers.Transient(errors.New("webhook returned non-success status code: 503")),
+			expected: engine.ErrorClassTransient,
+		},
+		{
+			name:     "flagged and wrapped",
+			err:      fmt.Errorf("dispatch: %w", dispatchers.TransientAfter(errors.New("rate limited"), time.Minute)),
+			expected: engine.ErrorClassTransient,
+		},
+		{
+			name:     "deadline exceeded",
+			err:      fmt.Errorf("failed to send: %w", context.DeadlineExceeded),
+			expected: engine.ErrorClassTransient,
+		},
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: engine.ErrorClassPermanent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, engine.ClassifyDispatchError(tt.err))
+		})
+	}
+}
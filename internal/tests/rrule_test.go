@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nthWeekday returns the nth weekday of a month at the given hour, n = -1 being the last one
+func nthWeekday(year int, month time.Month, weekday time.Weekday, n int, hour, minute int, loc *time.Location) time.Time {
+	if n < 0 {
+		last := time.Date(year, month+1, 0, hour, minute, 0, 0, loc)
+		return last.AddDate(0, 0, -((int(last.Weekday()) - int(weekday) + 7) % 7))
+	}
+	first := time.Date(year, month, 1, hour, minute, 0, 0, loc)
+	return first.AddDate(0, 0, (int(weekday)-int(first.Weekday())+7)%7+7*(n-1))
+}
+
+// The series start next year so they stay in the future relative to time.Now()
+func TestRRuleNextOccurrence(t *testing.T) {
+	parisLoc, _ := time.LoadLocation("Europe/Paris")
+	state := services.BuildRecurrenceState(services.RecurrenceRRule, false)
+	year := time.Now().Year() + 1
+	until := fmt.Sprintf("%d1231", year)
+
+	firstFriday := nthWeekday(year, time.January, time.Friday, 1, 9, 0, parisLoc)
+	// The clocks go forward on the last Sunday of March, six days after the Monday of that week
+	mondayBeforeDST := nthWeekday(year, time.March, time.Sunday, -1, 9, 0, parisLoc).AddDate(0, 0, -6)
+
+	tests := []struct {
+		name     string
+		rule     string
+		start    time.Time
+		from     time.Time
+		expected time.Time
+	}{
+		{
+			name:     "Every 2nd Tuesday of the month",
+			rule:     "FREQ=MONTHLY;BYDAY=2TU",
+			start:    nthWeekday(year, time.January, time.Tuesday, 2, 9, 0, parisLoc),
+			from:     nthWeekday(year, time.January, time.Tuesday, 2, 9, 0, parisLoc),
+			expected: nthWeekday(year, time.February, time.Tuesday, 2, 9, 0, parisLoc),
+		},
+		{
+			name:     "Last Friday of the month",
+			rule:     "FREQ=MONTHLY;BYDAY=-1FR",
+			start:    nthWeekday(year, time.January, time.Friday, -1, 17, 0, parisLoc),
+			from:     nthWeekday(year, time.January, time.Friday, -1, 17, 0, parisLoc),
+			expected: nthWeekday(year, time.February, time.Friday, -1, 17, 0, parisLoc),
+		},
+		{
+			name:     "Every 3 days",
+			rule:     "FREQ=DAILY;INTERVAL=3",
+			start:    time.Date(year, time.January, 1, 8, 30, 0, 0, parisLoc),
+			from:     time.Date(year, time.January, 1, 8, 30, 0, 0, parisLoc),
+			expected: time.Date(year, time.January, 4, 8, 30, 0, 0, parisLoc),
+		},
+		{
+			name:     "Mon/Wed/Fri, Friday to Monday",
+			rule:     "FREQ=WEEKLY;BYDAY=MO,WE,FR;UNTIL=" + until,
+			start:    firstFriday,
+			from:     firstFriday,
+			expected: firstFriday.AddDate(0, 0, 3),
+		},
+		{
+			name:     "Weekly across the spring DST change keeps 09:00 local",
+			rule:     "FREQ=WEEKLY;BYDAY=MO",
+			start:    mondayBeforeDST,
+			from:     mondayBeforeDST,
+			expected: mondayBeforeDST.AddDate(0, 0, 7),
+		},
+		{
+			name:     "Date-only UNTIL includes its last day",
+			rule:     "FREQ=DAILY;UNTIL=" + until,
+			start:    time.Date(year, time.December, 30, 9, 0, 0, 0, parisLoc),
+			from:     time.Date(year, time.December, 30, 9, 0, 0, 0, parisLoc),
+			expected: time.Date(year, time.December, 31, 9, 0, 0, 0, parisLoc),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := services.ParseRRule(tt.rule)
+			require.NoError(t, err)
+
+			result, err := services.GetNextOccurrenceInSeries(tt.from.UTC(), state,
+				&services.RecurrenceSeries{RRule: rule, Start: tt.start.UTC()}, "Europe/Paris")
+			require.NoError(t, err)
+			assert.True(t, result.Equal(tt.expected), "expected %v, got %v", tt.expected, result.In(parisLoc))
+		})
+	}
+}
+
+// Occurrences missed while the bot was down are skipped, like the other recurrences
+func TestRRuleSkipsMissedOccurrences(t *testing.T) {
+	parisLoc, _ := time.LoadLocation("Europe/Paris")
+	state := services.BuildRecurrenceState(services.RecurrenceRRule, false)
+	now := time.Now().In(parisLoc)
+	start := time.Date(now.Year(), now.Month(), now.Day()-10, 9, 0, 0, 0, parisLoc)
+	series := &services.RecurrenceSeries{RRule: "FREQ=DAILY", Start: start.UTC()}
+
+	next, err := services.GetNextOccurrenceInSeries(start.UTC(), state, series, "Europe/Paris")
+	require.NoError(t, err)
+	assert.True(t, next.After(now), "expected an occurrence after %v, got %v", now, next)
+	assert.True(t, next.Sub(now) <= 24*time.Hour+time.Hour, "expected the first occurrence after now, got %v", next)
+	assert.Equal(t, 9, next.In(parisLoc).Hour())
+}
+
+func TestRRuleEndOfSeries(t *testing.T) {
+	parisLoc, _ := time.LoadLocation("Europe/Paris")
+	state := services.BuildRecurrenceState(services.RecurrenceRRule, false)
+	start := time.Date(time.Now().Year()+1, time.June, 1, 9, 0, 0, 0, parisLoc)
+	rule := &services.RecurrenceSeries{RRule: "FREQ=DAILY;COUNT=2", Start: start.UTC()}
+
+	second, err := services.GetNextOccurrenceInSeries(start.UTC(), state, rule, "Europe/Paris")
+	require.NoError(t, err)
+	assert.True(t, second.Equal(start.AddDate(0, 0, 1)))
+
+	_, err = services.GetNextOccurrenceInSeries(second.UTC(), state, rule, "Europe/Paris")
+	assert.True(t, errors.Is(err, services.ErrRecurrenceEnded))
+
+	// The RRULE type cannot be evaluated without its rule
+	_, err = services.GetNextOccurrence(start.UTC(), state, "Europe/Paris")
+	assert.Error(t, err)
+}
+
+func TestParseRRule(t *testing.T) {
+	valid := []string{
+		"FREQ=WEEKLY;BYDAY=MO,WE,FR",
+		"RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+		"freq=yearly;bymonth=2;bymonthday=-1",
+	}
+	for _, rule := range valid {
+		_, err := services.ParseRRule(rule)
+		assert.NoError(t, err, rule)
+	}
+
+	invalid := []string{
+		"",
+		"BYDAY=MO",
+		"FREQ=MINUTELY",
+		"FREQ=DAILY;BYSECOND=10",
+		"DTSTART:20270101T090000Z\nRRULE:FREQ=DAILY",
+		"FREQ=MONTHLY;BYMONTHDAY=40",
+	}
+	for _, rule := range invalid {
+		_, err := services.ParseRRule(rule)
+		assert.Error(t, err, rule)
+	}
+
+	normalized, err := services.ParseRRule(" rrule:freq=daily;interval=3 ")
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=DAILY;INTERVAL=3", normalized)
+}
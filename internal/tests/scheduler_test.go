@@ -0,0 +1,209 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/dispatchers"
+	"github.com/ericp/chronos-bot-reminder/internal/engine"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSchedulerReminderRepository serves every reminder with a next fire to the
+// scheduler, without the destination filter of the query, and keeps its updates
+type fakeSchedulerReminderRepository struct {
+	repositories.ReminderRepository
+	mutex     sync.Mutex
+	reminders map[uuid.UUID]models.Reminder
+}
+
+func (f *fakeSchedulerReminderRepository) GetNextReminders() ([]models.Reminder, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var next []models.Reminder
+	for _, reminder := range f.reminders {
+		if reminder.NextFireUTC != nil {
+			next = append(next, reminder)
+		}
+	}
+	return next, nil
+}
+
+func (f *fakeSchedulerReminderRepository) Update(reminder *models.Reminder, notify bool) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.reminders[reminder.ID] = *reminder
+	return nil
+}
+
+func (f *fakeSchedulerReminderRepository) RescheduleReminder(reminder *models.Reminder, newTime time.Time, notify bool) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	reminder.RemindAtUTC = newTime
+	reminder.NextFireUTC = &newTime
+	f.reminders[reminder.ID] = *reminder
+	return nil
+}
+
+func (f *fakeSchedulerReminderRepository) get(id uuid.UUID) models.Reminder {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.reminders[id]
+}
+
+type schedulerFixture struct {
+	*outboxFixture
+	schedulerReminders *fakeSchedulerReminderRepository
+	scheduler          *engine.Scheduler
+}
+
+// newSchedulerFixture prepares a scheduler over the given reminders
+func newSchedulerFixture(reminders ...*models.Reminder) *schedulerFixture {
+	f := &schedulerFixture{
+		outboxFixture:      newOutboxFixture(),
+		schedulerReminders: &fakeSchedulerReminderRepository{reminders: map[uuid.UUID]models.Reminder{}},
+	}
+	for _, reminder := range reminders {
+		f.schedulerReminders.reminders[reminder.ID] = *reminder
+	}
+	f.scheduler = engine.NewScheduler(f.schedulerReminders, f.errors, f.registry, nil)
+	return f
+}
+
+func (f *schedulerFixture) start(t *testing.T) {
+	f.scheduler.Start(context.Background())
+	t.Cleanup(f.scheduler.Stop)
+}
+
+func TestFixedDestinationReceivesTheFireItMissed(t *testing.T) {
+	reminder := dueReminder(1)
+	destination := reminder.Destinations[0]
+	key := dispatchers.IdempotencyKey(reminder.ID, *reminder.NextFireUTC)
+	f := newSchedulerFixture(reminder)
+	f.dispatcher.fail(errors.New("webhook returned non-success status code: 404"))
+	f.start(t)
+
+	require.Eventually(t, func() bool {
+		unfixed, _ := f.errors.GetUnfixedByReminderDestinationID(destination.ID)
+		return len(unfixed) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.NotNil(t, f.schedulerReminders.get(reminder.ID).NextFireUTC, "nothing went out, the reminder stays due")
+
+	// The user fixes the destination and marks its error as fixed
+	f.dispatcher.fail(nil)
+	f.errors.fixAll()
+	f.scheduler.NotifyReminderUpdated(reminder.ID)
+
+	require.Eventually(t, func() bool {
+		return f.schedulerReminders.get(reminder.ID).NextFireUTC == nil
+	}, 2*time.Second, 10*time.Millisecond, "the one-time reminder is done once delivered")
+	assert.Equal(t, []string{key, key}, f.dispatcher.callsTo(destination.ID))
+	assert.Equal(t, models.DispatchStatusSent, f.outbox.status(key, destination.ID))
+}
+
+// blockingDispatcher holds every delivery until released
+type blockingDispatcher struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (d *blockingDispatcher) GetSupportedType() models.DestinationType {
+	return models.DestinationWebhook
+}
+
+func (d *blockingDispatcher) Dispatch(ctx context.Context, reminder *models.Reminder, destination *models.ReminderDestination, account *models.Account) (*models.DeliveryReceipt, error) {
+	d.entered <- struct{}{}
+	<-d.release
+	return nil, nil
+}
+
+func TestSchedulerStopWaitsForTheDeliveryInFlight(t *testing.T) {
+	reminder := dueReminder(1)
+	f := newSchedulerFixture(reminder)
+	dispatcher := &blockingDispatcher{entered: make(chan struct{}, 1), release: make(chan struct{})}
+	f.registry.RegisterDispatcher(dispatcher)
+	f.scheduler.Start(context.Background())
+
+	select {
+	case <-dispatcher.entered:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the reminder was not dispatched")
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		f.scheduler.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned while a delivery was in flight")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(dispatcher.release)
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stop did not return once the delivery was done")
+	}
+	assert.False(t, f.scheduler.IsRunning())
+}
+
+// recurringReminderDueNow returns a daily reminder due now with the given number of webhook destinations
+func recurringReminderDueNow(destinations int) *models.Reminder {
+	reminder := dueReminder(destinations)
+	now := time.Now().UTC().Truncate(time.Second)
+	reminder.RemindAtUTC = now
+	reminder.NextFireUTC = &now
+	reminder.Recurrence = services.RecurrenceDaily
+	return reminder
+}
+
+func TestBrokenDestinationDoesNotSilenceTheOthers(t *testing.T) {
+	reminder := recurringReminderDueNow(2)
+	broken, healthy := reminder.Destinations[0], reminder.Destinations[1]
+	fireTime := *reminder.NextFireUTC
+	f := newSchedulerFixture(reminder)
+	require.NoError(t, f.errors.Create(&models.ReminderError{ReminderID: reminder.ID, ReminderDestinationID: broken.ID, Stacktrace: "webhook returned non-success status code: 404"}))
+	f.start(t)
+
+	require.Eventually(t, func() bool {
+		next := f.schedulerReminders.get(reminder.ID).NextFireUTC
+		return next != nil && next.Equal(fireTime.AddDate(0, 0, 1))
+	}, 2*time.Second, 10*time.Millisecond, "the reminder moves on to its next occurrence")
+	assert.Len(t, f.dispatcher.callsTo(healthy.ID), 1)
+	assert.Empty(t, f.dispatcher.callsTo(broken.ID))
+}
+
+func TestReminderIsSkippedOnlyWhenEveryDestinationIsBroken(t *testing.T) {
+	reminder := recurringReminderDueNow(2)
+	fireTime := *reminder.NextFireUTC
+	f := newSchedulerFixture(reminder)
+	for _, destination := range reminder.Destinations {
+		require.NoError(t, f.errors.Create(&models.ReminderError{ReminderID: reminder.ID, ReminderDestinationID: destination.ID, Stacktrace: "webhook returned non-success status code: 404"}))
+	}
+	f.start(t)
+
+	assert.Never(t, func() bool {
+		return len(f.dispatcher.callsTo(reminder.Destinations[0].ID))+len(f.dispatcher.callsTo(reminder.Destinations[1].ID)) > 0
+	}, 300*time.Millisecond, 10*time.Millisecond)
+	assert.True(t, f.schedulerReminders.get(reminder.ID).NextFireUTC.Equal(fireTime), "the reminder stays due until a destination is fixed")
+
+	// Fixing one destination is enough for the reminder to fire again
+	f.errors.fixAll()
+	require.Eventually(t, func() bool {
+		return !f.schedulerReminders.get(reminder.ID).NextFireUTC.Equal(fireTime)
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Len(t, f.dispatcher.callsTo(reminder.Destinations[0].ID), 1)
+}
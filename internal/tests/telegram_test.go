@@ -0,0 +1,377 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/api"
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/database/repositories"
+	"github.com/ericp/chronos-bot-reminder/internal/dispatchers"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTelegramAPI records the Bot API calls and answers them like Telegram would
+type fakeTelegramAPI struct {
+	mu       sync.Mutex
+	calls    []fakeTelegramCall
+	failWith string // raw response body returned to every call when set
+	status   int
+}
+
+type fakeTelegramCall struct {
+	Method string
+	Params map[string]interface{}
+}
+
+func (f *fakeTelegramAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var params map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&params)
+	method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+
+	f.mu.Lock()
+	f.calls = append(f.calls, fakeTelegramCall{Method: method, Params: params})
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if f.failWith != "" {
+		w.WriteHeader(f.status)
+		w.Write([]byte(f.failWith))
+		return
+	}
+	if method == "sendMessage" {
+		w.Write([]byte(`{"ok": true, "result": {"message_id": 42, "chat": {"id": 100, "type": "private"}}}`))
+		return
+	}
+	w.Write([]byte(`{"ok": true, "result": true}`))
+}
+
+func (f *fakeTelegramAPI) callsTo(method string) []fakeTelegramCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var calls []fakeTelegramCall
+	for _, call := range f.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// fakeTelegramLinkCodeStore keeps the link codes in memory
+type fakeTelegramLinkCodeStore struct {
+	codes map[string]uuid.UUID
+}
+
+func (f *fakeTelegramLinkCodeStore) Save(code string, accountID uuid.UUID, ttl time.Duration) error {
+	f.codes[code] = accountID
+	return nil
+}
+
+func (f *fakeTelegramLinkCodeStore) Consume(code string) (uuid.UUID, error) {
+	accountID := f.codes[code]
+	delete(f.codes, code)
+	return accountID, nil
+}
+
+// fakeReminderRepository serves the reminders of the tests, the other methods are not used
+type fakeReminderRepository struct {
+	repositories.ReminderRepository
+	reminders map[uuid.UUID]*models.Reminder
+}
+
+func (f *fakeReminderRepository) GetByID(id uuid.UUID) (*models.Reminder, error) {
+	return f.reminders[id], nil
+}
+
+func (f *fakeReminderRepository) GetWithDestinations(id uuid.UUID) (*models.Reminder, error) {
+	return f.reminders[id], nil
+}
+
+func (f *fakeReminderRepository) GetNextsRemindersToDelete() ([]models.Reminder, error) {
+	return nil, nil
+}
+
+func (f *fakeReminderRepository) SnoozeReminder(reminder *models.Reminder, snoozeUntil time.Time) error {
+	reminder.SnoozedAtUTC = &snoozeUntil
+	reminder.NextFireUTC = &snoozeUntil
+	return nil
+}
+
+type telegramFixture struct {
+	api          *fakeTelegramAPI
+	identityRepo *fakeIdentityRepository
+	reminderRepo *fakeReminderRepository
+	codes        *fakeTelegramLinkCodeStore
+	service      *services.TelegramService
+	accountID    uuid.UUID
+}
+
+func newTelegramFixture(t *testing.T) *telegramFixture {
+	fake := &fakeTelegramAPI{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	fixture := &telegramFixture{
+		api:          fake,
+		identityRepo: &fakeIdentityRepository{},
+		reminderRepo: &fakeReminderRepository{reminders: map[uuid.UUID]*models.Reminder{}},
+		codes:        &fakeTelegramLinkCodeStore{codes: map[string]uuid.UUID{}},
+		accountID:    uuid.New(),
+	}
+	fixture.service = services.NewTelegramService(
+		services.NewTelegramClient(server.URL, "123:token"),
+		fixture.identityRepo,
+		fixture.reminderRepo,
+		fixture.codes,
+		"@ChronosBot",
+	)
+	return fixture
+}
+
+func (f *telegramFixture) linkChat(chatID string) {
+	f.identityRepo.Create(&models.Identity{AccountID: f.accountID, Provider: models.ProviderTelegram, ExternalID: chatID})
+}
+
+func TestTelegramDestinationValidation(t *testing.T) {
+	destination := &models.ReminderDestination{Type: models.DestinationTelegram, Metadata: models.JSONB{}}
+	assert.Error(t, destination.ValidateMetadata())
+
+	destination.Metadata["chat_id"] = 100
+	assert.Error(t, destination.ValidateMetadata(), "chat IDs are stored as strings")
+
+	destination.Metadata["chat_id"] = "-100123"
+	assert.NoError(t, destination.ValidateMetadata())
+}
+
+func TestTelegramDispatcher(t *testing.T) {
+	fixture := newTelegramFixture(t)
+	fixture.linkChat("100")
+	dispatcher := dispatchers.NewTelegramDispatcher(fixture.service)
+
+	reminder := &models.Reminder{
+		ID:          uuid.New(),
+		AccountID:   fixture.accountID,
+		Message:     "Pay <rent> & bills",
+		RemindAtUTC: time.Date(2030, time.June, 3, 7, 0, 0, 0, time.UTC),
+	}
+	account := &models.Account{Timezone: &models.Timezone{IANALocation: "Europe/Paris"}}
+	destination := &models.ReminderDestination{Type: models.DestinationTelegram, Metadata: models.JSONB{"chat_id": "100"}}
+
+	t.Run("Sends the reminder with snooze buttons", func(t *testing.T) {
+		receipt, err := dispatcher.Dispatch(context.Background(), reminder, destination, account)
+		require.NoError(t, err)
+		assert.Equal(t, "42", receipt.MessageID)
+
+		calls := fixture.api.callsTo("sendMessage")
+		require.Len(t, calls, 1)
+		params := calls[0].Params
+		assert.Equal(t, "100", params["chat_id"])
+		assert.Equal(t, "HTML", params["parse_mode"])
+		assert.Contains(t, params["text"], "Pay &lt;rent&gt; &amp; bills")
+		assert.Contains(t, params["text"], "09:00", "the time is shown in the account timezone")
+
+		keyboard := params["reply_markup"].(map[string]interface{})["inline_keyboard"].([]interface{})
+		buttons := keyboard[0].([]interface{})
+		require.Len(t, buttons, len(services.TelegramSnoozeOptions))
+		data := buttons[1].(map[string]interface{})["callback_data"].(string)
+		reminderID, minutes, ok := services.ParseTelegramSnoozeCallback(data)
+		assert.True(t, ok)
+		assert.Equal(t, reminder.ID, reminderID)
+		assert.Equal(t, 60, minutes)
+	})
+
+	t.Run("Chat must be linked to the account", func(t *testing.T) {
+		other := &models.ReminderDestination{Type: models.DestinationTelegram, Metadata: models.JSONB{"chat_id": "999"}}
+		_, err := dispatcher.Dispatch(context.Background(), reminder, other, account)
+		assert.ErrorIs(t, err, services.ErrTelegramChatNotLinked)
+	})
+
+	t.Run("Rate limits are retried after the requested delay", func(t *testing.T) {
+		fixture.api.status = http.StatusTooManyRequests
+		fixture.api.failWith = `{"ok": false, "error_code": 429, "description": "Too Many Requests: retry after 7", "parameters": {"retry_after": 7}}`
+		defer func() { fixture.api.failWith = "" }()
+
+		_, err := dispatcher.Dispatch(context.Background(), reminder, destination, account)
+		transientErr, ok := dispatchers.AsTransient(err)
+		require.True(t, ok)
+		assert.Equal(t, 7*time.Second, transientErr.RetryAfter)
+		assert.NotContains(t, err.Error(), "123:token")
+	})
+
+	t.Run("Blocked bot is not retried", func(t *testing.T) {
+		fixture.api.status = http.StatusForbidden
+		fixture.api.failWith = `{"ok": false, "error_code": 403, "description": "Forbidden: bot was blocked by the user"}`
+		defer func() { fixture.api.failWith = "" }()
+
+		_, err := dispatcher.Dispatch(context.Background(), reminder, destination, account)
+		require.Error(t, err)
+		_, transient := dispatchers.AsTransient(err)
+		assert.False(t, transient)
+	})
+}
+
+func TestTelegramChatLinking(t *testing.T) {
+	fixture := newTelegramFixture(t)
+
+	code, err := fixture.service.CreateLinkCode(fixture.accountID)
+	require.NoError(t, err)
+	assert.Equal(t, "https://t.me/ChronosBot?start="+code.Code, code.Link)
+
+	start := &services.TelegramUpdate{Message: &services.TelegramMessage{
+		Chat: services.TelegramChat{ID: 100, Type: "private"},
+		From: &services.TelegramUser{ID: 100, Username: "jane"},
+		Text: "/start " + code.Code,
+	}}
+	require.NoError(t, fixture.service.HandleUpdate(context.Background(), start))
+
+	chats, err := fixture.service.LinkedChats(fixture.accountID)
+	require.NoError(t, err)
+	require.Len(t, chats, 1)
+	assert.Equal(t, "100", chats[0].ExternalID)
+	assert.Equal(t, "@jane", *chats[0].Username)
+
+	// The code is single use
+	group := &services.TelegramUpdate{Message: &services.TelegramMessage{
+		Chat: services.TelegramChat{ID: -200, Type: "group", Title: "Family"},
+		Text: "/link@ChronosBot " + strings.ToLower(code.Code),
+	}}
+	require.NoError(t, fixture.service.HandleUpdate(context.Background(), group))
+	chats, _ = fixture.service.LinkedChats(fixture.accountID)
+	assert.Len(t, chats, 1)
+
+	replies := fixture.api.callsTo("sendMessage")
+	require.Len(t, replies, 2)
+	assert.Contains(t, replies[1].Params["text"], "invalid or has expired")
+
+	// A linked chat cannot be taken over by another account
+	otherCode, err := fixture.service.CreateLinkCode(uuid.New())
+	require.NoError(t, err)
+	start.Message.Text = "/link " + otherCode.Code
+	require.NoError(t, fixture.service.HandleUpdate(context.Background(), start))
+	identity, _ := fixture.identityRepo.GetByProviderAndExternalID(models.ProviderTelegram, "100")
+	assert.Equal(t, fixture.accountID, identity.AccountID)
+
+	require.NoError(t, fixture.service.UnlinkChat(fixture.accountID, "100"))
+	assert.ErrorIs(t, fixture.service.UnlinkChat(fixture.accountID, "100"), services.ErrTelegramChatNotLinked)
+}
+
+func TestTelegramSnoozeButtons(t *testing.T) {
+	fixture := newTelegramFixture(t)
+	fixture.linkChat("100")
+
+	newReminder := func() *models.Reminder {
+		reminder := &models.Reminder{
+			ID:           uuid.New(),
+			AccountID:    fixture.accountID,
+			Message:      "Stretch",
+			Destinations: []models.ReminderDestination{{Type: models.DestinationTelegram, Metadata: models.JSONB{"chat_id": "100"}}},
+		}
+		fixture.reminderRepo.reminders[reminder.ID] = reminder
+		return reminder
+	}
+	press := func(chatID int64, data string) *services.TelegramUpdate {
+		return &services.TelegramUpdate{CallbackQuery: &services.TelegramCallbackQuery{
+			ID:      uuid.NewString(),
+			Data:    data,
+			Message: &services.TelegramMessage{MessageID: 42, Chat: services.TelegramChat{ID: chatID}},
+		}}
+	}
+	lastAnswer := func() string {
+		answers := fixture.api.callsTo("answerCallbackQuery")
+		require.NotEmpty(t, answers)
+		return answers[len(answers)-1].Params["text"].(string)
+	}
+
+	t.Run("Snoozes the reminder and removes the buttons", func(t *testing.T) {
+		reminder := newReminder()
+		before := time.Now()
+		require.NoError(t, fixture.service.HandleUpdate(context.Background(), press(100, "snooze:"+reminder.ID.String()+":60")))
+
+		require.NotNil(t, reminder.SnoozedAtUTC)
+		assert.WithinDuration(t, before.Add(time.Hour), *reminder.SnoozedAtUTC, 5*time.Second)
+		assert.Contains(t, lastAnswer(), "1 hour")
+		assert.Len(t, fixture.api.callsTo("editMessageReplyMarkup"), 1)
+
+		// Pressing again keeps the first snooze
+		snoozedUntil := *reminder.SnoozedAtUTC
+		require.NoError(t, fixture.service.HandleUpdate(context.Background(), press(100, "snooze:"+reminder.ID.String()+":10")))
+		assert.Equal(t, snoozedUntil, *reminder.SnoozedAtUTC)
+		assert.Contains(t, lastAnswer(), "already snoozed")
+	})
+
+	t.Run("Other chats cannot snooze", func(t *testing.T) {
+		reminder := newReminder()
+		require.NoError(t, fixture.service.HandleUpdate(context.Background(), press(-300, "snooze:"+reminder.ID.String()+":60")))
+		assert.Nil(t, reminder.SnoozedAtUTC)
+		assert.Contains(t, lastAnswer(), "permission")
+	})
+
+	t.Run("Only the offered durations are accepted", func(t *testing.T) {
+		reminder := newReminder()
+		for _, data := range []string{"snooze:" + reminder.ID.String() + ":999999", "snooze:not-a-uuid:60", "other"} {
+			require.NoError(t, fixture.service.HandleUpdate(context.Background(), press(100, data)))
+		}
+		assert.Nil(t, reminder.SnoozedAtUTC)
+	})
+}
+
+func TestTelegramWebhookSecret(t *testing.T) {
+	fixture := newTelegramFixture(t)
+	handler := api.NewTelegramHandler(fixture.service, "s3cret")
+
+	body := `{"update_id": 1, "message": {"message_id": 1, "chat": {"id": 100, "type": "private"}, "text": "/start"}}`
+	tests := []struct {
+		name     string
+		secret   string
+		expected int
+	}{
+		{"Missing secret", "", http.StatusUnauthorized},
+		{"Wrong secret", "guess", http.StatusUnauthorized},
+		{"Valid secret", "s3cret", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/api/telegram/webhook", strings.NewReader(body))
+			if tt.secret != "" {
+				request.Header.Set(api.TelegramSecretTokenHeader, tt.secret)
+			}
+			recorder := httptest.NewRecorder()
+			handler.HandleWebhook(recorder, request)
+			assert.Equal(t, tt.expected, recorder.Code)
+		})
+	}
+
+	assert.Len(t, fixture.api.callsTo("sendMessage"), 1, "only the authenticated update is handled")
+}
+
+func TestTelegramLogsCarryTheCorrelationID(t *testing.T) {
+	fixture := newTelegramFixture(t)
+	buf := captureLogs(t, slog.LevelInfo)
+
+	code, err := fixture.service.CreateLinkCode(fixture.accountID)
+	require.NoError(t, err)
+	ctx := logging.WithCorrelationID(context.Background(), "update-42")
+	require.NoError(t, fixture.service.HandleUpdate(ctx, &services.TelegramUpdate{Message: &services.TelegramMessage{
+		Chat: services.TelegramChat{ID: 100, Type: "private"},
+		Text: "/link " + code.Code,
+	}}))
+
+	records := logRecords(t, buf)
+	require.Len(t, records, 1)
+	assert.Equal(t, "Telegram chat linked", records[0]["msg"])
+	assert.Equal(t, "update-42", records[0]["correlation_id"])
+	assert.Equal(t, fixture.accountID.String(), records[0]["account_id"])
+}
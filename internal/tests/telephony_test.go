@@ -0,0 +1,334 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/dispatchers"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTwilioAPI records the messages and calls created through the Twilio API
+type fakeTwilioAPI struct {
+	mu       sync.Mutex
+	requests []url.Values
+	paths    []string
+	status   int // response status when set, 201 otherwise
+}
+
+func (f *fakeTwilioAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, password, _ := r.BasicAuth()
+	if user != "AC123" || password != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	r.ParseForm()
+
+	f.mu.Lock()
+	f.requests = append(f.requests, r.PostForm)
+	f.paths = append(f.paths, r.URL.Path)
+	status := f.status
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch status {
+	case 0:
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid": "SM0001", "status": "queued"}`))
+	case http.StatusTooManyRequests:
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"code": 20429, "message": "Too Many Requests", "status": 429}`))
+	default:
+		w.WriteHeader(status)
+		w.Write([]byte(`{"code": 21211, "message": "The 'To' number is not a valid phone number.", "status": 400}`))
+	}
+}
+
+func (f *fakeTwilioAPI) last() (string, url.Values) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.paths[len(f.paths)-1], f.requests[len(f.requests)-1]
+}
+
+// fakePhoneNumberRepository keeps the phone numbers in memory
+type fakePhoneNumberRepository struct {
+	phoneNumbers []models.PhoneNumber
+}
+
+func (f *fakePhoneNumberRepository) Create(phoneNumber *models.PhoneNumber) error {
+	phoneNumber.ID = uuid.New()
+	f.phoneNumbers = append(f.phoneNumbers, *phoneNumber)
+	return nil
+}
+
+func (f *fakePhoneNumberRepository) GetByAccountAndNumber(accountID uuid.UUID, number string) (*models.PhoneNumber, error) {
+	for _, phoneNumber := range f.phoneNumbers {
+		if phoneNumber.AccountID == accountID && phoneNumber.Number == number {
+			return &phoneNumber, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakePhoneNumberRepository) GetByAccountID(accountID uuid.UUID) ([]models.PhoneNumber, error) {
+	var phoneNumbers []models.PhoneNumber
+	for _, phoneNumber := range f.phoneNumbers {
+		if phoneNumber.AccountID == accountID {
+			phoneNumbers = append(phoneNumbers, phoneNumber)
+		}
+	}
+	return phoneNumbers, nil
+}
+
+func (f *fakePhoneNumberRepository) Update(phoneNumber *models.PhoneNumber) error {
+	for i := range f.phoneNumbers {
+		if f.phoneNumbers[i].ID == phoneNumber.ID {
+			f.phoneNumbers[i] = *phoneNumber
+		}
+	}
+	return nil
+}
+
+func (f *fakePhoneNumberRepository) Delete(id uuid.UUID) error {
+	for i := range f.phoneNumbers {
+		if f.phoneNumbers[i].ID == id {
+			f.phoneNumbers = append(f.phoneNumbers[:i], f.phoneNumbers[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// fakeTelephonyUsageRepository counts the sends per account, channel and month
+type fakeTelephonyUsageRepository struct {
+	counts map[string]int
+}
+
+func (f *fakeTelephonyUsageRepository) key(accountID uuid.UUID, month string, channel models.DestinationType) string {
+	return accountID.String() + "/" + month + "/" + string(channel)
+}
+
+func (f *fakeTelephonyUsageRepository) GetByAccountAndMonth(accountID uuid.UUID, month string) (*models.TelephonyUsage, error) {
+	return &models.TelephonyUsage{
+		AccountID:  accountID,
+		Month:      month,
+		SMSCount:   f.counts[f.key(accountID, month, models.DestinationSMS)],
+		VoiceCount: f.counts[f.key(accountID, month, models.DestinationVoice)],
+	}, nil
+}
+
+func (f *fakeTelephonyUsageRepository) Reserve(accountID uuid.UUID, month string, channel models.DestinationType, limit int) (bool, error) {
+	key := f.key(accountID, month, channel)
+	if f.counts[key] >= limit {
+		return false, nil
+	}
+	f.counts[key]++
+	return true, nil
+}
+
+func (f *fakeTelephonyUsageRepository) Release(accountID uuid.UUID, month string, channel models.DestinationType) error {
+	f.counts[f.key(accountID, month, channel)]--
+	return nil
+}
+
+func newTelephonyFixture(t *testing.T, quota services.TelephonyQuota) (*fakeTwilioAPI, *fakePhoneNumberRepository, *services.TelephonyService) {
+	fake := &fakeTwilioAPI{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	phoneNumberRepo := &fakePhoneNumberRepository{}
+	service := services.NewTelephonyService(
+		services.NewTwilioProvider(server.URL, "AC123", "secret", "+15005550006"),
+		phoneNumberRepo,
+		&fakeTelephonyUsageRepository{counts: map[string]int{}},
+		quota,
+	)
+	return fake, phoneNumberRepo, service
+}
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		valid    bool
+	}{
+		{"+33 6 12 34 56 78", "+33612345678", true},
+		{"0033 (6) 12-34-56-78", "+33612345678", true},
+		{"+1.415.555.2671", "+14155552671", true},
+		{"0612345678", "", false},
+		{"+0612345678", "", false},
+		{"+33 6 12 34 56 78 90 12 34", "", false},
+		{"+33abc", "", false},
+	}
+
+	for _, tt := range tests {
+		normalized, valid := models.NormalizePhoneNumber(tt.input)
+		assert.Equal(t, tt.valid, valid, tt.input)
+		assert.Equal(t, tt.expected, normalized, tt.input)
+	}
+
+	destination := &models.ReminderDestination{Type: models.DestinationSMS, Metadata: models.JSONB{"phone_number": "06 12 34 56 78"}}
+	assert.Error(t, destination.ValidateMetadata())
+	destination.Metadata["phone_number"] = "+33612345678"
+	assert.NoError(t, destination.ValidateMetadata())
+}
+
+func TestPhoneNumberVerification(t *testing.T) {
+	fake, phoneNumberRepo, service := newTelephonyFixture(t, services.TelephonyQuota{SMS: 10, Voice: 10})
+	accountID := uuid.New()
+
+	phoneNumber, err := service.StartPhoneVerification(context.Background(), accountID, "+33 6 12 34 56 78")
+	require.NoError(t, err)
+	assert.Equal(t, "+33612345678", phoneNumber.Number)
+	assert.False(t, phoneNumber.IsVerified())
+
+	path, form := fake.last()
+	assert.Equal(t, "/2010-04-01/Accounts/AC123/Messages.json", path)
+	assert.Equal(t, "+33612345678", form.Get("To"))
+	assert.Equal(t, "+15005550006", form.Get("From"))
+	code := regexp.MustCompile(`\d{6}`).FindString(form.Get("Body"))
+	require.NotEmpty(t, code)
+	assert.NotContains(t, *phoneNumberRepo.phoneNumbers[0].CodeHash, code, "the code is stored hashed")
+
+	_, err = service.StartPhoneVerification(context.Background(), accountID, "+33612345678")
+	assert.ErrorIs(t, err, services.ErrPhoneVerificationCooldown)
+
+	verified, err := service.IsPhoneNumberVerified(accountID, "+33612345678")
+	require.NoError(t, err)
+	assert.False(t, verified)
+
+	_, err = service.ConfirmPhoneVerification(context.Background(), accountID, "+33612345678", "000000x")
+	assert.ErrorIs(t, err, services.ErrInvalidPhoneVerificationCode)
+	_, err = service.ConfirmPhoneVerification(context.Background(), uuid.New(), "+33612345678", code)
+	assert.ErrorIs(t, err, services.ErrPhoneNumberNotFound, "codes are scoped to the account")
+
+	phoneNumber, err = service.ConfirmPhoneVerification(context.Background(), accountID, "0033612345678", code)
+	require.NoError(t, err)
+	assert.True(t, phoneNumber.IsVerified())
+
+	verified, err = service.IsPhoneNumberVerified(accountID, "+33612345678")
+	require.NoError(t, err)
+	assert.True(t, verified)
+
+	_, err = service.StartPhoneVerification(context.Background(), accountID, "+33612345678")
+	assert.ErrorIs(t, err, services.ErrPhoneNumberAlreadyVerified)
+
+	t.Run("Too many wrong codes", func(t *testing.T) {
+		_, err := service.StartPhoneVerification(context.Background(), accountID, "+14155552671")
+		require.NoError(t, err)
+		_, form := fake.last()
+		code := regexp.MustCompile(`\d{6}`).FindString(form.Get("Body"))
+
+		for i := 0; i < services.MaxPhoneVerificationAttempts; i++ {
+			_, err := service.ConfirmPhoneVerification(context.Background(), accountID, "+14155552671", "wrong")
+			assert.ErrorIs(t, err, services.ErrInvalidPhoneVerificationCode)
+		}
+		_, err = service.ConfirmPhoneVerification(context.Background(), accountID, "+14155552671", code)
+		assert.ErrorIs(t, err, services.ErrTooManyPhoneVerificationAttempts)
+	})
+}
+
+func TestTelephonyQuota(t *testing.T) {
+	fake, _, service := newTelephonyFixture(t, services.TelephonyQuota{SMS: 2, Voice: 1})
+	accountID := uuid.New()
+
+	_, err := service.SendSMS(context.Background(), accountID, "+33612345678", "First")
+	require.NoError(t, err)
+
+	// A failed send does not use the quota
+	fake.status = http.StatusBadRequest
+	_, err = service.SendSMS(context.Background(), accountID, "+33612345678", "Rejected")
+	require.Error(t, err)
+	fake.status = 0
+
+	_, err = service.SendSMS(context.Background(), accountID, "+33612345678", "Second")
+	require.NoError(t, err)
+	_, err = service.SendSMS(context.Background(), accountID, "+33612345678", "Third")
+	assert.ErrorIs(t, err, services.ErrTelephonyQuotaExceeded)
+
+	// Each channel has its own quota
+	_, err = service.Call(context.Background(), accountID, "+33612345678", "Wake up")
+	require.NoError(t, err)
+
+	usage, err := service.Usage(accountID)
+	require.NoError(t, err)
+	assert.Equal(t, time.Now().UTC().Format("2006-01"), usage.Month)
+	assert.Equal(t, 2, usage.SMSUsed)
+	assert.Equal(t, 1, usage.VoiceUsed)
+	assert.Equal(t, 1, usage.VoiceQuota)
+}
+
+func TestTelephonyDispatchers(t *testing.T) {
+	fake, phoneNumberRepo, service := newTelephonyFixture(t, services.TelephonyQuota{SMS: 10, Voice: 1})
+	accountID := uuid.New()
+	verifiedAt := time.Now()
+	phoneNumberRepo.phoneNumbers = []models.PhoneNumber{{ID: uuid.New(), AccountID: accountID, Number: "+33612345678", VerifiedAt: &verifiedAt}}
+
+	reminder := &models.Reminder{ID: uuid.New(), AccountID: accountID, Message: "Take <meds> & water"}
+	sms := &models.ReminderDestination{Type: models.DestinationSMS, Metadata: models.JSONB{"phone_number": "+33612345678"}}
+	voice := &models.ReminderDestination{Type: models.DestinationVoice, Metadata: models.JSONB{"phone_number": "+33612345678"}}
+
+	t.Run("SMS", func(t *testing.T) {
+		receipt, err := dispatchers.NewSMSDispatcher(service).Dispatch(context.Background(), reminder, sms, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "SM0001", receipt.MessageID)
+
+		_, form := fake.last()
+		assert.Contains(t, form.Get("Body"), "Take <meds> & water")
+	})
+
+	t.Run("Voice reads the escaped message", func(t *testing.T) {
+		_, err := dispatchers.NewVoiceDispatcher(service).Dispatch(context.Background(), reminder, voice, nil)
+		require.NoError(t, err)
+
+		path, form := fake.last()
+		assert.True(t, strings.HasSuffix(path, "/Calls.json"))
+		assert.Contains(t, form.Get("Twiml"), "Take &lt;meds&gt; &amp; water")
+	})
+
+	t.Run("Quota exceeded is not retried", func(t *testing.T) {
+		_, err := dispatchers.NewVoiceDispatcher(service).Dispatch(context.Background(), reminder, voice, nil)
+		assert.ErrorIs(t, err, services.ErrTelephonyQuotaExceeded)
+		_, transient := dispatchers.AsTransient(err)
+		assert.False(t, transient)
+	})
+
+	t.Run("Unverified numbers are refused", func(t *testing.T) {
+		other := &models.ReminderDestination{Type: models.DestinationSMS, Metadata: models.JSONB{"phone_number": "+14155552671"}}
+		_, err := dispatchers.NewSMSDispatcher(service).Dispatch(context.Background(), reminder, other, nil)
+		assert.ErrorIs(t, err, services.ErrPhoneNumberNotVerified)
+	})
+
+	t.Run("Rate limits are retried", func(t *testing.T) {
+		fake.status = http.StatusTooManyRequests
+		defer func() { fake.status = 0 }()
+
+		_, err := dispatchers.NewSMSDispatcher(service).Dispatch(context.Background(), reminder, sms, nil)
+		transientErr, ok := dispatchers.AsTransient(err)
+		require.True(t, ok)
+		assert.Equal(t, 30*time.Second, transientErr.RetryAfter)
+	})
+
+	t.Run("Invalid numbers are not retried", func(t *testing.T) {
+		fake.status = http.StatusBadRequest
+		defer func() { fake.status = 0 }()
+
+		_, err := dispatchers.NewSMSDispatcher(service).Dispatch(context.Background(), reminder, sms, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "21211")
+		_, transient := dispatchers.AsTransient(err)
+		assert.False(t, transient)
+	})
+}
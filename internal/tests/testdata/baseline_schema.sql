@@ -0,0 +1,46 @@
+-- Schema of a database created by the last release migrated with GORM AutoMigrate,
+-- as that release ran it on boot: enum types, AutoMigrate, then the identity index.
+
+DO $$ BEGIN
+	CREATE TYPE provider_type AS ENUM ('discord', 'app', 'api_key', 'mobile');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+
+DO $$ BEGIN
+	CREATE TYPE destination_type AS ENUM ('discord_dm', 'discord_channel', 'webhook', 'email', 'android_push');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE "timezones" ("id" bigserial,"name" varchar(100) NOT NULL,"gmt_offset" decimal(4,2) NOT NULL,"iana_location" varchar(50) NOT NULL,PRIMARY KEY ("id"));
+CREATE TABLE "accounts" ("id" uuid DEFAULT gen_random_uuid(),"timezone_id" bigint,"email" text,"username" text,"password_hash" text,"email_verified" boolean DEFAULT false,"created_at" timestamptz NOT NULL DEFAULT now(),"updated_at" timestamptz NOT NULL DEFAULT now(),PRIMARY KEY ("id"),CONSTRAINT "fk_timezones_accounts" FOREIGN KEY ("timezone_id") REFERENCES "timezones"("id"));
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_accounts_email" ON "accounts" ("email");
+CREATE INDEX IF NOT EXISTS "idx_accounts_timezone_id" ON "accounts" ("timezone_id");
+CREATE TABLE "identities" ("id" uuid DEFAULT gen_random_uuid(),"account_id" uuid NOT NULL,"provider" provider_type NOT NULL,"external_id" text NOT NULL,"username" text,"avatar" text,"access_token" text,"refresh_token" text,"scopes" text,"created_at" timestamptz NOT NULL DEFAULT now(),PRIMARY KEY ("id"),CONSTRAINT "fk_accounts_identities" FOREIGN KEY ("account_id") REFERENCES "accounts"("id") ON DELETE CASCADE);
+CREATE INDEX IF NOT EXISTS "idx_identities_account_id" ON "identities" ("account_id");
+CREATE TABLE "reminders" ("id" uuid DEFAULT gen_random_uuid(),"account_id" uuid NOT NULL,"remind_at_utc" timestamptz NOT NULL,"snoozed_at_utc" timestamptz DEFAULT null,"next_fire_utc" timestamptz DEFAULT null,"message" text NOT NULL,"created_at" timestamptz NOT NULL DEFAULT now(),"recurrence" smallint NOT NULL DEFAULT 0,PRIMARY KEY ("id"),CONSTRAINT "fk_accounts_reminders" FOREIGN KEY ("account_id") REFERENCES "accounts"("id") ON DELETE CASCADE);
+CREATE INDEX IF NOT EXISTS "idx_reminders_account_id" ON "reminders" ("account_id");
+CREATE TABLE "reminder_destinations" ("id" uuid DEFAULT gen_random_uuid(),"reminder_id" uuid NOT NULL,"type" destination_type NOT NULL,"metadata" jsonb NOT NULL,PRIMARY KEY ("id"),CONSTRAINT "fk_reminders_destinations" FOREIGN KEY ("reminder_id") REFERENCES "reminders"("id") ON DELETE CASCADE);
+CREATE INDEX IF NOT EXISTS "idx_reminder_destinations_reminder_id" ON "reminder_destinations" ("reminder_id");
+CREATE TABLE "reminder_errors" ("id" uuid DEFAULT gen_random_uuid(),"reminder_id" uuid NOT NULL,"reminder_destination_id" uuid NOT NULL,"timestamp" timestamptz NOT NULL DEFAULT now(),"stacktrace" text NOT NULL,"fixed" boolean NOT NULL DEFAULT false,PRIMARY KEY ("id"),CONSTRAINT "fk_reminder_errors_reminder" FOREIGN KEY ("reminder_id") REFERENCES "reminders"("id") ON DELETE CASCADE,CONSTRAINT "fk_reminder_errors_reminder_destination" FOREIGN KEY ("reminder_destination_id") REFERENCES "reminder_destinations"("id") ON DELETE CASCADE);
+CREATE INDEX IF NOT EXISTS "idx_reminder_errors_reminder_destination_id" ON "reminder_errors" ("reminder_destination_id");
+CREATE INDEX IF NOT EXISTS "idx_reminder_errors_reminder_id" ON "reminder_errors" ("reminder_id");
+CREATE TABLE "email_verifications" ("id" uuid DEFAULT gen_random_uuid(),"account_id" uuid NOT NULL,"email" text NOT NULL,"code" text NOT NULL,"verified" boolean DEFAULT false,"expires_at" timestamp NOT NULL,"created_at" timestamp NOT NULL DEFAULT now(),"verified_at" timestamp DEFAULT null,PRIMARY KEY ("id"),CONSTRAINT "fk_email_verifications_account" FOREIGN KEY ("account_id") REFERENCES "accounts"("id") ON DELETE CASCADE);
+CREATE INDEX IF NOT EXISTS "idx_email_verifications_expires_at" ON "email_verifications" ("expires_at");
+CREATE INDEX IF NOT EXISTS "idx_email_verifications_email" ON "email_verifications" ("email");
+CREATE INDEX IF NOT EXISTS "idx_email_verifications_account_id" ON "email_verifications" ("account_id");
+CREATE TABLE "password_resets" ("id" uuid DEFAULT gen_random_uuid(),"account_id" uuid NOT NULL,"email" text NOT NULL,"token" text NOT NULL,"used" boolean DEFAULT false,"expires_at" timestamp NOT NULL,"created_at" timestamp NOT NULL DEFAULT now(),"used_at" timestamp DEFAULT null,PRIMARY KEY ("id"),CONSTRAINT "fk_password_resets_account" FOREIGN KEY ("account_id") REFERENCES "accounts"("id") ON DELETE CASCADE,CONSTRAINT "uni_password_resets_token" UNIQUE ("token"));
+CREATE INDEX IF NOT EXISTS "idx_password_resets_expires_at" ON "password_resets" ("expires_at");
+CREATE INDEX IF NOT EXISTS "idx_password_resets_token" ON "password_resets" ("token");
+CREATE INDEX IF NOT EXISTS "idx_password_resets_email" ON "password_resets" ("email");
+CREATE INDEX IF NOT EXISTS "idx_password_resets_account_id" ON "password_resets" ("account_id");
+CREATE TABLE "dfm_notes" ("id" uuid DEFAULT gen_random_uuid(),"account_id" uuid NOT NULL,"remind_at_utc" timestamptz DEFAULT null,"next_fire_utc" timestamptz DEFAULT null,"recurrence" smallint NOT NULL DEFAULT 0,"send_discord_dm" boolean NOT NULL DEFAULT true,"send_email" boolean NOT NULL DEFAULT false,"last_sent_at" timestamptz DEFAULT null,"created_at" timestamptz NOT NULL DEFAULT now(),"updated_at" timestamptz NOT NULL DEFAULT now(),PRIMARY KEY ("id"),CONSTRAINT "fk_dfm_notes_account" FOREIGN KEY ("account_id") REFERENCES "accounts"("id") ON DELETE CASCADE);
+CREATE INDEX IF NOT EXISTS "idx_dfm_notes_next_fire_utc" ON "dfm_notes" ("next_fire_utc");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_dfm_notes_account_id" ON "dfm_notes" ("account_id");
+CREATE TABLE "dfm_items" ("id" uuid DEFAULT gen_random_uuid(),"note_id" uuid NOT NULL,"content" text NOT NULL,"checked" boolean NOT NULL DEFAULT false,"position" bigint NOT NULL DEFAULT 0,"created_at" timestamptz NOT NULL DEFAULT now(),"updated_at" timestamptz NOT NULL DEFAULT now(),PRIMARY KEY ("id"),CONSTRAINT "fk_dfm_notes_items" FOREIGN KEY ("note_id") REFERENCES "dfm_notes"("id") ON DELETE CASCADE);
+CREATE INDEX IF NOT EXISTS "idx_dfm_items_note_id" ON "dfm_items" ("note_id");
+CREATE TABLE "fcm_tokens" ("id" uuid DEFAULT gen_random_uuid(),"account_id" uuid NOT NULL,"token" text NOT NULL,"device_id" text NOT NULL,"created_at" timestamptz NOT NULL DEFAULT now(),"updated_at" timestamptz NOT NULL DEFAULT now(),PRIMARY KEY ("id"),CONSTRAINT "fk_fcm_tokens_account" FOREIGN KEY ("account_id") REFERENCES "accounts"("id") ON DELETE CASCADE);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_fcm_tokens_token" ON "fcm_tokens" ("token");
+CREATE INDEX IF NOT EXISTS "idx_fcm_tokens_account_id" ON "fcm_tokens" ("account_id");
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_identities_provider_external_id
+	ON identities(provider, external_id);
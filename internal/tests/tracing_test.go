@@ -0,0 +1,199 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/api"
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/dispatchers"
+	"github.com/ericp/chronos-bot-reminder/internal/engine"
+	"github.com/ericp/chronos-bot-reminder/internal/tracing"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	spanExporterOnce sync.Once
+	spanExporter     *tracetest.InMemoryExporter
+	spanProvider     *sdktrace.TracerProvider
+)
+
+// captureSpans records the spans ended from now on. The provider is installed once:
+// the instrumented HTTP transports keep the provider they were created with.
+func captureSpans(t *testing.T) func() tracetest.SpanStubs {
+	t.Helper()
+
+	spanExporterOnce.Do(func() {
+		spanExporter = tracetest.NewInMemoryExporter()
+		spanProvider = tracing.Install(spanExporter, "chronos-test", 1)
+	})
+	require.NoError(t, spanProvider.ForceFlush(context.Background()))
+	spanExporter.Reset()
+
+	return func() tracetest.SpanStubs {
+		require.NoError(t, spanProvider.ForceFlush(context.Background()))
+		return spanExporter.GetSpans()
+	}
+}
+
+// spansNamed returns the recorded spans with the given name
+func spansNamed(spans tracetest.SpanStubs, name string) []tracetest.SpanStub {
+	var found []tracetest.SpanStub
+	for _, span := range spans {
+		if span.Name == name {
+			found = append(found, span)
+		}
+	}
+	return found
+}
+
+// spanAttribute returns the value of an attribute of the span, as a string
+func spanAttribute(span tracetest.SpanStub, key string) string {
+	for _, kv := range span.Attributes {
+		if kv.Key == attribute.Key(key) {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func TestTracingMiddlewareNamesTheSpanAfterTheRoute(t *testing.T) {
+	spans := captureSpans(t)
+
+	mux := api.NewWrappedMux()
+	mux.Use(api.TracingMiddleware(mux))
+	var handlerSpan trace.SpanContext
+	mux.HandleFunc("POST /api/reminders/{id}/snooze", func(w http.ResponseWriter, r *http.Request) {
+		handlerSpan = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	// The caller's trace is continued
+	request := httptest.NewRequest(http.MethodPost, "/api/reminders/42/snooze", nil)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	mux.ServeHTTP(httptest.NewRecorder(), request)
+
+	recorded := spansNamed(spans(), "POST /api/reminders/{id}/snooze")
+	require.Len(t, recorded, 1)
+	span := recorded[0]
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+	assert.Equal(t, span.SpanContext.SpanID(), handlerSpan.SpanID(), "handlers see the server span")
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+	assert.Equal(t, "500", spanAttribute(span, "http.response.status_code"))
+	assert.Equal(t, codes.Error, span.Status.Code)
+}
+
+func TestDispatchSpansAreChildrenOfTheFire(t *testing.T) {
+	spans := captureSpans(t)
+
+	f := newOutboxFixture()
+	reminder := dueReminder(2)
+	ctx, fire := tracing.Start(context.Background(), "scheduler.fire")
+	delivered, err := f.registry.DispatchReminderTo(ctx, reminder, reminder.Destinations)
+	fire.End()
+	require.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+
+	recorded := spansNamed(spans(), "dispatch webhook")
+	require.Len(t, recorded, 2)
+	for i, span := range recorded {
+		assert.Equal(t, fire.SpanContext().TraceID(), span.SpanContext.TraceID())
+		assert.Equal(t, fire.SpanContext().SpanID(), span.Parent.SpanID())
+		assert.Equal(t, reminder.ID.String(), spanAttribute(span, "reminder_id"))
+		assert.Equal(t, reminder.Destinations[i].ID.String(), spanAttribute(span, "destination_id"))
+		assert.Equal(t, "1", spanAttribute(span, "attempt"))
+		assert.Equal(t, codes.Unset, span.Status.Code)
+	}
+}
+
+func TestFailedDispatchSpanRecordsTheError(t *testing.T) {
+	spans := captureSpans(t)
+
+	f := newOutboxFixture()
+	f.dispatcher.err = errors.New("webhook returned non-success status code: 410")
+	reminder := dueReminder(1)
+	_, err := f.registry.DispatchReminderTo(context.Background(), reminder, reminder.Destinations)
+	require.Error(t, err)
+
+	recorded := spansNamed(spans(), "dispatch webhook")
+	require.Len(t, recorded, 1)
+	assert.Equal(t, codes.Error, recorded[0].Status.Code)
+	assert.Contains(t, recorded[0].Status.Description, "410")
+}
+
+func TestWebhookRequestsCarryTheTraceContext(t *testing.T) {
+	spans := captureSpans(t)
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	destination := &models.ReminderDestination{
+		ID:       uuid.New(),
+		Type:     models.DestinationWebhook,
+		Metadata: models.JSONB{"url": server.URL},
+	}
+	reminder := &models.Reminder{ID: uuid.New(), Message: "Stand-up", RemindAtUTC: time.Now().UTC()}
+
+	ctx, parent := tracing.Start(context.Background(), "dispatch webhook")
+	_, err := dispatchers.NewWebhookDispatcher().Dispatch(ctx, reminder, destination, &models.Account{ID: uuid.New()})
+	parent.End()
+	require.NoError(t, err)
+
+	// The outgoing request gets a client span of the same trace, the receiver continues it
+	require.NotEmpty(t, traceparent)
+	var client *tracetest.SpanStub
+	for _, span := range spans() {
+		if span.SpanKind == trace.SpanKindClient {
+			client = &span
+		}
+	}
+	require.NotNil(t, client)
+	assert.Equal(t, parent.SpanContext().SpanID(), client.Parent.SpanID())
+	assert.Equal(t, "00-"+client.SpanContext.TraceID().String()+"-"+client.SpanContext.SpanID().String()+"-01", traceparent)
+}
+
+func TestClusterNotifierCarriesTheTraceToOtherInstances(t *testing.T) {
+	spans := captureSpans(t)
+
+	bus := &fakeReminderEventBus{}
+	follower, leader := &recordingSchedulerNotifier{}, &recordingSchedulerNotifier{}
+	followerNotifier := engine.NewClusterNotifier("instance-a", follower, bus)
+	leaderNotifier := engine.NewClusterNotifier("instance-b", leader, bus)
+	require.NoError(t, followerNotifier.Listen(context.Background()))
+	require.NoError(t, leaderNotifier.Listen(context.Background()))
+
+	reminderID := uuid.New()
+	ctx, request := tracing.Start(context.Background(), "POST /api/reminders")
+	followerNotifier.NotifyReminderCreatedContext(ctx, reminderID)
+	request.End()
+	assert.Equal(t, []string{"created:" + reminderID.String()}, leader.events)
+
+	recorded := spans()
+	notify := spansNamed(recorded, "scheduler.notify")
+	require.Len(t, notify, 1)
+	assert.Equal(t, request.SpanContext().SpanID(), notify[0].Parent.SpanID())
+
+	// The leader continues the trace of the request it did not serve
+	received := spansNamed(recorded, "scheduler.notify.receive")
+	require.Len(t, received, 1)
+	assert.Equal(t, request.SpanContext().TraceID(), received[0].SpanContext.TraceID())
+	assert.Equal(t, notify[0].SpanContext.SpanID(), received[0].Parent.SpanID())
+	assert.Equal(t, "instance-a", spanAttribute(received[0], "from_instance_id"))
+}
@@ -0,0 +1,332 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/dispatchers"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeBase64URL(t *testing.T, value string) []byte {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	require.NoError(t, err)
+	return decoded
+}
+
+// TestEncryptWebPushPayloadRFC8291 checks the encryption against the example of RFC 8291 appendix A
+func TestEncryptWebPushPayloadRFC8291(t *testing.T) {
+	senderKey, err := ecdh.P256().NewPrivateKey(decodeBase64URL(t, "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"))
+	require.NoError(t, err)
+
+	keys := services.WebPushKeys{
+		P256dh: "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4",
+		Auth:   "BTBZMqHH6r4Tts7J_aSIgg",
+	}
+	salt := decodeBase64URL(t, "DGv6ra1nlYgDCS1FRnbzlw")
+
+	body, err := services.EncryptWebPushPayload([]byte("When I grow up, I want to be a watermelon"), keys, salt, senderKey)
+	require.NoError(t, err)
+	assert.Equal(t,
+		"DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN",
+		base64.RawURLEncoding.EncodeToString(body))
+}
+
+// fakeWebPushSubscriptionRepository keeps subscriptions in memory, keyed by endpoint
+type fakeWebPushSubscriptionRepository struct {
+	mu            sync.Mutex
+	subscriptions map[string]models.WebPushSubscription
+}
+
+func (f *fakeWebPushSubscriptionRepository) Upsert(subscription *models.WebPushSubscription) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.subscriptions == nil {
+		f.subscriptions = map[string]models.WebPushSubscription{}
+	}
+	f.subscriptions[subscription.Endpoint] = *subscription
+	return nil
+}
+
+func (f *fakeWebPushSubscriptionRepository) GetByAccountID(accountID uuid.UUID) ([]models.WebPushSubscription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var subscriptions []models.WebPushSubscription
+	for _, subscription := range f.subscriptions {
+		if subscription.AccountID == accountID {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (f *fakeWebPushSubscriptionRepository) DeleteByEndpoint(endpoint string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.subscriptions, endpoint)
+	return nil
+}
+
+func (f *fakeWebPushSubscriptionRepository) DeleteByAccountAndEndpoint(accountID uuid.UUID, endpoint string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if subscription, ok := f.subscriptions[endpoint]; ok && subscription.AccountID == accountID {
+		delete(f.subscriptions, endpoint)
+	}
+	return nil
+}
+
+// webPushBrowser is the key pair and auth secret of a subscribed browser
+type webPushBrowser struct {
+	privateKey *ecdh.PrivateKey
+	auth       []byte
+}
+
+func newWebPushBrowser(t *testing.T) *webPushBrowser {
+	privateKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	auth := make([]byte, 16)
+	_, err = rand.Read(auth)
+	require.NoError(t, err)
+	return &webPushBrowser{privateKey: privateKey, auth: auth}
+}
+
+func (b *webPushBrowser) keys() services.WebPushKeys {
+	return services.WebPushKeys{
+		P256dh: base64.RawURLEncoding.EncodeToString(b.privateKey.PublicKey().Bytes()),
+		Auth:   base64.RawURLEncoding.EncodeToString(b.auth),
+	}
+}
+
+// decrypt reverses the aes128gcm encryption the way a browser does
+func (b *webPushBrowser) decrypt(t *testing.T, body []byte) []byte {
+	require.Greater(t, len(body), 21)
+	salt := body[:16]
+	assert.Equal(t, uint32(4096), binary.BigEndian.Uint32(body[16:20]))
+	idLength := int(body[20])
+	senderKeyBytes := body[21 : 21+idLength]
+	ciphertext := body[21+idLength:]
+
+	senderKey, err := ecdh.P256().NewPublicKey(senderKeyBytes)
+	require.NoError(t, err)
+	sharedSecret, err := b.privateKey.ECDH(senderKey)
+	require.NoError(t, err)
+
+	keyInfo := "WebPush: info\x00" + string(b.privateKey.PublicKey().Bytes()) + string(senderKeyBytes)
+	ikm, err := hkdf.Key(sha256.New, sharedSecret, b.auth, keyInfo, 32)
+	require.NoError(t, err)
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	require.NoError(t, err)
+	contentKey, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	require.NoError(t, err)
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	require.NoError(t, err)
+
+	block, err := aes.NewCipher(contentKey)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	record, err := gcm.Open(nil, nonce, ciphertext, nil)
+	require.NoError(t, err)
+
+	// Strip the padding and the last record delimiter
+	record = bytes.TrimRight(record, "\x00")
+	require.Equal(t, byte(0x02), record[len(record)-1])
+	return record[:len(record)-1]
+}
+
+// fakePushService answers every push with the status configured for its endpoint path
+type fakePushService struct {
+	mu       sync.Mutex
+	statuses map[string]int
+	requests map[string]*http.Request
+	bodies   map[string][]byte
+}
+
+func (f *fakePushService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.requests == nil {
+		f.requests = map[string]*http.Request{}
+		f.bodies = map[string][]byte{}
+	}
+	f.requests[r.URL.Path] = r
+	f.bodies[r.URL.Path] = body
+
+	if status, ok := f.statuses[r.URL.Path]; ok {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Location", "https://push.example.com/message"+r.URL.Path)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func newTestWebPushService(t *testing.T) *services.WebPushService {
+	publicKey, privateKey, err := services.GenerateVAPIDKeys()
+	require.NoError(t, err)
+	service := services.NewWebPushService(publicKey, privateKey, "mailto:ops@example.com")
+	require.True(t, service.IsEnabled())
+	return service
+}
+
+func webPushDestination(accountID uuid.UUID) *models.ReminderDestination {
+	return &models.ReminderDestination{
+		Type:     models.DestinationWebPush,
+		Metadata: models.JSONB{"account_id": accountID.String()},
+	}
+}
+
+func TestWebPushServiceDisabledWithoutKeys(t *testing.T) {
+	assert.False(t, services.NewWebPushService("", "", "").IsEnabled())
+
+	// A public key from another pair must not be accepted
+	publicKey, _, err := services.GenerateVAPIDKeys()
+	require.NoError(t, err)
+	_, privateKey, err := services.GenerateVAPIDKeys()
+	require.NoError(t, err)
+	assert.False(t, services.NewWebPushService(publicKey, privateKey, "mailto:ops@example.com").IsEnabled())
+}
+
+func TestValidateWebPushSubscription(t *testing.T) {
+	keys := newWebPushBrowser(t).keys()
+
+	assert.NoError(t, services.ValidateWebPushSubscription("https://fcm.googleapis.com/fcm/send/abc", keys))
+	assert.ErrorIs(t, services.ValidateWebPushSubscription("http://fcm.googleapis.com/fcm/send/abc", keys), services.ErrInvalidWebPushSubscription)
+
+	badKey := keys
+	badKey.P256dh = base64.RawURLEncoding.EncodeToString(make([]byte, 65))
+	assert.ErrorIs(t, services.ValidateWebPushSubscription("https://push.example.com/a", badKey), services.ErrInvalidWebPushSubscription)
+
+	badAuth := keys
+	badAuth.Auth = base64.RawURLEncoding.EncodeToString(make([]byte, 8))
+	assert.ErrorIs(t, services.ValidateWebPushSubscription("https://push.example.com/a", badAuth), services.ErrInvalidWebPushSubscription)
+}
+
+func TestWebPushDispatcherDeliversEncryptedPayload(t *testing.T) {
+	pushService := &fakePushService{}
+	server := httptest.NewServer(pushService)
+	defer server.Close()
+
+	service := newTestWebPushService(t)
+	repo := &fakeWebPushSubscriptionRepository{}
+	accountID := uuid.New()
+	browser := newWebPushBrowser(t)
+	keys := browser.keys()
+	require.NoError(t, repo.Upsert(&models.WebPushSubscription{
+		AccountID: accountID,
+		Endpoint:  server.URL + "/browser",
+		P256dh:    keys.P256dh,
+		Auth:      keys.Auth,
+	}))
+
+	reminder := &models.Reminder{ID: uuid.New(), AccountID: accountID, Message: "Water the plants"}
+	dispatcher := dispatchers.NewWebPushDispatcher(service, repo)
+	receipt, err := dispatcher.Dispatch(context.Background(), reminder, webPushDestination(accountID), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "https://push.example.com/message/browser", receipt.MessageID)
+
+	req := pushService.requests["/browser"]
+	require.NotNil(t, req)
+	assert.Equal(t, "aes128gcm", req.Header.Get("Content-Encoding"))
+	assert.NotEmpty(t, req.Header.Get("TTL"))
+
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal(browser.decrypt(t, pushService.bodies["/browser"]), &payload))
+	assert.Equal(t, "Water the plants", payload["body"])
+	assert.Equal(t, reminder.ID.String(), payload["reminder_id"])
+
+	// The VAPID token is signed with the application server key and scoped to the push service
+	authorization := req.Header.Get("Authorization")
+	require.True(t, strings.HasPrefix(authorization, "vapid t="))
+	parts := strings.SplitN(strings.TrimPrefix(authorization, "vapid t="), ", k=", 2)
+	require.Len(t, parts, 2)
+	assert.Equal(t, service.PublicKey(), parts[1])
+
+	publicKey, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), decodeBase64URL(t, parts[1]))
+	require.NoError(t, err)
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(parts[0], claims, func(*jwt.Token) (interface{}, error) { return publicKey, nil },
+		jwt.WithValidMethods([]string{"ES256"}), jwt.WithAudience(server.URL))
+	require.NoError(t, err)
+	assert.Equal(t, "mailto:ops@example.com", claims["sub"])
+}
+
+func TestWebPushDispatcherPrunesExpiredSubscriptions(t *testing.T) {
+	pushService := &fakePushService{statuses: map[string]int{"/gone": http.StatusGone, "/unknown": http.StatusNotFound}}
+	server := httptest.NewServer(pushService)
+	defer server.Close()
+
+	repo := &fakeWebPushSubscriptionRepository{}
+	accountID := uuid.New()
+	for _, path := range []string{"/gone", "/unknown", "/alive"} {
+		keys := newWebPushBrowser(t).keys()
+		require.NoError(t, repo.Upsert(&models.WebPushSubscription{
+			AccountID: accountID,
+			Endpoint:  server.URL + path,
+			P256dh:    keys.P256dh,
+			Auth:      keys.Auth,
+		}))
+	}
+
+	dispatcher := dispatchers.NewWebPushDispatcher(newTestWebPushService(t), repo)
+	reminder := &models.Reminder{ID: uuid.New(), AccountID: accountID, Message: "Stand-up"}
+	_, err := dispatcher.Dispatch(context.Background(), reminder, webPushDestination(accountID), nil)
+	require.NoError(t, err)
+
+	subscriptions, _ := repo.GetByAccountID(accountID)
+	require.Len(t, subscriptions, 1)
+	assert.Equal(t, server.URL+"/alive", subscriptions[0].Endpoint)
+}
+
+func TestWebPushDispatcherRetriesUnavailablePushService(t *testing.T) {
+	pushService := &fakePushService{statuses: map[string]int{"/busy": http.StatusTooManyRequests, "/down": http.StatusServiceUnavailable}}
+	server := httptest.NewServer(pushService)
+	defer server.Close()
+
+	repo := &fakeWebPushSubscriptionRepository{}
+	accountID := uuid.New()
+	for _, path := range []string{"/busy", "/down"} {
+		keys := newWebPushBrowser(t).keys()
+		require.NoError(t, repo.Upsert(&models.WebPushSubscription{
+			AccountID: accountID,
+			Endpoint:  server.URL + path,
+			P256dh:    keys.P256dh,
+			Auth:      keys.Auth,
+		}))
+	}
+
+	dispatcher := dispatchers.NewWebPushDispatcher(newTestWebPushService(t), repo)
+	reminder := &models.Reminder{ID: uuid.New(), AccountID: accountID, Message: "Stand-up"}
+	_, err := dispatcher.Dispatch(context.Background(), reminder, webPushDestination(accountID), nil)
+	require.Error(t, err)
+	_, transient := dispatchers.AsTransient(err)
+	assert.True(t, transient)
+
+	// Unavailable subscriptions are kept for the retry
+	subscriptions, _ := repo.GetByAccountID(accountID)
+	assert.Len(t, subscriptions, 2)
+}
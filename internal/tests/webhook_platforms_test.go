@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/dispatchers"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func webhookPlatformFixture() *models.Reminder {
+	snoozedUntil := time.Date(2030, time.June, 3, 7, 15, 0, 0, time.UTC)
+	return &models.Reminder{
+		ID:           uuid.New(),
+		Message:      "Deploy <v2> & celebrate",
+		RemindAtUTC:  time.Date(2030, time.June, 3, 7, 0, 0, 0, time.UTC),
+		SnoozedAtUTC: &snoozedUntil,
+	}
+}
+
+func TestWebhookPlatformValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata models.JSONB
+		valid    bool
+	}{
+		{"Teams", models.JSONB{"platform": "teams"}, true},
+		{"Mattermost", models.JSONB{"platform": "mattermost", "channel": "town-square"}, true},
+		{"Google Chat", models.JSONB{"platform": "google_chat"}, true},
+		{"ntfy", models.JSONB{"platform": "ntfy", "priority": "high", "tags": []interface{}{"warning", "skull"}}, true},
+		{"ntfy numeric priority", models.JSONB{"platform": "ntfy", "priority": float64(5), "tags": "rotating_light"}, true},
+		{"ntfy invalid priority", models.JSONB{"platform": "ntfy", "priority": "extreme"}, false},
+		{"ntfy invalid tags", models.JSONB{"platform": "ntfy", "tags": []interface{}{1, 2}}, false},
+		{"Unknown platform", models.JSONB{"platform": "irc"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.metadata["url"] = "https://example.com/hook"
+			destination := &models.ReminderDestination{Type: models.DestinationWebhook, Metadata: tt.metadata}
+			err := destination.ValidateMetadata()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestWebhookPlatformPayloads(t *testing.T) {
+	formatter := services.NewWebhookFormatter()
+	reminder := webhookPlatformFixture()
+
+	format := func(metadata models.JSONB) map[string]interface{} {
+		destination := &models.ReminderDestination{Type: models.DestinationWebhook, Metadata: metadata}
+		payload, err := formatter.FormatPayload(reminder, destination, nil)
+		require.NoError(t, err)
+		require.NoError(t, formatter.ValidatePayload(destination, payload))
+		assert.Equal(t, "application/json", formatter.GetContentType(destination))
+
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal(payload, &decoded))
+		return decoded
+	}
+
+	t.Run("Teams Adaptive Card", func(t *testing.T) {
+		payload := format(models.JSONB{"platform": "teams"})
+		assert.Equal(t, "message", payload["type"])
+		attachment := payload["attachments"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, "application/vnd.microsoft.card.adaptive", attachment["contentType"])
+		card := attachment["content"].(map[string]interface{})
+		assert.Equal(t, "AdaptiveCard", card["type"])
+		body := card["body"].([]interface{})
+		assert.Equal(t, reminder.Message, body[1].(map[string]interface{})["text"])
+		facts := body[2].(map[string]interface{})["facts"].([]interface{})
+		assert.Len(t, facts, 2, "scheduled time and snooze")
+	})
+
+	t.Run("Mattermost attachments", func(t *testing.T) {
+		payload := format(models.JSONB{"platform": "mattermost", "channel": "town-square", "username": "chronos"})
+		assert.Equal(t, "town-square", payload["channel"])
+		assert.Equal(t, "chronos", payload["username"])
+		attachment := payload["attachments"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, reminder.Message, attachment["text"])
+		assert.Len(t, attachment["fields"], 2)
+	})
+
+	t.Run("Google Chat cardsV2", func(t *testing.T) {
+		payload := format(models.JSONB{"platform": "google_chat"})
+		card := payload["cardsV2"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, "reminder-"+reminder.ID.String(), card["cardId"])
+		sections := card["card"].(map[string]interface{})["sections"].([]interface{})
+		widgets := sections[0].(map[string]interface{})["widgets"].([]interface{})
+		paragraph := widgets[0].(map[string]interface{})["textParagraph"].(map[string]interface{})
+		assert.Equal(t, "Deploy &lt;v2&gt; &amp; celebrate", paragraph["text"], "card text is HTML")
+		assert.Len(t, widgets, 3)
+	})
+}
+
+func TestNtfyWebhook(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		received = r
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	destination := &models.ReminderDestination{
+		ID:   uuid.New(),
+		Type: models.DestinationWebhook,
+		Metadata: models.JSONB{
+			"url":      server.URL + "/chronos",
+			"platform": "ntfy",
+			"title":    "Work\r\nInjected: header",
+			"priority": "HIGH",
+			"tags":     []interface{}{"warning", "calendar"},
+		},
+	}
+	require.NoError(t, destination.ValidateMetadata())
+
+	_, err := dispatchers.NewWebhookDispatcher().Dispatch(context.Background(), webhookPlatformFixture(), destination, nil)
+	require.NoError(t, err)
+	require.NotNil(t, received)
+
+	assert.Equal(t, "text/plain; charset=utf-8", received.Header.Get("Content-Type"))
+	assert.Equal(t, "Work  Injected: header", received.Header.Get("Title"))
+	assert.Empty(t, received.Header.Get("Injected"))
+	assert.Equal(t, "high", received.Header.Get("Priority"))
+	assert.Equal(t, "warning,calendar", received.Header.Get("Tags"))
+	assert.Contains(t, string(body), "Deploy <v2> & celebrate")
+	assert.Contains(t, string(body), "Snoozed until")
+
+	// A template turns the payload back into JSON
+	destination.Metadata["template"] = `{"topic": "chronos", "message": {{json .Message}}}`
+	assert.Equal(t, "application/json", services.NewWebhookFormatter().GetContentType(destination))
+}
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/dispatchers"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/ericp/chronos-bot-reminder/pkg/webhook"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookSignatureVerification(t *testing.T) {
+	secret := "whsec_test"
+	body := []byte(`{"message":"Water the plants"}`)
+	now := time.Now()
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := webhook.Sign(secret, now, body)
+
+	assert.NoError(t, webhook.Verify(body, signature, timestamp, webhook.DefaultTolerance, secret))
+	assert.NoError(t, webhook.Verify(body, signature, timestamp, webhook.DefaultTolerance, "whsec_new", secret), "any of the secrets")
+
+	assert.ErrorIs(t, webhook.Verify([]byte(`{"message":"tampered"}`), signature, timestamp, webhook.DefaultTolerance, secret), webhook.ErrSignatureMismatch)
+	assert.ErrorIs(t, webhook.Verify(body, signature, timestamp, webhook.DefaultTolerance, "whsec_other"), webhook.ErrSignatureMismatch)
+	assert.ErrorIs(t, webhook.Verify(body, "", timestamp, webhook.DefaultTolerance, secret), webhook.ErrMissingHeaders)
+	assert.ErrorIs(t, webhook.Verify(body, signature, "yesterday", webhook.DefaultTolerance, secret), webhook.ErrInvalidTimestamp)
+
+	// A replayed request is rejected once outside the tolerance, even with a valid signature
+	old := now.Add(-time.Hour)
+	oldSignature := webhook.Sign(secret, old, body)
+	oldTimestamp := strconv.FormatInt(old.Unix(), 10)
+	assert.ErrorIs(t, webhook.Verify(body, oldSignature, oldTimestamp, webhook.DefaultTolerance, secret), webhook.ErrTimestampOutOfTolerance)
+	assert.ErrorIs(t, webhook.Verify(body, signature, oldTimestamp, webhook.DefaultTolerance, secret), webhook.ErrTimestampOutOfTolerance)
+
+	guard := webhook.NewReplayGuard(webhook.DefaultTolerance)
+	assert.NoError(t, guard.Check("delivery-1"))
+	assert.ErrorIs(t, guard.Check("delivery-1"), webhook.ErrReplayedDelivery)
+	assert.NoError(t, guard.Check("delivery-2"))
+}
+
+func TestWebhookSecretStorage(t *testing.T) {
+	services.SetWebhookSecretKey("test-key")
+
+	secret, err := services.GenerateWebhookSecret()
+	require.NoError(t, err)
+	assert.Contains(t, secret, services.WebhookSecretPrefix)
+
+	encrypted, err := services.EncryptWebhookSecret(secret)
+	require.NoError(t, err)
+	assert.NotContains(t, encrypted, secret)
+
+	decrypted, err := services.DecryptWebhookSecret(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, secret, decrypted)
+
+	// Secrets do not decrypt with another key
+	services.SetWebhookSecretKey("other-key")
+	_, err = services.DecryptWebhookSecret(encrypted)
+	assert.Error(t, err)
+	services.SetWebhookSecretKey("test-key")
+
+	_, err = services.DecryptWebhookSecret("whsec_plain")
+	assert.Error(t, err)
+}
+
+func TestPrepareWebhookSigning(t *testing.T) {
+	services.SetWebhookSecretKey("test-key")
+
+	t.Run("New webhook gets a secret", func(t *testing.T) {
+		destination := &models.ReminderDestination{
+			Type:     models.DestinationWebhook,
+			Metadata: models.JSONB{"url": "https://example.com/hook", services.WebhookSecretMetadataKey: "chosen-by-client"},
+		}
+		secret, err := services.PrepareWebhookSigning(destination, nil)
+		require.NoError(t, err)
+		require.NotEmpty(t, secret)
+
+		stored, err := services.WebhookSigningSecret(destination)
+		require.NoError(t, err)
+		assert.Equal(t, secret, stored, "the client value is discarded")
+
+		redacted := services.RedactDestinationMetadata(destination.Metadata)
+		assert.NotContains(t, redacted, services.WebhookSecretMetadataKey)
+		assert.Contains(t, destination.Metadata, services.WebhookSecretMetadataKey, "the stored metadata is left untouched")
+	})
+
+	t.Run("Same URL keeps its secret", func(t *testing.T) {
+		previous := models.ReminderDestination{Type: models.DestinationWebhook, Metadata: models.JSONB{"url": "https://example.com/hook"}}
+		original, err := services.PrepareWebhookSigning(&previous, nil)
+		require.NoError(t, err)
+
+		edited := &models.ReminderDestination{Type: models.DestinationWebhook, Metadata: models.JSONB{"url": "https://example.com/hook", "platform": "generic"}}
+		secret, err := services.PrepareWebhookSigning(edited, []models.ReminderDestination{previous})
+		require.NoError(t, err)
+		assert.Empty(t, secret)
+		kept, err := services.WebhookSigningSecret(edited)
+		require.NoError(t, err)
+		assert.Equal(t, original, kept)
+
+		moved := &models.ReminderDestination{Type: models.DestinationWebhook, Metadata: models.JSONB{"url": "https://example.org/other"}}
+		secret, err = services.PrepareWebhookSigning(moved, []models.ReminderDestination{previous})
+		require.NoError(t, err)
+		assert.NotEmpty(t, secret)
+		assert.NotEqual(t, original, secret)
+	})
+
+	t.Run("Other destinations are left alone", func(t *testing.T) {
+		destination := &models.ReminderDestination{Type: models.DestinationDiscordDM, Metadata: models.JSONB{"user_id": "1234"}}
+		secret, err := services.PrepareWebhookSigning(destination, nil)
+		require.NoError(t, err)
+		assert.Empty(t, secret)
+		assert.NotContains(t, destination.Metadata, services.WebhookSecretMetadataKey)
+	})
+}
+
+func TestWebhookDispatcherSignsRequests(t *testing.T) {
+	services.SetWebhookSecretKey("test-key")
+
+	var received *http.Request
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedBody, _ = io.ReadAll(r.Body)
+		received = r
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	destination := &models.ReminderDestination{
+		ID:   uuid.New(),
+		Type: models.DestinationWebhook,
+		Metadata: models.JSONB{
+			"url":     server.URL,
+			"headers": map[string]interface{}{webhook.SignatureHeader: "v1=forged", "X-Custom": "kept"},
+		},
+	}
+	secret, err := services.SetWebhookSigningSecret(destination)
+	require.NoError(t, err)
+
+	reminder := &models.Reminder{ID: uuid.New(), Message: "Stand-up", RemindAtUTC: time.Now().UTC()}
+	receipt, err := dispatchers.NewWebhookDispatcher().Dispatch(context.Background(), reminder, destination, &models.Account{ID: uuid.New()})
+	require.NoError(t, err)
+	require.NotNil(t, received)
+
+	deliveryID := received.Header.Get(webhook.DeliveryIDHeader)
+	assert.NotEmpty(t, deliveryID)
+	assert.Equal(t, deliveryID, receipt.MessageID)
+	assert.Equal(t, "kept", received.Header.Get("X-Custom"))
+	idempotencyKey := received.Header.Get(webhook.IdempotencyKeyHeader)
+	assert.Equal(t, dispatchers.IdempotencyKey(reminder.ID, reminder.RemindAtUTC), idempotencyKey)
+
+	received.Body = io.NopCloser(bytes.NewReader(receivedBody))
+	body, err := webhook.VerifyRequest(received, webhook.DefaultTolerance, secret)
+	require.NoError(t, err, "custom headers cannot override the signature")
+	assert.Equal(t, receivedBody, body)
+
+	// Each request gets its own delivery ID, the fire keeps its idempotency key
+	_, err = dispatchers.NewWebhookDispatcher().Dispatch(context.Background(), reminder, destination, &models.Account{ID: uuid.New()})
+	require.NoError(t, err)
+	assert.NotEqual(t, deliveryID, received.Header.Get(webhook.DeliveryIDHeader))
+	assert.Equal(t, idempotencyKey, received.Header.Get(webhook.IdempotencyKeyHeader))
+}
@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ericp/chronos-bot-reminder/internal/database/models"
+	"github.com/ericp/chronos-bot-reminder/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookTemplateValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		template interface{}
+		valid    bool
+	}{
+		{"Valid template", `{"text": {{json .Message}}, "at": {{json (formatTime "15:04" .RemindAt)}}}`, true},
+		{"Conditional", `{"snoozed": {{if .IsSnoozed}}true{{else}}false{{end}}}`, true},
+		{"Syntax error", `{"text": {{json .Message}`, false},
+		{"Unknown field", `{"text": {{json .Password}}}`, false},
+		{"Not JSON", `Reminder: {{.Message}}`, false},
+		{"Unescaped string", `{"text": "{{.Message}}"}`, false},
+		{"Range loop", `{"n": [{{range 1000000000}}1,{{end}}1]}`, false},
+		{"Nested template", `{{define "x"}}{}{{end}}{{template "x"}}`, false},
+		{"Variable", `{{$text := json .Message}}{"text": {{$text}}}`, true},
+		{"Escaping builtin", `{"q": {{json (urlquery .Message)}}}`, true},
+		{"Printf", `{"text": {{json (printf "%s" .Message)}}}`, false},
+		{"Print in a condition", `{{if print .Message}}{}{{end}}`, false},
+		{"Reassignment", `{{$text := .Message}}{{$text = .Timezone}}{"text": {{json $text}}}`, false},
+		{"Not a string", 42, false},
+		{"Too long", `{"text": "` + strings.Repeat("a", models.MaxWebhookTemplateLength) + `"}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			destination := &models.ReminderDestination{
+				Type:     models.DestinationWebhook,
+				Metadata: models.JSONB{"url": "https://example.com/hook", "template": tt.template},
+			}
+			err := destination.ValidateMetadata()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestWebhookTemplateRendering(t *testing.T) {
+	rule := "FREQ=WEEKLY;BYDAY=MO"
+	remindAt := time.Date(2030, time.June, 3, 7, 0, 0, 0, time.UTC)
+	snoozedUntil := remindAt.Add(15 * time.Minute)
+	reminder := &models.Reminder{
+		ID:           uuid.New(),
+		Message:      `Call "Mom"`,
+		RemindAtUTC:  remindAt,
+		Recurrence:   services.RecurrenceRRule,
+		RRule:        &rule,
+		SnoozedAtUTC: &snoozedUntil,
+	}
+	account := &models.Account{ID: uuid.New(), Timezone: &models.Timezone{IANALocation: "Europe/Paris"}}
+	destination := &models.ReminderDestination{
+		Type: models.DestinationWebhook,
+		Metadata: models.JSONB{
+			"url":      "https://example.com/hook",
+			"platform": "slack", // the template wins over the platform
+			"template": `{"title": {{json (upper .Message)}}, "local": {{json (formatTime "2006-01-02 15:04" .RemindAt)}}, "tz": {{json .Timezone}}, "recurrence": {{json .Recurrence}}, "rule": {{json .RRule}}, "snoozed_until": {{json (formatTime "15:04" .SnoozedUntil)}}, "account": {{json .AccountID}}}`,
+		},
+	}
+	require.NoError(t, destination.ValidateMetadata())
+
+	payload, err := services.NewWebhookFormatter().FormatPayload(reminder, destination, account)
+	require.NoError(t, err)
+
+	var rendered map[string]string
+	require.NoError(t, json.Unmarshal(payload, &rendered))
+	assert.Equal(t, `CALL "MOM"`, rendered["title"])
+	assert.Equal(t, "2030-06-03 09:00", rendered["local"])
+	assert.Equal(t, "Europe/Paris", rendered["tz"])
+	assert.Equal(t, "RRULE", rendered["recurrence"])
+	assert.Equal(t, rule, rendered["rule"])
+	assert.Equal(t, "09:15", rendered["snoozed_until"])
+	assert.Equal(t, account.ID.String(), rendered["account"])
+
+	// Without a template the platform shape is kept
+	delete(destination.Metadata, "template")
+	payload, err = services.NewWebhookFormatter().FormatPayload(reminder, destination, account)
+	require.NoError(t, err)
+	assert.Contains(t, string(payload), `"blocks"`)
+}
+
+// nestedJSON returns an expression encoding the message depth times, each level escapes
+// the quotes and backslashes of the previous one and doubles its size
+func nestedJSON(depth int) string {
+	expr := ".Message"
+	for i := 0; i < depth; i++ {
+		expr = "(json " + expr + ")"
+	}
+	return expr
+}
+
+func TestWebhookTemplateOutputLimit(t *testing.T) {
+	// Every value is under the limit, the payload is not
+	tmpl, err := models.ParseWebhookTemplate(`{{$v := ` + nestedJSON(11) + `}}{"a": {{json $v}}, "b": {{json $v}}, "c": {{json $v}}, "d": {{json $v}}, "e": {{json $v}}}`)
+	require.NoError(t, err)
+
+	_, err = models.RenderWebhookTemplate(tmpl, models.SampleWebhookTemplateData())
+	assert.ErrorContains(t, err, "rendered payload exceeds")
+}
+
+func TestWebhookTemplateValueLimit(t *testing.T) {
+	tmpl, err := models.ParseWebhookTemplate(`{"a": {{json ` + nestedJSON(30) + `}}}`)
+	require.NoError(t, err)
+
+	_, err = models.RenderWebhookTemplate(tmpl, models.SampleWebhookTemplateData())
+	assert.ErrorContains(t, err, "template value exceeds")
+}
+
+// A few bytes of printf used to allocate hundreds of megabytes per rendering
+func TestWebhookTemplateRejectsGrowingValues(t *testing.T) {
+	template := `{{$a := printf "%01000000d" 0}}{{$a = printf "%s%s%s%s%s%s%s%s%s%s" $a $a $a $a $a $a $a $a $a $a}}{{$a = printf "%s%s%s%s%s" $a $a $a $a $a}}{"x":1}`
+	destination := &models.ReminderDestination{
+		Type:     models.DestinationWebhook,
+		Metadata: models.JSONB{"url": "https://example.com/hook", "template": template},
+	}
+	assert.Error(t, destination.ValidateMetadata())
+
+	_, err := models.ParseWebhookTemplate(template)
+	assert.ErrorContains(t, err, "printf is not allowed")
+}
@@ -0,0 +1,115 @@
+// Package tracing provides the OpenTelemetry tracer of the application. Tracing is
+// disabled by default: the spans are then no-ops until a provider is installed.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ericp/chronos-bot-reminder/internal/config"
+	"github.com/ericp/chronos-bot-reminder/internal/logging"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans created by the application
+const instrumentationName = "github.com/ericp/chronos-bot-reminder"
+
+func init() {
+	// The trace context goes along the outgoing requests and the reminder events even
+	// when this instance does not export, so the other services keep a single trace
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Init exports the spans to the OTLP endpoint of the configuration when tracing is
+// enabled. The returned function flushes the pending spans on shutdown.
+func Init(cfg *config.Config) (func(context.Context) error, error) {
+	if !cfg.TracingEnabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var options []otlptracehttp.Option
+	if cfg.TracingEndpoint != "" {
+		endpoint, err := url.Parse(cfg.TracingEndpoint)
+		if err != nil || endpoint.Host == "" {
+			return nil, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_ENDPOINT %q", cfg.TracingEndpoint)
+		}
+		// As with the standard variable, the endpoint is the base URL of the collector
+		endpoint.Path = strings.TrimSuffix(endpoint.Path, "/") + "/v1/traces"
+		options = append(options, otlptracehttp.WithEndpointURL(endpoint.String()))
+	}
+	exporter, err := otlptracehttp.New(context.Background(), options...)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := Install(exporter, cfg.TracingServiceName, float64(cfg.TracingSamplePercent)/100)
+	logging.For(logging.App).Info("Tracing enabled", "endpoint", cfg.TracingEndpoint, "sample_percent", cfg.TracingSamplePercent)
+	return provider.Shutdown, nil
+}
+
+// Install makes the application export its spans to the exporter, in batches. The
+// tests install an in-memory exporter and flush the provider before reading it.
+func Install(exporter sdktrace.SpanExporter, serviceName string, sampleRatio float64) *sdktrace.TracerProvider {
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider
+}
+
+// Start starts a span, child of the span of the context if there is one
+func Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, options...)
+}
+
+// End records the error of the operation on the span, if any, and ends it
+func End(span trace.Span, err error) {
+	RecordError(span, err)
+	span.End()
+}
+
+// RecordError marks the span as failed with the error, a nil error is ignored
+func RecordError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// Transport wraps an HTTP transport, nil for the default one, so the outgoing requests
+// get a client span, child of the span of their context, and carry the trace context
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(base)
+}
+
+// Inject writes the trace context of ctx into the carrier, for the messages sent to
+// the other instances
+func Inject(ctx context.Context, carrier map[string]string) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(carrier))
+}
+
+// Extract returns ctx with the remote trace context found in the carrier
+func Extract(ctx context.Context, carrier map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(carrier))
+}
+
+// ExtractHTTP returns ctx with the remote trace context of the request headers
+func ExtractHTTP(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
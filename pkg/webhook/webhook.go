@@ -0,0 +1,171 @@
+// Package webhook verifies the signed requests Chronos sends to webhook destinations.
+//
+// Every request carries these headers:
+//
+//	X-Chronos-Timestamp: 1767225600
+//	X-Chronos-Signature: v1=5257a869e7ecebeda32affa62cdca3fa51cad7e77a0e56ff536d0ce8e108d8bd
+//	X-Chronos-Delivery:  0b9f4a52-7f0e-4c1e-9a43-2b6f5d1c7e10
+//	Idempotency-Key:     6f1c2a9e-3b4d-4e5f-8a7b-9c0d1e2f3a4b:1767225600
+//
+// The signature is the hex-encoded HMAC-SHA256 of "<timestamp>.<body>" keyed with the
+// signing secret of the destination. A receiver checks it with VerifyRequest:
+//
+//	body, err := webhook.VerifyRequest(r, webhook.DefaultTolerance, secret)
+//	if err != nil {
+//		http.Error(w, "invalid signature", http.StatusUnauthorized)
+//		return
+//	}
+//
+// The timestamp tolerance bounds how long a captured request can be replayed; a
+// ReplayGuard also rejects a delivery ID seen twice within that window.
+//
+// The delivery ID is new on every attempt. The idempotency key identifies the
+// reminder fire instead: it is the same when a delivery is retried or resumed after
+// a restart, so a receiver that already processed the key can skip the request.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// SignatureHeader carries the signatures of the request
+	SignatureHeader = "X-Chronos-Signature"
+	// TimestampHeader carries the Unix time the request was signed at
+	TimestampHeader = "X-Chronos-Timestamp"
+	// DeliveryIDHeader carries a unique identifier of the request
+	DeliveryIDHeader = "X-Chronos-Delivery"
+	// IdempotencyKeyHeader carries the identifier of the reminder fire, shared by its retries
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// SignatureVersion prefixes the signatures of the current scheme
+	SignatureVersion = "v1"
+	// DefaultTolerance is the largest accepted gap between the timestamp and the receiver's clock
+	DefaultTolerance = 5 * time.Minute
+)
+
+var (
+	// ErrMissingHeaders is returned when the signature or timestamp header is absent
+	ErrMissingHeaders = errors.New("webhook: missing signature headers")
+	// ErrInvalidTimestamp is returned when the timestamp header is not a Unix time
+	ErrInvalidTimestamp = errors.New("webhook: invalid timestamp")
+	// ErrTimestampOutOfTolerance is returned for a request signed too long ago, or in the future
+	ErrTimestampOutOfTolerance = errors.New("webhook: timestamp outside the tolerance")
+	// ErrSignatureMismatch is returned when no signature matches any of the secrets
+	ErrSignatureMismatch = errors.New("webhook: signature mismatch")
+	// ErrReplayedDelivery is returned by ReplayGuard for a delivery ID already seen
+	ErrReplayedDelivery = errors.New("webhook: delivery already received")
+)
+
+// Sign returns the signature header value of a body sent at the given time
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	return SignatureVersion + "=" + computeSignature(secret, timestamp.Unix(), body)
+}
+
+func computeSignature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and timestamp header values of a body against the given
+// secrets. Several secrets can be passed while a rotation is rolled out to the receiver.
+// A zero tolerance disables the timestamp check.
+func Verify(body []byte, signatureHeader, timestampHeader string, tolerance time.Duration, secrets ...string) error {
+	if signatureHeader == "" || timestampHeader == "" {
+		return ErrMissingHeaders
+	}
+
+	timestamp, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	if tolerance > 0 {
+		gap := time.Since(time.Unix(timestamp, 0))
+		if gap > tolerance || gap < -tolerance {
+			return ErrTimestampOutOfTolerance
+		}
+	}
+
+	for _, secret := range secrets {
+		expected := computeSignature(secret, timestamp, body)
+		for _, signature := range strings.Split(signatureHeader, ",") {
+			version, value, found := strings.Cut(strings.TrimSpace(signature), "=")
+			if !found || version != SignatureVersion {
+				continue
+			}
+			if hmac.Equal([]byte(value), []byte(expected)) {
+				return nil
+			}
+		}
+	}
+
+	return ErrSignatureMismatch
+}
+
+// VerifyRequest reads the body of a request and verifies its signature. The body is
+// returned and also left readable on the request.
+func VerifyRequest(r *http.Request, tolerance time.Duration, secrets ...string) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := Verify(body, r.Header.Get(SignatureHeader), r.Header.Get(TimestampHeader), tolerance, secrets...); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// ReplayGuard remembers the delivery IDs received within a window, in memory. Receivers
+// running several instances should keep the IDs in a shared store instead.
+type ReplayGuard struct {
+	window time.Duration
+	mu     sync.Mutex
+	seen   map[string]time.Time
+}
+
+// NewReplayGuard creates a guard remembering delivery IDs for the given window, which
+// should be at least the tolerance passed to Verify
+func NewReplayGuard(window time.Duration) *ReplayGuard {
+	return &ReplayGuard{
+		window: window,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// Check records a delivery ID and returns ErrReplayedDelivery when it was already seen
+func (g *ReplayGuard) Check(deliveryID string) error {
+	if deliveryID == "" {
+		return ErrMissingHeaders
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	for id, seenAt := range g.seen {
+		if now.Sub(seenAt) > g.window {
+			delete(g.seen, id)
+		}
+	}
+
+	if _, exists := g.seen[deliveryID]; exists {
+		return ErrReplayedDelivery
+	}
+	g.seen[deliveryID] = now
+	return nil
+}